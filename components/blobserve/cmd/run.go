@@ -52,7 +52,7 @@ var runCmd = &cobra.Command{
 			dockerCfgMu sync.RWMutex
 		)
 		if cfg.AuthCfg != "" {
-			dockerCfg = loadDockerCfg(cfg.AuthCfg)
+			dockerCfg = loadDockerCfg(cfg.AuthCfg, cfg.UpstreamAuthCfg)
 		}
 
 		reg := prometheus.NewRegistry()
@@ -133,15 +133,22 @@ var runCmd = &cobra.Command{
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
 
-		err = watch.File(ctx, cfg.AuthCfg, func() {
+		reloadDockerCfg := func() {
 			dockerCfgMu.Lock()
 			defer dockerCfgMu.Unlock()
 
-			dockerCfg = loadDockerCfg(cfg.AuthCfg)
-		})
+			dockerCfg = loadDockerCfg(cfg.AuthCfg, cfg.UpstreamAuthCfg)
+		}
+		err = watch.File(ctx, cfg.AuthCfg, reloadDockerCfg)
 		if err != nil {
 			log.WithError(err).Fatal("cannot start watch of Docker auth configuration file")
 		}
+		if cfg.UpstreamAuthCfg != "" {
+			err = watch.File(ctx, cfg.UpstreamAuthCfg, reloadDockerCfg)
+			if err != nil {
+				log.WithError(err).Fatal("cannot start watch of upstream Docker auth configuration file")
+			}
+		}
 
 		log.Info("🏪 blobserve is up and running")
 		sigChan := make(chan os.Signal, 1)
@@ -154,7 +161,22 @@ func init() {
 	rootCmd.AddCommand(runCmd)
 }
 
-func loadDockerCfg(fn string) *configfile.ConfigFile {
+func loadDockerCfg(fn, upstreamFn string) *configfile.ConfigFile {
+	dockerCfg := readDockerCfg(fn)
+	if upstreamFn == "" {
+		return dockerCfg
+	}
+
+	// The credentials of the backing registries take precedence over those of the upstream registries
+	for host, auth := range readDockerCfg(upstreamFn).AuthConfigs {
+		if _, exists := dockerCfg.AuthConfigs[host]; !exists {
+			dockerCfg.AuthConfigs[host] = auth
+		}
+	}
+	return dockerCfg
+}
+
+func readDockerCfg(fn string) *configfile.ConfigFile {
 	if tproot := os.Getenv("TELEPRESENCE_ROOT"); tproot != "" {
 		fn = filepath.Join(tproot, fn)
 	}
@@ -21,6 +21,7 @@ import (
 	"github.com/containerd/containerd/errdefs"
 	"golang.org/x/xerrors"
 
+	blobserve_config "github.com/gitpod-io/gitpod/blobserve/pkg/config"
 	"github.com/gitpod-io/gitpod/common-go/log"
 )
 
@@ -33,11 +34,12 @@ type blobspace interface {
 }
 
 type diskBlobspace struct {
-	Location string
-	MaxSize  int64
+	Location       string
+	MaxSize        int64
+	EvictionPolicy blobserve_config.EvictionPolicy
 }
 
-func newBlobSpace(loc string, maxSize int64, housekeepingInterval time.Duration) (bs *diskBlobspace, err error) {
+func newBlobSpace(loc string, maxSize int64, evictionPolicy blobserve_config.EvictionPolicy, housekeepingInterval time.Duration) (bs *diskBlobspace, err error) {
 	if tproot := os.Getenv("TELEPRESENCE_ROOT"); tproot != "" {
 		loc = filepath.Join(tproot, loc)
 	}
@@ -48,8 +50,9 @@ func newBlobSpace(loc string, maxSize int64, housekeepingInterval time.Duration)
 	}
 
 	bs = &diskBlobspace{
-		Location: loc,
-		MaxSize:  maxSize,
+		Location:       loc,
+		MaxSize:        maxSize,
+		EvictionPolicy: evictionPolicy,
 	}
 	if maxSize > 0 {
 		go bs.collectGarbage(housekeepingInterval)
@@ -111,8 +114,7 @@ func (b *diskBlobspace) collectGarbage(interval time.Duration) {
 
 		var spaceFreed int64
 		if totalSize > b.MaxSize {
-			// oldest first
-			sort.Slice(blobs, func(i, j int) bool { return blobs[j].LastUsed.After(blobs[i].LastUsed) })
+			sortForEviction(blobs, b.EvictionPolicy)
 
 			for totalSize > b.MaxSize && len(blobs) > 0 {
 				blob := blobs[0]
@@ -141,9 +143,21 @@ func (b *diskBlobspace) collectGarbage(interval time.Duration) {
 type gcBlob struct {
 	F        string
 	LastUsed time.Time
+	Added    time.Time
 	Size     int64
 }
 
+// sortForEviction sorts the blobs so that those to remove first come first
+func sortForEviction(blobs []gcBlob, policy blobserve_config.EvictionPolicy) {
+	switch policy {
+	case blobserve_config.EvictionPolicyFIFO:
+		sort.Slice(blobs, func(i, j int) bool { return blobs[j].Added.After(blobs[i].Added) })
+	default:
+		// least recently used first
+		sort.Slice(blobs, func(i, j int) bool { return blobs[j].LastUsed.After(blobs[i].LastUsed) })
+	}
+}
+
 func getGCBlob(wd string, f os.DirEntry) (blob gcBlob) {
 	finfo, _ := f.Info()
 
@@ -153,9 +167,13 @@ func getGCBlob(wd string, f os.DirEntry) (blob gcBlob) {
 		LastUsed: finfo.ModTime(),
 		Size:     0,
 	}
-	if _, err := os.Stat(fmt.Sprintf("%s.ready", fn)); os.IsNotExist(err) {
+	ready, err := os.Stat(fmt.Sprintf("%s.ready", fn))
+	if os.IsNotExist(err) {
 		return
 	}
+	if err == nil {
+		blob.Added = ready.ModTime()
+	}
 
 	if rawSize, err := os.ReadFile(fmt.Sprintf("%s.size", fn)); err == nil {
 		if size, err := strconv.ParseInt(string(rawSize), 10, 64); err == nil {
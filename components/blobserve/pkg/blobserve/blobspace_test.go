@@ -12,6 +12,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	blobserve_config "github.com/gitpod-io/gitpod/blobserve/pkg/config"
 	"github.com/google/go-cmp/cmp"
@@ -78,6 +79,54 @@ func Test_diskBlobspace_modifyFile(t *testing.T) {
 	}
 }
 
+func Test_sortForEviction(t *testing.T) {
+	now := time.Now()
+	blobs := func() []gcBlob {
+		return []gcBlob{
+			{F: "recently-added", Added: now.Add(-1 * time.Hour), LastUsed: now.Add(-3 * time.Hour)},
+			{F: "recently-used", Added: now.Add(-3 * time.Hour), LastUsed: now.Add(-1 * time.Minute)},
+			{F: "oldest", Added: now.Add(-5 * time.Hour), LastUsed: now.Add(-4 * time.Hour)},
+		}
+	}
+	names := func(blobs []gcBlob) []string {
+		res := make([]string, 0, len(blobs))
+		for _, b := range blobs {
+			res = append(res, b.F)
+		}
+		return res
+	}
+
+	tests := []struct {
+		Name     string
+		Policy   blobserve_config.EvictionPolicy
+		Expected []string
+	}{
+		{
+			Name:     "default",
+			Expected: []string{"oldest", "recently-added", "recently-used"},
+		},
+		{
+			Name:     "lru",
+			Policy:   blobserve_config.EvictionPolicyLRU,
+			Expected: []string{"oldest", "recently-added", "recently-used"},
+		},
+		{
+			Name:     "fifo",
+			Policy:   blobserve_config.EvictionPolicyFIFO,
+			Expected: []string{"oldest", "recently-used", "recently-added"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			act := blobs()
+			sortForEviction(act, tt.Policy)
+			if diff := cmp.Diff(tt.Expected, names(act)); diff != "" {
+				t.Errorf("sortForEviction() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func Test_modifySearchAndReplace(t *testing.T) {
 	type args struct {
 		Search  string
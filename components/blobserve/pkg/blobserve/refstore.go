@@ -55,7 +55,7 @@ type refstore struct {
 }
 
 func newRefStore(cfg blobserve_config.BlobServe, resolver ResolverProvider) (*refstore, error) {
-	bs, err := newBlobSpace(cfg.BlobSpace.Location, cfg.BlobSpace.MaxSize, 10*time.Minute)
+	bs, err := newBlobSpace(cfg.BlobSpace.Location, cfg.BlobSpace.MaxSize, cfg.BlobSpace.EvictionPolicy, 10*time.Minute)
 	if err != nil {
 		return nil, err
 	}
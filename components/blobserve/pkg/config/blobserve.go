@@ -38,4 +38,16 @@ type Repo struct {
 type BlobSpace struct {
 	Location string `json:"location"`
 	MaxSize  int64  `json:"maxSizeBytes,omitempty"`
+
+	// EvictionPolicy decides which blobs are removed first once the blobspace exceeds MaxSize
+	EvictionPolicy EvictionPolicy `json:"evictionPolicy,omitempty"`
 }
+
+type EvictionPolicy string
+
+const (
+	// EvictionPolicyLRU removes the least recently used blobs first. This is the default.
+	EvictionPolicyLRU EvictionPolicy = "lru"
+	// EvictionPolicyFIFO removes the blobs that were added first, regardless of their use.
+	EvictionPolicyFIFO EvictionPolicy = "fifo"
+)
@@ -16,6 +16,10 @@ type Config struct {
 	PProfAddr          string    `json:"pprofAddr"`
 	PrometheusAddr     string    `json:"prometheusAddr"`
 	ReadinessProbeAddr string    `json:"readinessProbeAddr"`
+
+	// UpstreamAuthCfg is a Docker auth config with the credentials of the upstream registries,
+	// e.g. Docker Hub. The credentials in AuthCfg take precedence.
+	UpstreamAuthCfg string `json:"upstreamDockerAuth,omitempty"`
 }
 
 // getConfig loads and validates the configuration
@@ -45,7 +45,15 @@ func NewFromEnvironment() Writer {
 		return &logAnalyticsWriter{}
 	case "segment":
 		log.Debug("segment analytics")
-		return &segmentAnalyticsWriter{Client: segment.New(os.Getenv("GITPOD_ANALYTICS_SEGMENT_KEY"))}
+		// The endpoint defaults to Segment's API
+		client, err := segment.NewWithConfig(os.Getenv("GITPOD_ANALYTICS_SEGMENT_KEY"), segment.Config{
+			Endpoint: os.Getenv("GITPOD_ANALYTICS_SEGMENT_ENDPOINT"),
+		})
+		if err != nil {
+			log.WithError(err).Error("cannot create segment analytics, falling back to no analytics")
+			return &noAnalyticsWriter{}
+		}
+		return &segmentAnalyticsWriter{Client: client}
 	default:
 		log.Debug("no analytics")
 		return &noAnalyticsWriter{}
@@ -6,6 +6,14 @@ package baseserver
 
 type Configuration struct {
 	Services ServicesConfiguration `json:"services" yaml:"services"`
+	// Builtin configures the builtin debug and metrics servers
+	Builtin *BuiltinConfiguration `json:"builtin,omitempty" yaml:"builtin,omitempty"`
+}
+
+type BuiltinConfiguration struct {
+	// TLS serves the debug and metrics endpoints over TLS. The health endpoints are kept on
+	// plain HTTP for the probes of the kubelet.
+	TLS *TLSConfiguration `json:"tls,omitempty" yaml:"tls,omitempty"`
 }
 
 type ServicesConfiguration struct {
@@ -95,6 +95,17 @@ func WithGRPC(cfg *ServerConfiguration) Option {
 	}
 }
 
+// WithBuiltinTLS serves the builtin debug and metrics servers over TLS.
+func WithBuiltinTLS(cfg *TLSConfiguration) Option {
+	return func(opts *options) error {
+		if opts.config.Builtin == nil {
+			opts.config.Builtin = &BuiltinConfiguration{}
+		}
+		opts.config.Builtin.TLS = cfg
+		return nil
+	}
+}
+
 func WithLogger(logger *logrus.Entry) Option {
 	return func(opts *options) error {
 		if logger == nil {
@@ -23,10 +23,12 @@ func TestOptions(t *testing.T) {
 	grpcHealthService := &grpc_health_v1.UnimplementedHealthServer{}
 	httpCfg := ServerConfiguration{Address: "localhost:8080"}
 	grpcCfg := ServerConfiguration{Address: "localhost:8081"}
+	builtinTLS := TLSConfiguration{CertPath: "/certs/tls.crt", KeyPath: "/certs/tls.key"}
 
 	var opts = []Option{
 		WithHTTP(&httpCfg),
 		WithGRPC(&grpcCfg),
+		WithBuiltinTLS(&builtinTLS),
 		WithLogger(logger),
 		WithCloseTimeout(timeout),
 		WithMetricsRegistry(registry),
@@ -44,6 +46,7 @@ func TestOptions(t *testing.T) {
 				GRPC: &grpcCfg,
 				HTTP: &httpCfg,
 			},
+			Builtin: &BuiltinConfiguration{TLS: &builtinTLS},
 		},
 		closeTimeout:    timeout,
 		metricsRegistry: registry,
@@ -357,6 +357,9 @@ func (s *Server) DebugAddress() string {
 	if s.builtinServices == nil {
 		return ""
 	}
+	if s.builtinServices.tls != nil {
+		return "https://" + s.builtinServices.Debug.Addr
+	}
 	return "http://" + s.builtinServices.Debug.Addr
 }
 func (s *Server) HealthAddr() string {
@@ -381,6 +384,9 @@ const (
 type builtinServices struct {
 	underTest bool
 
+	// tls serves the debug and metrics servers over TLS if set
+	tls *TLSConfiguration
+
 	Debug   *http.Server
 	Health  *http.Server
 	Metrics *http.Server
@@ -392,8 +398,14 @@ func newBuiltinServices(server *Server) *builtinServices {
 		healthAddr = ":0"
 	}
 
+	var tls *TLSConfiguration
+	if cfg := server.options.config.Builtin; cfg != nil {
+		tls = cfg.TLS
+	}
+
 	return &builtinServices{
 		underTest: server.options.underTest,
+		tls:       tls,
 		Debug: &http.Server{
 			Addr:    fmt.Sprintf(":%d", BuiltinDebugPort),
 			Handler: pprof.Handler(),
@@ -416,8 +428,8 @@ func (s *builtinServices) ListenAndServe() error {
 
 	var eg errgroup.Group
 	if !s.underTest {
-		eg.Go(func() error { return s.Debug.ListenAndServe() })
-		eg.Go(func() error { return s.Metrics.ListenAndServe() })
+		eg.Go(func() error { return s.listenAndServe(s.Debug) })
+		eg.Go(func() error { return s.listenAndServe(s.Metrics) })
 	}
 	eg.Go(func() error {
 		// health is the only service which has a variable address,
@@ -437,6 +449,13 @@ func (s *builtinServices) ListenAndServe() error {
 	return eg.Wait()
 }
 
+func (s *builtinServices) listenAndServe(srv *http.Server) error {
+	if s.tls == nil {
+		return srv.ListenAndServe()
+	}
+	return srv.ListenAndServeTLS(s.tls.CertPath, s.tls.KeyPath)
+}
+
 func (s *builtinServices) Close() error {
 	var eg errgroup.Group
 	eg.Go(func() error { return s.Debug.Close() })
@@ -0,0 +1,86 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package grpc
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/gitpod-io/gitpod/common-go/util"
+)
+
+// ClientConfig overrides the defaults of the connections of a client
+type ClientConfig struct {
+	// DialTimeout is how long the client tries to connect before it gives up
+	DialTimeout util.Duration `json:"dialTimeout,omitempty"`
+	// MaxMsgSize is the size of the largest message the client receives in bytes, defaults to 16MB
+	MaxMsgSize int `json:"maxMsgSize,omitempty"`
+	// Retry retries the calls that fail as the server is unavailable
+	Retry *RetryPolicy `json:"retry,omitempty"`
+}
+
+// RetryPolicy is the retry policy of the gRPC service config
+type RetryPolicy struct {
+	MaxAttempts       int           `json:"maxAttempts"`
+	InitialBackoff    util.Duration `json:"initialBackoff"`
+	MaxBackoff        util.Duration `json:"maxBackoff"`
+	BackoffMultiplier float64       `json:"backoffMultiplier,omitempty"`
+}
+
+// ClientOptions returns the default client connection options with the overrides of the config
+func ClientOptions(cfg *ClientConfig) []grpc.DialOption {
+	res := DefaultClientOptions()
+	if cfg == nil {
+		return res
+	}
+
+	if cfg.MaxMsgSize > 0 {
+		res = append(res, grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(cfg.MaxMsgSize)))
+	}
+	if cfg.Retry != nil {
+		res = append(res, grpc.WithDefaultServiceConfig(retryServiceConfig(cfg.Retry)))
+	}
+	return res
+}
+
+// DialTimeoutOrDefault returns the dial timeout of the config, or the default if it sets none
+func (cfg *ClientConfig) DialTimeoutOrDefault(def time.Duration) time.Duration {
+	if cfg == nil || cfg.DialTimeout <= 0 {
+		return def
+	}
+	return time.Duration(cfg.DialTimeout)
+}
+
+func retryServiceConfig(p *RetryPolicy) string {
+	multiplier := p.BackoffMultiplier
+	if multiplier <= 0 {
+		multiplier = 2
+	}
+
+	// An empty name applies the policy to all methods of all services
+	fc, _ := json.Marshal(map[string]interface{}{
+		"methodConfig": []interface{}{
+			map[string]interface{}{
+				"name": []interface{}{map[string]interface{}{}},
+				"retryPolicy": map[string]interface{}{
+					"maxAttempts":          p.MaxAttempts,
+					"initialBackoff":       durationString(time.Duration(p.InitialBackoff)),
+					"maxBackoff":           durationString(time.Duration(p.MaxBackoff)),
+					"backoffMultiplier":    multiplier,
+					"retryableStatusCodes": []string{"UNAVAILABLE"},
+				},
+			},
+		},
+	})
+	return string(fc)
+}
+
+// durationString formats a duration as the seconds with an s suffix the service config expects
+func durationString(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package grpc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/gitpod-io/gitpod/common-go/util"
+)
+
+func TestRetryServiceConfig(t *testing.T) {
+	policy := &RetryPolicy{
+		MaxAttempts:    4,
+		InitialBackoff: util.Duration(100 * time.Millisecond),
+		MaxBackoff:     util.Duration(90 * time.Second),
+	}
+
+	expectation := `{"methodConfig":[{"name":[{}],"retryPolicy":{"backoffMultiplier":2,"initialBackoff":"0.1s","maxAttempts":4,"maxBackoff":"90s","retryableStatusCodes":["UNAVAILABLE"]}}]}`
+	if diff := cmp.Diff(expectation, retryServiceConfig(policy)); diff != "" {
+		t.Errorf("unexpected service config (-want +got):\n%s", diff)
+	}
+
+	// the service config is parsed when the connection is created
+	conn, err := grpc.Dial("localhost:0",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultServiceConfig(retryServiceConfig(policy)),
+	)
+	if err != nil {
+		t.Fatalf("invalid service config: %v", err)
+	}
+	conn.Close()
+}
+
+func TestDialTimeoutOrDefault(t *testing.T) {
+	var unset *ClientConfig
+	if got := unset.DialTimeoutOrDefault(time.Second); got != time.Second {
+		t.Errorf("expected the default for a missing config, got %v", got)
+	}
+	cfg := &ClientConfig{DialTimeout: util.Duration(time.Minute)}
+	if got := cfg.DialTimeoutOrDefault(time.Second); got != time.Minute {
+		t.Errorf("expected the configured timeout, got %v", got)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"os"
 	"path"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
@@ -51,6 +52,11 @@ func Init(service, version string, json, verbose bool) {
 	Log = log.WithFields(ServiceContext(service, version))
 	log.SetReportCaller(true)
 
+	// JSON_LOG lets the installer choose the log format without changing the command line
+	if v, err := strconv.ParseBool(os.Getenv("JSON_LOG")); err == nil {
+		json = v
+	}
+
 	if json {
 		Log.Logger.SetFormatter(&gcpFormatter{
 			log.JSONFormatter{
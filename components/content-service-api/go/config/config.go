@@ -114,6 +114,15 @@ type S3Config struct {
 	Bucket          string `json:"bucket"`
 	Region          string `json:"region"`
 	CredentialsFile string `json:"credentialsFile"`
+	ParallelUpload  uint   `json:"parallelUpload,omitempty"`
+
+	// UsePathStyle addresses the bucket in the path of the URLs rather than in their host
+	UsePathStyle bool `json:"usePathStyle,omitempty"`
+	// SSEKMSKeyID is the AWS KMS key the objects are encrypted with
+	SSEKMSKeyID string `json:"sseKmsKeyId,omitempty"`
+	// BucketPerOwner stores the objects of each owner in a bucket of their own, which is named
+	// Bucket followed by the ID of the owner. The buckets are created when they are needed.
+	BucketPerOwner bool `json:"bucketPerOwner,omitempty"`
 }
 
 type PProf struct {
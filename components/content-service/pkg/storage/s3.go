@@ -34,6 +34,89 @@ var _ PresignedAccess = &PresignedS3Storage{}
 
 type S3Config struct {
 	Bucket string
+	// Region is the region the buckets of the owners are created in
+	Region string
+	// ParallelUpload is the number of parts uploaded at the same time, defaults to defaultCopyConcurrency
+	ParallelUpload int
+	// SSEKMSKeyID is the AWS KMS key the uploaded objects and the buckets of the owners are encrypted with
+	SSEKMSKeyID string
+	// BucketPerOwner stores the objects of each owner in a bucket named Bucket followed by the ID of the owner
+	BucketPerOwner bool
+}
+
+// bucket returns the bucket of the objects of the owner
+func (c S3Config) bucket(ownerID string) string {
+	if c.BucketPerOwner {
+		return c.Bucket + ownerID
+	}
+	return c.Bucket
+}
+
+// resolve returns the bucket a request for the bucket goes to. Without a bucket per owner, all
+// objects are in the configured bucket.
+func (c S3Config) resolve(bucket string) string {
+	if c.BucketPerOwner {
+		return bucket
+	}
+	return c.Bucket
+}
+
+// s3BucketClient is implemented by the S3 client to create the buckets of the owners
+type s3BucketClient interface {
+	HeadBucket(ctx context.Context, params *s3.HeadBucketInput, optFns ...func(*s3.Options)) (*s3.HeadBucketOutput, error)
+	CreateBucket(ctx context.Context, params *s3.CreateBucketInput, optFns ...func(*s3.Options)) (*s3.CreateBucketOutput, error)
+	PutBucketEncryption(ctx context.Context, params *s3.PutBucketEncryptionInput, optFns ...func(*s3.Options)) (*s3.PutBucketEncryptionOutput, error)
+}
+
+// ensureS3Bucket creates the bucket if it does not exist, encrypted with the KMS key of the config
+func ensureS3Bucket(ctx context.Context, client S3Client, config S3Config, bucket string) error {
+	bc, ok := client.(s3BucketClient)
+	if !ok {
+		return xerrors.Errorf("can only create buckets with actual S3 client")
+	}
+
+	_, err := bc.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(bucket)})
+	if err == nil {
+		return nil
+	}
+	var nf *types.NotFound
+	if !errors.As(err, &nf) {
+		return xerrors.Errorf("cannot check bucket %s: %w", bucket, err)
+	}
+
+	input := &s3.CreateBucketInput{Bucket: aws.String(bucket)}
+	// us-east-1 is the default location and must not be set as constraint
+	if config.Region != "" && config.Region != "us-east-1" {
+		input.CreateBucketConfiguration = &types.CreateBucketConfiguration{
+			LocationConstraint: types.BucketLocationConstraint(config.Region),
+		}
+	}
+	_, err = bc.CreateBucket(ctx, input)
+	var owned *types.BucketAlreadyOwnedByYou
+	if err != nil && !errors.As(err, &owned) {
+		return xerrors.Errorf("cannot create bucket %s: %w", bucket, err)
+	}
+
+	if config.SSEKMSKeyID == "" {
+		return nil
+	}
+	// objects uploaded with presigned URLs don't carry the encryption headers
+	_, err = bc.PutBucketEncryption(ctx, &s3.PutBucketEncryptionInput{
+		Bucket: aws.String(bucket),
+		ServerSideEncryptionConfiguration: &types.ServerSideEncryptionConfiguration{
+			Rules: []types.ServerSideEncryptionRule{{
+				ApplyServerSideEncryptionByDefault: &types.ServerSideEncryptionByDefault{
+					SSEAlgorithm:   types.ServerSideEncryptionAwsKms,
+					KMSMasterKeyID: aws.String(config.SSEKMSKeyID),
+				},
+				BucketKeyEnabled: true,
+			}},
+		},
+	})
+	if err != nil {
+		return xerrors.Errorf("cannot set encryption of bucket %s: %w", bucket, err)
+	}
+	return nil
 }
 
 type S3Client interface {
@@ -72,7 +155,7 @@ type PresignedS3Storage struct {
 
 // Bucket implements PresignedAccess
 func (rs *PresignedS3Storage) Bucket(userID string) string {
-	return rs.Config.Bucket
+	return rs.Config.bucket(userID)
 }
 
 // BlobObject implements PresignedAccess
@@ -92,12 +175,12 @@ func (rs *PresignedS3Storage) BackupObject(ownerID string, workspaceID string, n
 
 // DeleteBucket implements PresignedAccess
 func (rs *PresignedS3Storage) DeleteBucket(ctx context.Context, userID, bucket string) error {
-	if bucket != rs.Config.Bucket {
-		log.WithField("requestedBucket", bucket).WithField("configuredBucket", rs.Config.Bucket).Error("can only delete from configured bucket")
+	if bucket != rs.Config.bucket(userID) {
+		log.WithField("requestedBucket", bucket).WithField("configuredBucket", rs.Config.bucket(userID)).Error("can only delete from configured bucket")
 		return xerrors.Errorf("can only delete from configured bucket; this looks like a bug in Gitpod")
 	}
 
-	return rs.DeleteObject(ctx, rs.Config.Bucket, &DeleteObjectQuery{Prefix: userID + "/"})
+	return rs.DeleteObject(ctx, bucket, &DeleteObjectQuery{Prefix: userID + "/"})
 }
 
 // DeleteObject implements PresignedAccess
@@ -110,7 +193,7 @@ func (rs *PresignedS3Storage) DeleteObject(ctx context.Context, bucket string, q
 
 	case query.Prefix != "":
 		resp, err := rs.client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
-			Bucket: aws.String(rs.Config.Bucket),
+			Bucket: aws.String(rs.Config.resolve(bucket)),
 			Prefix: aws.String(query.Prefix),
 		})
 		if err != nil {
@@ -128,7 +211,7 @@ func (rs *PresignedS3Storage) DeleteObject(ctx context.Context, bucket string, q
 	}
 
 	_, err := rs.client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
-		Bucket: &rs.Config.Bucket,
+		Bucket: aws.String(rs.Config.resolve(bucket)),
 		Delete: &types.Delete{
 			Objects: objects,
 			Quiet:   true,
@@ -144,7 +227,7 @@ func (rs *PresignedS3Storage) DeleteObject(ctx context.Context, bucket string, q
 // DiskUsage implements PresignedAccess
 func (rs *PresignedS3Storage) DiskUsage(ctx context.Context, bucket string, prefix string) (size int64, err error) {
 	resp, err := rs.client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
-		Bucket: &rs.Config.Bucket,
+		Bucket: aws.String(rs.Config.resolve(bucket)),
 		Prefix: aws.String(prefix),
 	})
 	if err != nil {
@@ -159,7 +242,10 @@ func (rs *PresignedS3Storage) DiskUsage(ctx context.Context, bucket string, pref
 
 // EnsureExists implements PresignedAccess
 func (rs *PresignedS3Storage) EnsureExists(ctx context.Context, bucket string) error {
-	return nil
+	if !rs.Config.BucketPerOwner {
+		return nil
+	}
+	return ensureS3Bucket(ctx, rs.client, rs.Config, bucket)
 }
 
 // InstanceObject implements PresignedAccess
@@ -170,7 +256,7 @@ func (rs *PresignedS3Storage) InstanceObject(ownerID string, workspaceID string,
 // ObjectExists implements PresignedAccess
 func (rs *PresignedS3Storage) ObjectExists(ctx context.Context, bucket string, path string) (bool, error) {
 	_, err := rs.client.GetObjectAttributes(ctx, &s3.GetObjectAttributesInput{
-		Bucket:           &rs.Config.Bucket,
+		Bucket:           aws.String(rs.Config.resolve(bucket)),
 		Key:              aws.String(path),
 		ObjectAttributes: []types.ObjectAttributes{types.ObjectAttributesEtag},
 	})
@@ -189,7 +275,7 @@ func (rs *PresignedS3Storage) ObjectExists(ctx context.Context, bucket string, p
 // ObjectHash implements PresignedAccess
 func (rs *PresignedS3Storage) ObjectHash(ctx context.Context, bucket string, obj string) (string, error) {
 	resp, err := rs.client.GetObjectAttributes(ctx, &s3.GetObjectAttributesInput{
-		Bucket:           &rs.Config.Bucket,
+		Bucket:           aws.String(rs.Config.resolve(bucket)),
 		Key:              aws.String(obj),
 		ObjectAttributes: []types.ObjectAttributes{types.ObjectAttributesEtag},
 	})
@@ -208,7 +294,7 @@ func (rs *PresignedS3Storage) ObjectHash(ctx context.Context, bucket string, obj
 // SignDownload implements PresignedAccess
 func (rs *PresignedS3Storage) SignDownload(ctx context.Context, bucket string, obj string, options *SignedURLOptions) (info *DownloadInfo, err error) {
 	resp, err := rs.client.GetObjectAttributes(ctx, &s3.GetObjectAttributesInput{
-		Bucket:           &rs.Config.Bucket,
+		Bucket:           aws.String(rs.Config.resolve(bucket)),
 		Key:              aws.String(obj),
 		ObjectAttributes: []types.ObjectAttributes{types.ObjectAttributesObjectSize},
 	})
@@ -223,7 +309,7 @@ func (rs *PresignedS3Storage) SignDownload(ctx context.Context, bucket string, o
 	}
 
 	req, err := rs.PresignedFactory().PresignGetObject(ctx, &s3.GetObjectInput{
-		Bucket: aws.String(rs.Config.Bucket),
+		Bucket: aws.String(rs.Config.resolve(bucket)),
 		Key:    aws.String(obj),
 	})
 	if err != nil {
@@ -242,7 +328,7 @@ func (rs *PresignedS3Storage) SignDownload(ctx context.Context, bucket string, o
 // SignUpload implements PresignedAccess
 func (rs *PresignedS3Storage) SignUpload(ctx context.Context, bucket string, obj string, options *SignedURLOptions) (info *UploadInfo, err error) {
 	resp, err := rs.PresignedFactory().PresignPutObject(ctx, &s3.PutObjectInput{
-		Bucket: &rs.Config.Bucket,
+		Bucket: aws.String(rs.Config.resolve(bucket)),
 		Key:    aws.String(obj),
 	})
 	if err != nil {
@@ -271,7 +357,7 @@ type s3Storage struct {
 
 // Bucket implements DirectAccess
 func (s3st *s3Storage) Bucket(userID string) string {
-	return s3st.Config.Bucket
+	return s3st.Config.bucket(userID)
 }
 
 // BackupObject implements DirectAccess
@@ -303,7 +389,7 @@ func (s3st *s3Storage) download(ctx context.Context, destination string, obj str
 	defer os.Remove(s3File.Name())
 
 	_, err = downloader.Download(ctx, s3File, &s3.GetObjectInput{
-		Bucket: aws.String(s3st.Config.Bucket),
+		Bucket: aws.String(s3st.Bucket(s3st.OwnerID)),
 		Key:    aws.String(obj),
 	})
 	if err != nil {
@@ -324,8 +410,11 @@ func (s3st *s3Storage) download(ctx context.Context, destination string, obj str
 }
 
 // EnsureExists implements DirectAccess
-func (*s3Storage) EnsureExists(ctx context.Context) error {
-	return nil
+func (s3st *s3Storage) EnsureExists(ctx context.Context) error {
+	if !s3st.Config.BucketPerOwner {
+		return nil
+	}
+	return ensureS3Bucket(ctx, s3st.client, s3st.Config, s3st.Bucket(s3st.OwnerID))
 }
 
 // Init implements DirectAccess
@@ -344,7 +433,7 @@ func (s3st *s3Storage) ListObjects(ctx context.Context, prefix string) ([]string
 
 	var res []string
 	listParams := &s3.ListObjectsV2Input{
-		Bucket: aws.String(s3st.Config.Bucket),
+		Bucket: aws.String(s3st.Bucket(s3st.OwnerID)),
 		Prefix: aws.String(prefix),
 	}
 	fetchObjects := true
@@ -368,7 +457,7 @@ func (s3st *s3Storage) ListObjects(ctx context.Context, prefix string) ([]string
 
 // Qualify implements DirectAccess
 func (s3st *s3Storage) Qualify(name string) string {
-	return fmt.Sprintf("%s@%s", s3st.objectName(name), s3st.Config.Bucket)
+	return fmt.Sprintf("%s@%s", s3st.objectName(name), s3st.Bucket(s3st.OwnerID))
 }
 
 func (s3st *s3Storage) objectName(name string) string {
@@ -403,7 +492,16 @@ func (s3st *s3Storage) Upload(ctx context.Context, source string, name string, o
 		contentType = aws.String(options.ContentType)
 	}
 
-	bucket = s3st.Config.Bucket
+	var (
+		sse         types.ServerSideEncryption
+		sseKMSKeyID *string
+	)
+	if s3st.Config.SSEKMSKeyID != "" {
+		sse = types.ServerSideEncryptionAwsKms
+		sseKMSKeyID = aws.String(s3st.Config.SSEKMSKeyID)
+	}
+
+	bucket = s3st.Bucket(s3st.OwnerID)
 	obj = s3st.objectName(name)
 
 	s3c, ok := s3st.client.(*s3.Client)
@@ -411,8 +509,12 @@ func (s3st *s3Storage) Upload(ctx context.Context, source string, name string, o
 		err = xerrors.Errorf("Can only upload with actual S3 client")
 	}
 
+	concurrency := defaultCopyConcurrency
+	if s3st.Config.ParallelUpload > 0 {
+		concurrency = s3st.Config.ParallelUpload
+	}
 	uploader := s3manager.NewUploader(s3c, func(u *s3manager.Uploader) {
-		u.Concurrency = defaultCopyConcurrency
+		u.Concurrency = concurrency
 		u.PartSize = defaultPartSize * megabytes
 		u.BufferProvider = s3manager.NewBufferedReadSeekerWriteToPool(25 * megabytes)
 	})
@@ -426,6 +528,9 @@ func (s3st *s3Storage) Upload(ctx context.Context, source string, name string, o
 
 		Metadata:    options.Annotations,
 		ContentType: contentType,
+
+		ServerSideEncryption: sse,
+		SSEKMSKeyId:          sseKMSKeyID,
 	})
 	if err != nil {
 		return
@@ -61,3 +61,29 @@ func TestS3PresignedHappyPath(t *testing.T) {
 
 	SuiteTestPresignedAccess(t, ps)
 }
+
+func TestS3PresignedBucketPerOwner(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s3c := mock.NewMockS3Client(ctrl)
+	s3c.EXPECT().GetObjectAttributes(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, params *s3.GetObjectAttributesInput, optFns ...func(*s3.Options)) (*s3.GetObjectAttributesOutput, error) {
+		if *params.Bucket != "gitpod-owner" {
+			t.Errorf("object requested from bucket %s, expected gitpod-owner", *params.Bucket)
+		}
+		return &s3.GetObjectAttributesOutput{ETag: aws.String("foobar")}, nil
+	})
+
+	dut := storage.NewPresignedS3Access(s3c, storage.S3Config{Bucket: "gitpod-", BucketPerOwner: true})
+
+	bucket := dut.Bucket("owner")
+	if bucket != "gitpod-owner" {
+		t.Fatalf("bucket of owner is %s, expected gitpod-owner", bucket)
+	}
+	if _, err := dut.ObjectExists(context.Background(), bucket, "owner/workspaces/ws/full.tar"); err != nil {
+		t.Fatal(err)
+	}
+	if err := dut.DeleteBucket(context.Background(), "other", bucket); err == nil {
+		t.Error("deleted the bucket of another owner")
+	}
+}
@@ -234,8 +234,12 @@ func NewDirectAccess(c *config.StorageConfig) (DirectAccess, error) {
 			return nil, err
 		}
 
-		return newDirectS3Access(s3.NewFromConfig(*cfg), S3Config{
-			Bucket: c.S3Config.Bucket,
+		return newDirectS3Access(newS3Client(cfg, c.S3Config), S3Config{
+			Bucket:         c.S3Config.Bucket,
+			Region:         c.S3Config.Region,
+			ParallelUpload: int(c.S3Config.ParallelUpload),
+			SSEKMSKeyID:    c.S3Config.SSEKMSKeyID,
+			BucketPerOwner: c.S3Config.BucketPerOwner,
 		}), nil
 	default:
 		return &DirectNoopStorage{}, nil
@@ -260,8 +264,11 @@ func NewPresignedAccess(c *config.StorageConfig) (PresignedAccess, error) {
 			return nil, err
 		}
 
-		return NewPresignedS3Access(s3.NewFromConfig(*cfg), S3Config{
-			Bucket: c.S3Config.Bucket,
+		return NewPresignedS3Access(newS3Client(cfg, c.S3Config), S3Config{
+			Bucket:         c.S3Config.Bucket,
+			Region:         c.S3Config.Region,
+			SSEKMSKeyID:    c.S3Config.SSEKMSKeyID,
+			BucketPerOwner: c.S3Config.BucketPerOwner,
 		}), nil
 	default:
 		log.Warnf("falling back to noop presigned storage access. Is this intentional? (storage kind: %s)", c.Kind)
@@ -287,6 +294,12 @@ func loadAwsConfig(s3config *config.S3Config) (*aws.Config, error) {
 	return &cfg, nil
 }
 
+func newS3Client(cfg *aws.Config, s3config *config.S3Config) *s3.Client {
+	return s3.NewFromConfig(*cfg, func(o *s3.Options) {
+		o.UsePathStyle = s3config.UsePathStyle
+	})
+}
+
 func extractTarbal(ctx context.Context, dest string, src io.Reader, mappings []archive.IDMapping) error {
 	err := archive.ExtractTarbal(ctx, src, dest, archive.WithUIDMapping(mappings), archive.WithGIDMapping(mappings))
 	if err != nil {
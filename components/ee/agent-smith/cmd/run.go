@@ -79,10 +79,10 @@ var runCmd = &cobra.Command{
 					err = nil
 
 					if i.Kind.Severity() == common.SeverityAudit {
-						err = notifySlack(cfg.SlackWebhooks.Audit, cfg.Config.GitpodAPI.HostURL, violation, penalties)
+						err = notifySlack(cfg.SlackWebhooks.Audit, cfg.Config.GitpodAPI.HostURL, violation, penalties, cfg.Enforcement.AuditOnly)
 						break
 					} else if i.Kind.Severity() != common.SeverityBarely {
-						err = notifySlack(cfg.SlackWebhooks.Warning, cfg.Config.GitpodAPI.HostURL, violation, penalties)
+						err = notifySlack(cfg.SlackWebhooks.Warning, cfg.Config.GitpodAPI.HostURL, violation, penalties, cfg.Enforcement.AuditOnly)
 						break
 					}
 				}
@@ -132,7 +132,7 @@ func startMemoryWatchdog(maxSysMemMib uint64) {
 	}
 }
 
-func notifySlack(webhook string, hostURL string, ws agent.InfringingWorkspace, penalties []config.PenaltyKind) error {
+func notifySlack(webhook string, hostURL string, ws agent.InfringingWorkspace, penalties []config.PenaltyKind, auditOnly bool) error {
 	var (
 		region           = os.Getenv("GITPOD_REGION")
 		lblDetails       = "Details"
@@ -153,9 +153,13 @@ func notifySlack(webhook string, hostURL string, ws agent.InfringingWorkspace, p
 		},
 	}
 	if len(penalties) > 0 {
+		title := "enforced"
+		if auditOnly {
+			title = "not enforced (audit only)"
+		}
 		vs := make([]*slack.Field, len(penalties))
 		for i, p := range penalties {
-			vs[i] = &slack.Field{Title: "enforced", Value: string(p)}
+			vs[i] = &slack.Field{Title: title, Value: string(p)}
 		}
 		attachments = append(attachments, slack.Attachment{
 			Title:  &lblPenalties,
@@ -275,7 +275,7 @@ func (agent *Smith) Start(ctx context.Context, callback func(InfringingWorkspace
 				continue
 			}
 
-			_, _ = agent.Penalize(InfringingWorkspace{
+			ws := InfringingWorkspace{
 				SupervisorPID: proc.Workspace.PID,
 				Owner:         proc.Workspace.OwnerID,
 				InstanceID:    proc.Workspace.InstanceID,
@@ -287,7 +287,15 @@ func (agent *Smith) Start(ctx context.Context, callback func(InfringingWorkspace
 						CommandLine: proc.CommandLine,
 					},
 				},
-			})
+			}
+			penalties, _ := agent.Penalize(ws)
+
+			// A workspace keeps infringing until the penalty takes effect, which must not flood the notifications
+			key := fmt.Sprintf("%s/%s", ws.InstanceID, ws.Infringements[0].Kind)
+			if _, notified := agent.notifiedInfringements.Get(key); !notified {
+				agent.notifiedInfringements.Add(key, struct{}{})
+				callback(ws, penalties)
+			}
 		}
 	}
 }
@@ -302,6 +310,12 @@ func (agent *Smith) Penalize(ws InfringingWorkspace) ([]config.PenaltyKind, erro
 	owi := log.OWI(ws.Owner, ws.WorkspaceID, ws.InstanceID)
 
 	penalty := getPenalty(agent.EnforcementRules[defaultRuleset], agent.EnforcementRules[remoteURL], ws.Infringements)
+	if agent.Config.Enforcement.AuditOnly {
+		if len(penalty) > 0 {
+			log.WithField("infringement", ws.Infringements).WithField("penalties", penalty).WithFields(owi).Info("audit only, not applying penalties")
+		}
+		return penalty, nil
+	}
 	for _, p := range penalty {
 		switch p {
 		case config.PenaltyStopWorkspace:
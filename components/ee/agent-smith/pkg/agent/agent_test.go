@@ -5,6 +5,7 @@
 package agent
 
 import (
+	"math"
 	"sort"
 	"testing"
 
@@ -54,6 +55,24 @@ func TestGetPenalty(t *testing.T) {
 	}
 }
 
+func TestPenalizeAuditOnly(t *testing.T) {
+	kind := config.GradeKind(config.InfringementExec, common.SeverityVery)
+	agent := &Smith{
+		Config:           config.Config{Enforcement: config.Enforcement{AuditOnly: true}},
+		EnforcementRules: map[string]config.EnforcementRules{defaultRuleset: {kind: config.PenaltyStopWorkspace}},
+		metrics:          newAgentMetrics(),
+	}
+
+	// There is no process with that PID, stopping the workspace would fail
+	penalties, err := agent.Penalize(InfringingWorkspace{SupervisorPID: math.MaxInt32, Infringements: []Infringement{{Kind: kind}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]config.PenaltyKind{config.PenaltyStopWorkspace}, penalties); diff != "" {
+		t.Errorf("unexpected penalties (-want +got):\n%s", diff)
+	}
+}
+
 func TestFindEnforcementRules(t *testing.T) {
 	ra := config.EnforcementRules{config.GradeKind(config.InfringementExec, common.SeverityAudit): config.PenaltyLimitCPU}
 	tests := []struct {
@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"os"
 	"strings"
 
 	"github.com/gitpod-io/gitpod/agent-smith/pkg/classifier"
@@ -31,6 +32,13 @@ func GetConfig(cfgFile string) (*ServiceConfig, error) {
 		return nil, xerrors.Errorf("cannot unmarshal config: %v", err)
 	}
 
+	if cfg.SlackWebhooks != nil {
+		err = cfg.SlackWebhooks.readFiles()
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if cfg.ProbePath == "" {
 		cfg.ProbePath = "/app/probe.o"
 	}
@@ -60,6 +68,8 @@ type Enforcement struct {
 	Default         *EnforcementRules           `json:"default,omitempty"`
 	PerRepo         map[string]EnforcementRules `json:"perRepo,omitempty"`
 	CPULimitPenalty string                      `json:"cpuLimitPenalty,omitempty"`
+	// AuditOnly reports the penalties instead of applying them
+	AuditOnly bool `json:"auditOnly,omitempty"`
 }
 
 // EnforcementRules matches a infringement with a particular penalty
@@ -182,6 +192,35 @@ type Config struct {
 type SlackWebhooks struct {
 	Audit   string `json:"audit,omitempty"`
 	Warning string `json:"warning,omitempty"`
+
+	// AuditFile and WarningFile contain the webhooks, they take precedence over Audit and Warning
+	AuditFile   string `json:"auditFile,omitempty"`
+	WarningFile string `json:"warningFile,omitempty"`
+}
+
+// readFiles reads the webhooks from their files
+func (s *SlackWebhooks) readFiles() error {
+	for _, f := range []struct {
+		File    string
+		Webhook *string
+	}{
+		{s.AuditFile, &s.Audit},
+		{s.WarningFile, &s.Warning},
+	} {
+		if f.File == "" {
+			continue
+		}
+		webhook, err := ioutil.ReadFile(f.File)
+		if os.IsNotExist(err) {
+			// The secret the files come from need not have both webhooks
+			continue
+		}
+		if err != nil {
+			return xerrors.Errorf("cannot read slack webhook: %w", err)
+		}
+		*f.Webhook = strings.TrimSpace(string(webhook))
+	}
+	return nil
 }
 
 // Blocklists list s/signature blocklists for various levels of infringement
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/gitpod-io/gitpod/common-go/log"
@@ -21,21 +22,41 @@ import (
 	"gorm.io/plugin/opentelemetry/tracing"
 )
 
+const (
+	// SSLModeRequired connects with TLS, verifying the server certificate against the system roots
+	// unless a CA certificate is set
+	SSLModeRequired = "required"
+	// SSLModeSkipVerify connects with TLS, without verifying the server certificate
+	SSLModeSkipVerify = "skip-verify"
+)
+
 type ConnectionParams struct {
 	User     string
 	Password string
 	Host     string
 	Database string
 	CaCert   string
+	SSLMode  string
+
+	// MaxConnections limits the open connections, unlimited when zero
+	MaxConnections int
+	// IdleTimeout closes the connections that are idle for longer, never when zero
+	IdleTimeout time.Duration
 }
 
 func ConnectionParamsFromEnv() ConnectionParams {
+	maxConnections, _ := strconv.Atoi(os.Getenv("DB_MAX_CONNECTIONS"))
+	idleTimeout, _ := strconv.Atoi(os.Getenv("DB_IDLE_TIMEOUT_SECONDS"))
+
 	return ConnectionParams{
-		User:     os.Getenv("DB_USERNAME"),
-		Password: os.Getenv("DB_PASSWORD"),
-		Host:     net.JoinHostPort(os.Getenv("DB_HOST"), os.Getenv("DB_PORT")),
-		Database: "gitpod",
-		CaCert:   os.Getenv("DB_CA_CERT"),
+		User:           os.Getenv("DB_USERNAME"),
+		Password:       os.Getenv("DB_PASSWORD"),
+		Host:           net.JoinHostPort(os.Getenv("DB_HOST"), os.Getenv("DB_PORT")),
+		Database:       "gitpod",
+		CaCert:         os.Getenv("DB_CA_CERT"),
+		SSLMode:        os.Getenv("DB_SSL_MODE"),
+		MaxConnections: maxConnections,
+		IdleTimeout:    time.Duration(idleTimeout) * time.Second,
 	}
 }
 
@@ -63,13 +84,18 @@ func Connect(p ConnectionParams) (*gorm.DB, error) {
 
 		tlsConfigName := "custom"
 		err = driver_mysql.RegisterTLSConfig(tlsConfigName, &tls.Config{
-			RootCAs:    rootCertPool,
-			MinVersion: tls.VersionTLS12, // semgrep finding: set lower boundary to exclude insecure TLS1.0
+			RootCAs:            rootCertPool,
+			MinVersion:         tls.VersionTLS12, // semgrep finding: set lower boundary to exclude insecure TLS1.0
+			InsecureSkipVerify: p.SSLMode == SSLModeSkipVerify,
 		})
 		if err != nil {
 			return nil, fmt.Errorf("failed to register custom DB CA cert: %w", err)
 		}
 		cfg.TLSConfig = tlsConfigName
+	} else if p.SSLMode == SSLModeRequired {
+		cfg.TLSConfig = "true"
+	} else if p.SSLMode == SSLModeSkipVerify {
+		cfg.TLSConfig = "skip-verify"
 	}
 
 	// refer to https://github.com/go-sql-driver/mysql#dsn-data-source-name for details
@@ -99,5 +125,16 @@ func Connect(p ConnectionParams) (*gorm.DB, error) {
 		return nil, fmt.Errorf("failed to setup db tracing: %w", err)
 	}
 
+	pool, err := conn.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get db connection pool: %w", err)
+	}
+	if p.MaxConnections > 0 {
+		pool.SetMaxOpenConns(p.MaxConnections)
+	}
+	if p.IdleTimeout > 0 {
+		pool.SetConnMaxIdleTime(p.IdleTimeout)
+	}
+
 	return conn, nil
 }
@@ -5,8 +5,10 @@
 package db
 
 import (
-	"github.com/stretchr/testify/require"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
 )
 
 func TestConnectionParamsFromEnv(t *testing.T) {
@@ -15,12 +17,18 @@ func TestConnectionParamsFromEnv(t *testing.T) {
 	t.Setenv("DB_HOST", "dbhost")
 	t.Setenv("DB_PORT", "dbport")
 	t.Setenv("DB_CA_CERT", "cacert")
+	t.Setenv("DB_SSL_MODE", "required")
+	t.Setenv("DB_MAX_CONNECTIONS", "20")
+	t.Setenv("DB_IDLE_TIMEOUT_SECONDS", "300")
 
 	require.Equal(t, ConnectionParams{
-		User:     "username",
-		Password: "pass",
-		Host:     "dbhost:dbport",
-		Database: "gitpod",
-		CaCert:   "cacert",
+		User:           "username",
+		Password:       "pass",
+		Host:           "dbhost:dbport",
+		Database:       "gitpod",
+		CaCert:         "cacert",
+		SSLMode:        SSLModeRequired,
+		MaxConnections: 20,
+		IdleTimeout:    5 * time.Minute,
 	}, ConnectionParamsFromEnv())
 }
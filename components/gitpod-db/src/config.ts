@@ -21,9 +21,17 @@ export class Config {
             database: process.env.DB_NAME || "gitpod",
         };
 
-        if (process.env.DB_CA_CERT) {
+        const sslMode = process.env.DB_SSL_MODE;
+        if (process.env.DB_CA_CERT || sslMode) {
             dbSetup.ssl = {
                 ca: process.env.DB_CA_CERT,
+                rejectUnauthorized: sslMode !== "skip-verify",
+            };
+        }
+
+        if (process.env.DB_MAX_CONNECTIONS) {
+            dbSetup.extra = {
+                connectionLimit: getEnvVarParsed("DB_MAX_CONNECTIONS", Number.parseInt),
             };
         }
 
@@ -41,9 +49,10 @@ export class Config {
             password: dbConfig.password,
             database: dbConfig.database,
         };
-        if (dbConfig.ssl?.ca) {
+        if (dbConfig.ssl) {
             mysqlConfig.ssl = {
                 ca: dbConfig.ssl.ca,
+                rejectUnauthorized: dbConfig.ssl.rejectUnauthorized,
             };
         }
         return mysqlConfig;
@@ -62,5 +71,9 @@ export interface DatabaseConfig {
     password?: string;
     ssl?: {
         ca?: string;
+        rejectUnauthorized?: boolean;
+    };
+    extra?: {
+        connectionLimit?: number;
     };
 }
@@ -831,6 +831,17 @@ export abstract class AbstractTypeORMWorkspaceDBImpl implements WorkspaceDB {
             .getCount();
     }
 
+    public async countRunningPrebuildsOfProjects(projectIds: string[]): Promise<number> {
+        if (projectIds.length === 0) {
+            return 0;
+        }
+        const repo = await this.getPrebuiltWorkspaceRepo();
+        return await repo
+            .createQueryBuilder("pws")
+            .where('pws.projectId IN (:...projectIds) AND state = "building"', { projectIds })
+            .getCount();
+    }
+
     public async findPrebuildsWithWorkpace(cloneURL: string): Promise<PrebuildWithWorkspace[]> {
         const repo = await this.getPrebuiltWorkspaceRepo();
 
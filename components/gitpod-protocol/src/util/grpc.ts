@@ -7,6 +7,10 @@
 import * as grpc from "@grpc/grpc-js";
 import { Status } from "@grpc/grpc-js/build/src/constants";
 
+// GRPC_MAX_RECEIVE_MESSAGE_LENGTH raises the size of the largest message the clients receive, e.g. for
+// the logs of large image builds
+const maxReceiveMessageLength = parseInt(process.env.GRPC_MAX_RECEIVE_MESSAGE_LENGTH || "", 10);
+
 export const defaultGRPCOptions = {
     "grpc.keepalive_timeout_ms": 10000,
     "grpc.keepalive_time_ms": 60000,
@@ -14,7 +18,7 @@ export const defaultGRPCOptions = {
     "grpc.keepalive_permit_without_calls": 1,
     "grpc-node.max_session_memory": 50,
     "grpc.max_reconnect_backoff_ms": 5000,
-    "grpc.max_receive_message_length": 1024 * 1024 * 16,
+    "grpc.max_receive_message_length": maxReceiveMessageLength > 0 ? maxReceiveMessageLength : 1024 * 1024 * 16,
 };
 
 export type GrpcMethodType = "unary" | "client_stream" | "server_stream" | "bidi_stream";
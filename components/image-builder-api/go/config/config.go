@@ -6,6 +6,7 @@ package config
 
 import (
 	"github.com/gitpod-io/gitpod/common-go/baseserver"
+	common_grpc "github.com/gitpod-io/gitpod/common-go/grpc"
 )
 
 type ServiceConfig struct {
@@ -41,6 +42,22 @@ type Configuration struct {
 
 	// BuilderImage is an image ref to the workspace builder image
 	BuilderImage string `json:"builderImage"`
+
+	// BuildKit configures the buildkitd that runs in the image build workspaces
+	BuildKit *BuildKitConfig `json:"buildKit,omitempty"`
+
+	// AllowedBaseImageRegistries restricts the images that workspaces use, e.g. in the image of
+	// their .gitpod.yml, to these registries. The FROM of a Dockerfile is not checked.
+	AllowedBaseImageRegistries []string `json:"allowedBaseImageRegistries,omitempty"`
+}
+
+type BuildKitConfig struct {
+	// MaxParallelism limits the build steps that run at once. Zero means no limit.
+	MaxParallelism int `json:"maxParallelism,omitempty"`
+
+	// CacheDir is a directory of the image build workspaces that is shared by the builds, e.g.
+	// a volume added through the imagebuild pod template. The build cache is kept there.
+	CacheDir string `json:"cacheDir,omitempty"`
 }
 
 type TLS struct {
@@ -53,6 +70,8 @@ type TLS struct {
 type WorkspaceManagerConfig struct {
 	Address string `json:"address"`
 	TLS     TLS    `json:"tls,omitempty"`
+	// GRPC overrides the defaults of the connection to ws-manager
+	GRPC *common_grpc.ClientConfig `json:"grpc,omitempty"`
 	// expected to be a wsmanapi.WorkspaceManagerClient - use to avoid dependency on wsmanapi
 	// this field is used for testing only
 	Client interface{} `json:"-"`
@@ -49,10 +49,10 @@ func (b *Builder) Build() error {
 
 		if err != nil {
 			log.Warn("cannot connect to node-local buildkitd - falling back to pod-local one")
-			cl, teardown, err = StartBuildkit(buildkitdSocketPath)
+			cl, teardown, err = StartBuildkit(buildkitdSocketPath, b.Config.MaxParallelism)
 		}
 	} else {
-		cl, teardown, err = StartBuildkit(buildkitdSocketPath)
+		cl, teardown, err = StartBuildkit(buildkitdSocketPath, b.Config.MaxParallelism)
 	}
 	if err != nil {
 		return err
@@ -80,7 +80,7 @@ func (b *Builder) buildBaseLayer(ctx context.Context, cl *client.Client) error {
 	}
 
 	log.Info("building base image")
-	return buildImage(ctx, b.Config.ContextDir, b.Config.Dockerfile, b.Config.WorkspaceLayerAuth, b.Config.BaseRef)
+	return buildImage(ctx, b.Config.ContextDir, b.Config.Dockerfile, b.Config.WorkspaceLayerAuth, b.Config.BaseRef, b.Config.CacheDir)
 }
 
 func (b *Builder) buildWorkspaceImage(ctx context.Context, cl *client.Client) (err error) {
@@ -96,10 +96,11 @@ func (b *Builder) buildWorkspaceImage(ctx context.Context, cl *client.Client) (e
 		return xerrors.Errorf("unexpected error creating temporal directory: %w", err)
 	}
 
-	return buildImage(ctx, contextDir, filepath.Join(contextDir, "Dockerfile"), b.Config.WorkspaceLayerAuth, b.Config.TargetRef)
+	// The workspace image has no layers of its own, exporting its cache would replace that of the base image
+	return buildImage(ctx, contextDir, filepath.Join(contextDir, "Dockerfile"), b.Config.WorkspaceLayerAuth, b.Config.TargetRef, "")
 }
 
-func buildImage(ctx context.Context, contextDir, dockerfile, authLayer, target string) (err error) {
+func buildImage(ctx context.Context, contextDir, dockerfile, authLayer, target, cacheDir string) (err error) {
 	log.Info("waiting for build context")
 	waitctx, cancel := context.WithTimeout(ctx, 30*time.Minute)
 	defer cancel()
@@ -149,6 +150,13 @@ func buildImage(ctx context.Context, contextDir, dockerfile, authLayer, target s
 		"--local=dockerfile=" + filepath.Dir(dockerfile),
 		"--opt=filename=" + filepath.Base(dockerfile),
 	}
+	if cacheDir != "" {
+		// The cache is only there once a build has exported it
+		if _, err := os.Stat(filepath.Join(cacheDir, "index.json")); err == nil {
+			buildctlArgs = append(buildctlArgs, "--import-cache=type=local,src="+cacheDir)
+		}
+		buildctlArgs = append(buildctlArgs, "--export-cache=type=local,mode=max,dest="+cacheDir)
+	}
 
 	buildctlCmd := exec.Command("buildctl", buildctlArgs...)
 
@@ -199,8 +207,9 @@ func waitForBuildContext(ctx context.Context) error {
 	}
 }
 
-// StartBuildkit starts a local buildkit daemon
-func StartBuildkit(socketPath string) (cl *client.Client, teardown func() error, err error) {
+// StartBuildkit starts a local buildkit daemon. A maxParallelism of zero does not limit the
+// build steps that run at once.
+func StartBuildkit(socketPath string, maxParallelism int) (cl *client.Client, teardown func() error, err error) {
 	stderr, err := ioutil.TempFile(os.TempDir(), "buildkitd_stderr")
 	if err != nil {
 		return nil, nil, xerrors.Errorf("cannot create buildkitd log file: %w", err)
@@ -210,12 +219,26 @@ func StartBuildkit(socketPath string) (cl *client.Client, teardown func() error,
 		return nil, nil, xerrors.Errorf("cannot create buildkitd log file: %w", err)
 	}
 
-	cmd := exec.Command("buildkitd",
+	args := []string{
 		"--debug",
-		"--addr="+socketPath,
+		"--addr=" + socketPath,
 		"--oci-worker-net=host",
 		"--root=/workspace/buildkit",
-	)
+	}
+	if maxParallelism > 0 {
+		cfg, err := ioutil.TempFile(os.TempDir(), "buildkitd-*.toml")
+		if err != nil {
+			return nil, nil, xerrors.Errorf("cannot create buildkitd config: %w", err)
+		}
+		_, err = fmt.Fprintf(cfg, "[worker.oci]\n  max-parallelism = %d\n", maxParallelism)
+		cfg.Close()
+		if err != nil {
+			return nil, nil, xerrors.Errorf("cannot write buildkitd config: %w", err)
+		}
+		args = append(args, "--config="+cfg.Name())
+	}
+
+	cmd := exec.Command("buildkitd", args...)
 	cmd.SysProcAttr = &syscall.SysProcAttr{Credential: &syscall.Credential{Uid: 0, Gid: 0}}
 	cmd.Stderr = stderr
 	cmd.Stdout = stdout
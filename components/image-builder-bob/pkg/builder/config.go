@@ -10,6 +10,7 @@ import (
 	"encoding/base64"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"golang.org/x/xerrors"
@@ -27,6 +28,12 @@ type Config struct {
 	ContextDir         string
 	ExternalBuildkitd  string
 	localCacheImport   string
+
+	// MaxParallelism limits the build steps buildkitd runs at once. Zero means no limit.
+	MaxParallelism int
+	// CacheDir is where the build cache of the base image is exported to and imported from, so
+	// that it is shared by the image builds. No cache is kept when empty.
+	CacheDir string
 }
 
 // GetConfigFromEnv extracts configuration from environment variables
@@ -42,6 +49,15 @@ func GetConfigFromEnv() (*Config, error) {
 		ContextDir:         os.Getenv("BOB_CONTEXT_DIR"),
 		ExternalBuildkitd:  os.Getenv("BOB_EXTERNAL_BUILDKITD"),
 		localCacheImport:   os.Getenv("BOB_LOCAL_CACHE_IMPORT"),
+		CacheDir:           os.Getenv("BOB_CACHE_DIR"),
+	}
+
+	if p := os.Getenv("BOB_MAX_PARALLELISM"); p != "" {
+		var err error
+		cfg.MaxParallelism, err = strconv.Atoi(p)
+		if err != nil || cfg.MaxParallelism < 0 {
+			return nil, xerrors.Errorf("BOB_MAX_PARALLELISM must be a positive number")
+		}
 	}
 
 	if cfg.BaseRef == "" {
@@ -14,6 +14,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -73,7 +74,7 @@ func NewOrchestratingBuilder(cfg config.Configuration) (res *Orchestrator, err e
 	if c, ok := cfg.WorkspaceManager.Client.(wsmanapi.WorkspaceManagerClient); ok {
 		wsman = c
 	} else {
-		grpcOpts := common_grpc.DefaultClientOptions()
+		grpcOpts := common_grpc.ClientOptions(cfg.WorkspaceManager.GRPC)
 		if cfg.WorkspaceManager.TLS.Authority != "" || cfg.WorkspaceManager.TLS.Certificate != "" && cfg.WorkspaceManager.TLS.PrivateKey != "" {
 			tlsConfig, err := common_grpc.ClientAuthTLSConfig(
 				cfg.WorkspaceManager.TLS.Authority, cfg.WorkspaceManager.TLS.Certificate, cfg.WorkspaceManager.TLS.PrivateKey,
@@ -89,7 +90,13 @@ func NewOrchestratingBuilder(cfg config.Configuration) (res *Orchestrator, err e
 		} else {
 			grpcOpts = append(grpcOpts, grpc.WithTransportCredentials(insecure.NewCredentials()))
 		}
-		conn, err := grpc.Dial(cfg.WorkspaceManager.Address, grpcOpts...)
+		dialCtx := context.Background()
+		if timeout := cfg.WorkspaceManager.GRPC.DialTimeoutOrDefault(0); timeout > 0 {
+			var cancel context.CancelFunc
+			dialCtx, cancel = context.WithTimeout(dialCtx, timeout)
+			defer cancel()
+		}
+		conn, err := grpc.DialContext(dialCtx, cfg.WorkspaceManager.Address, grpcOpts...)
 		if err != nil {
 			return nil, err
 		}
@@ -155,6 +162,11 @@ func (o *Orchestrator) ResolveBaseImage(ctx context.Context, req *protocol.Resol
 	_ = json.Unmarshal(safeReqs, &safeReqsLog)
 	log.WithFields(safeReqsLog).Debug("ResolveBaseImage")
 
+	err = o.checkBaseImageAllowed(req.Ref)
+	if err != nil {
+		return nil, err
+	}
+
 	reqauth := o.AuthResolver.ResolveRequestAuth(req.Auth)
 
 	refstr, err := o.getAbsoluteImageRef(ctx, req.Ref, reqauth)
@@ -371,7 +383,7 @@ func (o *Orchestrator) Build(req *protocol.BuildRequest, resp protocol.ImageBuil
 					SupervisorRef: req.SupervisorRef,
 				},
 				WorkspaceLocation: contextPath,
-				Envvars: []*wsmanapi.EnvironmentVariable{
+				Envvars: append([]*wsmanapi.EnvironmentVariable{
 					{Name: "BOB_TARGET_REF", Value: "localhost:8080/target:latest"},
 					{Name: "BOB_BASE_REF", Value: bobBaseref},
 					{Name: "BOB_BUILD_BASE", Value: buildBase},
@@ -393,7 +405,7 @@ func (o *Orchestrator) Build(req *protocol.BuildRequest, resp protocol.ImageBuil
 						Value: string(additionalAuth),
 					},
 					{Name: "SUPERVISOR_DEBUG_ENABLE", Value: fmt.Sprintf("%v", log.Log.Logger.IsLevelEnabled(logrus.DebugLevel))},
-				},
+				}, o.buildKitEnvvars()...),
 			},
 			Type: wsmanapi.WorkspaceType_IMAGEBUILD,
 		})
@@ -560,6 +572,28 @@ func (o *Orchestrator) checkImageExists(ctx context.Context, ref string, authent
 	return true, nil
 }
 
+// buildKitEnvvars configures bob's buildkitd. The cache directory is bind mounted into the
+// build by workspacekit, as the build does not see the mounts of the pod otherwise.
+func (o *Orchestrator) buildKitEnvvars() []*wsmanapi.EnvironmentVariable {
+	cfg := o.Config.BuildKit
+	if cfg == nil {
+		return nil
+	}
+
+	var res []*wsmanapi.EnvironmentVariable
+	if cfg.MaxParallelism > 0 {
+		res = append(res, &wsmanapi.EnvironmentVariable{Name: "BOB_MAX_PARALLELISM", Value: strconv.Itoa(cfg.MaxParallelism)})
+	}
+	if cfg.CacheDir != "" {
+		mounts, _ := json.Marshal([]string{cfg.CacheDir})
+		res = append(res,
+			&wsmanapi.EnvironmentVariable{Name: "BOB_CACHE_DIR", Value: cfg.CacheDir},
+			&wsmanapi.EnvironmentVariable{Name: "GITPOD_WORKSPACEKIT_BIND_MOUNTS", Value: string(mounts)},
+		)
+	}
+	return res
+}
+
 // getAbsoluteImageRef returns the "digest" form of an image, i.e. contains no mutable image tags
 func (o *Orchestrator) getAbsoluteImageRef(ctx context.Context, ref string, allowedAuth auth.AllowedAuthFor) (res string, err error) {
 	auth, err := allowedAuth.GetAuthFor(o.Auth, ref)
@@ -580,12 +614,35 @@ func (o *Orchestrator) getAbsoluteImageRef(ctx context.Context, ref string, allo
 	return ref, nil
 }
 
+// checkBaseImageAllowed returns a PermissionDenied error if the image is not in one of the allowed registries
+func (o *Orchestrator) checkBaseImageAllowed(ref string) error {
+	if len(o.Config.AllowedBaseImageRegistries) == 0 {
+		return nil
+	}
+
+	pref, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "cannot parse image ref: %v", err)
+	}
+	domain := reference.Domain(pref)
+	for _, registry := range o.Config.AllowedBaseImageRegistries {
+		if domain == registry {
+			return nil
+		}
+	}
+	return status.Errorf(codes.PermissionDenied, "images from %s are not allowed", domain)
+}
+
 func (o *Orchestrator) getBaseImageRef(ctx context.Context, bs *protocol.BuildSource, allowedAuth auth.AllowedAuthFor) (res string, err error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "getBaseImageRef")
 	defer tracing.FinishSpan(span, &err)
 
 	switch src := bs.From.(type) {
 	case *protocol.BuildSource_Ref:
+		err = o.checkBaseImageAllowed(src.Ref.Ref)
+		if err != nil {
+			return "", err
+		}
 		return o.getAbsoluteImageRef(ctx, src.Ref.Ref, allowedAuth)
 
 	case *protocol.BuildSource_File:
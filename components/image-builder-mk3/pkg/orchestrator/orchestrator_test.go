@@ -160,9 +160,10 @@ func TestResolveBaseImage(t *testing.T) {
 	}
 	ref := "some-image:latest"
 	tests := []struct {
-		Name        string
-		Resolver    resolve.DockerRefResolver
-		Expectation Expectation
+		Name              string
+		Resolver          resolve.DockerRefResolver
+		AllowedRegistries []string
+		Expectation       Expectation
 	}{
 		{
 			Name:     "not found",
@@ -178,6 +179,22 @@ func TestResolveBaseImage(t *testing.T) {
 				Code: codes.Unauthenticated,
 			},
 		},
+		{
+			Name:              "registry not allowed",
+			Resolver:          resolve.MockRefResolver{},
+			AllowedRegistries: []string{"registry.example.com"},
+			Expectation: Expectation{
+				Code: codes.PermissionDenied,
+			},
+		},
+		{
+			Name:              "registry allowed",
+			Resolver:          resolve.MockRefResolver{},
+			AllowedRegistries: []string{"registry.example.com", "docker.io"},
+			Expectation: Expectation{
+				Code: codes.NotFound,
+			},
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.Name, func(t *testing.T) {
@@ -188,6 +205,7 @@ func TestResolveBaseImage(t *testing.T) {
 				WorkspaceManager: config.WorkspaceManagerConfig{
 					Client: wsmock.NewMockWorkspaceManagerClient(ctrl),
 				},
+				AllowedBaseImageRegistries: test.AllowedRegistries,
 			})
 			if err != nil {
 				t.Fatal(err)
@@ -50,7 +50,12 @@ var sendCmd = &cobra.Command{
 			return fmt.Errorf("GITPOD_INSTALLATION_PLATFORM envvar not set")
 		}
 
-		client, err := analytics.NewWithConfig(segmentIOToken, analytics.Config{})
+		client, err := analytics.NewWithConfig(segmentIOToken, analytics.Config{
+			Endpoint: os.Getenv("GITPOD_ANALYTICS_SEGMENT_ENDPOINT"),
+		})
+		if err != nil {
+			return err
+		}
 		defer func() {
 			err = client.Close()
 		}()
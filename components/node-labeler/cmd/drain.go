@@ -0,0 +1,142 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/gitpod-io/gitpod/common-go/log"
+)
+
+const (
+	// drainLabel is set on the nodes that should be drained of workspaces, e.g. before the
+	// node pool is upgraded
+	drainLabel = "gitpod.io/drain"
+	// drainedLabel is set on a draining node once the workspaces of the namespace are gone
+	drainedLabel = "gitpod.io/drained_ns_%v"
+	// drainStartedAnnotation records when the node was cordoned, the timeout counts from then
+	drainStartedAnnotation = "gitpod.io/drain-started"
+
+	workspaceComponent = "workspace"
+)
+
+var drainRequeueTime = time.Second * 30
+
+// NodeDrainReconciler cordons the nodes with the drain label and waits for their workspaces to
+// stop. Workspaces that still run when the timeout is over are stopped by deleting their pods,
+// which ws-manager backs up as on any other stop. Once no workspace is left, the node gets the
+// drained label, which the node pool upgrade can wait for.
+type NodeDrainReconciler struct {
+	client.Client
+
+	Timeout time.Duration
+}
+
+func (r *NodeDrainReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	var node corev1.Node
+	err := r.Get(ctx, req.NamespacedName, &node)
+	if err != nil {
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+
+	drained := fmt.Sprintf(drainedLabel, namespace)
+	started, cordoned := node.Annotations[drainStartedAnnotation]
+	if node.Labels[drainLabel] != "true" {
+		if !cordoned {
+			return reconcile.Result{}, nil
+		}
+		// the drain was called off, undo what it did
+		err = r.patchNode(ctx, node.Name, func(n *corev1.Node) {
+			n.Spec.Unschedulable = false
+			delete(n.Annotations, drainStartedAnnotation)
+			delete(n.Labels, drained)
+		})
+		if err != nil {
+			return reconcile.Result{}, fmt.Errorf("uncordoning node %s: %w", node.Name, err)
+		}
+		log.WithField("node", node.Name).Info("drain of node cancelled, uncordoned it")
+		return reconcile.Result{}, nil
+	}
+	if node.Labels[drained] == "true" {
+		return reconcile.Result{}, nil
+	}
+
+	startedAt, err := time.Parse(time.RFC3339, started)
+	if !cordoned || err != nil {
+		startedAt = time.Now()
+	}
+	if !cordoned || !node.Spec.Unschedulable {
+		err = r.patchNode(ctx, node.Name, func(n *corev1.Node) {
+			n.Spec.Unschedulable = true
+			if n.Annotations == nil {
+				n.Annotations = make(map[string]string)
+			}
+			n.Annotations[drainStartedAnnotation] = startedAt.UTC().Format(time.RFC3339)
+		})
+		if err != nil {
+			return reconcile.Result{}, fmt.Errorf("cordoning node %s: %w", node.Name, err)
+		}
+		log.WithField("node", node.Name).WithField("timeout", r.Timeout.String()).Info("cordoned node, waiting for its workspaces to stop")
+	}
+
+	var pods corev1.PodList
+	err = r.List(ctx, &pods,
+		client.InNamespace(namespace),
+		client.MatchingLabels{"component": workspaceComponent},
+		client.MatchingFields{"spec.nodeName": node.Name},
+	)
+	if err != nil {
+		return reconcile.Result{}, fmt.Errorf("listing the workspaces of node %s: %w", node.Name, err)
+	}
+
+	if len(pods.Items) == 0 {
+		err = r.patchNode(ctx, node.Name, func(n *corev1.Node) {
+			n.Labels[drained] = "true"
+		})
+		if err != nil {
+			return reconcile.Result{}, fmt.Errorf("labelling node %s: %w", node.Name, err)
+		}
+		log.WithField("node", node.Name).Info("node is drained of workspaces")
+		return reconcile.Result{}, nil
+	}
+
+	if time.Since(startedAt) < r.Timeout {
+		return reconcile.Result{RequeueAfter: drainRequeueTime}, nil
+	}
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if !pod.DeletionTimestamp.IsZero() {
+			continue
+		}
+		err = r.Delete(ctx, pod)
+		if err != nil && !errors.IsNotFound(err) {
+			return reconcile.Result{}, fmt.Errorf("stopping workspace %s: %w", pod.Name, err)
+		}
+		log.WithField("node", node.Name).WithField("pod", pod.Name).Info("drain timeout is over, stopping workspace")
+	}
+
+	return reconcile.Result{RequeueAfter: drainRequeueTime}, nil
+}
+
+func (r *NodeDrainReconciler) patchNode(ctx context.Context, nodeName string, change func(*corev1.Node)) error {
+	var current corev1.Node
+	err := r.Get(ctx, types.NamespacedName{Name: nodeName}, &current)
+	if err != nil {
+		return err
+	}
+
+	patch := client.MergeFrom(current.DeepCopy())
+	change(&current)
+	return r.Patch(ctx, &current, patch)
+}
@@ -7,6 +7,7 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/gitpod-io/gitpod/common-go/log"
 	"github.com/spf13/cobra"
@@ -26,7 +27,10 @@ var (
 	registryFacadePort int
 	wsdaemonPort       int
 
-	namespace string
+	namespace               string
+	infrastructureNamespace string
+
+	workspaceDrainTimeout time.Duration
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -51,6 +55,8 @@ func init() {
 	rootCmd.PersistentFlags().IntVar(&registryFacadePort, "registry-facade-port", 31750, "registry-facade node port")
 	rootCmd.PersistentFlags().IntVar(&wsdaemonPort, "ws-daemon-port", 8080, "ws-daemon service port")
 	rootCmd.PersistentFlags().StringVar(&namespace, "namespace", "default", "Namespace where Gitpod components are running")
+	rootCmd.PersistentFlags().StringVar(&infrastructureNamespace, "infrastructure-namespace", "", "Namespace where ws-daemon and registry-facade are running, if not the namespace of the other components")
+	rootCmd.PersistentFlags().DurationVar(&workspaceDrainTimeout, "workspace-drain-timeout", 0, "drain the workspaces of nodes labelled gitpod.io/drain=true, stopping those still running after this timeout. Disabled if zero")
 
 	rootCmd.PersistentFlags().BoolVarP(&jsonLog, "json-log", "j", true, "produce JSON log output on verbose level")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose JSON logging")
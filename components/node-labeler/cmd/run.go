@@ -28,6 +28,7 @@ import (
 	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
@@ -55,7 +56,7 @@ var runCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		ctrl.SetLogger(logrusr.New(log.Log))
 
-		mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+		opts := ctrl.Options{
 			Scheme:                 scheme,
 			MetricsBindAddress:     "127.0.0.1:9500",
 			HealthProbeBindAddress: ":8086",
@@ -66,7 +67,14 @@ var runCmd = &cobra.Command{
 			// in case node-labeler is restarted and not change happens, we could waste (at least) 20m in a node
 			// that never will run workspaces and the additional nodes cluster-autoscaler adds to compensate
 			SyncPeriod: pointer.Duration(2 * time.Minute),
-		})
+		}
+		// ws-daemon and registry-facade can run in a namespace of their own
+		if infrastructureNamespace != "" && infrastructureNamespace != namespace {
+			opts.Namespace = ""
+			opts.NewCache = cache.MultiNamespacedCacheBuilder([]string{namespace, infrastructureNamespace})
+		}
+
+		mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), opts)
 		if err != nil {
 			log.WithError(err).Fatal("unable to start node-labeber")
 		}
@@ -100,6 +108,17 @@ var runCmd = &cobra.Command{
 			log.WithError(err).Fatal("unable to bind controller watch event handler")
 		}
 
+		if workspaceDrainTimeout > 0 {
+			err = ctrl.NewControllerManagedBy(mgr).
+				Named("node-drain").
+				For(&corev1.Node{}).
+				WithOptions(controller.Options{MaxConcurrentReconciles: 1}).
+				Complete(&NodeDrainReconciler{client, workspaceDrainTimeout})
+			if err != nil {
+				log.WithError(err).Fatal("unable to bind node drain controller")
+			}
+		}
+
 		metrics.Registry.MustRegister(NodeLabelerCounterVec)
 		metrics.Registry.MustRegister(NodeLabelerTimeHistVec)
 
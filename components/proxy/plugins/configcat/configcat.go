@@ -5,6 +5,7 @@
 package configcat
 
 import (
+	"crypto/sha256"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -48,6 +49,8 @@ type ConfigCat struct {
 	baseUrl string
 	// pollInterval sets after how much time a configuration is considered stale.
 	pollInterval time.Duration
+	// staticConfig is served instead of the configs of configcat if it is read from a file
+	staticConfig *configCache
 
 	configCache map[string]*configCache
 	m           sync.RWMutex
@@ -70,29 +73,48 @@ func (c *ConfigCat) ServeHTTP(w http.ResponseWriter, r *http.Request, next caddy
 		return next.ServeHTTP(w, r)
 	}
 	w.Header().Set("Content-Type", "application/json")
+	if c.staticConfig != nil {
+		c.serveConfig(w, r, c.staticConfig)
+		return nil
+	}
 	if c.sdkKey == "" {
 		w.Write(DefaultConfig)
 		return nil
 	}
-	etag := r.Header.Get("If-None-Match")
 	arr := strings.Split(r.URL.Path, "/")
 	configVersion := arr[len(arr)-1]
-	config := c.getConfigWithCache(configVersion)
+	c.serveConfig(w, r, c.getConfigWithCache(configVersion))
+	return nil
+}
+
+func (c *ConfigCat) serveConfig(w http.ResponseWriter, r *http.Request, config *configCache) {
+	etag := r.Header.Get("If-None-Match")
 	if etag != "" && config.hash == etag {
 		w.WriteHeader(http.StatusNotModified)
-		return nil
+		return
 	}
 	if config.hash != "" {
 		w.Header().Set("ETag", config.hash)
 	}
 	w.Write(config.data)
-	return nil
 }
 
 func (c *ConfigCat) Provision(ctx caddy.Context) error {
 	c.logger = ctx.Logger(c)
 	c.configCache = make(map[string]*configCache)
 
+	if fn := os.Getenv("CONFIGCAT_CONFIG_FILE"); fn != "" {
+		b, err := os.ReadFile(fn)
+		if err != nil {
+			return fmt.Errorf("cannot read configcat config file: %w", err)
+		}
+		c.staticConfig = &configCache{
+			data: b,
+			hash: fmt.Sprintf(`"%x"`, sha256.Sum256(b)),
+		}
+		return nil
+	}
+
 	c.sdkKey = os.Getenv("CONFIGCAT_SDK_KEY")
 	if c.sdkKey == "" {
 		return nil
@@ -31,7 +31,9 @@ type CorsOrigin struct {
 	AnyDomain      bool     `json:"any_domain,omitempty"`
 	BaseDomain     string   `json:"base_domain,omitempty"`
 	AllowedOrigins []string `json:"allowed_origins,omitempty"`
-	Debug          bool     `json:"debug,omitempty"`
+	// GRPCWeb allows the headers of the gRPC-Web and Connect protocols
+	GRPCWeb bool `json:"grpc_web,omitempty"`
+	Debug   bool `json:"debug,omitempty"`
 }
 
 // CaddyModule returns the Caddy module information.
@@ -49,6 +51,9 @@ var (
 		"X-Requested-With", "X-Account-Type", "X-Client-Commit", "X-Client-Name", "X-Client-Version", "X-Execution-Id", "X-Machine-Id", "X-Machine-Session-Id", "X-User-Session-Id",
 	}
 	exposeHeaders = []string{"Authorization", "etag", "x-operation-id", "retry-after"}
+
+	grpcWebAllowedHeaders = []string{"X-Grpc-Web", "X-User-Agent", "Grpc-Timeout", "Connect-Protocol-Version", "Connect-Timeout-Ms"}
+	grpcWebExposeHeaders  = []string{"Grpc-Status", "Grpc-Message", "Grpc-Status-Details-Bin"}
 )
 
 // ServeHTTP implements caddyhttp.MiddlewareHandler.
@@ -62,11 +67,17 @@ func (m CorsOrigin) ServeHTTP(w http.ResponseWriter, r *http.Request, next caddy
 		allowedOrigins = m.AllowedOrigins
 	}
 
+	allowed, exposed := allowedHeaders, exposeHeaders
+	if m.GRPCWeb {
+		allowed = append(append([]string{}, allowedHeaders...), grpcWebAllowedHeaders...)
+		exposed = append(append([]string{}, exposeHeaders...), grpcWebExposeHeaders...)
+	}
+
 	c := cors.New(cors.Options{
 		AllowedOrigins:   allowedOrigins,
 		AllowedMethods:   allowedMethods,
-		AllowedHeaders:   allowedHeaders,
-		ExposedHeaders:   exposeHeaders,
+		AllowedHeaders:   allowed,
+		ExposedHeaders:   exposed,
 		AllowCredentials: true,
 		MaxAge:           60,
 		Debug:            m.Debug,
@@ -112,6 +123,14 @@ func (m *CorsOrigin) UnmarshalCaddyfile(d *caddyfile.Dispenser) error {
 			origins := strings.Split(value, ",")
 			m.AllowedOrigins = origins
 
+		case "grpc_web":
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return d.Errf("invalid boolean value for subdirective grpc_web '%s'", value)
+			}
+
+			m.GRPCWeb = b
+
 		case "debug":
 			b, err := strconv.ParseBool(value)
 			if err != nil {
@@ -0,0 +1,111 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package ratelimit
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bufbuild/connect-go"
+	lru "github.com/hashicorp/golang-lru"
+
+	"github.com/gitpod-io/gitpod/components/public-api/go/config"
+	"github.com/gitpod-io/gitpod/public-api-server/pkg/auth"
+)
+
+// maxWindows bounds the number of callers that are tracked. The least recently seen ones are
+// forgotten first, and start with a full budget when they come back.
+const maxWindows = 10000
+
+// NewInterceptor limits the calls to the procedures of the limits. It has to run after the auth
+// interceptor, which puts the token of the caller on the context.
+func NewInterceptor(limits map[string]config.RateLimit) (*Interceptor, error) {
+	windows, err := lru.New(maxWindows)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Interceptor{
+		limits:  limits,
+		windows: windows,
+		now:     time.Now,
+	}, nil
+}
+
+type Interceptor struct {
+	limits  map[string]config.RateLimit
+	windows *lru.Cache
+	now     func() time.Time
+
+	mu sync.Mutex
+}
+
+type window struct {
+	start time.Time
+	count uint
+}
+
+func (i *Interceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return connect.UnaryFunc(func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if !req.Spec().IsClient {
+			if err := i.allow(ctx, req.Spec().Procedure); err != nil {
+				return nil, err
+			}
+		}
+
+		return next(ctx, req)
+	})
+}
+
+func (i *Interceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+func (i *Interceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		if err := i.allow(ctx, conn.Spec().Procedure); err != nil {
+			return err
+		}
+
+		return next(ctx, conn)
+	}
+}
+
+// allow counts the call in the current window of the caller, and fails once the window is used up
+func (i *Interceptor) allow(ctx context.Context, procedure string) error {
+	limit, ok := i.limits[procedure]
+	if !ok {
+		return nil
+	}
+
+	// The auth interceptor rejects the calls without a token, any others share one budget
+	var caller string
+	if token, err := auth.TokenFromContext(ctx); err == nil {
+		caller = fmt.Sprintf("%x", sha256.Sum256([]byte(token.Value)))
+	}
+	key := procedure + "/" + caller
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	now := i.now()
+	period := time.Duration(limit.PeriodSeconds) * time.Second
+	w, ok := i.windows.Get(key)
+	if !ok || now.Sub(w.(*window).start) >= period {
+		w = &window{start: now}
+		i.windows.Add(key, w)
+	}
+
+	current := w.(*window)
+	if current.count >= limit.Requests {
+		retry := current.start.Add(period).Sub(now).Round(time.Second)
+		return connect.NewError(connect.CodeResourceExhausted, fmt.Errorf("rate limit of %s exceeded, retry in %s", procedure, retry))
+	}
+	current.count++
+	return nil
+}
@@ -0,0 +1,43 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bufbuild/connect-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gitpod-io/gitpod/components/public-api/go/config"
+	"github.com/gitpod-io/gitpod/public-api-server/pkg/auth"
+)
+
+func TestInterceptor_Allow(t *testing.T) {
+	const procedure = "/gitpod.experimental.v1.WorkspacesService/ListWorkspaces"
+
+	interceptor, err := NewInterceptor(map[string]config.RateLimit{
+		procedure: {Requests: 2, PeriodSeconds: 60},
+	})
+	require.NoError(t, err)
+	now := time.Now()
+	interceptor.now = func() time.Time { return now }
+
+	alice := auth.TokenToContext(context.Background(), auth.NewAccessToken("alice"))
+	bob := auth.TokenToContext(context.Background(), auth.NewAccessToken("bob"))
+
+	require.NoError(t, interceptor.allow(alice, procedure))
+	require.NoError(t, interceptor.allow(alice, procedure))
+	err = interceptor.allow(alice, procedure)
+	require.Error(t, err)
+	require.Equal(t, connect.CodeResourceExhausted, connect.CodeOf(err))
+
+	require.NoError(t, interceptor.allow(bob, procedure), "each token has its own budget")
+	require.NoError(t, interceptor.allow(alice, "/gitpod.experimental.v1.TeamsService/ListTeams"), "procedures without a limit are not counted")
+
+	now = now.Add(time.Minute)
+	require.NoError(t, interceptor.allow(alice, procedure), "the budget is restored after the period")
+}
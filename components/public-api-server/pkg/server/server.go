@@ -6,6 +6,8 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -36,6 +38,7 @@ import (
 	"github.com/gitpod-io/gitpod/public-api-server/pkg/oidc"
 	"github.com/gitpod-io/gitpod/public-api-server/pkg/origin"
 	"github.com/gitpod-io/gitpod/public-api-server/pkg/proxy"
+	"github.com/gitpod-io/gitpod/public-api-server/pkg/ratelimit"
 	"github.com/gitpod-io/gitpod/public-api-server/pkg/webhooks"
 	"github.com/sirupsen/logrus"
 )
@@ -63,9 +66,11 @@ func Start(logger *logrus.Entry, version string, cfg *config.Configuration) erro
 		return fmt.Errorf("failed to read cipherset from file: %w", err)
 	}
 
-	redisClient := redis.NewClient(&redis.Options{
-		Addr: cfg.Redis.Address,
-	})
+	redisOptions, err := redisOptionsFromConfig(cfg.Redis)
+	if err != nil {
+		return fmt.Errorf("failed to configure redis: %w", err)
+	}
+	redisClient := redis.NewClient(redisOptions)
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	err = redisClient.Ping(ctx).Err()
@@ -146,6 +151,7 @@ func Start(logger *logrus.Entry, version string, cfg *config.Configuration) erro
 		cipher:      cipherSet,
 		oidcService: oidcService,
 		idpService:  idpService,
+		rateLimits:  cfg.RateLimits,
 	}); registerErr != nil {
 		return fmt.Errorf("failed to register services: %w", registerErr)
 	}
@@ -165,6 +171,7 @@ type registerDependencies struct {
 	cipher      db.Cipher
 	oidcService *oidc.Service
 	idpService  *identityprovider.Service
+	rateLimits  map[string]config.RateLimit
 }
 
 func register(srv *baseserver.Server, deps *registerDependencies) error {
@@ -176,6 +183,11 @@ func register(srv *baseserver.Server, deps *registerDependencies) error {
 		return err
 	}
 
+	rateLimitInterceptor, err := ratelimit.NewInterceptor(deps.rateLimits)
+	if err != nil {
+		return err
+	}
+
 	rootHandler := chi.NewRouter()
 	rootHandler.Use(chi_middleware.Recoverer)
 	rootHandler.Use(middleware.NewLoggingMiddleware())
@@ -185,6 +197,7 @@ func register(srv *baseserver.Server, deps *registerDependencies) error {
 			NewMetricsInterceptor(connectMetrics),
 			NewLogInterceptor(log.Log),
 			auth.NewServerInterceptor(),
+			rateLimitInterceptor,
 			origin.NewInterceptor(),
 		),
 	}
@@ -223,3 +236,29 @@ func readSecretFromFile(path string) (string, error) {
 
 	return strings.TrimSpace(string(b)), nil
 }
+
+func redisOptionsFromConfig(cfg config.RedisConfiguration) (*redis.Options, error) {
+	opts := &redis.Options{
+		Addr: cfg.Address,
+	}
+
+	if cfg.TLS {
+		opts.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	if cfg.CredentialsPath != "" {
+		username, err := os.ReadFile(filepath.Join(cfg.CredentialsPath, "username"))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to read redis username: %w", err)
+		}
+		opts.Username = strings.TrimSpace(string(username))
+
+		password, err := os.ReadFile(filepath.Join(cfg.CredentialsPath, "password"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read redis password: %w", err)
+		}
+		opts.Password = strings.TrimSpace(string(password))
+	}
+
+	return opts, nil
+}
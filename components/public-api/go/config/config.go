@@ -32,11 +32,28 @@ type Configuration struct {
 	// Redis configures the connection to Redis
 	Redis RedisConfiguration `json:"redis"`
 
+	// RateLimits limit the calls of each token to a procedure, keyed by the procedure, e.g.
+	// /gitpod.experimental.v1.WorkspacesService/ListWorkspaces
+	RateLimits map[string]RateLimit `json:"rateLimits,omitempty"`
+
 	Server *baseserver.Configuration `json:"server,omitempty"`
 }
 
+type RateLimit struct {
+	// Requests is the number of calls that are allowed in each period
+	Requests uint `json:"requests"`
+
+	PeriodSeconds uint `json:"periodSeconds"`
+}
+
 type RedisConfiguration struct {
 
 	// Address configures the redis connection of this component
 	Address string `json:"address"`
+
+	// CredentialsPath is an optional directory containing the "username" and "password" files
+	CredentialsPath string `json:"credentialsPath,omitempty"`
+
+	// TLS enables TLS for the redis connection
+	TLS bool `json:"tls,omitempty"`
 }
@@ -18,6 +18,10 @@ type ServiceConfig struct {
 	PProfAddr          string `json:"pprofAddr"`
 	PrometheusAddr     string `json:"prometheusAddr"`
 	ReadinessProbeAddr string `json:"readinessProbeAddr"`
+
+	// UpstreamAuthCfg is a Docker auth config with the credentials of the registries the images
+	// of the workspaces are pulled from, e.g. Docker Hub. The credentials in AuthCfg take precedence.
+	UpstreamAuthCfg string `json:"upstreamDockerAuth,omitempty"`
 }
 
 // GetConfig loads and validates the configuration
@@ -83,7 +83,7 @@ var runCmd = &cobra.Command{
 			dockerCfgMu sync.RWMutex
 		)
 		if cfg.AuthCfg != "" {
-			dockerCfg = loadDockerCfg(cfg.AuthCfg)
+			dockerCfg = loadDockerCfg(cfg.AuthCfg, cfg.UpstreamAuthCfg)
 		}
 
 		resolverProvider := func() remotes.Resolver {
@@ -165,15 +165,22 @@ var runCmd = &cobra.Command{
 			log.WithError(err).Fatal("cannot start watch of configuration file")
 		}
 
-		err = watch.File(ctx, cfg.AuthCfg, func() {
+		reloadDockerCfg := func() {
 			dockerCfgMu.Lock()
 			defer dockerCfgMu.Unlock()
 
-			dockerCfg = loadDockerCfg(cfg.AuthCfg)
-		})
+			dockerCfg = loadDockerCfg(cfg.AuthCfg, cfg.UpstreamAuthCfg)
+		}
+		err = watch.File(ctx, cfg.AuthCfg, reloadDockerCfg)
 		if err != nil {
 			log.WithError(err).Fatal("cannot start watch of Docker auth configuration file")
 		}
+		if cfg.UpstreamAuthCfg != "" {
+			err = watch.File(ctx, cfg.UpstreamAuthCfg, reloadDockerCfg)
+			if err != nil {
+				log.WithError(err).Fatal("cannot start watch of upstream Docker auth configuration file")
+			}
+		}
 
 		go func() {
 			defer close(registryDoneChan)
@@ -190,7 +197,22 @@ var runCmd = &cobra.Command{
 	},
 }
 
-func loadDockerCfg(fn string) *configfile.ConfigFile {
+func loadDockerCfg(fn, upstreamFn string) *configfile.ConfigFile {
+	dockerCfg := readDockerCfg(fn)
+	if upstreamFn == "" {
+		return dockerCfg
+	}
+
+	// The credentials of the backing registries take precedence over those of the upstream registries
+	for host, auth := range readDockerCfg(upstreamFn).AuthConfigs {
+		if _, exists := dockerCfg.AuthConfigs[host]; !exists {
+			dockerCfg.AuthConfigs[host] = auth
+		}
+	}
+	return dockerCfg
+}
+
+func readDockerCfg(fn string) *configfile.ConfigFile {
 	if tproot := os.Getenv("TELEPRESENCE_ROOT"); tproot != "" {
 		fn = filepath.Join(tproot, fn)
 	}
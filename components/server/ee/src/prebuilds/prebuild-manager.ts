@@ -271,6 +271,12 @@ export class PrebuildManager {
                     "Prebuild is rate limited. Please contact Gitpod if you believe this happened in error.";
                 await this.workspaceDB.trace({ span }).storePrebuiltWorkspace(prebuild);
                 span.setTag("ratelimited", true);
+            } else if (project && (await this.shouldLimitConcurrentPrebuilds(span, project))) {
+                prebuild.state = "aborted";
+                prebuild.error =
+                    "Too many prebuilds are running for this project or its team. Please try again once they are done.";
+                await this.workspaceDB.trace({ span }).storePrebuiltWorkspace(prebuild);
+                span.setTag("concurrencylimited", true);
             } else if (project && (await this.shouldSkipInactiveProject(project))) {
                 prebuild.state = "aborted";
                 prebuild.error =
@@ -449,6 +455,36 @@ export class PrebuildManager {
         return false;
     }
 
+    private async shouldLimitConcurrentPrebuilds(span: opentracing.Span, project: Project): Promise<boolean> {
+        const { maxConcurrentPrebuildsPerProject, maxConcurrentPrebuildsPerTeam } = this.config;
+        const workspaceDB = this.workspaceDB.trace({ span });
+
+        if (maxConcurrentPrebuildsPerProject) {
+            const running = await workspaceDB.countRunningPrebuildsOfProjects([project.id]);
+            if (running >= maxConcurrentPrebuildsPerProject) {
+                log.debug("Prebuild exceeds the concurrency limit of the project", {
+                    projectId: project.id,
+                    running,
+                    limit: maxConcurrentPrebuildsPerProject,
+                });
+                return true;
+            }
+        }
+        if (maxConcurrentPrebuildsPerTeam && project.teamId) {
+            const projects = await this.projectService.getTeamProjects(project.teamId);
+            const running = await workspaceDB.countRunningPrebuildsOfProjects(projects.map((p) => p.id));
+            if (running >= maxConcurrentPrebuildsPerTeam) {
+                log.debug("Prebuild exceeds the concurrency limit of the team", {
+                    teamId: project.teamId,
+                    running,
+                    limit: maxConcurrentPrebuildsPerTeam,
+                });
+                return true;
+            }
+        }
+        return false;
+    }
+
     private async shouldSkipInactiveProject(project: Project): Promise<boolean> {
         return await this.projectService.isProjectConsideredInactive(project.id);
     }
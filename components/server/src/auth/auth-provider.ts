@@ -19,6 +19,8 @@ export interface AuthProviderParams extends AuthProviderEntry {
     readonly oauth: OAuth2Config & {
         // extending:
         readonly configFn?: string;
+        // read into clientSecret when the config is loaded
+        readonly clientSecretFile?: string;
     };
 
     // for special auth providers only
@@ -23,5 +23,10 @@ export function spicedbClientFromEnv(): SpiceDBClient {
         return;
     }
 
-    return v1.NewClient(token, address, v1.ClientSecurity.INSECURE_PLAINTEXT_CREDENTIALS).promises;
+    // the in-cluster SpiceDB is served without TLS
+    const security =
+        process.env["SPICEDB_TLS"] === "true"
+            ? v1.ClientSecurity.SECURE
+            : v1.ClientSecurity.INSECURE_PLAINTEXT_CREDENTIALS;
+    return v1.NewClient(token, address, security).promises;
 }
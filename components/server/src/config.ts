@@ -7,7 +7,7 @@
 import { GitpodHostUrl } from "@gitpod/gitpod-protocol/lib/util/gitpod-host-url";
 import { AuthProviderParams, normalizeAuthProviderParams } from "./auth/auth-provider";
 
-import { NamedWorkspaceFeatureFlag } from "@gitpod/gitpod-protocol";
+import { NamedWorkspaceFeatureFlag, PortVisibility } from "@gitpod/gitpod-protocol";
 
 import { RateLimiterConfig } from "./auth/rate-limiter";
 import { CodeSyncConfig } from "./code-sync/code-sync-service";
@@ -50,6 +50,8 @@ export interface WorkspaceDefaults {
     defaultFeatureFlags: NamedWorkspaceFeatureFlag[];
     timeoutDefault?: string;
     timeoutExtended?: string;
+    /** disableDotfiles ignores the dotfiles repositories of the users */
+    disableDotfiles?: boolean;
 }
 
 export interface WorkspaceGarbageCollection {
@@ -81,6 +83,32 @@ export interface WorkspaceGarbageCollection {
     purgeChunkLimit: number;
 }
 
+export interface WorkspacePortsConfig {
+    /** defaultVisibility of the ports whose visibility is not configured, defaults to private */
+    defaultVisibility?: PortVisibility;
+    /** disablePublic makes all ports private */
+    disablePublic?: boolean;
+    /** allowedRanges are the ports that can be exposed, all ports can be exposed if there are none */
+    allowedRanges?: { from: number; to: number }[];
+}
+
+export namespace WorkspacePortsConfig {
+    export function isAllowed(config: WorkspacePortsConfig | undefined, port: number): boolean {
+        const ranges = config?.allowedRanges || [];
+        return ranges.length === 0 || ranges.some((r) => port >= r.from && port <= r.to);
+    }
+
+    export function visibility(
+        config: WorkspacePortsConfig | undefined,
+        visibility: PortVisibility | undefined,
+    ): PortVisibility {
+        if (config?.disablePublic) {
+            return "private";
+        }
+        return visibility || config?.defaultVisibility || "private";
+    }
+}
+
 /**
  * This is the config shape as found in the configuration file, e.g. server-configmap.yaml
  */
@@ -105,6 +133,11 @@ export interface ConfigSerialized {
     session: {
         maxAgeMs: number;
         secret: string;
+        /** cookie defaults to a lax cookie that is also sent over HTTP */
+        cookie?: {
+            sameSite: "lax" | "strict" | "none";
+            secure: boolean;
+        };
     };
 
     githubApp?: {
@@ -112,6 +145,8 @@ export interface ConfigSerialized {
         appId: number;
         baseUrl?: string;
         webhookSecret: string;
+        /** webhookSecretFile takes precedence over webhookSecret */
+        webhookSecretFile?: string;
         authProviderId: string;
         certPath: string;
         marketplaceName: string;
@@ -138,6 +173,15 @@ export interface ConfigSerialized {
     /** maxConcurrentPrebuildsPerRef is the maximum number of prebuilds we allow per ref type at any given time */
     maxConcurrentPrebuildsPerRef: number;
 
+    /** maxConcurrentPrebuildsPerProject limits the prebuilds that run at once for a project, unless it is unset or 0 */
+    maxConcurrentPrebuildsPerProject?: number;
+
+    /** maxConcurrentPrebuildsPerTeam limits the prebuilds that run at once for the projects of a team, unless it is unset or 0 */
+    maxConcurrentPrebuildsPerTeam?: number;
+
+    /** prebuildWorkspaceClass is the class of the prebuilds of the projects that do not set their own */
+    prebuildWorkspaceClass?: string;
+
     incrementalPrebuilds: {
         repositoryPasslist: string[];
         commitHistory: number;
@@ -148,6 +192,18 @@ export interface ConfigSerialized {
         passlist: string[];
     };
 
+    /** blockedRepositories are checked before the blocked repositories in the database */
+    blockedRepositories?: { urlRegExp: string; blockUser: boolean }[];
+
+    /** blockedDomains are the hosts, including their subdomains, that no workspace can be started from */
+    blockedDomains?: string[];
+
+    /** websocketPingIntervalMs is how often the clients of the websocket API are pinged, defaults to 30 seconds */
+    websocketPingIntervalMs?: number;
+
+    /** workspacePorts limits the ports the workspaces can expose and who can access them */
+    workspacePorts?: WorkspacePortsConfig;
+
     showSetupModal: boolean;
 
     admin: {
@@ -254,13 +310,21 @@ export namespace ConfigFile {
         }
     }
 
+    function readClientSecretFile(params: AuthProviderParams): AuthProviderParams {
+        if (!params.oauth?.clientSecretFile) {
+            return params;
+        }
+        const clientSecret = fs.readFileSync(filePathTelepresenceAware(params.oauth.clientSecretFile), "utf-8");
+        return { ...params, oauth: { ...params.oauth, clientSecret: clientSecret.trim() } };
+    }
+
     function loadAndCompleteConfig(config: ConfigSerialized): Config {
         const hostUrl = new GitpodHostUrl(config.hostUrl);
         let authProviderConfigs: AuthProviderParams[] = [];
         const rawProviderConfigs = config.authProviderConfigs;
         if (rawProviderConfigs) {
             /* Add raw provider data */
-            authProviderConfigs.push(...rawProviderConfigs);
+            authProviderConfigs.push(...rawProviderConfigs.map(readClientSecretFile));
         }
         const rawProviderConfigFiles = config.authProviderConfigFiles;
         if (rawProviderConfigFiles) {
@@ -329,9 +393,20 @@ export namespace ConfigFile {
             }
         }
 
+        let githubApp = config.githubApp;
+        if (githubApp?.webhookSecretFile) {
+            try {
+                const webhookSecret = fs.readFileSync(filePathTelepresenceAware(githubApp.webhookSecretFile), "utf-8");
+                githubApp = { ...githubApp, webhookSecret: webhookSecret.trim() };
+            } catch (error) {
+                log.error("Could not load GitHub App webhook secret", error);
+            }
+        }
+
         return {
             ...config,
             hostUrl,
+            githubApp,
             authProviderConfigs,
             builtinAuthProvidersConfigured,
             chargebeeProviderOptions,
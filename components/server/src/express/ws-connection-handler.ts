@@ -20,9 +20,14 @@ export class WsConnectionHandler implements Disposable {
     protected readonly disposables: DisposableCollection = new DisposableCollection();
     protected readonly clients: Set<websocket> = new Set();
 
+    /**
+     * @param intervalMs how often the clients are pinged, and how long they have to answer
+     */
+    constructor(protected readonly intervalMs: number = 30000) {}
+
     start(): void {
         // implement heartbeating closely following https://www.npmjs.com/package/ws#how-to-detect-and-close-broken-connections
-        const INTERVAL = 30000;
+        const INTERVAL = this.intervalMs;
         const TIMEOUT = INTERVAL;
         const CLOSING_TIMEOUT = INTERVAL;
         const timer = repeat(async () => {
@@ -222,7 +222,7 @@ export class Server<C extends GitpodClient, S extends GitpodServer> {
                 },
             );
 
-            const wsPingPongHandler = new WsConnectionHandler();
+            const wsPingPongHandler = new WsConnectionHandler(this.config.websocketPingIntervalMs);
             const wsHandler = new WsExpressHandler(httpServer, verifyClient);
             wsHandler.ws(
                 websocketConnectionHandler.path,
@@ -51,9 +51,9 @@ export class SessionHandlerProvider {
         return {
             path: "/", // default
             httpOnly: true, // default
-            secure: false, // default, TODO SSL! Config proxy
-            maxAge: config.session.maxAgeMs, // configured in Helm chart, defaults to 3 days.
-            sameSite: "lax", // default: true. "Lax" needed for OAuth.
+            secure: config.session.cookie?.secure ?? false, // set by the installer, TLS ends at the proxy
+            maxAge: config.session.maxAgeMs, // set by the installer, defaults to 3 days
+            sameSite: config.session.cookie?.sameSite ?? "lax", // set by the installer, "lax" is needed for OAuth
         };
     }
 
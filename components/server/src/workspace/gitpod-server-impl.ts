@@ -139,7 +139,7 @@ import { IAnalyticsWriter } from "@gitpod/gitpod-protocol/lib/analytics";
 import { AuthProviderService } from "../auth/auth-provider-service";
 import { HostContextProvider } from "../auth/host-context-provider";
 import { GuardedResource, ResourceAccessGuard, ResourceAccessOp } from "../auth/resource-access";
-import { Config } from "../config";
+import { Config, WorkspacePortsConfig } from "../config";
 import { NotFoundError, UnauthorizedError } from "../errors";
 import { RepoURL } from "../repohost/repo-url";
 import { TermsProvider } from "../terms/terms-provider";
@@ -1619,12 +1619,17 @@ export class GitpodServerImpl implements GitpodServerWithTracing, Disposable {
         }
         traceWI(ctx, { instanceId: runningInstance.id });
         await this.guardAccess({ kind: "workspaceInstance", subject: runningInstance, workspace }, "update");
+        if (!WorkspacePortsConfig.isAllowed(this.config.workspacePorts, port.port)) {
+            throw new ResponseError(ErrorCodes.BAD_REQUEST, `Port ${port.port} is outside of the allowed ranges.`);
+        }
 
         const req = new ControlPortRequest();
         req.setId(runningInstance.id);
         const spec = new PortSpec();
         spec.setPort(port.port);
-        spec.setVisibility(this.portVisibilityToProto(port.visibility));
+        spec.setVisibility(
+            this.portVisibilityToProto(WorkspacePortsConfig.visibility(this.config.workspacePorts, port.visibility)),
+        );
         req.setSpec(spec);
         req.setExpose(true);
 
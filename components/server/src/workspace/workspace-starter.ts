@@ -95,7 +95,7 @@ import { inject, injectable } from "inversify";
 import { v4 as uuidv4 } from "uuid";
 import { HostContextProvider } from "../auth/host-context-provider";
 import { ScopedResourceGuard } from "../auth/resource-access";
-import { Config } from "../config";
+import { Config, WorkspacePortsConfig } from "../config";
 import { OneTimeSecretServer } from "../one-time-secret-server";
 import { AuthorizationService } from "../user/authorization-service";
 import { TokenProvider } from "../user/token-provider";
@@ -143,6 +143,7 @@ export async function getWorkspaceClassForInstance(
     workspaceClassOverride: string | undefined,
     entitlementService: EntitlementService,
     config: WorkspaceClassesConfig,
+    prebuildWorkspaceClass?: string,
 ): Promise<string> {
     const span = TraceContext.startSpan("getWorkspaceClassForInstance", ctx);
     try {
@@ -156,7 +157,7 @@ export async function getWorkspaceClassForInstance(
         if (!workspaceClass) {
             switch (workspace.type) {
                 case "prebuild":
-                    workspaceClass = project?.settings?.workspaceClasses?.prebuild;
+                    workspaceClass = project?.settings?.workspaceClasses?.prebuild || prebuildWorkspaceClass;
                     break;
                 case "regular":
                     workspaceClass = project?.settings?.workspaceClasses?.regular;
@@ -438,7 +439,13 @@ export class WorkspaceStarter {
     }
 
     protected async checkBlockedRepository(user: User, contextURL: string) {
-        const blockedRepository = await this.blockedRepositoryDB.findBlockedRepositoryByURL(contextURL);
+        if (this.isBlockedDomain(contextURL)) {
+            throw new Error(`${contextURL} is blocklisted on Gitpod.`);
+        }
+
+        const blockedRepository =
+            (this.config.blockedRepositories || []).find((r) => new RegExp(r.urlRegExp).test(contextURL)) ||
+            (await this.blockedRepositoryDB.findBlockedRepositoryByURL(contextURL));
         if (!blockedRepository) return;
 
         if (blockedRepository.blockUser) {
@@ -452,6 +459,20 @@ export class WorkspaceStarter {
         throw new Error(`${contextURL} is blocklisted on Gitpod.`);
     }
 
+    protected isBlockedDomain(contextURL: string): boolean {
+        let host: string;
+        try {
+            host = new URL(contextURL).hostname.toLowerCase();
+        } catch (error) {
+            // Context URLs without a scheme, eg github.com/gitpod-io/gitpod
+            host = contextURL.split("/")[0].toLowerCase();
+        }
+        return (this.config.blockedDomains || []).some((d) => {
+            d = d.toLowerCase();
+            return host === d || host.endsWith(`.${d}`);
+        });
+    }
+
     // Note: this function does not expect to be awaited for by its caller. This means that it takes care of error handling itself.
     protected async actuallyStartWorkspace(
         ctx: TraceContext,
@@ -872,6 +893,7 @@ export class WorkspaceStarter {
                 workspaceClassOverride,
                 this.entitlementService,
                 this.config.workspaceClasses,
+                this.config.prebuildWorkspaceClass,
             );
 
             featureFlags = featureFlags.concat(["workspace_class_limiting"]);
@@ -1361,7 +1383,9 @@ export class WorkspaceStarter {
         // supervisor ensures dotfiles are only used if the workspace is a regular workspace
         const dotfileEnv = new EnvironmentVariable();
         dotfileEnv.setName("SUPERVISOR_DOTFILE_REPO");
-        dotfileEnv.setValue(user.additionalData?.dotfileRepo || "");
+        dotfileEnv.setValue(
+            this.config.workspaceDefaults.disableDotfiles ? "" : user.additionalData?.dotfileRepo || "",
+        );
         envvars.push(dotfileEnv);
 
         if (workspace.config.coreDump?.enabled) {
@@ -1424,11 +1448,18 @@ export class WorkspaceStarter {
                     return undefined;
                 }
                 portIndex.add(p.port);
+                if (!WorkspacePortsConfig.isAllowed(this.config.workspacePorts, p.port)) {
+                    log.debug(
+                        { instanceId: instance.id, workspaceId: workspace.id, userId: user.id },
+                        `port outside of the allowed ranges: ${p.port}`,
+                    );
+                    return undefined;
+                }
 
                 const spec = new PortSpec();
                 spec.setPort(p.port);
                 spec.setVisibility(
-                    p.visibility == "public"
+                    WorkspacePortsConfig.visibility(this.config.workspacePorts, p.visibility) == "public"
                         ? PortVisibility.PORT_VISIBILITY_PUBLIC
                         : PortVisibility.PORT_VISIBILITY_PRIVATE,
                 );
@@ -293,6 +293,10 @@ type WorkspaceConfig struct {
 	// OwnerId is the user id who owns the workspace
 	OwnerId string `env:"GITPOD_OWNER_ID"`
 
+	// SSHUserCA are the public keys of the SSH certificate authorities whose user certificates
+	// sshd accepts, if the certificate has the owner of the workspace as its principal
+	SSHUserCA string `env:"GITPOD_SSH_USER_CA"`
+
 	// DebugWorkspaceType indicates whether it is a regular or prebuild debug workspace
 	DebugWorkspaceType api.DebugWorkspaceType `env:"SUPERVISOR_DEBUG_WORKSPACE_TYPE"`
 
@@ -40,12 +40,19 @@ func newSSHServer(ctx context.Context, cfg *Config, envvars []string) (*sshServe
 		return nil, xerrors.Errorf("unexpected error creating SSH dir: %w", err)
 	}
 
-	return &sshServer{
+	server := &sshServer{
 		ctx:     ctx,
 		cfg:     cfg,
 		sshkey:  sshkey,
 		envvars: envvars,
-	}, nil
+	}
+	if cfg.SSHUserCA != "" {
+		server.userCA, server.principals, err = prepareSSHUserCA(filepath.Dir(sshkey), cfg)
+		if err != nil {
+			return nil, xerrors.Errorf("unexpected error preparing SSH user CA: %w", err)
+		}
+	}
+	return server, nil
 }
 
 type sshServer struct {
@@ -54,6 +61,9 @@ type sshServer struct {
 	envvars []string
 
 	sshkey string
+	// userCA and principals are the files sshd accepts user certificates with, if a CA is configured
+	userCA     string
+	principals string
 }
 
 // ListenAndServe listens on the TCP network address laddr and then handle packets on incoming connections.
@@ -104,6 +114,12 @@ func (s *sshServer) handleConn(ctx context.Context, conn net.Conn) {
 		"-oSubsystem sftp internal-sftp",
 		"-oStrictModes no", // don't care for home directory and file permissions
 	)
+	if s.userCA != "" {
+		args = append(args,
+			"-oTrustedUserCAKeys "+s.userCA,
+			"-oAuthorizedPrincipalsFile "+s.principals,
+		)
+	}
 	// can be configured with gp env LOG_LEVEL=DEBUG to see SSH sessions/channels
 	sshdLogLevel := "ERROR"
 	switch log.Log.Logger.GetLevel() {
@@ -240,6 +256,26 @@ func ensureSSHDir(cfg *Config) error {
 	return nil
 }
 
+// prepareSSHUserCA writes the public keys of the user CAs and the principal the certificates
+// must have, which is the owner of the workspace, to the directory
+func prepareSSHUserCA(dir string, cfg *Config) (userCA, principals string, err error) {
+	if cfg.OwnerId == "" {
+		return "", "", xerrors.Errorf("the workspace has no owner to accept certificates of")
+	}
+
+	userCA = filepath.Join(dir, "user_ca.pub")
+	err = os.WriteFile(userCA, []byte(cfg.SSHUserCA+"\n"), 0o644)
+	if err != nil {
+		return "", "", err
+	}
+	principals = filepath.Join(dir, "user_ca_principals")
+	err = os.WriteFile(principals, []byte(cfg.OwnerId+"\n"), 0o644)
+	if err != nil {
+		return "", "", err
+	}
+	return userCA, principals, nil
+}
+
 func configureSSHDefaultDir(cfg *Config) {
 	if cfg.RepoRoot == "" {
 		log.Error("cannot configure ssh default dir with empty repo root")
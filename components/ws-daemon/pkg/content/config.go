@@ -11,6 +11,7 @@ import (
 	"github.com/gitpod-io/gitpod/common-go/util"
 	cntntcfg "github.com/gitpod-io/gitpod/content-service/api/config"
 	"github.com/gitpod-io/gitpod/ws-daemon/api"
+	"github.com/gitpod-io/gitpod/ws-daemon/pkg/quota"
 	"golang.org/x/xerrors"
 )
 
@@ -39,6 +40,45 @@ type Config struct {
 
 	// Initializer configures the isolated content initializer runtime
 	Initializer InitializerConfig `json:"initializer"`
+
+	// StorageQuota configures the disk quota of the workspaces
+	StorageQuota StorageQuotaConfig `json:"storageQuota,omitempty"`
+}
+
+// QuotaBackend enforces the disk quota of the workspaces
+type QuotaBackend string
+
+const (
+	// QuotaBackendXFS sets XFS project quotas on the workspace directories, which requires the
+	// working area to be on an XFS filesystem that is mounted with prjquota
+	QuotaBackendXFS QuotaBackend = "xfs"
+	// QuotaBackendNone does not enforce the quota
+	QuotaBackendNone QuotaBackend = "none"
+)
+
+type StorageQuotaConfig struct {
+	// Backend defaults to QuotaBackendXFS
+	Backend QuotaBackend `json:"backend,omitempty"`
+	// DefaultSize is the quota of the workspaces that are started without one
+	DefaultSize quota.Size `json:"defaultSize,omitempty"`
+}
+
+// NewXFS returns the quota of the working area, or nil if no quota is enforced. It fails if the
+// filesystem of the working area does not support XFS project quotas.
+func (c StorageQuotaConfig) NewXFS(workingArea string) (*quota.XFS, error) {
+	switch c.Backend {
+	case QuotaBackendNone:
+		return nil, nil
+	case "", QuotaBackendXFS:
+	default:
+		return nil, xerrors.Errorf("unknown storage quota backend: %s", c.Backend)
+	}
+
+	xfs, err := quota.NewXFS(workingArea)
+	if err != nil {
+		return nil, xerrors.Errorf("cannot enforce the storage quota on %s, it must be on an XFS filesystem with project quotas or the quota backend must be %s: %w", workingArea, QuotaBackendNone, err)
+	}
+	return xfs, nil
 }
 
 type BackupConfig struct {
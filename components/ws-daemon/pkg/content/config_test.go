@@ -39,3 +39,15 @@ func TestFSShiftMethodUnmarshalJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestStorageQuotaConfigNewXFS(t *testing.T) {
+	xfs, err := content.StorageQuotaConfig{Backend: content.QuotaBackendNone}.NewXFS(t.TempDir())
+	if err != nil || xfs != nil {
+		t.Errorf("expected no quota without an error for the none backend, got %v, %v", xfs, err)
+	}
+
+	_, err = content.StorageQuotaConfig{Backend: "btrfs"}.NewXFS(t.TempDir())
+	if err == nil {
+		t.Errorf("expected an error for an unknown backend")
+	}
+}
@@ -36,12 +36,12 @@ func WorkspaceLifecycleHooks(cfg Config, workspaceCIDR string, workspaceExistenc
 			// When starting a workspace, use soft limit for the following reason to ensure content is restored
 			// - workspacekit needs to generate some temporary file when starting a workspace
 			// - when extracting tar file, tar command create some symlinks following a original content
-			hookInstallQuota(xfs, false),
+			hookInstallQuota(xfs, false, cfg.StorageQuota.DefaultSize),
 		},
 		session.WorkspaceReady: {
 			startIWS,
 			hookSetupRemoteStorage(cfg),
-			hookInstallQuota(xfs, true),
+			hookInstallQuota(xfs, true, cfg.StorageQuota.DefaultSize),
 		},
 		session.WorkspaceDisposed: {
 			iws.StopServingWorkspace,
@@ -106,7 +106,7 @@ func hookSetupWorkspaceLocation(ctx context.Context, ws *session.Workspace) (err
 }
 
 // hookInstallQuota enforces filesystem quota on the workspace location (if the filesystem supports it)
-func hookInstallQuota(xfs *quota.XFS, isHard bool) session.WorkspaceLivecycleHook {
+func hookInstallQuota(xfs *quota.XFS, isHard bool, defaultSize quota.Size) session.WorkspaceLivecycleHook {
 	return func(ctx context.Context, ws *session.Workspace) (err error) {
 		span, _ := opentracing.StartSpanFromContext(ctx, "hook.InstallQuota")
 		defer tracing.FinishSpan(span, &err)
@@ -115,12 +115,14 @@ func hookInstallQuota(xfs *quota.XFS, isHard bool) session.WorkspaceLivecycleHoo
 			return nil
 		}
 
-		if ws.StorageQuota == 0 {
+		size := quota.Size(ws.StorageQuota)
+		if size == 0 {
+			size = defaultSize
+		}
+		if size == 0 {
 			return nil
 		}
 
-		size := quota.Size(ws.StorageQuota)
-
 		log.WithFields(ws.OWI()).WithField("size", size).WithField("directory", ws.Location).Debug("setting disk quota")
 
 		var (
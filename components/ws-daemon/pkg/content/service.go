@@ -37,7 +37,6 @@ import (
 	"github.com/gitpod-io/gitpod/ws-daemon/pkg/container"
 	"github.com/gitpod-io/gitpod/ws-daemon/pkg/internal/session"
 	"github.com/gitpod-io/gitpod/ws-daemon/pkg/iws"
-	"github.com/gitpod-io/gitpod/ws-daemon/pkg/quota"
 )
 
 // Metrics combine custom Metrics exported by WorkspaceService
@@ -80,7 +79,7 @@ func NewWorkspaceService(ctx context.Context, cfg Config, runtime container.Runt
 		return nil, xerrors.Errorf("cannot create working area: %w", err)
 	}
 
-	xfs, err := quota.NewXFS(cfg.WorkingArea)
+	xfs, err := cfg.StorageQuota.NewXFS(cfg.WorkingArea)
 	if err != nil {
 		return nil, err
 	}
@@ -30,6 +30,8 @@ type Config struct {
 	TotalBandwidth resource.Quantity `json:"totalBandwidth"`
 	Limit          resource.Quantity `json:"limit"`
 	BurstLimit     resource.Quantity `json:"burstLimit"`
+	// Buckets replace the fixed limit with limits that decrease as a workspace spends CPU time
+	Buckets []Bucket `json:"buckets,omitempty"`
 
 	ControlPeriod  util.Duration `json:"controlPeriod"`
 	CGroupBasePath string        `json:"cgroupBasePath"`
@@ -65,8 +67,12 @@ func NewDispatchListener(cfg *Config, prom prometheus.Registerer) *DispatchListe
 	}
 
 	if cfg.Enabled {
+		var limiter ResourceLimiter = FixedLimiter(BandwidthFromQuantity(d.Config.Limit))
+		if len(d.Config.Buckets) > 0 {
+			limiter = BucketLimiter(d.Config.Buckets)
+		}
 		dist := NewDistributor(d.source, d.sink,
-			CompositeLimiter(AnnotationLimiter(kubernetes.WorkspaceCpuMinLimitAnnotation), FixedLimiter(BandwidthFromQuantity(d.Config.Limit))),
+			CompositeLimiter(AnnotationLimiter(kubernetes.WorkspaceCpuMinLimitAnnotation), limiter),
 			CompositeLimiter(AnnotationLimiter(kubernetes.WorkspaceCpuBurstLimitAnnotation), FixedLimiter(BandwidthFromQuantity(d.Config.BurstLimit))),
 			BandwidthFromQuantity(d.Config.TotalBandwidth),
 		)
@@ -37,7 +37,6 @@ import (
 	"github.com/gitpod-io/gitpod/ws-daemon/pkg/dispatch"
 	"github.com/gitpod-io/gitpod/ws-daemon/pkg/iws"
 	"github.com/gitpod-io/gitpod/ws-daemon/pkg/netlimit"
-	"github.com/gitpod-io/gitpod/ws-daemon/pkg/quota"
 )
 
 var (
@@ -187,7 +186,7 @@ func NewDaemon(config Config) (*Daemon, error) {
 		contentCfg.WorkingArea += config.WorkspaceController.WorkingAreaSuffix
 		contentCfg.WorkingAreaNode += config.WorkspaceController.WorkingAreaSuffix
 
-		xfs, err := quota.NewXFS(contentCfg.WorkingArea)
+		xfs, err := contentCfg.StorageQuota.NewXFS(contentCfg.WorkingArea)
 		if err != nil {
 			return nil, err
 		}
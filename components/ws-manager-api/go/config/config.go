@@ -82,6 +82,11 @@ type Configuration struct {
 	SecretsNamespace string `json:"secretsNamespace"`
 	// SchedulerName is the name of the workspace scheduler all pods are created with
 	SchedulerName string `json:"schedulerName"`
+	// ImagePullSecrets are the secrets the workspace pods pull their images with
+	ImagePullSecrets []string `json:"imagePullSecrets,omitempty"`
+	// SSHUserCAPath is a file with the public keys of the SSH certificate authorities whose user
+	// certificates the workspaces accept, in the format of an authorized_keys file
+	SSHUserCAPath string `json:"sshUserCAPath,omitempty"`
 	// SeccompProfile names the seccomp profile workspaces will use
 	SeccompProfile string `json:"seccompProfile"`
 	// Timeouts configures how long workspaces can be without activity before they're shut down.
@@ -205,6 +210,32 @@ type WorkspaceDaemonConfiguration struct {
 		// PrivateKey is the private key in order to use the certificate
 		PrivateKey string `json:"key"`
 	} `json:"tls"`
+	// GRPC overrides the defaults of the connections to ws-daemon
+	GRPC *grpc.ClientConfig `json:"grpc,omitempty"`
+	// Namespace is the namespace ws-daemon runs in, defaults to the namespace of the workspaces
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// NamespaceOrDefault returns the namespace of ws-daemon, or the namespace of the workspaces if none is configured
+func (c *WorkspaceDaemonConfiguration) NamespaceOrDefault(def string) string {
+	if c.Namespace == "" {
+		return def
+	}
+	return c.Namespace
+}
+
+// SSHUserCA returns the public keys of the SSH user certificate authorities, or an empty string
+// if none are configured. The file is read on every call, so a changed secret is picked up by
+// the workspaces that start afterwards.
+func (c *Configuration) SSHUserCA() (string, error) {
+	if c.SSHUserCAPath == "" {
+		return "", nil
+	}
+	fc, err := os.ReadFile(c.SSHUserCAPath)
+	if err != nil {
+		return "", xerrors.Errorf("cannot read SSH user CA: %w", err)
+	}
+	return string(bytes.TrimSpace(fc)), nil
 }
 
 // Validate validates the configuration to catch issues during startup and not at runtime
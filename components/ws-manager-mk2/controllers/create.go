@@ -352,6 +352,11 @@ func createDefiniteWorkspacePod(sctx *startWorkspaceContext) (*corev1.Pod, error
 	}
 
 	graceSec := int64(gracePeriod.Seconds())
+	var pullSecrets []corev1.LocalObjectReference
+	for _, name := range sctx.Config.ImagePullSecrets {
+		pullSecrets = append(pullSecrets, corev1.LocalObjectReference{Name: name})
+	}
+
 	pod := corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        fmt.Sprintf("%s-%s", prefix, sctx.Workspace.Name),
@@ -364,6 +369,7 @@ func createDefiniteWorkspacePod(sctx *startWorkspaceContext) (*corev1.Pod, error
 			Hostname:                     sctx.Workspace.Spec.Ownership.WorkspaceID,
 			AutomountServiceAccountToken: pointer.Bool(false),
 			ServiceAccountName:           "workspace",
+			ImagePullSecrets:             pullSecrets,
 			SchedulerName:                sctx.Config.SchedulerName,
 			EnableServiceLinks:           pointer.Bool(false),
 			Affinity:                     affinity,
@@ -533,6 +539,15 @@ func createWorkspaceEnvironment(sctx *startWorkspaceContext) ([]corev1.EnvVar, e
 	result = append(result, corev1.EnvVar{Name: "GITPOD_WORKSPACE_URL", Value: wsUrl})
 	result = append(result, corev1.EnvVar{Name: "GITPOD_WORKSPACE_CLUSTER_HOST", Value: sctx.Config.WorkspaceClusterHost})
 	result = append(result, corev1.EnvVar{Name: "THEIA_SUPERVISOR_ENDPOINT", Value: fmt.Sprintf(":%d", sctx.SupervisorPort)})
+
+	// supervisor lets sshd accept the user certificates of the CAs
+	userCA, err := sctx.Config.SSHUserCA()
+	if err != nil {
+		return nil, err
+	}
+	if userCA != "" {
+		result = append(result, corev1.EnvVar{Name: "GITPOD_SSH_USER_CA", Value: userCA})
+	}
 	// TODO(ak) remove THEIA_WEBVIEW_EXTERNAL_ENDPOINT and THEIA_MINI_BROWSER_HOST_PATTERN when Theia is removed
 	result = append(result, corev1.EnvVar{Name: "THEIA_WEBVIEW_EXTERNAL_ENDPOINT", Value: "webview-{{hostname}}"})
 	result = append(result, corev1.EnvVar{Name: "THEIA_MINI_BROWSER_HOST_PATTERN", Value: "browser-{{hostname}}"})
@@ -72,12 +72,16 @@ func main() {
 	var configFN string
 	var jsonLog bool
 	var verbose bool
+	var enableWebhooks bool
+	var webhookCertDir string
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
 	flag.StringVar(&configFN, "config", "", "Path to the config file")
 	flag.BoolVar(&jsonLog, "json-log", true, "produce JSON log output on verbose level")
 	flag.BoolVar(&verbose, "verbose", false, "Enable verbose logging")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false, "Serve the admission webhooks of the workspace resources")
+	flag.StringVar(&webhookCertDir, "webhook-cert-dir", "", "Directory of the tls.crt and tls.key of the webhook server, defaults to the controller-runtime one")
 	flag.Parse()
 
 	log.Init(ServiceName, Version, jsonLog, verbose)
@@ -124,6 +128,7 @@ func main() {
 		Scheme:                 scheme,
 		MetricsBindAddress:     cfg.Prometheus.Addr,
 		Port:                   9443,
+		CertDir:                webhookCertDir,
 		HealthProbeBindAddress: cfg.Health.Addr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "ws-manager-mk2-leader.gitpod.io",
@@ -174,10 +179,12 @@ func main() {
 		os.Exit(1)
 	}
 
-	// if err = (&workspacev1.Workspace{}).SetupWebhookWithManager(mgr); err != nil {
-	// 	setupLog.Error(err, "unable to create webhook", "webhook", "Workspace")
-	// 	os.Exit(1)
-	// }
+	if enableWebhooks {
+		if err = (&workspacev1.Workspace{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Workspace")
+			os.Exit(1)
+		}
+	}
 
 	//+kubebuilder:scaffold:builder
 
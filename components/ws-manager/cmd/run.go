@@ -25,6 +25,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
 
@@ -68,6 +69,12 @@ var runCmd = &cobra.Command{
 			LeaderElectionID:       "ws-manager-leader.gitpod.io",
 		}
 
+		// ws-daemon can run in a namespace of its own, which the cache has to watch as well
+		if ns := cfg.Manager.WorkspaceDaemon.NamespaceOrDefault(cfg.Manager.Namespace); ns != cfg.Manager.Namespace {
+			opts.Namespace = ""
+			opts.NewCache = cache.MultiNamespacedCacheBuilder([]string{cfg.Manager.Namespace, ns})
+		}
+
 		if cfg.Prometheus.Addr != "" {
 			opts.MetricsBindAddress = cfg.Prometheus.Addr
 			err := metrics.Registry.Register(common_grpc.ClientMetrics())
@@ -126,7 +126,7 @@ const (
 	stopWorkspaceNormallyGracePeriod = 30 * time.Second
 	// stopWorkspaceImmediatelyGracePeriod is the grace period we use when stopping a pod as soon as possbile
 	stopWorkspaceImmediatelyGracePeriod = 1 * time.Second
-	// wsdaemonDialTimeout is the time we allow for trying to connect to ws-daemon.
+	// wsdaemonDialTimeout is the time we allow for trying to connect to ws-daemon, unless the config sets one.
 	// Note: this is NOT the time we allow for RPC calls to wsdaemon, but just for establishing the connection.
 	wsdaemonDialTimeout = 10 * time.Second
 
@@ -153,7 +153,7 @@ func New(config config.Configuration, client client.Client, rawClient kubernetes
 		Content:              cp,
 		clock:                clock.System(),
 		subscribers:          make(map[string]chan *api.SubscribeResponse),
-		wsdaemonPool:         grpcpool.New(wsdaemonConnfactory, checkWSDaemonEndpoint(config.Namespace, client)),
+		wsdaemonPool:         grpcpool.New(wsdaemonConnfactory, checkWSDaemonEndpoint(config.WorkspaceDaemon.NamespaceOrDefault(config.Namespace), client)),
 		eventRecorder:        eventRecorder,
 	}
 	m.metrics = newMetrics(m)
@@ -1531,7 +1531,7 @@ func (m *Manager) connectToWorkspaceDaemon(ctx context.Context, wso workspaceObj
 		var podList corev1.PodList
 		err := m.Clientset.List(ctx, &podList,
 			&client.ListOptions{
-				Namespace: m.Config.Namespace,
+				Namespace: m.Config.WorkspaceDaemon.NamespaceOrDefault(m.Config.Namespace),
 				LabelSelector: labels.SelectorFromSet(labels.Set{
 					"component": "ws-daemon",
 					"app":       "gitpod",
@@ -1683,7 +1683,7 @@ func (m *Manager) waitForWorkspaceVolumeSnapshotReady(ctx context.Context, pvcVo
 func newWssyncConnectionFactory(managerConfig config.Configuration) (grpcpool.Factory, error) {
 	cfg := managerConfig.WorkspaceDaemon
 	// TODO(cw): add client-side gRPC metrics
-	grpcOpts := common_grpc.DefaultClientOptions()
+	grpcOpts := common_grpc.ClientOptions(cfg.GRPC)
 	if cfg.TLS.Authority != "" || cfg.TLS.Certificate != "" && cfg.TLS.PrivateKey != "" {
 		tlsConfig, err := common_grpc.ClientAuthTLSConfig(
 			cfg.TLS.Authority, cfg.TLS.Certificate, cfg.TLS.PrivateKey,
@@ -1700,11 +1700,12 @@ func newWssyncConnectionFactory(managerConfig config.Configuration) (grpcpool.Fa
 		grpcOpts = append(grpcOpts, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	}
 	port := cfg.Port
+	dialTimeout := cfg.GRPC.DialTimeoutOrDefault(wsdaemonDialTimeout)
 
 	return func(host string) (*grpc.ClientConn, error) {
 		var (
 			addr           = fmt.Sprintf("%s:%d", host, port)
-			conctx, cancel = context.WithTimeout(context.Background(), wsdaemonDialTimeout)
+			conctx, cancel = context.WithTimeout(context.Background(), dialTimeout)
 		)
 		// Canceling conctx becomes a no-op once the connection is established.
 		// Because we use WithBlock in the opts DialContext will only return once the connection is established.
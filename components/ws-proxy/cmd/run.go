@@ -160,6 +160,17 @@ var runCmd = &cobra.Command{
 			}
 			if len(signers) > 0 {
 				server := sshproxy.New(signers, infoprov, heartbeat)
+				if cfg.SSHUserCA != "" {
+					b, err := os.ReadFile(cfg.SSHUserCA)
+					if err != nil {
+						log.WithError(err).Fatal("cannot read SSH user CA")
+					}
+					userCAs, err := sshproxy.ParseUserCAs(b)
+					if err != nil {
+						log.WithError(err).Fatal("cannot parse SSH user CA")
+					}
+					server.TrustUserCAs(userCAs)
+				}
 				l, err := net.Listen("tcp", ":2200")
 				if err != nil {
 					panic(err)
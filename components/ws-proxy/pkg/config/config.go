@@ -23,6 +23,9 @@ type Config struct {
 	Namespace          string                       `json:"namespace"`
 	WorkspaceManager   *WorkspaceManagerConn        `json:"wsManager"`
 	EnableWorkspaceCRD bool                         `json:"enableWorkspaceCRD"`
+	// SSHUserCA is a file with the public keys of the certificate authorities whose user
+	// certificates the SSH gateway accepts for the workspaces of the certificate's principal
+	SSHUserCA string `json:"sshUserCA,omitempty"`
 }
 
 type WorkspaceManagerConn struct {
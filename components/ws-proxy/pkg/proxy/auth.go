@@ -17,7 +17,9 @@ import (
 )
 
 // WorkspaceAuthHandler rejects requests which are not authenticated or authorized to access a workspace.
-func WorkspaceAuthHandler(domain string, info WorkspaceInfoProvider) mux.MiddlewareFunc {
+// Requests to ports the policy does not allow are rejected, and public ports are private if the policy
+// disables them.
+func WorkspaceAuthHandler(domain string, info WorkspaceInfoProvider, policy *PortPolicy) mux.MiddlewareFunc {
 	return func(h http.Handler) http.Handler {
 		cookiePrefix := domain
 		for _, c := range []string{" ", "-", "."} {
@@ -47,6 +49,20 @@ func WorkspaceAuthHandler(domain string, info WorkspaceInfoProvider) mux.Middlew
 				return
 			}
 
+			var prt uint64
+			if port != "" {
+				var err error
+				prt, err = strconv.ParseUint(port, 10, 16)
+				if err != nil {
+					log.WithField("port", port).WithError(err).Error("cannot convert port to int")
+				} else if !policy.Allowed(uint16(prt)) {
+					log.WithField("port", port).Debug("port is not allowed by the port policy")
+					resp.WriteHeader(http.StatusForbidden)
+
+					return
+				}
+			}
+
 			if ws.Auth != nil && ws.Auth.Admission == api.AdmissionLevel_ADMIT_EVERYONE {
 				// workspace is free for all - no tokens or cookies matter
 				h.ServeHTTP(resp, req)
@@ -60,10 +76,7 @@ func WorkspaceAuthHandler(domain string, info WorkspaceInfoProvider) mux.Middlew
 				// to the same access policies as the workspace itself is.
 				var isPublic bool
 
-				prt, err := strconv.ParseUint(port, 10, 16)
-				if err != nil {
-					log.WithField("port", port).WithError(err).Error("cannot convert port to int")
-				} else {
+				if prt != 0 && (policy == nil || !policy.DisablePublic) {
 					for _, p := range ws.Ports {
 						if p.Port == uint32(prt) {
 							isPublic = p.Visibility == api.PortVisibility_PORT_VISIBILITY_PUBLIC
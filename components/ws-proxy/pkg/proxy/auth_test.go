@@ -70,6 +70,7 @@ func TestWorkspaceAuthHandler(t *testing.T) {
 		OwnerCookie string
 		WorkspaceID string
 		Port        string
+		Policy      *PortPolicy
 		Expected    testResult
 	}{
 		{
@@ -197,6 +198,40 @@ func TestWorkspaceAuthHandler(t *testing.T) {
 				StatusCode:    http.StatusOK,
 			},
 		},
+		{
+			Name:        "public port without cookie with public ports disabled",
+			Infos:       publicPortInfos,
+			WorkspaceID: workspaceID,
+			Port:        strconv.Itoa(testPort),
+			Policy:      &PortPolicy{DisablePublic: true},
+			Expected: testResult{
+				HandlerCalled: false,
+				StatusCode:    http.StatusUnauthorized,
+			},
+		},
+		{
+			Name:        "private port with owner cookie outside of the allowed ranges",
+			Infos:       ownerOnlyInfos,
+			WorkspaceID: workspaceID,
+			Port:        strconv.Itoa(testPort),
+			OwnerCookie: ownerToken,
+			Policy:      &PortPolicy{AllowedRanges: []PortRange{{From: 3000, To: 3999}}},
+			Expected: testResult{
+				HandlerCalled: false,
+				StatusCode:    http.StatusForbidden,
+			},
+		},
+		{
+			Name:        "public port without cookie in the allowed ranges",
+			Infos:       publicPortInfos,
+			WorkspaceID: workspaceID,
+			Port:        strconv.Itoa(testPort),
+			Policy:      &PortPolicy{AllowedRanges: []PortRange{{From: 3000, To: 3999}, {From: testPort, To: testPort}}},
+			Expected: testResult{
+				HandlerCalled: true,
+				StatusCode:    http.StatusOK,
+			},
+		},
 		{
 			Name:        "broken port",
 			Infos:       publicPortInfos,
@@ -223,7 +258,7 @@ func TestWorkspaceAuthHandler(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.Name, func(t *testing.T) {
 			var res testResult
-			handler := WorkspaceAuthHandler(domain, &fixedInfoProvider{Infos: test.Infos})(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+			handler := WorkspaceAuthHandler(domain, &fixedInfoProvider{Infos: test.Infos}, test.Policy)(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
 				res.HandlerCalled = true
 				resp.WriteHeader(http.StatusOK)
 			}))
@@ -26,6 +26,8 @@ type Config struct {
 	BlobServer         *BlobServerConfig   `json:"blobServer"`
 	GitpodInstallation *GitpodInstallation `json:"gitpodInstallation"`
 	WorkspacePodConfig *WorkspacePodConfig `json:"workspacePodConfig"`
+	PortPolicy         *PortPolicy         `json:"portPolicy,omitempty"`
+	ServerTimeouts     *ServerTimeouts     `json:"serverTimeouts,omitempty"`
 
 	BuiltinPages BuiltinPagesConfig `json:"builtinPages"`
 }
@@ -40,6 +42,8 @@ func (c *Config) Validate() error {
 		c.BlobServer,
 		c.GitpodInstallation,
 		c.WorkspacePodConfig,
+		c.PortPolicy,
+		c.ServerTimeouts,
 	} {
 		err := v.Validate()
 		if err != nil {
@@ -103,6 +107,59 @@ func (c *WorkspacePodConfig) Validate() error {
 	return nil
 }
 
+// PortPolicy limits which workspace ports are served and who can access them.
+type PortPolicy struct {
+	// DisablePublic serves public ports like private ones, i.e. only to the owner of the workspace
+	DisablePublic bool `json:"disablePublic,omitempty"`
+	// AllowedRanges are the ports that are served. All ports are served if there are none.
+	AllowedRanges []PortRange `json:"allowedRanges,omitempty"`
+	// Protocol is used to connect to the ports, either http or https. Defaults to http.
+	Protocol string `json:"protocol,omitempty"`
+}
+
+// PortRange is a range of ports, including From and To.
+type PortRange struct {
+	From uint16 `json:"from"`
+	To   uint16 `json:"to"`
+}
+
+// Validate validates the configuration to catch issues during startup and not at runtime.
+func (c *PortPolicy) Validate() error {
+	if c == nil {
+		return nil
+	}
+	if c.Protocol != "" && c.Protocol != "http" && c.Protocol != "https" {
+		return xerrors.Errorf("invalid port protocol %s", c.Protocol)
+	}
+	for _, r := range c.AllowedRanges {
+		if r.From == 0 || r.From > r.To {
+			return xerrors.Errorf("invalid port range %d-%d", r.From, r.To)
+		}
+	}
+	return nil
+}
+
+// Allowed returns true if the port may be served.
+func (c *PortPolicy) Allowed(port uint16) bool {
+	if c == nil || len(c.AllowedRanges) == 0 {
+		return true
+	}
+	for _, r := range c.AllowedRanges {
+		if port >= r.From && port <= r.To {
+			return true
+		}
+	}
+	return false
+}
+
+// Scheme returns the scheme of the ports.
+func (c *PortPolicy) Scheme() string {
+	if c == nil || c.Protocol == "" {
+		return "http"
+	}
+	return c.Protocol
+}
+
 // GitpodInstallation contains config regarding the Gitpod installation.
 type GitpodInstallation struct {
 	Scheme                   string `json:"scheme"`
@@ -169,6 +226,29 @@ func (c *TransportConfig) Validate() error {
 	)
 }
 
+// ServerTimeouts configures the timeouts of the HTTPS server of ws-proxy. A zero timeout
+// means there is none. The read and write timeouts do not apply to WebSocket connections.
+type ServerTimeouts struct {
+	ReadHeader util.Duration `json:"readHeader,omitempty"`
+	Read       util.Duration `json:"read,omitempty"`
+	Write      util.Duration `json:"write,omitempty"`
+	Idle       util.Duration `json:"idle,omitempty"`
+}
+
+// Validate validates the configuration to catch issues during startup and not at runtime.
+func (c *ServerTimeouts) Validate() error {
+	if c == nil {
+		return nil
+	}
+
+	return validation.ValidateStruct(c,
+		validation.Field(&c.ReadHeader, validation.Min(util.Duration(0))),
+		validation.Field(&c.Read, validation.Min(util.Duration(0))),
+		validation.Field(&c.Write, validation.Min(util.Duration(0))),
+		validation.Field(&c.Idle, validation.Min(util.Duration(0))),
+	)
+}
+
 // BuiltinPagesConfig configures pages served directly by ws-proxy.
 type BuiltinPagesConfig struct {
 	Location string `json:"location"`
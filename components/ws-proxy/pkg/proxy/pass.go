@@ -250,6 +250,12 @@ func withXFrameOptionsFilter() proxyPassOpt {
 	}
 }
 
+func withTransport(transport http.RoundTripper) proxyPassOpt {
+	return func(cfg *proxyPassConfig) {
+		cfg.Transport = transport
+	}
+}
+
 func withUseTargetHost() proxyPassOpt {
 	return func(cfg *proxyPassConfig) {
 		cfg.UseTargetHost = true
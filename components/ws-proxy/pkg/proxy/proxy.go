@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/klauspost/cpuid/v2"
@@ -68,6 +69,13 @@ func (p *WorkspaceProxy) MustServe() {
 		},
 		ErrorLog: stdlog.New(logrusErrorWriter{}, "", 0),
 	}
+	if t := p.Config.ServerTimeouts; t != nil {
+		// net/http clears the deadlines of the connections that are hijacked to be upgraded
+		srv.ReadHeaderTimeout = time.Duration(t.ReadHeader)
+		srv.ReadTimeout = time.Duration(t.Read)
+		srv.WriteTimeout = time.Duration(t.Write)
+		srv.IdleTimeout = time.Duration(t.Idle)
+	}
 
 	var (
 		crt = p.Config.HTTPS.Certificate
@@ -7,6 +7,7 @@ package proxy
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -43,7 +44,7 @@ type RouteHandlerConfigOpt func(*Config, *RouteHandlerConfig)
 // WithDefaultAuth enables workspace access authentication.
 func WithDefaultAuth(infoprov WorkspaceInfoProvider) RouteHandlerConfigOpt {
 	return func(config *Config, c *RouteHandlerConfig) {
-		c.WorkspaceAuthHandler = WorkspaceAuthHandler(config.GitpodInstallation.HostName, infoprov)
+		c.WorkspaceAuthHandler = WorkspaceAuthHandler(config.GitpodInstallation.HostName, infoprov, config.PortPolicy)
 	}
 }
 
@@ -408,6 +409,17 @@ func installWorkspacePortRoutes(r *mux.Router, config *RouteHandlerConfig, infoP
 	// filter all session cookies
 	r.Use(sensitiveCookieHandler(config.Config.GitpodInstallation.HostName))
 
+	opts := []proxyPassOpt{
+		withHTTPErrorHandler(showPortNotFoundPage),
+		withXFrameOptionsFilter(),
+	}
+	if config.Config.PortPolicy.Scheme() == "https" {
+		// ports serving https mostly do so with self-signed certificates
+		transport := createDefaultTransport(config.Config.TransportConfig)
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		opts = append(opts, withTransport(transport))
+	}
+
 	// forward request to workspace port
 	r.NewRoute().HandlerFunc(
 		func(rw http.ResponseWriter, r *http.Request) {
@@ -432,8 +444,7 @@ func installWorkspacePortRoutes(r *mux.Router, config *RouteHandlerConfig, infoP
 				config,
 				infoProvider,
 				workspacePodPortResolver,
-				withHTTPErrorHandler(showPortNotFoundPage),
-				withXFrameOptionsFilter(),
+				opts...,
 			)(rw, r)
 		},
 	)
@@ -458,13 +469,10 @@ func workspacePodResolver(config *Config, infoProvider WorkspaceInfoProvider, re
 func workspacePodPortResolver(config *Config, infoProvider WorkspaceInfoProvider, req *http.Request) (url *url.URL, err error) {
 	coords := getWorkspaceCoords(req)
 	workspaceInfo := infoProvider.WorkspaceInfo(coords.ID)
-	var port string
 	if coords.Debug {
-		port = fmt.Sprint(config.WorkspacePodConfig.DebugWorkspaceProxyPort)
-	} else {
-		port = coords.Port
+		return buildWorkspacePodURL(workspaceInfo.IPAddress, fmt.Sprint(config.WorkspacePodConfig.DebugWorkspaceProxyPort))
 	}
-	return buildWorkspacePodURL(workspaceInfo.IPAddress, port)
+	return url.Parse(fmt.Sprintf("%s://%v:%v", config.PortPolicy.Scheme(), workspaceInfo.IPAddress, coords.Port))
 }
 
 // workspacePodSupervisorResolver resolves to the workspace pods Supervisor url from the given request.
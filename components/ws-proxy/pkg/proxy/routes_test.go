@@ -23,6 +23,7 @@ import (
 
 	"github.com/gitpod-io/golang-crypto/ssh"
 	"github.com/google/go-cmp/cmp"
+	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 
 	"github.com/gitpod-io/gitpod/common-go/log"
@@ -871,6 +872,40 @@ func (p *fakeWsInfoProvider) WorkspaceCoords(wsProxyPort string) *WorkspaceCoord
 	return nil
 }
 
+func TestWorkspacePodPortResolver(t *testing.T) {
+	infoProvider := &fakeWsInfoProvider{infos: []WorkspaceInfo{{WorkspaceID: workspaces[0].WorkspaceID, IPAddress: "10.0.0.1"}}}
+	tests := []struct {
+		Desc        string
+		Policy      *PortPolicy
+		Debug       bool
+		Expectation string
+	}{
+		{Desc: "no policy", Expectation: "http://10.0.0.1:28080"},
+		{Desc: "http", Policy: &PortPolicy{Protocol: "http"}, Expectation: "http://10.0.0.1:28080"},
+		{Desc: "https", Policy: &PortPolicy{Protocol: "https"}, Expectation: "https://10.0.0.1:28080"},
+		{Desc: "https debug workspace", Policy: &PortPolicy{Protocol: "https"}, Debug: true, Expectation: fmt.Sprintf("http://10.0.0.1:%d", debugWorkspaceProxyPort)},
+	}
+	for _, test := range tests {
+		t.Run(test.Desc, func(t *testing.T) {
+			cfg := config
+			cfg.PortPolicy = test.Policy
+			req := mux.SetURLVars(httptest.NewRequest("GET", "/", nil), map[string]string{
+				workspaceIDIdentifier:    workspaces[0].WorkspaceID,
+				workspacePortIdentifier:  "28080",
+				debugWorkspaceIdentifier: strconv.FormatBool(test.Debug),
+			})
+
+			act, err := workspacePodPortResolver(&cfg, infoProvider, req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if act.String() != test.Expectation {
+				t.Errorf("unexpected URL: want %s, got %s", test.Expectation, act)
+			}
+		})
+	}
+}
+
 func TestSSHGatewayRouter(t *testing.T) {
 	generatePrivateKey := func() ssh.Signer {
 		prik, err := rsa.GenerateKey(rand.Reader, 2048)
@@ -5,6 +5,7 @@
 package sshproxy
 
 import (
+	"bytes"
 	"context"
 	"crypto/subtle"
 	"net"
@@ -96,6 +97,7 @@ type Server struct {
 
 	sshConfig             *ssh.ServerConfig
 	workspaceInfoProvider proxy.WorkspaceInfoProvider
+	userCAs               []ssh.PublicKey
 }
 
 func init() {
@@ -204,6 +206,26 @@ func New(signers []ssh.Signer, workspaceInfoProvider proxy.WorkspaceInfoProvider
 	return server
 }
 
+// TrustUserCAs accepts the user certificates of the CAs, a certificate authenticates its
+// principal for the workspaces the principal owns
+func (s *Server) TrustUserCAs(keys []ssh.PublicKey) {
+	s.userCAs = keys
+}
+
+// ParseUserCAs parses the public keys of the user CAs, which are in the format of an authorized_keys file
+func ParseUserCAs(b []byte) ([]ssh.PublicKey, error) {
+	var keys []ssh.PublicKey
+	for len(bytes.TrimSpace(b)) > 0 {
+		key, _, _, rest, err := ssh.ParseAuthorizedKey(b)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+		b = rest
+	}
+	return keys, nil
+}
+
 func ReportSSHAttemptMetrics(err error) {
 	if err == nil {
 		SSHAttemptTotal.WithLabelValues("success", "").Inc()
@@ -384,6 +406,9 @@ func (s *Server) TrackSSHConnection(wsInfo *proxy.WorkspaceInfo, phase string, e
 }
 
 func (s *Server) VerifyPublicKey(ctx context.Context, wsInfo *proxy.WorkspaceInfo, pk ssh.PublicKey) (bool, error) {
+	if cert, ok := pk.(*ssh.Certificate); ok {
+		return s.verifyUserCertificate(wsInfo, cert)
+	}
 	for _, keyStr := range wsInfo.SSHPublicKeys {
 		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(keyStr))
 		if err != nil {
@@ -398,6 +423,33 @@ func (s *Server) VerifyPublicKey(ctx context.Context, wsInfo *proxy.WorkspaceInf
 	return false, nil
 }
 
+// verifyUserCertificate accepts a user certificate of a trusted CA that is valid now and has the
+// owner of the workspace as its principal
+func (s *Server) verifyUserCertificate(wsInfo *proxy.WorkspaceInfo, cert *ssh.Certificate) (bool, error) {
+	if cert.CertType != ssh.UserCert {
+		return false, nil
+	}
+	checker := ssh.CertChecker{
+		IsUserAuthority: func(auth ssh.PublicKey) bool {
+			authData := auth.Marshal()
+			for _, ca := range s.userCAs {
+				caData := ca.Marshal()
+				if len(caData) == len(authData) && subtle.ConstantTimeCompare(caData, authData) == 1 {
+					return true
+				}
+			}
+			return false
+		},
+	}
+	if !checker.IsUserAuthority(cert.SignatureKey) {
+		return false, nil
+	}
+	if err := checker.CheckCert(wsInfo.OwnerUserId, cert); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 func (s *Server) GetWorkspaceSSHKey(ctx context.Context, workspaceIP string, supervisorPort string) (ssh.Signer, error) {
 	supervisorConn, err := grpc.Dial(workspaceIP+":"+supervisorPort, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	if err != nil {
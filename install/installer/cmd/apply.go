@@ -0,0 +1,277 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gitpod-io/gitpod/common-go/log"
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	"github.com/gitpod-io/gitpod/installer/pkg/postprocess"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/yaml"
+)
+
+// pruneKeptKinds are never pruned, as deleting them deletes data: the claims hold the data of
+// the stateful sets and a custom resource definition takes all its custom resources with it
+var pruneKeptKinds = map[string]struct{}{
+	"PersistentVolumeClaim":    {},
+	"CustomResourceDefinition": {},
+}
+
+var applyOpts struct {
+	Kube   kubeConfig
+	Prune  bool
+	DryRun bool
+}
+
+// applyCmd represents the apply command
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Renders the Kubernetes manifests and applies them to the cluster",
+	Long: `Renders the Kubernetes manifests and applies them to the cluster
+
+Each rendered object is applied with a server-side apply in the order it is
+rendered in. Every object carries the app.kubernetes.io/managed-by,
+app.kubernetes.io/part-of, gitpod.io/installation and gitpod.io/config-hash
+labels.
+
+With --prune, the objects of the installation that carry these labels but are
+no longer rendered, e.g. those of a renamed or disabled component, are deleted
+after the apply. Persistent volume claims and custom resource definitions are
+never pruned. Pruning needs the full installation to be rendered, so it cannot
+be combined with --component, --target or the pre-upgrade phase.`,
+	Example: `  # Install or upgrade Gitpod and remove the objects that are no longer rendered.
+  gitpod-installer apply --config config.yaml --namespace gitpod --prune
+
+  # Only report what would be applied and pruned.
+  gitpod-installer apply --config config.yaml --namespace gitpod --prune --dry-run`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if applyOpts.Prune {
+			if renderOpts.Component != "" {
+				return fmt.Errorf("--prune and --component cannot be used together")
+			}
+			if renderOpts.Target != "" && renderOpts.Target != renderTargetAll {
+				return fmt.Errorf("--prune and --target %s cannot be used together", renderOpts.Target)
+			}
+			if renderOpts.Phase == renderPhasePreUpgrade {
+				return fmt.Errorf("--prune and --phase %s cannot be used together", renderPhasePreUpgrade)
+			}
+		}
+
+		renderOpts.Cluster = &applyOpts.Kube
+
+		yaml, err := renderFn()
+		if err != nil {
+			return err
+		}
+		objs, err := common.YamlToRuntimeObject(yaml)
+		if err != nil {
+			return err
+		}
+
+		client, mapper, err := dynamicClientFromKubeConfig(&applyOpts.Kube)
+		if err != nil {
+			return err
+		}
+
+		ctx := context.Background()
+		a := &applier{client: client, mapper: mapper, rendered: make(map[objectKey]struct{}, len(objs))}
+		for _, obj := range objs {
+			if err := a.apply(ctx, obj); err != nil {
+				return err
+			}
+		}
+
+		if applyOpts.Prune {
+			if err := a.prune(ctx, renderOpts.Namespace, objs); err != nil {
+				return err
+			}
+		}
+
+		log.Infof("%d objects applied, %d pruned", a.applied, a.pruned)
+		return nil
+	},
+}
+
+// objectKey identifies an object in the cluster, the namespace is empty for cluster-scoped objects
+type objectKey struct {
+	Group     string
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+func keyOf(obj *unstructured.Unstructured, namespace string) objectKey {
+	return objectKey{
+		Group:     obj.GroupVersionKind().Group,
+		Kind:      obj.GetKind(),
+		Namespace: namespace,
+		Name:      obj.GetName(),
+	}
+}
+
+type applier struct {
+	client dynamic.Interface
+	mapper meta.RESTMapper
+
+	// rendered are the objects that have been applied
+	rendered map[objectKey]struct{}
+
+	applied int
+	pruned  int
+}
+
+func (a *applier) apply(ctx context.Context, obj common.RuntimeObject) error {
+	var desired unstructured.Unstructured
+	if err := yaml.Unmarshal([]byte(obj.Content), &desired.Object); err != nil {
+		return err
+	}
+
+	resource, namespace, err := resourceForObject(a.client, a.mapper, &desired)
+	if meta.IsNoMatchError(err) {
+		// The kind may be of a custom resource definition that has only just been applied
+		if resettable, ok := a.mapper.(meta.ResettableRESTMapper); ok {
+			resettable.Reset()
+			resource, namespace, err = resourceForObject(a.client, a.mapper, &desired)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(desired.Object)
+	if err != nil {
+		return err
+	}
+	opts := metav1.PatchOptions{
+		FieldManager: fieldManager,
+		Force:        pointer.Bool(true),
+	}
+	if applyOpts.DryRun {
+		opts.DryRun = []string{metav1.DryRunAll}
+	}
+	_, err = resource.Patch(ctx, desired.GetName(), types.ApplyPatchType, data, opts)
+	if err != nil {
+		return fmt.Errorf("cannot apply %s %s: %w", desired.GetKind(), desired.GetName(), err)
+	}
+
+	log.Infof("applied %s %s", desired.GetKind(), desired.GetName())
+	a.rendered[keyOf(&desired, namespace)] = struct{}{}
+	a.applied++
+	return nil
+}
+
+// prune deletes the objects with the owner labels of the installation that have not been
+// applied. The kinds that are looked at are those of the render and of the installed objects.
+func (a *applier) prune(ctx context.Context, namespace string, objs []common.RuntimeObject) error {
+	installed, err := installedObjects(ctx, a.client, namespace)
+	if err != nil {
+		// A new installation has nothing to prune but the objects of the render
+		log.WithError(err).Warn("cannot read the installed objects, only pruning the kinds that are rendered")
+	}
+
+	kinds := make(map[schema.GroupVersionKind]struct{})
+	for _, obj := range append(append([]common.RuntimeObject{}, objs...), installed...) {
+		kinds[obj.GroupVersionKind()] = struct{}{}
+	}
+
+	selector := postprocess.OwnerSelector(namespace)
+	var live []unstructured.Unstructured
+	for gvk := range kinds {
+		mapping, err := a.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if meta.IsNoMatchError(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		list, err := a.client.Resource(mapping.Resource).List(ctx, metav1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			return fmt.Errorf("cannot list the %s: %w", mapping.Resource.Resource, err)
+		}
+		live = append(live, list.Items...)
+	}
+
+	for _, obj := range pruneCandidates(a.rendered, live) {
+		resource, _, err := resourceForObject(a.client, a.mapper, &obj)
+		if err != nil {
+			return err
+		}
+
+		opts := metav1.DeleteOptions{PropagationPolicy: &deletePropagationBackground}
+		if applyOpts.DryRun {
+			opts.DryRun = []string{metav1.DryRunAll}
+		}
+		err = resource.Delete(ctx, obj.GetName(), opts)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return fmt.Errorf("cannot prune %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+		log.Infof("pruned %s %s", obj.GetKind(), obj.GetName())
+		a.pruned++
+	}
+	return nil
+}
+
+var deletePropagationBackground = metav1.DeletePropagationBackground
+
+// pruneCandidates returns the live objects that have not been rendered, except those of the
+// kinds that are never pruned
+func pruneCandidates(rendered map[objectKey]struct{}, live []unstructured.Unstructured) []unstructured.Unstructured {
+	var res []unstructured.Unstructured
+	seen := make(map[objectKey]struct{}, len(live))
+	for _, obj := range live {
+		if _, keep := pruneKeptKinds[obj.GetKind()]; keep {
+			continue
+		}
+		key := keyOf(&obj, obj.GetNamespace())
+		if _, ok := rendered[key]; ok {
+			continue
+		}
+		// The same object is listed once for each version of its kind
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		res = append(res, obj)
+	}
+	return res
+}
+
+func init() {
+	rootCmd.AddCommand(applyCmd)
+
+	dir, err := os.Getwd()
+	if err != nil {
+		log.WithError(err).Fatal("Failed to get working directory")
+	}
+
+	applyCmd.Flags().StringVarP(&renderOpts.ConfigFN, "config", "c", getEnvvar("GITPOD_INSTALLER_CONFIG", filepath.Join(dir, "gitpod.config.yaml")), "path to the config file, use - for stdin")
+	applyCmd.Flags().StringVarP(&renderOpts.Namespace, "namespace", "n", getEnvvar("NAMESPACE", "default"), "namespace to deploy to")
+	applyCmd.Flags().BoolVar(&renderOpts.ValidateConfigDisabled, "no-validation", false, "if set, the config will not be validated before running")
+	applyCmd.Flags().BoolVar(&renderOpts.UseExperimentalConfig, "use-experimental-config", false, "enable the use of experimental config that is prone to be changed")
+	applyCmd.Flags().BoolVar(&renderOpts.NoCache, "no-cache", false, "resolve the digests of the images against the registries instead of using the cached digests")
+	applyCmd.Flags().BoolVar(&renderOpts.NoExpiredCache, "no-expired-cache", false, "fail instead of using an expired cached digest if a registry cannot be reached")
+	applyCmd.Flags().StringVar(&renderOpts.Component, "component", "", "only apply the objects of the named component, eg server")
+	applyCmd.Flags().StringVar(&renderOpts.Target, "target", renderTargetAll, "apply the objects of the meta or workspace cluster of a full installation only")
+	applyCmd.Flags().StringVar(&renderOpts.Phase, "phase", renderPhaseAll, "apply the pre-upgrade or upgrade phase of an upgrade only")
+	applyCmd.Flags().StringVar(&applyOpts.Kube.Config, "kubeconfig", "", "path to the kubeconfig file")
+	applyCmd.Flags().BoolVar(&applyOpts.Prune, "prune", false, "delete the objects of the installation that are no longer rendered")
+	applyCmd.Flags().BoolVar(&applyOpts.DryRun, "dry-run", false, "only report what would be applied and pruned")
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestPruneCandidates(t *testing.T) {
+	object := func(apiVersion, kind, namespace, name string) unstructured.Unstructured {
+		var res unstructured.Unstructured
+		res.SetAPIVersion(apiVersion)
+		res.SetKind(kind)
+		res.SetNamespace(namespace)
+		res.SetName(name)
+		return res
+	}
+
+	rendered := map[objectKey]struct{}{
+		{Group: "apps", Kind: "Deployment", Namespace: "gitpod", Name: "server"}:         {},
+		{Group: "rbac.authorization.k8s.io", Kind: "ClusterRole", Name: "gitpod-server"}: {},
+	}
+	live := []unstructured.Unstructured{
+		object("apps/v1", "Deployment", "gitpod", "server"),
+		object("rbac.authorization.k8s.io/v1", "ClusterRole", "", "gitpod-server"),
+		// renamed to server
+		object("apps/v1", "Deployment", "gitpod", "webapp"),
+		object("apps/v1beta1", "Deployment", "gitpod", "webapp"),
+		// the same name in another namespace is another object
+		object("apps/v1", "Deployment", "other", "server"),
+		object("v1", "PersistentVolumeClaim", "gitpod", "data-mysql-0"),
+		object("apiextensions.k8s.io/v1", "CustomResourceDefinition", "", "workspaces.workspace.gitpod.io"),
+	}
+
+	var pruned []string
+	for _, obj := range pruneCandidates(rendered, live) {
+		pruned = append(pruned, obj.GetNamespace()+"/"+obj.GetName())
+	}
+	require.Equal(t, []string{"gitpod/webapp", "other/server"}, pruned)
+}
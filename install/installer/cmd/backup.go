@@ -0,0 +1,233 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gitpod-io/gitpod/common-go/log"
+	"github.com/gitpod-io/gitpod/installer/pkg/backup"
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	"github.com/gitpod-io/gitpod/installer/pkg/components/gitpod"
+	"github.com/gitpod-io/gitpod/installer/pkg/config"
+	configv1 "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// backupPassphraseEnv is the environment variable the passphrase of the archive is read from
+const backupPassphraseEnv = "GITPOD_INSTALLER_BACKUP_PASSPHRASE"
+
+var gvrSecrets = schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+
+var backupOpts struct {
+	Kube           kubeConfig
+	Output         string
+	PassphraseFile string
+}
+
+// backupCmd represents the backup command
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Exports the config and secrets of an installation into an encrypted archive",
+	Long: `Exports the config and secrets of an installation into an encrypted archive
+
+The archive contains the installed config from the gitpod config map, the
+secrets the installer generated and the secrets of the auth providers the
+config references, in whichever namespace they are. Use "restore" to import
+the archive into the same or another cluster.
+
+The archive is encrypted with a key derived from the passphrase, which is read
+from the file given with --passphrase-file or the ` + backupPassphraseEnv + `
+environment variable.
+
+The data of the database and the object storage is not part of the archive,
+nor are the secrets issued by cert-manager, which are issued again.`,
+	Example: `  # Back up the installation in the gitpod namespace.
+  gitpod-installer backup --namespace gitpod --passphrase-file passphrase.txt --output gitpod-backup.bin`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if backupOpts.Output == "" {
+			return fmt.Errorf("--output must be set to the file the archive is written to")
+		}
+		passphrase, err := readBackupPassphrase(backupOpts.PassphraseFile)
+		if err != nil {
+			return err
+		}
+
+		client, _, err := dynamicClientFromKubeConfig(&backupOpts.Kube)
+		if err != nil {
+			return err
+		}
+		archive, err := collectBackup(context.Background(), client, renderOpts.Namespace, time.Now())
+		if err != nil {
+			return err
+		}
+
+		f, err := os.OpenFile(backupOpts.Output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if err := backup.Write(f, passphrase, archive); err != nil {
+			os.Remove(backupOpts.Output)
+			return err
+		}
+
+		log.Infof("config and %d secrets of version %s written to %s", len(archive.Secrets), archive.Manifest.Version, backupOpts.Output)
+		return nil
+	},
+}
+
+// readBackupPassphrase reads the passphrase from the file, or the environment if no file is given
+func readBackupPassphrase(fn string) ([]byte, error) {
+	var passphrase []byte
+	if fn != "" {
+		fc, err := os.ReadFile(fn)
+		if err != nil {
+			return nil, err
+		}
+		passphrase = bytes.TrimRight(fc, "\r\n")
+	} else {
+		passphrase = []byte(os.Getenv(backupPassphraseEnv))
+	}
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("a passphrase must be given with --passphrase-file or %s", backupPassphraseEnv)
+	}
+	return passphrase, nil
+}
+
+// collectBackup reads the installed config and the secrets of the installation in the namespace
+func collectBackup(ctx context.Context, client dynamic.Interface, namespace string, created time.Time) (*backup.Archive, error) {
+	cfgMap, err := client.Resource(gvrConfigMaps).Namespace(namespace).Get(ctx, gitpod.Component, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, fmt.Errorf("there is no installation in namespace %s - the %s config map does not exist", namespace, gitpod.Component)
+	} else if err != nil {
+		return nil, err
+	}
+	data, _, err := unstructured.NestedStringMap(cfgMap.Object, "data")
+	if err != nil {
+		return nil, err
+	}
+
+	var installed gitpod.Gitpod
+	if err := json.Unmarshal([]byte(data["versions.json"]), &installed); err != nil {
+		return nil, fmt.Errorf("cannot read the installed versions: %w", err)
+	}
+	rawCfg, _, err := config.Load(data["config.yaml"], false)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the installed config: %w", err)
+	}
+	cfg, ok := rawCfg.(*configv1.Config)
+	if !ok {
+		return nil, fmt.Errorf("cannot back up a config of version %T", rawCfg)
+	}
+
+	res := &backup.Archive{
+		Manifest: backup.Manifest{
+			Version:   installed.VersionManifest.Version,
+			Namespace: namespace,
+			Created:   created.UTC(),
+		},
+		Config: []byte(data["config.yaml"]),
+	}
+
+	objs, err := installedObjects(ctx, client, namespace)
+	if err != nil {
+		return nil, err
+	}
+	var secrets []backup.Secret
+	for _, obj := range objs {
+		if obj.Kind != common.TypeMetaSecret.Kind {
+			continue
+		}
+		ns := obj.Metadata.Namespace
+		if ns == "" {
+			ns = namespace
+		}
+		secrets = append(secrets, backup.Secret{Namespace: ns, Name: obj.Metadata.Name, Kind: backup.SecretGenerated})
+	}
+	for _, name := range authProviderSecrets(cfg) {
+		secrets = append(secrets, backup.Secret{Namespace: namespace, Name: name, Kind: backup.SecretAuthProvider})
+	}
+
+	seen := make(map[string]struct{}, len(secrets))
+	for _, s := range secrets {
+		key := s.Namespace + "/" + s.Name
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		secret, err := client.Resource(gvrSecrets).Namespace(s.Namespace).Get(ctx, s.Name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			log.Warnf("secret %s does not exist and is not backed up", key)
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("cannot read secret %s: %w", key, err)
+		}
+		res.Manifest.Secrets = append(res.Manifest.Secrets, s)
+		res.Secrets = append(res.Secrets, portableSecret(secret))
+	}
+
+	return res, nil
+}
+
+// authProviderSecrets returns the names of the secrets of the auth providers in the config
+func authProviderSecrets(cfg *configv1.Config) []string {
+	var res []string
+	for _, ref := range cfg.AuthProviders {
+		if ref.Kind == configv1.ObjectRefSecret {
+			res = append(res, ref.Name)
+		}
+	}
+	if cfg.Components != nil && cfg.Components.Server != nil {
+		for _, p := range cfg.Components.Server.AuthProviders {
+			if p.ClientSecret.Kind == configv1.ObjectRefSecret {
+				res = append(res, p.ClientSecret.Name)
+			}
+		}
+	}
+	return res
+}
+
+// portableSecret removes the fields of the secret that the cluster sets, so that it can be
+// created in another cluster
+func portableSecret(secret *unstructured.Unstructured) *unstructured.Unstructured {
+	res := secret.DeepCopy()
+	for _, field := range []string{"uid", "resourceVersion", "generation", "creationTimestamp", "selfLink", "managedFields", "ownerReferences"} {
+		unstructured.RemoveNestedField(res.Object, "metadata", field)
+	}
+	annotations := res.GetAnnotations()
+	for k := range annotations {
+		if strings.HasPrefix(k, "kubectl.kubernetes.io/") {
+			delete(annotations, k)
+		}
+	}
+	if len(annotations) == 0 {
+		unstructured.RemoveNestedField(res.Object, "metadata", "annotations")
+	} else {
+		res.SetAnnotations(annotations)
+	}
+	return res
+}
+
+func init() {
+	rootCmd.AddCommand(backupCmd)
+
+	backupCmd.Flags().StringVarP(&renderOpts.Namespace, "namespace", "n", getEnvvar("NAMESPACE", "default"), "namespace Gitpod is deployed to")
+	backupCmd.Flags().StringVar(&backupOpts.Kube.Config, "kubeconfig", "", "path to the kubeconfig file")
+	backupCmd.Flags().StringVarP(&backupOpts.Output, "output", "o", "", "path to write the archive to")
+	backupCmd.Flags().StringVar(&backupOpts.PassphraseFile, "passphrase-file", "", fmt.Sprintf("path to a file with the passphrase of the archive, defaults to the %s environment variable", backupPassphraseEnv))
+}
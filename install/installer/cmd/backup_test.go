@@ -0,0 +1,120 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/backup"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func TestBackupRestore(t *testing.T) {
+	object := func(kind, namespace, name string, fields map[string]interface{}) *unstructured.Unstructured {
+		obj := map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       kind,
+			"metadata": map[string]interface{}{
+				"name":            name,
+				"namespace":       namespace,
+				"uid":             "a1b2",
+				"resourceVersion": "42",
+			},
+		}
+		for k, v := range fields {
+			obj[k] = v
+		}
+		return &unstructured.Unstructured{Object: obj}
+	}
+
+	cfg := `apiVersion: v1
+domain: gitpod.example.com
+authProviders:
+  - kind: secret
+    name: github
+components:
+  server:
+    authProviders:
+      - id: gitlab
+        host: gitlab.example.com
+        type: GitLab
+        clientId: gitpod
+        clientSecret:
+          kind: secret
+          name: gitlab
+`
+	app := `apiVersion: v1
+kind: Secret
+metadata:
+  name: server-session
+  namespace: gitpod
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: ws-manager-client
+  namespace: workspaces
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: removed
+  namespace: gitpod
+`
+	secret := map[string]interface{}{"data": map[string]interface{}{"secret": "c2VjcmV0"}}
+
+	src := fake.NewSimpleDynamicClient(runtime.NewScheme(),
+		object("ConfigMap", "gitpod", "gitpod", map[string]interface{}{
+			"data": map[string]interface{}{"config.yaml": cfg, "versions.json": `{"versions":{"version":"2023.3.0"}}`},
+		}),
+		object("ConfigMap", "gitpod", installationConfigMap, map[string]interface{}{
+			"data": map[string]interface{}{"app.yaml": app},
+		}),
+		object("Secret", "gitpod", "server-session", secret),
+		object("Secret", "workspaces", "ws-manager-client", secret),
+		object("Secret", "gitpod", "github", secret),
+		object("Secret", "gitpod", "gitlab", secret),
+	)
+
+	archive, err := collectBackup(context.Background(), src, "gitpod", time.Now())
+	require.NoError(t, err)
+	require.Equal(t, "2023.3.0", archive.Manifest.Version)
+	require.Equal(t, cfg, string(archive.Config))
+	require.Equal(t, []backup.Secret{
+		{Namespace: "gitpod", Name: "server-session", Kind: backup.SecretGenerated},
+		{Namespace: "workspaces", Name: "ws-manager-client", Kind: backup.SecretGenerated},
+		{Namespace: "gitpod", Name: "github", Kind: backup.SecretAuthProvider},
+		{Namespace: "gitpod", Name: "gitlab", Kind: backup.SecretAuthProvider},
+	}, archive.Manifest.Secrets)
+	for _, s := range archive.Secrets {
+		require.Empty(t, s.GetUID())
+		require.Empty(t, s.GetResourceVersion())
+	}
+
+	dst := fake.NewSimpleDynamicClient(runtime.NewScheme(),
+		object("Secret", "restored", "github", map[string]interface{}{"data": map[string]interface{}{"secret": "b2xk"}}),
+	)
+	for _, s := range restoredSecrets(archive, "restored") {
+		require.NoError(t, restoreSecret(context.Background(), dst, s))
+	}
+
+	for _, s := range []struct{ Namespace, Name string }{
+		{"restored", "server-session"},
+		{"workspaces", "ws-manager-client"},
+		{"restored", "github"},
+		{"restored", "gitlab"},
+	} {
+		live, err := dst.Resource(gvrSecrets).Namespace(s.Namespace).Get(context.Background(), s.Name, metav1.GetOptions{})
+		require.NoError(t, err, "%s/%s", s.Namespace, s.Name)
+		data, _, _ := unstructured.NestedString(live.Object, "data", "secret")
+		require.Equal(t, "c2VjcmV0", data, "%s/%s", s.Namespace, s.Name)
+	}
+}
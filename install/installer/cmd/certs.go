@@ -0,0 +1,19 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// certsCmd represents the certs command
+var certsCmd = &cobra.Command{
+	Use:   "certs",
+	Short: "Manages the certificates of the internal PKI",
+}
+
+func init() {
+	rootCmd.AddCommand(certsCmd)
+}
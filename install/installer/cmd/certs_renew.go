@@ -0,0 +1,145 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gitpod-io/gitpod/common-go/log"
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	certmanagerv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var gvrCertificates = schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "certificates"}
+
+var certsRenewOpts struct {
+	Kube           kubeConfig
+	ExpiringWithin time.Duration
+	DryRun         bool
+}
+
+// certsRenewCmd represents the certs renew command
+var certsRenewCmd = &cobra.Command{
+	Use:   "renew",
+	Short: "Has cert-manager re-issue the certificates of the internal PKI",
+	Long: `Has cert-manager re-issue the certificates of the internal PKI
+
+The certificates the components authenticate each other with are renewed by
+cert-manager before they expire. This re-issues them now, like "cmctl renew"
+does, e.g. after the internal CA changed. Only the certificates that expire
+within --expiring-within are re-issued if it is set.
+
+The secrets of the re-issued certificates are printed, one per line. The
+components reload or are restarted with their new certificates.`,
+	Example: `  # Re-issue the certificates that expire within the next 30 days.
+  gitpod-installer certs renew --namespace gitpod --kubeconfig ~/.kube/config --expiring-within 720h`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, _, err := dynamicClientFromKubeConfig(&certsRenewOpts.Kube)
+		if err != nil {
+			return err
+		}
+
+		renewed, err := renewCertificates(context.Background(), client, renderOpts.Namespace, time.Now(), certsRenewOpts.ExpiringWithin, certsRenewOpts.DryRun)
+		if err != nil {
+			return err
+		}
+		for _, secret := range renewed {
+			fmt.Println(secret)
+		}
+
+		if certsRenewOpts.DryRun {
+			log.Infof("%d certificates would be re-issued", len(renewed))
+		} else {
+			log.Infof("%d certificates are re-issued", len(renewed))
+		}
+		return nil
+	},
+}
+
+// renewCertificates triggers the issuance of the internal certificates in the namespace that
+// expire within the duration, or of all of them if it is zero. It returns the secrets of the
+// certificates that are re-issued.
+func renewCertificates(ctx context.Context, client dynamic.Interface, namespace string, now time.Time, expiringWithin time.Duration, dryRun bool) ([]string, error) {
+	resource := client.Resource(gvrCertificates).Namespace(namespace)
+	list, err := resource.List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", common.InternalPKILabel, common.InternalPKILabelValue),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot list the certificates: %w", err)
+	}
+	sort.Slice(list.Items, func(i, j int) bool { return list.Items[i].GetName() < list.Items[j].GetName() })
+
+	var res []string
+	for _, item := range list.Items {
+		var cert certmanagerv1.Certificate
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &cert); err != nil {
+			return nil, fmt.Errorf("cannot read certificate %s: %w", item.GetName(), err)
+		}
+		if expiringWithin > 0 && cert.Status.NotAfter != nil && cert.Status.NotAfter.Time.After(now.Add(expiringWithin)) {
+			continue
+		}
+		res = append(res, fmt.Sprintf("%s/%s", namespace, cert.Spec.SecretName))
+
+		if dryRun || isIssuing(&cert) {
+			continue
+		}
+		setIssuing(&cert, now)
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&cert)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := resource.UpdateStatus(ctx, &unstructured.Unstructured{Object: content}, metav1.UpdateOptions{}); err != nil {
+			return nil, fmt.Errorf("cannot re-issue certificate %s: %w", cert.Name, err)
+		}
+	}
+	return res, nil
+}
+
+func isIssuing(cert *certmanagerv1.Certificate) bool {
+	for _, c := range cert.Status.Conditions {
+		if c.Type == certmanagerv1.CertificateConditionIssuing && c.Status == cmmeta.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// setIssuing sets the condition that has cert-manager issue the certificate
+func setIssuing(cert *certmanagerv1.Certificate, now time.Time) {
+	condition := certmanagerv1.CertificateCondition{
+		Type:               certmanagerv1.CertificateConditionIssuing,
+		Status:             cmmeta.ConditionTrue,
+		LastTransitionTime: &metav1.Time{Time: now},
+		Reason:             "ManuallyTriggered",
+		Message:            "Certificate re-issuance triggered by the installer",
+		ObservedGeneration: cert.Generation,
+	}
+	for i, c := range cert.Status.Conditions {
+		if c.Type == certmanagerv1.CertificateConditionIssuing {
+			cert.Status.Conditions[i] = condition
+			return
+		}
+	}
+	cert.Status.Conditions = append(cert.Status.Conditions, condition)
+}
+
+func init() {
+	certsCmd.AddCommand(certsRenewCmd)
+
+	certsRenewCmd.Flags().StringVarP(&renderOpts.Namespace, "namespace", "n", getEnvvar("NAMESPACE", "default"), "namespace Gitpod is deployed to")
+	certsRenewCmd.Flags().StringVar(&certsRenewOpts.Kube.Config, "kubeconfig", "", "path to the kubeconfig file")
+	certsRenewCmd.Flags().DurationVar(&certsRenewOpts.ExpiringWithin, "expiring-within", 0, "only re-issue the certificates that expire within this duration, e.g. 720h")
+	certsRenewCmd.Flags().BoolVar(&certsRenewOpts.DryRun, "dry-run", false, "print the secrets of the certificates that would be re-issued without re-issuing them")
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	certmanagerv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func TestRenewCertificates(t *testing.T) {
+	now := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+	certificate := func(name string, internal bool, notAfter time.Time) runtime.Object {
+		cert := &certmanagerv1.Certificate{
+			TypeMeta: common.TypeMetaCertificate,
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "gitpod",
+				Labels:    map[string]string{"app": "gitpod"},
+			},
+			Spec: certmanagerv1.CertificateSpec{SecretName: name + "-tls"},
+			Status: certmanagerv1.CertificateStatus{
+				NotAfter: &metav1.Time{Time: notAfter},
+				Conditions: []certmanagerv1.CertificateCondition{
+					{Type: certmanagerv1.CertificateConditionReady, Status: cmmeta.ConditionTrue},
+				},
+			},
+		}
+		if internal {
+			cert.Labels[common.InternalPKILabel] = common.InternalPKILabelValue
+		}
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cert)
+		require.NoError(t, err)
+		return &unstructured.Unstructured{Object: content}
+	}
+	newClient := func() *fake.FakeDynamicClient {
+		return fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{gvrCertificates: "CertificateList"},
+			certificate("ws-manager", true, now.Add(10*24*time.Hour)),
+			certificate("ws-daemon", true, now.Add(60*24*time.Hour)),
+			certificate("https-certificates", false, now.Add(time.Hour)),
+		)
+	}
+	issuing := func(t *testing.T, client *fake.FakeDynamicClient, name string) bool {
+		obj, err := client.Resource(gvrCertificates).Namespace("gitpod").Get(context.Background(), name, metav1.GetOptions{})
+		require.NoError(t, err)
+		var cert certmanagerv1.Certificate
+		require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &cert))
+		return isIssuing(&cert)
+	}
+
+	t.Run("all", func(t *testing.T) {
+		client := newClient()
+		renewed, err := renewCertificates(context.Background(), client, "gitpod", now, 0, false)
+		require.NoError(t, err)
+		require.Equal(t, []string{"gitpod/ws-daemon-tls", "gitpod/ws-manager-tls"}, renewed)
+		require.True(t, issuing(t, client, "ws-daemon"))
+		require.True(t, issuing(t, client, "ws-manager"))
+		require.False(t, issuing(t, client, "https-certificates"), "only the internal certificates are re-issued")
+	})
+
+	t.Run("expiring within", func(t *testing.T) {
+		client := newClient()
+		renewed, err := renewCertificates(context.Background(), client, "gitpod", now, 30*24*time.Hour, false)
+		require.NoError(t, err)
+		require.Equal(t, []string{"gitpod/ws-manager-tls"}, renewed)
+		require.True(t, issuing(t, client, "ws-manager"))
+		require.False(t, issuing(t, client, "ws-daemon"))
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		client := newClient()
+		renewed, err := renewCertificates(context.Background(), client, "gitpod", now, 0, true)
+		require.NoError(t, err)
+		require.Len(t, renewed, 2)
+		require.False(t, issuing(t, client, "ws-manager"))
+	})
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/components/gitpod"
+	"github.com/gitpod-io/gitpod/installer/pkg/config/versions"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
+)
+
+// compatibilityOpts are the flags of the commands that check the compatibility of the
+// version they render with the installed one
+type compatibilityOpts struct {
+	Kube             kubeConfig
+	InstalledVersion string
+}
+
+// enabled returns whether the installed version is given or can be read from the cluster
+func (o *compatibilityOpts) enabled() bool {
+	return o.InstalledVersion != "" || o.Kube.Config != ""
+}
+
+// installedRelease is the version and config API version of the last applied render
+type installedRelease struct {
+	Version    string
+	APIVersion string
+}
+
+// readInstalledRelease reads the installed release from the gitpod config map in the
+// namespace. It returns nil if there is no installation.
+func readInstalledRelease(ctx context.Context, client dynamic.Interface, namespace string) (*installedRelease, error) {
+	cfgMap, err := client.Resource(gvrConfigMaps).Namespace(namespace).Get(ctx, gitpod.Component, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	data, _, err := unstructured.NestedStringMap(cfgMap.Object, "data")
+	if err != nil {
+		return nil, err
+	}
+
+	var installed gitpod.Gitpod
+	if err := json.Unmarshal([]byte(data["versions.json"]), &installed); err != nil {
+		return nil, fmt.Errorf("cannot read the installed versions: %w", err)
+	}
+	var installedCfg struct {
+		APIVersion string `json:"apiVersion"`
+	}
+	if err := yaml.Unmarshal([]byte(data["config.yaml"]), &installedCfg); err != nil {
+		return nil, fmt.Errorf("cannot read the installed config: %w", err)
+	}
+
+	return &installedRelease{Version: installed.VersionManifest.Version, APIVersion: installedCfg.APIVersion}, nil
+}
+
+// checkCompatibility returns the warnings about rendering the version and config API version
+// over the installed release, and an error if the upgrade is blocked
+func checkCompatibility(opts *compatibilityOpts, namespace, version, apiVersion string) ([]string, error) {
+	installed := &installedRelease{Version: opts.InstalledVersion}
+	if opts.InstalledVersion == "" {
+		client, _, err := dynamicClientFromKubeConfig(&opts.Kube)
+		if err != nil {
+			return nil, err
+		}
+		installed, err = readInstalledRelease(context.Background(), client, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read the installed version: %w", err)
+		}
+		if installed == nil {
+			return nil, nil
+		}
+	}
+
+	compatibility, err := versions.EmbeddedCompatibility()
+	if err != nil {
+		return nil, err
+	}
+	warnings, err := compatibility.CheckUpgrade(installed.Version, version)
+	if err != nil {
+		return warnings, err
+	}
+	if installed.APIVersion != "" && installed.APIVersion != apiVersion {
+		warnings = append(warnings, fmt.Sprintf("the installed config is of version %s, the config is of version %s", installed.APIVersion, apiVersion))
+	}
+	return warnings, nil
+}
@@ -0,0 +1,35 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func TestReadInstalledRelease(t *testing.T) {
+	client := fake.NewSimpleDynamicClient(runtime.NewScheme(), &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "gitpod", "namespace": "gitpod"},
+		"data": map[string]interface{}{
+			"config.yaml":   "apiVersion: v1\ndomain: gitpod.example.com\n",
+			"versions.json": `{"versions":{"version":"2023.3.0"}}`,
+		},
+	}})
+
+	installed, err := readInstalledRelease(context.Background(), client, "gitpod")
+	require.NoError(t, err)
+	require.Equal(t, &installedRelease{Version: "2023.3.0", APIVersion: "v1"}, installed)
+
+	installed, err = readInstalledRelease(context.Background(), client, "other")
+	require.NoError(t, err)
+	require.Nil(t, installed)
+}
@@ -19,7 +19,8 @@ var configNodeContainerdCmd = &cobra.Command{
 			return err
 		}
 
-		_, _, cfg, err := loadConfig(configOpts.ConfigFile)
+		// The config is written back, so its templates are kept
+		_, _, cfg, err := readConfig(configOpts.ConfigFile)
 		if err != nil {
 			return err
 		}
@@ -8,11 +8,13 @@ import (
 	"fmt"
 
 	"github.com/gitpod-io/gitpod/installer/pkg/config"
+	configv1 "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
 	"github.com/spf13/cobra"
 )
 
 var configInitOpts struct {
 	OverwriteConfig bool
+	Interactive     bool
 }
 
 // configInitCmd represents the validate command
@@ -22,9 +24,15 @@ var configInitCmd = &cobra.Command{
 	Long: `Create a base config file
 
 This file contains all the credentials to install a Gitpod instance and
-be saved to a repository.`,
+be saved to a repository.
+
+In interactive mode the domain, the platform and the database, storage and
+registry are asked for, and the config is validated before it is saved.`,
 	Example: `  # Save config to config.yaml.
-gitpod-installer config init -c ./gitpod.config.yaml`,
+gitpod-installer config init -c ./gitpod.config.yaml
+
+  # Answer the questions to fill in the config.
+gitpod-installer config init -c ./gitpod.config.yaml --interactive`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Check file isn't present
 		exists, err := configFileExists()
@@ -40,6 +48,15 @@ gitpod-installer config init -c ./gitpod.config.yaml`,
 			return err
 		}
 
+		if configInitOpts.Interactive {
+			if err := interactiveConfig(newPrompter(cmd.InOrStdin(), cmd.OutOrStdout()), cfg.(*configv1.Config)); err != nil {
+				return err
+			}
+			if err := runConfigValidation(config.CurrentVersion, cfg); err != nil {
+				return err
+			}
+		}
+
 		return saveConfigFile(cfg)
 	},
 }
@@ -48,4 +65,5 @@ func init() {
 	configCmd.AddCommand(configInitCmd)
 
 	configInitCmd.Flags().BoolVar(&configInitOpts.OverwriteConfig, "overwrite", false, "overwrite config file if it exists")
+	configInitCmd.Flags().BoolVarP(&configInitOpts.Interactive, "interactive", "i", false, "ask for the settings of the installation")
 }
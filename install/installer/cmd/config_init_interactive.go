@@ -0,0 +1,233 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	configv1 "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+	"github.com/gitpod-io/gitpod/installer/pkg/containerd"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	platformGKE   = "gke"
+	platformEKS   = "eks"
+	platformAKS   = "aks"
+	platformK3s   = "k3s"
+	platformOther = "other"
+)
+
+// prompter asks the questions of the interactive config init
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func newPrompter(in io.Reader, out io.Writer) *prompter {
+	return &prompter{in: bufio.NewReader(in), out: out}
+}
+
+// ask returns the answer to the question, or the default if the answer is empty. Without a
+// default the question is asked until it is answered.
+func (p *prompter) ask(question, dflt string) (string, error) {
+	for {
+		if dflt != "" {
+			fmt.Fprintf(p.out, "%s [%s]: ", question, dflt)
+		} else {
+			fmt.Fprintf(p.out, "%s: ", question)
+		}
+
+		answer, err := p.in.ReadString('\n')
+		answer = strings.TrimSpace(answer)
+		if err == io.EOF && answer == "" && dflt == "" {
+			return "", fmt.Errorf("no answer to %q", question)
+		} else if err != nil && err != io.EOF {
+			return "", err
+		}
+
+		if answer == "" {
+			answer = dflt
+		}
+		if answer != "" {
+			return answer, nil
+		}
+	}
+}
+
+// choose asks the question until one of the options is answered
+func (p *prompter) choose(question string, options []string, dflt string) (string, error) {
+	for {
+		answer, err := p.ask(fmt.Sprintf("%s (%s)", question, strings.Join(options, "/")), dflt)
+		if err != nil {
+			return "", err
+		}
+		for _, o := range options {
+			if strings.EqualFold(answer, o) {
+				return o, nil
+			}
+		}
+		fmt.Fprintf(p.out, "%s is not one of %s\n", answer, strings.Join(options, ", "))
+	}
+}
+
+// secret asks for the name of a secret with the keys that are described
+func (p *prompter) secret(description, dflt string) (configv1.ObjectRef, error) {
+	name, err := p.ask(fmt.Sprintf("Name of the secret with %s", description), dflt)
+	if err != nil {
+		return configv1.ObjectRef{}, err
+	}
+	return configv1.ObjectRef{Kind: configv1.ObjectRefSecret, Name: name}, nil
+}
+
+// interactiveConfig fills in the config from the answers to the questions. The defaults of the
+// dependencies depend on the platform, e.g. Cloud SQL and Cloud Storage on GKE.
+func interactiveConfig(p *prompter, cfg *configv1.Config) error {
+	var err error
+
+	cfg.Domain, err = p.ask("Domain Gitpod is installed on, e.g. gitpod.example.com", "")
+	if err != nil {
+		return err
+	}
+
+	platform, err := p.choose("Kubernetes platform", []string{platformGKE, platformEKS, platformAKS, platformK3s, platformOther}, platformOther)
+	if err != nil {
+		return err
+	}
+	if platform != platformOther {
+		cfg.Telemetry = &configv1.TelemetryConfig{Data: &configv1.TelemetryData{Platform: platform}}
+	}
+	if platform == platformK3s {
+		cfg.Workspace.Runtime.ContainerDSocketDir = containerd.ContainerdSocketLocationK3s.String()
+		cfg.Workspace.Runtime.ContainerDRuntimeDir = containerd.ContainerdLocationK3s.String()
+	}
+
+	cfg.Certificate, err = p.secret("the TLS certificate of the domain and its wildcards", cfg.Certificate.Name)
+	if err != nil {
+		return err
+	}
+
+	if err := interactiveDatabase(p, cfg, platform); err != nil {
+		return err
+	}
+	if err := interactiveObjectStorage(p, cfg, platform); err != nil {
+		return err
+	}
+	return interactiveContainerRegistry(p, cfg)
+}
+
+func interactiveDatabase(p *prompter, cfg *configv1.Config, platform string) error {
+	options := []string{"in-cluster", "mysql", "postgres"}
+	if platform == platformGKE {
+		options = append(options, "cloudsql")
+	}
+	kind, err := p.choose("Database", options, "in-cluster")
+	if err != nil {
+		return err
+	}
+
+	cfg.Database = configv1.Database{InCluster: pointer.Bool(kind == "in-cluster")}
+	switch kind {
+	case "mysql":
+		certificate, err := p.secret("the encryptionKeys, host, password, port and username of the database", "database")
+		if err != nil {
+			return err
+		}
+		cfg.Database.External = &configv1.DatabaseExternal{Certificate: &certificate}
+	case "postgres":
+		certificate, err := p.secret("the database, encryptionKeys, host, password, port and username of the database", "database")
+		if err != nil {
+			return err
+		}
+		cfg.Database.External = &configv1.DatabaseExternal{Postgres: &configv1.DatabaseExternalPostgres{Certificate: certificate}}
+	case "cloudsql":
+		instance, err := p.ask("Cloud SQL instance, e.g. project:region:name", "")
+		if err != nil {
+			return err
+		}
+		serviceAccount, err := p.secret("the credentials.json of the Cloud SQL service account and the encryptionKeys, password and username", "cloudsql")
+		if err != nil {
+			return err
+		}
+		cfg.Database.CloudSQL = &configv1.DatabaseCloudSQL{Instance: instance, ServiceAccount: serviceAccount}
+	}
+
+	return nil
+}
+
+func interactiveObjectStorage(p *prompter, cfg *configv1.Config, platform string) error {
+	dflt := "in-cluster"
+	switch platform {
+	case platformGKE:
+		dflt = "gcs"
+	case platformEKS:
+		dflt = "s3"
+	}
+	kind, err := p.choose("Object storage for the workspace backups", []string{"in-cluster", "s3", "gcs"}, dflt)
+	if err != nil {
+		return err
+	}
+
+	storage := &cfg.ObjectStorage
+	storage.InCluster = pointer.Bool(kind == "in-cluster")
+	switch kind {
+	case "s3":
+		endpoint := ""
+		if platform == platformEKS {
+			endpoint = "s3.amazonaws.com"
+		}
+		s3 := &configv1.ObjectStorageS3{}
+		if s3.Endpoint, err = p.ask("S3 endpoint", endpoint); err != nil {
+			return err
+		}
+		if s3.BucketName, err = p.ask("S3 bucket", ""); err != nil {
+			return err
+		}
+		credentials, err := p.secret("the accessKeyId and secretAccessKey", "object-storage")
+		if err != nil {
+			return err
+		}
+		s3.Credentials = &credentials
+		storage.S3 = s3
+	case "gcs":
+		gcs := &configv1.ObjectStorageCloudStorage{}
+		if gcs.Project, err = p.ask("Google Cloud project of the buckets", ""); err != nil {
+			return err
+		}
+		if gcs.ServiceAccount, err = p.secret("the service-account.json of the storage service account", "object-storage"); err != nil {
+			return err
+		}
+		storage.CloudStorage = gcs
+	}
+
+	return nil
+}
+
+func interactiveContainerRegistry(p *prompter, cfg *configv1.Config) error {
+	kind, err := p.choose("Container registry for the workspace images", []string{"in-cluster", "external"}, "in-cluster")
+	if err != nil {
+		return err
+	}
+
+	registry := &cfg.ContainerRegistry
+	registry.InCluster = pointer.Bool(kind == "in-cluster")
+	if kind == "external" {
+		external := &configv1.ContainerRegistryExternal{}
+		if external.URL, err = p.ask("Registry URL, e.g. registry.example.com/gitpod", ""); err != nil {
+			return err
+		}
+		certificate, err := p.secret("the .dockerconfigjson of the registry", "container-registry")
+		if err != nil {
+			return err
+		}
+		external.Certificate = &certificate
+		registry.External = external
+	}
+
+	return nil
+}
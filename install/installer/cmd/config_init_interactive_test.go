@@ -0,0 +1,60 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/pointer"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/config"
+	configv1 "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+	"github.com/gitpod-io/gitpod/installer/pkg/containerd"
+)
+
+func TestInteractiveConfig(t *testing.T) {
+	answer := func(t *testing.T, answers ...string) *configv1.Config {
+		cfg, err := config.NewDefaultConfig()
+		require.NoError(t, err)
+
+		p := newPrompter(strings.NewReader(strings.Join(answers, "\n")+"\n"), io.Discard)
+		require.NoError(t, interactiveConfig(p, cfg.(*configv1.Config)))
+		return cfg.(*configv1.Config)
+	}
+
+	t.Run("eks", func(t *testing.T) {
+		cfg := answer(t, "gitpod.example.com", "EKS", "", "postgres", "", "", "", "gitpod-backups", "", "")
+
+		require.Equal(t, "gitpod.example.com", cfg.Domain)
+		require.Equal(t, "eks", cfg.Telemetry.Data.Platform)
+		require.True(t, cfg.Database.UsePostgres())
+		require.Equal(t, &configv1.ObjectStorageS3{
+			Endpoint:    "s3.amazonaws.com",
+			BucketName:  "gitpod-backups",
+			Credentials: &configv1.ObjectRef{Kind: configv1.ObjectRefSecret, Name: "object-storage"},
+		}, cfg.ObjectStorage.S3)
+		require.Equal(t, pointer.Bool(false), cfg.ObjectStorage.InCluster)
+		require.Equal(t, pointer.Bool(true), cfg.ContainerRegistry.InCluster)
+	})
+
+	t.Run("k3s", func(t *testing.T) {
+		cfg := answer(t, "gitpod.example.com", "k3s", "tls", "", "", "external", "registry.example.com/gitpod", "")
+
+		require.Equal(t, "tls", cfg.Certificate.Name)
+		require.Equal(t, containerd.ContainerdSocketLocationK3s.String(), cfg.Workspace.Runtime.ContainerDSocketDir)
+		require.Equal(t, pointer.Bool(true), cfg.Database.InCluster)
+		require.Equal(t, pointer.Bool(true), cfg.ObjectStorage.InCluster)
+		require.Equal(t, "registry.example.com/gitpod", cfg.ContainerRegistry.External.URL)
+		require.Equal(t, "container-registry", cfg.ContainerRegistry.External.Certificate.Name)
+	})
+
+	t.Run("unknown option", func(t *testing.T) {
+		cfg := answer(t, "gitpod.example.com", "openshift", "", "", "", "", "", "")
+		require.Nil(t, cfg.Telemetry, "the question is asked again and the default is taken")
+	})
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	"github.com/gitpod-io/gitpod/installer/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+const (
+	lintOutputText = "text"
+	lintOutputJSON = "json"
+)
+
+var configLintOpts struct {
+	OutputFormat string
+	FailOn       string
+	Namespace    string
+}
+
+// configLintCmd represents the lint command
+var configLintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Flag the risky and deprecated settings of a config file",
+	Long: `Flag the risky and deprecated settings of a config file
+
+Unlike "validate config", the settings that are flagged are valid, but risky
+for a production installation, such as the in-cluster database, debug ports
+that accept connections, containers without resource requests or the
+experimental config. Every finding has a severity of error, warning or info.
+
+The command exits with 1 if there are findings of the --fail-on severity or
+higher, so that it can gate the config in CI.`,
+	Example: `  # List the findings.
+  gitpod-installer config lint -c ./gitpod.config.yaml
+
+  # Fail on warnings and errors, and report them as JSON.
+  gitpod-installer config lint -c ./gitpod.config.yaml --fail-on warning --output-format json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		failOn, err := config.ParseLintSeverity(configLintOpts.FailOn)
+		if err != nil {
+			return err
+		}
+		if _, err := configFileExistsAndInit(); err != nil {
+			return err
+		}
+		_, cfgVersion, cfg, err := loadConfig(configOpts.ConfigFile, configLintOpts.Namespace)
+		if err != nil {
+			return err
+		}
+		apiVersion, err := config.LoadConfigVersion(cfgVersion)
+		if err != nil {
+			return err
+		}
+
+		res := config.Lint(apiVersion, cfg)
+		switch configLintOpts.OutputFormat {
+		case lintOutputText:
+			printLintResult(os.Stdout, res)
+		case lintOutputJSON:
+			fc, err := common.ToJSONString(res)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(fc))
+		default:
+			return fmt.Errorf("unsupported output format: %s", configLintOpts.OutputFormat)
+		}
+
+		if res.Count(failOn) > 0 {
+			os.Exit(1)
+		}
+		return nil
+	},
+}
+
+func printLintResult(out io.Writer, res *config.LintResult) {
+	if len(res.Findings) == 0 {
+		fmt.Fprintln(out, "No findings")
+		return
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SEVERITY\tRULE\tFIELD\tMESSAGE")
+	for _, f := range res.Findings {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", f.Severity, f.Rule, f.Field, f.Message)
+	}
+	w.Flush()
+}
+
+func init() {
+	configCmd.AddCommand(configLintCmd)
+
+	configLintCmd.Flags().StringVar(&configLintOpts.OutputFormat, "output-format", lintOutputText, fmt.Sprintf("format of the findings, one of %s or %s", lintOutputText, lintOutputJSON))
+	configLintCmd.Flags().StringVarP(&configLintOpts.Namespace, "namespace", "n", getEnvvar("NAMESPACE", "default"), "namespace the templates of the config are executed with")
+	configLintCmd.Flags().StringVar(&configLintOpts.FailOn, "fail-on", string(config.LintSeverityError), fmt.Sprintf("exit with 1 if there are findings of this severity or higher, one of %s, %s or %s", config.LintSeverityError, config.LintSeverityWarning, config.LintSeverityInfo))
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gitpod-io/gitpod/common-go/log"
+	"github.com/gitpod-io/gitpod/installer/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+var configMigrateOpts struct {
+	DryRun bool
+}
+
+// configMigrateCmd represents the migrate command
+var configMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade a config file to the latest version",
+	Long: `Upgrade a config file to the latest version
+
+The config file is upgraded to the latest API version supported by this
+installer. Any fields that have been renamed or removed are reported.`,
+	Example: `  # Upgrade the config file in place.
+  gitpod-installer config migrate -c ./gitpod.config.yaml
+
+  # Print the upgraded config without changing the file.
+  gitpod-installer config migrate -c ./gitpod.config.yaml --dry-run`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if _, err := configFileExistsAndInit(); err != nil {
+			return err
+		}
+
+		fc, err := os.ReadFile(configOpts.ConfigFile)
+		if err != nil {
+			return err
+		}
+
+		res, err := config.Migrate(string(fc))
+		if err != nil {
+			return err
+		}
+
+		for _, c := range res.Changes {
+			log.Info(c.String())
+		}
+
+		migrated, err := config.Marshal(res.Version, res.Config)
+		if err != nil {
+			return err
+		}
+
+		// Ensure the migrated config can be used by this version of the installer
+		cfg, version, err := config.Load(string(migrated), rootOpts.StrictConfigParse)
+		if err != nil {
+			return fmt.Errorf("migrated config cannot be loaded - please update it manually: %w", err)
+		}
+		apiVersion, err := config.LoadConfigVersion(version)
+		if err != nil {
+			return err
+		}
+		warnings, _ := apiVersion.CheckDeprecated(cfg)
+		for k, v := range warnings {
+			log.Warnf("Deprecated config parameter: %s=%v", k, v)
+		}
+
+		if configMigrateOpts.DryRun {
+			fmt.Print(string(migrated))
+			return nil
+		}
+
+		if res.FromVersion == res.Version && len(res.Changes) == 0 {
+			log.Infof("Config is already at version %s", res.Version)
+			return nil
+		}
+
+		if err := os.WriteFile(configOpts.ConfigFile, migrated, 0644); err != nil {
+			return err
+		}
+		log.Infof("Config migrated from %s to %s and written to %s", res.FromVersion, res.Version, configOpts.ConfigFile)
+
+		return nil
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configMigrateCmd)
+
+	configMigrateCmd.Flags().BoolVar(&configMigrateOpts.DryRun, "dry-run", false, "print the migrated config instead of writing it to the file")
+}
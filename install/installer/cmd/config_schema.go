@@ -0,0 +1,45 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	"github.com/gitpod-io/gitpod/installer/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// configSchemaCmd represents the schema command
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of the config",
+	Long: `Print the JSON Schema of the config
+
+The schema of the latest API version supported by this installer is printed,
+including the experimental section. The fields are described and have their
+defaults so that editors and pipelines can check a config file before it is
+rendered.`,
+	Example: `  # Write the schema next to the config file.
+  gitpod-installer config schema > gitpod.config.schema.json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		schema, err := config.Schema(config.CurrentVersion)
+		if err != nil {
+			return err
+		}
+
+		fc, err := common.ToJSONString(schema)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(fc))
+
+		return nil
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configSchemaCmd)
+}
@@ -0,0 +1,242 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gitpod-io/gitpod/common-go/log"
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/yaml"
+)
+
+const fieldManager = "gitpod-installer"
+
+type diffStatus string
+
+const (
+	diffStatusCreated   diffStatus = "created"
+	diffStatusChanged   diffStatus = "changed"
+	diffStatusUnchanged diffStatus = "unchanged"
+)
+
+type objectDiff struct {
+	Kind      string     `json:"kind"`
+	Namespace string     `json:"namespace,omitempty"`
+	Name      string     `json:"name"`
+	Status    diffStatus `json:"status"`
+	Diff      string     `json:"diff,omitempty"`
+}
+
+var diffOpts struct {
+	Kube          kubeConfig
+	ShowUnchanged bool
+	ExitCode      bool
+}
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Compares the rendered Kubernetes manifests against the live cluster",
+	Long: `Compares the rendered Kubernetes manifests against the live cluster
+
+Each rendered object is sent to the cluster as a server-side apply in
+dry-run mode. The result is compared to the current state of the object
+so only the changes that an apply would make are reported.`,
+	Example: `  # Show the changes that a new config would make to the cluster.
+  gitpod-installer diff --config config.yaml --namespace gitpod`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		renderOpts.Cluster = &diffOpts.Kube
+
+		yaml, err := renderFn()
+		if err != nil {
+			return err
+		}
+
+		objs, err := common.YamlToRuntimeObject(yaml)
+		if err != nil {
+			return err
+		}
+
+		client, mapper, err := dynamicClientFromKubeConfig(&diffOpts.Kube)
+		if err != nil {
+			return err
+		}
+
+		changes := 0
+		for _, obj := range objs {
+			res, err := diffObject(context.Background(), client, mapper, obj)
+			if err != nil {
+				return err
+			}
+
+			if res.Status != diffStatusUnchanged {
+				changes++
+			} else if !diffOpts.ShowUnchanged {
+				continue
+			}
+
+			printObjectDiff(res)
+		}
+
+		log.Infof("%d of %d objects would be changed", changes, len(objs))
+		if diffOpts.ExitCode && changes > 0 {
+			os.Exit(1)
+		}
+
+		return nil
+	},
+}
+
+// diffObject performs a server-side dry-run apply of the object and compares it to the live object
+func diffObject(ctx context.Context, client dynamic.Interface, mapper meta.RESTMapper, obj common.RuntimeObject) (*objectDiff, error) {
+	var desired unstructured.Unstructured
+	if err := yaml.Unmarshal([]byte(obj.Content), &desired.Object); err != nil {
+		return nil, err
+	}
+
+	resource, namespace, err := resourceForObject(client, mapper, &desired)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &objectDiff{
+		Kind:      desired.GetKind(),
+		Namespace: namespace,
+		Name:      desired.GetName(),
+	}
+
+	live, err := resource.Get(ctx, desired.GetName(), metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		res.Status = diffStatusCreated
+		res.Diff = cmp.Diff(nil, sanitizeForDiff(&desired))
+		return res, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot get %s %s: %w", desired.GetKind(), desired.GetName(), err)
+	}
+
+	data, err := json.Marshal(desired.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	dryRun, err := resource.Patch(ctx, desired.GetName(), types.ApplyPatchType, data, metav1.PatchOptions{
+		DryRun:       []string{metav1.DryRunAll},
+		FieldManager: fieldManager,
+		Force:        pointer.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot dry-run apply %s %s: %w", desired.GetKind(), desired.GetName(), err)
+	}
+
+	res.Diff = cmp.Diff(sanitizeForDiff(live), sanitizeForDiff(dryRun))
+	if res.Diff == "" {
+		res.Status = diffStatusUnchanged
+	} else {
+		res.Status = diffStatusChanged
+	}
+
+	return res, nil
+}
+
+// redactionKey keys the hashes of the redacted values. It is random, so that the hashes only
+// tell whether a value changed within one diff and cannot be looked up.
+var redactionKey = func() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+	return key
+}()
+
+// sanitizeForDiff removes the fields that are managed by the API server and would always differ,
+// and redacts the values of secrets
+func sanitizeForDiff(obj *unstructured.Unstructured) map[string]interface{} {
+	res := obj.DeepCopy()
+
+	unstructured.RemoveNestedField(res.Object, "status")
+	for _, field := range []string{"managedFields", "resourceVersion", "generation", "uid", "creationTimestamp"} {
+		unstructured.RemoveNestedField(res.Object, "metadata", field)
+	}
+
+	if res.GetKind() == "Secret" {
+		for _, field := range []string{"data", "stringData"} {
+			values, ok := res.Object[field].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			for key, value := range values {
+				values[key] = redact(fmt.Sprint(value))
+			}
+		}
+		// kubectl apply keeps the whole secret in an annotation
+		if annotations := res.GetAnnotations(); annotations != nil {
+			if value, ok := annotations[corev1.LastAppliedConfigAnnotation]; ok {
+				annotations[corev1.LastAppliedConfigAnnotation] = redact(value)
+				res.SetAnnotations(annotations)
+			}
+		}
+	}
+
+	return res.Object
+}
+
+// redact replaces a value with its keyed hash, so that the diff shows that it changed but not
+// what it is
+func redact(value string) string {
+	mac := hmac.New(sha256.New, redactionKey)
+	mac.Write([]byte(value))
+	return fmt.Sprintf("<redacted %s>", hex.EncodeToString(mac.Sum(nil))[:16])
+}
+
+func printObjectDiff(res *objectDiff) {
+	name := fmt.Sprintf("%s/%s", res.Kind, res.Name)
+	if res.Namespace != "" {
+		name = fmt.Sprintf("%s (namespace %s)", name, res.Namespace)
+	}
+
+	fmt.Printf("--- %s: %s\n", name, res.Status)
+	if res.Diff != "" {
+		fmt.Println(res.Diff)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	dir, err := os.Getwd()
+	if err != nil {
+		log.WithError(err).Fatal("Failed to get working directory")
+	}
+
+	diffCmd.Flags().StringVarP(&renderOpts.ConfigFN, "config", "c", getEnvvar("GITPOD_INSTALLER_CONFIG", filepath.Join(dir, "gitpod.config.yaml")), "path to the config file, use - for stdin")
+	diffCmd.Flags().StringVarP(&renderOpts.Namespace, "namespace", "n", getEnvvar("NAMESPACE", "default"), "namespace to deploy to")
+	diffCmd.Flags().BoolVar(&renderOpts.ValidateConfigDisabled, "no-validation", false, "if set, the config will not be validated before running")
+	diffCmd.Flags().BoolVar(&renderOpts.UseExperimentalConfig, "use-experimental-config", false, "enable the use of experimental config that is prone to be changed")
+	diffCmd.Flags().BoolVar(&renderOpts.NoCache, "no-cache", false, "resolve the digests of the images against the registries instead of using the cached digests")
+	diffCmd.Flags().BoolVar(&renderOpts.NoExpiredCache, "no-expired-cache", false, "fail instead of using an expired cached digest if a registry cannot be reached")
+	diffCmd.Flags().StringVar(&renderOpts.Component, "component", "", "only compare the objects of the named component, eg server")
+	diffCmd.Flags().StringVar(&diffOpts.Kube.Config, "kubeconfig", "", "path to the kubeconfig file")
+	diffCmd.Flags().BoolVar(&diffOpts.ShowUnchanged, "show-unchanged", false, "also list the objects that would not be changed")
+	diffCmd.Flags().BoolVar(&diffOpts.ExitCode, "exit-code", false, "exit with a non-zero status if there are any changes")
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestSanitizeForDiff(t *testing.T) {
+	secret := func(password string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata": map[string]interface{}{
+				"name":            "mysql",
+				"resourceVersion": "42",
+				"annotations": map[string]interface{}{
+					"kubectl.kubernetes.io/last-applied-configuration": `{"stringData":{"password":"` + password + `"}}`,
+				},
+			},
+			"data":       map[string]interface{}{"username": "Z2l0cG9k", "password": password},
+			"stringData": map[string]interface{}{"host": "db.example.com"},
+		}}
+	}
+
+	previous := sanitizeForDiff(secret("c2VjcmV0"))
+	require.NotContains(t, previous["metadata"], "resourceVersion")
+
+	diff := cmp.Diff(previous, sanitizeForDiff(secret("bmV3LXNlY3JldA==")))
+	require.Contains(t, diff, "password")
+	require.Contains(t, diff, "<redacted ")
+	for _, value := range []string{"c2VjcmV0", "bmV3LXNlY3JldA==", "Z2l0cG9k", "db.example.com"} {
+		require.NotContains(t, diff, value)
+	}
+	require.NotContains(t, cmp.Diff(nil, previous), "db.example.com", "the values of a created secret are redacted too")
+
+	configMap := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "server"},
+		"data":       map[string]interface{}{"config.json": "{}"},
+	}}
+	require.Equal(t, "{}", sanitizeForDiff(configMap)["data"].(map[string]interface{})["config.json"])
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	"github.com/gitpod-io/gitpod/installer/pkg/estimate"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	estimateOutputText = "text"
+	estimateOutputJSON = "json"
+)
+
+var estimateOpts struct {
+	ConfigFN     string
+	Workspaces   map[string]int64
+	OutputFormat string
+}
+
+// estimateCmd represents the estimate command
+var estimateCmd = &cobra.Command{
+	Use:   "estimate",
+	Short: "Estimates the capacity an installation needs",
+	Long: `Estimates the capacity an installation needs
+
+The objects are rendered from the config, so the estimate has the replicas and
+resource requests of the components, the sizes of their volumes and the load
+balancer services that the installation is applied with. The workspaces that
+run at the same time are given by their class: the default class requests the
+workspace resources of the config, the other classes their own resources.
+
+The CPU and memory of the components is what their pods request, once with the
+replicas they start with and once scaled to the maximum of their autoscalers.
+The daemon sets run on every node, so their requests are added to every node
+that is sized for the workspaces.`,
+	Example: `  # Estimate an installation that runs 20 default and 5 large workspaces at once.
+  gitpod-installer estimate --config config.yaml --workspaces default=20,large=5`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if estimateOpts.ConfigFN == "" {
+			return fmt.Errorf("config is a required flag")
+		}
+
+		_, cfgVersion, cfg, err := loadConfig(estimateOpts.ConfigFN, renderOpts.Namespace)
+		if err != nil {
+			return err
+		}
+
+		k8s, err := renderKubernetesObjects(cfgVersion, cfg)
+		if err != nil {
+			return err
+		}
+
+		est, err := estimate.Compute(cfg, k8s, estimateOpts.Workspaces)
+		if err != nil {
+			return err
+		}
+
+		switch estimateOpts.OutputFormat {
+		case estimateOutputText:
+			printEstimate(os.Stdout, est)
+		case estimateOutputJSON:
+			fc, err := common.ToJSONString(est)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(fc))
+		default:
+			return fmt.Errorf("unsupported output format: %s", estimateOpts.OutputFormat)
+		}
+		return nil
+	},
+}
+
+func printEstimate(out io.Writer, est *estimate.Estimate) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KIND\tNAME\tREPLICAS\tCPU PER POD\tMEMORY PER POD")
+	for _, wl := range est.Components {
+		replicas := fmt.Sprintf("%d", wl.Replicas)
+		if wl.MaxReplicas != wl.Replicas {
+			replicas = fmt.Sprintf("%d-%d", wl.Replicas, wl.MaxReplicas)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", wl.Kind, wl.Name, replicas, quantity(wl.Pod.CPU), quantity(wl.Pod.Memory))
+	}
+	fmt.Fprintf(w, "Total\t\t\t%s\t%s\n", quantity(est.Total.Components.CPU), quantity(est.Total.Components.Memory))
+	w.Flush()
+	if est.Total.ComponentsMax.CPU.Cmp(est.Total.Components.CPU) != 0 || est.Total.ComponentsMax.Memory.Cmp(est.Total.Components.Memory) != 0 {
+		fmt.Fprintf(out, "Scaled to the maximum replicas the components request %s CPU and %s memory.\n", quantity(est.Total.ComponentsMax.CPU), quantity(est.Total.ComponentsMax.Memory))
+	}
+
+	fmt.Fprintln(out)
+	w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DAEMON SET\tCPU PER NODE\tMEMORY PER NODE")
+	for _, wl := range est.Nodes {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", wl.Name, quantity(wl.Pod.CPU), quantity(wl.Pod.Memory))
+	}
+	fmt.Fprintf(w, "Total\t%s\t%s\n", quantity(est.Total.Nodes.CPU), quantity(est.Total.Nodes.Memory))
+	w.Flush()
+
+	fmt.Fprintln(out)
+	if len(est.Workspaces) == 0 {
+		fmt.Fprintln(out, "No workspaces are estimated, use --workspaces to give the number of workspaces of each class.")
+	} else {
+		w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "WORKSPACE CLASS\tWORKSPACES\tCPU\tMEMORY\tSTORAGE\tGPUS")
+		for _, c := range est.Workspaces {
+			fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%d\n", c.Name, c.Count,
+				quantity(estimate.Scale(c.Workspace.CPU, c.Count)),
+				quantity(estimate.Scale(c.Workspace.Memory, c.Count)),
+				quantity(estimate.Scale(c.Storage, c.Count)),
+				c.GPUs*c.Count)
+		}
+		fmt.Fprintf(w, "Total\t\t%s\t%s\t%s\t%d\n", quantity(est.Total.Workspaces.CPU), quantity(est.Total.Workspaces.Memory), quantity(est.Total.WorkspaceStorage), est.Total.WorkspaceGPUs)
+		w.Flush()
+	}
+
+	fmt.Fprintln(out)
+	w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VOLUME\tSTORAGE CLASS\tCOUNT\tSIZE EACH")
+	for _, v := range est.Volumes {
+		storageClass := v.StorageClass
+		if storageClass == "" {
+			storageClass = "(default)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", v.Name, storageClass, v.Count, quantity(v.Size))
+	}
+	fmt.Fprintf(w, "Total\t\t\t%s\n", quantity(est.Total.Volumes))
+	w.Flush()
+
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "Load balancers: %d\n", len(est.LoadBalancers))
+	for _, lb := range est.LoadBalancers {
+		fmt.Fprintf(out, "  %s\n", lb)
+	}
+}
+
+func quantity(q resource.Quantity) string {
+	if q.IsZero() {
+		return "-"
+	}
+	return q.String()
+}
+
+func init() {
+	rootCmd.AddCommand(estimateCmd)
+
+	estimateCmd.Flags().StringVarP(&estimateOpts.ConfigFN, "config", "c", os.Getenv("GITPOD_INSTALLER_CONFIG"), "path to the config file, use - for stdin")
+	estimateCmd.Flags().StringVarP(&renderOpts.Namespace, "namespace", "n", getEnvvar("NAMESPACE", "default"), "namespace to deploy to")
+	estimateCmd.Flags().StringToInt64Var(&estimateOpts.Workspaces, "workspaces", nil, "number of workspaces of each class that run at the same time, e.g. default=20,large=5")
+	estimateCmd.Flags().StringVar(&estimateOpts.OutputFormat, "output-format", estimateOutputText, fmt.Sprintf("format of the estimate, one of %s or %s", estimateOutputText, estimateOutputJSON))
+}
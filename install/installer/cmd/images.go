@@ -0,0 +1,19 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// imagesCmd represents the images command
+var imagesCmd = &cobra.Command{
+	Use:   "images",
+	Short: "Reports on the images of an installation",
+}
+
+func init() {
+	rootCmd.AddCommand(imagesCmd)
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/distribution/reference"
+	"github.com/gitpod-io/gitpod/common-go/log"
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	configv1 "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+	"github.com/gitpod-io/gitpod/installer/pkg/sbom"
+	"github.com/spf13/cobra"
+)
+
+var imagesSBOMOpts struct {
+	ConfigFN string
+	Format   string
+	Output   string
+}
+
+// imagesSBOMCmd represents the images sbom command
+var imagesSBOMCmd = &cobra.Command{
+	Use:   "sbom",
+	Short: "Generates a software bill of materials of every image used",
+	Long: `Generates a software bill of materials of every image used
+
+The images are those rendered from the config, resolved to the digest they
+currently have in their registry. The SBOM attestations that were pushed with
+the images are aggregated into a single SPDX or CycloneDX document. Every
+image is a package of the document which contains the packages of its SBOM.
+Images without an attestation in the format are listed with their digest only.
+
+Credentials for the registries are read from the local Docker config.`,
+	Example: `
+  gitpod-installer images sbom --config config.yaml --format cyclonedx --output gitpod.cdx.json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if imagesSBOMOpts.ConfigFN == "" {
+			return fmt.Errorf("config is a required flag")
+		}
+
+		_, cfgVersion, cfg, err := loadConfig(imagesSBOMOpts.ConfigFN, renderOpts.Namespace)
+		if err != nil {
+			return err
+		}
+
+		versionMF, err := getVersionManifest()
+		if err != nil {
+			return err
+		}
+
+		refs, err := configImages(cfgVersion, cfg)
+		if err != nil {
+			return err
+		}
+
+		images, err := sbom.Fetch(context.Background(), common.NewRegistryResolver(), imagesSBOMOpts.Format, refs, func(img sbom.Image) {
+			if len(img.SBOMs) == 0 {
+				log.Warnf("%s (%s) has no %s SBOM attestation", img.Reference, img.Digest, imagesSBOMOpts.Format)
+				return
+			}
+			log.Infof("Fetched %s (%s)", img.Reference, img.Digest)
+		})
+		if err != nil {
+			return err
+		}
+
+		report, err := sbom.Report(imagesSBOMOpts.Format, versionMF.Version, images, time.Now())
+		if err != nil {
+			return err
+		}
+		fc, err := common.ToJSONString(report)
+		if err != nil {
+			return err
+		}
+
+		var out io.Writer = os.Stdout
+		if imagesSBOMOpts.Output != "" {
+			f, err := os.Create(imagesSBOMOpts.Output)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			out = f
+		}
+		_, err = fmt.Fprintln(out, string(fc))
+		return err
+	},
+}
+
+// configImages returns the images that are rendered from the config. Unlike the mirror list these
+// are the images of the configured repository and only of the configured platform.
+func configImages(cfgVersion string, cfg *configv1.Config) ([]string, error) {
+	k8s, err := renderKubernetesObjects(cfgVersion, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// The images in the ConfigMaps are found by the repository, as getGenericImages does it for
+	// the Gitpod repository
+	repo := regexp.MustCompile(regexp.QuoteMeta(strings.TrimRight(cfg.Repository, "/")) + `/[^\s",]+`)
+
+	allImages := make(map[string]bool)
+	for _, item := range k8s {
+		rawImages := append(getPodImages(item), getGenericImages(item)...)
+		rawImages = append(rawImages, repo.FindAllString(item, -1)...)
+		for _, img := range rawImages {
+			if img == common.GitpodContainerRegistry {
+				continue
+			}
+			if _, err := reference.ParseNamed(img); err != nil {
+				continue
+			}
+			allImages[img] = true
+		}
+	}
+
+	images := make([]string, 0, len(allImages))
+	for img := range allImages {
+		images = append(images, img)
+	}
+	sort.Strings(images)
+
+	return images, nil
+}
+
+func init() {
+	imagesCmd.AddCommand(imagesSBOMCmd)
+
+	imagesSBOMCmd.Flags().StringVarP(&imagesSBOMOpts.ConfigFN, "config", "c", os.Getenv("GITPOD_INSTALLER_CONFIG"), "path to the config file")
+	imagesSBOMCmd.Flags().StringVar(&imagesSBOMOpts.Format, "format", sbom.FormatSPDX, fmt.Sprintf("format of the SBOM, one of %s or %s", sbom.FormatSPDX, sbom.FormatCycloneDX))
+	imagesSBOMCmd.Flags().StringVarP(&imagesSBOMOpts.Output, "output", "o", "", "path to write the SBOM to, defaults to stdout")
+}
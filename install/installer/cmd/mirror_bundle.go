@@ -0,0 +1,88 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/gitpod-io/gitpod/common-go/log"
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	"github.com/gitpod-io/gitpod/installer/pkg/mirror"
+	"github.com/spf13/cobra"
+)
+
+var mirrorBundleOpts struct {
+	ConfigFN          string
+	ExcludeThirdParty bool
+	Output            string
+}
+
+// mirrorBundleCmd represents the mirror bundle command
+var mirrorBundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "Pulls every image used into a bundle that can be copied to an air-gapped environment",
+	Long: `Pulls every image used into a bundle that can be copied to an air-gapped environment
+
+The images are the same as those listed by "mirror list", so the "repository"
+field in the config must be set to your container repository server address.
+The bundle is an OCI image layout tarball which also contains a manifest of
+the images, their target names and digests. Use "mirror push" to push the
+images in the bundle to the repository.
+
+Credentials for the registries are read from the local Docker config.`,
+	Example: `
+  gitpod-installer mirror bundle --config config.yaml --output gitpod-images.tar`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if mirrorBundleOpts.ConfigFN == "" {
+			return fmt.Errorf("config is a required flag")
+		}
+
+		_, cfgVersion, cfg, err := loadConfig(mirrorBundleOpts.ConfigFN, renderOpts.Namespace)
+		if err != nil {
+			return err
+		}
+
+		list, err := generateMirrorList(cfgVersion, cfg, mirrorBundleOpts.ExcludeThirdParty)
+		if err != nil {
+			return err
+		}
+
+		images := make([]mirror.Image, 0, len(list))
+		for _, img := range list {
+			images = append(images, mirror.Image{
+				Original: img.Original,
+				Target:   img.Target,
+			})
+		}
+
+		f, err := os.Create(mirrorBundleOpts.Output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		err = mirror.Bundle(context.Background(), common.NewRegistryResolver(), images, f, func(img mirror.Image) {
+			log.Infof("Pulled %s (%s)", img.Original, img.Digest)
+		})
+		if err != nil {
+			os.Remove(mirrorBundleOpts.Output)
+			return err
+		}
+
+		log.Infof("%d images written to %s", len(images), mirrorBundleOpts.Output)
+
+		return nil
+	},
+}
+
+func init() {
+	mirrorCmd.AddCommand(mirrorBundleCmd)
+
+	mirrorBundleCmd.Flags().BoolVar(&mirrorBundleOpts.ExcludeThirdParty, "exclude-third-party", false, "exclude non-Gitpod images")
+	mirrorBundleCmd.Flags().StringVarP(&mirrorBundleOpts.ConfigFN, "config", "c", os.Getenv("GITPOD_INSTALLER_CONFIG"), "path to the config file")
+	mirrorBundleCmd.Flags().StringVarP(&mirrorBundleOpts.Output, "output", "o", "gitpod-images.tar", "path to write the bundle to")
+}
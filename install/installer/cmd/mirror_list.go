@@ -58,12 +58,12 @@ image to the "target" repo`,
 			return fmt.Errorf("config is a required flag")
 		}
 
-		_, cfgVersion, cfg, err := loadConfig(mirrorListOpts.ConfigFN)
+		_, cfgVersion, cfg, err := loadConfig(mirrorListOpts.ConfigFN, renderOpts.Namespace)
 		if err != nil {
 			return err
 		}
 
-		images, err := generateMirrorList(cfgVersion, cfg)
+		images, err := generateMirrorList(cfgVersion, cfg, mirrorListOpts.ExcludeThirdParty)
 		if err != nil {
 			return err
 		}
@@ -97,7 +97,7 @@ func renderAllKubernetesObject(cfgVersion string, cfg *configv1.Config) ([]strin
 			cfg.Database = configv1.Database{
 				InCluster: pointer.Bool(false),
 				External: &configv1.DatabaseExternal{
-					Certificate: configv1.ObjectRef{
+					Certificate: &configv1.ObjectRef{
 						Kind: configv1.ObjectRefSecret,
 						Name: "value",
 					},
@@ -181,7 +181,7 @@ func renderAllKubernetesObject(cfgVersion string, cfg *configv1.Config) ([]strin
 	return k8s, nil
 }
 
-func generateMirrorList(cfgVersion string, cfg *configv1.Config) ([]mirrorListRepo, error) {
+func generateMirrorList(cfgVersion string, cfg *configv1.Config, excludeThirdParty bool) ([]mirrorListRepo, error) {
 	// Throw error if set to the default Gitpod repository
 	if cfg.Repository == common.GitpodContainerRegistry {
 		return nil, fmt.Errorf("cannot mirror images to repository %s", common.GitpodContainerRegistry)
@@ -193,6 +193,9 @@ func generateMirrorList(cfgVersion string, cfg *configv1.Config) ([]mirrorListRe
 	// Use the default Gitpod registry to pull from
 	cfg.Repository = common.GitpodContainerRegistry
 
+	// Images are mirrored by tag
+	cfg.PinImageDigests = nil
+
 	k8s, err := renderAllKubernetesObject(cfgVersion, cfg)
 	if err != nil {
 		return nil, err
@@ -224,7 +227,7 @@ func generateMirrorList(cfgVersion string, cfg *configv1.Config) ([]mirrorListRe
 		if strings.Contains(img, cfg.Repository) {
 			// This is the Gitpod registry
 			target = strings.Replace(target, cfg.Repository, targetRepo, 1)
-		} else if !mirrorListOpts.ExcludeThirdParty {
+		} else if !excludeThirdParty {
 			// Amend third-party images - remove the first part
 			thirdPartyImg := strings.Join(strings.Split(img, "/")[1:], "/")
 			target = fmt.Sprintf("%s/%s", targetRepo, thirdPartyImg)
@@ -0,0 +1,59 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"context"
+	"os"
+
+	"github.com/gitpod-io/gitpod/common-go/log"
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	"github.com/gitpod-io/gitpod/installer/pkg/mirror"
+	"github.com/spf13/cobra"
+)
+
+var mirrorPushOpts struct {
+	Bundle string
+}
+
+// mirrorPushCmd represents the mirror push command
+var mirrorPushCmd = &cobra.Command{
+	Use:   "push",
+	Short: "Pushes the images in a bundle to their target repository",
+	Long: `Pushes the images in a bundle to their target repository
+
+The bundle is created with "mirror bundle". Each image is pushed to the
+target name in the bundle's manifest, keeping its digest.
+
+Credentials for the registry are read from the local Docker config.`,
+	Example: `
+  gitpod-installer mirror push --bundle gitpod-images.tar`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		f, err := os.Open(mirrorPushOpts.Bundle)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		count := 0
+		err = mirror.Push(context.Background(), common.NewRegistryResolver(), f, func(img mirror.Image) {
+			count++
+			log.Infof("Pushed %s", img.Target)
+		})
+		if err != nil {
+			return err
+		}
+
+		log.Infof("%d images pushed", count)
+
+		return nil
+	},
+}
+
+func init() {
+	mirrorCmd.AddCommand(mirrorPushCmd)
+
+	mirrorPushCmd.Flags().StringVarP(&mirrorPushOpts.Bundle, "bundle", "b", "gitpod-images.tar", "path to the bundle created by mirror bundle")
+}
@@ -10,17 +10,20 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 
 	_ "embed"
 
 	"github.com/gitpod-io/gitpod/common-go/log"
 	"github.com/gitpod-io/gitpod/installer/pkg/common"
 	"github.com/gitpod-io/gitpod/installer/pkg/components"
+	"github.com/gitpod-io/gitpod/installer/pkg/components/migrations"
 	"github.com/gitpod-io/gitpod/installer/pkg/config"
 	configv1 "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
 	"github.com/gitpod-io/gitpod/installer/pkg/config/v1/experimental"
 	"github.com/gitpod-io/gitpod/installer/pkg/postprocess"
 	"github.com/spf13/cobra"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/yaml"
 )
 
@@ -30,8 +33,39 @@ var renderOpts struct {
 	ValidateConfigDisabled bool
 	UseExperimentalConfig  bool
 	FilesDir               string
+	OutputDir              string
+	Component              string
+	OutputFormat           string
+	PinDigests             bool
+	NoCache                bool
+	NoExpiredCache         bool
+	// Cluster is the cluster the Kubernetes version is detected from if the config sets none
+	Cluster       *kubeConfig
+	Target        string
+	Phase         string
+	Compatibility compatibilityOpts
+	// Compare is the previous render the objects are compared against instead of printed
+	Compare             string
+	CompareOutputFormat string
+	ExitCode            bool
 }
 
+const (
+	outputFormatYAML      = "yaml"
+	outputFormatHelmChart = "helm-chart"
+	outputFormatKustomize = "kustomize"
+
+	renderTargetAll       = "all"
+	renderTargetMeta      = "meta"
+	renderTargetWorkspace = "workspace"
+
+	renderPhaseAll        = "all"
+	renderPhasePreUpgrade = "pre-upgrade"
+	renderPhaseUpgrade    = "upgrade"
+
+	clusterScopedDir = "cluster"
+)
+
 // renderCmd represents the render command
 var renderCmd = &cobra.Command{
 	Use:   "render",
@@ -43,13 +77,84 @@ A config file is required which can be generated with the init command.`,
   gitpod-installer render --config config.yaml | kubectl apply -f -
 
   # Install Gitpod into a non-default namespace.
-  gitpod-installer render --config config.yaml --namespace gitpod | kubectl apply -f -`,
+  gitpod-installer render --config config.yaml --namespace gitpod | kubectl apply -f -
+
+  # Render only the server component.
+  gitpod-installer render --config config.yaml --component server | kubectl apply -f -
+
+  # Render one file per object into the ./manifests directory.
+  gitpod-installer render --config config.yaml --output-dir ./manifests
+
+  # Reference every image by its digest rather than its tag.
+  gitpod-installer render --config config.yaml --pin-digests | kubectl apply -f -
+
+  # Split a full installation into a meta and a workspace cluster.
+  gitpod-installer render --config config.yaml --target meta | kubectl --context meta apply -f -
+  gitpod-installer render --config config.yaml --target workspace | kubectl --context workspace apply -f -
+
+  # Run the database migrations before the other components are upgraded.
+  gitpod-installer render --config config.yaml --phase pre-upgrade | kubectl apply -f -
+  kubectl wait --for=condition=complete --timeout=10m job/migrations
+  gitpod-installer render --config config.yaml --phase upgrade | kubectl apply -f -
+
+  # Check the upgrade from the installed version before it is applied.
+  gitpod-installer render --config config.yaml --kubeconfig ~/.kube/config | kubectl apply -f -
+
+  # Render a Helm chart into the ./chart directory.
+  gitpod-installer render --config config.yaml --output-format helm-chart --output-dir ./chart
+
+  # Render a Kustomize base and an overlay to start from into the ./kustomize directory.
+  gitpod-installer render --config config.yaml --output-format kustomize --output-dir ./kustomize
+  kubectl apply -k ./kustomize/overlays/custom
+
+  # Show the objects a config change makes to a render that was saved before.
+  gitpod-installer render --config config.yaml --output-dir ./snapshot
+  gitpod-installer render --config new-config.yaml --compare ./snapshot --exit-code`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		yaml, err := renderFn()
+		if renderOpts.Compatibility.Kube.Config != "" {
+			renderOpts.Cluster = &renderOpts.Compatibility.Kube
+		}
+
+		cfgVersion, cfg, err := loadRenderConfig()
 		if err != nil {
 			return err
 		}
 
+		yaml, err := renderKubernetesObjects(cfgVersion, cfg)
+		if err != nil {
+			return err
+		}
+
+		if renderOpts.Compare != "" {
+			if renderOpts.OutputFormat != outputFormatYAML {
+				return fmt.Errorf("--compare cannot be used with --output-format %s", renderOpts.OutputFormat)
+			}
+			changed, err := compareRender(os.Stdout, renderOpts.Compare, renderOpts.CompareOutputFormat, yaml)
+			if err != nil {
+				return err
+			}
+			if renderOpts.ExitCode && changed {
+				os.Exit(1)
+			}
+			return nil
+		}
+
+		switch renderOpts.OutputFormat {
+		case outputFormatYAML:
+		case outputFormatHelmChart:
+			if renderOpts.OutputDir == "" {
+				return fmt.Errorf("--output-dir must be set to the directory the Helm chart is written to")
+			}
+			return saveYamlToHelmChart(renderOpts.OutputDir, cfg, renderOpts.Namespace, yaml)
+		case outputFormatKustomize:
+			if renderOpts.OutputDir == "" {
+				return fmt.Errorf("--output-dir must be set to the directory the Kustomize base and overlay are written to")
+			}
+			return saveYamlToKustomize(renderOpts.OutputDir, renderOpts.Namespace, yaml)
+		default:
+			return fmt.Errorf("unsupported output format: %s", renderOpts.OutputFormat)
+		}
+
 		if renderOpts.FilesDir != "" {
 			err := saveYamlToFiles(renderOpts.FilesDir, yaml)
 			if err != nil {
@@ -58,6 +163,10 @@ A config file is required which can be generated with the init command.`,
 			return nil
 		}
 
+		if renderOpts.OutputDir != "" {
+			return saveYamlToDir(renderOpts.OutputDir, yaml)
+		}
+
 		for _, item := range yaml {
 			fmt.Println(item)
 		}
@@ -67,11 +176,21 @@ A config file is required which can be generated with the init command.`,
 }
 
 func renderFn() ([]string, error) {
-	_, cfgVersion, cfg, err := loadConfig(renderOpts.ConfigFN)
+	cfgVersion, cfg, err := loadRenderConfig()
 	if err != nil {
 		return nil, err
 	}
 
+	return renderKubernetesObjects(cfgVersion, cfg)
+}
+
+// loadRenderConfig loads the config file, dropping the experimental config unless it is enabled
+func loadRenderConfig() (string, *configv1.Config, error) {
+	_, cfgVersion, cfg, err := loadConfig(renderOpts.ConfigFN, renderOpts.Namespace)
+	if err != nil {
+		return "", nil, err
+	}
+
 	if cfg.Experimental != nil {
 		if renderOpts.UseExperimentalConfig {
 			fmt.Fprintf(os.Stderr, "rendering using experimental config\n")
@@ -81,7 +200,36 @@ func renderFn() ([]string, error) {
 		}
 	}
 
-	return renderKubernetesObjects(cfgVersion, cfg)
+	if renderOpts.PinDigests {
+		cfg.PinImageDigests = pointer.Bool(true)
+	}
+
+	if cfg.KubernetesVersion == "" && renderOpts.Cluster != nil {
+		version, err := kubernetesVersionFromKubeConfig(renderOpts.Cluster)
+		if err != nil {
+			return "", nil, fmt.Errorf("cannot detect the Kubernetes version of the cluster, set kubernetesVersion in the config instead: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "rendering for Kubernetes %s\n", version)
+		cfg.KubernetesVersion = version
+	}
+
+	return cfgVersion, cfg, nil
+}
+
+// imageDigestCacheDir returns the directory the digests of the pinned images are cached in, or
+// an empty string if they are not cached
+func imageDigestCacheDir() string {
+	if renderOpts.NoCache {
+		return ""
+	}
+	if dir := os.Getenv("GITPOD_INSTALLER_CACHE_DIR"); dir != "" {
+		return filepath.Join(dir, "image-digests")
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "gitpod-installer", "image-digests")
 }
 
 func saveYamlToFiles(dir string, yaml []string) error {
@@ -100,7 +248,51 @@ func saveYamlToFiles(dir string, yaml []string) error {
 	return nil
 }
 
-func loadConfig(cfgFN string) (rawCfg interface{}, cfgVersion string, cfg *configv1.Config, err error) {
+// saveYamlToDir writes each object to <namespace>/<kind>-<name>.yaml. Objects without
+// a namespace, such as cluster roles, are written to the cluster directory.
+func saveYamlToDir(dir string, yaml []string) error {
+	written := make(map[string]struct{}, len(yaml))
+	for _, mf := range yaml {
+		objs, err := common.YamlToRuntimeObject([]string{mf})
+		if err != nil {
+			return err
+		}
+		obj := objs[0]
+
+		namespace := obj.Metadata.Namespace
+		if namespace == "" {
+			namespace = clusterScopedDir
+		}
+		nsDir := filepath.Join(dir, namespace)
+		if err := os.MkdirAll(nsDir, 0755); err != nil {
+			return err
+		}
+
+		fn := filepath.Join(nsDir, fmt.Sprintf("%s-%s.yaml", strings.ToLower(obj.Kind), obj.Metadata.Name))
+		if _, exists := written[fn]; exists {
+			return fmt.Errorf("cannot write %s: more than one object uses this file name", fn)
+		}
+		written[fn] = struct{}{}
+
+		if err := ioutil.WriteFile(fn, []byte(mf), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadConfig reads the config file and executes its templated fields with the namespace
+func loadConfig(cfgFN, namespace string) (rawCfg interface{}, cfgVersion string, cfg *configv1.Config, err error) {
+	rawCfg, cfgVersion, cfg, err = readConfig(cfgFN)
+	if err != nil {
+		return
+	}
+	err = executeConfigTemplates(cfg, namespace)
+	return
+}
+
+// readConfig reads the config file as it is written, with the templates of its fields
+func readConfig(cfgFN string) (rawCfg interface{}, cfgVersion string, cfg *configv1.Config, err error) {
 	var overrideConfig string
 	// Update overrideConfig if cfgFN is not empty
 	switch cfgFN {
@@ -132,9 +324,37 @@ func loadConfig(cfgFN string) (rawCfg interface{}, cfgVersion string, cfg *confi
 	}
 	cfg = rawCfg.(*configv1.Config)
 
+	templatesDir := "."
+	if cfgFN != "-" {
+		templatesDir = filepath.Dir(cfgFN)
+	}
+	err = cfg.Workspace.Templates.LoadFiles(templatesDir)
+	if err != nil {
+		err = fmt.Errorf("error loading config: %w", err)
+		return
+	}
+
 	return rawCfg, cfgVersion, cfg, err
 }
 
+// executeConfigTemplates executes the templated fields of the config with the namespace and the
+// version of the installation. The version manifest is only read if the config has templates.
+func executeConfigTemplates(cfg *configv1.Config, namespace string) error {
+	if !cfg.HasFieldTemplates() {
+		return nil
+	}
+
+	versionMF, err := getVersionManifest()
+	if err != nil {
+		return fmt.Errorf("cannot read the version for the templates of the config: %w", err)
+	}
+	return cfg.ExecuteFieldTemplates(configv1.FieldTemplateValues{
+		Namespace: namespace,
+		Version:   versionMF.Version,
+		Kind:      cfg.Kind,
+	})
+}
+
 func renderKubernetesObjects(cfgVersion string, cfg *configv1.Config) ([]string, error) {
 	versionMF, err := getVersionManifest()
 	if err != nil {
@@ -163,10 +383,23 @@ func renderKubernetesObjects(cfgVersion string, cfg *configv1.Config) ([]string,
 		}
 	}
 
-	ctx, err := common.NewRenderContext(*cfg, *versionMF, renderOpts.Namespace)
+	if renderOpts.Compatibility.enabled() {
+		warnings, err := checkCompatibility(&renderOpts.Compatibility, renderOpts.Namespace, versionMF.Version, cfgVersion)
+		for _, w := range warnings {
+			fmt.Fprintf(os.Stderr, "%s\n", w)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, err := newRenderContext(cfg, versionMF, renderOpts.Namespace)
 	if err != nil {
 		return nil, err
 	}
+	if dir := imageDigestCacheDir(); dir != "" {
+		ctx.SetImageDigestResolver(common.CachedImageDigestResolver(dir, common.ImageDigestCacheTTL, !renderOpts.NoExpiredCache, common.RegistryImageDigestResolver()))
+	}
 
 	var renderable common.RenderFunc
 	var helmCharts common.HelmFunc
@@ -190,10 +423,72 @@ func renderKubernetesObjects(cfgVersion string, cfg *configv1.Config) ([]string,
 		return nil, fmt.Errorf("unsupported installation kind: %s", cfg.Kind)
 	}
 
-	objs, err := common.CompositeRenderFunc(components.CommonObjects, renderable)(ctx)
+	kind := cfg.Kind
+	switch renderOpts.Target {
+	case "", renderTargetAll:
+	case renderTargetMeta, renderTargetWorkspace:
+		if cfg.Kind != configv1.InstallationFull {
+			return nil, fmt.Errorf("--target %s can only be used with installation kind %s", renderOpts.Target, configv1.InstallationFull)
+		}
+		if renderOpts.Component != "" {
+			return nil, fmt.Errorf("--target and --component cannot be used together")
+		}
+		if renderOpts.Target == renderTargetMeta {
+			renderable = components.MetaObjects
+			helmCharts = components.MetaHelmDependencies
+			kind = configv1.InstallationMeta
+		} else {
+			renderable = components.WorkspaceObjects
+			helmCharts = components.WorkspaceHelmDependencies
+			kind = configv1.InstallationWorkspace
+		}
+	default:
+		return nil, fmt.Errorf("unsupported render target: %s", renderOpts.Target)
+	}
+
+	renderable = common.CompositeRenderFunc(renderable, components.ExtensionObjects(kind))
+	helmCharts = common.CompositeHelmFunc(helmCharts, components.ExtensionHelmDependencies(kind))
+
+	component := renderOpts.Component
+	switch renderOpts.Phase {
+	case "", renderPhaseAll, renderPhaseUpgrade:
+	case renderPhasePreUpgrade:
+		if component != "" {
+			return nil, fmt.Errorf("--phase %s and --component cannot be used together", renderPhasePreUpgrade)
+		}
+		// The migrations run against the database of the current installation
+		component = migrations.Component
+	default:
+		return nil, fmt.Errorf("unsupported render phase: %s", renderOpts.Phase)
+	}
+
+	commonObjects := components.CommonObjects
+	commonHelmCharts := components.CommonHelmDependencies
+	if component != "" {
+		// Only render the selected component - the common objects belong to other components
+		renderable, helmCharts, err = components.ForComponent(component)
+		if err != nil {
+			return nil, err
+		}
+		commonObjects = common.CompositeRenderFunc()
+		commonHelmCharts = common.CompositeHelmFunc()
+	}
+
+	objs, err := common.CompositeRenderFunc(commonObjects, renderable)(ctx)
 	if err != nil {
 		return nil, err
 	}
+	common.WorkloadProbes(ctx, objs)
+	common.WorkloadEnv(ctx, objs)
+	common.WorkloadNodeSelector(ctx, objs)
+	if err := common.WorkloadContainers(ctx, objs); err != nil {
+		return nil, err
+	}
+	common.WorkloadSecurity(ctx, objs)
+	common.WorkloadDNS(ctx, objs)
+	objs = common.WorkloadServiceMonitors(ctx, objs)
+	common.WorkloadScrapeAnnotations(ctx, objs)
+	objs = common.WorkloadMetricsTLS(ctx, objs)
 
 	k8s := make([]string, 0)
 	for _, o := range objs {
@@ -205,24 +500,57 @@ func renderKubernetesObjects(cfgVersion string, cfg *configv1.Config) ([]string,
 		k8s = append(k8s, fmt.Sprintf("---\n%s\n", string(fc)))
 	}
 
-	charts, err := common.CompositeHelmFunc(components.CommonHelmDependencies, helmCharts)(ctx)
+	charts, err := common.CompositeHelmFunc(commonHelmCharts, helmCharts)(ctx)
 	if err != nil {
 		return nil, err
 	}
 	k8s = append(k8s, charts...)
 
+	if err := ctx.ImageDigestErrors(); err != nil {
+		return nil, err
+	}
+	if err := ctx.ImageVariantErrors(); err != nil {
+		return nil, err
+	}
+
 	// convert everything to individual objects
 	runtimeObjs, err := common.YamlToRuntimeObject(k8s)
 	if err != nil {
 		return nil, err
 	}
 
-	// generate a config map with every component installed
-	runtimeObjsAndConfig, err := common.GenerateInstallationConfigMap(ctx, runtimeObjs)
+	if err := postprocess.ExternalStorage(ctx.Config.ObjectStorage, ctx.Namespace, runtimeObjs); err != nil {
+		return nil, err
+	}
+
+	// the migrations have already been run by the pre-upgrade phase
+	if renderOpts.Phase == renderPhaseUpgrade {
+		runtimeObjs = excludeMigrationsJob(runtimeObjs)
+	}
+
+	// without permissions for the cluster, everything is scoped to the namespace
+	if ctx.Config.NamespaceScoped() {
+		runtimeObjs, err = postprocess.NamespaceScope(ctx.Namespace, runtimeObjs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	runtimeObjs, err = postprocess.Secrets(ctx.Config.SecretsBackend, ctx.Namespace, runtimeObjs)
 	if err != nil {
 		return nil, err
 	}
 
+	// generate a config map with every component installed
+	// this is skipped when rendering a single component as it would not list every object installed
+	runtimeObjsAndConfig := runtimeObjs
+	if component == "" {
+		runtimeObjsAndConfig, err = common.GenerateInstallationConfigMap(ctx, runtimeObjs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// sort the objects and return the plain YAML
 	sortedObjs, err := common.DependencySortingRenderFunc(runtimeObjsAndConfig)
 	if err != nil {
@@ -244,6 +572,35 @@ func renderKubernetesObjects(cfgVersion string, cfg *configv1.Config) ([]string,
 		return nil, err
 	}
 
+	postProcessed, err = postprocess.Mesh(ctx.Config.Mesh, postProcessed)
+	if err != nil {
+		return nil, err
+	}
+
+	postProcessed, err = postprocess.ClusterAutoscaler(ctx.Config.ClusterAutoscaler, postProcessed)
+	if err != nil {
+		return nil, err
+	}
+
+	configHash, err := postprocess.ConfigHash(cfg)
+	if err != nil {
+		return nil, err
+	}
+	postProcessed, err = postprocess.OwnerLabels(ctx.Namespace, configHash, postProcessed)
+	if err != nil {
+		return nil, err
+	}
+
+	postProcessed, err = postprocess.ApplyOrder(ctx.Config.ApplyOrder, postProcessed)
+	if err != nil {
+		return nil, err
+	}
+
+	postProcessed, err = postprocess.Patch(ctx.Config.Customization, postProcessed)
+	if err != nil {
+		return nil, err
+	}
+
 	// output the YAML to stdout
 	output := make([]string, 0)
 	for _, c := range postProcessed {
@@ -253,6 +610,17 @@ func renderKubernetesObjects(cfgVersion string, cfg *configv1.Config) ([]string,
 	return output, nil
 }
 
+func excludeMigrationsJob(objs []common.RuntimeObject) []common.RuntimeObject {
+	res := make([]common.RuntimeObject, 0, len(objs))
+	for _, o := range objs {
+		if o.Kind == common.TypeMetaBatchJob.Kind && o.Metadata.Name == migrations.Component {
+			continue
+		}
+		res = append(res, o)
+	}
+	return res
+}
+
 func init() {
 	rootCmd.AddCommand(renderCmd)
 
@@ -266,4 +634,20 @@ func init() {
 	renderCmd.Flags().BoolVar(&renderOpts.ValidateConfigDisabled, "no-validation", false, "if set, the config will not be validated before running")
 	renderCmd.Flags().BoolVar(&renderOpts.UseExperimentalConfig, "use-experimental-config", false, "enable the use of experimental config that is prone to be changed")
 	renderCmd.Flags().StringVar(&renderOpts.FilesDir, "output-split-files", "", "path to output individual Kubernetes manifests to")
+	renderCmd.Flags().StringVar(&renderOpts.OutputDir, "output-dir", "", "path to output one Kubernetes manifest per object to, grouped by namespace")
+	renderCmd.MarkFlagsMutuallyExclusive("output-dir", "output-split-files")
+	renderCmd.Flags().StringVar(&renderOpts.Compare, "compare", "", "path to a previous render, a file or a directory of --output-dir or --output-split-files, to print the differences to instead of the objects")
+	renderCmd.Flags().StringVar(&renderOpts.CompareOutputFormat, "compare-output-format", compareOutputText, fmt.Sprintf("format of the differences of --compare, one of %s or %s", compareOutputText, compareOutputJSON))
+	renderCmd.Flags().BoolVar(&renderOpts.ExitCode, "exit-code", false, "exit with a non-zero status if --compare finds any differences")
+	renderCmd.MarkFlagsMutuallyExclusive("compare", "output-dir")
+	renderCmd.MarkFlagsMutuallyExclusive("compare", "output-split-files")
+	renderCmd.Flags().StringVar(&renderOpts.OutputFormat, "output-format", outputFormatYAML, fmt.Sprintf("format of the rendered output, one of %s, %s or %s", outputFormatYAML, outputFormatHelmChart, outputFormatKustomize))
+	renderCmd.Flags().BoolVar(&renderOpts.PinDigests, "pin-digests", false, "resolve the tag of every image to its digest, this requires access to the image registries")
+	renderCmd.Flags().BoolVar(&renderOpts.NoCache, "no-cache", false, "resolve the digests of the images against the registries instead of using the cached digests")
+	renderCmd.Flags().BoolVar(&renderOpts.NoExpiredCache, "no-expired-cache", false, "fail instead of using an expired cached digest if a registry cannot be reached")
+	renderCmd.Flags().StringVar(&renderOpts.Component, "component", "", "only render the objects of the named component, eg server")
+	renderCmd.Flags().StringVar(&renderOpts.Phase, "phase", renderPhaseAll, fmt.Sprintf("upgrade phase to render, one of %s, %s for the database migrations only or %s for everything but the migrations", renderPhaseAll, renderPhasePreUpgrade, renderPhaseUpgrade))
+	renderCmd.Flags().StringVar(&renderOpts.Compatibility.Kube.Config, "kubeconfig", "", "path to the kubeconfig of the cluster to check the upgrade from the installed version against and to detect the Kubernetes version of")
+	renderCmd.Flags().StringVar(&renderOpts.Compatibility.InstalledVersion, "installed-version", "", "version of the installation to check the upgrade from, instead of reading it from the cluster")
+	renderCmd.Flags().StringVar(&renderOpts.Target, "target", renderTargetAll, fmt.Sprintf("cluster to render a %s installation for, one of %s, %s or %s", configv1.InstallationFull, renderTargetAll, renderTargetMeta, renderTargetWorkspace))
 }
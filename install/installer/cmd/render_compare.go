@@ -0,0 +1,84 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/gitpod-io/gitpod/common-go/log"
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	"github.com/gitpod-io/gitpod/installer/pkg/compare"
+)
+
+const (
+	compareOutputText = "text"
+	compareOutputJSON = "json"
+)
+
+// compareRender compares the rendered objects to the previous render in path and prints the
+// objects that differ. It returns whether there are any differences.
+func compareRender(out io.Writer, path, format string, yaml []string) (bool, error) {
+	previous, err := compare.ReadPath(path)
+	if err != nil {
+		return false, fmt.Errorf("cannot read the previous render: %w", err)
+	}
+
+	res, err := compare.Compare(previous, yaml)
+	if err != nil {
+		return false, err
+	}
+
+	switch format {
+	case compareOutputText:
+		printCompareResult(out, res)
+	case compareOutputJSON:
+		fc, err := common.ToJSONString(res)
+		if err != nil {
+			return false, err
+		}
+		fmt.Fprintln(out, string(fc))
+	default:
+		return false, fmt.Errorf("unsupported compare output format: %s", format)
+	}
+
+	counts := make(map[compare.Status]int)
+	for _, obj := range res.Objects {
+		counts[obj.Status]++
+	}
+	log.Infof("%d objects added, %d removed, %d changed and %d unchanged", counts[compare.StatusAdded], counts[compare.StatusRemoved], counts[compare.StatusChanged], res.Unchanged)
+
+	return len(res.Objects) > 0, nil
+}
+
+func printCompareResult(out io.Writer, res *compare.Result) {
+	for _, obj := range res.Objects {
+		name := fmt.Sprintf("%s/%s", obj.Kind, obj.Name)
+		if obj.Namespace != "" {
+			name = fmt.Sprintf("%s (namespace %s)", name, obj.Namespace)
+		}
+		fmt.Fprintf(out, "--- %s: %s\n", name, obj.Status)
+
+		for _, c := range obj.Changes {
+			switch {
+			case c.Previous == nil:
+				fmt.Fprintf(out, "  + %s: %s\n", c.Path, compareValue(c.Current))
+			case c.Current == nil:
+				fmt.Fprintf(out, "  - %s: %s\n", c.Path, compareValue(c.Previous))
+			default:
+				fmt.Fprintf(out, "  ~ %s: %s -> %s\n", c.Path, compareValue(c.Previous), compareValue(c.Current))
+			}
+		}
+	}
+}
+
+func compareValue(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	configv1 "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	helmChartName    = "gitpod"
+	helmChartVersion = "0.1.0"
+)
+
+// saveYamlToHelmChart writes the rendered objects as a Helm chart to dir. The installer config
+// is saved as the chart's values, and the templates read the domain and the repository from them.
+func saveYamlToHelmChart(dir string, cfg *configv1.Config, namespace string, yaml []string) error {
+	versionMF, err := getVersionManifest()
+	if err != nil {
+		return err
+	}
+
+	c, err := helmChartFromYaml(cfg, namespace, versionMF.Version, yaml)
+	if err != nil {
+		return err
+	}
+
+	return chartutil.SaveDir(c, dir)
+}
+
+func helmChartFromYaml(cfg *configv1.Config, namespace, appVersion string, manifests []string) (*chart.Chart, error) {
+	values, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	// the values.yaml file is saved from Raw, Values is what the templates are rendered with
+	parsedValues, err := chartutil.ReadValues(values)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion:  chart.APIVersionV2,
+			Name:        helmChartName,
+			Description: "Gitpod rendered by the Gitpod Installer",
+			Type:        "application",
+			Version:     helmChartVersion,
+			AppVersion:  appVersion,
+		},
+		Values: parsedValues,
+		Raw: []*chart.File{{
+			Name: chartutil.ValuesfileName,
+			Data: values,
+		}},
+		Templates: []*chart.File{{
+			// the objects carry the namespace they were rendered for
+			Name: chartutil.TemplatesDir + "/namespace.yaml",
+			Data: []byte(fmt.Sprintf(`{{- if ne .Release.Namespace %[1]q }}
+{{- fail "the chart was rendered for the namespace %[1]s, install it there or render it again with --namespace" }}
+{{- end }}
+`, namespace)),
+		}},
+	}
+
+	for i, mf := range manifests {
+		objs, err := common.YamlToRuntimeObject([]string{mf})
+		if err != nil {
+			return nil, err
+		}
+		obj := objs[0]
+
+		c.Templates = append(c.Templates, &chart.File{
+			Name: fmt.Sprintf("%s/%03d_%s_%s.yaml", chartutil.TemplatesDir, i, obj.Kind, obj.Metadata.Name),
+			Data: []byte(templateHelmValues(cfg, escapeHelmTemplate(mf))),
+		})
+	}
+
+	return c, c.Validate()
+}
+
+// escapeHelmTemplate stops Helm from evaluating template actions that are part of the
+// rendered objects, eg in config files
+func escapeHelmTemplate(mf string) string {
+	return strings.ReplaceAll(mf, "{{", `{{"{{"}}`)
+}
+
+// templateHelmValues replaces the repository of the images and the domain with the values
+// of the chart. The repository goes first, as it can be a subdomain of the domain.
+func templateHelmValues(cfg *configv1.Config, mf string) string {
+	if cfg.Repository != "" {
+		mf = strings.ReplaceAll(mf, cfg.Repository+"/", "{{ .Values.repository }}/")
+	}
+	if cfg.Domain != "" {
+		mf = strings.ReplaceAll(mf, cfg.Domain, "{{ .Values.domain }}")
+	}
+	return mf
+}
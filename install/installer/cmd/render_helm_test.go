@@ -0,0 +1,53 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"testing"
+
+	configv1 "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+)
+
+const testHelmManifest = "---\n# v1/ConfigMap test\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: test\n  namespace: gitpod\ndata:\n  image: registry.gitpod.example.com/build/server:test\n  template: https://{{ .Prefix }}.gitpod.example.com\n"
+
+func renderTestHelmChart(t *testing.T, namespace string, overrides map[string]interface{}) (*chart.Chart, map[string]string, error) {
+	cfg := &configv1.Config{Domain: "gitpod.example.com", Repository: "registry.gitpod.example.com/build"}
+	c, err := helmChartFromYaml(cfg, "gitpod", "test", []string{testHelmManifest})
+	require.NoError(t, err)
+
+	values, err := chartutil.ToRenderValues(c, overrides, chartutil.ReleaseOptions{Name: "gitpod", Namespace: namespace}, nil)
+	require.NoError(t, err)
+
+	rendered, err := engine.Render(c, values)
+	return c, rendered, err
+}
+
+func TestHelmChartFromYaml(t *testing.T) {
+	c, rendered, err := renderTestHelmChart(t, "gitpod", nil)
+	require.NoError(t, err)
+	require.Equal(t, "templates/000_ConfigMap_test.yaml", c.Templates[1].Name)
+	require.Equal(t, "gitpod.example.com", c.Values["domain"])
+	require.Equal(t, "registry.gitpod.example.com/build", c.Values["repository"])
+	require.Equal(t, testHelmManifest, rendered["gitpod/templates/000_ConfigMap_test.yaml"], "the rendered chart must match the installer output")
+}
+
+func TestHelmChartFromYaml_Values(t *testing.T) {
+	_, rendered, err := renderTestHelmChart(t, "gitpod", map[string]interface{}{
+		"domain":     "gitpod.example.org",
+		"repository": "mirror.example.org/gitpod",
+	})
+	require.NoError(t, err)
+	require.Contains(t, rendered["gitpod/templates/000_ConfigMap_test.yaml"], "image: mirror.example.org/gitpod/server:test\n")
+	require.Contains(t, rendered["gitpod/templates/000_ConfigMap_test.yaml"], "template: https://{{ .Prefix }}.gitpod.example.org\n")
+}
+
+func TestHelmChartFromYaml_Namespace(t *testing.T) {
+	_, _, err := renderTestHelmChart(t, "default", nil)
+	require.ErrorContains(t, err, "the chart was rendered for the namespace gitpod")
+}
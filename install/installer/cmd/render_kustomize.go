@@ -0,0 +1,146 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	kustomizeBaseDir    = "base"
+	kustomizeOverlayDir = "overlays/custom"
+)
+
+// kustomizeOverlayPatches is appended to the overlay to show how the objects of the base are changed
+const kustomizeOverlayPatches = `# Patches change the objects of the base, eg
+# patches:
+#   - target:
+#       kind: Deployment
+#       name: server
+#     patch: |-
+#       - op: replace
+#         path: /spec/replicas
+#         value: 2
+`
+
+// saveYamlToKustomize writes the rendered objects as a Kustomize base to dir, together with an
+// overlay that shows the images, namespace and patches that an environment would change
+func saveYamlToKustomize(dir, namespace string, yaml []string) error {
+	files, err := kustomizeFromYaml(namespace, yaml)
+	if err != nil {
+		return err
+	}
+
+	for fn, fc := range files {
+		fn = filepath.Join(dir, fn)
+		if err := os.MkdirAll(filepath.Dir(fn), 0755); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(fn, fc, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// kustomizeFromYaml returns the files of the base and the overlay by their path
+func kustomizeFromYaml(namespace string, manifests []string) (map[string][]byte, error) {
+	files := make(map[string][]byte, len(manifests)+2)
+	base := types.Kustomization{
+		TypeMeta: types.TypeMeta{APIVersion: types.KustomizationVersion, Kind: types.KustomizationKind},
+	}
+	images := make(map[string]types.Image)
+
+	for i, mf := range manifests {
+		objs, err := common.YamlToRuntimeObject([]string{mf})
+		if err != nil {
+			return nil, err
+		}
+		obj := objs[0]
+
+		fn := fmt.Sprintf("%03d_%s_%s.yaml", i, obj.Kind, obj.Metadata.Name)
+		files[filepath.Join(kustomizeBaseDir, fn)] = []byte(mf)
+		base.Resources = append(base.Resources, fn)
+
+		var content interface{}
+		if err := yaml.Unmarshal([]byte(obj.Content), &content); err != nil {
+			return nil, err
+		}
+		for _, img := range containerImages(content) {
+			image := kustomizeImage(img)
+			if other, ok := images[image.Name]; ok && other != image {
+				// the overlay must not change the image to one of its versions
+				image = types.Image{Name: image.Name}
+			}
+			images[image.Name] = image
+		}
+	}
+
+	overlay := types.Kustomization{
+		TypeMeta:  base.TypeMeta,
+		Resources: []string{"../../" + kustomizeBaseDir},
+		Namespace: namespace,
+	}
+	for _, image := range images {
+		overlay.Images = append(overlay.Images, image)
+	}
+	sort.Slice(overlay.Images, func(i, j int) bool { return overlay.Images[i].Name < overlay.Images[j].Name })
+
+	fc, err := yaml.Marshal(base)
+	if err != nil {
+		return nil, err
+	}
+	files[filepath.Join(kustomizeBaseDir, "kustomization.yaml")] = fc
+
+	fc, err = yaml.Marshal(overlay)
+	if err != nil {
+		return nil, err
+	}
+	files[filepath.Join(kustomizeOverlayDir, "kustomization.yaml")] = append(fc, []byte(kustomizeOverlayPatches)...)
+
+	return files, nil
+}
+
+// containerImages returns the images of the containers in the object, which are the maps
+// that have both a name and an image
+func containerImages(obj interface{}) []string {
+	var res []string
+	switch v := obj.(type) {
+	case map[string]interface{}:
+		_, hasName := v["name"].(string)
+		if img, ok := v["image"].(string); ok && hasName {
+			res = append(res, img)
+		}
+		for _, child := range v {
+			res = append(res, containerImages(child)...)
+		}
+	case []interface{}:
+		for _, child := range v {
+			res = append(res, containerImages(child)...)
+		}
+	}
+	return res
+}
+
+// kustomizeImage returns the image transformation that keeps the image as it is. Kustomize
+// matches the images by their name without the tag or digest.
+func kustomizeImage(img string) types.Image {
+	if name, digest, ok := strings.Cut(img, "@"); ok {
+		return types.Image{Name: name, Digest: digest}
+	}
+	if i := strings.LastIndex(img, ":"); i > strings.LastIndex(img, "/") {
+		return types.Image{Name: img[:i], NewTag: img[i+1:]}
+	}
+	return types.Image{Name: img}
+}
@@ -0,0 +1,52 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/yaml"
+)
+
+func TestKustomizeFromYaml(t *testing.T) {
+	manifests := []string{
+		"---\n# v1/ConfigMap test\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: test\n  namespace: gitpod\ndata:\n  key: value\n",
+		"---\n# apps/v1/Deployment server\napiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: server\n  namespace: gitpod\nspec:\n  template:\n    spec:\n      initContainers:\n      - name: wait\n        image: eu.gcr.io/gitpod/service-waiter:v1\n      containers:\n      - name: server\n        image: eu.gcr.io/gitpod/server@sha256:abc\n      - name: proxy\n        image: localhost:5000/proxy\n",
+		"---\n# apps/v1/Deployment other\napiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: other\n  namespace: gitpod\nspec:\n  template:\n    spec:\n      containers:\n      - name: wait\n        image: eu.gcr.io/gitpod/service-waiter:v2\n",
+	}
+
+	files, err := kustomizeFromYaml("gitpod", manifests)
+	require.NoError(t, err)
+	require.Contains(t, files, "base/000_ConfigMap_test.yaml")
+	require.Contains(t, files, "base/001_Deployment_server.yaml")
+
+	var overlay types.Kustomization
+	require.NoError(t, yaml.Unmarshal(files["overlays/custom/kustomization.yaml"], &overlay))
+	require.Equal(t, "gitpod", overlay.Namespace)
+	require.Equal(t, []types.Image{
+		{Name: "eu.gcr.io/gitpod/server", Digest: "sha256:abc"},
+		{Name: "eu.gcr.io/gitpod/service-waiter"},
+		{Name: "localhost:5000/proxy"},
+	}, overlay.Images, "an image with several versions is left as it is")
+
+	fs := filesys.MakeFsInMemory()
+	for fn, fc := range files {
+		require.NoError(t, fs.WriteFile(filepath.Join("/out", fn), fc))
+	}
+	res, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(fs, "/out/overlays/custom")
+	require.NoError(t, err)
+	require.Len(t, res.Resources(), 3)
+
+	out, err := res.AsYaml()
+	require.NoError(t, err)
+	require.Contains(t, string(out), "image: eu.gcr.io/gitpod/server@sha256:abc", "the overlay does not change the images")
+	require.Contains(t, string(out), "image: eu.gcr.io/gitpod/service-waiter:v1")
+	require.Contains(t, string(out), "image: eu.gcr.io/gitpod/service-waiter:v2")
+}
@@ -7,15 +7,75 @@ package cmd
 import (
 	"flag"
 	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	configv1 "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
 )
 
 func init() {
 	// Ensure that the randomisation always returns the same values
-	rootOpts.SeedValue = 42
+	rootOpts.Seed = "42"
 }
 
 func TestMain(m *testing.M) {
 	flag.Parse()
 	os.Exit(m.Run())
 }
+
+func TestExecuteConfigTemplates(t *testing.T) {
+	versionMF := filepath.Join(t.TempDir(), "versions.yaml")
+	require.NoError(t, os.WriteFile(versionMF, []byte("version: 2023.1.0\n"), 0644))
+	previous := rootOpts.VersionMF
+	rootOpts.VersionMF = versionMF
+	t.Cleanup(func() { rootOpts.VersionMF = previous })
+
+	cfg := &configv1.Config{
+		Kind:   configv1.InstallationFull,
+		Domain: "{{ .Namespace }}.gitpod.example.com",
+		ObjectStorage: configv1.ObjectStorage{S3: &configv1.ObjectStorageS3{
+			BucketName: "gitpod-{{ .Namespace }}",
+		}},
+		Components: &configv1.Components{PodConfig: map[string]*configv1.PodConfig{
+			"server": {ServiceAccountAnnotations: map[string]string{
+				"eks.amazonaws.com/role-arn": "arn:aws:iam::123456789012:role/{{ .Namespace }}-server",
+				"gitpod.io/version":          "{{ .Version }}-{{ .Kind }}",
+			}},
+		}},
+	}
+	require.True(t, cfg.HasFieldTemplates())
+	require.NoError(t, executeConfigTemplates(cfg, "staging"))
+
+	require.Equal(t, "staging.gitpod.example.com", cfg.Domain)
+	require.Equal(t, "gitpod-staging", cfg.ObjectStorage.S3.BucketName)
+	require.Equal(t, map[string]string{
+		"eks.amazonaws.com/role-arn": "arn:aws:iam::123456789012:role/staging-server",
+		"gitpod.io/version":          "2023.1.0-Full",
+	}, cfg.Components.PodConfig["server"].ServiceAccountAnnotations)
+	require.False(t, cfg.HasFieldTemplates())
+
+	err := executeConfigTemplates(&configv1.Config{Domain: "{{ .Region }}.gitpod.example.com"}, "staging")
+	require.ErrorContains(t, err, "cannot execute the template of domain")
+}
+
+func TestLoadConfigTemplates(t *testing.T) {
+	dir := t.TempDir()
+	versionMF := filepath.Join(dir, "versions.yaml")
+	require.NoError(t, os.WriteFile(versionMF, []byte("version: 2023.1.0\n"), 0644))
+	previous := rootOpts.VersionMF
+	rootOpts.VersionMF = versionMF
+	t.Cleanup(func() { rootOpts.VersionMF = previous })
+
+	cfgFN := filepath.Join(dir, "gitpod.config.yaml")
+	require.NoError(t, os.WriteFile(cfgFN, []byte("apiVersion: v1\nkind: Full\ndomain: '{{ .Namespace }}.gitpod.example.com'\n"), 0644))
+
+	_, _, cfg, err := loadConfig(cfgFN, "staging")
+	require.NoError(t, err)
+	require.Equal(t, "staging.gitpod.example.com", cfg.Domain, "every command sees the executed templates")
+
+	_, _, cfg, err = readConfig(cfgFN)
+	require.NoError(t, err)
+	require.Equal(t, "{{ .Namespace }}.gitpod.example.com", cfg.Domain, "the templates are kept when the config is written back")
+}
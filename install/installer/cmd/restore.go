@@ -0,0 +1,158 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/gitpod-io/gitpod/common-go/log"
+	"github.com/gitpod-io/gitpod/installer/pkg/backup"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
+)
+
+var restoreOpts struct {
+	Kube           kubeConfig
+	Input          string
+	PassphraseFile string
+	ConfigFN       string
+	DryRun         bool
+}
+
+// restoreCmd represents the restore command
+var restoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Imports the config and secrets of an archive written by backup",
+	Long: `Imports the config and secrets of an archive written by backup
+
+The config is written to the file given with --config, which must not exist
+yet, and the secrets are created or replaced in the cluster. The secrets of
+the installation's namespace are restored into the namespace given with
+--namespace, the others into the namespace they were backed up from.
+
+Render and apply the restored config with the installer of the backed up
+version to get the installation as it was. The random values the installer
+generates only change if the secrets that hold them are not restored.`,
+	Example: `  # Restore an installation into the gitpod namespace of another cluster.
+  gitpod-installer restore --input gitpod-backup.bin --passphrase-file passphrase.txt --namespace gitpod --config gitpod.config.yaml
+
+  # Print the secrets of the archive instead of creating them.
+  gitpod-installer restore --input gitpod-backup.bin --passphrase-file passphrase.txt --config gitpod.config.yaml --dry-run`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if restoreOpts.Input == "" {
+			return fmt.Errorf("--input must be set to the archive to restore")
+		}
+		if restoreOpts.ConfigFN == "" {
+			return fmt.Errorf("--config must be set to the file the config is written to")
+		}
+		passphrase, err := readBackupPassphrase(restoreOpts.PassphraseFile)
+		if err != nil {
+			return err
+		}
+
+		f, err := os.Open(restoreOpts.Input)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		archive, err := backup.Read(f, passphrase)
+		if err != nil {
+			return err
+		}
+
+		namespace := archive.Manifest.Namespace
+		if cmd.Flags().Changed("namespace") {
+			namespace = renderOpts.Namespace
+		}
+		secrets := restoredSecrets(archive, namespace)
+
+		cfgFile, err := os.OpenFile(restoreOpts.ConfigFN, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err != nil {
+			return fmt.Errorf("cannot write the config: %w", err)
+		}
+		defer cfgFile.Close()
+		if _, err := cfgFile.Write(archive.Config); err != nil {
+			return err
+		}
+
+		if restoreOpts.DryRun {
+			for _, secret := range secrets {
+				fc, err := yaml.Marshal(secret.Object)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("---\n%s", fc)
+			}
+			return nil
+		}
+
+		client, _, err := dynamicClientFromKubeConfig(&restoreOpts.Kube)
+		if err != nil {
+			return err
+		}
+		ctx := context.Background()
+		for _, secret := range secrets {
+			if err := restoreSecret(ctx, client, secret); err != nil {
+				return err
+			}
+			log.Infof("restored secret %s/%s", secret.GetNamespace(), secret.GetName())
+		}
+
+		log.Infof("config of version %s written to %s, render it with the installer of that version", archive.Manifest.Version, restoreOpts.ConfigFN)
+		return nil
+	},
+}
+
+// restoredSecrets returns the secrets of the archive with those of the backed up namespace moved
+// to the namespace
+func restoredSecrets(archive *backup.Archive, namespace string) []*unstructured.Unstructured {
+	res := make([]*unstructured.Unstructured, 0, len(archive.Secrets))
+	for i, secret := range archive.Secrets {
+		secret = secret.DeepCopy()
+		if ns := archive.Manifest.Secrets[i].Namespace; ns == archive.Manifest.Namespace {
+			secret.SetNamespace(namespace)
+		} else {
+			secret.SetNamespace(ns)
+		}
+		res = append(res, secret)
+	}
+	return res
+}
+
+// restoreSecret creates the secret, or replaces it if it exists
+func restoreSecret(ctx context.Context, client dynamic.Interface, secret *unstructured.Unstructured) error {
+	resource := client.Resource(gvrSecrets).Namespace(secret.GetNamespace())
+
+	_, err := resource.Create(ctx, secret, metav1.CreateOptions{})
+	if !errors.IsAlreadyExists(err) {
+		return err
+	}
+
+	live, err := resource.Get(ctx, secret.GetName(), metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	secret = secret.DeepCopy()
+	secret.SetResourceVersion(live.GetResourceVersion())
+	_, err = resource.Update(ctx, secret, metav1.UpdateOptions{})
+	return err
+}
+
+func init() {
+	rootCmd.AddCommand(restoreCmd)
+
+	restoreCmd.Flags().StringVarP(&renderOpts.Namespace, "namespace", "n", getEnvvar("NAMESPACE", "default"), "namespace to restore the installation into, defaults to the namespace it was backed up from")
+	restoreCmd.Flags().StringVar(&restoreOpts.Kube.Config, "kubeconfig", "", "path to the kubeconfig file")
+	restoreCmd.Flags().StringVarP(&restoreOpts.Input, "input", "i", "", "path to the archive to restore")
+	restoreCmd.Flags().StringVar(&restoreOpts.PassphraseFile, "passphrase-file", "", fmt.Sprintf("path to a file with the passphrase of the archive, defaults to the %s environment variable", backupPassphraseEnv))
+	restoreCmd.Flags().StringVarP(&restoreOpts.ConfigFN, "config", "c", "", "path to write the restored config to")
+	restoreCmd.Flags().BoolVar(&restoreOpts.DryRun, "dry-run", false, "print the secrets instead of creating them in the cluster")
+}
@@ -5,17 +5,26 @@
 package cmd
 
 import (
-	cryptoRand "crypto/rand"
-	"math/rand"
+	"fmt"
 	"os"
 	"os/user"
 	"path/filepath"
-	"strings"
 
+	"github.com/Masterminds/semver"
 	"github.com/gitpod-io/gitpod/common-go/log"
 	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	configv1 "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+	"github.com/gitpod-io/gitpod/installer/pkg/config/versions"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -31,14 +40,14 @@ func Execute() {
 var rootOpts struct {
 	VersionMF         string
 	StrictConfigParse bool
-	SeedValue         int64
+	Seed              string
 	LogLevel          string
 }
 
 func init() {
-	cobra.OnInitialize(setSeed, setLogLevel)
+	cobra.OnInitialize(setLogLevel)
 	rootCmd.PersistentFlags().StringVar(&rootOpts.VersionMF, "debug-version-file", "", "path to a version manifest - not intended for production use")
-	rootCmd.PersistentFlags().Int64Var(&rootOpts.SeedValue, "seed", 0, "specify the seed value for randomization - if 0 it is kept as the default")
+	rootCmd.PersistentFlags().StringVar(&rootOpts.Seed, "seed", getEnvvar("GITPOD_INSTALLER_SEED", ""), "secret the generated values are derived from, so that every render generates the same values - random if empty [$GITPOD_INSTALLER_SEED]")
 	rootCmd.PersistentFlags().BoolVar(&rootOpts.StrictConfigParse, "strict-parse", true, "toggle strict configuration parsing")
 	rootCmd.PersistentFlags().StringVar(&rootOpts.LogLevel, "log-level", "info", "set the log level")
 }
@@ -52,17 +61,13 @@ func setLogLevel() {
 	log.Log.Logger.SetLevel(newLevel)
 }
 
-func setSeed() {
-	if rootOpts.SeedValue != 0 {
-		rand.Seed(rootOpts.SeedValue)
-
-		// crypto/rand is used by the bcrypt package to generate its random values
-		str, err := common.RandomString(128)
-		if err != nil {
-			panic(err)
-		}
-		cryptoRand.Reader = strings.NewReader(str)
+// newRenderContext creates the render context, with the generated values derived from the
+// --seed if it is set
+func newRenderContext(cfg *configv1.Config, versionMF *versions.Manifest, namespace string) (*common.RenderContext, error) {
+	if rootOpts.Seed == "" {
+		return common.NewRenderContext(*cfg, *versionMF, namespace)
 	}
+	return common.NewSeededRenderContext(*cfg, *versionMF, namespace, []byte(rootOpts.Seed))
 }
 
 type kubeConfig struct {
@@ -85,6 +90,84 @@ func checkKubeConfig(kube *kubeConfig) error {
 	return nil
 }
 
+// restConfigFromKubeConfig returns the client config of the cluster in the kubeconfig
+func restConfigFromKubeConfig(kube *kubeConfig) (*rest.Config, error) {
+	if err := checkKubeConfig(kube); err != nil {
+		return nil, err
+	}
+
+	clientcfg := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: kube.Config},
+		&clientcmd.ConfigOverrides{},
+	)
+	return clientcfg.ClientConfig()
+}
+
+// kubernetesVersionFromKubeConfig returns the major and minor version of Kubernetes the cluster
+// in the kubeconfig runs, e.g. 1.25
+func kubernetesVersionFromKubeConfig(kube *kubeConfig) (string, error) {
+	res, err := restConfigFromKubeConfig(kube)
+	if err != nil {
+		return "", err
+	}
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(res)
+	if err != nil {
+		return "", err
+	}
+	info, err := discoveryClient.ServerVersion()
+	if err != nil {
+		return "", err
+	}
+
+	// the providers append their own release to the version, e.g. v1.25.4-gke.1600
+	version, err := semver.NewVersion(info.GitVersion)
+	if err != nil {
+		return "", fmt.Errorf("cannot parse the Kubernetes version %s: %w", info.GitVersion, err)
+	}
+	return fmt.Sprintf("%d.%d", version.Major(), version.Minor()), nil
+}
+
+// dynamicClientFromKubeConfig returns a dynamic client and REST mapper for the cluster in the kubeconfig
+func dynamicClientFromKubeConfig(kube *kubeConfig) (dynamic.Interface, meta.RESTMapper, error) {
+	res, err := restConfigFromKubeConfig(kube)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client, err := dynamic.NewForConfig(res)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(res)
+	if err != nil {
+		return nil, nil, err
+	}
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discoveryClient))
+
+	return client, mapper, nil
+}
+
+// resourceForObject returns the resource client for the object's kind and the namespace it lives in
+func resourceForObject(client dynamic.Interface, mapper meta.RESTMapper, obj *unstructured.Unstructured) (dynamic.ResourceInterface, string, error) {
+	gvk := obj.GroupVersionKind()
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, "", fmt.Errorf("cannot find resource for %s: %w", gvk.String(), err)
+	}
+
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		return client.Resource(mapping.Resource), "", nil
+	}
+
+	namespace := obj.GetNamespace()
+	if namespace == "" {
+		namespace = renderOpts.Namespace
+	}
+
+	return client.Resource(mapping.Resource).Namespace(namespace), namespace, nil
+}
+
 // getEnvvar gets an envvar and allows a default value
 func getEnvvar(key, fallback string) string {
 	if value, ok := os.LookupEnv(key); ok {
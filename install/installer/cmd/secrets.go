@@ -0,0 +1,19 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// secretsCmd represents the secrets command
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Manages the secrets generated by the installer",
+}
+
+func init() {
+	rootCmd.AddCommand(secretsCmd)
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gitpod-io/gitpod/common-go/log"
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	configv1 "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+var secretsRotateOpts struct {
+	Groups []string
+}
+
+// secretsRotateCmd represents the secrets rotate command
+var secretsRotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "Renders the objects that change when generated secrets are rotated",
+	Long: `Renders the objects that change when generated secrets are rotated
+
+The secrets the installer generates are derived from the seed the installation
+is rendered with, so the same --seed must be given. The output contains the
+rotated secrets and the objects that use them, whose checksum annotations make
+the affected pods restart once applied.
+
+The rotation must be kept in the config, otherwise the next render restores
+the previous secrets - the secretRotations to set are printed to stderr.
+
+Secrets issued by cert-manager, such as the ws-manager client certificates,
+are rotated by cert-manager and are not affected.`,
+	Example: `  # Rotate the credentials of the in-cluster registry.
+  gitpod-installer secrets rotate --config config.yaml --seed "$GITPOD_INSTALLER_SEED" --group registry | kubectl apply -f -`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if rootOpts.Seed == "" {
+			return fmt.Errorf("--seed must be set to the seed the installation is rendered with")
+		}
+		if len(secretsRotateOpts.Groups) == 0 {
+			return fmt.Errorf("at least one --group must be rotated")
+		}
+
+		cfgVersion, cfg, err := loadRenderConfig()
+		if err != nil {
+			return err
+		}
+
+		rotations := make(map[configv1.SecretGroup]int, len(cfg.SecretRotations)+len(secretsRotateOpts.Groups))
+		for group, rotation := range cfg.SecretRotations {
+			rotations[group] = rotation
+		}
+		for _, g := range secretsRotateOpts.Groups {
+			group := configv1.SecretGroup(g)
+			if _, ok := configv1.SecretGroupList[group]; !ok {
+				return fmt.Errorf("unknown secret group %s - valid groups are: %s", g, strings.Join(secretGroupNames(), ", "))
+			}
+			rotations[group]++
+		}
+
+		current, err := renderKubernetesObjects(cfgVersion, cfg)
+		if err != nil {
+			return err
+		}
+
+		rotatedCfg := *cfg
+		rotatedCfg.SecretRotations = rotations
+		rotated, err := renderKubernetesObjects(cfgVersion, &rotatedCfg)
+		if err != nil {
+			return err
+		}
+
+		changed, err := changedObjects(current, rotated)
+		if err != nil {
+			return err
+		}
+		for _, item := range changed {
+			fmt.Println(item)
+		}
+
+		fc, err := yaml.Marshal(map[string]interface{}{"secretRotations": rotations})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "set the secret rotations in the config to keep the rotated secrets:\n%s", fc)
+
+		return nil
+	},
+}
+
+// changedObjects returns the rendered objects that are new or differ from the current render
+func changedObjects(current, rendered []string) ([]string, error) {
+	key := func(obj common.RuntimeObject) string {
+		return fmt.Sprintf("%s/%s/%s/%s", obj.APIVersion, obj.Kind, obj.Metadata.Namespace, obj.Metadata.Name)
+	}
+
+	currentObjs, err := common.YamlToRuntimeObject(current)
+	if err != nil {
+		return nil, err
+	}
+	contents := make(map[string]string, len(currentObjs))
+	for _, obj := range currentObjs {
+		contents[key(obj)] = obj.Content
+	}
+
+	var res []string
+	for _, mf := range rendered {
+		objs, err := common.YamlToRuntimeObject([]string{mf})
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objs {
+			if content, ok := contents[key(obj)]; !ok || content != obj.Content {
+				res = append(res, mf)
+				break
+			}
+		}
+	}
+	return res, nil
+}
+
+func secretGroupNames() []string {
+	names := make([]string, 0, len(configv1.SecretGroupList))
+	for group := range configv1.SecretGroupList {
+		names = append(names, string(group))
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	secretsCmd.AddCommand(secretsRotateCmd)
+
+	dir, err := os.Getwd()
+	if err != nil {
+		log.WithError(err).Fatal("Failed to get working directory")
+	}
+
+	secretsRotateCmd.Flags().StringVarP(&renderOpts.ConfigFN, "config", "c", getEnvvar("GITPOD_INSTALLER_CONFIG", filepath.Join(dir, "gitpod.config.yaml")), "path to the config file, use - for stdin")
+	secretsRotateCmd.Flags().StringVarP(&renderOpts.Namespace, "namespace", "n", getEnvvar("NAMESPACE", "default"), "namespace Gitpod is deployed to")
+	secretsRotateCmd.Flags().BoolVar(&renderOpts.UseExperimentalConfig, "use-experimental-config", false, "enable the use of experimental config that is prone to be changed")
+	secretsRotateCmd.Flags().StringSliceVar(&secretsRotateOpts.Groups, "group", nil, "group of secrets to rotate, one of registry, storage or server - can be repeated")
+}
@@ -0,0 +1,27 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangedObjects(t *testing.T) {
+	current := []string{
+		"---\n# v1/Secret registry\napiVersion: v1\nkind: Secret\nmetadata:\n  name: registry\ndata:\n  password: b2xk\n",
+		"---\n# v1/ConfigMap server\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: server\n",
+	}
+	rendered := []string{
+		"---\n# v1/Secret registry\napiVersion: v1\nkind: Secret\nmetadata:\n  name: registry\ndata:\n  password: bmV3\n",
+		"---\n# v1/ConfigMap server\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: server\n",
+		"---\n# v1/ConfigMap proxy\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: proxy\n",
+	}
+
+	changed, err := changedObjects(current, rendered)
+	require.NoError(t, err)
+	require.Equal(t, []string{rendered[0], rendered[2]}, changed)
+}
@@ -0,0 +1,296 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	"github.com/gitpod-io/gitpod/installer/pkg/components/gitpod"
+	"github.com/gitpod-io/gitpod/installer/pkg/config"
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	statusOutputText = "text"
+	statusOutputJSON = "json"
+)
+
+// workloadResources are the resources of the kinds whose health is reported
+var workloadResources = map[string]schema.GroupVersionResource{
+	common.TypeMetaDeployment.Kind:  {Group: "apps", Version: "v1", Resource: "deployments"},
+	common.TypeMetaStatefulSet.Kind: {Group: "apps", Version: "v1", Resource: "statefulsets"},
+	common.TypeMetaDaemonset.Kind:   {Group: "apps", Version: "v1", Resource: "daemonsets"},
+}
+
+var gvrCronJobs = schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "cronjobs"}
+
+type workloadStatus struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Component string `json:"component,omitempty"`
+	Desired   int64  `json:"desired"`
+	Ready     int64  `json:"ready"`
+	Healthy   bool   `json:"healthy"`
+	// Message describes why the workload is unhealthy
+	Message string `json:"message,omitempty"`
+}
+
+type installationStatus struct {
+	Namespace string `json:"namespace"`
+	Version   string `json:"version"`
+	Platform  string `json:"platform,omitempty"`
+	// LastTelemetry is the last time the telemetry was sent successfully
+	LastTelemetry *time.Time `json:"lastTelemetry,omitempty"`
+	// ConfigDrift is the difference between the installed config and the one that was given,
+	// it is empty if they are the same or no config was given
+	ConfigDrift string           `json:"configDrift,omitempty"`
+	Workloads   []workloadStatus `json:"workloads"`
+}
+
+// healthy returns whether every workload is healthy
+func (s *installationStatus) healthy() bool {
+	for _, w := range s.Workloads {
+		if !w.Healthy {
+			return false
+		}
+	}
+	return true
+}
+
+var statusOpts struct {
+	Kube         kubeConfig
+	ConfigFN     string
+	OutputFormat string
+	ExitCode     bool
+}
+
+// statusCmd represents the status command
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Reports the version, config and health of an installation",
+	Long: `Reports the version, config and health of an installation
+
+The version and config are read from the gitpod config map the installer
+renders into the namespace, so they are those of the last applied render.
+If a config file is given, it is compared against the installed config to
+show the changes that have not been applied yet. The health of every
+deployment, stateful set and daemon set listed in the gitpod-app config map
+is reported by their ready replicas.`,
+	Example: `  # Check the installation is healthy and runs the given config.
+  gitpod-installer status --namespace gitpod --config config.yaml --exit-code`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var cfg []byte
+		if statusOpts.ConfigFN != "" {
+			_, cfgVersion, c, err := loadConfig(statusOpts.ConfigFN, renderOpts.Namespace)
+			if err != nil {
+				return err
+			}
+			cfg, err = config.Marshal(cfgVersion, c)
+			if err != nil {
+				return err
+			}
+		}
+
+		client, _, err := dynamicClientFromKubeConfig(&statusOpts.Kube)
+		if err != nil {
+			return err
+		}
+
+		status, err := installStatus(context.Background(), client, renderOpts.Namespace, cfg)
+		if err != nil {
+			return err
+		}
+
+		switch statusOpts.OutputFormat {
+		case statusOutputText:
+			printStatus(os.Stdout, status)
+		case statusOutputJSON:
+			fc, err := common.ToJSONString(status)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(fc))
+		default:
+			return fmt.Errorf("unsupported output format: %s", statusOpts.OutputFormat)
+		}
+
+		if statusOpts.ExitCode && (status.ConfigDrift != "" || !status.healthy()) {
+			os.Exit(1)
+		}
+		return nil
+	},
+}
+
+// installStatus reads the status of the installation in the namespace. The config is compared
+// against the installed one if it is given.
+func installStatus(ctx context.Context, client dynamic.Interface, namespace string, cfg []byte) (*installationStatus, error) {
+	cfgMap, err := client.Resource(gvrConfigMaps).Namespace(namespace).Get(ctx, gitpod.Component, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, fmt.Errorf("there is no installation in namespace %s - the %s config map does not exist", namespace, gitpod.Component)
+	} else if err != nil {
+		return nil, err
+	}
+	data, _, err := unstructured.NestedStringMap(cfgMap.Object, "data")
+	if err != nil {
+		return nil, err
+	}
+
+	var installed gitpod.Gitpod
+	if err := json.Unmarshal([]byte(data["versions.json"]), &installed); err != nil {
+		return nil, fmt.Errorf("cannot read the installed versions: %w", err)
+	}
+	var installedCfg struct {
+		Telemetry *struct {
+			Data *struct {
+				Platform string `json:"platform"`
+			} `json:"data"`
+		} `json:"telemetry"`
+	}
+	if err := yaml.Unmarshal([]byte(data["config.yaml"]), &installedCfg); err != nil {
+		return nil, fmt.Errorf("cannot read the installed config: %w", err)
+	}
+
+	res := &installationStatus{
+		Namespace: namespace,
+		Version:   installed.VersionManifest.Version,
+	}
+	if installedCfg.Telemetry != nil && installedCfg.Telemetry.Data != nil {
+		res.Platform = installedCfg.Telemetry.Data.Platform
+	}
+
+	cronJob, err := client.Resource(gvrCronJobs).Namespace(namespace).Get(ctx, gitpod.Component, metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	}
+	if err == nil {
+		if ts, ok, _ := unstructured.NestedString(cronJob.Object, "status", "lastSuccessfulTime"); ok {
+			if t, err := time.Parse(time.RFC3339, ts); err == nil {
+				res.LastTelemetry = &t
+			}
+		}
+	}
+
+	if cfg != nil {
+		res.ConfigDrift, err = configDrift([]byte(data["config.yaml"]), cfg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	objs, err := installedObjects(ctx, client, namespace)
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range objs {
+		gvr, ok := workloadResources[obj.Kind]
+		if !ok {
+			continue
+		}
+		w, err := workloadHealth(ctx, client.Resource(gvr).Namespace(namespace), obj)
+		if err != nil {
+			return nil, err
+		}
+		res.Workloads = append(res.Workloads, *w)
+	}
+
+	return res, nil
+}
+
+// configDrift returns the difference between the installed and the given config
+func configDrift(installed, cfg []byte) (string, error) {
+	var from, to interface{}
+	if err := yaml.Unmarshal(installed, &from); err != nil {
+		return "", err
+	}
+	if err := yaml.Unmarshal(cfg, &to); err != nil {
+		return "", err
+	}
+	return cmp.Diff(from, to), nil
+}
+
+func workloadHealth(ctx context.Context, resource dynamic.ResourceInterface, obj common.RuntimeObject) (*workloadStatus, error) {
+	res := &workloadStatus{Kind: obj.Kind, Name: obj.Metadata.Name, Component: obj.Metadata.Labels["component"]}
+
+	live, err := resource.Get(ctx, obj.Metadata.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		res.Message = "not found"
+		return res, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot get %s %s: %w", obj.Kind, obj.Metadata.Name, err)
+	}
+
+	if obj.Kind == common.TypeMetaDaemonset.Kind {
+		res.Desired, _, _ = unstructured.NestedInt64(live.Object, "status", "desiredNumberScheduled")
+		res.Ready, _, _ = unstructured.NestedInt64(live.Object, "status", "numberReady")
+	} else {
+		// the replicas default to one when they are not set
+		replicas, ok, _ := unstructured.NestedInt64(live.Object, "spec", "replicas")
+		if !ok {
+			replicas = 1
+		}
+		res.Desired = replicas
+		res.Ready, _, _ = unstructured.NestedInt64(live.Object, "status", "readyReplicas")
+	}
+
+	res.Healthy = res.Ready >= res.Desired
+	if !res.Healthy {
+		res.Message = fmt.Sprintf("%d of %d ready", res.Ready, res.Desired)
+	}
+	return res, nil
+}
+
+func printStatus(out io.Writer, status *installationStatus) {
+	fmt.Fprintf(out, "Namespace:  %s\n", status.Namespace)
+	fmt.Fprintf(out, "Version:    %s\n", status.Version)
+	if status.Platform != "" {
+		fmt.Fprintf(out, "Platform:   %s\n", status.Platform)
+	}
+	if status.LastTelemetry != nil {
+		fmt.Fprintf(out, "Telemetry:  last sent %s\n", status.LastTelemetry.Format(time.RFC3339))
+	}
+	if statusOpts.ConfigFN != "" {
+		if status.ConfigDrift == "" {
+			fmt.Fprintln(out, "Config:     matches the installed config")
+		} else {
+			fmt.Fprintf(out, "Config:     differs from the installed config\n%s\n", status.ConfigDrift)
+		}
+	}
+
+	fmt.Fprintln(out)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KIND\tNAME\tREADY\tSTATUS")
+	for _, wl := range status.Workloads {
+		state := "healthy"
+		if !wl.Healthy {
+			state = wl.Message
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d/%d\t%s\n", wl.Kind, wl.Name, wl.Ready, wl.Desired, state)
+	}
+	w.Flush()
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+
+	statusCmd.Flags().StringVarP(&renderOpts.Namespace, "namespace", "n", getEnvvar("NAMESPACE", "default"), "namespace Gitpod is deployed to")
+	statusCmd.Flags().StringVar(&statusOpts.Kube.Config, "kubeconfig", "", "path to the kubeconfig file")
+	statusCmd.Flags().StringVarP(&statusOpts.ConfigFN, "config", "c", "", "path to a config file to compare against the installed config, use - for stdin")
+	statusCmd.Flags().StringVar(&statusOpts.OutputFormat, "output-format", statusOutputText, fmt.Sprintf("format of the status, one of %s or %s", statusOutputText, statusOutputJSON))
+	statusCmd.Flags().BoolVar(&statusOpts.ExitCode, "exit-code", false, "exit with a non-zero status if the config differs or a workload is unhealthy")
+}
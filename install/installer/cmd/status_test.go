@@ -0,0 +1,99 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func TestInstallStatus(t *testing.T) {
+	object := func(apiVersion, kind, name string, fields map[string]interface{}) runtime.Object {
+		obj := map[string]interface{}{
+			"apiVersion": apiVersion,
+			"kind":       kind,
+			"metadata":   map[string]interface{}{"name": name, "namespace": "gitpod"},
+		}
+		for k, v := range fields {
+			obj[k] = v
+		}
+		return &unstructured.Unstructured{Object: obj}
+	}
+
+	installedCfg := "apiVersion: v1\ndomain: gitpod.example.com\ntelemetry:\n  data:\n    platform: gke\n"
+	app := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: server
+  labels:
+    component: server
+---
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: ws-daemon
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: messagebus
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: server
+`
+
+	client := fake.NewSimpleDynamicClient(runtime.NewScheme(),
+		object("v1", "ConfigMap", "gitpod", map[string]interface{}{
+			"data": map[string]interface{}{
+				"config.yaml":   installedCfg,
+				"versions.json": `{"versions":{"version":"2023.3.0"}}`,
+			},
+		}),
+		object("v1", "ConfigMap", installationConfigMap, map[string]interface{}{
+			"data": map[string]interface{}{"app.yaml": app},
+		}),
+		object("batch/v1", "CronJob", "gitpod", map[string]interface{}{
+			"status": map[string]interface{}{"lastSuccessfulTime": "2023-03-01T00:00:00Z"},
+		}),
+		object("apps/v1", "Deployment", "server", map[string]interface{}{
+			"spec":   map[string]interface{}{"replicas": int64(2)},
+			"status": map[string]interface{}{"readyReplicas": int64(1)},
+		}),
+		object("apps/v1", "DaemonSet", "ws-daemon", map[string]interface{}{
+			"status": map[string]interface{}{"desiredNumberScheduled": int64(3), "numberReady": int64(3)},
+		}),
+	)
+
+	status, err := installStatus(context.Background(), client, "gitpod", nil)
+	require.NoError(t, err)
+	require.Equal(t, "2023.3.0", status.Version)
+	require.Equal(t, "gke", status.Platform)
+	require.Equal(t, "2023-03-01T00:00:00Z", status.LastTelemetry.Format("2006-01-02T15:04:05Z07:00"))
+	require.Empty(t, status.ConfigDrift)
+	require.Equal(t, []workloadStatus{
+		{Kind: "Deployment", Name: "server", Component: "server", Desired: 2, Ready: 1, Message: "1 of 2 ready"},
+		{Kind: "DaemonSet", Name: "ws-daemon", Desired: 3, Ready: 3, Healthy: true},
+		{Kind: "StatefulSet", Name: "messagebus", Message: "not found"},
+	}, status.Workloads)
+	require.False(t, status.healthy())
+
+	status, err = installStatus(context.Background(), client, "gitpod", []byte(installedCfg))
+	require.NoError(t, err)
+	require.Empty(t, status.ConfigDrift)
+
+	status, err = installStatus(context.Background(), client, "gitpod", []byte("apiVersion: v1\ndomain: gitpod.example.org\n"))
+	require.NoError(t, err)
+	require.Contains(t, status.ConfigDrift, "gitpod.example.org")
+
+	_, err = installStatus(context.Background(), client, "default", nil)
+	require.ErrorContains(t, err, "there is no installation in namespace default")
+}
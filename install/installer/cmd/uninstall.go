@@ -0,0 +1,306 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gitpod-io/gitpod/common-go/log"
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+// installationConfigMap is the config map that lists the objects of an installation
+const installationConfigMap = "gitpod-app"
+
+var (
+	gvrConfigMaps  = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	gvrVolumeClaim = schema.GroupVersionResource{Version: "v1", Resource: "persistentvolumeclaims"}
+)
+
+var webhookKinds = map[string]struct{}{
+	"ValidatingWebhookConfiguration": {},
+	"MutatingWebhookConfiguration":   {},
+}
+
+var uninstallOpts struct {
+	Kube             kubeConfig
+	DeleteVolumes    bool
+	DeleteCRDs       bool
+	DeleteWebhooks   bool
+	RemoveFinalizers bool
+	DryRun           bool
+}
+
+// uninstallCmd represents the uninstall command
+var uninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Removes an installation from the cluster",
+	Long: `Removes an installation from the cluster
+
+The objects of the installation are read from the gitpod-app config map in the
+namespace and deleted in the reverse order they are installed in, so the
+workloads are gone before the roles and secrets they use. The config map is
+deleted last, so an interrupted uninstall can be run again.
+
+Persistent volume claims, custom resource definitions and webhook
+configurations are kept unless their flags are set. Objects that are left
+terminating because the controller that would remove their finalizers has
+already been deleted can have the finalizers removed with --remove-finalizers.`,
+	Example: `  # Remove everything, including the data of the in-cluster database and storage.
+  gitpod-installer uninstall --namespace gitpod --delete-volumes --delete-crds --delete-webhooks --remove-finalizers`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, mapper, err := dynamicClientFromKubeConfig(&uninstallOpts.Kube)
+		if err != nil {
+			return err
+		}
+
+		ctx := context.Background()
+		objs, err := installedObjects(ctx, client, renderOpts.Namespace)
+		if err != nil {
+			return err
+		}
+
+		u := &uninstaller{client: client, mapper: mapper}
+		for _, obj := range uninstallOrder(objs) {
+			if err := u.uninstall(ctx, obj); err != nil {
+				return err
+			}
+		}
+		if uninstallOpts.DeleteVolumes {
+			if err := u.deleteVolumeClaims(ctx, renderOpts.Namespace); err != nil {
+				return err
+			}
+		}
+
+		// The config map goes last, it is needed to run the uninstall again
+		cfgMap, err := client.Resource(gvrConfigMaps).Namespace(renderOpts.Namespace).Get(ctx, installationConfigMap, metav1.GetOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		if err == nil {
+			if err := u.delete(ctx, client.Resource(gvrConfigMaps).Namespace(renderOpts.Namespace), cfgMap); err != nil {
+				return err
+			}
+		}
+
+		log.Infof("%d objects deleted, %d kept", u.deleted, u.kept)
+		return nil
+	},
+}
+
+// installedObjects returns the objects listed in the installation's config map
+func installedObjects(ctx context.Context, client dynamic.Interface, namespace string) ([]common.RuntimeObject, error) {
+	cfgMap, err := client.Resource(gvrConfigMaps).Namespace(namespace).Get(ctx, installationConfigMap, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, fmt.Errorf("there is no installation in namespace %s - the %s config map does not exist", namespace, installationConfigMap)
+	} else if err != nil {
+		return nil, err
+	}
+
+	app, _, err := unstructured.NestedString(cfgMap.Object, "data", "app.yaml")
+	if err != nil {
+		return nil, err
+	}
+
+	return common.YamlToRuntimeObject([]string{app})
+}
+
+// uninstallOrder sorts the objects in the reverse order of their installation. The webhooks go
+// first, as they would otherwise block the deletion of the objects they inspect once the
+// services they call are gone, and the installation's config map is left out.
+func uninstallOrder(objs []common.RuntimeObject) []common.RuntimeObject {
+	sorted, _ := common.DependencySortingRenderFunc(append([]common.RuntimeObject{}, objs...))
+
+	var webhooks, res []common.RuntimeObject
+	for i := len(sorted) - 1; i >= 0; i-- {
+		obj := sorted[i]
+		if obj.Kind == common.TypeMetaConfigmap.Kind && obj.Metadata.Name == installationConfigMap {
+			continue
+		}
+		if _, ok := webhookKinds[obj.Kind]; ok {
+			webhooks = append(webhooks, obj)
+			continue
+		}
+		res = append(res, obj)
+	}
+
+	return append(webhooks, res...)
+}
+
+type uninstaller struct {
+	client dynamic.Interface
+	mapper meta.RESTMapper
+
+	// volumeClaims are the prefixes of the names of the claims created for the stateful sets
+	volumeClaims []string
+
+	deleted int
+	kept    int
+}
+
+func (u *uninstaller) uninstall(ctx context.Context, obj common.RuntimeObject) error {
+	var target unstructured.Unstructured
+	target.SetAPIVersion(obj.APIVersion)
+	target.SetKind(obj.Kind)
+	target.SetName(obj.Metadata.Name)
+	target.SetNamespace(obj.Metadata.Namespace)
+
+	keep := ""
+	switch _, webhook := webhookKinds[obj.Kind]; {
+	case obj.Kind == "PersistentVolumeClaim" && !uninstallOpts.DeleteVolumes:
+		keep = "--delete-volumes"
+	case obj.Kind == "CustomResourceDefinition" && !uninstallOpts.DeleteCRDs:
+		keep = "--delete-crds"
+	case webhook && !uninstallOpts.DeleteWebhooks:
+		keep = "--delete-webhooks"
+	}
+	if keep != "" {
+		log.Infof("keeping %s %s, set %s to delete it", obj.Kind, obj.Metadata.Name, keep)
+		u.kept++
+		return nil
+	}
+
+	resource, _, err := resourceForObject(u.client, u.mapper, &target)
+	if meta.IsNoMatchError(err) {
+		// The kind is gone with its custom resource definition
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	live, err := resource.Get(ctx, target.GetName(), metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("cannot get %s %s: %w", obj.Kind, obj.Metadata.Name, err)
+	}
+
+	switch obj.Kind {
+	case common.TypeMetaStatefulSet.Kind:
+		u.addVolumeClaims(live)
+	case "CustomResourceDefinition":
+		// Custom resources with finalizers would keep the definition terminating forever
+		if err := u.deleteCustomResources(ctx, live); err != nil {
+			return err
+		}
+	}
+
+	return u.delete(ctx, resource, live)
+}
+
+func (u *uninstaller) delete(ctx context.Context, resource dynamic.ResourceInterface, obj *unstructured.Unstructured) error {
+	opts := metav1.DeleteOptions{}
+	if uninstallOpts.DryRun {
+		opts.DryRun = []string{metav1.DryRunAll}
+	}
+	err := resource.Delete(ctx, obj.GetName(), opts)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("cannot delete %s %s: %w", obj.GetKind(), obj.GetName(), err)
+	}
+	log.Infof("deleted %s %s", obj.GetKind(), obj.GetName())
+	u.deleted++
+
+	if uninstallOpts.DryRun || !uninstallOpts.RemoveFinalizers || len(obj.GetFinalizers()) == 0 {
+		return nil
+	}
+
+	// Once the object is marked for deletion, nothing is lost by not waiting for its finalizers
+	_, err = resource.Patch(ctx, obj.GetName(), types.MergePatchType, []byte(`{"metadata":{"finalizers":null}}`), metav1.PatchOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("cannot remove the finalizers of %s %s: %w", obj.GetKind(), obj.GetName(), err)
+	}
+	return nil
+}
+
+// deleteCustomResources deletes the custom resources of a custom resource definition in all namespaces
+func (u *uninstaller) deleteCustomResources(ctx context.Context, crd *unstructured.Unstructured) error {
+	group, _, _ := unstructured.NestedString(crd.Object, "spec", "group")
+	plural, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "plural")
+	versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+
+	for _, v := range versions {
+		version, ok := v.(map[string]interface{})
+		if !ok || version["storage"] != true {
+			continue
+		}
+
+		resource := u.client.Resource(schema.GroupVersionResource{Group: group, Version: fmt.Sprint(version["name"]), Resource: plural})
+		list, err := resource.List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("cannot list the %s.%s: %w", plural, group, err)
+		}
+		for i := range list.Items {
+			item := &list.Items[i]
+			if err := u.delete(ctx, resource.Namespace(item.GetNamespace()), item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (u *uninstaller) addVolumeClaims(sts *unstructured.Unstructured) {
+	templates, _, _ := unstructured.NestedSlice(sts.Object, "spec", "volumeClaimTemplates")
+	for _, t := range templates {
+		template, ok := t.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(template, "metadata", "name")
+		u.volumeClaims = append(u.volumeClaims, fmt.Sprintf("%s-%s-", name, sts.GetName()))
+	}
+}
+
+// deleteVolumeClaims deletes the claims the stateful sets have created, which are not removed with them
+func (u *uninstaller) deleteVolumeClaims(ctx context.Context, namespace string) error {
+	if len(u.volumeClaims) == 0 {
+		return nil
+	}
+
+	resource := u.client.Resource(gvrVolumeClaim).Namespace(namespace)
+	list, err := resource.List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	ordinal := regexp.MustCompile(`^[0-9]+$`)
+	for i := range list.Items {
+		claim := &list.Items[i]
+		for _, prefix := range u.volumeClaims {
+			if strings.HasPrefix(claim.GetName(), prefix) && ordinal.MatchString(strings.TrimPrefix(claim.GetName(), prefix)) {
+				if err := u.delete(ctx, resource, claim); err != nil {
+					return err
+				}
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(uninstallCmd)
+
+	uninstallCmd.Flags().StringVarP(&renderOpts.Namespace, "namespace", "n", getEnvvar("NAMESPACE", "default"), "namespace Gitpod is deployed to")
+	uninstallCmd.Flags().StringVar(&uninstallOpts.Kube.Config, "kubeconfig", "", "path to the kubeconfig file")
+	uninstallCmd.Flags().BoolVar(&uninstallOpts.DeleteVolumes, "delete-volumes", false, "also delete the persistent volume claims, including those of the stateful sets - this deletes the data")
+	uninstallCmd.Flags().BoolVar(&uninstallOpts.DeleteCRDs, "delete-crds", false, "also delete the custom resource definitions and all their custom resources")
+	uninstallCmd.Flags().BoolVar(&uninstallOpts.DeleteWebhooks, "delete-webhooks", false, "also delete the validating and mutating webhook configurations")
+	uninstallCmd.Flags().BoolVar(&uninstallOpts.RemoveFinalizers, "remove-finalizers", false, "remove the finalizers of the deleted objects so they do not wait for controllers that are gone")
+	uninstallCmd.Flags().BoolVar(&uninstallOpts.DryRun, "dry-run", false, "only report what would be deleted")
+}
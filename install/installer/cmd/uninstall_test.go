@@ -0,0 +1,38 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+)
+
+func TestUninstallOrder(t *testing.T) {
+	object := func(kind, name string) common.RuntimeObject {
+		return common.RuntimeObject{
+			TypeMeta: metav1.TypeMeta{Kind: kind},
+			Metadata: metav1.ObjectMeta{Name: name},
+		}
+	}
+
+	objs := []common.RuntimeObject{
+		object("ServiceAccount", "server"),
+		object("ConfigMap", installationConfigMap),
+		object("Deployment", "server"),
+		object("ValidatingWebhookConfiguration", "workspaces"),
+		object("CustomResourceDefinition", "workspaces.workspace.gitpod.io"),
+		object("Service", "server"),
+	}
+
+	var order []string
+	for _, obj := range uninstallOrder(objs) {
+		order = append(order, obj.Kind)
+	}
+	require.Equal(t, []string{"ValidatingWebhookConfiguration", "Deployment", "Service", "CustomResourceDefinition", "ServiceAccount"}, order)
+}
@@ -20,15 +20,20 @@ import (
 )
 
 var validateClusterOpts struct {
-	Kube      kubeConfig
-	Namespace string
-	Config    string
+	Kube       kubeConfig
+	Namespace  string
+	Config     string
+	ProbeImage string
 }
 
 // validateClusterCmd represents the cluster command
 var validateClusterCmd = &cobra.Command{
 	Use:   "cluster",
 	Short: "Validate the cluster setup",
+	Long: `Validate the cluster setup
+
+The result is printed as JSON. Some checks inspect the nodes by running a
+short-lived pod on each of them in the target namespace.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if err := checkKubeConfig(&validateClusterOpts.Kube); err != nil {
 			return err
@@ -43,13 +48,15 @@ var validateClusterCmd = &cobra.Command{
 			return err
 		}
 
-		result, err := cluster.ClusterChecks.Validate(context.Background(), res, validateClusterOpts.Namespace)
+		ctx := cluster.WithNodeProbeImage(context.Background(), validateClusterOpts.ProbeImage)
+
+		result, err := cluster.ClusterChecks.Validate(ctx, res, validateClusterOpts.Namespace)
 		if err != nil {
 			return err
 		}
 
 		if validateClusterOpts.Config != "" {
-			res, err := runClusterConfigValidation(context.Background(), res, validateClusterOpts.Namespace)
+			res, err := runClusterConfigValidation(ctx, res, validateClusterOpts.Namespace)
 			if err != nil {
 				return err
 			}
@@ -90,7 +97,7 @@ var validateClusterCmd = &cobra.Command{
 }
 
 func runClusterConfigValidation(ctx context.Context, restConfig *rest.Config, namespace string) (*cluster.ValidationResult, error) {
-	_, version, cfg, err := loadConfig(validateClusterOpts.Config)
+	_, version, cfg, err := loadConfig(validateClusterOpts.Config, namespace)
 	if err != nil {
 		return nil, err
 	}
@@ -111,5 +118,6 @@ func init() {
 
 	validateClusterCmd.PersistentFlags().StringVar(&validateClusterOpts.Kube.Config, "kubeconfig", "", "path to the kubeconfig file")
 	validateClusterCmd.PersistentFlags().StringVarP(&validateClusterOpts.Config, "config", "c", getEnvvar("GITPOD_INSTALLER_CONFIG", filepath.Join(dir, "gitpod.config.yaml")), "path to the config file")
+	validateClusterCmd.PersistentFlags().StringVar(&validateClusterOpts.ProbeImage, "probe-image", cluster.NodeProbeImageDefault, "image used to inspect the nodes, it must provide a POSIX shell")
 	validateClusterCmd.PersistentFlags().StringVarP(&validateClusterOpts.Namespace, "namespace", "n", getEnvvar("NAMESPACE", "default"), "namespace to deploy to")
 }
@@ -5,33 +5,51 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 
 	"github.com/gitpod-io/gitpod/common-go/log"
+	"github.com/gitpod-io/gitpod/installer/pkg/cluster"
 	"github.com/gitpod-io/gitpod/installer/pkg/config"
 	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
 )
 
 var validateConfigOpts struct {
-	Config string
+	Config        string
+	Namespace     string
+	Compatibility compatibilityOpts
+	Cluster       bool
 }
 
 // validateConfigCmd represents the cluster command
 var validateConfigCmd = &cobra.Command{
 	Use:   "config",
 	Short: "Validate the deployment configuration",
+	Long: `Validate the deployment configuration
+
+With --cluster, the secrets the config references, such as the certificates
+and the credentials of the storage, registry and database, are read from the
+namespace to check that they exist and contain the keys the components expect.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if validateConfigOpts.Config == "" {
 			log.Fatal("missing --config")
 		}
-		_, cfgVersion, cfg, err := loadConfig(validateConfigOpts.Config)
+		_, cfgVersion, cfg, err := loadConfig(validateConfigOpts.Config, validateConfigOpts.Namespace)
 		if err != nil {
 			return err
 		}
 
-		if err = runConfigValidation(cfgVersion, cfg); err != nil {
+		var checks []func(res *config.ValidationResult) error
+		if validateConfigOpts.Compatibility.enabled() {
+			checks = append(checks, validateCompatibility(cfgVersion))
+		}
+		if validateConfigOpts.Cluster {
+			checks = append(checks, validateSecrets(cfgVersion, cfg))
+		}
+		if err = runConfigValidation(cfgVersion, cfg, checks...); err != nil {
 			return err
 		}
 
@@ -39,9 +57,68 @@ var validateConfigCmd = &cobra.Command{
 	},
 }
 
+// validateCompatibility adds the warnings about the upgrade from the installed version to the
+// result, a blocked upgrade makes the config invalid
+func validateCompatibility(cfgVersion string) func(res *config.ValidationResult) error {
+	return func(res *config.ValidationResult) error {
+		versionMF, err := getVersionManifest()
+		if err != nil {
+			return err
+		}
+		warnings, err := checkCompatibility(&validateConfigOpts.Compatibility, validateConfigOpts.Namespace, versionMF.Version, cfgVersion)
+		res.Warnings = append(res.Warnings, warnings...)
+		if err != nil {
+			res.Fatal = append(res.Fatal, err.Error())
+			res.Valid = false
+		}
+		return nil
+	}
+}
+
+// validateSecrets adds the errors of the secrets the config references to the result, a missing
+// secret or key makes the config invalid
+func validateSecrets(cfgVersion string, cfg interface{}) func(res *config.ValidationResult) error {
+	return func(res *config.ValidationResult) error {
+		apiVersion, err := config.LoadConfigVersion(cfgVersion)
+		if err != nil {
+			return err
+		}
+
+		// a copy, so that the default kubeconfig does not enable the compatibility check
+		kube := validateConfigOpts.Compatibility.Kube
+		if err := checkKubeConfig(&kube); err != nil {
+			return err
+		}
+		restConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+			&clientcmd.ClientConfigLoadingRules{ExplicitPath: kube.Config},
+			&clientcmd.ConfigOverrides{},
+		).ClientConfig()
+		if err != nil {
+			return err
+		}
+
+		result, err := apiVersion.ClusterValidation(cfg).Secrets().Validate(context.Background(), restConfig, validateConfigOpts.Namespace)
+		if err != nil {
+			return err
+		}
+		for _, item := range result.Items {
+			for _, e := range item.Errors {
+				if e.Type == cluster.ValidationStatusError {
+					res.Fatal = append(res.Fatal, e.Message)
+					res.Valid = false
+				} else {
+					res.Warnings = append(res.Warnings, e.Message)
+				}
+			}
+		}
+		return nil
+	}
+}
+
 // runConfigValidation this will run the validation and print any validation errors
-// It's silent if everything is fine
-func runConfigValidation(version string, cfg interface{}) error {
+// It's silent if everything is fine. The checks add the results of the validations that need
+// more than the config.
+func runConfigValidation(version string, cfg interface{}, checks ...func(res *config.ValidationResult) error) error {
 	apiVersion, err := config.LoadConfigVersion(version)
 	if err != nil {
 		return err
@@ -51,6 +128,11 @@ func runConfigValidation(version string, cfg interface{}) error {
 	if err != nil {
 		return err
 	}
+	for _, check := range checks {
+		if err := check(res); err != nil {
+			return err
+		}
+	}
 	res.Marshal(os.Stdout)
 	if len(res.Fatal) > 0 {
 		return fmt.Errorf("configuration invalid")
@@ -67,5 +149,9 @@ func init() {
 		log.WithError(err).Fatal("Failed to get working directory")
 	}
 
+	validateConfigCmd.Flags().StringVarP(&validateConfigOpts.Namespace, "namespace", "n", getEnvvar("NAMESPACE", "default"), "namespace Gitpod is deployed to")
+	validateConfigCmd.Flags().StringVar(&validateConfigOpts.Compatibility.Kube.Config, "kubeconfig", "", "path to the kubeconfig of the cluster to check the upgrade from the installed version and the secrets against")
+	validateConfigCmd.Flags().BoolVar(&validateConfigOpts.Cluster, "cluster", false, "check that the secrets the config references exist in the namespace and contain the expected keys")
+	validateConfigCmd.Flags().StringVar(&validateConfigOpts.Compatibility.InstalledVersion, "installed-version", "", "version of the installation to check the upgrade from, instead of reading it from the cluster")
 	validateCmd.PersistentFlags().StringVarP(&validateConfigOpts.Config, "config", "c", getEnvvar("GITPOD_INSTALLER_CONFIG", filepath.Join(dir, "gitpod.config.yaml")), "path to the config file")
 }
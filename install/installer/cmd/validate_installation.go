@@ -0,0 +1,150 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gitpod-io/gitpod/common-go/log"
+	"github.com/gitpod-io/gitpod/installer/pkg/cluster"
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	configv1 "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/utils/pointer"
+)
+
+var validateInstallationOpts struct {
+	Kube      kubeConfig
+	Namespace string
+	Config    string
+}
+
+// validateInstallationCmd represents the installation command
+var validateInstallationCmd = &cobra.Command{
+	Use:   "installation",
+	Short: "Validate that an installation is up and running",
+	Long: `Validate that an installation is up and running
+
+This is run after the rendered objects are applied. It checks that the
+workloads and components are ready, connects to the database from a
+short-lived pod in the target namespace and resolves the domains of the
+installation. The result is printed as JSON.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkKubeConfig(&validateInstallationOpts.Kube); err != nil {
+			return err
+		}
+
+		_, _, cfg, err := loadConfig(validateInstallationOpts.Config, validateInstallationOpts.Namespace)
+		if err != nil {
+			return err
+		}
+		versionMF, err := getVersionManifest()
+		if err != nil {
+			return err
+		}
+		renderCtx, err := newRenderContext(cfg, versionMF, validateInstallationOpts.Namespace)
+		if err != nil {
+			return err
+		}
+
+		clientcfg := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+			&clientcmd.ClientConfigLoadingRules{ExplicitPath: validateInstallationOpts.Kube.Config},
+			&clientcmd.ConfigOverrides{},
+		)
+		res, err := clientcfg.ClientConfig()
+		if err != nil {
+			return err
+		}
+
+		result, err := installationChecks(renderCtx).Validate(context.Background(), res, validateInstallationOpts.Namespace)
+		if err != nil {
+			return err
+		}
+
+		jsonOut, err := common.ToJSONString(result)
+		if err != nil {
+			return err
+		}
+		out := fmt.Sprintf("%s\n", string(jsonOut))
+
+		if result.Status == cluster.ValidationStatusError {
+			// Warnings are treated as valid
+			_, err := fmt.Fprintln(os.Stderr, out)
+			if err != nil {
+				return err
+			}
+			os.Exit(1)
+		}
+
+		fmt.Printf(out)
+		return nil
+	},
+}
+
+// installationChecks are the checks against an installation of the config
+func installationChecks(ctx *common.RenderContext) cluster.ValidationChecks {
+	wsSuffix := ""
+	if ctx.Config.Metadata.InstallationShortname != "" && ctx.Config.Metadata.InstallationShortname != configv1.InstallationShortNameOldDefault {
+		wsSuffix = "-" + ctx.Config.Metadata.InstallationShortname
+	}
+
+	// The subdomains are covered by wildcard records, any name is as good as another
+	hosts := []string{
+		ctx.Config.Domain,
+		fmt.Sprintf("installer-check.%s", ctx.Config.Domain),
+		fmt.Sprintf("installer-check.ws%s.%s", wsSuffix, ctx.Config.Domain),
+	}
+	if ctx.Config.PublicAPIEnabled() && !ctx.Config.PublicAPIHostnameCovered() {
+		hosts = append(hosts, ctx.Config.PublicAPIHostname())
+	}
+
+	database := common.DatabaseWaiterContainer(ctx)
+	database.Args = append(database.Args, "--timeout=1m")
+
+	return cluster.ValidationChecks{
+		{
+			Name:        "Workloads ready",
+			Description: "all deployments, stateful sets and daemon sets have their pods ready",
+			Check:       cluster.CheckWorkloadsReady,
+		},
+		{
+			Name:        "Components ready",
+			Description: "the readiness endpoint of every component responds",
+			Check:       cluster.CheckComponentsReady,
+		},
+		{
+			Name:        "Database connection",
+			Description: "a pod in the namespace connects to the database",
+			Check: cluster.CheckPodSucceeds("database", corev1.PodSpec{
+				AutomountServiceAccountToken:  pointer.Bool(false),
+				TerminationGracePeriodSeconds: pointer.Int64(0),
+				Containers:                    []corev1.Container{*database},
+			}),
+		},
+		{
+			Name:        "Domains resolve",
+			Description: "the domain and its wildcard subdomains resolve",
+			Check:       cluster.CheckHostsResolve(hosts...),
+		},
+	}
+}
+
+func init() {
+	validateCmd.AddCommand(validateInstallationCmd)
+
+	dir, err := os.Getwd()
+	if err != nil {
+		log.WithError(err).Fatal("Failed to get working directory")
+	}
+
+	validateInstallationCmd.PersistentFlags().StringVar(&validateInstallationOpts.Kube.Config, "kubeconfig", "", "path to the kubeconfig file")
+	validateInstallationCmd.PersistentFlags().StringVarP(&validateInstallationOpts.Config, "config", "c", getEnvvar("GITPOD_INSTALLER_CONFIG", filepath.Join(dir, "gitpod.config.yaml")), "path to the config file")
+	validateInstallationCmd.PersistentFlags().StringVarP(&validateInstallationOpts.Namespace, "namespace", "n", getEnvvar("NAMESPACE", "default"), "namespace Gitpod is deployed to")
+}
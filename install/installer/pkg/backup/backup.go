@@ -0,0 +1,270 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/scrypt"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// ManifestFile is the file in the archive that describes the backup
+	ManifestFile = "gitpod-backup.json"
+	// ConfigFile is the file in the archive with the installer config
+	ConfigFile = "config.yaml"
+
+	secretsDir = "secrets"
+
+	// maxArchiveSize limits the archives that are read into memory, the secrets of an
+	// installation are a few kilobytes
+	maxArchiveSize = 64 << 20
+)
+
+const (
+	// SecretGenerated is a secret the installer renders
+	SecretGenerated = "generated"
+	// SecretAuthProvider is a secret with an auth provider that the config references
+	SecretAuthProvider = "authProvider"
+)
+
+// magic starts every archive, the digit is the version of the format
+var magic = []byte("GITPOD-BACKUP-1\n")
+
+const (
+	saltSize = 16
+	keySize  = 32
+)
+
+// The parameters of the key derivation, as they are recommended for interactive logins
+const (
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// Manifest describes the contents of the archive
+type Manifest struct {
+	// Version is the installed version of Gitpod
+	Version   string    `json:"version"`
+	Namespace string    `json:"namespace"`
+	Created   time.Time `json:"created"`
+	Secrets   []Secret  `json:"secrets"`
+}
+
+// Secret is a secret in the archive
+type Secret struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Kind is SecretGenerated or SecretAuthProvider
+	Kind string `json:"kind"`
+}
+
+// Archive is the installer config and the secrets of an installation
+type Archive struct {
+	Manifest Manifest
+	Config   []byte
+	// Secrets are in the order of the manifest's secrets
+	Secrets []*unstructured.Unstructured
+}
+
+// Write encrypts the archive with a key derived from the passphrase and writes it
+func Write(w io.Writer, passphrase []byte, a *Archive) error {
+	if len(passphrase) == 0 {
+		return fmt.Errorf("the passphrase must not be empty")
+	}
+	if len(a.Secrets) != len(a.Manifest.Secrets) {
+		return fmt.Errorf("the manifest lists %d secrets, the archive has %d", len(a.Manifest.Secrets), len(a.Secrets))
+	}
+
+	var plain bytes.Buffer
+	gz := gzip.NewWriter(&plain)
+	tw := tar.NewWriter(gz)
+
+	manifest, err := json.MarshalIndent(a.Manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFile(tw, ManifestFile, manifest, a.Manifest.Created); err != nil {
+		return err
+	}
+	if err := writeFile(tw, ConfigFile, a.Config, a.Manifest.Created); err != nil {
+		return err
+	}
+	for i, secret := range a.Secrets {
+		fc, err := yaml.Marshal(secret.Object)
+		if err != nil {
+			return err
+		}
+		if err := writeFile(tw, secretFile(a.Manifest.Secrets[i]), fc, a.Manifest.Created); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return err
+	}
+	aead, err := newAEAD(passphrase, salt)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+
+	header := append(append(append([]byte{}, magic...), salt...), nonce...)
+	// the header is authenticated, so that the salt cannot be changed
+	sealed := aead.Seal(nil, nonce, plain.Bytes(), header)
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	_, err = w.Write(sealed)
+	return err
+}
+
+// Read decrypts the archive with the passphrase it was written with
+func Read(r io.Reader, passphrase []byte) (*Archive, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxArchiveSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxArchiveSize {
+		return nil, fmt.Errorf("the archive is larger than %d bytes", maxArchiveSize)
+	}
+	if !bytes.HasPrefix(data, magic) {
+		return nil, fmt.Errorf("not a backup of the installer")
+	}
+	if len(data) < len(magic)+saltSize {
+		return nil, fmt.Errorf("the archive is truncated")
+	}
+
+	salt := data[len(magic) : len(magic)+saltSize]
+	aead, err := newAEAD(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	headerSize := len(magic) + saltSize + aead.NonceSize()
+	if len(data) < headerSize {
+		return nil, fmt.Errorf("the archive is truncated")
+	}
+	plain, err := aead.Open(nil, data[len(magic)+saltSize:headerSize], data[headerSize:], data[:headerSize])
+	if err != nil {
+		return nil, fmt.Errorf("cannot decrypt the archive, the passphrase is wrong or the archive is damaged")
+	}
+
+	files, err := readFiles(plain)
+	if err != nil {
+		return nil, err
+	}
+
+	var res Archive
+	fc, ok := files[ManifestFile]
+	if !ok {
+		return nil, fmt.Errorf("the archive has no %s", ManifestFile)
+	}
+	if err := json.Unmarshal(fc, &res.Manifest); err != nil {
+		return nil, fmt.Errorf("cannot read %s: %w", ManifestFile, err)
+	}
+	res.Config, ok = files[ConfigFile]
+	if !ok {
+		return nil, fmt.Errorf("the archive has no %s", ConfigFile)
+	}
+	for _, s := range res.Manifest.Secrets {
+		fn := secretFile(s)
+		fc, ok := files[fn]
+		if !ok {
+			return nil, fmt.Errorf("the archive has no %s", fn)
+		}
+		var obj map[string]interface{}
+		if err := yaml.Unmarshal(fc, &obj); err != nil {
+			return nil, fmt.Errorf("cannot read %s: %w", fn, err)
+		}
+		res.Secrets = append(res.Secrets, &unstructured.Unstructured{Object: obj})
+	}
+
+	return &res, nil
+}
+
+func newAEAD(passphrase, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(passphrase, salt, scryptN, scryptR, scryptP, keySize)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func secretFile(s Secret) string {
+	return path.Join(secretsDir, s.Namespace, s.Name+".yaml")
+}
+
+func writeFile(tw *tar.Writer, name string, content []byte, modTime time.Time) error {
+	err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     int64(len(content)),
+		Mode:     0600,
+		ModTime:  modTime,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = tw.Write(content)
+	return err
+}
+
+func readFiles(archive []byte) (map[string][]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	res := make(map[string][]byte)
+	tr := tar.NewReader(io.LimitReader(gz, maxArchiveSize))
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg || strings.Contains(hdr.Name, "..") {
+			continue
+		}
+		fc, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		res[hdr.Name] = fc
+	}
+	return res, nil
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package backup
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestWriteRead(t *testing.T) {
+	archive := &Archive{
+		Manifest: Manifest{
+			Version:   "2023.3.0",
+			Namespace: "gitpod",
+			Created:   time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC),
+			Secrets:   []Secret{{Namespace: "gitpod", Name: "server-session", Kind: SecretGenerated}},
+		},
+		Config: []byte("apiVersion: v1\ndomain: gitpod.example.com\n"),
+		Secrets: []*unstructured.Unstructured{{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata":   map[string]interface{}{"name": "server-session", "namespace": "gitpod"},
+			"data":       map[string]interface{}{"secret": "c2VjcmV0"},
+		}}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, []byte("passphrase"), archive))
+	require.NotContains(t, buf.String(), "gitpod.example.com")
+
+	res, err := Read(bytes.NewReader(buf.Bytes()), []byte("passphrase"))
+	require.NoError(t, err)
+	require.Equal(t, archive, res)
+
+	_, err = Read(bytes.NewReader(buf.Bytes()), []byte("wrong"))
+	require.ErrorContains(t, err, "cannot decrypt")
+
+	damaged := append([]byte{}, buf.Bytes()...)
+	damaged[len(magic)] ^= 1
+	_, err = Read(bytes.NewReader(damaged), []byte("passphrase"))
+	require.ErrorContains(t, err, "cannot decrypt")
+
+	_, err = Read(bytes.NewReader([]byte("apiVersion: v1")), []byte("passphrase"))
+	require.ErrorContains(t, err, "not a backup")
+}
@@ -6,12 +6,16 @@ package cluster
 
 import (
 	"context"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
 	"net"
 	"net/netip"
+	"path"
 	"strings"
 
 	"github.com/Masterminds/semver"
+	"github.com/gitpod-io/gitpod/installer/pkg/containerd"
 	certmanager "github.com/jetstack/cert-manager/pkg/client/clientset/versioned"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -163,6 +167,56 @@ func CheckSecretRule(validator func(*corev1.Secret) ([]ValidationError, error))
 	}
 }
 
+// CheckSecretCertificateNames checks that the certificate in the tls.crt entry is valid for the
+// DNS names. A wildcard name must be covered by a wildcard certificate name.
+func CheckSecretCertificateNames(names ...string) CheckSecretOpt {
+	return CheckSecretRule(func(s *corev1.Secret) ([]ValidationError, error) {
+		data, ok := s.Data["tls.crt"]
+		if !ok {
+			return nil, nil
+		}
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return []ValidationError{{
+				Message: fmt.Sprintf("secret %s has no PEM encoded certificate in tls.crt", s.Name),
+				Type:    ValidationStatusError,
+			}}, nil
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return []ValidationError{{
+				Message: fmt.Sprintf("cannot parse the certificate of secret %s: %v", s.Name, err),
+				Type:    ValidationStatusError,
+			}}, nil
+		}
+
+		var res []ValidationError
+		for _, name := range names {
+			if certificateCoversName(cert, name) {
+				continue
+			}
+			res = append(res, ValidationError{
+				Message: fmt.Sprintf("certificate of secret %s is not valid for %s", s.Name, name),
+				Type:    ValidationStatusError,
+			})
+		}
+		return res, nil
+	})
+}
+
+func certificateCoversName(cert *x509.Certificate, name string) bool {
+	if !strings.HasPrefix(name, "*.") {
+		return cert.VerifyHostname(name) == nil
+	}
+
+	for _, n := range cert.DNSNames {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
 // CheckSecret produces a new check for an in-cluster secret
 func CheckSecret(name string, opts ...CheckSecretOpt) ValidationCheck {
 	var cfg checkSecretOpts
@@ -173,6 +227,7 @@ func CheckSecret(name string, opts ...CheckSecretOpt) ValidationCheck {
 	return ValidationCheck{
 		Name:        name + " is present and valid",
 		Description: "ensures the " + name + " secret is present and contains the required data",
+		Secret:      name,
 		Check: func(ctx context.Context, config *rest.Config, namespace string) ([]ValidationError, error) {
 			client, err := clientsetFromContext(ctx, config)
 			if err != nil {
@@ -354,3 +409,238 @@ func CheckWorkspaceCIDR(networkCIDR string) ValidationCheck {
 		},
 	}
 }
+
+// checkCgroupV2 checks the nodes use the unified cgroup hierarchy
+func checkCgroupV2(ctx context.Context, config *rest.Config, namespace string) ([]ValidationError, error) {
+	nodes, err := ListNodesFromContext(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := runNodeProbe(ctx, config, namespace, nodes, NodeProbe{
+		Name:      "cgroup",
+		Script:    "stat -fc %T /host/sys/fs/cgroup",
+		HostPaths: []string{"/sys/fs/cgroup"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := probeErrors("cgroup", results)
+	for _, r := range results {
+		if r.Err != nil {
+			continue
+		}
+		if r.Output != "cgroup2fs" {
+			res = append(res, ValidationError{
+				Message: "cgroup v2 is not enabled on node: " + r.Node + ", /sys/fs/cgroup is " + r.Output,
+				Type:    ValidationStatusWarning,
+			})
+		}
+	}
+
+	return res, nil
+}
+
+// checkDefaultStorageClass checks there is a default storage class for the persistent volume claims
+func checkDefaultStorageClass(ctx context.Context, config *rest.Config, namespace string) ([]ValidationError, error) {
+	client, err := clientsetFromContext(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	storageClasses, err := client.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var defaults []string
+	for _, sc := range storageClasses.Items {
+		for _, annotation := range []string{"storageclass.kubernetes.io/is-default-class", "storageclass.beta.kubernetes.io/is-default-class"} {
+			if sc.Annotations[annotation] == "true" {
+				defaults = append(defaults, sc.Name)
+				break
+			}
+		}
+	}
+
+	switch len(defaults) {
+	case 0:
+		// Treat as warning - the in-cluster dependencies may not be used
+		return []ValidationError{
+			{
+				Message: "no default storage class configured",
+				Type:    ValidationStatusWarning,
+			},
+		}, nil
+	case 1:
+		return nil, nil
+	default:
+		return []ValidationError{
+			{
+				Message: "multiple default storage classes configured: " + strings.Join(defaults, ", "),
+				Type:    ValidationStatusWarning,
+			},
+		}, nil
+	}
+}
+
+// networkPolicyCNIs are the daemon sets of network plugins that enforce network policies
+var networkPolicyCNIs = []string{
+	"antrea-agent",
+	"calico-node",
+	"canal",
+	"cilium",
+	"kube-router",
+	"weave-net",
+}
+
+// checkNetworkPolicySupport checks that the network plugin enforces network policies. There
+// is no API to ask for this, so the daemon sets of the well-known network plugins are looked for.
+func checkNetworkPolicySupport(ctx context.Context, config *rest.Config, namespace string) ([]ValidationError, error) {
+	client, err := clientsetFromContext(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	daemonSets, err := client.AppsV1().DaemonSets(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ds := range daemonSets.Items {
+		for _, cni := range networkPolicyCNIs {
+			if ds.Name == cni {
+				return nil, nil
+			}
+		}
+	}
+
+	return []ValidationError{
+		{
+			Message: "no network plugin that enforces network policies found, supported plugins are " + strings.Join(networkPolicyCNIs, ", "),
+			Type:    ValidationStatusWarning,
+		},
+	}, nil
+}
+
+// CheckContainerdLocation checks the containerd socket and runtime directory exist on the workspace nodes
+func CheckContainerdLocation(socketDir, runtimeDir string) ValidationCheck {
+	return ValidationCheck{
+		Name:        "containerd location",
+		Description: "the containerd socket is in " + socketDir + " and the runtime directory " + runtimeDir + " exists on all workspace nodes",
+		Check: func(ctx context.Context, config *rest.Config, namespace string) ([]ValidationError, error) {
+			allNodes, err := ListNodesFromContext(ctx, config)
+			if err != nil {
+				return nil, err
+			}
+
+			var nodes []corev1.Node
+			for _, node := range allNodes {
+				_, regular := node.Labels[AffinityLabelWorkspacesRegular]
+				_, headless := node.Labels[AffinityLabelWorkspacesHeadless]
+				if regular || headless {
+					nodes = append(nodes, node)
+				}
+			}
+			if len(nodes) == 0 {
+				// Missing labels are reported by the affinity labels check
+				return nil, nil
+			}
+
+			results, err := runNodeProbe(ctx, config, namespace, nodes, NodeProbe{
+				Name:      "containerd",
+				Script:    containerdProbeScript(socketDir, runtimeDir),
+				HostPaths: []string{"/"},
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			res := probeErrors("containerd", results)
+			for i, r := range results {
+				if r.Err != nil {
+					continue
+				}
+				res = append(res, containerdLocationErrors(nodes[i], socketDir, runtimeDir, r.Output)...)
+			}
+
+			return res, nil
+		},
+	}
+}
+
+// containerdProbeScript tests the configured locations and reports which of the known ones exist
+func containerdProbeScript(socketDir, runtimeDir string) string {
+	script := fmt.Sprintf("test -S %s || echo missing-socket\n", path.Join("/host", socketDir, "containerd.sock"))
+	script += fmt.Sprintf("test -d %s || echo missing-runtime\n", path.Join("/host", runtimeDir))
+	for _, l := range containerd.SocketLocations() {
+		script += fmt.Sprintf("test -S %s && echo socket %s\n", path.Join("/host", l.String(), "containerd.sock"), l)
+	}
+	for _, l := range containerd.ContainerdLocations() {
+		script += fmt.Sprintf("test -d %s && echo runtime %s\n", path.Join("/host", l.String()), l)
+	}
+	return script + "true\n"
+}
+
+// containerdLocationErrors says where containerd is on a node whose probe did not find it where
+// the config expects it
+func containerdLocationErrors(node corev1.Node, socketDir, runtimeDir, output string) []ValidationError {
+	var missingSocket, missingRuntime bool
+	var sockets, runtimes []string
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 1 && fields[0] == "missing-socket":
+			missingSocket = true
+		case len(fields) == 1 && fields[0] == "missing-runtime":
+			missingRuntime = true
+		case len(fields) == 2 && fields[0] == "socket":
+			sockets = append(sockets, fields[1])
+		case len(fields) == 2 && fields[0] == "runtime":
+			runtimes = append(runtimes, fields[1])
+		}
+	}
+
+	nodeName := node.Name
+	var distName, distSocket, distRuntime string
+	if dist := containerd.DetectDistribution(node.Status.NodeInfo); dist != nil {
+		nodeName = fmt.Sprintf("%s (%s)", node.Name, dist.Name)
+		distName, distSocket, distRuntime = dist.Name, dist.Socket.String(), dist.Containerd.String()
+	}
+
+	var res []ValidationError
+	if missingSocket {
+		res = append(res, ValidationError{
+			Message: "containerd socket not found in " + socketDir + " on node " + nodeName + " - " + containerdLocationHint("workspace.runtime.containerdSocketDir", sockets, distName, distSocket),
+			Type:    ValidationStatusError,
+		})
+	}
+	if missingRuntime {
+		res = append(res, ValidationError{
+			Message: "containerd runtime directory " + runtimeDir + " not found on node " + nodeName + " - " + containerdLocationHint("workspace.runtime.containerdRuntimeDir", runtimes, distName, distRuntime),
+			Type:    ValidationStatusError,
+		})
+	}
+	return res
+}
+
+// containerdLocationHint tells what to set the config field to. Of the locations found on the node,
+// the one the distribution of the node usually has is preferred.
+func containerdLocationHint(field string, found []string, distribution, expected string) string {
+	for _, f := range found {
+		if f == expected {
+			return "set " + field + " to " + f
+		}
+	}
+	switch {
+	case len(found) == 1:
+		return "set " + field + " to " + found[0]
+	case len(found) > 1:
+		return "set " + field + " to one of " + strings.Join(found, ", ")
+	case distribution != "":
+		return distribution + " puts it in " + expected + ", which does not exist either - check that containerd is running and set " + field + " to where it is configured to put it"
+	default:
+		return "none of the known locations exist, set " + field + " to where containerd is configured to put it"
+	}
+}
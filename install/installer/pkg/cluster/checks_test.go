@@ -0,0 +1,170 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package cluster
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func fakeClientContext(objects ...runtime.Object) context.Context {
+	return context.WithValue(context.Background(), keyClientset, fake.NewSimpleClientset(objects...))
+}
+
+func TestCheckDefaultStorageClass(t *testing.T) {
+	storageClass := func(name string, isDefault bool) *storagev1.StorageClass {
+		sc := &storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if isDefault {
+			sc.Annotations = map[string]string{"storageclass.kubernetes.io/is-default-class": "true"}
+		}
+		return sc
+	}
+
+	tests := []struct {
+		Name     string
+		Objects  []runtime.Object
+		Expected int
+	}{
+		{Name: "no storage classes", Expected: 1},
+		{Name: "no default", Objects: []runtime.Object{storageClass("standard", false)}, Expected: 1},
+		{Name: "one default", Objects: []runtime.Object{storageClass("standard", true), storageClass("fast", false)}, Expected: 0},
+		{Name: "multiple defaults", Objects: []runtime.Object{storageClass("standard", true), storageClass("fast", true)}, Expected: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			res, err := checkDefaultStorageClass(fakeClientContext(test.Objects...), nil, "default")
+			require.NoError(t, err)
+			require.Len(t, res, test.Expected)
+		})
+	}
+}
+
+func TestCheckNetworkPolicySupport(t *testing.T) {
+	daemonSet := func(name string) *appsv1.DaemonSet {
+		return &appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "kube-system"}}
+	}
+
+	res, err := checkNetworkPolicySupport(fakeClientContext(daemonSet("kube-flannel-ds")), nil, "default")
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	require.Equal(t, ValidationStatusWarning, res[0].Type)
+
+	res, err = checkNetworkPolicySupport(fakeClientContext(daemonSet("kube-flannel-ds"), daemonSet("calico-node")), nil, "default")
+	require.NoError(t, err)
+	require.Empty(t, res)
+}
+
+func TestCheckSecretCertificateNames(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		DNSNames:     []string{"gitpod.example.com", "*.gitpod.example.com"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "https-certificates", Namespace: "default"},
+		Data: map[string][]byte{
+			"tls.crt": pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		},
+	}
+
+	tests := []struct {
+		Name     string
+		Names    []string
+		Expected int
+	}{
+		{Name: "covered names", Names: []string{"gitpod.example.com", "*.gitpod.example.com", "reg.gitpod.example.com"}, Expected: 0},
+		{Name: "other domain", Names: []string{"gitpod.example.org"}, Expected: 1},
+		{Name: "nested wildcard", Names: []string{"*.ws.gitpod.example.com"}, Expected: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			check := CheckSecret(secret.Name, CheckSecretCertificateNames(test.Names...))
+			res, err := check.Check(fakeClientContext(secret), nil, "default")
+			require.NoError(t, err)
+			require.Len(t, res, test.Expected)
+		})
+	}
+}
+
+func TestValidationChecksSecrets(t *testing.T) {
+	checks := ValidationChecks{
+		CheckSecret("https-certificates", CheckSecretRequiredData("tls.crt", "tls.key")),
+		CheckContainerdLocation("", ""),
+		CheckSecret("storage"),
+	}
+
+	var names []string
+	for _, check := range checks.Secrets() {
+		names = append(names, check.Secret)
+	}
+	require.Equal(t, []string{"https-certificates", "storage"}, names)
+}
+
+func TestContainerdLocationErrors(t *testing.T) {
+	k3s := corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status:     corev1.NodeStatus{NodeInfo: corev1.NodeSystemInfo{KubeletVersion: "v1.26.4+k3s1"}},
+	}
+
+	tests := []struct {
+		Name     string
+		Node     corev1.Node
+		Output   string
+		Expected []string
+	}{
+		{Name: "found", Node: k3s, Output: "socket /run/k3s/containerd"},
+		{
+			Name:   "found elsewhere",
+			Node:   k3s,
+			Output: "missing-socket\nmissing-runtime\nsocket /run/k3s/containerd\nruntime /run/k3s/containerd/io.containerd.runtime.v2.task/k8s.io\nruntime /var/lib/containerd/io.containerd.runtime.v2.task/k8s.io",
+			Expected: []string{
+				"containerd socket not found in /run/containerd on node node-1 (k3s) - set workspace.runtime.containerdSocketDir to /run/k3s/containerd",
+				"containerd runtime directory /var/lib/containerd/io.containerd.runtime.v2.task/k8s.io not found on node node-1 (k3s) - set workspace.runtime.containerdRuntimeDir to /run/k3s/containerd/io.containerd.runtime.v2.task/k8s.io",
+			},
+		},
+		{
+			Name:   "not found",
+			Node:   corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}},
+			Output: "missing-socket",
+			Expected: []string{
+				"containerd socket not found in /run/containerd on node node-2 - none of the known locations exist, set workspace.runtime.containerdSocketDir to where containerd is configured to put it",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			var messages []string
+			for _, err := range containerdLocationErrors(test.Node, "/run/containerd", "/var/lib/containerd/io.containerd.runtime.v2.task/k8s.io", test.Output) {
+				require.Equal(t, ValidationStatusError, err.Type)
+				messages = append(messages, err.Message)
+			}
+			require.Equal(t, test.Expected, messages)
+		})
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/utils/pointer"
+)
+
+// installationSelector selects the objects of the Gitpod installation
+var installationSelector = metav1.ListOptions{LabelSelector: "app=gitpod"}
+
+// CheckWorkloadsReady checks that every deployment, stateful set and daemon set has all its pods ready
+func CheckWorkloadsReady(ctx context.Context, config *rest.Config, namespace string) ([]ValidationError, error) {
+	client, err := clientsetFromContext(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	notReady := func(kind, name string, ready, desired int32) ValidationError {
+		return ValidationError{
+			Message: fmt.Sprintf("%s %s has %d of %d pods ready", kind, name, ready, desired),
+			Type:    ValidationStatusError,
+		}
+	}
+
+	var res []ValidationError
+	deployments, err := client.AppsV1().Deployments(namespace).List(ctx, installationSelector)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range deployments.Items {
+		desired := pointer.Int32Deref(d.Spec.Replicas, 1)
+		if d.Status.ReadyReplicas < desired {
+			res = append(res, notReady("deployment", d.Name, d.Status.ReadyReplicas, desired))
+		}
+	}
+
+	statefulSets, err := client.AppsV1().StatefulSets(namespace).List(ctx, installationSelector)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range statefulSets.Items {
+		desired := pointer.Int32Deref(s.Spec.Replicas, 1)
+		if s.Status.ReadyReplicas < desired {
+			res = append(res, notReady("statefulset", s.Name, s.Status.ReadyReplicas, desired))
+		}
+	}
+
+	daemonSets, err := client.AppsV1().DaemonSets(namespace).List(ctx, installationSelector)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range daemonSets.Items {
+		if d.Status.NumberReady < d.Status.DesiredNumberScheduled {
+			res = append(res, notReady("daemonset", d.Name, d.Status.NumberReady, d.Status.DesiredNumberScheduled))
+		}
+	}
+
+	return res, nil
+}
+
+// CheckComponentsReady calls the HTTP readiness endpoint of each running pod through the API server
+func CheckComponentsReady(ctx context.Context, config *rest.Config, namespace string) ([]ValidationError, error) {
+	client, err := clientsetFromContext(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := client.CoreV1().Pods(namespace).List(ctx, installationSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []ValidationError
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+
+		for _, container := range pod.Spec.Containers {
+			if container.ReadinessProbe == nil || container.ReadinessProbe.HTTPGet == nil {
+				continue
+			}
+			probe := container.ReadinessProbe.HTTPGet
+
+			port := probe.Port.String()
+			if probe.Port.IntValue() == 0 {
+				// The pod proxy needs the number of a named port
+				for _, p := range container.Ports {
+					if p.Name == probe.Port.StrVal {
+						port = strconv.Itoa(int(p.ContainerPort))
+					}
+				}
+			}
+
+			_, err := client.CoreV1().Pods(namespace).ProxyGet(string(probe.Scheme), pod.Name, port, probe.Path, nil).DoRaw(ctx)
+			if err != nil {
+				res = append(res, ValidationError{
+					Message: fmt.Sprintf("container %s of pod %s is not ready on %s:%s%s: %v", container.Name, pod.Name, probe.Scheme, port, probe.Path, err),
+					Type:    ValidationStatusError,
+				})
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// CheckHostsResolve checks that the DNS names resolve from where the installer runs
+func CheckHostsResolve(hosts ...string) ValidationCheckFunc {
+	return func(ctx context.Context, config *rest.Config, namespace string) ([]ValidationError, error) {
+		var res []ValidationError
+		for _, host := range hosts {
+			if _, err := net.DefaultResolver.LookupHost(ctx, host); err != nil {
+				res = append(res, ValidationError{
+					Message: fmt.Sprintf("cannot resolve %s: %v", host, err),
+					Type:    ValidationStatusError,
+				})
+			}
+		}
+		return res, nil
+	}
+}
+
+// CheckPodSucceeds runs a pod with the spec in the namespace and checks that it succeeds
+func CheckPodSucceeds(name string, spec corev1.PodSpec) ValidationCheckFunc {
+	return func(ctx context.Context, config *rest.Config, namespace string) ([]ValidationError, error) {
+		client, err := clientsetFromContext(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+
+		spec.RestartPolicy = corev1.RestartPolicyNever
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: fmt.Sprintf("installer-probe-%s-", name),
+				Namespace:    namespace,
+				Labels: map[string]string{
+					"app":       "gitpod",
+					"component": "installer-probe",
+				},
+			},
+			Spec: spec,
+		}
+
+		logs, succeeded, err := runProbePod(ctx, client, pod)
+		if err != nil {
+			return []ValidationError{{
+				Message: fmt.Sprintf("cannot run %s probe: %v", name, err),
+				Type:    ValidationStatusError,
+			}}, nil
+		}
+		if !succeeded {
+			return []ValidationError{{
+				Message: fmt.Sprintf("%s probe failed: %s", name, logs),
+				Type:    ValidationStatusError,
+			}}, nil
+		}
+		return nil, nil
+	}
+}
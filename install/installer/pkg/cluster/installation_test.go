@@ -0,0 +1,35 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestCheckWorkloadsReady(t *testing.T) {
+	deployment := func(name string, replicas, ready int32) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": "gitpod"}},
+			Spec:       appsv1.DeploymentSpec{Replicas: pointer.Int32(replicas)},
+			Status:     appsv1.DeploymentStatus{ReadyReplicas: ready},
+		}
+	}
+	daemonSet := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "ws-daemon", Namespace: "default", Labels: map[string]string{"app": "gitpod"}},
+		Status:     appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, NumberReady: 2},
+	}
+
+	res, err := CheckWorkloadsReady(fakeClientContext(deployment("server", 2, 2), deployment("proxy", 2, 1), daemonSet), nil, "default")
+	require.NoError(t, err)
+	require.Equal(t, []ValidationError{
+		{Message: "deployment proxy has 1 of 2 pods ready", Type: ValidationStatusError},
+		{Message: "daemonset ws-daemon has 2 of 3 pods ready", Type: ValidationStatusError},
+	}, res)
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	// NodeProbeImageDefault is used to run the node probes if no other image is given
+	NodeProbeImageDefault = "docker.io/library/busybox:1.36"
+
+	nodeProbeTimeout = 2 * time.Minute
+	keyProbeImage    = "nodeProbeImage"
+)
+
+// WithNodeProbeImage sets the image that is used by checks that need to run a pod on the nodes
+func WithNodeProbeImage(ctx context.Context, image string) context.Context {
+	return context.WithValue(ctx, keyProbeImage, image)
+}
+
+func nodeProbeImageFromContext(ctx context.Context) string {
+	if image, ok := ctx.Value(keyProbeImage).(string); ok && image != "" {
+		return image
+	}
+	return NodeProbeImageDefault
+}
+
+// NodeProbe is a script that is run in a pod on a node. The host paths are mounted
+// read-only into the pod at the same location under /host.
+type NodeProbe struct {
+	Name      string
+	Script    string
+	HostPaths []string
+}
+
+type nodeProbeResult struct {
+	Node   string
+	Output string
+	Err    error
+}
+
+// runNodeProbe runs the probe on every node and returns the output for each of them
+func runNodeProbe(ctx context.Context, config *rest.Config, namespace string, nodes []corev1.Node, probe NodeProbe) ([]nodeProbeResult, error) {
+	client, err := clientsetFromContext(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	var volumes []corev1.Volume
+	var mounts []corev1.VolumeMount
+	for i, path := range probe.HostPaths {
+		name := fmt.Sprintf("host-%d", i)
+		volumes = append(volumes, corev1.Volume{
+			Name:         name,
+			VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: path}},
+		})
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      name,
+			MountPath: "/host" + path,
+			ReadOnly:  true,
+		})
+	}
+
+	res := make([]nodeProbeResult, len(nodes))
+	var wg sync.WaitGroup
+	for i, node := range nodes {
+		wg.Add(1)
+		go func(i int, node string) {
+			defer wg.Done()
+
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					GenerateName: fmt.Sprintf("installer-probe-%s-", probe.Name),
+					Namespace:    namespace,
+					Labels: map[string]string{
+						"app":       "gitpod",
+						"component": "installer-probe",
+					},
+				},
+				Spec: corev1.PodSpec{
+					NodeName:                      node,
+					RestartPolicy:                 corev1.RestartPolicyNever,
+					AutomountServiceAccountToken:  pointer.Bool(false),
+					TerminationGracePeriodSeconds: pointer.Int64(0),
+					Tolerations: []corev1.Toleration{{
+						Operator: corev1.TolerationOpExists,
+					}},
+					Containers: []corev1.Container{{
+						Name:         "probe",
+						Image:        nodeProbeImageFromContext(ctx),
+						Command:      []string{"sh", "-c", probe.Script},
+						VolumeMounts: mounts,
+					}},
+					Volumes: volumes,
+				},
+			}
+
+			res[i] = nodeProbeResult{Node: node}
+			res[i].Output, _, res[i].Err = runProbePod(ctx, client, pod)
+		}(i, node.Name)
+	}
+	wg.Wait()
+
+	return res, nil
+}
+
+// runProbePod runs the pod to completion and returns its logs and whether it succeeded
+func runProbePod(ctx context.Context, client kubernetes.Interface, pod *corev1.Pod) (string, bool, error) {
+	pod, err := client.CoreV1().Pods(pod.Namespace).Create(ctx, pod, metav1.CreateOptions{})
+	if err != nil {
+		return "", false, err
+	}
+	defer func() {
+		_ = client.CoreV1().Pods(pod.Namespace).Delete(context.Background(), pod.Name, metav1.DeleteOptions{})
+	}()
+
+	var phase corev1.PodPhase
+	err = wait.PollImmediateWithContext(ctx, time.Second, nodeProbeTimeout, func(ctx context.Context) (bool, error) {
+		p, err := client.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		phase = p.Status.Phase
+		return phase == corev1.PodSucceeded || phase == corev1.PodFailed, nil
+	})
+	if err != nil {
+		return "", false, fmt.Errorf("probe pod %s did not complete: %w", pod.Name, err)
+	}
+
+	logs, err := client.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{}).Do(ctx).Raw()
+	if err != nil {
+		return "", false, err
+	}
+	return string(bytes.TrimSpace(logs)), phase == corev1.PodSucceeded, nil
+}
+
+// probeErrors turns the probe results that could not be run into warnings. The check
+// cannot be performed on those nodes, which should not stop the installation.
+func probeErrors(probe string, results []nodeProbeResult) []ValidationError {
+	var res []ValidationError
+	for _, r := range results {
+		if r.Err != nil {
+			res = append(res, ValidationError{
+				Message: fmt.Sprintf("cannot run %s probe on node %s: %s", probe, r.Node, strings.TrimSpace(r.Err.Error())),
+				Type:    ValidationStatusWarning,
+			})
+		}
+	}
+	return res
+}
@@ -34,6 +34,8 @@ type ValidationCheck struct {
 	Name        string              `json:"name"`
 	Description string              `json:"description"`
 	Check       ValidationCheckFunc `json:"-"`
+	// Secret is the name of the secret the check reads, if it checks one
+	Secret string `json:"-"`
 }
 
 type ValidationCheckFunc func(ctx context.Context, config *rest.Config, namespace string) ([]ValidationError, error)
@@ -76,6 +78,21 @@ var ClusterChecks = ValidationChecks{
 		Description: "ensure that the target namespace exists",
 		Check:       checkNamespaceExists,
 	},
+	{
+		Name:        "cgroup v2 enabled",
+		Description: "all cluster nodes use cgroup v2",
+		Check:       checkCgroupV2,
+	},
+	{
+		Name:        "default StorageClass",
+		Description: "a default StorageClass is configured for persistent volume claims",
+		Check:       checkDefaultStorageClass,
+	},
+	{
+		Name:        "NetworkPolicy support",
+		Description: "the network plugin enforces NetworkPolicies",
+		Check:       checkNetworkPolicySupport,
+	},
 }
 
 // ValidationChecks are a group of validations
@@ -83,6 +100,17 @@ type ValidationChecks []ValidationCheck
 
 func (v ValidationChecks) Len() int { return len(v) }
 
+// Secrets returns the checks of the secrets
+func (v ValidationChecks) Secrets() ValidationChecks {
+	var res ValidationChecks
+	for _, check := range v {
+		if check.Secret != "" {
+			res = append(res, check)
+		}
+	}
+	return res
+}
+
 // Validate runs the checks
 func (checks ValidationChecks) Validate(ctx context.Context, config *rest.Config, namespace string) (*ValidationResult, error) {
 	results := &ValidationResult{
@@ -5,7 +5,13 @@
 package common
 
 import (
+	"fmt"
+	"time"
+
+	certmanagerv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func CAVolume() corev1.Volume {
@@ -19,11 +25,70 @@ func CAVolume() corev1.Volume {
 	}
 }
 
+const caCertificatesPath = "/etc/ssl/certs/ca-certificates.crt"
+
 func CAVolumeMount() corev1.VolumeMount {
 	return corev1.VolumeMount{
 		Name:      "ca-certificates",
-		MountPath: "/etc/ssl/certs/ca-certificates.crt",
+		MountPath: caCertificatesPath,
 		SubPath:   "ca-certificates.crt",
 		ReadOnly:  true,
 	}
 }
+
+// NodeCAEnv makes Node.js trust the mounted CA bundle - it ships its own CAs
+// rather than using the system's
+func NodeCAEnv() []corev1.EnvVar {
+	return []corev1.EnvVar{{
+		Name:  "NODE_EXTRA_CA_CERTS",
+		Value: caCertificatesPath,
+	}}
+}
+
+// WebhookCertificate is the certificate the admission webhooks of the component are served with.
+// cert-manager renews it before it expires, and the webhook configurations with the
+// WebhookCAInjection annotations are kept trusting its CA. The component must reload the
+// certificate when its secret changes.
+func WebhookCertificate(ctx *RenderContext, component string, name string, secretName string) *certmanagerv1.Certificate {
+	duration := InternalCertDuration
+	var renewBefore *metav1.Duration
+	if cfg := ctx.Config.WebhookCertificate; cfg != nil {
+		if cfg.Duration != nil {
+			duration = &metav1.Duration{Duration: time.Duration(*cfg.Duration)}
+		}
+		if cfg.RenewBefore != nil {
+			renewBefore = &metav1.Duration{Duration: time.Duration(*cfg.RenewBefore)}
+		}
+	}
+
+	return &certmanagerv1.Certificate{
+		TypeMeta: TypeMetaCertificate,
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ctx.Namespace,
+			Labels:    DefaultLabels(component),
+		},
+		Spec: certmanagerv1.CertificateSpec{
+			Duration:    duration,
+			RenewBefore: renewBefore,
+			SecretName:  secretName,
+			DNSNames: []string{
+				fmt.Sprintf("%s.%s.svc", component, ctx.Namespace),
+				fmt.Sprintf("%s.%s.svc.cluster.local", component, ctx.Namespace),
+			},
+			IssuerRef: cmmeta.ObjectReference{
+				Name:  CertManagerCAIssuer,
+				Kind:  certmanagerv1.ClusterIssuerKind,
+				Group: "cert-manager.io",
+			},
+		},
+	}
+}
+
+// WebhookCAInjection are the annotations that have the cert-manager CA injector write the CA of
+// the certificate into a webhook configuration, again whenever the certificate is renewed
+func WebhookCAInjection(ctx *RenderContext, certificate string) map[string]string {
+	return map[string]string{
+		"cert-manager.io/inject-ca-from": fmt.Sprintf("%s/%s", ctx.Namespace, certificate),
+	}
+}
@@ -12,12 +12,14 @@ import (
 	"math/rand"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gitpod-io/gitpod/common-go/baseserver"
 	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
 	"github.com/gitpod-io/gitpod/installer/pkg/config/v1/experimental"
 
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -68,13 +70,52 @@ func MergeEnv(envs ...[]corev1.EnvVar) (res []corev1.EnvVar) {
 	return
 }
 
+// noProxyDefaults are the hosts that are reached within the cluster and must never
+// go through the proxy. The Gitpod services call each other by their short name.
+var noProxyDefaults = []string{
+	"localhost",
+	"127.0.0.1",
+	"$(KUBERNETES_SERVICE_HOST)",
+	".svc",
+	".cluster.local",
+	"blobserve",
+	"content-service",
+	"dashboard",
+	"ide-metrics",
+	"ide-proxy",
+	"ide-service",
+	ImageBuilderComponent,
+	"messagebus",
+	"minio",
+	"mysql",
+	"openvsx-proxy",
+	ProxyComponent,
+	PublicApiComponent,
+	"redis",
+	"registry",
+	RegistryFacadeComponent,
+	ServerComponent,
+	"spicedb",
+	UsageComponent,
+	"ws-daemon",
+	"wsdaemon",
+	WSManagerComponent,
+	WSManagerMk2Component,
+	WSManagerBridgeComponent,
+	WSProxyComponent,
+}
+
 func ProxyEnv(cfg *config.Config) []corev1.EnvVar {
 	if cfg.HTTPProxy == nil {
 		return []corev1.EnvVar{}
 	}
 
-	// The hard-coded values are the gRPC service names and the licence server
-	noProxyValue := "ws-manager,wsdaemon,$(CUSTOM_NO_PROXY)"
+	// the self-hosted Git hosts are only reachable on the private network, not through the proxy
+	noProxy := append([]string{}, noProxyDefaults...)
+	for _, scm := range cfg.SCMHosts {
+		noProxy = append(noProxy, scm.Host)
+	}
+	noProxyValue := strings.Join(append(noProxy, "$(CUSTOM_NO_PROXY)"), ",")
 
 	return MergeEnv(
 		getProxyServerEnvvar(cfg, "HTTP_PROXY", "httpProxy"),
@@ -94,6 +135,11 @@ func DefaultEnv(cfg *config.Config) []corev1.EnvVar {
 		logLevel = string(cfg.Observability.LogLevel)
 	}
 
+	var logFormat []corev1.EnvVar
+	if cfg.Observability.LogFormat != "" {
+		logFormat = append(logFormat, corev1.EnvVar{Name: "JSON_LOG", Value: strconv.FormatBool(cfg.Observability.LogFormat == config.LogFormatJSON)})
+	}
+
 	return MergeEnv(
 		[]corev1.EnvVar{
 			{Name: "GITPOD_DOMAIN", Value: cfg.Domain},
@@ -108,6 +154,7 @@ func DefaultEnv(cfg *config.Config) []corev1.EnvVar {
 			{Name: "KUBE_DOMAIN", Value: "svc.cluster.local"},
 			{Name: "LOG_LEVEL", Value: strings.ToLower(logLevel)},
 		},
+		logFormat,
 		ProxyEnv(cfg),
 	)
 }
@@ -149,15 +196,21 @@ func tracingEnv(context *RenderContext, component string, tracing *experimental.
 
 	if ep := context.Config.Observability.Tracing.Endpoint; ep != nil {
 		res = append(res, corev1.EnvVar{Name: "JAEGER_ENDPOINT", Value: *ep})
-		res = append(res, corev1.EnvVar{Name: "OTEL_EXPORTER_OTLP_ENDPOINT", Value: *ep})
+		if context.Config.Observability.Tracing.OTLPEndpoint == nil {
+			res = append(res, corev1.EnvVar{Name: "OTEL_EXPORTER_OTLP_ENDPOINT", Value: *ep})
+		}
 	} else if v := context.Config.Observability.Tracing.AgentHost; v != nil {
 		res = append(res, corev1.EnvVar{Name: "JAEGER_AGENT_HOST", Value: *v})
-	} else {
+	} else if context.Config.Observability.Tracing.OTLPEndpoint == nil {
 		// TODO(cw): think about proper error handling here.
 		//			 Returning an error would be the appropriate thing to do,
 		//			 but would make env var composition more cumbersome.
 	}
 
+	if ep := context.Config.Observability.Tracing.OTLPEndpoint; ep != nil {
+		res = append(res, corev1.EnvVar{Name: "OTEL_EXPORTER_OTLP_ENDPOINT", Value: *ep})
+	}
+
 	if context.Config.Observability.Tracing.SecretName != nil {
 		res = append(res, corev1.EnvVar{
 			Name: "JAEGER_USER",
@@ -176,8 +229,9 @@ func tracingEnv(context *RenderContext, component string, tracing *experimental.
 		})
 	}
 
-	res = append(res, corev1.EnvVar{Name: "JAEGER_SERVICE_NAME", Value: component})
-	res = append(res, corev1.EnvVar{Name: "OTEL_SERVICE_NAME", Value: component})
+	serviceName := context.Config.Observability.Tracing.ServiceNamePrefix + component
+	res = append(res, corev1.EnvVar{Name: "JAEGER_SERVICE_NAME", Value: serviceName})
+	res = append(res, corev1.EnvVar{Name: "OTEL_SERVICE_NAME", Value: serviceName})
 
 	jaegerTags := []string{}
 	if context.Config.Metadata.InstallationShortname != "" {
@@ -198,10 +252,19 @@ func tracingEnv(context *RenderContext, component string, tracing *experimental.
 
 	samplerType := experimental.TracingSampleTypeConst
 	samplerParam := "1"
+	otelSampler := string(samplerType)
+
+	if rate := context.Config.Observability.Tracing.SamplingRate; rate != nil {
+		samplerType = experimental.TracingSampleTypeProbabilistic
+		samplerParam = strconv.FormatFloat(*rate, 'f', -1, 64)
+		// https://opentelemetry.io/docs/concepts/sdk-configuration/general-sdk-configuration/#otel_traces_sampler
+		otelSampler = "parentbased_traceidratio"
+	}
 
 	if tracing != nil {
 		if tracing.SamplerType != nil {
 			samplerType = *tracing.SamplerType
+			otelSampler = string(samplerType)
 		}
 		if tracing.SamplerParam != nil {
 			samplerParam = strconv.FormatFloat(*tracing.SamplerParam, 'f', -1, 64)
@@ -212,7 +275,7 @@ func tracingEnv(context *RenderContext, component string, tracing *experimental.
 		corev1.EnvVar{Name: "JAEGER_SAMPLER_TYPE", Value: string(samplerType)},
 		corev1.EnvVar{Name: "JAEGER_SAMPLER_PARAM", Value: samplerParam},
 
-		corev1.EnvVar{Name: "OTEL_TRACES_SAMPLER", Value: string(samplerType)},
+		corev1.EnvVar{Name: "OTEL_TRACES_SAMPLER", Value: otelSampler},
 		corev1.EnvVar{Name: "OTEL_TRACES_SAMPLER_ARG", Value: samplerParam},
 	)
 
@@ -220,17 +283,24 @@ func tracingEnv(context *RenderContext, component string, tracing *experimental.
 }
 
 func AnalyticsEnv(cfg *config.Config) (res []corev1.EnvVar) {
-	if cfg.Analytics == nil {
+	if cfg.Analytics == nil || cfg.TelemetryDisabled() {
 		return
 	}
 
-	return []corev1.EnvVar{{
+	res = []corev1.EnvVar{{
 		Name:  "GITPOD_ANALYTICS_WRITER",
 		Value: cfg.Analytics.Writer,
 	}, {
 		Name:  "GITPOD_ANALYTICS_SEGMENT_KEY",
 		Value: cfg.Analytics.SegmentKey,
 	}}
+	if cfg.Analytics.SegmentEndpoint != "" {
+		res = append(res, corev1.EnvVar{
+			Name:  "GITPOD_ANALYTICS_SEGMENT_ENDPOINT",
+			Value: cfg.Analytics.SegmentEndpoint,
+		})
+	}
+	return res
 }
 
 func MessageBusEnv(cfg *config.Config) (res []corev1.EnvVar) {
@@ -299,7 +369,37 @@ func DatabaseEnv(cfg *config.Config) (res []corev1.EnvVar) {
 				}},
 			},
 		)
-	} else if cfg.Database.External != nil && cfg.Database.External.Certificate.Name != "" {
+	} else if cfg.Database.UsePostgres() {
+		// External PostgreSQL DB
+		secretRef = corev1.LocalObjectReference{Name: cfg.Database.External.Postgres.Certificate.Name}
+		envvars = append(envvars,
+			corev1.EnvVar{
+				Name:  "DB_TYPE",
+				Value: "postgres",
+			},
+			corev1.EnvVar{
+				Name: "DB_HOST",
+				ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: secretRef,
+					Key:                  "host",
+				}},
+			},
+			corev1.EnvVar{
+				Name: "DB_PORT",
+				ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: secretRef,
+					Key:                  "port",
+				}},
+			},
+			corev1.EnvVar{
+				Name: "DB_NAME",
+				ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: secretRef,
+					Key:                  "database",
+				}},
+			},
+		)
+	} else if cfg.Database.External != nil && cfg.Database.External.Certificate != nil && cfg.Database.External.Certificate.Name != "" {
 		// External DB
 		secretRef = corev1.LocalObjectReference{Name: cfg.Database.External.Certificate.Name}
 		envvars = append(envvars,
@@ -369,6 +469,27 @@ func DatabaseEnv(cfg *config.Config) (res []corev1.EnvVar) {
 			}},
 		})
 	}
+	if cfg.Database.SSL != nil && cfg.Database.SSL.Mode != "" {
+		envvars = append(envvars, corev1.EnvVar{
+			Name:  "DB_SSL_MODE",
+			Value: string(cfg.Database.SSL.Mode),
+		})
+	}
+
+	if pool := cfg.Database.Pool; pool != nil {
+		if pool.MaxConnections != nil {
+			envvars = append(envvars, corev1.EnvVar{
+				Name:  "DB_MAX_CONNECTIONS",
+				Value: strconv.Itoa(int(*pool.MaxConnections)),
+			})
+		}
+		if pool.IdleTimeoutSeconds != nil {
+			envvars = append(envvars, corev1.EnvVar{
+				Name:  "DB_IDLE_TIMEOUT_SECONDS",
+				Value: strconv.Itoa(int(*pool.IdleTimeoutSeconds)),
+			})
+		}
+	}
 
 	return envvars
 }
@@ -378,7 +499,11 @@ func DatabaseEnvSecret(cfg config.Config) (corev1.Volume, corev1.VolumeMount, st
 
 	if pointer.BoolDeref(cfg.Database.InCluster, false) {
 		secretName = InClusterDbSecret
-	} else if cfg.Database.External != nil && cfg.Database.External.Certificate.Name != "" {
+	} else if cfg.Database.UsePostgres() {
+		// External PostgreSQL DB
+		secretName = cfg.Database.External.Postgres.Certificate.Name
+
+	} else if cfg.Database.External != nil && cfg.Database.External.Certificate != nil && cfg.Database.External.Certificate.Name != "" {
 		// External DB
 		secretName = cfg.Database.External.Certificate.Name
 
@@ -408,6 +533,7 @@ func DatabaseEnvSecret(cfg config.Config) (corev1.Volume, corev1.VolumeMount, st
 	return volume, mount, DatabaseConfigMountPath
 }
 
+// ConfigcatEnv lets the components read the feature flags from the configcat endpoint of the proxy
 func ConfigcatEnv(ctx *RenderContext) []corev1.EnvVar {
 	var sdkKey string
 	_ = ctx.WithExperimental(func(cfg *experimental.Config) error {
@@ -417,7 +543,7 @@ func ConfigcatEnv(ctx *RenderContext) []corev1.EnvVar {
 		return nil
 	})
 
-	if sdkKey == "" {
+	if sdkKey == "" && ctx.Config.FeatureFlags == nil {
 		return nil
 	}
 
@@ -433,6 +559,8 @@ func ConfigcatEnv(ctx *RenderContext) []corev1.EnvVar {
 	}
 }
 
+// ConfigcatProxyEnv configures where the proxy reads the feature flags from. The featureFlags of
+// the config take precedence over the experimental configcat settings.
 func ConfigcatProxyEnv(ctx *RenderContext) []corev1.EnvVar {
 	var (
 		sdkKey       string
@@ -450,6 +578,35 @@ func ConfigcatProxyEnv(ctx *RenderContext) []corev1.EnvVar {
 		return nil
 	})
 
+	if flags := ctx.Config.FeatureFlags; flags != nil && flags.Static != nil {
+		return []corev1.EnvVar{{
+			Name:  "CONFIGCAT_CONFIG_FILE",
+			Value: FeatureFlagsFile,
+		}}
+	} else if flags != nil && flags.ConfigCat != nil {
+		pollInterval = time.Minute.String()
+		if flags.ConfigCat.PollInterval != nil {
+			pollInterval = flags.ConfigCat.PollInterval.String()
+		}
+		return []corev1.EnvVar{
+			{
+				Name: "CONFIGCAT_SDK_KEY",
+				ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: flags.ConfigCat.SDKKey.Name},
+					Key:                  "sdkKey",
+				}},
+			},
+			{
+				Name:  "CONFIGCAT_BASE_URL",
+				Value: flags.ConfigCat.BaseURL,
+			},
+			{
+				Name:  "CONFIGCAT_POLL_INTERVAL",
+				Value: pollInterval,
+			},
+		}
+	}
+
 	if sdkKey == "" {
 		return nil
 	}
@@ -471,13 +628,18 @@ func ConfigcatProxyEnv(ctx *RenderContext) []corev1.EnvVar {
 }
 
 func DatabaseWaiterContainer(ctx *RenderContext) *corev1.Container {
+	args := []string{
+		"-v",
+		"database",
+	}
+	if w := ctx.Config.Database.Wait; w != nil && w.Timeout != nil {
+		args = append(args, "--timeout", w.Timeout.String())
+	}
+
 	return &corev1.Container{
 		Name:  "database-waiter",
 		Image: ctx.ImageName(ctx.Config.Repository, "service-waiter", ctx.VersionManifest.Components.ServiceWaiter.Version),
-		Args: []string{
-			"-v",
-			"database",
-		},
+		Args:  args,
 		SecurityContext: &corev1.SecurityContext{
 			Privileged:               pointer.Bool(false),
 			AllowPrivilegeEscalation: pointer.Bool(false),
@@ -490,6 +652,50 @@ func DatabaseWaiterContainer(ctx *RenderContext) *corev1.Container {
 	}
 }
 
+// DatabaseWaiterContainers are the init containers of the components that use the database. They
+// wait until the database is reachable and, if configured, until its migrations are applied.
+func DatabaseWaiterContainers(ctx *RenderContext) []corev1.Container {
+	res := []corev1.Container{*DatabaseWaiterContainer(ctx)}
+	if ctx.Config.Database.Wait != nil && ctx.Config.Database.Wait.Migrations {
+		res = append(res, *DatabaseMigrationWaiterContainer(ctx))
+	}
+	return res
+}
+
+// DatabaseMigrationWaiterContainer waits until the migrations job has applied the migrations of
+// the db-migrations image. TypeORM fails to show the migrations while some are pending.
+func DatabaseMigrationWaiterContainer(ctx *RenderContext) *corev1.Container {
+	return &corev1.Container{
+		Name:  "migration-waiter",
+		Image: ctx.ImageName(ctx.Config.Repository, "db-migrations", ctx.VersionManifest.Components.DBMigrations.Version),
+		Command: []string{
+			"sh",
+			"-c",
+			fmt.Sprintf(`cd /app/node_modules/@gitpod/gitpod-db && end=$(( $(date +%%s) + %d ))
+until yarn run --silent typeorm migration:show > /dev/null; do
+  if [ "$(date +%%s)" -ge "$end" ]; then echo "the database migrations were not applied in time"; exit 1; fi
+  echo "waiting for the database migrations"; sleep 5
+done`, int64(databaseWaitTimeout(ctx).Seconds())),
+		},
+		SecurityContext: &corev1.SecurityContext{
+			Privileged:               pointer.Bool(false),
+			AllowPrivilegeEscalation: pointer.Bool(false),
+		},
+		Env: MergeEnv(
+			DatabaseEnv(&ctx.Config),
+			DefaultEnv(&ctx.Config),
+		),
+	}
+}
+
+// databaseWaitTimeout is how long the init containers wait for the database
+func databaseWaitTimeout(ctx *RenderContext) time.Duration {
+	if w := ctx.Config.Database.Wait; w != nil && w.Timeout != nil {
+		return time.Duration(*w.Timeout)
+	}
+	return 5 * time.Minute
+}
+
 func MessageBusWaiterContainer(ctx *RenderContext) *corev1.Container {
 	return &corev1.Container{
 		Name:  "msgbus-waiter",
@@ -516,7 +722,7 @@ func KubeRBACProxyContainer(ctx *RenderContext) *corev1.Container {
 
 func KubeRBACProxyContainerWithConfig(ctx *RenderContext) *corev1.Container {
 	return &corev1.Container{
-		Name:  "kube-rbac-proxy",
+		Name:  kubeRBACProxyContainer,
 		Image: ctx.ImageName(ThirdPartyContainerRepo(ctx.Config.Repository, KubeRBACProxyRepo), KubeRBACProxyImage, KubeRBACProxyTag),
 		Args: []string{
 			"--logtostderr",
@@ -564,14 +770,22 @@ func IsDatabaseMigrationDisabled(ctx *RenderContext) bool {
 	return disableMigration
 }
 
+// Replicas returns the replica count of the component. This is nil if the component is
+// autoscaled, so that applying the deployment does not reset the autoscaler's value.
 func Replicas(ctx *RenderContext, component string) *int32 {
 	replicas := int32(1)
 
 	if ctx.Config.Components != nil && ctx.Config.Components.PodConfig[component] != nil {
+		if ctx.Config.Components.PodConfig[component].Autoscaling != nil {
+			return nil
+		}
 		if ctx.Config.Components.PodConfig[component].Replicas != nil {
 			replicas = *ctx.Config.Components.PodConfig[component].Replicas
 		}
 	}
+	if component == ServerComponent && ctx.Config.Components != nil && ctx.Config.Components.Server != nil && ctx.Config.Components.Server.Replicas != nil {
+		replicas = *ctx.Config.Components.Server.Replicas
+	}
 
 	return &replicas
 }
@@ -588,6 +802,271 @@ func ResourceRequirements(ctx *RenderContext, component, containerName string, d
 	return resources
 }
 
+// Affinity returns the default affinity with the node affinity replaced by the
+// one configured for the component, if any
+func Affinity(ctx *RenderContext, component string, defaults *corev1.Affinity) *corev1.Affinity {
+	if ctx.Config.Components == nil || ctx.Config.Components.PodConfig[component] == nil || ctx.Config.Components.PodConfig[component].NodeAffinity == nil {
+		return defaults
+	}
+
+	affinity := &corev1.Affinity{}
+	if defaults != nil {
+		affinity = defaults.DeepCopy()
+	}
+	affinity.NodeAffinity = ctx.Config.Components.PodConfig[component].NodeAffinity.DeepCopy()
+
+	return affinity
+}
+
+// Tolerations returns the default tolerations together with the ones configured for the component
+func Tolerations(ctx *RenderContext, component string, defaults []corev1.Toleration) []corev1.Toleration {
+	if ctx.Config.Components == nil || ctx.Config.Components.PodConfig[component] == nil {
+		return defaults
+	}
+
+	return append(append([]corev1.Toleration{}, defaults...), ctx.Config.Components.PodConfig[component].Tolerations...)
+}
+
+// NodeSelector adds the node selector configured for the component to the pod
+func NodeSelector(ctx *RenderContext, component string, pod *corev1.PodSpec) {
+	if ctx.Config.Components == nil || ctx.Config.Components.PodConfig[component] == nil {
+		return
+	}
+	selector := ctx.Config.Components.PodConfig[component].NodeSelector
+	if len(selector) == 0 {
+		return
+	}
+
+	if pod.NodeSelector == nil {
+		pod.NodeSelector = make(map[string]string, len(selector))
+	}
+	for k, v := range selector {
+		pod.NodeSelector[k] = v
+	}
+}
+
+// WorkloadNodeSelector adds the node selectors configured for the components to the pods of
+// the objects, by the component in the labels of their pods
+func WorkloadNodeSelector(ctx *RenderContext, objs []runtime.Object) {
+	for _, obj := range objs {
+		if template := podTemplate(obj); template != nil {
+			NodeSelector(ctx, template.Labels["component"], &template.Spec)
+		}
+	}
+}
+
+// Probes applies the probe settings configured for the component to the containers of the pod
+func Probes(ctx *RenderContext, component string, pod *corev1.PodSpec) {
+	if ctx.Config.Components == nil || ctx.Config.Components.PodConfig[component] == nil {
+		return
+	}
+
+	for i := range pod.Containers {
+		c := &pod.Containers[i]
+		probes := ctx.Config.Components.PodConfig[component].Probes[c.Name]
+		if probes == nil {
+			continue
+		}
+
+		if probes.Startup != nil && c.StartupProbe == nil {
+			// the startup probe checks the container the same way as the other probes
+			switch {
+			case c.LivenessProbe != nil:
+				c.StartupProbe = &corev1.Probe{ProbeHandler: *c.LivenessProbe.ProbeHandler.DeepCopy()}
+			case c.ReadinessProbe != nil:
+				c.StartupProbe = &corev1.Probe{ProbeHandler: *c.ReadinessProbe.ProbeHandler.DeepCopy()}
+			}
+		}
+		applyProbeSettings(c.LivenessProbe, probes.Liveness)
+		applyProbeSettings(c.ReadinessProbe, probes.Readiness)
+		applyProbeSettings(c.StartupProbe, probes.Startup)
+	}
+}
+
+// WorkloadProbes applies the probe settings to the pods of the deployments, stateful sets and
+// daemon sets, by the component in the labels of their pods
+func WorkloadProbes(ctx *RenderContext, objs []runtime.Object) {
+	for _, obj := range objs {
+		switch obj.(type) {
+		case *appsv1.Deployment, *appsv1.StatefulSet, *appsv1.DaemonSet:
+		default:
+			continue
+		}
+		template := podTemplate(obj)
+		Probes(ctx, template.Labels["component"], &template.Spec)
+	}
+}
+
+// Env adds the env vars and sources configured for the component to the containers of the pod.
+// The configured env vars replace those of the same name.
+func Env(ctx *RenderContext, component string, pod *corev1.PodSpec) {
+	if ctx.Config.Components == nil || ctx.Config.Components.PodConfig[component] == nil {
+		return
+	}
+	cfg := ctx.Config.Components.PodConfig[component]
+	if len(cfg.Env) == 0 && len(cfg.EnvFrom) == 0 {
+		return
+	}
+
+	for i := range pod.Containers {
+		c := &pod.Containers[i]
+
+		env := make([]corev1.EnvVar, 0, len(c.Env)+len(cfg.Env))
+		for _, e := range c.Env {
+			if !hasEnvVar(cfg.Env, e.Name) {
+				env = append(env, e)
+			}
+		}
+		for _, e := range cfg.Env {
+			env = append(env, *e.DeepCopy())
+		}
+		c.Env = env
+
+		for _, e := range cfg.EnvFrom {
+			c.EnvFrom = append(c.EnvFrom, *e.DeepCopy())
+		}
+	}
+}
+
+func hasEnvVar(env []corev1.EnvVar, name string) bool {
+	for _, e := range env {
+		if e.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// WorkloadEnv adds the env vars configured for the components to the pods of the objects, by the
+// component in the labels of their pods
+func WorkloadEnv(ctx *RenderContext, objs []runtime.Object) {
+	for _, obj := range objs {
+		if template := podTemplate(obj); template != nil {
+			Env(ctx, template.Labels["component"], &template.Spec)
+		}
+	}
+}
+
+// Containers adds the sidecars, init containers and volumes configured for the component to
+// the pod. It fails if the pod already has a container or volume of the same name.
+func Containers(ctx *RenderContext, component string, pod *corev1.PodSpec) error {
+	if ctx.Config.Components == nil || ctx.Config.Components.PodConfig[component] == nil {
+		return nil
+	}
+	cfg := ctx.Config.Components.PodConfig[component]
+
+	names := make(map[string]struct{}, len(pod.Containers)+len(pod.InitContainers))
+	for _, c := range append(append([]corev1.Container{}, pod.Containers...), pod.InitContainers...) {
+		names[c.Name] = struct{}{}
+	}
+	for _, c := range append(append([]corev1.Container{}, cfg.Sidecars...), cfg.InitContainers...) {
+		if _, ok := names[c.Name]; ok {
+			return fmt.Errorf("the pods of %s already have a container %s", component, c.Name)
+		}
+	}
+	for _, v := range cfg.Volumes {
+		for _, existing := range pod.Volumes {
+			if existing.Name == v.Name {
+				return fmt.Errorf("the pods of %s already have a volume %s", component, v.Name)
+			}
+		}
+	}
+
+	for _, c := range cfg.Sidecars {
+		pod.Containers = append(pod.Containers, *c.DeepCopy())
+	}
+	for _, c := range cfg.InitContainers {
+		pod.InitContainers = append(pod.InitContainers, *c.DeepCopy())
+	}
+	for _, v := range cfg.Volumes {
+		pod.Volumes = append(pod.Volumes, *v.DeepCopy())
+	}
+	return nil
+}
+
+// WorkloadContainers adds the containers configured for the components to the pods of the
+// objects, by the component in the labels of their pods
+func WorkloadContainers(ctx *RenderContext, objs []runtime.Object) error {
+	for _, obj := range objs {
+		template := podTemplate(obj)
+		if template == nil {
+			continue
+		}
+		if err := Containers(ctx, template.Labels["component"], &template.Spec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// podTemplate returns the template of the pods the object creates, or nil if it has none
+func podTemplate(obj runtime.Object) *corev1.PodTemplateSpec {
+	switch o := obj.(type) {
+	case *appsv1.Deployment:
+		return &o.Spec.Template
+	case *appsv1.StatefulSet:
+		return &o.Spec.Template
+	case *appsv1.DaemonSet:
+		return &o.Spec.Template
+	case *batchv1.Job:
+		return &o.Spec.Template
+	case *batchv1.CronJob:
+		return &o.Spec.JobTemplate.Spec.Template
+	}
+	return nil
+}
+
+func applyProbeSettings(probe *corev1.Probe, settings *config.ProbeSettings) {
+	if probe == nil || settings == nil {
+		return
+	}
+
+	if settings.InitialDelaySeconds != nil {
+		probe.InitialDelaySeconds = *settings.InitialDelaySeconds
+	}
+	if settings.PeriodSeconds != nil {
+		probe.PeriodSeconds = *settings.PeriodSeconds
+	}
+	if settings.TimeoutSeconds != nil {
+		probe.TimeoutSeconds = *settings.TimeoutSeconds
+	}
+	if settings.SuccessThreshold != nil {
+		probe.SuccessThreshold = *settings.SuccessThreshold
+	}
+	if settings.FailureThreshold != nil {
+		probe.FailureThreshold = *settings.FailureThreshold
+	}
+}
+
+// workspaceInfraComponents run on the workspace nodes and are needed by the running workspaces
+var workspaceInfraComponents = map[string]struct{}{
+	"agent-smith":             {},
+	"blobserve":               {},
+	ImageBuilderComponent:     {},
+	"image-builder-mk3-wsman": {},
+	"node-labeler":            {},
+	RegistryFacadeComponent:   {},
+	"ws-daemon":               {},
+	WSManagerComponent:        {},
+	WSManagerMk2Component:     {},
+	WSProxyComponent:          {},
+}
+
+// PriorityClassName returns the priority class of the component's pods. If the Gitpod priority
+// classes are enabled, they are used for the components that have no other default.
+func PriorityClassName(ctx *RenderContext, component string, defaultClass string) string {
+	if ctx.Config.Components != nil && ctx.Config.Components.PodConfig[component] != nil && ctx.Config.Components.PodConfig[component].PriorityClassName != "" {
+		return ctx.Config.Components.PodConfig[component].PriorityClassName
+	}
+	if defaultClass != "" || ctx.Config.PriorityClasses == nil || !ctx.Config.PriorityClasses.Enabled {
+		return defaultClass
+	}
+	if _, ok := workspaceInfraComponents[component]; ok {
+		return PriorityClassWorkspaceInfra
+	}
+	return PriorityClassMeta
+}
+
 // ObjectHash marshals the objects to YAML and produces a sha256 hash of the output.
 // This function is useful for restarting pods when the config changes.
 // Takes an error as argument to make calling it more conventient. If that error is not nil,
@@ -656,6 +1135,10 @@ var (
 		APIVersion: "v1",
 		Kind:       "ConfigMap",
 	}
+	TypeMetaPersistentVolumeClaim = metav1.TypeMeta{
+		APIVersion: "v1",
+		Kind:       "PersistentVolumeClaim",
+	}
 	TypeMetaServiceAccount = metav1.TypeMeta{
 		APIVersion: "v1",
 		Kind:       "ServiceAccount",
@@ -696,6 +1179,28 @@ var (
 		APIVersion: "apps/v1",
 		Kind:       "Deployment",
 	}
+	TypeMetaHorizontalPodAutoscaler = metav1.TypeMeta{
+		APIVersion: "autoscaling/v2",
+		Kind:       "HorizontalPodAutoscaler",
+	}
+	// TypeMetaHorizontalPodAutoscalerV2beta2 is the autoscaler of the Kubernetes versions that do
+	// not serve autoscaling/v2 yet
+	TypeMetaHorizontalPodAutoscalerV2beta2 = metav1.TypeMeta{
+		APIVersion: "autoscaling/v2beta2",
+		Kind:       "HorizontalPodAutoscaler",
+	}
+	TypeMetaPodDisruptionBudget = metav1.TypeMeta{
+		APIVersion: "policy/v1",
+		Kind:       "PodDisruptionBudget",
+	}
+	TypeMetaServiceMonitor = metav1.TypeMeta{
+		APIVersion: "monitoring.coreos.com/v1",
+		Kind:       "ServiceMonitor",
+	}
+	TypeMetaPrometheusRule = metav1.TypeMeta{
+		APIVersion: "monitoring.coreos.com/v1",
+		Kind:       "PrometheusRule",
+	}
 	TypeMetaCertificate = metav1.TypeMeta{
 		APIVersion: "cert-manager.io/v1",
 		Kind:       "Certificate",
@@ -708,10 +1213,26 @@ var (
 		APIVersion: "v1",
 		Kind:       "Secret",
 	}
+	TypeMetaExternalSecret = metav1.TypeMeta{
+		APIVersion: "external-secrets.io/v1beta1",
+		Kind:       "ExternalSecret",
+	}
+	TypeMetaSealedSecret = metav1.TypeMeta{
+		APIVersion: "bitnami.com/v1alpha1",
+		Kind:       "SealedSecret",
+	}
 	TypeMetaPodSecurityPolicy = metav1.TypeMeta{
 		APIVersion: "policy/v1beta1",
 		Kind:       "PodSecurityPolicy",
 	}
+	TypeMetaSecurityContextConstraints = metav1.TypeMeta{
+		APIVersion: "security.openshift.io/v1",
+		Kind:       "SecurityContextConstraints",
+	}
+	TypeMetaRoute = metav1.TypeMeta{
+		APIVersion: "route.openshift.io/v1",
+		Kind:       "Route",
+	}
 	TypeMetaResourceQuota = metav1.TypeMeta{
 		APIVersion: "v1",
 		Kind:       "ResourceQuota",
@@ -732,6 +1253,18 @@ var (
 		APIVersion: "trust.cert-manager.io/v1alpha1",
 		Kind:       "Bundle",
 	}
+	TypeMetaPriorityClass = metav1.TypeMeta{
+		APIVersion: "scheduling.k8s.io/v1",
+		Kind:       "PriorityClass",
+	}
+	TypeMetaMutatingWebhookConfiguration = metav1.TypeMeta{
+		APIVersion: "admissionregistration.k8s.io/v1",
+		Kind:       "MutatingWebhookConfiguration",
+	}
+	TypeMetaValidatingWebhookConfiguration = metav1.TypeMeta{
+		APIVersion: "admissionregistration.k8s.io/v1",
+		Kind:       "ValidatingWebhookConfiguration",
+	}
 )
 
 // validCookieChars contains all characters which may occur in an HTTP Cookie value (unicode \u0021 through \u007E),
@@ -814,14 +1347,35 @@ func ExperimentalWebappConfig(ctx *RenderContext) *experimental.WebAppConfig {
 	return experimentalCfg.WebApp
 }
 
+// WorkspaceCertificate returns the secret with the certificate for the workspace domains
+func WorkspaceCertificate(ctx *RenderContext) config.ObjectRef {
+	if ctx.Config.WorkspaceCertificate != nil {
+		return *ctx.Config.WorkspaceCertificate
+	}
+	return ctx.Config.Certificate
+}
+
 // WithLocalWsManager returns true if the installed application cluster should connect to a local ws-manager
 func WithLocalWsManager(ctx *RenderContext) bool {
 	return ctx.Config.Kind == config.InstallationFull
 }
 
-func DaemonSetRolloutStrategy() appsv1.DaemonSetUpdateStrategy {
+// DaemonSetRolloutStrategy returns the update strategy configured for the daemon set of the
+// component, or a rolling update of 20% of the nodes at a time
+func DaemonSetRolloutStrategy(ctx *RenderContext, component string) appsv1.DaemonSetUpdateStrategy {
 	maxUnavailable := intstr.Parse("20%")
 
+	if ctx.Config.Components != nil && ctx.Config.Components.PodConfig[component] != nil {
+		if strategy := ctx.Config.Components.PodConfig[component].UpdateStrategy; strategy != nil {
+			if strategy.Type == config.DaemonSetUpdateOnDelete {
+				return appsv1.DaemonSetUpdateStrategy{Type: appsv1.OnDeleteDaemonSetStrategyType}
+			}
+			if strategy.MaxUnavailable != nil {
+				maxUnavailable = *strategy.MaxUnavailable
+			}
+		}
+	}
+
 	return appsv1.DaemonSetUpdateStrategy{
 		Type: appsv1.RollingUpdateDaemonSetStrategyType,
 		RollingUpdate: &appsv1.RollingUpdateDaemonSet{
@@ -829,3 +1383,182 @@ func DaemonSetRolloutStrategy() appsv1.DaemonSetUpdateStrategy {
 		},
 	}
 }
+
+// WorkspaceTemplates returns the installation's workspace templates. Workspaces never get a
+// service mesh sidecar, which is disabled in the default pod template.
+func WorkspaceTemplates(ctx *RenderContext) *config.WorkspaceTemplates {
+	tpls := &config.WorkspaceTemplates{}
+	if ctx.Config.Workspace.Templates != nil {
+		tpls.Prebuild = ctx.Config.Workspace.Templates.Prebuild
+		tpls.ImageBuild = ctx.Config.Workspace.Templates.ImageBuild.DeepCopy()
+		tpls.Regular = ctx.Config.Workspace.Templates.Regular
+		tpls.Default = ctx.Config.Workspace.Templates.Default.DeepCopy()
+	}
+
+	if bk := BuildKit(ctx); bk != nil && (bk.Resources != nil || bk.Cache != nil) {
+		if tpls.ImageBuild == nil {
+			tpls.ImageBuild = &corev1.Pod{}
+		}
+		spec := &tpls.ImageBuild.Spec
+
+		// The container is merged with the workspace container by its name
+		container := corev1.Container{Name: WorkspaceContainerName}
+		if bk.Resources != nil {
+			container.Resources = *bk.Resources
+		}
+		if bk.Cache != nil {
+			spec.Volumes = append(spec.Volumes, corev1.Volume{
+				Name: "buildkit-cache",
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: ImageBuilderCacheClaim},
+				},
+			})
+			container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+				Name:      "buildkit-cache",
+				MountPath: ImageBuilderCacheDir,
+			})
+			// The build runs as root in a user namespace, which is mapped to 33333 on the node
+			if spec.SecurityContext == nil {
+				spec.SecurityContext = &corev1.PodSecurityContext{}
+			}
+			if spec.SecurityContext.FSGroup == nil {
+				spec.SecurityContext.FSGroup = pointer.Int64(33333)
+			}
+		}
+		spec.Containers = append(spec.Containers, container)
+	}
+
+	if dnsConfig := NodeLocalDNSConfig(ctx); dnsConfig != nil && ctx.Config.Workspace.DNS == nil {
+		if tpls.Default == nil {
+			tpls.Default = &corev1.Pod{}
+		}
+		if tpls.Default.Spec.DNSPolicy == "" && tpls.Default.Spec.DNSConfig == nil {
+			tpls.Default.Spec.DNSPolicy = corev1.DNSNone
+			tpls.Default.Spec.DNSConfig = dnsConfig
+		}
+	}
+	if dns := ctx.Config.Workspace.DNS; dns != nil {
+		if tpls.Default == nil {
+			tpls.Default = &corev1.Pod{}
+		}
+		// the settings of a default template in the config take precedence
+		spec := &tpls.Default.Spec
+		if spec.DNSPolicy == "" {
+			spec.DNSPolicy = dns.Policy
+		}
+		if spec.DNSConfig == nil && (len(dns.Nameservers) > 0 || len(dns.Searches) > 0 || len(dns.Options) > 0) {
+			spec.DNSConfig = &corev1.PodDNSConfig{
+				Nameservers: dns.Nameservers,
+				Searches:    dns.Searches,
+				Options:     dns.Options,
+			}
+		}
+	}
+
+	annotations := make(map[string]string)
+	for k, v := range MeshSidecarAnnotations(ctx.Config.Mesh, false) {
+		annotations[k] = v
+	}
+	if ctx.Config.ClusterAutoscaler != nil && ctx.Config.ClusterAutoscaler.Enabled {
+		// A workspace cannot be moved to another node, the node has to stay until it stops
+		annotations[AnnotationSafeToEvict] = "false"
+	}
+	if len(annotations) > 0 {
+		if tpls.Default == nil {
+			tpls.Default = &corev1.Pod{}
+		}
+		if tpls.Default.Annotations == nil {
+			tpls.Default.Annotations = make(map[string]string, len(annotations))
+		}
+		for k, v := range annotations {
+			tpls.Default.Annotations[k] = v
+		}
+	}
+	return tpls
+}
+
+// BuildKit returns the config of BuildKit in the image builds, or nil when the defaults apply
+func BuildKit(ctx *RenderContext) *config.BuildKit {
+	if ctx.Config.Components == nil || ctx.Config.Components.ImageBuilder == nil {
+		return nil
+	}
+	return ctx.Config.Components.ImageBuilder.BuildKit
+}
+
+// UseWsManagerMk2 is true when ws-manager-mk2 manages the workspaces, in place of ws-manager or,
+// with the experimental flag, next to it
+func UseWsManagerMk2(ctx *RenderContext) bool {
+	if ctx.Config.Workspace.Manager == config.WorkspaceManagerMk2 {
+		return true
+	}
+
+	var useMk2 bool
+	_ = ctx.WithExperimental(func(ucfg *experimental.Config) error {
+		if ucfg.Workspace != nil {
+			useMk2 = ucfg.Workspace.UseWsmanagerMk2
+		}
+		return nil
+	})
+	return useMk2
+}
+
+// WorkspaceClassTemplates returns the workspace templates for a workspace class. These are the
+// installation's templates with the class' node selector and GPUs added to the default pod template.
+func WorkspaceClassTemplates(ctx *RenderContext, class config.WorkspaceClass) *config.WorkspaceTemplates {
+	tpls := WorkspaceTemplates(ctx)
+	if len(class.NodeSelector) == 0 && class.GPUs == 0 {
+		return tpls
+	}
+
+	if tpls.Default == nil {
+		tpls.Default = &corev1.Pod{}
+	}
+	spec := &tpls.Default.Spec
+
+	if len(class.NodeSelector) > 0 && spec.NodeSelector == nil {
+		spec.NodeSelector = make(map[string]string, len(class.NodeSelector))
+	}
+	for k, v := range class.NodeSelector {
+		spec.NodeSelector[k] = v
+	}
+
+	if class.GPUs > 0 {
+		tolerations := []corev1.Toleration{{
+			Key:      string(GPUResourceName),
+			Operator: corev1.TolerationOpExists,
+			Effect:   corev1.TaintEffectNoSchedule,
+		}}
+		if gpu := ctx.Config.Workspace.GPU; gpu != nil {
+			spec.RuntimeClassName = gpu.RuntimeClassName
+			if len(gpu.Tolerations) > 0 {
+				tolerations = gpu.Tolerations
+			}
+		}
+		spec.Tolerations = append(spec.Tolerations, tolerations...)
+
+		// ws-manager merges the template containers with the workspace pod's containers by name
+		idx := -1
+		for i, c := range spec.Containers {
+			if c.Name == WorkspaceContainerName {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			spec.Containers = append(spec.Containers, corev1.Container{Name: WorkspaceContainerName})
+			idx = len(spec.Containers) - 1
+		}
+		res := &spec.Containers[idx].Resources
+		if res.Requests == nil {
+			res.Requests = corev1.ResourceList{}
+		}
+		if res.Limits == nil {
+			res.Limits = corev1.ResourceList{}
+		}
+		gpus := *resource.NewQuantity(class.GPUs, resource.DecimalSI)
+		res.Requests[GPUResourceName] = gpus
+		res.Limits[GPUResourceName] = gpus
+	}
+
+	return tpls
+}
@@ -5,13 +5,26 @@
 package common_test
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
 
 	"github.com/gitpod-io/gitpod/common-go/baseserver"
+	common_grpc "github.com/gitpod-io/gitpod/common-go/grpc"
+	"github.com/gitpod-io/gitpod/common-go/util"
 	"github.com/gitpod-io/gitpod/installer/pkg/common"
 	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
 	"github.com/gitpod-io/gitpod/installer/pkg/config/versions"
@@ -46,3 +59,638 @@ func TestKubeRBACProxyContainerWithConfig(t *testing.T) {
 		{Name: baseserver.BuiltinMetricsPortName, ContainerPort: baseserver.BuiltinMetricsPort},
 	}, container.Ports)
 }
+
+func TestProxyEnv(t *testing.T) {
+	require.Empty(t, common.ProxyEnv(&config.Config{}))
+
+	env := common.ProxyEnv(&config.Config{HTTPProxy: &config.ObjectRef{Kind: config.ObjectRefSecret, Name: "http-proxy"}})
+	var noProxy string
+	for i, e := range env {
+		if e.Name == "NO_PROXY" {
+			noProxy = e.Value
+			// the custom entries can only be referenced if they're defined before
+			require.Contains(t, env[:i], corev1.EnvVar{
+				Name: "CUSTOM_NO_PROXY",
+				ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "http-proxy"},
+					Key:                  "noProxy",
+					Optional:             pointer.Bool(true),
+				}},
+			})
+		}
+	}
+	for _, host := range []string{"localhost", ".svc", common.ServerComponent, common.ImageBuilderComponent, common.RegistryFacadeComponent, "content-service", "$(CUSTOM_NO_PROXY)"} {
+		require.Contains(t, strings.Split(noProxy, ","), host)
+	}
+}
+
+func TestAnalyticsEnv(t *testing.T) {
+	analytics := &config.Analytics{Writer: "segment", SegmentKey: "key", SegmentEndpoint: "https://segment.example.com"}
+
+	env := make(map[string]string)
+	for _, e := range common.AnalyticsEnv(&config.Config{Analytics: analytics}) {
+		env[e.Name] = e.Value
+	}
+	require.Equal(t, map[string]string{
+		"GITPOD_ANALYTICS_WRITER":           "segment",
+		"GITPOD_ANALYTICS_SEGMENT_KEY":      "key",
+		"GITPOD_ANALYTICS_SEGMENT_ENDPOINT": "https://segment.example.com",
+	}, env)
+
+	require.Empty(t, common.AnalyticsEnv(&config.Config{Analytics: analytics, Telemetry: &config.TelemetryConfig{Disabled: true}}))
+}
+
+func TestWebappTracingEnv(t *testing.T) {
+	ctx, err := common.NewRenderContext(config.Config{
+		Observability: config.Observability{Tracing: &config.Tracing{
+			Endpoint:          pointer.String("http://jaeger:14268/api/traces"),
+			OTLPEndpoint:      pointer.String("http://tempo:4318"),
+			SamplingRate:      pointer.Float64(0.25),
+			ServiceNamePrefix: "gitpod-",
+		}},
+	}, versions.Manifest{}, "test")
+	require.NoError(t, err)
+
+	env := make(map[string]string)
+	for _, e := range common.WebappTracingEnv(ctx, common.ServerComponent) {
+		env[e.Name] = e.Value
+	}
+	require.Equal(t, "http://jaeger:14268/api/traces", env["JAEGER_ENDPOINT"])
+	require.Equal(t, "http://tempo:4318", env["OTEL_EXPORTER_OTLP_ENDPOINT"])
+	require.Equal(t, "gitpod-server", env["OTEL_SERVICE_NAME"])
+	require.Equal(t, "probabilistic", env["JAEGER_SAMPLER_TYPE"])
+	require.Equal(t, "parentbased_traceidratio", env["OTEL_TRACES_SAMPLER"])
+	require.Equal(t, "0.25", env["OTEL_TRACES_SAMPLER_ARG"])
+}
+
+func TestGRPCClientConfig(t *testing.T) {
+	ctx, err := common.NewRenderContext(config.Config{}, versions.Manifest{}, "test_namespace")
+	require.NoError(t, err)
+	require.Nil(t, common.GRPCClientConfig(ctx))
+	require.Empty(t, common.GRPCClientEnv(ctx))
+
+	size := resource.MustParse("64Mi")
+	dialTimeout := util.Duration(30 * time.Second)
+	ctx, err = common.NewRenderContext(config.Config{
+		Components: &config.Components{
+			GRPC: &config.GRPCClients{
+				DialTimeout:    &dialTimeout,
+				MaxMessageSize: &size,
+				Retry: &config.GRPCRetryPolicy{
+					MaxAttempts:    3,
+					InitialBackoff: util.Duration(200 * time.Millisecond),
+					MaxBackoff:     util.Duration(5 * time.Second),
+				},
+			},
+		},
+	}, versions.Manifest{}, "test_namespace")
+	require.NoError(t, err)
+
+	require.Equal(t, &common_grpc.ClientConfig{
+		DialTimeout: util.Duration(30 * time.Second),
+		MaxMsgSize:  64 * 1024 * 1024,
+		Retry: &common_grpc.RetryPolicy{
+			MaxAttempts:    3,
+			InitialBackoff: util.Duration(200 * time.Millisecond),
+			MaxBackoff:     util.Duration(5 * time.Second),
+		},
+	}, common.GRPCClientConfig(ctx))
+	require.Equal(t, []corev1.EnvVar{{Name: "GRPC_MAX_RECEIVE_MESSAGE_LENGTH", Value: "67108864"}}, common.GRPCClientEnv(ctx))
+}
+
+func TestComponentLogLevel(t *testing.T) {
+	ctx, err := common.NewRenderContext(config.Config{
+		Observability: config.Observability{LogLevel: config.LogLevelInfo, LogFormat: config.LogFormatText},
+		Components: &config.Components{PodConfig: map[string]*config.PodConfig{
+			common.ServerComponent: {LogLevel: config.LogLevelDebug},
+		}},
+	}, versions.Manifest{}, "test")
+	require.NoError(t, err)
+
+	env := common.CustomizeEnvvar(ctx, common.ServerComponent, common.DefaultEnv(&ctx.Config))
+	require.Contains(t, env, corev1.EnvVar{Name: "LOG_LEVEL", Value: "debug"})
+	require.Contains(t, env, corev1.EnvVar{Name: "JSON_LOG", Value: "false"})
+
+	env = common.CustomizeEnvvar(ctx, common.WSManagerComponent, common.DefaultEnv(&ctx.Config))
+	require.Contains(t, env, corev1.EnvVar{Name: "LOG_LEVEL", Value: "info"})
+}
+
+func TestDatabaseEnv_Pool(t *testing.T) {
+	env := common.DatabaseEnv(&config.Config{
+		Database: config.Database{
+			InCluster: pointer.Bool(true),
+			SSL:       &config.SSLOptions{Mode: config.DatabaseSSLModeRequired},
+			Pool: &config.DatabasePool{
+				MaxConnections:     pointer.Int32(15),
+				IdleTimeoutSeconds: pointer.Int32(300),
+			},
+		},
+	})
+
+	require.Contains(t, env, corev1.EnvVar{Name: "DB_SSL_MODE", Value: "required"})
+	require.Contains(t, env, corev1.EnvVar{Name: "DB_MAX_CONNECTIONS", Value: "15"})
+	require.Contains(t, env, corev1.EnvVar{Name: "DB_IDLE_TIMEOUT_SECONDS", Value: "300"})
+}
+
+func TestDatabaseWaiterContainers(t *testing.T) {
+	timeout := util.Duration(10 * time.Minute)
+	tests := []struct {
+		Name       string
+		Wait       *config.DatabaseWait
+		Containers []string
+		WaiterArgs []string
+	}{
+		{
+			Name:       "default",
+			Containers: []string{"database-waiter"},
+			WaiterArgs: []string{"-v", "database"},
+		},
+		{
+			Name:       "migrations and timeout",
+			Wait:       &config.DatabaseWait{Migrations: true, Timeout: &timeout},
+			Containers: []string{"database-waiter", "migration-waiter"},
+			WaiterArgs: []string{"-v", "database", "--timeout", "10m0s"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			ctx, err := common.NewRenderContext(config.Config{
+				Database: config.Database{InCluster: pointer.Bool(true), Wait: test.Wait},
+			}, versions.Manifest{Components: versions.Components{
+				ServiceWaiter: versions.Versioned{Version: "test"},
+				DBMigrations:  versions.Versioned{Version: "test"},
+			}}, "test_namespace")
+			require.NoError(t, err)
+
+			containers := common.DatabaseWaiterContainers(ctx)
+			var names []string
+			for _, c := range containers {
+				names = append(names, c.Name)
+			}
+			require.Equal(t, test.Containers, names)
+			require.Equal(t, test.WaiterArgs, containers[0].Args)
+			if len(containers) > 1 {
+				require.Contains(t, containers[1].Command[2], "+ 600 ))")
+				require.Contains(t, containers[1].Command[2], "typeorm migration:show")
+			}
+		})
+	}
+}
+
+func TestStorageConfig_S3(t *testing.T) {
+	ctx, err := common.NewRenderContext(config.Config{
+		Metadata: config.Metadata{Region: "eu-central-1"},
+		ObjectStorage: config.ObjectStorage{
+			S3: &config.ObjectStorageS3{
+				Endpoint:       "s3.eu-central-1.amazonaws.com",
+				BucketName:     "gitpod-",
+				SSEKMSKeyARN:   "arn:aws:kms:eu-central-1:111122223333:key/1234abcd-12ab-34cd-56ef-1234567890ab",
+				ForcePathStyle: true,
+				BucketPerOwner: true,
+			},
+		},
+	}, versions.Manifest{}, "test_namespace")
+	require.NoError(t, err)
+
+	cfg := common.StorageConfig(ctx).S3Config
+	require.Equal(t, "gitpod-", cfg.Bucket)
+	require.Equal(t, "arn:aws:kms:eu-central-1:111122223333:key/1234abcd-12ab-34cd-56ef-1234567890ab", cfg.SSEKMSKeyID)
+	require.True(t, cfg.UsePathStyle)
+	require.True(t, cfg.BucketPerOwner)
+}
+
+func TestWebhookCertificate(t *testing.T) {
+	ctx, err := common.NewRenderContext(config.Config{}, versions.Manifest{}, "test")
+	require.NoError(t, err)
+
+	cert := common.WebhookCertificate(ctx, common.WSManagerMk2Component, "webhook", "webhook-tls")
+	require.Equal(t, common.InternalCertDuration, cert.Spec.Duration)
+	require.Nil(t, cert.Spec.RenewBefore)
+	require.Equal(t, []string{"ws-manager-mk2.test.svc", "ws-manager-mk2.test.svc.cluster.local"}, cert.Spec.DNSNames)
+
+	duration, renewBefore := util.Duration(48*time.Hour), util.Duration(12*time.Hour)
+	ctx, err = common.NewRenderContext(config.Config{
+		WebhookCertificate: &config.WebhookCertificate{Duration: &duration, RenewBefore: &renewBefore},
+	}, versions.Manifest{}, "test")
+	require.NoError(t, err)
+
+	cert = common.WebhookCertificate(ctx, common.WSManagerMk2Component, "webhook", "webhook-tls")
+	require.Equal(t, 48*time.Hour, cert.Spec.Duration.Duration)
+	require.Equal(t, 12*time.Hour, cert.Spec.RenewBefore.Duration)
+}
+
+func TestWorkloadProbes(t *testing.T) {
+	ctx, err := common.NewRenderContext(config.Config{
+		Components: &config.Components{
+			PodConfig: map[string]*config.PodConfig{
+				common.ServerComponent: {
+					Probes: map[string]*config.ContainerProbes{
+						common.ServerComponent: {
+							Readiness: &config.ProbeSettings{TimeoutSeconds: pointer.Int32(5), FailureThreshold: pointer.Int32(12)},
+							Startup:   &config.ProbeSettings{PeriodSeconds: pointer.Int32(5), FailureThreshold: pointer.Int32(60)},
+						},
+					},
+				},
+			},
+		},
+	}, versions.Manifest{}, "test_namespace")
+	require.NoError(t, err)
+
+	handler := corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{Path: "/live"}}
+	pod := func(component string) corev1.PodTemplateSpec {
+		return corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: common.DefaultLabels(component)},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{
+					Name:           common.ServerComponent,
+					LivenessProbe:  &corev1.Probe{ProbeHandler: handler, FailureThreshold: 3},
+					ReadinessProbe: &corev1.Probe{ProbeHandler: corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{Path: "/ready"}}, TimeoutSeconds: 1, FailureThreshold: 3},
+				}, {
+					Name:           "kube-rbac-proxy",
+					ReadinessProbe: &corev1.Probe{TimeoutSeconds: 1},
+				}},
+			},
+		}
+	}
+	server := &appsv1.Deployment{Spec: appsv1.DeploymentSpec{Template: pod(common.ServerComponent)}}
+	proxy := &appsv1.Deployment{Spec: appsv1.DeploymentSpec{Template: pod(common.ProxyComponent)}}
+
+	common.WorkloadProbes(ctx, []runtime.Object{server, proxy})
+
+	container := server.Spec.Template.Spec.Containers[0]
+	require.Equal(t, &corev1.Probe{ProbeHandler: handler, FailureThreshold: 3}, container.LivenessProbe, "probes without settings are unchanged")
+	require.Equal(t, int32(5), container.ReadinessProbe.TimeoutSeconds)
+	require.Equal(t, int32(12), container.ReadinessProbe.FailureThreshold)
+	require.Equal(t, &corev1.Probe{ProbeHandler: handler, PeriodSeconds: 5, FailureThreshold: 60}, container.StartupProbe, "the startup probe checks the liveness")
+	require.Equal(t, int32(1), server.Spec.Template.Spec.Containers[1].ReadinessProbe.TimeoutSeconds, "only the named container is changed")
+	require.Equal(t, pod(common.ProxyComponent), proxy.Spec.Template, "other components are unchanged")
+}
+
+func TestWorkloadEnv(t *testing.T) {
+	envFrom := corev1.EnvFromSource{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "apm"}}}
+	ctx, err := common.NewRenderContext(config.Config{
+		Components: &config.Components{
+			PodConfig: map[string]*config.PodConfig{
+				common.ServerComponent: {
+					Env:     []corev1.EnvVar{{Name: "LOG_LEVEL", Value: "debug"}, {Name: "APM_SERVICE", Value: "server"}},
+					EnvFrom: []corev1.EnvFromSource{envFrom},
+				},
+			},
+		},
+	}, versions.Manifest{}, "test_namespace")
+	require.NoError(t, err)
+
+	pod := func(component string) corev1.PodTemplateSpec {
+		return corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: common.DefaultLabels(component)},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{
+					Name: common.ServerComponent,
+					Env:  []corev1.EnvVar{{Name: "LOG_LEVEL", Value: "info"}, {Name: "PORT", Value: "3000"}},
+				}, {
+					Name: "kube-rbac-proxy",
+				}},
+			},
+		}
+	}
+	server := &appsv1.Deployment{Spec: appsv1.DeploymentSpec{Template: pod(common.ServerComponent)}}
+	proxy := &appsv1.Deployment{Spec: appsv1.DeploymentSpec{Template: pod(common.ProxyComponent)}}
+
+	common.WorkloadEnv(ctx, []runtime.Object{server, proxy, &corev1.ConfigMap{}})
+
+	containers := server.Spec.Template.Spec.Containers
+	require.Equal(t, []corev1.EnvVar{
+		{Name: "PORT", Value: "3000"},
+		{Name: "LOG_LEVEL", Value: "debug"},
+		{Name: "APM_SERVICE", Value: "server"},
+	}, containers[0].Env, "the configured env vars replace those of the same name")
+	require.Equal(t, []corev1.EnvFromSource{envFrom}, containers[0].EnvFrom)
+	require.Equal(t, []corev1.EnvVar{{Name: "LOG_LEVEL", Value: "debug"}, {Name: "APM_SERVICE", Value: "server"}}, containers[1].Env, "every container is changed")
+	require.Equal(t, pod(common.ProxyComponent), proxy.Spec.Template, "other components are unchanged")
+}
+
+func TestWorkloadContainers(t *testing.T) {
+	agent := corev1.Container{
+		Name:         "agent",
+		Image:        "example.com/agent:1.0",
+		VolumeMounts: []corev1.VolumeMount{{Name: "agent-socket", MountPath: "/var/run/agent"}},
+	}
+	volume := corev1.Volume{Name: "agent-socket", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}
+	podConfig := &config.PodConfig{
+		Sidecars:       []corev1.Container{agent},
+		InitContainers: []corev1.Container{{Name: "agent-init", Image: "example.com/agent-init:1.0"}},
+		Volumes:        []corev1.Volume{volume},
+	}
+	ctx, err := common.NewRenderContext(config.Config{
+		Components: &config.Components{PodConfig: map[string]*config.PodConfig{common.ServerComponent: podConfig}},
+	}, versions.Manifest{}, "test_namespace")
+	require.NoError(t, err)
+
+	pod := func(component string) corev1.PodTemplateSpec {
+		return corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: common.DefaultLabels(component)},
+			Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{{Name: "database-waiter"}},
+				Containers:     []corev1.Container{{Name: common.ServerComponent}},
+			},
+		}
+	}
+	server := &appsv1.Deployment{Spec: appsv1.DeploymentSpec{Template: pod(common.ServerComponent)}}
+	proxy := &appsv1.Deployment{Spec: appsv1.DeploymentSpec{Template: pod(common.ProxyComponent)}}
+
+	require.NoError(t, common.WorkloadContainers(ctx, []runtime.Object{server, proxy}))
+
+	spec := server.Spec.Template.Spec
+	require.Equal(t, []corev1.Container{{Name: common.ServerComponent}, agent}, spec.Containers)
+	require.Equal(t, []corev1.Container{{Name: "database-waiter"}, {Name: "agent-init", Image: "example.com/agent-init:1.0"}}, spec.InitContainers)
+	require.Equal(t, []corev1.Volume{volume}, spec.Volumes)
+	require.Equal(t, pod(common.ProxyComponent), proxy.Spec.Template, "other components are unchanged")
+
+	podConfig.Sidecars[0].Name = "database-waiter"
+	server = &appsv1.Deployment{Spec: appsv1.DeploymentSpec{Template: pod(common.ServerComponent)}}
+	require.ErrorContains(t, common.WorkloadContainers(ctx, []runtime.Object{server}), "already have a container database-waiter")
+}
+
+func TestWorkloadSecurity(t *testing.T) {
+	ctx, err := common.NewRenderContext(config.Config{
+		Security: &config.Security{
+			Hardened:               true,
+			OptOut:                 []string{common.UsageComponent},
+			WritableRootFilesystem: []string{common.PublicApiComponent},
+		},
+	}, versions.Manifest{}, "test_namespace")
+	require.NoError(t, err)
+
+	pod := func(component string) corev1.PodTemplateSpec {
+		return corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: common.DefaultLabels(component)},
+			Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{{
+					Name:            "sysctl",
+					SecurityContext: &corev1.SecurityContext{Privileged: pointer.Bool(true)},
+				}},
+				Containers: []corev1.Container{{
+					Name: component,
+					SecurityContext: &corev1.SecurityContext{
+						Capabilities: &corev1.Capabilities{Add: []corev1.Capability{"NET_BIND_SERVICE"}},
+					},
+				}},
+			},
+		}
+	}
+	server := &appsv1.Deployment{Spec: appsv1.DeploymentSpec{Template: pod(common.ServerComponent)}}
+	publicAPI := &appsv1.Deployment{Spec: appsv1.DeploymentSpec{Template: pod(common.PublicApiComponent)}}
+	usage := &appsv1.Deployment{Spec: appsv1.DeploymentSpec{Template: pod(common.UsageComponent)}}
+	registryFacade := &appsv1.DaemonSet{Spec: appsv1.DaemonSetSpec{Template: pod(common.RegistryFacadeComponent)}}
+	redis := &appsv1.Deployment{Spec: appsv1.DeploymentSpec{Template: pod("redis")}}
+	redis.Spec.Template.Spec.SecurityContext = &corev1.PodSecurityContext{RunAsNonRoot: pointer.Bool(false)}
+	redis.Spec.Template.Spec.Containers[0].SecurityContext.RunAsNonRoot = pointer.Bool(false)
+
+	common.WorkloadSecurity(ctx, []runtime.Object{server, publicAPI, usage, registryFacade, redis})
+
+	spec := server.Spec.Template.Spec
+	require.Equal(t, &corev1.PodSecurityContext{
+		RunAsNonRoot:   pointer.Bool(true),
+		SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+	}, spec.SecurityContext)
+	require.Equal(t, &corev1.SecurityContext{
+		Capabilities:             &corev1.Capabilities{Add: []corev1.Capability{"NET_BIND_SERVICE"}, Drop: []corev1.Capability{"ALL"}},
+		AllowPrivilegeEscalation: pointer.Bool(false),
+		ReadOnlyRootFilesystem:   pointer.Bool(true),
+	}, spec.Containers[0].SecurityContext)
+	require.Equal(t, []corev1.VolumeMount{{Name: "hardened-tmp", MountPath: "/tmp"}}, spec.Containers[0].VolumeMounts)
+	require.Len(t, spec.Volumes, 1)
+	require.Equal(t, &corev1.SecurityContext{Privileged: pointer.Bool(true)}, spec.InitContainers[0].SecurityContext, "privileged containers are unchanged")
+
+	spec = publicAPI.Spec.Template.Spec
+	require.Nil(t, spec.Containers[0].SecurityContext.ReadOnlyRootFilesystem)
+	require.Empty(t, spec.Volumes)
+
+	spec = redis.Spec.Template.Spec
+	require.Equal(t, pointer.Bool(true), spec.SecurityContext.RunAsNonRoot, "components that run as root run as non-root")
+	require.Equal(t, pointer.Int64(common.HardenedUser), spec.SecurityContext.RunAsUser)
+	require.Equal(t, pointer.Int64(common.HardenedUser), spec.SecurityContext.RunAsGroup)
+	require.Equal(t, pointer.Bool(true), spec.Containers[0].SecurityContext.RunAsNonRoot)
+	require.Equal(t, pointer.Int64(common.HardenedUser), spec.Containers[0].SecurityContext.RunAsUser)
+
+	require.Equal(t, pod(common.UsageComponent), usage.Spec.Template, "opted out components are unchanged")
+	require.Equal(t, pod(common.RegistryFacadeComponent), registryFacade.Spec.Template, "the node components are unchanged")
+}
+
+func TestWorkloadDNS(t *testing.T) {
+	ctx, err := common.NewRenderContext(config.Config{
+		Network: &config.Network{NodeLocalDNSCache: &config.NodeLocalDNSCache{Enabled: true}},
+	}, versions.Manifest{}, "test_namespace")
+	require.NoError(t, err)
+
+	server := &appsv1.Deployment{}
+	migrations := &batchv1.Job{}
+	custom := &appsv1.DaemonSet{Spec: appsv1.DaemonSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+		DNSConfig: &corev1.PodDNSConfig{Nameservers: []string{"10.0.0.10"}},
+	}}}}
+
+	common.WorkloadDNS(ctx, []runtime.Object{server, migrations, custom})
+
+	expected := &corev1.PodDNSConfig{
+		Nameservers: []string{common.NodeLocalDNSCacheAddress},
+		Searches:    []string{"test_namespace.svc.cluster.local", "svc.cluster.local", "cluster.local"},
+		Options:     []corev1.PodDNSConfigOption{{Name: "ndots", Value: pointer.String("5")}},
+	}
+	for _, spec := range []corev1.PodSpec{server.Spec.Template.Spec, migrations.Spec.Template.Spec} {
+		require.Equal(t, corev1.DNSNone, spec.DNSPolicy)
+		require.Equal(t, expected, spec.DNSConfig)
+	}
+	require.Equal(t, corev1.DNSPolicy(""), custom.Spec.Template.Spec.DNSPolicy, "pods with their own DNS config are unchanged")
+}
+
+func TestWorkloadScrapeAnnotations(t *testing.T) {
+	metricsPod := func(component string) corev1.PodTemplateSpec {
+		return corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: common.DefaultLabels(component)},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{
+				{Name: component},
+				{Name: "kube-rbac-proxy", Ports: []corev1.ContainerPort{{Name: "metrics", ContainerPort: 9500}}},
+			}},
+		}
+	}
+	render := func(target config.ScrapeTarget) (*appsv1.Deployment, *appsv1.Deployment, *corev1.Service) {
+		ctx, err := common.NewRenderContext(config.Config{
+			Observability: config.Observability{
+				ScrapeAnnotations: &config.ScrapeAnnotations{Enabled: true, Target: target},
+				MetricLabels:      map[string]map[string]string{"server": {"team": "webapp"}},
+			},
+		}, versions.Manifest{}, "test_namespace")
+		require.NoError(t, err)
+
+		server := &appsv1.Deployment{Spec: appsv1.DeploymentSpec{Template: metricsPod("server")}}
+		dashboard := &appsv1.Deployment{Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: common.DefaultLabels("dashboard")},
+		}}}
+		service := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Labels: common.DefaultLabels("server")},
+			Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "http", Port: 3000}, {Name: "metrics", Port: 9500}}},
+		}
+		common.WorkloadScrapeAnnotations(ctx, []runtime.Object{server, dashboard, service})
+		return server, dashboard, service
+	}
+
+	expected := map[string]string{
+		"prometheus.io/scrape":     "true",
+		"prometheus.io/port":       "9500",
+		"prometheus.io/path":       "/metrics",
+		"prometheus.io/label-team": "webapp",
+	}
+
+	server, dashboard, service := render("")
+	require.Equal(t, expected, server.Spec.Template.Annotations)
+	require.Empty(t, dashboard.Spec.Template.Annotations, "pods without a metrics port are not scraped")
+	require.Empty(t, service.Annotations)
+
+	server, _, service = render(config.ScrapeTargetService)
+	require.Empty(t, server.Spec.Template.Annotations)
+	require.Equal(t, expected, service.Annotations)
+}
+
+func TestWorkloadServiceMonitors(t *testing.T) {
+	metricsPod := func(component string) corev1.PodTemplateSpec {
+		return corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: common.DefaultLabels(component)},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{
+				{Name: component},
+				{Name: "kube-rbac-proxy", Ports: []corev1.ContainerPort{{Name: "metrics", ContainerPort: 9500}}},
+			}},
+		}
+	}
+	render := func(prometheusOperator bool) []runtime.Object {
+		ctx, err := common.NewRenderContext(config.Config{
+			Observability: config.Observability{PrometheusOperator: prometheusOperator},
+		}, versions.Manifest{}, "test_namespace")
+		require.NoError(t, err)
+
+		return common.WorkloadServiceMonitors(ctx, []runtime.Object{
+			&appsv1.Deployment{Spec: appsv1.DeploymentSpec{Template: metricsPod("server")}},
+			&corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "server", Labels: common.DefaultLabels("server")},
+				Spec: corev1.ServiceSpec{
+					Selector: common.DefaultLabels("server"),
+					Ports:    []corev1.ServicePort{{Name: "http", Port: 3000}},
+				},
+			},
+			&appsv1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "infra"},
+				Spec:       appsv1.DaemonSetSpec{Template: metricsPod("agent-smith")},
+			},
+			&appsv1.Deployment{Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: common.DefaultLabels("dashboard")},
+			}}},
+		})
+	}
+
+	require.Len(t, render(false), 4)
+
+	objs := render(true)
+	require.Len(t, objs, 7, "a ServiceMonitor for each component with a metrics port, and a service for agent-smith")
+	require.Equal(t, []corev1.ServicePort{
+		{Name: "http", Port: 3000},
+		{Name: "metrics", Protocol: corev1.ProtocolTCP, Port: 9500, TargetPort: intstr.FromInt(9500)},
+	}, objs[1].(*corev1.Service).Spec.Ports)
+
+	monitors := make(map[string]string)
+	for _, obj := range objs[4:] {
+		switch o := obj.(type) {
+		case *unstructured.Unstructured:
+			require.Equal(t, "ServiceMonitor", o.GetKind())
+			monitors[o.GetName()] = o.GetNamespace()
+		case *corev1.Service:
+			require.Equal(t, "agent-smith", o.Name)
+			require.Equal(t, "infra", o.Namespace)
+			require.Equal(t, "metrics", o.Spec.Ports[0].Name)
+		}
+	}
+	require.Equal(t, map[string]string{"server": "test_namespace", "agent-smith": "infra"}, monitors)
+}
+
+func TestWorkloadMetricsTLS(t *testing.T) {
+	ctx, err := common.NewRenderContext(config.Config{
+		Observability: config.Observability{MetricsTLS: true},
+	}, versions.Manifest{}, "test_namespace")
+	require.NoError(t, err)
+
+	deployment := func(component string, mounts []corev1.VolumeMount) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: component, Namespace: ctx.Namespace},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: common.DefaultLabels(component)},
+				Spec: corev1.PodSpec{
+					Volumes: common.MetricsTLSVolumes(ctx, component),
+					Containers: []corev1.Container{
+						{Name: component, VolumeMounts: mounts},
+						*common.KubeRBACProxyContainer(ctx),
+					},
+				},
+			}},
+		}
+	}
+	usage := deployment("usage", common.MetricsTLSVolumeMounts(ctx))
+	server := deployment("server", nil)
+	server.Spec.Template.Spec.Volumes = nil
+
+	objs := common.WorkloadMetricsTLS(ctx, []runtime.Object{usage, server})
+	require.Len(t, objs, 4, "a certificate is issued for each component")
+
+	for _, d := range []*appsv1.Deployment{usage, server} {
+		require.Len(t, d.Spec.Template.Spec.Volumes, 1)
+		require.Equal(t, common.MetricsTLSSecretName(d.Name), d.Spec.Template.Spec.Volumes[0].Secret.SecretName)
+	}
+	require.Equal(t, []string{
+		"--logtostderr",
+		"--secure-listen-address=[$(IP)]:9500",
+		"--upstream=https://127.0.0.1:9500/",
+		"--tls-cert-file=/metrics-tls-certs/tls.crt",
+		"--tls-private-key-file=/metrics-tls-certs/tls.key",
+		"--upstream-ca-file=/metrics-tls-certs/ca.crt",
+	}, usage.Spec.Template.Spec.Containers[1].Args)
+	require.Equal(t, []string{
+		"--logtostderr",
+		"--secure-listen-address=[$(IP)]:9500",
+		"--upstream=http://127.0.0.1:9500/",
+		"--tls-cert-file=/metrics-tls-certs/tls.crt",
+		"--tls-private-key-file=/metrics-tls-certs/tls.key",
+	}, server.Spec.Template.Spec.Containers[1].Args, "the metrics of components without TLS are proxied from plain HTTP")
+}
+
+func TestGenerateGrafanaDashboard(t *testing.T) {
+	render := func(cfg config.Config) []runtime.Object {
+		ctx, err := common.NewRenderContext(cfg, versions.Manifest{}, "test_namespace")
+		require.NoError(t, err)
+		objs, err := common.GenerateGrafanaDashboard("server", []common.GrafanaPanel{
+			{Title: "API requests", Unit: "reqps", Queries: []common.GrafanaQuery{{Expr: "sum(rate(gitpod_server_api_calls_total{$selector}[5m]))"}}},
+		})(ctx)
+		require.NoError(t, err)
+		return objs
+	}
+
+	require.Empty(t, render(config.Config{}))
+
+	objs := render(config.Config{Observability: config.Observability{
+		GrafanaDashboards: &config.GrafanaDashboards{Enabled: true, Namespace: "monitoring"},
+	}})
+	require.Len(t, objs, 1)
+	cm := objs[0].(*corev1.ConfigMap)
+	require.Equal(t, "monitoring", cm.Namespace)
+	require.Equal(t, "1", cm.Labels[common.GrafanaDashboardLabel])
+
+	var dashboard struct {
+		UID    string `json:"uid"`
+		Panels []struct {
+			Title   string `json:"title"`
+			Targets []struct {
+				Expr string `json:"expr"`
+			} `json:"targets"`
+		} `json:"panels"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(cm.Data["gitpod-server.json"]), &dashboard))
+	require.Equal(t, "gitpod-server", dashboard.UID)
+	require.Len(t, dashboard.Panels, 3, "the runtime panels are added")
+	require.Equal(t, `sum(rate(gitpod_server_api_calls_total{namespace="test_namespace", pod=~"server-[^-]+(-[^-]+)?"}[5m]))`, dashboard.Panels[0].Targets[0].Expr)
+}
@@ -7,6 +7,7 @@ package common
 import (
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -14,11 +15,16 @@ import (
 
 const (
 	AppName                         = "gitpod"
+	BlobServeComponent              = "blobserve"
 	BlobServeServicePort            = 4000
 	CertManagerCAIssuer             = "gitpod-ca-issuer"
+	CloudSQLProxyComponent          = "cloudsqlproxy"
+	DashboardComponent              = "dashboard"
+	DockerRegistryComponent         = "docker-registry"
 	DockerRegistryURL               = "docker.io"
 	DockerRegistryName              = "registry"
 	GitpodContainerRegistry         = "eu.gcr.io/gitpod-core-dev/build"
+	IDEMetricsComponent             = "ide-metrics"
 	InClusterDbSecret               = "mysql"
 	InClusterMessageQueueName       = "rabbitmq"
 	InClusterMessageQueueTLS        = "messagebus-certificates-secret-core"
@@ -27,11 +33,13 @@ const (
 	KubeRBACProxyTag                = "v0.12.0"
 	MinioServiceAPIPort             = 9000
 	MonitoringChart                 = "monitoring"
+	NodeLabelerComponent            = "node-labeler"
 	ProxyComponent                  = "proxy"
 	ProxyContainerHTTPPort          = 80
 	ProxyContainerHTTPName          = "http"
 	ProxyContainerHTTPSPort         = 443
 	ProxyContainerHTTPSName         = "https"
+	RedisComponent                  = "redis"
 	RegistryAuthSecret              = "builtin-registry-auth"
 	RegistryTLSCertSecret           = "builtin-registry-certs"
 	RegistryFacadeComponent         = "registry-facade"
@@ -40,9 +48,14 @@ const (
 	ServerComponent                 = "server"
 	ServerIAMSessionPort            = 9876
 	ServerInstallationAdminPort     = 9000
+	SpiceDBComponent                = "spicedb"
 	SystemNodeCritical              = "system-node-critical"
+	WorkspaceContainerName          = "workspace"
+	PriorityClassMeta               = "gitpod-meta"
+	PriorityClassWorkspaceInfra     = "gitpod-workspace-infra"
 	PaymentEndpointComponent        = "payment-endpoint"
 	PublicApiComponent              = "public-api-server"
+	PublicApiHTTPServicePort        = 9002
 	UsageComponent                  = "usage"
 	WSManagerComponent              = "ws-manager"
 	WSManagerMk2Component           = "ws-manager-mk2"
@@ -55,6 +68,8 @@ const (
 	ImageBuilderVolumeTLSCerts      = "image-builder-mk3-tls-certs"
 	ImageBuilderTLSSecretWsman      = "image-builder-mk3-wsman-tls"
 	ImageBuilderVolumeTLSCertsWsman = "image-builder-mk3-wsman-tls-certs"
+	ImageBuilderCacheClaim          = "image-builder-cache"
+	ImageBuilderCacheDir            = "/var/cache/bob"
 	DebugNodePort                   = 9229
 	DBCaCertEnvVarName              = "DB_CA_CERT"
 	DBCaFileName                    = "ca.crt"
@@ -64,7 +79,23 @@ const (
 
 	AnnotationConfigChecksum = "gitpod.io/checksum_config"
 
+	// AnnotationSafeToEvict tells the cluster autoscaler whether it may evict a pod to remove its node
+	AnnotationSafeToEvict = "cluster-autoscaler.kubernetes.io/safe-to-evict"
+	// AnnotationEnableDSEviction tells the cluster autoscaler whether it evicts a daemon set's pod
+	// from a node it removes, rather than deleting the node with the pod still running
+	AnnotationEnableDSEviction = "cluster-autoscaler.kubernetes.io/enable-ds-eviction"
+
 	DatabaseConfigMountPath = "/secrets/database-config"
+
+	// FeatureFlagsConfigMap has the static feature flags in the format of ConfigCat, the proxy
+	// serves them from FeatureFlagsFile
+	FeatureFlagsConfigMap = "feature-flags"
+	FeatureFlagsMountPath = "/etc/caddy/feature-flags"
+	FeatureFlagsFile      = FeatureFlagsMountPath + "/config_v5.json"
+
+	DefaultAutoscalingCPUUtilization = 80
+
+	GPUResourceName corev1.ResourceName = "nvidia.com/gpu"
 )
 
 var (
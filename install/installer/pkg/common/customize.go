@@ -82,6 +82,9 @@ func CustomizeEnvvar(ctx *RenderContext, component string, existingEnvvars []cor
 
 	// Get existing as a map so we don't override these
 	for _, e := range existingEnvvars {
+		if e.Name == "LOG_LEVEL" && ctx.Config.Components != nil && ctx.Config.Components.PodConfig[component] != nil && ctx.Config.Components.PodConfig[component].LogLevel != "" {
+			e.Value = string(ctx.Config.Components.PodConfig[component].LogLevel)
+		}
 		// Ensure that existing envvars are first
 		customizeOrder = append(customizeOrder, e.Name)
 		existing[e.Name] = e
@@ -0,0 +1,53 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package common
+
+import (
+	v1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// DebugPortsEnabled returns whether the component exposes its debug and profiling ports. They
+// are exposed if the config does not control them.
+func DebugPortsEnabled(ctx *RenderContext, component string) bool {
+	if ctx.Config.Network == nil || ctx.Config.Network.DebugPorts == nil {
+		return true
+	}
+
+	debug := ctx.Config.Network.DebugPorts
+	if enabled, ok := debug.Components[component]; ok {
+		return enabled
+	}
+	return debug.Enabled
+}
+
+// DebugPortsIngressRules allows the pods in the debug namespace to connect to the debug ports of
+// the component, if they are enabled
+func DebugPortsIngressRules(ctx *RenderContext, component string, ports ...int32) []v1.NetworkPolicyIngressRule {
+	if ctx.Config.Network == nil || ctx.Config.Network.DebugPorts == nil || ctx.Config.Network.DebugPorts.Namespace == "" {
+		return nil
+	}
+	if !DebugPortsEnabled(ctx, component) {
+		return nil
+	}
+
+	rule := v1.NetworkPolicyIngressRule{
+		From: []v1.NetworkPolicyPeer{{
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"kubernetes.io/metadata.name": ctx.Config.Network.DebugPorts.Namespace,
+				},
+			},
+		}},
+	}
+	for _, port := range ports {
+		rule.Ports = append(rule.Ports, v1.NetworkPolicyPort{
+			Protocol: TCPProtocol,
+			Port:     &intstr.IntOrString{IntVal: port},
+		})
+	}
+	return []v1.NetworkPolicyIngressRule{rule}
+}
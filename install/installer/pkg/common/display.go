@@ -22,14 +22,18 @@ var sortOrder = []string{
 	"Namespace",
 	"NetworkPolicy",
 	"ResourceQuota",
+	"PriorityClass",
 	"Issuer",
 	"Certificate",
 	"LimitRange",
 	"PodSecurityPolicy",
+	"SecurityContextConstraints",
 	"PodDisruptionBudget",
 	"ServiceAccount",
 	"Secret",
 	"SecretList",
+	"ExternalSecret",
+	"SealedSecret",
 	"ConfigMap",
 	"StorageClass",
 	"PersistentVolume",
@@ -54,7 +58,12 @@ var sortOrder = []string{
 	"Job",
 	"CronJob",
 	"Ingress",
+	"Route",
 	"APIService",
+	"MutatingWebhookConfiguration",
+	"ValidatingWebhookConfiguration",
+	"ServiceMonitor",
+	"PrometheusRule",
 }
 
 type RuntimeObject struct {
@@ -0,0 +1,92 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package common
+
+import (
+	"fmt"
+	"net"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+)
+
+// NodeLocalDNSCacheAddress is the address the NodeLocal DNSCache listens on by default
+const NodeLocalDNSCacheAddress = "169.254.20.10"
+
+// nodeLocalDNSCacheAddress returns the address of the NodeLocal DNSCache, or an empty string if
+// it is not used
+func nodeLocalDNSCacheAddress(ctx *RenderContext) string {
+	if ctx.Config.Network == nil || ctx.Config.Network.NodeLocalDNSCache == nil || !ctx.Config.Network.NodeLocalDNSCache.Enabled {
+		return ""
+	}
+	if addr := ctx.Config.Network.NodeLocalDNSCache.Address; addr != "" {
+		return addr
+	}
+	return NodeLocalDNSCacheAddress
+}
+
+// NodeLocalDNSConfig returns the DNS config of the pods that resolve names with the NodeLocal
+// DNSCache. The search domains are those the kubelet sets for the pods in the namespace.
+func NodeLocalDNSConfig(ctx *RenderContext) *corev1.PodDNSConfig {
+	addr := nodeLocalDNSCacheAddress(ctx)
+	if addr == "" {
+		return nil
+	}
+
+	return &corev1.PodDNSConfig{
+		Nameservers: []string{addr},
+		Searches: []string{
+			fmt.Sprintf("%s.svc.cluster.local", ctx.Namespace),
+			"svc.cluster.local",
+			"cluster.local",
+		},
+		Options: []corev1.PodDNSConfigOption{
+			{Name: "ndots", Value: pointer.String("5")},
+		},
+	}
+}
+
+// WorkloadDNS points the pods of the workloads at the NodeLocal DNSCache, unless they configure
+// their DNS themselves
+func WorkloadDNS(ctx *RenderContext, objs []runtime.Object) {
+	dnsConfig := NodeLocalDNSConfig(ctx)
+	if dnsConfig == nil {
+		return
+	}
+
+	for _, obj := range objs {
+		template := podTemplate(obj)
+		if template == nil || template.Spec.DNSConfig != nil || template.Spec.DNSPolicy == corev1.DNSNone {
+			continue
+		}
+		template.Spec.DNSPolicy = corev1.DNSNone
+		template.Spec.DNSConfig = dnsConfig.DeepCopy()
+	}
+}
+
+// AllowNodeLocalDNSEgressRules allows the connections to the NodeLocal DNSCache if it is used.
+// The cache listens on an address of the node, so no pod selector matches it.
+func AllowNodeLocalDNSEgressRules(ctx *RenderContext) []v1.NetworkPolicyEgressRule {
+	addr := nodeLocalDNSCacheAddress(ctx)
+	if addr == "" {
+		return nil
+	}
+
+	cidr := addr + "/32"
+	if net.ParseIP(addr).To4() == nil {
+		cidr = addr + "/128"
+	}
+	tcp, udp := corev1.ProtocolTCP, corev1.ProtocolUDP
+	return []v1.NetworkPolicyEgressRule{{
+		Ports: []v1.NetworkPolicyPort{
+			{Protocol: &tcp, Port: &intstr.IntOrString{IntVal: 53}},
+			{Protocol: &udp, Port: &intstr.IntOrString{IntVal: 53}},
+		},
+		To: []v1.NetworkPolicyPeer{{IPBlock: &v1.IPBlock{CIDR: cidr}}},
+	}}
+}
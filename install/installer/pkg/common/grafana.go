@@ -0,0 +1,156 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// GrafanaDashboardLabel is the label the dashboard sidecar of the Grafana chart loads the
+	// dashboards of the config maps by
+	GrafanaDashboardLabel = "grafana_dashboard"
+
+	// GrafanaSelector is replaced with the selector of the component's pods in the queries of
+	// the panels
+	GrafanaSelector = "$selector"
+)
+
+// GrafanaQuery is a query of a dashboard panel
+type GrafanaQuery struct {
+	Expr string
+	// Legend is the legend format of the series, e.g. {{method}}
+	Legend string
+}
+
+// GrafanaPanel is a time series panel of a dashboard
+type GrafanaPanel struct {
+	Title   string
+	Unit    string
+	Queries []GrafanaQuery
+}
+
+// grafanaRuntimePanels are added to the panels of every component, the Go and Node.js clients
+// of Prometheus both export them
+var grafanaRuntimePanels = []GrafanaPanel{
+	{
+		Title:   "Memory usage",
+		Unit:    "bytes",
+		Queries: []GrafanaQuery{{Expr: "process_resident_memory_bytes{$selector}", Legend: "{{pod}}"}},
+	},
+	{
+		Title:   "CPU usage",
+		Unit:    "short",
+		Queries: []GrafanaQuery{{Expr: "rate(process_cpu_seconds_total{$selector}[5m])", Legend: "{{pod}}"}},
+	},
+}
+
+// GenerateGrafanaDashboard renders a config map with a dashboard of the panels and the runtime
+// metrics of the component if the Grafana dashboards are enabled
+func GenerateGrafanaDashboard(component string, panels []GrafanaPanel) RenderFunc {
+	return func(cfg *RenderContext) ([]runtime.Object, error) {
+		dashboards := cfg.Config.Observability.GrafanaDashboards
+		if dashboards == nil || !dashboards.Enabled {
+			return nil, nil
+		}
+
+		namespace := cfg.Namespace
+		if dashboards.Namespace != "" {
+			namespace = dashboards.Namespace
+		}
+
+		dashboard, err := json.MarshalIndent(grafanaDashboard(cfg, component, append(panels, grafanaRuntimePanels...)), "", "  ")
+		if err != nil {
+			return nil, err
+		}
+
+		labels := CustomizeLabel(cfg, component, TypeMetaConfigmap)
+		labels[GrafanaDashboardLabel] = "1"
+
+		return []runtime.Object{&corev1.ConfigMap{
+			TypeMeta: TypeMetaConfigmap,
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        fmt.Sprintf("%s-grafana-dashboard", component),
+				Namespace:   namespace,
+				Labels:      labels,
+				Annotations: CustomizeAnnotation(cfg, component, TypeMetaConfigmap),
+			},
+			Data: map[string]string{
+				fmt.Sprintf("gitpod-%s.json", component): string(dashboard),
+			},
+		}}, nil
+	}
+}
+
+func grafanaDashboard(cfg *RenderContext, component string, panels []GrafanaPanel) map[string]interface{} {
+	// The pods of a deployment or stateful set are named <component>-<hash>-<hash> or
+	// <component>-<ordinal>, those of a daemon set <component>-<hash>. This also works where
+	// the scrape config does not keep the labels of the pods.
+	selector := fmt.Sprintf(`namespace="%s", pod=~"%s-[^-]+(-[^-]+)?"`, cfg.Namespace, component)
+	datasource := map[string]interface{}{
+		"type": "prometheus",
+		"uid":  "${datasource}",
+	}
+
+	res := make([]interface{}, 0, len(panels))
+	for i, p := range panels {
+		targets := make([]interface{}, 0, len(p.Queries))
+		for j, q := range p.Queries {
+			targets = append(targets, map[string]interface{}{
+				"datasource":   datasource,
+				"refId":        string(rune('A' + j)),
+				"expr":         strings.ReplaceAll(q.Expr, GrafanaSelector, selector),
+				"legendFormat": q.Legend,
+			})
+		}
+		res = append(res, map[string]interface{}{
+			"id":         i + 1,
+			"type":       "timeseries",
+			"title":      p.Title,
+			"datasource": datasource,
+			"gridPos": map[string]interface{}{
+				"h": 8,
+				"w": 12,
+				"x": (i % 2) * 12,
+				"y": (i / 2) * 8,
+			},
+			"fieldConfig": map[string]interface{}{
+				"defaults":  map[string]interface{}{"unit": p.Unit},
+				"overrides": []interface{}{},
+			},
+			"targets": targets,
+		})
+	}
+
+	return map[string]interface{}{
+		"uid":           fmt.Sprintf("gitpod-%s", component),
+		"title":         fmt.Sprintf("Gitpod / %s", component),
+		"tags":          []interface{}{"gitpod"},
+		"editable":      true,
+		"schemaVersion": 36,
+		"refresh":       "1m",
+		"time": map[string]interface{}{
+			"from": "now-6h",
+			"to":   "now",
+		},
+		"templating": map[string]interface{}{
+			"list": []interface{}{
+				map[string]interface{}{
+					"name":  "datasource",
+					"label": "Data source",
+					"type":  "datasource",
+					"query": "prometheus",
+				},
+			},
+		},
+		"panels": res,
+	}
+}
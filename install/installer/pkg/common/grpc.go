@@ -0,0 +1,48 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package common
+
+import (
+	"strconv"
+
+	common_grpc "github.com/gitpod-io/gitpod/common-go/grpc"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// GRPCClientConfig returns the overrides of the Go gRPC clients, or nil to keep their defaults
+func GRPCClientConfig(ctx *RenderContext) *common_grpc.ClientConfig {
+	if ctx.Config.Components == nil || ctx.Config.Components.GRPC == nil {
+		return nil
+	}
+	cfg := ctx.Config.Components.GRPC
+
+	res := &common_grpc.ClientConfig{}
+	if cfg.DialTimeout != nil {
+		res.DialTimeout = *cfg.DialTimeout
+	}
+	if cfg.MaxMessageSize != nil {
+		res.MaxMsgSize = int(cfg.MaxMessageSize.Value())
+	}
+	if cfg.Retry != nil {
+		res.Retry = &common_grpc.RetryPolicy{
+			MaxAttempts:    cfg.Retry.MaxAttempts,
+			InitialBackoff: cfg.Retry.InitialBackoff,
+			MaxBackoff:     cfg.Retry.MaxBackoff,
+		}
+	}
+	return res
+}
+
+// GRPCClientEnv sets the size of the largest message the gRPC clients of the TypeScript
+// components receive
+func GRPCClientEnv(ctx *RenderContext) []corev1.EnvVar {
+	if ctx.Config.Components == nil || ctx.Config.Components.GRPC == nil || ctx.Config.Components.GRPC.MaxMessageSize == nil {
+		return nil
+	}
+	return []corev1.EnvVar{{
+		Name:  "GRPC_MAX_RECEIVE_MESSAGE_LENGTH",
+		Value: strconv.FormatInt(ctx.Config.Components.GRPC.MaxMessageSize.Value(), 10),
+	}}
+}
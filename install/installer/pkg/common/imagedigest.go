@@ -0,0 +1,123 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/containerd/containerd/remotes"
+	"github.com/containerd/containerd/remotes/docker"
+	dockerconfig "github.com/docker/cli/cli/config"
+)
+
+const imageDigestTimeout = 30 * time.Second
+
+// ImageDigestResolver returns the digest of the manifest an image reference points to
+type ImageDigestResolver func(ctx context.Context, ref string) (string, error)
+
+// NewRegistryResolver returns a resolver for image registries. Credentials are taken
+// from the local Docker config so that private registries can be used.
+func NewRegistryResolver() remotes.Resolver {
+	cfg := dockerconfig.LoadDefaultConfigFile(io.Discard)
+
+	return docker.NewResolver(docker.ResolverOptions{
+		Hosts: docker.ConfigureDefaultRegistries(
+			docker.WithAuthorizer(docker.NewDockerAuthorizer(
+				docker.WithAuthCreds(func(host string) (string, string, error) {
+					if host == "registry-1.docker.io" {
+						host = "https://index.docker.io/v1/"
+					}
+					auth, err := cfg.GetAuthConfig(host)
+					if err != nil {
+						return "", "", err
+					}
+					if auth.IdentityToken != "" {
+						return "", auth.IdentityToken, nil
+					}
+					return auth.Username, auth.Password, nil
+				}),
+			)),
+		),
+	})
+}
+
+// RegistryImageDigestResolver resolves image digests by querying the registry
+func RegistryImageDigestResolver() ImageDigestResolver {
+	resolver := NewRegistryResolver()
+
+	return func(ctx context.Context, ref string) (string, error) {
+		ctx, cancel := context.WithTimeout(ctx, imageDigestTimeout)
+		defer cancel()
+
+		_, desc, err := resolver.Resolve(ctx, ref)
+		if err != nil {
+			return "", err
+		}
+		return desc.Digest.String(), nil
+	}
+}
+
+// imageDigest is the result of resolving an image reference
+type imageDigest struct {
+	once   sync.Once
+	digest string
+	err    error
+}
+
+// resolveImageDigest returns the digest for the image reference, only asking the
+// resolver once per reference
+func (r *RenderContext) resolveImageDigest(ref string) (string, bool) {
+	r.mu.Lock()
+	if r.imageDigests == nil {
+		r.imageDigests = make(map[string]*imageDigest)
+	}
+	if r.imageDigestResolver == nil {
+		r.imageDigestResolver = RegistryImageDigestResolver()
+	}
+	d, ok := r.imageDigests[ref]
+	if !ok {
+		d = &imageDigest{}
+		r.imageDigests[ref] = d
+	}
+	resolver := r.imageDigestResolver
+	r.mu.Unlock()
+
+	// the other images are resolved in the meantime
+	d.once.Do(func() {
+		d.digest, d.err = resolver(context.Background(), ref)
+	})
+	return d.digest, d.err == nil
+}
+
+// ImageDigestErrors returns an error listing the images that could not be pinned to a
+// digest. ImageName is called while building objects where an error cannot be returned,
+// so this should be checked once rendering has finished.
+func (r *RenderContext) ImageDigestErrors() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var refs []string
+	for ref, d := range r.imageDigests {
+		if d.err != nil {
+			refs = append(refs, ref)
+		}
+	}
+	if len(refs) == 0 {
+		return nil
+	}
+	sort.Strings(refs)
+
+	msg := "cannot pin images to a digest:"
+	for _, ref := range refs {
+		msg += fmt.Sprintf("\n  %s: %v", ref, r.imageDigests[ref].err)
+	}
+	return errors.New(msg)
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package common
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gitpod-io/gitpod/common-go/log"
+	"github.com/opencontainers/go-digest"
+)
+
+// ImageDigestCacheTTL is how long a cached digest is used before the registry is asked again
+const ImageDigestCacheTTL = 24 * time.Hour
+
+// imageDigestCacheEntry is the file a resolved digest is cached in
+type imageDigestCacheEntry struct {
+	Ref        string    `json:"ref"`
+	Digest     string    `json:"digest"`
+	ResolvedAt time.Time `json:"resolvedAt"`
+}
+
+// CachedImageDigestResolver caches the digests the resolver returns in the directory, one
+// file per image reference. A digest is reused for the TTL. Once it has expired the
+// resolver is asked again. With useExpired, the expired digest is used with a warning if
+// the registry cannot be reached, so that a warmed cache also works offline.
+func CachedImageDigestResolver(dir string, ttl time.Duration, useExpired bool, resolver ImageDigestResolver) ImageDigestResolver {
+	return func(ctx context.Context, ref string) (string, error) {
+		fn := filepath.Join(dir, imageDigestCacheKey(ref))
+
+		cached, _ := readImageDigestCache(fn, ref)
+		if cached != nil && time.Since(cached.ResolvedAt) < ttl {
+			return cached.Digest, nil
+		}
+
+		dgst, err := resolver(ctx, ref)
+		if err != nil {
+			if cached != nil && useExpired {
+				log.WithError(err).
+					WithField("image", ref).
+					WithField("age", time.Since(cached.ResolvedAt).Round(time.Second).String()).
+					Warn("cannot resolve the digest of the image, using the expired cached digest")
+				return cached.Digest, nil
+			}
+			return "", err
+		}
+
+		// the cache is best effort, a digest that cannot be stored is resolved again next time
+		_ = writeImageDigestCache(fn, imageDigestCacheEntry{
+			Ref:        ref,
+			Digest:     dgst,
+			ResolvedAt: time.Now(),
+		})
+		return dgst, nil
+	}
+}
+
+// imageDigestCacheKey is the name of the cache file of the reference, which cannot contain
+// the slashes and colons of the reference
+func imageDigestCacheKey(ref string) string {
+	h := sha256.Sum256([]byte(ref))
+	return hex.EncodeToString(h[:]) + ".json"
+}
+
+func readImageDigestCache(fn, ref string) (*imageDigestCacheEntry, error) {
+	fc, err := os.ReadFile(fn)
+	if err != nil {
+		return nil, err
+	}
+
+	var entry imageDigestCacheEntry
+	if err := json.Unmarshal(fc, &entry); err != nil {
+		return nil, err
+	}
+	if entry.Ref != ref {
+		return nil, os.ErrNotExist
+	}
+	if _, err := digest.Parse(entry.Digest); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// writeImageDigestCache writes the entry to a temporary file first, so that renders running
+// at the same time never read a partial entry
+func writeImageDigestCache(fn string, entry imageDigestCacheEntry) error {
+	fc, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(fn)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(fc); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), fn)
+}
+
+// SetImageDigestResolver replaces the resolver the images are pinned to a digest with
+func (r *RenderContext) SetImageDigestResolver(resolver ImageDigestResolver) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.imageDigestResolver = resolver
+}
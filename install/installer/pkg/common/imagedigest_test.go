@@ -0,0 +1,86 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package common
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/pointer"
+
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+	"github.com/gitpod-io/gitpod/installer/pkg/config/versions"
+)
+
+func TestImageNamePinDigests(t *testing.T) {
+	const dgst = "sha256:5b0bcabd1ed22e9fb1310cf6c2dec7cdef19f0ad69efa1f392e94a4333501270"
+
+	ctx, err := NewRenderContext(config.Config{
+		PinImageDigests: pointer.Bool(true),
+	}, versions.Manifest{}, "test_namespace")
+	require.NoError(t, err)
+
+	calls := 0
+	ctx.imageDigestResolver = func(_ context.Context, ref string) (string, error) {
+		calls++
+		if ref == "some-repo.com/missing:v1" {
+			return "", fmt.Errorf("not found")
+		}
+		return dgst, nil
+	}
+
+	require.Equal(t, "some-repo.com/some-image@"+dgst, ctx.ImageName("some-repo.com", "some-image", "v1"))
+	require.Equal(t, "some-repo.com/some-image@"+dgst, ctx.ImageName("some-repo.com", "some-image", "v1"))
+	require.Equal(t, 1, calls, "digest must only be resolved once per image")
+	require.NoError(t, ctx.ImageDigestErrors())
+
+	require.Equal(t, "some-repo.com/missing:v1", ctx.ImageName("some-repo.com", "missing", "v1"))
+	require.EqualError(t, ctx.ImageDigestErrors(), "cannot pin images to a digest:\n  some-repo.com/missing:v1: not found")
+}
+
+func TestCachedImageDigestResolver(t *testing.T) {
+	const (
+		ref  = "some-repo.com/some-image:v1"
+		dgst = "sha256:5b0bcabd1ed22e9fb1310cf6c2dec7cdef19f0ad69efa1f392e94a4333501270"
+	)
+	dir := t.TempDir()
+
+	calls := 0
+	var registryErr error
+	registry := func(_ context.Context, ref string) (string, error) {
+		calls++
+		if registryErr != nil {
+			return "", registryErr
+		}
+		return dgst, nil
+	}
+
+	resolver := CachedImageDigestResolver(dir, time.Hour, true, registry)
+	res, err := resolver(context.Background(), ref)
+	require.NoError(t, err)
+	require.Equal(t, dgst, res)
+
+	res, err = resolver(context.Background(), ref)
+	require.NoError(t, err)
+	require.Equal(t, dgst, res)
+	require.Equal(t, 1, calls, "a cached digest must not be resolved again")
+
+	// an expired digest is resolved again, but still used if the registry cannot be reached
+	registryErr = fmt.Errorf("offline")
+	res, err = CachedImageDigestResolver(dir, 0, true, registry)(context.Background(), ref)
+	require.NoError(t, err)
+	require.Equal(t, dgst, res)
+	require.Equal(t, 2, calls)
+
+	_, err = CachedImageDigestResolver(dir, 0, false, registry)(context.Background(), ref)
+	require.EqualError(t, err, "offline", "the expired digest is not used without useExpired")
+	require.Equal(t, 3, calls)
+
+	_, err = resolver(context.Background(), "some-repo.com/other-image:v1")
+	require.EqualError(t, err, "offline")
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package common
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+)
+
+// imageVariantTag returns the tag of the image in the configured variant. Only Gitpod's images
+// are published in variants, they are told apart from third-party images by their tag being
+// a version of the manifest.
+func (r *RenderContext) imageVariantTag(name, tag string) string {
+	variant := r.Config.ImageVariant
+	if variant == "" || variant == config.ImageVariantDefault {
+		return tag
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.manifestVersions == nil {
+		r.manifestVersions = r.VersionManifest.Components.Versions()
+	}
+	if _, ok := r.manifestVersions[tag]; !ok {
+		return tag
+	}
+
+	if !r.VersionManifest.HasVariant(name, string(variant)) {
+		if r.imageVariantErrors == nil {
+			r.imageVariantErrors = make(map[string]struct{})
+		}
+		// reported by ImageVariantErrors
+		r.imageVariantErrors[name] = struct{}{}
+		return tag
+	}
+	return fmt.Sprintf("%s-%s", tag, variant)
+}
+
+// ImageVariantErrors returns an error listing the images that are not published in the
+// configured variant. Like ImageDigestErrors, this should be checked once rendering has finished.
+func (r *RenderContext) ImageVariantErrors() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.imageVariantErrors) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(r.imageVariantErrors))
+	for name := range r.imageVariantErrors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	msg := fmt.Sprintf("images are not published in the %s variant:", r.Config.ImageVariant)
+	for _, name := range names {
+		msg += fmt.Sprintf("\n  %s", name)
+	}
+	msg += "\nreplace them in components.images or use the default variant"
+	return errors.New(msg)
+}
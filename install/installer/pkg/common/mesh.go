@@ -0,0 +1,58 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package common
+
+import (
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+)
+
+// MeshSidecarAnnotations returns the pod annotations that enable or disable the sidecar of the service mesh
+func MeshSidecarAnnotations(mesh *config.Mesh, inject bool) map[string]string {
+	if mesh == nil {
+		return nil
+	}
+
+	switch mesh.Kind {
+	case config.MeshIstio:
+		if !inject {
+			return map[string]string{"sidecar.istio.io/inject": "false"}
+		}
+		return map[string]string{
+			"sidecar.istio.io/inject": "true",
+			// Have the sidecar answer the kubelet's HTTP probes, which cannot present a mesh certificate
+			"sidecar.istio.io/rewriteAppHTTPProbers": "true",
+			// Components connect to each other on start, which fails until the sidecar is ready
+			"proxy.istio.io/config": `{"holdApplicationUntilProxyStarts":true}`,
+		}
+	case config.MeshLinkerd:
+		if !inject {
+			return map[string]string{"linkerd.io/inject": "disabled"}
+		}
+		return map[string]string{"linkerd.io/inject": "enabled"}
+	}
+	return nil
+}
+
+// MeshControlPlaneNamespace returns the namespace of the service mesh control plane
+func MeshControlPlaneNamespace(mesh *config.Mesh) string {
+	if mesh.ControlPlaneNamespace != "" {
+		return mesh.ControlPlaneNamespace
+	}
+	if mesh.Kind == config.MeshLinkerd {
+		return "linkerd"
+	}
+	return "istio-system"
+}
+
+// MeshControlPlanePorts returns the ports of the service mesh control plane that the sidecars
+// connect to, for the certificates and the proxy configuration
+func MeshControlPlanePorts(mesh *config.Mesh) []int32 {
+	if mesh.Kind == config.MeshLinkerd {
+		// identity, destination and policy
+		return []int32{8080, 8086, 8090}
+	}
+	// xDS and CA of istiod
+	return []int32{15012}
+}
@@ -0,0 +1,188 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package common
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/gitpod-io/gitpod/common-go/baseserver"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	MetricsTLSVolume    = "metrics-tls-certs"
+	MetricsTLSMountPath = "/metrics-tls-certs"
+
+	kubeRBACProxyContainer = "kube-rbac-proxy"
+)
+
+// MetricsTLSSecretName is the secret of the certificate the metrics of the component are served with
+func MetricsTLSSecretName(component string) string {
+	return fmt.Sprintf("%s-metrics-tls", component)
+}
+
+// BaseserverBuiltin configures the builtin debug and metrics servers of baseserver, which are
+// served over TLS if the installation requires it
+func BaseserverBuiltin(ctx *RenderContext) *baseserver.BuiltinConfiguration {
+	if !ctx.Config.Observability.MetricsTLS {
+		return nil
+	}
+	return &baseserver.BuiltinConfiguration{
+		TLS: &baseserver.TLSConfiguration{
+			CAPath:   filepath.Join(MetricsTLSMountPath, "ca.crt"),
+			CertPath: filepath.Join(MetricsTLSMountPath, "tls.crt"),
+			KeyPath:  filepath.Join(MetricsTLSMountPath, "tls.key"),
+		},
+	}
+}
+
+// MetricsTLSVolumes is the certificate volume of the pods of a component that serves its
+// builtin servers over TLS
+func MetricsTLSVolumes(ctx *RenderContext, component string) []corev1.Volume {
+	if !ctx.Config.Observability.MetricsTLS {
+		return nil
+	}
+	return []corev1.Volume{metricsTLSVolume(component)}
+}
+
+// MetricsTLSVolumeMounts mounts the certificate of the builtin servers
+func MetricsTLSVolumeMounts(ctx *RenderContext) []corev1.VolumeMount {
+	if !ctx.Config.Observability.MetricsTLS {
+		return nil
+	}
+	return []corev1.VolumeMount{metricsTLSVolumeMount()}
+}
+
+func metricsTLSVolume(component string) corev1.Volume {
+	return corev1.Volume{
+		Name: MetricsTLSVolume,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{SecretName: MetricsTLSSecretName(component)},
+		},
+	}
+}
+
+func metricsTLSVolumeMount() corev1.VolumeMount {
+	return corev1.VolumeMount{
+		Name:      MetricsTLSVolume,
+		MountPath: MetricsTLSMountPath,
+		ReadOnly:  true,
+	}
+}
+
+// WorkloadMetricsTLS has the kube-rbac-proxy of the pods serve the metrics over TLS, and returns
+// the certificates it is served with. A component serves its own metrics over TLS if it mounts
+// the certificate, which kube-rbac-proxy then verifies.
+func WorkloadMetricsTLS(ctx *RenderContext, objs []runtime.Object) []runtime.Object {
+	if !ctx.Config.Observability.MetricsTLS {
+		return objs
+	}
+
+	issued := make(map[string]struct{})
+	var certificates []runtime.Object
+	for _, obj := range objs {
+		template := podTemplate(obj)
+		if template == nil {
+			continue
+		}
+
+		var (
+			proxy       *corev1.Container
+			tlsUpstream bool
+		)
+		for i := range template.Spec.Containers {
+			c := &template.Spec.Containers[i]
+			if c.Name == kubeRBACProxyContainer {
+				proxy = c
+				continue
+			}
+			for _, m := range c.VolumeMounts {
+				if m.Name == MetricsTLSVolume {
+					tlsUpstream = true
+				}
+			}
+		}
+		if proxy == nil {
+			continue
+		}
+
+		component := template.Labels["component"]
+		secureKubeRBACProxy(proxy, tlsUpstream)
+		if !hasVolume(template.Spec.Volumes, MetricsTLSVolume) {
+			template.Spec.Volumes = append(template.Spec.Volumes, metricsTLSVolume(component))
+		}
+
+		namespace := ctx.Namespace
+		if o, ok := obj.(metav1.Object); ok && o.GetNamespace() != "" {
+			namespace = o.GetNamespace()
+		}
+		key := namespace + "/" + component
+		if _, ok := issued[key]; ok {
+			continue
+		}
+		issued[key] = struct{}{}
+
+		cert := InternalCertificate(ctx, component, MetricsTLSSecretName(component), MetricsTLSSecretName(component), []string{
+			component,
+			fmt.Sprintf("%s.%s.svc", component, namespace),
+			"localhost",
+		})
+		cert.Namespace = namespace
+		// kube-rbac-proxy connects to the metrics of the component by its loopback address
+		cert.Spec.IPAddresses = []string{"127.0.0.1"}
+		certificates = append(certificates, cert)
+	}
+
+	return append(objs, certificates...)
+}
+
+// secureKubeRBACProxy listens on the metrics port with TLS and, if the component serves its
+// metrics over TLS, connects to them with TLS as well
+func secureKubeRBACProxy(proxy *corev1.Container, tlsUpstream bool) {
+	args := make([]string, 0, len(proxy.Args)+3)
+	for _, arg := range proxy.Args {
+		switch {
+		case strings.HasPrefix(arg, "--insecure-listen-address="):
+			arg = "--secure-listen-address=" + strings.TrimPrefix(arg, "--insecure-listen-address=")
+		case strings.HasPrefix(arg, "--upstream=http://") && tlsUpstream:
+			arg = "--upstream=https://" + strings.TrimPrefix(arg, "--upstream=http://")
+		}
+		args = append(args, arg)
+	}
+	args = append(args,
+		"--tls-cert-file="+filepath.Join(MetricsTLSMountPath, "tls.crt"),
+		"--tls-private-key-file="+filepath.Join(MetricsTLSMountPath, "tls.key"),
+	)
+	if tlsUpstream {
+		args = append(args, "--upstream-ca-file="+filepath.Join(MetricsTLSMountPath, "ca.crt"))
+	}
+	proxy.Args = args
+
+	if !hasVolumeMount(proxy.VolumeMounts, MetricsTLSVolume) {
+		proxy.VolumeMounts = append(proxy.VolumeMounts, metricsTLSVolumeMount())
+	}
+}
+
+func hasVolume(volumes []corev1.Volume, name string) bool {
+	for _, v := range volumes {
+		if v.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func hasVolumeMount(mounts []corev1.VolumeMount, name string) bool {
+	for _, m := range mounts {
+		if m.Name == name {
+			return true
+		}
+	}
+	return false
+}
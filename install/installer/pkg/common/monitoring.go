@@ -0,0 +1,306 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package common
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gitpod-io/gitpod/common-go/baseserver"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// The Prometheus Operator types are not a dependency of the installer, so the
+// monitoring resources are rendered as unstructured objects
+
+// WorkloadServiceMonitors renders a ServiceMonitor for every component whose pods expose the
+// metrics port, if the Prometheus Operator integration is enabled. The metrics port is added
+// to the component's service if it does not expose it, and a service is added for the
+// components that have none.
+func WorkloadServiceMonitors(ctx *RenderContext, objs []runtime.Object) []runtime.Object {
+	if !ctx.Config.Observability.PrometheusOperator {
+		return objs
+	}
+
+	services := make(map[string]*corev1.Service)
+	for _, obj := range objs {
+		if s, ok := obj.(*corev1.Service); ok && s.Spec.Selector["component"] != "" {
+			services[objectNamespace(ctx, s)+"/"+s.Spec.Selector["component"]] = s
+		}
+	}
+
+	monitored := make(map[string]struct{})
+	var res []runtime.Object
+	for _, obj := range objs {
+		template := podTemplate(obj)
+		if template == nil {
+			continue
+		}
+		port := metricsPort(template)
+		component := template.Labels["component"]
+		if port == 0 || component == "" {
+			continue
+		}
+
+		namespace := objectNamespace(ctx, obj)
+		key := namespace + "/" + component
+		if _, ok := monitored[key]; ok {
+			continue
+		}
+		monitored[key] = struct{}{}
+
+		if service, ok := services[key]; ok {
+			addMetricsServicePort(service, port)
+		} else {
+			service := metricsService(ctx, component, port)
+			service.Namespace = namespace
+			res = append(res, service)
+		}
+		res = append(res, serviceMonitor(ctx, component, namespace))
+	}
+
+	return append(objs, res...)
+}
+
+// metricsPort returns the metrics port the containers of the pod expose, or 0 if there is none
+func metricsPort(template *corev1.PodTemplateSpec) int32 {
+	for _, c := range template.Spec.Containers {
+		for _, p := range c.Ports {
+			if p.Name == baseserver.BuiltinMetricsPortName {
+				return p.ContainerPort
+			}
+		}
+	}
+	return 0
+}
+
+func objectNamespace(ctx *RenderContext, obj runtime.Object) string {
+	if o, ok := obj.(metav1.Object); ok && o.GetNamespace() != "" {
+		return o.GetNamespace()
+	}
+	return ctx.Namespace
+}
+
+func addMetricsServicePort(service *corev1.Service, port int32) {
+	for _, p := range service.Spec.Ports {
+		if p.Name == baseserver.BuiltinMetricsPortName {
+			return
+		}
+	}
+	service.Spec.Ports = append(service.Spec.Ports, corev1.ServicePort{
+		Protocol:   *TCPProtocol,
+		Name:       baseserver.BuiltinMetricsPortName,
+		Port:       port,
+		TargetPort: intstr.IntOrString{IntVal: port},
+	})
+}
+
+// metricsService is the service of a component that has no other, which only exposes its
+// metrics port
+func metricsService(ctx *RenderContext, component string, port int32) *corev1.Service {
+	// GenerateService cannot fail
+	objs, _ := GenerateService(component, []ServicePort{{
+		Name:          baseserver.BuiltinMetricsPortName,
+		ContainerPort: port,
+		ServicePort:   port,
+	}})(ctx)
+	return objs[0].(*corev1.Service)
+}
+
+// serviceMonitor renders a ServiceMonitor that scrapes the metrics port of the component's
+// service
+func serviceMonitor(ctx *RenderContext, component, namespace string) *unstructured.Unstructured {
+	endpoint := map[string]interface{}{
+		"port":     baseserver.BuiltinMetricsPortName,
+		"interval": "60s",
+	}
+	if relabelings := metricRelabelings(ctx, component); len(relabelings) > 0 {
+		endpoint["relabelings"] = relabelings
+	}
+	if ctx.Config.Observability.MetricsTLS {
+		endpoint["scheme"] = "https"
+		endpoint["tlsConfig"] = map[string]interface{}{
+			"ca": map[string]interface{}{
+				"configMap": map[string]interface{}{
+					"name": "gitpod-ca",
+					"key":  "gitpod-ca.crt",
+				},
+			},
+			"serverName": fmt.Sprintf("%s.%s.svc", component, namespace),
+		}
+	}
+
+	obj := newMonitoringObject(ctx, component, TypeMetaServiceMonitor)
+	obj.SetNamespace(namespace)
+	obj.Object["spec"] = map[string]interface{}{
+		"selector": map[string]interface{}{
+			"matchLabels": stringMapToInterface(DefaultLabels(component)),
+		},
+		"namespaceSelector": map[string]interface{}{
+			"matchNames": []interface{}{namespace},
+		},
+		"endpoints": []interface{}{endpoint},
+	}
+
+	return obj
+}
+
+// metricRelabelings set the metric labels of the component on its targets
+func metricRelabelings(cfg *RenderContext, component string) []interface{} {
+	labels := cfg.Config.Observability.MetricLabels[component]
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var res []interface{}
+	for _, name := range names {
+		res = append(res, map[string]interface{}{
+			"targetLabel": name,
+			"replacement": labels[name],
+		})
+	}
+	return res
+}
+
+// ScrapeAnnotations are the annotations that have Prometheus scrape the metrics port of the
+// component, with the metric labels of the component as hints for the relabeling
+func ScrapeAnnotations(cfg *RenderContext, component string, port int32) map[string]string {
+	res := map[string]string{
+		"prometheus.io/scrape": "true",
+		"prometheus.io/port":   fmt.Sprintf("%d", port),
+		"prometheus.io/path":   "/metrics",
+	}
+	if cfg.Config.Observability.MetricsTLS {
+		res["prometheus.io/scheme"] = "https"
+	}
+	for name, value := range cfg.Config.Observability.MetricLabels[component] {
+		res[config.ScrapeLabelAnnotation(name)] = value
+	}
+	return res
+}
+
+// WorkloadScrapeAnnotations sets the scrape annotations on the pods with a metrics port, or on
+// the services with one if they are the target
+func WorkloadScrapeAnnotations(ctx *RenderContext, objs []runtime.Object) {
+	scrape := ctx.Config.Observability.ScrapeAnnotations
+	if scrape == nil || !scrape.Enabled {
+		return
+	}
+
+	for _, obj := range objs {
+		var (
+			component string
+			port      int32
+			meta      *metav1.ObjectMeta
+		)
+		switch o := obj.(type) {
+		case *corev1.Service:
+			if scrape.Target != config.ScrapeTargetService {
+				continue
+			}
+			for _, p := range o.Spec.Ports {
+				if p.Name == baseserver.BuiltinMetricsPortName {
+					port = p.Port
+				}
+			}
+			component, meta = o.Labels["component"], &o.ObjectMeta
+		case *appsv1.Deployment, *appsv1.StatefulSet, *appsv1.DaemonSet:
+			if scrape.Target == config.ScrapeTargetService {
+				continue
+			}
+			template := podTemplate(obj)
+			port = metricsPort(template)
+			component, meta = template.Labels["component"], &template.ObjectMeta
+		}
+		if port == 0 {
+			continue
+		}
+
+		if meta.Annotations == nil {
+			meta.Annotations = make(map[string]string)
+		}
+		for k, v := range ScrapeAnnotations(ctx, component, port) {
+			meta.Annotations[k] = v
+		}
+	}
+}
+
+// PrometheusRule is a single alerting rule of a PrometheusRule resource
+type PrometheusRule struct {
+	Alert       string
+	Expr        string
+	For         string
+	Severity    string
+	Summary     string
+	Description string
+}
+
+// GeneratePrometheusRule renders a PrometheusRule with the given alerts if the Prometheus
+// Operator integration is enabled
+func GeneratePrometheusRule(component string, rules []PrometheusRule) RenderFunc {
+	return func(cfg *RenderContext) ([]runtime.Object, error) {
+		if !cfg.Config.Observability.PrometheusOperator || len(rules) == 0 {
+			return nil, nil
+		}
+
+		var alerts []interface{}
+		for _, r := range rules {
+			alerts = append(alerts, map[string]interface{}{
+				"alert": r.Alert,
+				"expr":  r.Expr,
+				"for":   r.For,
+				"labels": map[string]interface{}{
+					"severity": r.Severity,
+				},
+				"annotations": map[string]interface{}{
+					"summary":     r.Summary,
+					"description": r.Description,
+				},
+			})
+		}
+
+		obj := newMonitoringObject(cfg, component, TypeMetaPrometheusRule)
+		obj.Object["spec"] = map[string]interface{}{
+			"groups": []interface{}{
+				map[string]interface{}{
+					"name":  component,
+					"rules": alerts,
+				},
+			},
+		}
+
+		return []runtime.Object{obj}, nil
+	}
+}
+
+func newMonitoringObject(cfg *RenderContext, component string, typeMeta metav1.TypeMeta) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(typeMeta.APIVersion)
+	obj.SetKind(typeMeta.Kind)
+	obj.SetName(component)
+	obj.SetNamespace(cfg.Namespace)
+	obj.SetLabels(CustomizeLabel(cfg, component, typeMeta))
+	if annotations := CustomizeAnnotation(cfg, component, typeMeta); len(annotations) > 0 {
+		obj.SetAnnotations(annotations)
+	}
+
+	return obj
+}
+
+func stringMapToInterface(in map[string]string) map[string]interface{} {
+	res := make(map[string]interface{}, len(in))
+	for k, v := range in {
+		res[k] = v
+	}
+	return res
+}
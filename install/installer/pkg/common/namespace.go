@@ -0,0 +1,78 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package common
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// InfrastructureNamespace returns the namespace ws-daemon, registry-facade and agent-smith run in
+func InfrastructureNamespace(ctx *RenderContext) string {
+	if ns := ctx.Config.Workspace.InfrastructureNamespace; ns != "" {
+		return ns
+	}
+	return ctx.Namespace
+}
+
+// SeparateInfrastructureNamespace returns true if ws-daemon, registry-facade and agent-smith run
+// in a namespace other than the one of the installation
+func SeparateInfrastructureNamespace(ctx *RenderContext) bool {
+	return InfrastructureNamespace(ctx) != ctx.Namespace
+}
+
+// InInfrastructureNamespace moves the objects that the render functions put in the namespace of
+// the installation to the infrastructure namespace. The objects of other namespaces are kept
+// where they are, so the objects that must stay in the installation namespace are rendered
+// outside of it.
+func InInfrastructureNamespace(f ...RenderFunc) RenderFunc {
+	render := CompositeRenderFunc(f...)
+	return func(ctx *RenderContext) ([]runtime.Object, error) {
+		objs, err := render(ctx)
+		if err != nil || !SeparateInfrastructureNamespace(ctx) {
+			return objs, err
+		}
+
+		namespace := InfrastructureNamespace(ctx)
+		for _, o := range objs {
+			if obj, ok := o.(metav1.Object); ok && obj.GetNamespace() == ctx.Namespace {
+				obj.SetNamespace(namespace)
+			}
+		}
+		return objs, nil
+	}
+}
+
+// InfrastructureNamespaceObject renders the infrastructure namespace, if it is separate
+func InfrastructureNamespaceObject(ctx *RenderContext) ([]runtime.Object, error) {
+	if !SeparateInfrastructureNamespace(ctx) {
+		return nil, nil
+	}
+
+	return []runtime.Object{&corev1.Namespace{
+		TypeMeta: TypeMetaNamespace,
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   InfrastructureNamespace(ctx),
+			Labels: map[string]string{"app": AppName},
+		},
+	}}, nil
+}
+
+// NamespacePodPeer selects the pods with the labels in a namespace from a NetworkPolicy in
+// policyNamespace. The namespace is only selected if it is another one, so that the policies
+// of an installation in a single namespace stay the same.
+func NamespacePodPeer(policyNamespace, podNamespace string, labels map[string]string) v1.NetworkPolicyPeer {
+	peer := v1.NetworkPolicyPeer{
+		PodSelector: &metav1.LabelSelector{MatchLabels: labels},
+	}
+	if podNamespace != policyNamespace {
+		peer.NamespaceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{
+			"kubernetes.io/metadata.name": podNamespace,
+		}}
+	}
+	return peer
+}
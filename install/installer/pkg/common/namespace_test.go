@@ -0,0 +1,70 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package common_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+	"github.com/gitpod-io/gitpod/installer/pkg/config/versions"
+)
+
+func TestInInfrastructureNamespace(t *testing.T) {
+	render := common.InInfrastructureNamespace(func(ctx *common.RenderContext) ([]runtime.Object, error) {
+		return []runtime.Object{
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "installation", Namespace: ctx.Namespace}},
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "secrets", Namespace: common.WorkspaceSecretsNamespace}},
+		}, nil
+	})
+
+	tests := []struct {
+		Name                    string
+		InfrastructureNamespace string
+		Expectation             []string
+		ExpectNamespace         bool
+	}{
+		{
+			Name:        "unset",
+			Expectation: []string{"test_namespace", common.WorkspaceSecretsNamespace},
+		},
+		{
+			Name:                    "installation namespace",
+			InfrastructureNamespace: "test_namespace",
+			Expectation:             []string{"test_namespace", common.WorkspaceSecretsNamespace},
+		},
+		{
+			Name:                    "separate",
+			InfrastructureNamespace: "gitpod-infra",
+			Expectation:             []string{"gitpod-infra", common.WorkspaceSecretsNamespace},
+			ExpectNamespace:         true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			cfg := config.Config{Workspace: config.Workspace{InfrastructureNamespace: test.InfrastructureNamespace}}
+			ctx, err := common.NewRenderContext(cfg, versions.Manifest{}, "test_namespace")
+			require.NoError(t, err)
+
+			objects, err := render(ctx)
+			require.NoError(t, err)
+
+			var namespaces []string
+			for _, o := range objects {
+				namespaces = append(namespaces, o.(metav1.Object).GetNamespace())
+			}
+			require.Equal(t, test.Expectation, namespaces)
+
+			namespace, err := common.InfrastructureNamespaceObject(ctx)
+			require.NoError(t, err)
+			require.Equal(t, test.ExpectNamespace, len(namespace) == 1)
+		})
+	}
+}
@@ -5,12 +5,234 @@
 package common
 
 import (
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
 )
 
+// StrictNetworkPolicy returns true if all traffic that is not explicitly allowed is denied
+func StrictNetworkPolicy(ctx *RenderContext) bool {
+	return ctx.Config.Network != nil && ctx.Config.Network.Policy == config.NetworkPolicyStrict
+}
+
+// NetworkPolicyTypes returns the policy types of a component's NetworkPolicy. The egress
+// of a component is only restricted in strict mode.
+func NetworkPolicyTypes(ctx *RenderContext) []v1.PolicyType {
+	if StrictNetworkPolicy(ctx) {
+		return []v1.PolicyType{v1.PolicyTypeIngress, v1.PolicyTypeEgress}
+	}
+	return []v1.PolicyType{v1.PolicyTypeIngress}
+}
+
+// NetworkPolicyEgress returns the egress rules of a component's NetworkPolicy in strict mode:
+// DNS and the rules of the peers the component connects to.
+func NetworkPolicyEgress(ctx *RenderContext, rules ...v1.NetworkPolicyEgressRule) []v1.NetworkPolicyEgressRule {
+	if !StrictNetworkPolicy(ctx) {
+		return nil
+	}
+
+	res := append([]v1.NetworkPolicyEgressRule{AllowKubeDnsEgressRule()}, AllowNodeLocalDNSEgressRules(ctx)...)
+	return append(res, rules...)
+}
+
+// installationNamespacesSelector selects the namespace of the installation and the
+// infrastructure namespace, whichever of the two a policy is in
+func installationNamespacesSelector(ctx *RenderContext) *metav1.LabelSelector {
+	namespaces := []string{ctx.Namespace}
+	if SeparateInfrastructureNamespace(ctx) {
+		namespaces = append(namespaces, InfrastructureNamespace(ctx))
+	}
+	return &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{
+		Key:      "kubernetes.io/metadata.name",
+		Operator: metav1.LabelSelectorOpIn,
+		Values:   namespaces,
+	}}}
+}
+
+// DefaultDenyNetworkPolicy denies all traffic in the namespace, and in a separate infrastructure
+// namespace, in strict mode, so only the traffic allowed by the components' NetworkPolicies is possible
+func DefaultDenyNetworkPolicy(ctx *RenderContext) ([]runtime.Object, error) {
+	if !StrictNetworkPolicy(ctx) {
+		return nil, nil
+	}
+
+	namespaces := []string{ctx.Namespace}
+	if SeparateInfrastructureNamespace(ctx) {
+		namespaces = append(namespaces, InfrastructureNamespace(ctx))
+	}
+
+	var res []runtime.Object
+	for _, namespace := range namespaces {
+		res = append(res, &v1.NetworkPolicy{
+			TypeMeta: TypeMetaNetworkPolicy,
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "default-deny",
+				Namespace: namespace,
+				Labels:    map[string]string{"app": AppName},
+			},
+			Spec: v1.NetworkPolicySpec{
+				PodSelector: metav1.LabelSelector{},
+				PolicyTypes: []v1.PolicyType{v1.PolicyTypeIngress, v1.PolicyTypeEgress},
+			},
+		})
+	}
+	return res, nil
+}
+
+// HelmDependencyNetworkPolicy allows the Gitpod components to reach the pods of an in-cluster
+// Helm dependency, such as the database, in strict mode. The pods may also reach each other, and
+// the peers of the rules.
+func HelmDependencyNetworkPolicy(component string, podLabels map[string]string, rules ...v1.NetworkPolicyEgressRule) RenderFunc {
+	return func(ctx *RenderContext) ([]runtime.Object, error) {
+		if !StrictNetworkPolicy(ctx) {
+			return nil, nil
+		}
+
+		from := []v1.NetworkPolicyPeer{{
+			PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": AppName}},
+		}, {
+			PodSelector: &metav1.LabelSelector{MatchLabels: podLabels},
+		}}
+		if SeparateInfrastructureNamespace(ctx) {
+			// the Gitpod components of the infrastructure namespace, eg registry-facade pulling from the in-cluster registry
+			from = append(from, NamespacePodPeer(ctx.Namespace, InfrastructureNamespace(ctx), map[string]string{"app": AppName}))
+		}
+
+		return []runtime.Object{&v1.NetworkPolicy{
+			TypeMeta: TypeMetaNetworkPolicy,
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      component,
+				Namespace: ctx.Namespace,
+				Labels:    DefaultLabels(component),
+			},
+			Spec: v1.NetworkPolicySpec{
+				PodSelector: metav1.LabelSelector{MatchLabels: podLabels},
+				PolicyTypes: NetworkPolicyTypes(ctx),
+				Egress:      NetworkPolicyEgress(ctx, append([]v1.NetworkPolicyEgressRule{allowHelmDependencyEgressRule(ctx, podLabels)}, rules...)...),
+				Ingress: []v1.NetworkPolicyIngressRule{{
+					From: from,
+				}},
+			},
+		}}, nil
+	}
+}
+
+// AllowComponentsEgressRule allows traffic to the pods of the Gitpod components, which may be in
+// the namespace of the installation or in the infrastructure namespace
+func AllowComponentsEgressRule(ctx *RenderContext, components ...string) v1.NetworkPolicyEgressRule {
+	var to []v1.NetworkPolicyPeer
+	for _, component := range components {
+		to = append(to, v1.NetworkPolicyPeer{
+			PodSelector:       &metav1.LabelSelector{MatchLabels: DefaultLabels(component)},
+			NamespaceSelector: installationNamespacesSelector(ctx),
+		})
+	}
+	return v1.NetworkPolicyEgressRule{To: to}
+}
+
+// allowHelmDependencyEgressRule allows traffic to the pods of an in-cluster Helm dependency,
+// which are in the namespace of the installation
+func allowHelmDependencyEgressRule(ctx *RenderContext, podLabels map[string]string) v1.NetworkPolicyEgressRule {
+	return v1.NetworkPolicyEgressRule{
+		To: []v1.NetworkPolicyPeer{{
+			PodSelector: &metav1.LabelSelector{MatchLabels: podLabels},
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{
+				"kubernetes.io/metadata.name": ctx.Namespace,
+			}},
+		}},
+	}
+}
+
+// AllowDatabaseEgressRule allows traffic to the in-cluster database, the Cloud SQL proxy or an
+// external database
+func AllowDatabaseEgressRule(ctx *RenderContext) v1.NetworkPolicyEgressRule {
+	switch {
+	case pointer.BoolDeref(ctx.Config.Database.InCluster, false):
+		return allowHelmDependencyEgressRule(ctx, map[string]string{"app.kubernetes.io/name": "mysql"})
+	case ctx.Config.Database.CloudSQL != nil:
+		return AllowComponentsEgressRule(ctx, CloudSQLProxyComponent)
+	}
+	return AllowExternalEgressRule()
+}
+
+// AllowObjectStorageEgressRule allows traffic to the in-cluster or an external object storage
+func AllowObjectStorageEgressRule(ctx *RenderContext) v1.NetworkPolicyEgressRule {
+	if pointer.BoolDeref(ctx.Config.ObjectStorage.InCluster, false) {
+		return allowHelmDependencyEgressRule(ctx, map[string]string{"app.kubernetes.io/name": "minio"})
+	}
+	return AllowExternalEgressRule()
+}
+
+// AllowContainerRegistryEgressRule allows traffic to the in-cluster or an external container registry
+func AllowContainerRegistryEgressRule(ctx *RenderContext) v1.NetworkPolicyEgressRule {
+	if pointer.BoolDeref(ctx.Config.ContainerRegistry.InCluster, false) {
+		return allowHelmDependencyEgressRule(ctx, DefaultLabels(DockerRegistryComponent))
+	}
+	return AllowExternalEgressRule()
+}
+
+// AllowRedisEgressRule allows traffic to the in-cluster or an external Redis
+func AllowRedisEgressRule(ctx *RenderContext) v1.NetworkPolicyEgressRule {
+	if ctx.Config.Redis == nil || pointer.BoolDeref(ctx.Config.Redis.InCluster, true) {
+		return AllowComponentsEgressRule(ctx, RedisComponent)
+	}
+	return AllowExternalEgressRule()
+}
+
+// AllowSpiceDBEgressRule allows traffic to the in-cluster or an external SpiceDB
+func AllowSpiceDBEgressRule(ctx *RenderContext) v1.NetworkPolicyEgressRule {
+	if ctx.Config.Components != nil && ctx.Config.Components.SpiceDB != nil && ctx.Config.Components.SpiceDB.External != nil {
+		return AllowExternalEgressRule()
+	}
+	return AllowComponentsEgressRule(ctx, SpiceDBComponent)
+}
+
+// AllowMessageBusEgressRule allows traffic to the in-cluster RabbitMQ
+func AllowMessageBusEgressRule(ctx *RenderContext) v1.NetworkPolicyEgressRule {
+	return allowHelmDependencyEgressRule(ctx, map[string]string{"app.kubernetes.io/name": "rabbitmq"})
+}
+
+// AllowExternalEgressRule allows traffic to any address, eg for databases, object storage and
+// container registries outside the cluster. The reserved VM metadata IPs of IPv4 and IPv6
+// are excluded.
+func AllowExternalEgressRule() v1.NetworkPolicyEgressRule {
+	return v1.NetworkPolicyEgressRule{
+		To: []v1.NetworkPolicyPeer{{
+			IPBlock: &v1.IPBlock{
+				CIDR:   "0.0.0.0/0",
+				Except: []string{"169.254.169.254/32"},
+			},
+		}, {
+			IPBlock: &v1.IPBlock{
+				CIDR:   "::/0",
+				Except: []string{"fd00:ec2::254/128"},
+			},
+		}},
+	}
+}
+
+// AllowKubeAPIEgressRule allows traffic to the Kubernetes API. The address of the API server
+// depends on the cluster, so only the ports are restricted.
+func AllowKubeAPIEgressRule() v1.NetworkPolicyEgressRule {
+	return v1.NetworkPolicyEgressRule{
+		Ports: []v1.NetworkPolicyPort{
+			{
+				Protocol: TCPProtocol,
+				Port:     &intstr.IntOrString{IntVal: 443},
+			},
+			{
+				Protocol: TCPProtocol,
+				Port:     &intstr.IntOrString{IntVal: 6443},
+			},
+		},
+	}
+}
+
 func AllowKubeDnsEgressRule() v1.NetworkPolicyEgressRule {
 	var tcp = corev1.ProtocolTCP
 	var udp = corev1.ProtocolUDP
@@ -65,15 +287,27 @@ func AllowWSManagerEgressRule() v1.NetworkPolicyEgressRule {
 				},
 			},
 		},
-		To: []v1.NetworkPolicyPeer{{
-			PodSelector: &metav1.LabelSelector{
-				MatchLabels: map[string]string{
-					"app":       AppName,
-					"component": WSManagerComponent,
+		To: []v1.NetworkPolicyPeer{
+			{
+				PodSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"app":       AppName,
+						"component": WSManagerComponent,
+					},
 				},
+				NamespaceSelector: &metav1.LabelSelector{},
 			},
-			NamespaceSelector: &metav1.LabelSelector{},
-		}},
+			{
+				// ws-manager-mk2 serves the same API, in place of ws-manager or next to it
+				PodSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"app":       AppName,
+						"component": WSManagerMk2Component,
+					},
+				},
+				NamespaceSelector: &metav1.LabelSelector{},
+			},
+		},
 	}
 
 	return dnsEgressRule
@@ -5,7 +5,11 @@
 package common
 
 import (
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -24,6 +28,13 @@ func DefaultServiceAccount(component string) RenderFunc {
 			}
 		}
 
+		serviceAccountAnnotations := func() map[string]string {
+			if cfg.Config.Components == nil || cfg.Config.Components.PodConfig[component] == nil {
+				return nil
+			}
+			return cfg.Config.Components.PodConfig[component].ServiceAccountAnnotations
+		}
+
 		return []runtime.Object{
 			&corev1.ServiceAccount{
 				TypeMeta: TypeMetaServiceAccount,
@@ -31,7 +42,7 @@ func DefaultServiceAccount(component string) RenderFunc {
 					Name:        component,
 					Namespace:   cfg.Namespace,
 					Labels:      CustomizeLabel(cfg, component, TypeMetaConfigmap),
-					Annotations: CustomizeAnnotation(cfg, component, TypeMetaConfigmap),
+					Annotations: CustomizeAnnotation(cfg, component, TypeMetaConfigmap, serviceAccountAnnotations),
 				},
 				AutomountServiceAccountToken: pointer.Bool(true),
 				ImagePullSecrets:             pullSecrets,
@@ -77,11 +88,22 @@ func GenerateService(component string, ports []ServicePort, mod ...func(spec *co
 			},
 		}
 
+		if cfg.Config.Network != nil {
+			service.Spec.IPFamilyPolicy = cfg.Config.Network.IPFamilyPolicy
+			service.Spec.IPFamilies = cfg.Config.Network.IPFamilies
+		}
+
 		for _, m := range mod {
 			// Apply any custom modifications to the spec
 			m(service)
 		}
 
+		if service.Spec.Type == corev1.ServiceTypeExternalName {
+			// External name services have no cluster IP to choose the family of
+			service.Spec.IPFamilyPolicy = nil
+			service.Spec.IPFamilies = nil
+		}
+
 		// Add in the customizations for labels and annotations
 		service.ObjectMeta.Labels = CustomizeLabel(cfg, component, TypeMetaService, func() map[string]string {
 			return service.ObjectMeta.Labels
@@ -94,6 +116,161 @@ func GenerateService(component string, ports []ServicePort, mod ...func(spec *co
 	}
 }
 
+// WithServiceConfig applies the configured service type and annotations to the service
+func WithServiceConfig(cfg *config.ComponentTypeService) func(service *corev1.Service) {
+	return func(service *corev1.Service) {
+		if cfg == nil {
+			return
+		}
+		if cfg.ServiceType != nil {
+			service.Spec.Type = *cfg.ServiceType
+		}
+		for k, v := range cfg.Annotations {
+			service.Annotations[k] = v
+		}
+	}
+}
+
+// GenerateHorizontalPodAutoscaler renders an autoscaler for the component's deployment if
+// autoscaling is configured for it
+func GenerateHorizontalPodAutoscaler(component string) RenderFunc {
+	return func(cfg *RenderContext) ([]runtime.Object, error) {
+		if cfg.Config.Components == nil || cfg.Config.Components.PodConfig[component] == nil {
+			return nil, nil
+		}
+		autoscaling := cfg.Config.Components.PodConfig[component].Autoscaling
+		if autoscaling == nil {
+			return nil, nil
+		}
+
+		var metrics []autoscalingv2.MetricSpec
+		if autoscaling.TargetCPUUtilizationPercentage != nil {
+			metrics = append(metrics, resourceMetric(corev1.ResourceCPU, *autoscaling.TargetCPUUtilizationPercentage))
+		}
+		if autoscaling.TargetMemoryUtilizationPercentage != nil {
+			metrics = append(metrics, resourceMetric(corev1.ResourceMemory, *autoscaling.TargetMemoryUtilizationPercentage))
+		}
+		if len(metrics) == 0 {
+			metrics = append(metrics, resourceMetric(corev1.ResourceCPU, DefaultAutoscalingCPUUtilization))
+		}
+
+		v2 := cfg.Config.KubernetesVersionAtLeast(config.KubernetesVersionAutoscalingV2)
+		typeMeta := TypeMetaHorizontalPodAutoscaler
+		if !v2 {
+			typeMeta = TypeMetaHorizontalPodAutoscalerV2beta2
+		}
+
+		hpa := &autoscalingv2.HorizontalPodAutoscaler{
+			TypeMeta: TypeMetaHorizontalPodAutoscaler,
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        component,
+				Namespace:   cfg.Namespace,
+				Labels:      CustomizeLabel(cfg, component, typeMeta),
+				Annotations: CustomizeAnnotation(cfg, component, typeMeta),
+			},
+			Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+					APIVersion: TypeMetaDeployment.APIVersion,
+					Kind:       TypeMetaDeployment.Kind,
+					Name:       component,
+				},
+				MinReplicas: autoscaling.MinReplicas,
+				MaxReplicas: autoscaling.MaxReplicas,
+				Metrics:     metrics,
+			},
+		}
+		if !v2 {
+			return []runtime.Object{horizontalPodAutoscalerV2beta2(hpa)}, nil
+		}
+
+		return []runtime.Object{hpa}, nil
+	}
+}
+
+// horizontalPodAutoscalerV2beta2 converts the autoscaler to autoscaling/v2beta2, which has
+// the same fields for the resource metrics
+func horizontalPodAutoscalerV2beta2(hpa *autoscalingv2.HorizontalPodAutoscaler) *autoscalingv2beta2.HorizontalPodAutoscaler {
+	metrics := make([]autoscalingv2beta2.MetricSpec, 0, len(hpa.Spec.Metrics))
+	for _, m := range hpa.Spec.Metrics {
+		metrics = append(metrics, autoscalingv2beta2.MetricSpec{
+			Type: autoscalingv2beta2.ResourceMetricSourceType,
+			Resource: &autoscalingv2beta2.ResourceMetricSource{
+				Name: m.Resource.Name,
+				Target: autoscalingv2beta2.MetricTarget{
+					Type:               autoscalingv2beta2.MetricTargetType(m.Resource.Target.Type),
+					AverageUtilization: m.Resource.Target.AverageUtilization,
+				},
+			},
+		})
+	}
+
+	return &autoscalingv2beta2.HorizontalPodAutoscaler{
+		TypeMeta:   TypeMetaHorizontalPodAutoscalerV2beta2,
+		ObjectMeta: hpa.ObjectMeta,
+		Spec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{
+				APIVersion: hpa.Spec.ScaleTargetRef.APIVersion,
+				Kind:       hpa.Spec.ScaleTargetRef.Kind,
+				Name:       hpa.Spec.ScaleTargetRef.Name,
+			},
+			MinReplicas: hpa.Spec.MinReplicas,
+			MaxReplicas: hpa.Spec.MaxReplicas,
+			Metrics:     metrics,
+		},
+	}
+}
+
+// GeneratePodDisruptionBudget renders a budget for the component's pods. This is rendered if
+// a budget is configured or if the component runs more than one replica.
+func GeneratePodDisruptionBudget(component string) RenderFunc {
+	return func(cfg *RenderContext) ([]runtime.Object, error) {
+		var podConfig *config.PodConfig
+		if cfg.Config.Components != nil {
+			podConfig = cfg.Config.Components.PodConfig[component]
+		}
+
+		spec := policyv1.PodDisruptionBudgetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: DefaultLabels(component)},
+		}
+		if podConfig != nil && podConfig.PodDisruptionBudget != nil {
+			spec.MinAvailable = podConfig.PodDisruptionBudget.MinAvailable
+			spec.MaxUnavailable = podConfig.PodDisruptionBudget.MaxUnavailable
+		} else if replicas := Replicas(cfg, component); replicas == nil || *replicas > 1 {
+			// autoscaled or multi-replica components
+			maxUnavailable := intstr.FromInt(1)
+			spec.MaxUnavailable = &maxUnavailable
+		} else {
+			return nil, nil
+		}
+
+		return []runtime.Object{
+			&policyv1.PodDisruptionBudget{
+				TypeMeta: TypeMetaPodDisruptionBudget,
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        component,
+					Namespace:   cfg.Namespace,
+					Labels:      CustomizeLabel(cfg, component, TypeMetaPodDisruptionBudget),
+					Annotations: CustomizeAnnotation(cfg, component, TypeMetaPodDisruptionBudget),
+				},
+				Spec: spec,
+			},
+		}, nil
+	}
+}
+
+func resourceMetric(name corev1.ResourceName, utilization int32) autoscalingv2.MetricSpec {
+	return autoscalingv2.MetricSpec{
+		Type: autoscalingv2.ResourceMetricSourceType,
+		Resource: &autoscalingv2.ResourceMetricSource{
+			Name: name,
+			Target: autoscalingv2.MetricTarget{
+				Type:               autoscalingv2.UtilizationMetricType,
+				AverageUtilization: pointer.Int32(utilization),
+			},
+		},
+	}
+}
+
 // DockerRegistryHash creates a sample pod spec that can be converted into a hash for annotations
 func DockerRegistryHash(ctx *RenderContext) ([]runtime.Object, error) {
 	if !pointer.BoolDeref(ctx.Config.ContainerRegistry.InCluster, false) {
@@ -112,3 +289,29 @@ func DockerRegistryHash(ctx *RenderContext) ([]runtime.Object, error) {
 		}},
 	}}}, nil
 }
+
+// UpstreamRegistryAuth returns the volume with the credentials of the upstream registries and the
+// path of their Docker auth config in the mount
+func UpstreamRegistryAuth(ctx *RenderContext) (volume corev1.Volume, mount corev1.VolumeMount, path string, ok bool) {
+	secret := ctx.Config.ContainerRegistry.UpstreamCredentials
+	if secret == nil {
+		return
+	}
+
+	volume = corev1.Volume{
+		Name: "upstream-pull-secret",
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: secret.Name,
+				Items:      []corev1.KeyToPath{{Key: ".dockerconfigjson", Path: "pull-secret.json"}},
+			},
+		},
+	}
+	mount = corev1.VolumeMount{
+		Name:      "upstream-pull-secret",
+		MountPath: "/mnt/upstream-pull-secret",
+		ReadOnly:  true,
+	}
+
+	return volume, mount, "/mnt/upstream-pull-secret/pull-secret.json", true
+}
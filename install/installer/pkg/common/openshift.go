@@ -0,0 +1,159 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package common
+
+import (
+	"strings"
+
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+	"github.com/gitpod-io/gitpod/installer/pkg/config/v1/experimental"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/api/policy/v1beta1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// The OpenShift types are not a dependency of the installer, so the OpenShift
+// resources are rendered as unstructured objects
+
+// IsOpenShift returns true if the installation targets OpenShift
+func IsOpenShift(ctx *RenderContext) bool {
+	return ctx.Config.Platform != nil && ctx.Config.Platform.Kind == config.PlatformOpenShift
+}
+
+// UsePodSecurityPolicies returns true if PodSecurityPolicies are enabled in the experimental config
+func UsePodSecurityPolicies(ctx *RenderContext) bool {
+	var enabled bool
+	_ = ctx.WithExperimental(func(cfg *experimental.Config) error {
+		enabled = cfg.Common != nil && cfg.Common.UsePodSecurityPolicies
+		return nil
+	})
+	return enabled
+}
+
+// PodSecurityPolicies renders the policies if PodSecurityPolicies are enabled. On OpenShift,
+// each policy is also rendered as SecurityContextConstraints of the same name.
+func PodSecurityPolicies(ctx *RenderContext, policies ...*v1beta1.PodSecurityPolicy) ([]runtime.Object, error) {
+	var resources []runtime.Object
+	for _, psp := range policies {
+		if UsePodSecurityPolicies(ctx) {
+			resources = append(resources, psp)
+		}
+		if IsOpenShift(ctx) {
+			resources = append(resources, securityContextConstraints(psp))
+		}
+	}
+
+	return resources, nil
+}
+
+// PodSecurityPolicyRules returns the rules that allow the use of the named policies rendered
+// by PodSecurityPolicies
+func PodSecurityPolicyRules(ctx *RenderContext, names ...string) []rbacv1.PolicyRule {
+	var rules []rbacv1.PolicyRule
+	if UsePodSecurityPolicies(ctx) {
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups:     []string{"policy"},
+			Resources:     []string{"podsecuritypolicies"},
+			Verbs:         []string{"use"},
+			ResourceNames: names,
+		})
+	}
+	if IsOpenShift(ctx) {
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups:     []string{"security.openshift.io"},
+			Resources:     []string{"securitycontextconstraints"},
+			Verbs:         []string{"use"},
+			ResourceNames: names,
+		})
+	}
+
+	return rules
+}
+
+// securityContextConstraints converts a PodSecurityPolicy to the equivalent SecurityContextConstraints.
+// These are granted through RBAC, so no users or groups are set.
+func securityContextConstraints(psp *v1beta1.PodSecurityPolicy) *unstructured.Unstructured {
+	spec := psp.Spec
+
+	var volumes []interface{}
+	hostPath := false
+	for _, v := range spec.Volumes {
+		volumes = append(volumes, string(v))
+		hostPath = hostPath || v == v1beta1.HostPath || v == v1beta1.All
+	}
+
+	var seccompProfiles []interface{}
+	if profiles, ok := psp.Annotations["seccomp.security.alpha.kubernetes.io/allowedProfileNames"]; ok {
+		for _, p := range strings.Split(profiles, ",") {
+			seccompProfiles = append(seccompProfiles, p)
+		}
+	}
+
+	runAsUser := map[string]interface{}{"type": string(spec.RunAsUser.Rule)}
+	if spec.RunAsUser.Rule == v1beta1.RunAsUserStrategyMustRunAs {
+		runAsUser["type"] = "MustRunAsRange"
+		if len(spec.RunAsUser.Ranges) > 0 {
+			runAsUser["uidRangeMin"] = spec.RunAsUser.Ranges[0].Min
+			runAsUser["uidRangeMax"] = spec.RunAsUser.Ranges[0].Max
+		}
+	}
+
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"allowPrivilegedContainer": spec.Privileged,
+		"allowHostNetwork":         spec.HostNetwork,
+		"allowHostPorts":           len(spec.HostPorts) > 0,
+		"allowHostPID":             spec.HostPID,
+		"allowHostIPC":             spec.HostIPC,
+		"allowHostDirVolumePlugin": hostPath,
+		"readOnlyRootFilesystem":   spec.ReadOnlyRootFilesystem,
+		"allowedCapabilities":      capabilitiesToInterface(spec.AllowedCapabilities),
+		"requiredDropCapabilities": capabilitiesToInterface(spec.RequiredDropCapabilities),
+		"volumes":                  volumes,
+		"seccompProfiles":          seccompProfiles,
+		"runAsUser":                runAsUser,
+		"seLinuxContext":           map[string]interface{}{"type": string(spec.SELinux.Rule)},
+		"fsGroup":                  groupStrategy(string(spec.FSGroup.Rule), spec.FSGroup.Ranges),
+		"supplementalGroups":       groupStrategy(string(spec.SupplementalGroups.Rule), spec.SupplementalGroups.Ranges),
+		"users":                    []interface{}{},
+		"groups":                   []interface{}{},
+	}}
+	for k, v := range obj.Object {
+		if l, ok := v.([]interface{}); ok && l == nil {
+			delete(obj.Object, k)
+		}
+	}
+	if spec.AllowPrivilegeEscalation != nil {
+		obj.Object["allowPrivilegeEscalation"] = *spec.AllowPrivilegeEscalation
+	}
+	obj.SetAPIVersion(TypeMetaSecurityContextConstraints.APIVersion)
+	obj.SetKind(TypeMetaSecurityContextConstraints.Kind)
+	obj.SetName(psp.Name)
+	obj.SetLabels(psp.Labels)
+
+	return obj
+}
+
+func capabilitiesToInterface(caps []corev1.Capability) []interface{} {
+	var res []interface{}
+	for _, c := range caps {
+		res = append(res, string(c))
+	}
+	return res
+}
+
+func groupStrategy(rule string, ranges []v1beta1.IDRange) map[string]interface{} {
+	res := map[string]interface{}{"type": rule}
+	var r []interface{}
+	for _, rng := range ranges {
+		r = append(r, map[string]interface{}{"min": rng.Min, "max": rng.Max})
+	}
+	if len(r) > 0 {
+		res["ranges"] = r
+	}
+	return res
+}
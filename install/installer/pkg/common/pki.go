@@ -0,0 +1,83 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package common
+
+import (
+	"time"
+
+	certmanagerv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// InternalPKILabel marks the certificates of the internal PKI, so that "certs renew" finds them
+	InternalPKILabel = "gitpod.io/pki"
+	// InternalPKILabelValue is the value of the InternalPKILabel
+	InternalPKILabelValue = "internal"
+
+	// InternalCASecret is the secret of the self-signed root CA in the namespace of cert-manager
+	InternalCASecret = "gitpod-identity-trust-root"
+)
+
+var internalCADuration = &metav1.Duration{Duration: time.Hour * 24 * 365}
+
+// InternalCertificate is a certificate the components authenticate each other with. It is
+// issued by the internal CA and valid for the duration of the internal PKI config.
+func InternalCertificate(ctx *RenderContext, component string, name string, secretName string, dnsNames []string) *certmanagerv1.Certificate {
+	duration := InternalCertDuration
+	var renewBefore *metav1.Duration
+	if cfg := ctx.Config.InternalPKI; cfg != nil {
+		if cfg.Duration != nil {
+			duration = &metav1.Duration{Duration: time.Duration(*cfg.Duration)}
+		}
+		if cfg.RenewBefore != nil {
+			renewBefore = &metav1.Duration{Duration: time.Duration(*cfg.RenewBefore)}
+		}
+	}
+
+	labels := DefaultLabels(component)
+	labels[InternalPKILabel] = InternalPKILabelValue
+
+	return &certmanagerv1.Certificate{
+		TypeMeta: TypeMetaCertificate,
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ctx.Namespace,
+			Labels:    labels,
+		},
+		Spec: certmanagerv1.CertificateSpec{
+			Duration:    duration,
+			RenewBefore: renewBefore,
+			SecretName:  secretName,
+			DNSNames:    dnsNames,
+			IssuerRef: cmmeta.ObjectReference{
+				Name:  CertManagerCAIssuer,
+				Kind:  certmanagerv1.ClusterIssuerKind,
+				Group: "cert-manager.io",
+			},
+			SecretTemplate: &certmanagerv1.CertificateSecretTemplate{
+				Labels: DefaultLabels(component),
+			},
+		},
+	}
+}
+
+// InternalCA returns the secret of the CA that issues the internal certificates, and whether
+// it is an external CA rather than the self-signed root CA
+func InternalCA(ctx *RenderContext) (secretName string, external bool) {
+	if cfg := ctx.Config.InternalPKI; cfg != nil && cfg.CA != nil {
+		return cfg.CA.Name, true
+	}
+	return InternalCASecret, false
+}
+
+// InternalCADuration is how long the self-signed root CA is valid
+func InternalCADuration(ctx *RenderContext) *metav1.Duration {
+	if cfg := ctx.Config.InternalPKI; cfg != nil && cfg.CADuration != nil {
+		return &metav1.Duration{Duration: time.Duration(*cfg.CADuration)}
+	}
+	return internalCADuration
+}
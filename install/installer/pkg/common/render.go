@@ -5,10 +5,16 @@
 package common
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
 	"fmt"
+	"io"
 	"strings"
+	"sync"
 
 	"github.com/docker/distribution/reference"
+	"golang.org/x/crypto/hkdf"
 	"helm.sh/helm/v3/pkg/cli/values"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/utils/pointer"
@@ -28,19 +34,41 @@ type HelmConfig struct {
 	Values  *values.Options
 }
 
+// CompositeRenderFunc renders the functions concurrently. The objects are returned in the order
+// of the functions, and the error is the one of the first function that fails, so the result
+// does not depend on the order they finish in.
 func CompositeRenderFunc(f ...RenderFunc) RenderFunc {
 	return func(ctx *RenderContext) ([]runtime.Object, error) {
+		var (
+			objs   = make([][]runtime.Object, len(f))
+			errs   = make([]error, len(f))
+			panics = make([]interface{}, len(f))
+			wg     sync.WaitGroup
+		)
+		for i, g := range f {
+			wg.Add(1)
+			go func(i int, g RenderFunc) {
+				defer wg.Done()
+				// a panic is raised again by the caller, as if the function ran in its goroutine
+				defer func() { panics[i] = recover() }()
+				objs[i], errs[i] = g(ctx)
+			}(i, g)
+		}
+		wg.Wait()
+
 		var res []runtime.Object
-		for _, g := range f {
-			obj, err := g(ctx)
-			if err != nil {
-				return nil, err
+		for i := range f {
+			if panics[i] != nil {
+				panic(panics[i])
+			}
+			if errs[i] != nil {
+				return nil, errs[i]
 			}
-			if len(obj) == 0 {
+			if len(objs[i]) == 0 {
 				// the RenderFunc chose not to render anything, possibly based on config it received
 				continue
 			}
-			res = append(res, obj...)
+			res = append(res, objs[i]...)
 		}
 		return res, nil
 	}
@@ -66,6 +94,10 @@ type GeneratedValues struct {
 	InternalRegistryUsername     string
 	InternalRegistryPassword     string
 	InternalRegistrySharedSecret string
+	ServerJWTSecret              string
+	ServerSessionSecret          string
+	// SSHUserCAKey is the private key the generated SSH user CA is derived from
+	SSHUserCAKey []byte
 }
 
 type RenderContext struct {
@@ -75,6 +107,16 @@ type RenderContext struct {
 	Values          GeneratedValues
 
 	experimentalConfig *experimental.Config
+
+	// mu guards the caches of the images, which are filled while the components are rendered
+	// concurrently
+	mu sync.Mutex
+
+	imageDigestResolver ImageDigestResolver
+	imageDigests        map[string]*imageDigest
+
+	manifestVersions   map[string]struct{}
+	imageVariantErrors map[string]struct{}
 }
 
 // WithExperimental provides access to the unsupported config. This will only do something
@@ -90,7 +132,23 @@ func (r *RenderContext) WithExperimental(mod func(ucfg *experimental.Config) err
 	return mod(r.experimentalConfig)
 }
 
+// imageOverride returns the image of the config that replaces the one with the name
+func (r *RenderContext) imageOverride(name string) *config.ComponentImage {
+	if r.Config.Components == nil {
+		return nil
+	}
+	return r.Config.Components.Images[name]
+}
+
 func (r *RenderContext) RepoName(repo, name string) string {
+	if img := r.imageOverride(name); img != nil && img.Repository != "" {
+		pref, err := reference.ParseNormalizedNamed(img.Repository)
+		if err != nil {
+			panic(fmt.Sprintf("cannot parse image repo %s: %v", img.Repository, err))
+		}
+		return pref.String()
+	}
+
 	var ref string
 	if repo == "" {
 		ref = name
@@ -111,6 +169,17 @@ func (r *RenderContext) RepoName(repo, name string) string {
 }
 
 func (r *RenderContext) ImageName(repo, name, tag string) string {
+	img := r.imageOverride(name)
+	switch {
+	case img != nil && img.Digest != "":
+		return fmt.Sprintf("%s@%s", r.RepoName(repo, name), img.Digest)
+	case img != nil && img.Tag != "":
+		// the tag of an override is taken as it is, not in the variant
+		tag = img.Tag
+	default:
+		tag = r.imageVariantTag(name, tag)
+	}
+
 	ref := fmt.Sprintf("%s:%s", r.RepoName(repo, name), tag)
 	pref, err := reference.ParseNamed(ref)
 	if err != nil {
@@ -120,47 +189,86 @@ func (r *RenderContext) ImageName(repo, name, tag string) string {
 		panic(fmt.Sprintf("image ref %s has no tag: %v", ref, err))
 	}
 
+	if pointer.BoolDeref(r.Config.PinImageDigests, false) {
+		dgst, ok := r.resolveImageDigest(ref)
+		if !ok {
+			// reported by ImageDigestErrors
+			return ref
+		}
+		return fmt.Sprintf("%s@%s", pref.Name(), dgst)
+	}
+
 	return ref
 }
 
-// generateValues generates the random values used throughout the context
-// todo(sje): find a way of persisting these values for updates
-func (r *RenderContext) generateValues() error {
-	storageAccessKey, err := RandomString(20)
-	if err != nil {
-		return err
+// generateValues generates the random values used throughout the context. Each value is derived
+// from the key by its name, so the values only depend on the key and not on the order they are
+// generated in or on the components that are rendered.
+func (r *RenderContext) generateValues(key []byte) {
+	rotated := func(group config.SecretGroup, name string) string {
+		// A rotation derives a new value without changing the values of the other groups
+		if rotation := r.Config.SecretRotations[group]; rotation > 0 {
+			return fmt.Sprintf("%s/%d", name, rotation)
+		}
+		return name
+	}
+	derive := func(group config.SecretGroup, name string, length int) string {
+		return derivedString(key, rotated(group, name), length)
 	}
-	r.Values.StorageAccessKey = storageAccessKey
 
-	storageSecretKey, err := RandomString(20)
-	if err != nil {
-		return err
+	r.Values = GeneratedValues{
+		StorageAccessKey:             derive(config.SecretGroupStorage, "storageAccessKey", 20),
+		StorageSecretKey:             derive(config.SecretGroupStorage, "storageSecretKey", 20),
+		InternalRegistryUsername:     derive(config.SecretGroupRegistry, "internalRegistryUsername", 20),
+		InternalRegistryPassword:     derive(config.SecretGroupRegistry, "internalRegistryPassword", 20),
+		InternalRegistrySharedSecret: derive(config.SecretGroupRegistry, "internalRegistrySharedSecret", 20),
+		ServerJWTSecret:              derive(config.SecretGroupServer, "serverJWTSecret", 20),
+		ServerSessionSecret:          derive(config.SecretGroupServer, "serverSessionSecret", 32),
+		SSHUserCAKey:                 derivedKey(key, "sshUserCAKey"),
 	}
-	r.Values.StorageSecretKey = storageSecretKey
+}
 
-	internalRegistryUsername, err := RandomString(20)
-	if err != nil {
-		return err
+// derivedString derives a string of up to 32 characters from the key, using the same
+// characters as RandomString
+func derivedString(key []byte, name string, length int) string {
+	b := derivedKey(key, name)[:length]
+
+	for i, c := range b {
+		b[i] = validCookieChars[int(c)%len(validCookieChars)]
 	}
-	r.Values.InternalRegistryUsername = internalRegistryUsername
+	return string(b)
+}
 
-	internalRegistryPassword, err := RandomString(20)
-	if err != nil {
-		return err
+// derivedKey derives 32 bytes of key material from the key
+func derivedKey(key []byte, name string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(name))
+	return mac.Sum(nil)
+}
+
+// NewRenderContext constructor function to create a new RenderContext with random values generated
+func NewRenderContext(cfg config.Config, versionManifest versions.Manifest, namespace string) (*RenderContext, error) {
+	key := make([]byte, sha256.Size)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
 	}
-	r.Values.InternalRegistryPassword = internalRegistryPassword
 
-	internalRegistrySharedSecret, err := RandomString(20)
-	if err != nil {
-		return err
+	return newRenderContext(cfg, versionManifest, namespace, key), nil
+}
+
+// NewSeededRenderContext creates a new RenderContext with the values derived from the seed, so
+// that every render with the same seed generates the same values. The seed is a secret, as
+// anyone who knows it can derive the generated credentials.
+func NewSeededRenderContext(cfg config.Config, versionManifest versions.Manifest, namespace string, seed []byte) (*RenderContext, error) {
+	key := make([]byte, sha256.Size)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, seed, nil, []byte("gitpod-installer generated values")), key); err != nil {
+		return nil, err
 	}
-	r.Values.InternalRegistrySharedSecret = internalRegistrySharedSecret
 
-	return nil
+	return newRenderContext(cfg, versionManifest, namespace, key), nil
 }
 
-// NewRenderContext constructor function to create a new RenderContext with the values generated
-func NewRenderContext(cfg config.Config, versionManifest versions.Manifest, namespace string) (*RenderContext, error) {
+func newRenderContext(cfg config.Config, versionManifest versions.Manifest, namespace string, key []byte) *RenderContext {
 	us := cfg.Experimental
 	cfg.Experimental = nil
 
@@ -171,10 +279,7 @@ func NewRenderContext(cfg config.Config, versionManifest versions.Manifest, name
 		experimentalConfig: us,
 	}
 
-	err := ctx.generateValues()
-	if err != nil {
-		return nil, err
-	}
+	ctx.generateValues(key)
 
-	return ctx, nil
+	return ctx
 }
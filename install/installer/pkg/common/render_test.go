@@ -5,15 +5,27 @@
 package common_test
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/pointer"
 
+	"github.com/gitpod-io/gitpod/installer/pkg/cluster"
 	"github.com/gitpod-io/gitpod/installer/pkg/common"
 	content_service "github.com/gitpod-io/gitpod/installer/pkg/components/content-service"
 	"github.com/gitpod-io/gitpod/installer/pkg/components/dashboard"
@@ -36,6 +48,46 @@ func TestCompositeRenderFunc_NilObjectsNilError(t *testing.T) {
 	require.Len(t, objects, 0)
 }
 
+func TestCompositeRenderFunc_Order(t *testing.T) {
+	render := func(name string, delay time.Duration, err error) common.RenderFunc {
+		return func(cfg *common.RenderContext) ([]runtime.Object, error) {
+			time.Sleep(delay)
+			if err != nil {
+				return nil, err
+			}
+			return []runtime.Object{&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name}}}, nil
+		}
+	}
+
+	ctx, err := common.NewRenderContext(config.Config{}, versions.Manifest{}, "test_namespace")
+	require.NoError(t, err)
+
+	objects, err := common.CompositeRenderFunc(
+		render("first", 20*time.Millisecond, nil),
+		render("second", 0, nil),
+		render("third", 10*time.Millisecond, nil),
+	)(ctx)
+	require.NoError(t, err)
+	var names []string
+	for _, obj := range objects {
+		names = append(names, obj.(*corev1.ConfigMap).Name)
+	}
+	require.Equal(t, []string{"first", "second", "third"}, names, "the objects must be in the order of the functions")
+
+	_, err = common.CompositeRenderFunc(
+		render("first", 0, nil),
+		render("second", 20*time.Millisecond, fmt.Errorf("second failed")),
+		render("third", 0, fmt.Errorf("third failed")),
+	)(ctx)
+	require.EqualError(t, err, "second failed", "the error must be the one of the first function that fails")
+
+	require.PanicsWithValue(t, "boom", func() {
+		_, _ = common.CompositeRenderFunc(func(cfg *common.RenderContext) ([]runtime.Object, error) {
+			panic("boom")
+		})(ctx)
+	})
+}
+
 func TestReplicas(t *testing.T) {
 	testCases := []struct {
 		Component        string
@@ -80,6 +132,125 @@ func TestReplicas(t *testing.T) {
 	}
 }
 
+func TestGenerateHorizontalPodAutoscaler(t *testing.T) {
+	ctx, err := common.NewRenderContext(config.Config{
+		Components: &config.Components{
+			PodConfig: map[string]*config.PodConfig{
+				"server": {
+					Replicas: pointer.Int32(2),
+					Autoscaling: &config.Autoscaling{
+						MinReplicas: pointer.Int32(2),
+						MaxReplicas: 5,
+					},
+				},
+			},
+		},
+	}, versions.Manifest{}, "test_namespace")
+	require.NoError(t, err)
+
+	objects, err := common.GenerateHorizontalPodAutoscaler(server.Component)(ctx)
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+
+	hpa := objects[0].(*autoscalingv2.HorizontalPodAutoscaler)
+	require.Equal(t, server.Component, hpa.Spec.ScaleTargetRef.Name)
+	require.Equal(t, int32(5), hpa.Spec.MaxReplicas)
+	require.Len(t, hpa.Spec.Metrics, 1)
+	require.Equal(t, corev1.ResourceCPU, hpa.Spec.Metrics[0].Resource.Name)
+	require.Equal(t, int32(common.DefaultAutoscalingCPUUtilization), *hpa.Spec.Metrics[0].Resource.Target.AverageUtilization)
+	require.Nil(t, common.Replicas(ctx, server.Component), "the autoscaler must own the replica count")
+
+	objects, err = common.GenerateHorizontalPodAutoscaler(dashboard.Component)(ctx)
+	require.NoError(t, err)
+	require.Len(t, objects, 0)
+}
+
+func TestGenerateHorizontalPodAutoscalerV2beta2(t *testing.T) {
+	ctx, err := common.NewRenderContext(config.Config{
+		KubernetesVersion: "1.22",
+		Components: &config.Components{
+			PodConfig: map[string]*config.PodConfig{
+				"server": {
+					Autoscaling: &config.Autoscaling{
+						MaxReplicas:                       5,
+						TargetMemoryUtilizationPercentage: pointer.Int32(80),
+					},
+				},
+			},
+		},
+	}, versions.Manifest{}, "test_namespace")
+	require.NoError(t, err)
+
+	objects, err := common.GenerateHorizontalPodAutoscaler(server.Component)(ctx)
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+
+	hpa := objects[0].(*autoscalingv2beta2.HorizontalPodAutoscaler)
+	require.Equal(t, common.TypeMetaHorizontalPodAutoscalerV2beta2, hpa.TypeMeta)
+	require.Equal(t, server.Component, hpa.Spec.ScaleTargetRef.Name)
+	require.Equal(t, int32(5), hpa.Spec.MaxReplicas)
+	require.Len(t, hpa.Spec.Metrics, 1)
+	require.Equal(t, corev1.ResourceMemory, hpa.Spec.Metrics[0].Resource.Name)
+	require.Equal(t, autoscalingv2beta2.UtilizationMetricType, hpa.Spec.Metrics[0].Resource.Target.Type)
+	require.Equal(t, int32(80), *hpa.Spec.Metrics[0].Resource.Target.AverageUtilization)
+}
+
+func TestGeneratePodDisruptionBudget(t *testing.T) {
+	minAvailable := intstr.FromString("50%")
+	ctx, err := common.NewRenderContext(config.Config{
+		Components: &config.Components{
+			PodConfig: map[string]*config.PodConfig{
+				"server":    {Replicas: pointer.Int32(3)},
+				"dashboard": {PodDisruptionBudget: &config.PodDisruptionBudget{MinAvailable: &minAvailable}},
+			},
+		},
+	}, versions.Manifest{}, "test_namespace")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		Component      string
+		Name           string
+		Expected       bool
+		MinAvailable   *intstr.IntOrString
+		MaxUnavailable *intstr.IntOrString
+	}{
+		{
+			Component:      server.Component,
+			Name:           "multi-replica components get a default budget",
+			Expected:       true,
+			MaxUnavailable: &intstr.IntOrString{IntVal: 1},
+		},
+		{
+			Component:    dashboard.Component,
+			Name:         "configured budgets are used",
+			Expected:     true,
+			MinAvailable: &minAvailable,
+		},
+		{
+			Component: content_service.Component,
+			Name:      "single replica components get no budget",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			objects, err := common.GeneratePodDisruptionBudget(testCase.Component)(ctx)
+			require.NoError(t, err)
+
+			if !testCase.Expected {
+				require.Len(t, objects, 0)
+				return
+			}
+
+			require.Len(t, objects, 1)
+			pdb := objects[0].(*policyv1.PodDisruptionBudget)
+			require.Equal(t, testCase.MinAvailable, pdb.Spec.MinAvailable)
+			require.Equal(t, testCase.MaxUnavailable, pdb.Spec.MaxUnavailable)
+			require.Equal(t, common.DefaultLabels(testCase.Component), pdb.Spec.Selector.MatchLabels)
+		})
+	}
+}
+
 func TestResourceRequirements(t *testing.T) {
 	defaultResources := corev1.ResourceRequirements{
 		Requests: corev1.ResourceList{
@@ -180,6 +351,212 @@ func TestResourceRequirements(t *testing.T) {
 	}
 }
 
+func TestAffinityAndTolerations(t *testing.T) {
+	nodeAffinity := &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+				MatchExpressions: []corev1.NodeSelectorRequirement{{
+					Key:      "pool",
+					Operator: corev1.NodeSelectorOpIn,
+					Values:   []string{"meta"},
+				}},
+			}},
+		},
+	}
+	toleration := corev1.Toleration{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "meta", Effect: corev1.TaintEffectNoSchedule}
+
+	ctx, err := common.NewRenderContext(config.Config{
+		Components: &config.Components{
+			PodConfig: map[string]*config.PodConfig{
+				server.Component: {
+					NodeAffinity: nodeAffinity,
+					Tolerations:  []corev1.Toleration{toleration},
+				},
+			},
+		},
+	}, versions.Manifest{}, "test_namespace")
+	require.NoError(t, err)
+
+	defaultAffinity := cluster.WithNodeAffinityHostnameAntiAffinity(server.Component, cluster.AffinityLabelMeta)
+	defaultTolerations := common.GPUToleration()
+
+	t.Run("configured node affinity replaces the default", func(t *testing.T) {
+		affinity := common.Affinity(ctx, server.Component, defaultAffinity)
+		require.Equal(t, nodeAffinity, affinity.NodeAffinity)
+		require.Equal(t, defaultAffinity.PodAntiAffinity, affinity.PodAntiAffinity)
+		require.NotEqual(t, nodeAffinity, defaultAffinity.NodeAffinity, "default affinity must not be modified")
+	})
+
+	t.Run("configured tolerations are added to the defaults", func(t *testing.T) {
+		tolerations := common.Tolerations(ctx, server.Component, defaultTolerations)
+		require.Equal(t, append(common.GPUToleration(), toleration), tolerations)
+	})
+
+	t.Run("unconfigured components use the defaults", func(t *testing.T) {
+		require.Equal(t, defaultAffinity, common.Affinity(ctx, dashboard.Component, defaultAffinity))
+		require.Equal(t, defaultTolerations, common.Tolerations(ctx, dashboard.Component, defaultTolerations))
+	})
+}
+
+func TestDaemonSetRolloutStrategyAndNodeSelector(t *testing.T) {
+	maxUnavailable := intstr.FromInt(5)
+	ctx, err := common.NewRenderContext(config.Config{
+		Components: &config.Components{
+			PodConfig: map[string]*config.PodConfig{
+				"ws-daemon": {
+					UpdateStrategy: &config.DaemonSetUpdateStrategy{Type: config.DaemonSetUpdateRolling, MaxUnavailable: &maxUnavailable},
+					NodeSelector:   map[string]string{"pool": "workspace"},
+				},
+				"registry-facade": {
+					UpdateStrategy: &config.DaemonSetUpdateStrategy{Type: config.DaemonSetUpdateOnDelete},
+				},
+			},
+		},
+	}, versions.Manifest{}, "test_namespace")
+	require.NoError(t, err)
+
+	t.Run("configured max unavailable replaces the default", func(t *testing.T) {
+		strategy := common.DaemonSetRolloutStrategy(ctx, "ws-daemon")
+		require.Equal(t, appsv1.RollingUpdateDaemonSetStrategyType, strategy.Type)
+		require.Equal(t, &maxUnavailable, strategy.RollingUpdate.MaxUnavailable)
+	})
+
+	t.Run("on delete has no rolling update", func(t *testing.T) {
+		strategy := common.DaemonSetRolloutStrategy(ctx, "registry-facade")
+		require.Equal(t, appsv1.DaemonSetUpdateStrategy{Type: appsv1.OnDeleteDaemonSetStrategyType}, strategy)
+	})
+
+	t.Run("unconfigured daemon sets update 20% of the nodes at a time", func(t *testing.T) {
+		strategy := common.DaemonSetRolloutStrategy(ctx, "agent-smith")
+		require.Equal(t, "20%", strategy.RollingUpdate.MaxUnavailable.String())
+	})
+
+	t.Run("configured node selector is added to the pods", func(t *testing.T) {
+		objs := []runtime.Object{&appsv1.DaemonSet{Spec: appsv1.DaemonSetSpec{Template: corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"component": "ws-daemon"}},
+			Spec:       corev1.PodSpec{NodeSelector: map[string]string{"kubernetes.io/os": "linux"}},
+		}}}}
+		common.WorkloadNodeSelector(ctx, objs)
+		require.Equal(t, map[string]string{"kubernetes.io/os": "linux", "pool": "workspace"}, objs[0].(*appsv1.DaemonSet).Spec.Template.Spec.NodeSelector)
+	})
+}
+
+func TestStrictNetworkPolicy(t *testing.T) {
+	testCases := []struct {
+		Name                string
+		Network             *config.Network
+		ExpectedPolicyTypes []networkingv1.PolicyType
+		ExpectedObjects     int
+	}{
+		{
+			Name:                "egress is not restricted by default",
+			ExpectedPolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		},
+		{
+			Name:                "strict mode restricts egress and denies everything else",
+			Network:             &config.Network{Policy: config.NetworkPolicyStrict},
+			ExpectedPolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+			ExpectedObjects:     1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			ctx, err := common.NewRenderContext(config.Config{Network: testCase.Network}, versions.Manifest{}, "test_namespace")
+			require.NoError(t, err)
+
+			require.Equal(t, testCase.ExpectedPolicyTypes, common.NetworkPolicyTypes(ctx))
+
+			egress := common.NetworkPolicyEgress(ctx, common.AllowExternalEgressRule())
+			if testCase.Network == nil {
+				require.Nil(t, egress)
+			} else {
+				require.Equal(t, []networkingv1.NetworkPolicyEgressRule{
+					common.AllowKubeDnsEgressRule(),
+					common.AllowExternalEgressRule(),
+				}, egress)
+			}
+
+			objects, err := common.DefaultDenyNetworkPolicy(ctx)
+			require.NoError(t, err)
+			require.Len(t, objects, testCase.ExpectedObjects)
+		})
+	}
+}
+
+func TestEgressRules(t *testing.T) {
+	ctx, err := common.NewRenderContext(config.Config{
+		Database:          config.Database{InCluster: pointer.Bool(true)},
+		ObjectStorage:     config.ObjectStorage{InCluster: pointer.Bool(false)},
+		ContainerRegistry: config.ContainerRegistry{InCluster: pointer.Bool(true)},
+		Workspace:         config.Workspace{InfrastructureNamespace: "gitpod-infra"},
+	}, versions.Manifest{}, "test_namespace")
+	require.NoError(t, err)
+
+	require.Equal(t, []networkingv1.NetworkPolicyPeer{{
+		IPBlock: &networkingv1.IPBlock{CIDR: "0.0.0.0/0", Except: []string{"169.254.169.254/32"}},
+	}, {
+		IPBlock: &networkingv1.IPBlock{CIDR: "::/0", Except: []string{"fd00:ec2::254/128"}},
+	}}, common.AllowExternalEgressRule().To, "the metadata endpoints of IPv4 and IPv6 are excluded")
+
+	require.Equal(t, []networkingv1.NetworkPolicyPeer{{
+		PodSelector: &metav1.LabelSelector{MatchLabels: common.DefaultLabels(common.ServerComponent)},
+		NamespaceSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{
+			Key:      "kubernetes.io/metadata.name",
+			Operator: metav1.LabelSelectorOpIn,
+			Values:   []string{"test_namespace", "gitpod-infra"},
+		}}},
+	}}, common.AllowComponentsEgressRule(ctx, common.ServerComponent).To)
+
+	inCluster := func(labels map[string]string) []networkingv1.NetworkPolicyPeer {
+		return []networkingv1.NetworkPolicyPeer{{
+			PodSelector:       &metav1.LabelSelector{MatchLabels: labels},
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"kubernetes.io/metadata.name": "test_namespace"}},
+		}}
+	}
+	require.Equal(t, inCluster(map[string]string{"app.kubernetes.io/name": "mysql"}), common.AllowDatabaseEgressRule(ctx).To)
+	require.Equal(t, inCluster(common.DefaultLabels(common.DockerRegistryComponent)), common.AllowContainerRegistryEgressRule(ctx).To)
+	require.Equal(t, common.AllowExternalEgressRule(), common.AllowObjectStorageEgressRule(ctx), "the object storage is external")
+
+	ctx.Config.Database = config.Database{CloudSQL: &config.DatabaseCloudSQL{Instance: "gitpod:europe-west1:db"}}
+	require.Equal(t, common.AllowComponentsEgressRule(ctx, common.CloudSQLProxyComponent), common.AllowDatabaseEgressRule(ctx))
+}
+
+func TestPodSecurityPoliciesOnOpenShift(t *testing.T) {
+	ctx, err := common.NewRenderContext(config.Config{
+		Platform: &config.Platform{Kind: config.PlatformOpenShift},
+	}, versions.Manifest{}, "test_namespace")
+	require.NoError(t, err)
+
+	objects, err := common.PodSecurityPolicies(ctx, &policyv1beta1.PodSecurityPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-policy"},
+		Spec: policyv1beta1.PodSecurityPolicySpec{
+			Volumes:   []policyv1beta1.FSType{policyv1beta1.ConfigMap, policyv1beta1.HostPath},
+			RunAsUser: policyv1beta1.RunAsUserStrategyOptions{Rule: policyv1beta1.RunAsUserStrategyMustRunAsNonRoot},
+			FSGroup: policyv1beta1.FSGroupStrategyOptions{
+				Rule:   policyv1beta1.FSGroupStrategyMustRunAs,
+				Ranges: []policyv1beta1.IDRange{{Min: 1, Max: 65535}},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, objects, 1, "only the security context constraints must be rendered")
+
+	scc := objects[0].(*unstructured.Unstructured)
+	require.Equal(t, common.TypeMetaSecurityContextConstraints.Kind, scc.GetKind())
+	require.Equal(t, "test-policy", scc.GetName())
+	require.Equal(t, true, scc.Object["allowHostDirVolumePlugin"])
+	require.Equal(t, map[string]interface{}{"type": "MustRunAsNonRoot"}, scc.Object["runAsUser"])
+	require.Equal(t, map[string]interface{}{
+		"type":   "MustRunAs",
+		"ranges": []interface{}{map[string]interface{}{"min": int64(1), "max": int64(65535)}},
+	}, scc.Object["fsGroup"])
+
+	rules := common.PodSecurityPolicyRules(ctx, "test-policy")
+	require.Len(t, rules, 1)
+	require.Equal(t, []string{"securitycontextconstraints"}, rules[0].Resources)
+}
+
 func TestRepoName(t *testing.T) {
 	type Expectation struct {
 		Result string
@@ -293,3 +670,222 @@ func TestRepoName(t *testing.T) {
 		})
 	}
 }
+
+func TestImageNameOverrides(t *testing.T) {
+	ctx, err := common.NewRenderContext(config.Config{
+		Components: &config.Components{
+			Images: map[string]*config.ComponentImage{
+				"server":     {Repository: "registry.example.com/internal/server", Tag: "custom"},
+				"ws-daemon":  {Tag: "custom"},
+				"supervisor": {Digest: "sha256:b5231d6e3c2247d2b4c9f8f7db9d3a1e0e1d1c7a6a5e9a1f4b0c0e5d3a2f1e0d"},
+			},
+		},
+	}, versions.Manifest{}, "test_namespace")
+	require.NoError(t, err)
+
+	require.Equal(t, "registry.example.com/internal/server:custom", ctx.ImageName("eu.gcr.io/gitpod-core-dev/build", "server", "v1"))
+	require.Equal(t, "eu.gcr.io/gitpod-core-dev/build/ws-daemon:custom", ctx.ImageName("eu.gcr.io/gitpod-core-dev/build", "ws-daemon", "v1"))
+	require.Equal(t, "eu.gcr.io/gitpod-core-dev/build/supervisor@sha256:b5231d6e3c2247d2b4c9f8f7db9d3a1e0e1d1c7a6a5e9a1f4b0c0e5d3a2f1e0d", ctx.ImageName("eu.gcr.io/gitpod-core-dev/build", "supervisor", "v1"))
+	require.Equal(t, "eu.gcr.io/gitpod-core-dev/build/proxy:v1", ctx.ImageName("eu.gcr.io/gitpod-core-dev/build", "proxy", "v1"), "the other images are not changed")
+	require.Equal(t, "registry.example.com/internal/server", ctx.RepoName("eu.gcr.io/gitpod-core-dev/build", "server"))
+}
+
+func TestDefaultServiceAccountAnnotations(t *testing.T) {
+	ctx, err := common.NewRenderContext(config.Config{
+		Components: &config.Components{
+			PodConfig: map[string]*config.PodConfig{
+				server.Component: {
+					ServiceAccountAnnotations: map[string]string{"eks.amazonaws.com/role-arn": "arn:aws:iam::123456789012:role/gitpod"},
+				},
+			},
+		},
+	}, versions.Manifest{}, "test_namespace")
+	require.NoError(t, err)
+
+	objs, err := common.DefaultServiceAccount(server.Component)(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"eks.amazonaws.com/role-arn": "arn:aws:iam::123456789012:role/gitpod"}, objs[0].(*corev1.ServiceAccount).Annotations)
+
+	objs, err = common.DefaultServiceAccount(dashboard.Component)(ctx)
+	require.NoError(t, err)
+	require.Empty(t, objs[0].(*corev1.ServiceAccount).Annotations)
+}
+
+func TestGenerateServiceIPFamilies(t *testing.T) {
+	policy := corev1.IPFamilyPolicyRequireDualStack
+	ctx, err := common.NewRenderContext(config.Config{
+		Network: &config.Network{
+			IPFamilyPolicy: &policy,
+			IPFamilies:     []corev1.IPFamily{corev1.IPv6Protocol, corev1.IPv4Protocol},
+		},
+	}, versions.Manifest{}, "test_namespace")
+	require.NoError(t, err)
+
+	objs, err := common.GenerateService(server.Component, []common.ServicePort{{Name: "http", ContainerPort: 3000, ServicePort: 3000}})(ctx)
+	require.NoError(t, err)
+	svc := objs[0].(*corev1.Service)
+	require.Equal(t, &policy, svc.Spec.IPFamilyPolicy)
+	require.Equal(t, []corev1.IPFamily{corev1.IPv6Protocol, corev1.IPv4Protocol}, svc.Spec.IPFamilies)
+
+	objs, err = common.GenerateService(server.Component, nil, func(service *corev1.Service) {
+		service.Spec.Type = corev1.ServiceTypeExternalName
+	})(ctx)
+	require.NoError(t, err)
+	require.Nil(t, objs[0].(*corev1.Service).Spec.IPFamilyPolicy)
+}
+
+func TestPriorityClassName(t *testing.T) {
+	ctx, err := common.NewRenderContext(config.Config{
+		PriorityClasses: &config.PriorityClasses{Enabled: true},
+		Components: &config.Components{
+			PodConfig: map[string]*config.PodConfig{
+				server.Component: {PriorityClassName: "custom"},
+			},
+		},
+	}, versions.Manifest{}, "test_namespace")
+	require.NoError(t, err)
+
+	require.Equal(t, "custom", common.PriorityClassName(ctx, server.Component, common.SystemNodeCritical))
+	require.Equal(t, common.SystemNodeCritical, common.PriorityClassName(ctx, common.WSManagerComponent, common.SystemNodeCritical))
+	require.Equal(t, common.PriorityClassWorkspaceInfra, common.PriorityClassName(ctx, common.ImageBuilderComponent, ""))
+	require.Equal(t, common.PriorityClassMeta, common.PriorityClassName(ctx, dashboard.Component, ""))
+
+	ctx.Config.PriorityClasses = nil
+	require.Equal(t, "", common.PriorityClassName(ctx, dashboard.Component, ""))
+}
+
+func TestWorkspaceClassTemplates(t *testing.T) {
+	defaultTpl := &corev1.Pod{Spec: corev1.PodSpec{NodeSelector: map[string]string{"zone": "a"}}}
+	ctx, err := common.NewRenderContext(config.Config{
+		Workspace: config.Workspace{
+			Templates: &config.WorkspaceTemplates{Default: defaultTpl},
+		},
+	}, versions.Manifest{}, "test_namespace")
+	require.NoError(t, err)
+
+	tpls := common.WorkspaceClassTemplates(ctx, config.WorkspaceClass{Name: "large", NodeSelector: map[string]string{"size": "large"}})
+	require.Equal(t, map[string]string{"zone": "a", "size": "large"}, tpls.Default.Spec.NodeSelector)
+	require.Equal(t, map[string]string{"zone": "a"}, defaultTpl.Spec.NodeSelector, "the installation's template must not be modified")
+
+	ctx.Config.Workspace.Templates = nil
+	tpls = common.WorkspaceClassTemplates(ctx, config.WorkspaceClass{Name: "small"})
+	require.Nil(t, tpls.Default)
+}
+
+func TestWorkspaceClassTemplatesGPU(t *testing.T) {
+	ctx, err := common.NewRenderContext(config.Config{
+		Workspace: config.Workspace{
+			GPU: &config.WorkspaceGPU{RuntimeClassName: pointer.String("nvidia")},
+		},
+	}, versions.Manifest{}, "test_namespace")
+	require.NoError(t, err)
+
+	tpls := common.WorkspaceClassTemplates(ctx, config.WorkspaceClass{Name: "gpu", GPUs: 2})
+	spec := tpls.Default.Spec
+	require.Equal(t, pointer.String("nvidia"), spec.RuntimeClassName)
+	require.Equal(t, []corev1.Toleration{{Key: "nvidia.com/gpu", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}}, spec.Tolerations)
+	require.Len(t, spec.Containers, 1)
+	require.Equal(t, common.WorkspaceContainerName, spec.Containers[0].Name)
+	require.Equal(t, "2", spec.Containers[0].Resources.Limits.Name(common.GPUResourceName, resource.DecimalSI).String())
+
+	tpls = common.WorkspaceClassTemplates(ctx, config.WorkspaceClass{Name: "cpu"})
+	require.Nil(t, tpls.Default)
+}
+
+func TestWorkspaceTemplatesBuildKit(t *testing.T) {
+	imageBuildTpl := &corev1.Pod{Spec: corev1.PodSpec{NodeSelector: map[string]string{"zone": "a"}}}
+	ctx, err := common.NewRenderContext(config.Config{
+		Workspace: config.Workspace{
+			Templates: &config.WorkspaceTemplates{ImageBuild: imageBuildTpl},
+		},
+		Components: &config.Components{ImageBuilder: &config.ImageBuilderComponent{BuildKit: &config.BuildKit{
+			Resources: &corev1.ResourceRequirements{Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("16Gi")}},
+			Cache:     &config.PersistentVolumeClaim{Size: resource.MustParse("100Gi")},
+		}}},
+	}, versions.Manifest{}, "test_namespace")
+	require.NoError(t, err)
+
+	spec := common.WorkspaceTemplates(ctx).ImageBuild.Spec
+	require.Equal(t, map[string]string{"zone": "a"}, spec.NodeSelector)
+	require.Len(t, spec.Containers, 1)
+	require.Equal(t, common.WorkspaceContainerName, spec.Containers[0].Name)
+	require.Equal(t, "16Gi", spec.Containers[0].Resources.Limits.Memory().String())
+	require.Equal(t, []corev1.VolumeMount{{Name: "buildkit-cache", MountPath: common.ImageBuilderCacheDir}}, spec.Containers[0].VolumeMounts)
+	require.Equal(t, common.ImageBuilderCacheClaim, spec.Volumes[0].PersistentVolumeClaim.ClaimName)
+	require.Equal(t, pointer.Int64(33333), spec.SecurityContext.FSGroup)
+	require.Empty(t, imageBuildTpl.Spec.Containers, "the installation's template must not be modified")
+
+	ctx.Config.Components = nil
+	require.Equal(t, imageBuildTpl, common.WorkspaceTemplates(ctx).ImageBuild)
+}
+
+func TestWorkspaceTemplatesDNS(t *testing.T) {
+	ctx, err := common.NewRenderContext(config.Config{
+		Workspace: config.Workspace{
+			DNS: &config.WorkspaceDNS{
+				Policy:      corev1.DNSNone,
+				Nameservers: []string{"10.10.0.53"},
+				Searches:    []string{"corp.example.com"},
+			},
+		},
+	}, versions.Manifest{}, "test_namespace")
+	require.NoError(t, err)
+
+	spec := common.WorkspaceClassTemplates(ctx, config.WorkspaceClass{Name: "large", NodeSelector: map[string]string{"size": "large"}}).Default.Spec
+	require.Equal(t, corev1.DNSNone, spec.DNSPolicy)
+	require.Equal(t, &corev1.PodDNSConfig{Nameservers: []string{"10.10.0.53"}, Searches: []string{"corp.example.com"}}, spec.DNSConfig)
+
+	defaultTpl := &corev1.Pod{Spec: corev1.PodSpec{DNSPolicy: corev1.DNSDefault}}
+	ctx.Config.Workspace.Templates = &config.WorkspaceTemplates{Default: defaultTpl}
+	spec = common.WorkspaceTemplates(ctx).Default.Spec
+	require.Equal(t, corev1.DNSDefault, spec.DNSPolicy, "the template takes precedence")
+	require.NotNil(t, spec.DNSConfig)
+	require.Nil(t, defaultTpl.Spec.DNSConfig, "the installation's template must not be modified")
+}
+
+func TestGeneratedValuesFromSeed(t *testing.T) {
+	newValues := func(seed string) common.GeneratedValues {
+		ctx, err := common.NewSeededRenderContext(config.Config{}, versions.Manifest{}, "test", []byte(seed))
+		require.NoError(t, err)
+		return ctx.Values
+	}
+
+	values := newValues("a")
+	require.Equal(t, values, newValues("a"))
+	require.NotEqual(t, values, newValues("b"))
+	require.NotEqual(t, values.InternalRegistryUsername, values.InternalRegistryPassword)
+	require.Len(t, values.ServerJWTSecret, 20)
+
+	random, err := common.NewRenderContext(config.Config{}, versions.Manifest{}, "test")
+	require.NoError(t, err)
+	require.NotEqual(t, values, random.Values)
+}
+
+func TestImageNameVariant(t *testing.T) {
+	manifest := versions.Manifest{
+		Variants: map[string][]string{
+			"server":    {"fips", "distroless"},
+			"ws-daemon": {"distroless"},
+		},
+	}
+	manifest.Components.Server.Version = "v1"
+	manifest.Components.WSDaemon.Version = "v1"
+
+	ctx, err := common.NewRenderContext(config.Config{
+		ImageVariant: config.ImageVariantFIPS,
+		Components: &config.Components{
+			Images: map[string]*config.ComponentImage{
+				"proxy": {Tag: "custom"},
+			},
+		},
+	}, manifest, "test_namespace")
+	require.NoError(t, err)
+
+	require.Equal(t, "eu.gcr.io/gitpod-core-dev/build/server:v1-fips", ctx.ImageName("eu.gcr.io/gitpod-core-dev/build", "server", "v1"))
+	require.Equal(t, "eu.gcr.io/gitpod-core-dev/build/proxy:custom", ctx.ImageName("eu.gcr.io/gitpod-core-dev/build", "proxy", "v1"), "overrides are not changed")
+	require.Equal(t, "docker.io/library/redis:6.2", ctx.ImageName("docker.io", "library/redis", "6.2"), "third-party images are not changed")
+	require.NoError(t, ctx.ImageVariantErrors())
+
+	require.Equal(t, "eu.gcr.io/gitpod-core-dev/build/ws-daemon:v1", ctx.ImageName("eu.gcr.io/gitpod-core-dev/build", "ws-daemon", "v1"))
+	require.ErrorContains(t, ctx.ImageVariantErrors(), "ws-daemon")
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package common
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	hardenedTmpVolume = "hardened-tmp"
+	hardenedTmpPath   = "/tmp"
+
+	// HardenedUser is the user and group the hardened pods run as if their images run as root
+	HardenedUser = 65532
+)
+
+// unhardenedComponents need privileged containers, host paths or capabilities to manage the
+// workspaces on their nodes
+var unhardenedComponents = map[string]struct{}{
+	"agent-smith":           {},
+	RegistryFacadeComponent: {},
+	"ws-daemon":             {},
+}
+
+// writableRootFilesystemComponents write outside of their volumes, e.g. the caches and pid
+// files of nginx and Caddy
+var writableRootFilesystemComponents = map[string]struct{}{
+	"dashboard":    {},
+	"ide-proxy":    {},
+	ProxyComponent: {},
+	"redis":        {},
+}
+
+// Hardened returns whether the pods of the component are hardened
+func Hardened(ctx *RenderContext, component string) bool {
+	if ctx.Config.Security == nil || !ctx.Config.Security.Hardened {
+		return false
+	}
+	if _, ok := unhardenedComponents[component]; ok {
+		return false
+	}
+	for _, c := range ctx.Config.Security.OptOut {
+		if c == component {
+			return false
+		}
+	}
+	return true
+}
+
+// Harden sets the security context of the pod and its containers to what the Pod Security
+// Standard "restricted" requires, if the component is hardened. A pod or container that runs
+// as root runs as HardenedUser instead. Privileged containers are kept, so a component that
+// needs one must not be hardened.
+func Harden(ctx *RenderContext, component string, pod *corev1.PodSpec) {
+	if !Hardened(ctx, component) {
+		return
+	}
+
+	if pod.SecurityContext == nil {
+		pod.SecurityContext = &corev1.PodSecurityContext{}
+	}
+	if pod.SecurityContext.SeccompProfile == nil {
+		pod.SecurityContext.SeccompProfile = &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}
+	}
+	runAsNonRoot(&pod.SecurityContext.RunAsNonRoot, &pod.SecurityContext.RunAsUser, &pod.SecurityContext.RunAsGroup)
+
+	readOnly := !hasWritableRootFilesystem(ctx, component)
+	tmpMounted := false
+	harden := func(c *corev1.Container) {
+		if c.SecurityContext == nil {
+			c.SecurityContext = &corev1.SecurityContext{}
+		}
+		sc := c.SecurityContext
+		if pointer.BoolDeref(sc.Privileged, false) {
+			return
+		}
+		if sc.RunAsNonRoot != nil {
+			runAsNonRoot(&sc.RunAsNonRoot, &sc.RunAsUser, &sc.RunAsGroup)
+		}
+		if sc.AllowPrivilegeEscalation == nil {
+			sc.AllowPrivilegeEscalation = pointer.Bool(false)
+		}
+		if sc.Capabilities == nil {
+			sc.Capabilities = &corev1.Capabilities{}
+		}
+		// the capabilities that are added are kept, the runtime drops all others
+		if !hasCapability(sc.Capabilities.Drop, "ALL") {
+			sc.Capabilities.Drop = append(sc.Capabilities.Drop, "ALL")
+		}
+
+		if readOnly && sc.ReadOnlyRootFilesystem == nil {
+			sc.ReadOnlyRootFilesystem = pointer.Bool(true)
+			// most programs need a writable temporary directory
+			if !hasMountAt(c.VolumeMounts, hardenedTmpPath) {
+				c.VolumeMounts = append(c.VolumeMounts, corev1.VolumeMount{Name: hardenedTmpVolume, MountPath: hardenedTmpPath})
+				tmpMounted = true
+			}
+		}
+	}
+	for i := range pod.InitContainers {
+		harden(&pod.InitContainers[i])
+	}
+	for i := range pod.Containers {
+		harden(&pod.Containers[i])
+	}
+
+	if tmpMounted {
+		pod.Volumes = append(pod.Volumes, corev1.Volume{
+			Name:         hardenedTmpVolume,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		})
+	}
+}
+
+// WorkloadSecurity hardens the pods of the objects, by the component in the labels of their pods
+func WorkloadSecurity(ctx *RenderContext, objs []runtime.Object) {
+	for _, obj := range objs {
+		if template := podTemplate(obj); template != nil {
+			Harden(ctx, template.Labels["component"], &template.Spec)
+		}
+	}
+}
+
+// runAsNonRoot sets runAsNonRoot, and the user and group a component that asked to run as
+// root runs as instead, because the kubelet refuses to start those as non-root otherwise
+func runAsNonRoot(nonRoot **bool, user, group **int64) {
+	if !pointer.BoolDeref(*nonRoot, true) {
+		if *user == nil {
+			*user = pointer.Int64(HardenedUser)
+		}
+		if *group == nil {
+			*group = pointer.Int64(HardenedUser)
+		}
+	}
+	*nonRoot = pointer.Bool(true)
+}
+
+func hasWritableRootFilesystem(ctx *RenderContext, component string) bool {
+	if _, ok := writableRootFilesystemComponents[component]; ok {
+		return true
+	}
+	for _, c := range ctx.Config.Security.WritableRootFilesystem {
+		if c == component {
+			return true
+		}
+	}
+	return false
+}
+
+func hasCapability(caps []corev1.Capability, capability corev1.Capability) bool {
+	for _, c := range caps {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+func hasMountAt(mounts []corev1.VolumeMount, path string) bool {
+	for _, m := range mounts {
+		if m.MountPath == path {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package common
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+
+	"golang.org/x/crypto/ssh"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// SSHUserCASecret is the secret of the generated SSH user CA, and the config map of its public key
+	SSHUserCASecret = "ssh-user-ca"
+	// SSHUserCAPublicKey is the key of the public keys of the CA in the secret
+	SSHUserCAPublicKey = "ca.pub"
+	// SSHUserCAPrivateKey is the key of the private key of the generated CA in the secret
+	SSHUserCAPrivateKey = "ca"
+
+	sshUserCAVolume    = "ssh-user-ca"
+	sshUserCAMountPath = "/mnt/ssh-user-ca"
+)
+
+// SSHUserCAPublicKeyPath is the file the components read the public keys of the SSH user CA from
+const SSHUserCAPublicKeyPath = sshUserCAMountPath + "/" + SSHUserCAPublicKey
+
+// SSHUserCASecretName returns the secret with the public keys of the SSH user CA, or an empty
+// string if no CA is configured
+func SSHUserCASecretName(ctx *RenderContext) string {
+	ca := ctx.Config.Workspace.SSHUserCA
+	switch {
+	case ca == nil:
+		return ""
+	case ca.Secret != nil:
+		return ca.Secret.Name
+	case ca.Generate:
+		return SSHUserCASecret
+	}
+	return ""
+}
+
+// SSHUserCAVolume mounts the public keys of the SSH user CA to SSHUserCAPublicKeyPath, it
+// returns nothing if no CA is configured
+func SSHUserCAVolume(ctx *RenderContext) ([]corev1.Volume, []corev1.VolumeMount) {
+	secretName := SSHUserCASecretName(ctx)
+	if secretName == "" {
+		return nil, nil
+	}
+
+	return []corev1.Volume{{
+		Name: sshUserCAVolume,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: secretName,
+				Items:      []corev1.KeyToPath{{Key: SSHUserCAPublicKey, Path: SSHUserCAPublicKey}},
+			},
+		},
+	}}, []corev1.VolumeMount{{
+		Name:      sshUserCAVolume,
+		MountPath: sshUserCAMountPath,
+		ReadOnly:  true,
+	}}
+}
+
+// SSHUserCAObjects renders the generated SSH user CA. The secret has its private key to sign
+// the user certificates with, the config map only the public key, so that it can be shared
+// with the SSH clients of the users.
+func SSHUserCAObjects(ctx *RenderContext) ([]runtime.Object, error) {
+	ca := ctx.Config.Workspace.SSHUserCA
+	if ca == nil || !ca.Generate {
+		return nil, nil
+	}
+
+	key := generatedSSHUserCA(ctx.Values.SSHUserCAKey)
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("cannot marshal the SSH user CA: %w", err)
+	}
+	pub, err := ssh.NewPublicKey(&key.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot marshal the public key of the SSH user CA: %w", err)
+	}
+	authorizedKey := string(ssh.MarshalAuthorizedKey(pub))
+
+	return []runtime.Object{
+		&corev1.Secret{
+			TypeMeta: TypeMetaSecret,
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      SSHUserCASecret,
+				Namespace: ctx.Namespace,
+				Labels:    DefaultLabels(WSProxyComponent),
+			},
+			Data: map[string][]byte{
+				SSHUserCAPrivateKey: pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}),
+				SSHUserCAPublicKey:  []byte(authorizedKey),
+			},
+		},
+		&corev1.ConfigMap{
+			TypeMeta: TypeMetaConfigmap,
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      SSHUserCASecret,
+				Namespace: ctx.Namespace,
+				Labels:    DefaultLabels(WSProxyComponent),
+			},
+			Data: map[string]string{
+				SSHUserCAPublicKey: authorizedKey,
+			},
+		},
+	}, nil
+}
+
+// generatedSSHUserCA derives a P-256 key from the key material, which ssh-keygen reads in the
+// PEM format of the secret. The scalar is kept within [1, N-1] of the curve.
+func generatedSSHUserCA(material []byte) *ecdsa.PrivateKey {
+	curve := elliptic.P256()
+	one := big.NewInt(1)
+	d := new(big.Int).SetBytes(material)
+	d.Mod(d, new(big.Int).Sub(curve.Params().N, one))
+	d.Add(d, one)
+
+	key := &ecdsa.PrivateKey{D: d, PublicKey: ecdsa.PublicKey{Curve: curve}}
+	key.PublicKey.X, key.PublicKey.Y = curve.ScalarBaseMult(d.Bytes())
+	return key
+}
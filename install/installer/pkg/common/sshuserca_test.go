@@ -0,0 +1,75 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package common_test
+
+import (
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+	"github.com/gitpod-io/gitpod/installer/pkg/config/versions"
+)
+
+func TestSSHUserCAObjects(t *testing.T) {
+	render := func(seed string) *corev1.Secret {
+		ctx, err := common.NewSeededRenderContext(config.Config{
+			Workspace: config.Workspace{SSHUserCA: &config.SSHUserCA{Generate: true}},
+		}, versions.Manifest{}, "test_namespace", []byte(seed))
+		require.NoError(t, err)
+
+		objs, err := common.SSHUserCAObjects(ctx)
+		require.NoError(t, err)
+		require.Len(t, objs, 2)
+		require.Equal(t, string(objs[0].(*corev1.Secret).Data[common.SSHUserCAPublicKey]), objs[1].(*corev1.ConfigMap).Data[common.SSHUserCAPublicKey])
+		return objs[0].(*corev1.Secret)
+	}
+
+	secret := render("a")
+	require.Equal(t, secret.Data, render("a").Data, "the CA must be derived from the seed")
+	require.NotEqual(t, secret.Data, render("b").Data)
+
+	block, _ := pem.Decode(secret.Data[common.SSHUserCAPrivateKey])
+	require.NotNil(t, block)
+	require.Equal(t, "EC PRIVATE KEY", block.Type)
+	signer, err := ssh.ParsePrivateKey(secret.Data[common.SSHUserCAPrivateKey])
+	require.NoError(t, err)
+	require.Equal(t, string(ssh.MarshalAuthorizedKey(signer.PublicKey())), string(secret.Data[common.SSHUserCAPublicKey]))
+}
+
+func TestSSHUserCAVolume(t *testing.T) {
+	tests := []struct {
+		Name           string
+		SSHUserCA      *config.SSHUserCA
+		ExpectedSecret string
+	}{
+		{Name: "unset"},
+		{Name: "secret", SSHUserCA: &config.SSHUserCA{Secret: &config.ObjectRef{Kind: config.ObjectRefSecret, Name: "corporate-ca"}}, ExpectedSecret: "corporate-ca"},
+		{Name: "generated", SSHUserCA: &config.SSHUserCA{Generate: true}, ExpectedSecret: common.SSHUserCASecret},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			ctx, err := common.NewRenderContext(config.Config{
+				Workspace: config.Workspace{SSHUserCA: test.SSHUserCA},
+			}, versions.Manifest{}, "test_namespace")
+			require.NoError(t, err)
+
+			volumes, mounts := common.SSHUserCAVolume(ctx)
+			if test.ExpectedSecret == "" {
+				require.Empty(t, volumes)
+				require.Empty(t, mounts)
+				return
+			}
+			require.Len(t, volumes, 1)
+			require.Equal(t, test.ExpectedSecret, volumes[0].Secret.SecretName)
+			require.Len(t, mounts, 1)
+			require.Equal(t, common.SSHUserCAPublicKeyPath, mounts[0].MountPath+"/"+common.SSHUserCAPublicKey)
+		})
+	}
+}
@@ -42,8 +42,11 @@ func StorageConfig(context *RenderContext) storageconfig.StorageConfig {
 		res = &storageconfig.StorageConfig{
 			Kind: storageconfig.S3Storage,
 			S3Config: &storageconfig.S3Config{
-				Region: context.Config.Metadata.Region,
-				Bucket: context.Config.ObjectStorage.S3.BucketName,
+				Region:         context.Config.Metadata.Region,
+				Bucket:         context.Config.ObjectStorage.S3.BucketName,
+				UsePathStyle:   context.Config.ObjectStorage.S3.ForcePathStyle,
+				SSEKMSKeyID:    context.Config.ObjectStorage.S3.SSEKMSKeyARN,
+				BucketPerOwner: context.Config.ObjectStorage.S3.BucketPerOwner,
 			},
 		}
 
@@ -70,6 +73,15 @@ func StorageConfig(context *RenderContext) storageconfig.StorageConfig {
 		panic("no valid storage configuration set")
 	}
 
+	if backups := context.Config.ObjectStorage.Backups; backups != nil && backups.ParallelUpload != nil {
+		switch res.Kind {
+		case storageconfig.MinIOStorage:
+			res.MinIOConfig.ParallelUpload = uint(*backups.ParallelUpload)
+		case storageconfig.S3Storage:
+			res.S3Config.ParallelUpload = uint(*backups.ParallelUpload)
+		}
+	}
+
 	// 5 GiB
 	res.BlobQuota = 5 * 1024 * 1024 * 1024
 	if context.Config.ObjectStorage.BlobQuota != nil {
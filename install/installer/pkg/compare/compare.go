@@ -0,0 +1,341 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+// Package compare compares two renders of an installation object by object
+package compare
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	"github.com/gitpod-io/gitpod/installer/pkg/postprocess"
+	"sigs.k8s.io/yaml"
+)
+
+type Status string
+
+const (
+	StatusAdded   Status = "added"
+	StatusRemoved Status = "removed"
+	StatusChanged Status = "changed"
+)
+
+// Change is a field that differs between the renders. Previous is unset if the field was
+// added, Current if it was removed.
+type Change struct {
+	Path     string      `json:"path"`
+	Previous interface{} `json:"previous,omitempty"`
+	Current  interface{} `json:"current,omitempty"`
+}
+
+type ObjectDiff struct {
+	Kind      string   `json:"kind"`
+	Namespace string   `json:"namespace,omitempty"`
+	Name      string   `json:"name"`
+	Status    Status   `json:"status"`
+	Changes   []Change `json:"changes,omitempty"`
+}
+
+type Result struct {
+	// Objects are the objects that were added, removed or changed, sorted by their kind,
+	// namespace and name
+	Objects   []ObjectDiff `json:"objects"`
+	Unchanged int          `json:"unchanged"`
+}
+
+// ignoredAnnotations change with every change of a config, which is reported in the config
+// itself. Helm charts annotate their pods with checksum/<name>.
+var ignoredAnnotations = regexp.MustCompile(`^(` + regexp.QuoteMeta(common.AnnotationConfigChecksum) + `|checksum/.+)$`)
+
+// ReadPath reads the rendered objects from a file, or from every YAML file of a directory,
+// like the output of render --output-dir
+func ReadPath(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return []string{string(b)}, nil
+	}
+
+	var res []string
+	err = filepath.WalkDir(path, func(fn string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if ext := filepath.Ext(fn); ext != ".yaml" && ext != ".yml" {
+			return nil
+		}
+		b, err := os.ReadFile(fn)
+		if err != nil {
+			return err
+		}
+		res = append(res, string(b))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("%s has no YAML files to compare against", path)
+	}
+	return res, nil
+}
+
+// Compare compares the objects of the current render to those of the previous one. The order
+// of the objects, the order of the lists whose items have a name, like containers or
+// environment variables, and the config hashes do not make a difference. Strings that hold a
+// JSON document, like the configs of the components, are compared field by field.
+func Compare(previous, current []string) (*Result, error) {
+	prev, err := parseObjects(previous)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the previous render: %w", err)
+	}
+	cur, err := parseObjects(current)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the current render: %w", err)
+	}
+
+	res := &Result{Objects: []ObjectDiff{}}
+	for key, c := range cur {
+		p, ok := prev[key]
+		if !ok {
+			res.Objects = append(res.Objects, ObjectDiff{Kind: key.Kind, Namespace: key.Namespace, Name: key.Name, Status: StatusAdded})
+			continue
+		}
+
+		var changes []Change
+		diffValues("", p, c, &changes)
+		if len(changes) == 0 {
+			res.Unchanged++
+			continue
+		}
+		res.Objects = append(res.Objects, ObjectDiff{Kind: key.Kind, Namespace: key.Namespace, Name: key.Name, Status: StatusChanged, Changes: changes})
+	}
+	for key := range prev {
+		if _, ok := cur[key]; !ok {
+			res.Objects = append(res.Objects, ObjectDiff{Kind: key.Kind, Namespace: key.Namespace, Name: key.Name, Status: StatusRemoved})
+		}
+	}
+
+	sort.Slice(res.Objects, func(i, j int) bool {
+		a, b := res.Objects[i], res.Objects[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return res, nil
+}
+
+type objectKey struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+func parseObjects(yamls []string) (map[objectKey]map[string]interface{}, error) {
+	docs, err := common.YamlToRuntimeObject(yamls)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[objectKey]map[string]interface{}, len(docs))
+	for _, doc := range docs {
+		// Documents of only comments, or files like Chart.yaml, are no objects
+		if doc.Kind == "" || doc.Metadata.Name == "" {
+			continue
+		}
+
+		var obj map[string]interface{}
+		if err := yaml.Unmarshal([]byte(doc.Content), &obj); err != nil {
+			return nil, err
+		}
+		normalize(obj)
+
+		key := objectKey{Kind: doc.Kind, Namespace: doc.Metadata.Namespace, Name: doc.Metadata.Name}
+		if _, exists := res[key]; exists {
+			return nil, fmt.Errorf("%s %s is rendered more than once", key.Kind, key.Name)
+		}
+		res[key] = obj
+	}
+	return res, nil
+}
+
+// normalize removes the config hashes from the annotations and labels of the object and its
+// pod templates
+func normalize(v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			values, ok := value.(map[string]interface{})
+			switch {
+			case key == "annotations" && ok:
+				for name := range values {
+					if ignoredAnnotations.MatchString(name) {
+						delete(values, name)
+					}
+				}
+			case key == "labels" && ok:
+				delete(values, postprocess.LabelConfigHash)
+			}
+			if ok && len(values) == 0 && (key == "annotations" || key == "labels") {
+				delete(v, key)
+				continue
+			}
+			normalize(value)
+		}
+	case []interface{}:
+		for _, item := range v {
+			normalize(item)
+		}
+	}
+}
+
+func diffValues(path string, previous, current interface{}, changes *[]Change) {
+	switch p := previous.(type) {
+	case map[string]interface{}:
+		if c, ok := current.(map[string]interface{}); ok {
+			diffMaps(path, p, c, joinPath, changes)
+			return
+		}
+	case []interface{}:
+		if c, ok := current.([]interface{}); ok {
+			diffLists(path, p, c, changes)
+			return
+		}
+	case string:
+		if c, ok := current.(string); ok && p != c {
+			pDoc, pOK := jsonDocument(p)
+			cDoc, cOK := jsonDocument(c)
+			if pOK && cOK {
+				diffValues(path, pDoc, cDoc, changes)
+				return
+			}
+		}
+	}
+
+	if !reflect.DeepEqual(previous, current) {
+		*changes = append(*changes, Change{Path: path, Previous: previous, Current: current})
+	}
+}
+
+func diffMaps(path string, previous, current map[string]interface{}, join func(path, key string) string, changes *[]Change) {
+	keys := make([]string, 0, len(previous)+len(current))
+	for key := range previous {
+		keys = append(keys, key)
+	}
+	for key := range current {
+		if _, ok := previous[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		p, pOK := previous[key]
+		c, cOK := current[key]
+		fieldPath := join(path, key)
+		switch {
+		case !pOK:
+			*changes = append(*changes, Change{Path: fieldPath, Current: c})
+		case !cOK:
+			*changes = append(*changes, Change{Path: fieldPath, Previous: p})
+		default:
+			diffValues(fieldPath, p, c, changes)
+		}
+	}
+}
+
+// diffLists matches the items of lists that are all named, e.g. containers, by their name, so
+// they can be reordered. Other lists are compared item by item.
+func diffLists(path string, previous, current []interface{}, changes *[]Change) {
+	pNamed, pOK := namedItems(previous)
+	cNamed, cOK := namedItems(current)
+	if pOK && cOK {
+		diffMaps(path, pNamed, cNamed, joinName, changes)
+		return
+	}
+
+	for i := 0; i < len(previous) || i < len(current); i++ {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		switch {
+		case i >= len(previous):
+			*changes = append(*changes, Change{Path: itemPath, Current: current[i]})
+		case i >= len(current):
+			*changes = append(*changes, Change{Path: itemPath, Previous: previous[i]})
+		default:
+			diffValues(itemPath, previous[i], current[i], changes)
+		}
+	}
+}
+
+// namedItems returns the items of the list by their name, if every item has a unique name
+func namedItems(list []interface{}) (map[string]interface{}, bool) {
+	if len(list) == 0 {
+		return nil, false
+	}
+	res := make(map[string]interface{}, len(list))
+	for _, item := range list {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		name, ok := m["name"].(string)
+		if !ok {
+			return nil, false
+		}
+		if _, exists := res[name]; exists {
+			return nil, false
+		}
+		res[name] = item
+	}
+	return res, true
+}
+
+func jsonDocument(s string) (interface{}, bool) {
+	trimmed := strings.TrimSpace(s)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return nil, false
+	}
+	var res interface{}
+	if err := json.Unmarshal([]byte(trimmed), &res); err != nil {
+		return nil, false
+	}
+	return res, true
+}
+
+var plainKey = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+func joinPath(path, key string) string {
+	switch {
+	case !plainKey.MatchString(key):
+		return fmt.Sprintf("%s[%q]", path, key)
+	case path == "":
+		return key
+	}
+	return path + "." + key
+}
+
+func joinName(path, name string) string {
+	return fmt.Sprintf("%s[%s]", path, name)
+}
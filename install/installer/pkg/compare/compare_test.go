@@ -0,0 +1,133 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package compare_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/compare"
+)
+
+const previous = `---
+# v1/ConfigMap server-config
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: server-config
+  namespace: gitpod
+data:
+  config.json: |
+    {"workspaceDefaults": {"workspaceImage": "gitpod/workspace-full"}, "maintenanceMode": false}
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: server
+  namespace: gitpod
+  labels:
+    gitpod.io/config-hash: abc
+spec:
+  template:
+    metadata:
+      annotations:
+        gitpod.io/checksum_config: abc
+    spec:
+      containers:
+      - name: server
+        image: server:1
+        env:
+        - name: A
+          value: "1"
+        - name: B
+          value: "2"
+      - name: kube-rbac-proxy
+        image: kube-rbac-proxy:1
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: proxy
+  namespace: gitpod
+`
+
+const current = `---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: server
+  namespace: gitpod
+  labels:
+    gitpod.io/config-hash: def
+spec:
+  template:
+    metadata:
+      annotations:
+        gitpod.io/checksum_config: def
+    spec:
+      containers:
+      - name: kube-rbac-proxy
+        image: kube-rbac-proxy:1
+      - name: server
+        image: server:2
+        env:
+        - name: B
+          value: "2"
+        - name: A
+          value: "1"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: server-config
+  namespace: gitpod
+data:
+  config.json: |
+    {"maintenanceMode": false, "workspaceDefaults": {"workspaceImage": "gitpod/workspace-base"}}
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: server
+`
+
+func TestCompare(t *testing.T) {
+	res, err := compare.Compare([]string{previous}, []string{current})
+	require.NoError(t, err)
+
+	require.Equal(t, 0, res.Unchanged)
+	require.Equal(t, []compare.ObjectDiff{
+		{Kind: "ClusterRole", Name: "server", Status: compare.StatusAdded},
+		{Kind: "ConfigMap", Namespace: "gitpod", Name: "server-config", Status: compare.StatusChanged, Changes: []compare.Change{
+			{Path: `data["config.json"].workspaceDefaults.workspaceImage`, Previous: "gitpod/workspace-full", Current: "gitpod/workspace-base"},
+		}},
+		{Kind: "Deployment", Namespace: "gitpod", Name: "server", Status: compare.StatusChanged, Changes: []compare.Change{
+			{Path: "spec.template.spec.containers[server].image", Previous: "server:1", Current: "server:2"},
+		}},
+		{Kind: "Service", Namespace: "gitpod", Name: "proxy", Status: compare.StatusRemoved},
+	}, res.Objects, "the order of the objects, containers and env and the config hashes make no difference")
+
+	res, err = compare.Compare([]string{current}, []string{current})
+	require.NoError(t, err)
+	require.Empty(t, res.Objects)
+	require.Equal(t, 3, res.Unchanged)
+}
+
+func TestReadPath(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "gitpod"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "gitpod", "configmap-server-config.yaml"), []byte(previous), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("not an object"), 0644))
+
+	yamls, err := compare.ReadPath(dir)
+	require.NoError(t, err)
+	require.Equal(t, []string{previous}, yamls)
+
+	_, err = compare.ReadPath(t.TempDir())
+	require.Error(t, err)
+}
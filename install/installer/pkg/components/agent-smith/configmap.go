@@ -6,6 +6,7 @@ package agentsmith
 
 import (
 	"fmt"
+	"path/filepath"
 
 	"github.com/gitpod-io/gitpod/agent-smith/pkg/config"
 	"github.com/gitpod-io/gitpod/common-go/baseserver"
@@ -31,8 +32,19 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 	}
 
 	if ctx.Config.Components != nil && ctx.Config.Components.AgentSmith != nil {
-		ascfg.Config = *ctx.Config.Components.AgentSmith
+		hostURL := ascfg.Config.GitpodAPI.HostURL
+		ascfg.Config = ctx.Config.Components.AgentSmith.Config
 		ascfg.Config.KubernetesNamespace = ctx.Namespace
+		if ascfg.Config.GitpodAPI.HostURL == "" {
+			ascfg.Config.GitpodAPI.HostURL = hostURL
+		}
+
+		if ctx.Config.Components.AgentSmith.SlackWebhooksSecret != nil {
+			ascfg.Config.SlackWebhooks = &config.SlackWebhooks{
+				AuditFile:   filepath.Join(slackWebhooksMountPath, "audit"),
+				WarningFile: filepath.Join(slackWebhooksMountPath, "warning"),
+			}
+		}
 	}
 
 	fc, err := common.ToJSONString(ascfg)
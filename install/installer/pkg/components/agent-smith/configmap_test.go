@@ -0,0 +1,48 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package agentsmith
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	agentSmith "github.com/gitpod-io/gitpod/agent-smith/pkg/config"
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+	"github.com/gitpod-io/gitpod/installer/pkg/config/versions"
+)
+
+func TestConfigMap_AuditOnly(t *testing.T) {
+	ctx, err := common.NewRenderContext(config.Config{
+		Domain: "gitpod.example.com",
+		Components: &config.Components{
+			AgentSmith: &config.AgentSmithComponent{
+				Config: agentSmith.Config{
+					Enforcement: agentSmith.Enforcement{AuditOnly: true},
+					Kubernetes:  agentSmith.Kubernetes{Enabled: true},
+				},
+				SlackWebhooksSecret: &config.ObjectRef{Kind: config.ObjectRefSecret, Name: "agent-smith-webhooks"},
+			},
+		},
+	}, versions.Manifest{}, "test_namespace")
+	require.NoError(t, err)
+
+	objs, err := configmap(ctx)
+	require.NoError(t, err)
+
+	var cfg agentSmith.ServiceConfig
+	require.NoError(t, json.Unmarshal([]byte(objs[0].(*corev1.ConfigMap).Data["config.json"]), &cfg))
+
+	require.True(t, cfg.Enforcement.AuditOnly)
+	require.Equal(t, "https://gitpod.example.com", cfg.GitpodAPI.HostURL)
+	require.Equal(t, "test_namespace", cfg.Namespace)
+	require.Equal(t, &agentSmith.SlackWebhooks{
+		AuditFile:   "/secrets/slack-webhooks/audit",
+		WarningFile: "/secrets/slack-webhooks/warning",
+	}, cfg.SlackWebhooks)
+}
@@ -6,4 +6,6 @@ package agentsmith
 
 const (
 	Component = "agent-smith"
+
+	slackWebhooksMountPath = "/secrets/slack-webhooks"
 )
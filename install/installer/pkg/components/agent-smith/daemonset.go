@@ -24,6 +24,36 @@ func daemonset(ctx *common.RenderContext) ([]runtime.Object, error) {
 		return nil, err
 	}
 
+	volumes := []corev1.Volume{
+		{
+			Name: "config",
+			VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: Component},
+			}},
+		},
+		common.CAVolume(),
+	}
+	volumeMounts := []corev1.VolumeMount{
+		{
+			Name:      "config",
+			MountPath: "/config",
+		},
+		common.CAVolumeMount(),
+	}
+	if ctx.Config.Components != nil && ctx.Config.Components.AgentSmith != nil && ctx.Config.Components.AgentSmith.SlackWebhooksSecret != nil {
+		volumes = append(volumes, corev1.Volume{
+			Name: "slack-webhooks",
+			VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{
+				SecretName: ctx.Config.Components.AgentSmith.SlackWebhooksSecret.Name,
+			}},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      "slack-webhooks",
+			MountPath: slackWebhooksMountPath,
+			ReadOnly:  true,
+		})
+	}
+
 	return []runtime.Object{&appsv1.DaemonSet{
 		TypeMeta: common.TypeMetaDaemonset,
 		ObjectMeta: metav1.ObjectMeta{
@@ -45,7 +75,9 @@ func daemonset(ctx *common.RenderContext) ([]runtime.Object, error) {
 					Annotations: common.CustomizeAnnotation(ctx, Component, common.TypeMetaDaemonset),
 				},
 				Spec: corev1.PodSpec{
-					Affinity:                      cluster.WithNodeAffinity(cluster.AffinityLabelWorkspacesRegular, cluster.AffinityLabelWorkspacesHeadless),
+					Affinity:                      common.Affinity(ctx, Component, cluster.WithNodeAffinity(cluster.AffinityLabelWorkspacesRegular, cluster.AffinityLabelWorkspacesHeadless)),
+					PriorityClassName:             common.PriorityClassName(ctx, Component, ""),
+					Tolerations:                   common.Tolerations(ctx, Component, nil),
 					ServiceAccountName:            Component,
 					HostPID:                       true,
 					EnableServiceLinks:            pointer.Bool(false),
@@ -63,13 +95,7 @@ func daemonset(ctx *common.RenderContext) ([]runtime.Object, error) {
 								"memory": resource.MustParse("32Mi"),
 							},
 						}),
-						VolumeMounts: []corev1.VolumeMount{
-							{
-								Name:      "config",
-								MountPath: "/config",
-							},
-							common.CAVolumeMount(),
-						},
+						VolumeMounts: volumeMounts,
 						Env: common.CustomizeEnvvar(ctx, Component, common.MergeEnv(
 							common.DefaultEnv(&ctx.Config),
 							common.WorkspaceTracingEnv(ctx, Component),
@@ -82,18 +108,10 @@ func daemonset(ctx *common.RenderContext) ([]runtime.Object, error) {
 					},
 						*common.KubeRBACProxyContainer(ctx),
 					},
-					Volumes: []corev1.Volume{
-						{
-							Name: "config",
-							VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
-								LocalObjectReference: corev1.LocalObjectReference{Name: Component},
-							}},
-						},
-						common.CAVolume(),
-					},
+					Volumes: volumes,
 				},
 			},
-			UpdateStrategy: common.DaemonSetRolloutStrategy(),
+			UpdateStrategy: common.DaemonSetRolloutStrategy(ctx, Component),
 		},
 	}}, nil
 }
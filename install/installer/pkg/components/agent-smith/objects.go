@@ -7,10 +7,13 @@ package agentsmith
 import "github.com/gitpod-io/gitpod/installer/pkg/common"
 
 var Objects = common.CompositeRenderFunc(
-	configmap,
-	daemonset,
-	networkpolicy,
-	role,
-	rolebinding,
-	common.DefaultServiceAccount(Component),
+	common.InInfrastructureNamespace(
+		configmap,
+		daemonset,
+		networkpolicy,
+		role,
+		rolebinding,
+		common.DefaultServiceAccount(Component),
+	),
+	workspaceRole,
 )
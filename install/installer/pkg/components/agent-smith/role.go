@@ -8,27 +8,24 @@ import (
 	"fmt"
 
 	"github.com/gitpod-io/gitpod/installer/pkg/common"
-	"github.com/gitpod-io/gitpod/installer/pkg/config/v1/experimental"
 
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// workspacePodsRule allows agent-smith to annotate the workspace pods it finds abusing their workspace
+var workspacePodsRule = rbacv1.PolicyRule{
+	APIGroups: []string{""},
+	Resources: []string{"pods"},
+	Verbs:     []string{"get", "update"},
+}
+
 func role(ctx *common.RenderContext) ([]runtime.Object, error) {
-	var rules []rbacv1.PolicyRule
-
-	_ = ctx.WithExperimental(func(cfg *experimental.Config) error {
-		if cfg.Common != nil && cfg.Common.UsePodSecurityPolicies {
-			rules = append(rules, rbacv1.PolicyRule{
-				APIGroups:     []string{"policy"},
-				Resources:     []string{"podsecuritypolicies"},
-				Verbs:         []string{"use"},
-				ResourceNames: []string{fmt.Sprintf("%s-ns-privileged-unconfined", ctx.Namespace)},
-			})
-		}
-		return nil
-	})
+	rules := common.PodSecurityPolicyRules(ctx, fmt.Sprintf("%s-ns-privileged-unconfined", ctx.Namespace))
+	if !common.SeparateInfrastructureNamespace(ctx) {
+		rules = append(rules, workspacePodsRule)
+	}
 
 	return []runtime.Object{
 		&rbacv1.Role{
@@ -38,11 +35,46 @@ func role(ctx *common.RenderContext) ([]runtime.Object, error) {
 				Namespace: ctx.Namespace,
 				Labels:    common.DefaultLabels(Component),
 			},
-			Rules: append(rules, rbacv1.PolicyRule{
-				APIGroups: []string{""},
-				Resources: []string{"pods"},
-				Verbs:     []string{"get", "update"},
-			}),
+			Rules: rules,
+		},
+	}, nil
+}
+
+// workspaceRole grants agent-smith access to the workspace pods in the namespace of the
+// installation if it runs in the infrastructure namespace
+func workspaceRole(ctx *common.RenderContext) ([]runtime.Object, error) {
+	if !common.SeparateInfrastructureNamespace(ctx) {
+		return nil, nil
+	}
+
+	labels := common.DefaultLabels(Component)
+	return []runtime.Object{
+		&rbacv1.Role{
+			TypeMeta: common.TypeMetaRole,
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      Component,
+				Namespace: ctx.Namespace,
+				Labels:    labels,
+			},
+			Rules: []rbacv1.PolicyRule{workspacePodsRule},
+		},
+		&rbacv1.RoleBinding{
+			TypeMeta: common.TypeMetaRoleBinding,
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      Component,
+				Namespace: ctx.Namespace,
+				Labels:    labels,
+			},
+			RoleRef: rbacv1.RoleRef{
+				Kind:     "Role",
+				Name:     Component,
+				APIGroup: "rbac.authorization.k8s.io",
+			},
+			Subjects: []rbacv1.Subject{{
+				Kind:      "ServiceAccount",
+				Name:      Component,
+				Namespace: common.InfrastructureNamespace(ctx),
+			}},
 		},
 	}, nil
 }
@@ -49,7 +49,7 @@ func rolebinding(ctx *common.RenderContext) ([]runtime.Object, error) {
 			Subjects: []rbacv1.Subject{{
 				Kind:      "ServiceAccount",
 				Name:      Component,
-				Namespace: ctx.Namespace,
+				Namespace: common.InfrastructureNamespace(ctx),
 			}},
 		},
 	}, nil
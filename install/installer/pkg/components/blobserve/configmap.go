@@ -6,6 +6,8 @@ package blobserve
 
 import (
 	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
 	blobserve_config "github.com/gitpod-io/gitpod/blobserve/pkg/config"
@@ -21,20 +23,20 @@ import (
 )
 
 func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
-	// todo(sje): find this value
-	hasOpenVSXProxy := true
-
-	// todo(sje): find this value
-	//nolint:typecheck
-	openVSXProxyUrl := "vsx-proxy-host"
-	if hasOpenVSXProxy {
-		openVSXProxyUrl = fmt.Sprintf("open-vsx.%s", ctx.Config.Domain)
+	// Without the proxy, the extensions are fetched from the host of the OpenVSX URL
+	openVSXProxyUrl := fmt.Sprintf("open-vsx.%s", ctx.Config.Domain)
+	if !ctx.Config.OpenVSXProxyEnabled() {
+		u, err := url.Parse(ctx.Config.OpenVSX.URL)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse the OpenVSX URL: %w", err)
+		}
+		openVSXProxyUrl = u.Host
 	}
 
 	// Check also link below before change values
 	// https://github.com/gitpod-io/gitpod/blob/2cba7bd1d8a2accd294ab7733f6da4532e48984c/components/ide/code/startup.sh#L37
-	extensionsGalleryItemUrl := "https://open-vsx.org/vscode/item"
-	trustedDomain := "https://open-vsx.org"
+	trustedDomain := strings.TrimSuffix(ctx.Config.OpenVSX.URL, "/")
+	extensionsGalleryItemUrl := fmt.Sprintf("%s/vscode/item", trustedDomain)
 
 	bscfg := blobserve_config.Config{
 		BlobServe: blobserve_config.BlobServe{
@@ -70,7 +72,7 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 						Path:        "/ide/out/vs/workbench/workbench.web.main.js",
 					}, {
 						Search:      "ide.gitpod.io/code/markeplace.json",
-						Replacement: fmt.Sprintf("ide.%s/code/marketplace.json", ctx.Config.Domain),
+						Replacement: fmt.Sprintf("%s/code/marketplace.json", ctx.Config.IDEAssetsHost()),
 						Path:        "/ide/out/vs/workbench/workbench.web.main.js",
 					}},
 					// TODO consider to provide it as a part of image label or rather inline ${ide} and ${supervisor} in index.html
@@ -110,6 +112,15 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 		PrometheusAddr:     common.LocalhostPrometheusAddr(),
 		ReadinessProbeAddr: fmt.Sprintf(":%v", ReadinessPort),
 	}
+	if cache := cacheConfig(ctx); cache != nil {
+		if cache.MaxSize != nil {
+			bscfg.BlobServe.BlobSpace.MaxSize = cache.MaxSize.Value()
+		}
+		bscfg.BlobServe.BlobSpace.EvictionPolicy = blobserve_config.EvictionPolicy(cache.EvictionPolicy)
+	}
+	if _, _, path, ok := common.UpstreamRegistryAuth(ctx); ok {
+		bscfg.UpstreamAuthCfg = path
+	}
 
 	fc, err := common.ToJSONString(bscfg)
 	if err != nil {
@@ -9,10 +9,11 @@ import (
 )
 
 const (
-	Component       = "blobserve"
+	Component       = common.BlobServeComponent
 	ContainerPort   = 32224
 	ServicePort     = common.BlobServeServicePort
 	ServicePortName = "service"
 	MaxSizeBytes    = 1024 * 1024 * 1024 // 1 Gibibyte
 	ReadinessPort   = 8086
+	CacheClaimName  = "blobserve-cache"
 )
@@ -34,6 +34,24 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 		return nil, fmt.Errorf("%s: invalid container registry config", Component)
 	}
 
+	var (
+		volumes      []corev1.Volume
+		volumeMounts []corev1.VolumeMount
+	)
+	cacheVolume := corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}
+	strategy := common.DeploymentStrategy
+	if cache := cacheConfig(ctx); cache != nil && cache.PVC != nil {
+		cacheVolume = corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: CacheClaimName},
+		}
+		// The claim is released by the old pod before the new one can mount it
+		strategy = appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType}
+	}
+	if volume, mount, _, ok := common.UpstreamRegistryAuth(ctx); ok {
+		volumes = append(volumes, volume)
+		volumeMounts = append(volumeMounts, mount)
+	}
+
 	var hashObj []runtime.Object
 	if objs, err := configmap(ctx); err != nil {
 		return nil, err
@@ -64,7 +82,7 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 			Spec: appsv1.DeploymentSpec{
 				Selector: &metav1.LabelSelector{MatchLabels: common.DefaultLabels(Component)},
 				Replicas: common.Replicas(ctx, Component),
-				Strategy: common.DeploymentStrategy,
+				Strategy: strategy,
 				Template: corev1.PodTemplateSpec{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      Component,
@@ -77,14 +95,16 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 						}),
 					},
 					Spec: corev1.PodSpec{
-						Affinity:                  cluster.WithNodeAffinityHostnameAntiAffinity(Component, cluster.AffinityLabelIDE),
+						Affinity:                  common.Affinity(ctx, Component, cluster.WithNodeAffinityHostnameAntiAffinity(Component, cluster.AffinityLabelIDE)),
+						PriorityClassName:         common.PriorityClassName(ctx, Component, ""),
+						Tolerations:               common.Tolerations(ctx, Component, nil),
 						TopologySpreadConstraints: cluster.WithHostnameTopologySpread(Component),
 						ServiceAccountName:        Component,
 						EnableServiceLinks:        pointer.Bool(false),
-						Volumes: []corev1.Volume{
+						Volumes: append([]corev1.Volume{
 							{
 								Name:         "cache",
-								VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+								VolumeSource: cacheVolume,
 							}, {
 								Name: "config",
 								VolumeSource: corev1.VolumeSource{
@@ -102,7 +122,7 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 								},
 							},
 							common.CAVolume(),
-						},
+						}, volumes...),
 						Containers: []corev1.Container{{
 							Name:            Component,
 							Args:            []string{"run", "/mnt/config/config.json"},
@@ -126,7 +146,7 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 								common.DefaultEnv(&ctx.Config),
 								common.WorkspaceTracingEnv(ctx, Component),
 							)),
-							VolumeMounts: []corev1.VolumeMount{
+							VolumeMounts: append([]corev1.VolumeMount{
 								{
 									Name:      "config",
 									MountPath: "/mnt/config",
@@ -139,7 +159,7 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 									MountPath: "/mnt/pull-secret",
 								},
 								common.CAVolumeMount(),
-							},
+							}, volumeMounts...),
 
 							ReadinessProbe: &corev1.Probe{
 								ProbeHandler: corev1.ProbeHandler{
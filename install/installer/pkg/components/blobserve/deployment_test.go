@@ -0,0 +1,81 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package blobserve
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/pointer"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+	"github.com/gitpod-io/gitpod/installer/pkg/config/versions"
+)
+
+func TestDeployment_CacheVolume(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Cache    *config.BlobserveCache
+		Claim    bool
+		Strategy appsv1.DeploymentStrategyType
+	}{
+		{
+			Name:     "default",
+			Strategy: appsv1.RollingUpdateDeploymentStrategyType,
+		},
+		{
+			Name:     "emptyDir",
+			Cache:    &config.BlobserveCache{EvictionPolicy: "fifo"},
+			Strategy: appsv1.RollingUpdateDeploymentStrategyType,
+		},
+		{
+			Name:     "claim",
+			Cache:    &config.BlobserveCache{PVC: &config.PersistentVolumeClaim{Size: resource.MustParse("30Gi")}},
+			Claim:    true,
+			Strategy: appsv1.RecreateDeploymentStrategyType,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			ctx, err := common.NewRenderContext(config.Config{
+				Repository:        "eu.gcr.io/gitpod-core-dev/build",
+				ContainerRegistry: config.ContainerRegistry{InCluster: pointer.Bool(true)},
+				Components:        &config.Components{Blobserve: &config.BlobserveComponent{Cache: test.Cache}},
+			}, versions.Manifest{Components: versions.Components{Blobserve: versions.Versioned{Version: "test"}}}, "test_namespace")
+			require.NoError(t, err)
+
+			objs, err := deployment(ctx)
+			require.NoError(t, err)
+			dpl := objs[0].(*appsv1.Deployment)
+			require.Equal(t, test.Strategy, dpl.Spec.Strategy.Type)
+
+			var cache *corev1.Volume
+			for i, v := range dpl.Spec.Template.Spec.Volumes {
+				if v.Name == "cache" {
+					cache = &dpl.Spec.Template.Spec.Volumes[i]
+				}
+			}
+			require.NotNil(t, cache)
+			if test.Claim {
+				require.Equal(t, CacheClaimName, cache.PersistentVolumeClaim.ClaimName)
+			} else {
+				require.NotNil(t, cache.EmptyDir)
+			}
+
+			claims, err := pvc(ctx)
+			require.NoError(t, err)
+			if test.Claim {
+				require.Len(t, claims, 1)
+			} else {
+				require.Empty(t, claims)
+			}
+		})
+	}
+}
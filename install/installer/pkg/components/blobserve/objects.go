@@ -10,6 +10,7 @@ var Objects = common.CompositeRenderFunc(
 	configmap,
 	deployment,
 	networkpolicy,
+	pvc,
 	rolebinding,
 	common.GenerateService(Component, []common.ServicePort{
 		{
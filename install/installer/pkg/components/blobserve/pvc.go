@@ -0,0 +1,55 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package blobserve
+
+import (
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// cacheConfig returns the configured cache, or nil when the defaults apply
+func cacheConfig(ctx *common.RenderContext) *config.BlobserveCache {
+	if ctx.Config.Components == nil || ctx.Config.Components.Blobserve == nil {
+		return nil
+	}
+	return ctx.Config.Components.Blobserve.Cache
+}
+
+func pvc(ctx *common.RenderContext) ([]runtime.Object, error) {
+	cache := cacheConfig(ctx)
+	if cache == nil || cache.PVC == nil {
+		return nil, nil
+	}
+
+	var storageClass *string
+	if cache.PVC.StorageClass != "" {
+		storageClass = &cache.PVC.StorageClass
+	}
+
+	return []runtime.Object{
+		&corev1.PersistentVolumeClaim{
+			TypeMeta: common.TypeMetaPersistentVolumeClaim,
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        CacheClaimName,
+				Namespace:   ctx.Namespace,
+				Labels:      common.CustomizeLabel(ctx, Component, common.TypeMetaPersistentVolumeClaim),
+				Annotations: common.CustomizeAnnotation(ctx, Component, common.TypeMetaPersistentVolumeClaim),
+			},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				StorageClassName: storageClass,
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceStorage: cache.PVC.Size,
+					},
+				},
+			},
+		},
+	}, nil
+}
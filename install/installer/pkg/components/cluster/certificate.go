@@ -0,0 +1,123 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package cluster
+
+import (
+	"fmt"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+
+	cmacme "github.com/jetstack/cert-manager/pkg/apis/acme/v1"
+	v1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const letsEncryptServer = "https://acme-v02.api.letsencrypt.org/directory"
+
+// certificate renders the certificate for the domains that Gitpod is served on
+func certificate(ctx *common.RenderContext) ([]runtime.Object, error) {
+	issuer := ctx.Config.CertificateIssuer
+	if issuer == nil {
+		return nil, nil
+	}
+
+	var objects []runtime.Object
+	issuerKind := v1.ClusterIssuerKind
+	if issuer.DNS01 != nil {
+		acmeIssuer, err := dns01Issuer(ctx, issuer.Name, issuer.DNS01)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, acmeIssuer)
+		issuerKind = v1.IssuerKind
+	}
+
+	dnsNames := []string{
+		ctx.Config.Domain,
+		fmt.Sprintf("*.%s", ctx.Config.Domain),
+	}
+	for _, d := range ctx.Config.WorkspaceDomains() {
+		dnsNames = append(dnsNames, fmt.Sprintf("*.%s", d))
+	}
+	if ctx.Config.PublicAPIEnabled() && !ctx.Config.PublicAPIHostnameCovered() {
+		dnsNames = append(dnsNames, ctx.Config.PublicAPIHostname())
+	}
+
+	return append(objects, &v1.Certificate{
+		TypeMeta: common.TypeMetaCertificate,
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ctx.Config.Certificate.Name,
+			Namespace: ctx.Namespace,
+			Labels:    common.DefaultLabels(Component),
+		},
+		Spec: v1.CertificateSpec{
+			SecretName: ctx.Config.Certificate.Name,
+			DNSNames:   dnsNames,
+			IssuerRef: cmmeta.ObjectReference{
+				Name:  issuer.Name,
+				Kind:  issuerKind,
+				Group: "cert-manager.io",
+			},
+			SecretTemplate: &v1.CertificateSecretTemplate{
+				Labels: common.DefaultLabels(Component),
+			},
+		},
+	}), nil
+}
+
+func dns01Issuer(ctx *common.RenderContext, name string, cfg *config.CertificateIssuerDNS01) (*v1.Issuer, error) {
+	credentials := &cmmeta.SecretKeySelector{
+		LocalObjectReference: cmmeta.LocalObjectReference{Name: cfg.Credentials.Name},
+		Key:                  config.DNS01CredentialsKey(cfg.Provider),
+	}
+
+	var solver cmacme.ACMEChallengeSolverDNS01
+	switch cfg.Provider {
+	case config.DNS01ProviderCloudDNS:
+		solver.CloudDNS = &cmacme.ACMEIssuerDNS01ProviderCloudDNS{
+			ServiceAccount: credentials,
+			Project:        cfg.Project,
+		}
+	case config.DNS01ProviderCloudflare:
+		solver.Cloudflare = &cmacme.ACMEIssuerDNS01ProviderCloudflare{
+			APIToken: credentials,
+		}
+	case config.DNS01ProviderRoute53:
+		solver.Route53 = &cmacme.ACMEIssuerDNS01ProviderRoute53{
+			AccessKeyID:     cfg.AccessKeyID,
+			SecretAccessKey: *credentials,
+			Region:          cfg.Region,
+		}
+	default:
+		return nil, fmt.Errorf("unsupported DNS01 provider: %s", cfg.Provider)
+	}
+
+	server := cfg.Server
+	if server == "" {
+		server = letsEncryptServer
+	}
+
+	return &v1.Issuer{
+		TypeMeta: common.TypeMetaCertificateIssuer,
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ctx.Namespace,
+			Labels:    common.DefaultLabels(Component),
+		},
+		Spec: v1.IssuerSpec{IssuerConfig: v1.IssuerConfig{
+			ACME: &cmacme.ACMEIssuer{
+				Email:  cfg.Email,
+				Server: server,
+				PrivateKey: cmmeta.SecretKeySelector{
+					LocalObjectReference: cmmeta.LocalObjectReference{Name: fmt.Sprintf("%s-account-key", name)},
+				},
+				Solvers: []cmacme.ACMEChallengeSolver{{DNS01: &solver}},
+			},
+		}},
+	}, nil
+}
@@ -21,7 +21,7 @@ import (
 
 func certmanager(ctx *common.RenderContext) ([]runtime.Object, error) {
 	issuerName := "gitpod-self-signed-issuer"
-	secretCAName := "gitpod-identity-trust-root"
+	secretCAName, externalCA := common.InternalCA(ctx)
 
 	// TODO (gpl): This is a workaround to untangle the refactoring of existing infrastructure from
 	// moving forward with this change
@@ -33,49 +33,87 @@ func certmanager(ctx *common.RenderContext) ([]runtime.Object, error) {
 		return nil
 	})
 
-	return []runtime.Object{
-		// Define a self-signed issuer so we can generate a CA
-		&v1.ClusterIssuer{
-			TypeMeta: common.TypeMetaCertificateClusterIssuer,
-			ObjectMeta: metav1.ObjectMeta{
-				Name:   issuerName,
-				Labels: common.DefaultLabels(Component),
-			},
-			Spec: v1.IssuerSpec{IssuerConfig: v1.IssuerConfig{
-				SelfSigned: &v1.SelfSignedIssuer{},
-			}},
+	// The secret of an external CA is that of a CA issuer, its own certificate is in tls.crt
+	caKey := "ca.crt"
+	if externalCA {
+		caKey = "tls.crt"
+	}
+	gitpodCA := trust.BundleSource{
+		Secret: &trust.SourceObjectKeySelector{
+			Name:        secretCAName,
+			KeySelector: trust.KeySelector{Key: caKey},
 		},
-		// Generate that CA
-		&v1.Certificate{
-			TypeMeta: common.TypeMetaCertificate,
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      "gitpod-trust-anchor",
-				Namespace: caCertificateNamespace,
-				Labels:    common.DefaultLabels(Component),
+	}
+	caBundleSources := []trust.BundleSource{{UseDefaultCAs: pointer.Bool(true)}, gitpodCA}
+	if ctx.Config.CustomCACert != nil {
+		caBundleSources = append(caBundleSources, trust.BundleSource{
+			Secret: &trust.SourceObjectKeySelector{
+				Name:        ctx.Config.CustomCACert.Name,
+				KeySelector: trust.KeySelector{Key: "ca.crt"},
 			},
-			Spec: v1.CertificateSpec{
-				IsCA:       true,
-				Duration:   &metav1.Duration{Duration: time.Duration(8760 * time.Hour)}, // 365 days
-				CommonName: "root.gitpod.cluster.local",
-				SecretName: secretCAName,
-				PrivateKey: &v1.CertificatePrivateKey{
-					Algorithm: v1.ECDSAKeyAlgorithm,
-					Size:      256,
-				},
-				IssuerRef: cmmeta.ObjectReference{
-					Name:  issuerName,
-					Kind:  v1.ClusterIssuerKind,
-					Group: "cert-manager.io",
-				},
-				SecretTemplate: &v1.CertificateSecretTemplate{
+		})
+	}
+	for _, scm := range ctx.Config.SCMHosts {
+		if scm.CACert == nil {
+			continue
+		}
+		caBundleSources = append(caBundleSources, trust.BundleSource{
+			Secret: &trust.SourceObjectKeySelector{
+				Name:        scm.CACert.Name,
+				KeySelector: trust.KeySelector{Key: "ca.crt"},
+			},
+		})
+	}
+
+	var objs []runtime.Object
+	if !externalCA {
+		objs = append(objs,
+			// Define a self-signed issuer so we can generate a CA
+			&v1.ClusterIssuer{
+				TypeMeta: common.TypeMetaCertificateClusterIssuer,
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   issuerName,
 					Labels: common.DefaultLabels(Component),
 				},
-				Usages: []v1.KeyUsage{
-					v1.UsageCertSign,
-					v1.UsageCRLSign,
+				Spec: v1.IssuerSpec{IssuerConfig: v1.IssuerConfig{
+					SelfSigned: &v1.SelfSignedIssuer{},
+				}},
+			},
+			// Generate that CA
+			&v1.Certificate{
+				TypeMeta: common.TypeMetaCertificate,
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "gitpod-trust-anchor",
+					Namespace: caCertificateNamespace,
+					Labels:    common.DefaultLabels(Component),
+				},
+				Spec: v1.CertificateSpec{
+					IsCA:       true,
+					Duration:   common.InternalCADuration(ctx),
+					CommonName: "root.gitpod.cluster.local",
+					SecretName: secretCAName,
+					PrivateKey: &v1.CertificatePrivateKey{
+						Algorithm: v1.ECDSAKeyAlgorithm,
+						Size:      256,
+					},
+					IssuerRef: cmmeta.ObjectReference{
+						Name:  issuerName,
+						Kind:  v1.ClusterIssuerKind,
+						Group: "cert-manager.io",
+					},
+					SecretTemplate: &v1.CertificateSecretTemplate{
+						Labels: common.DefaultLabels(Component),
+					},
+					Usages: []v1.KeyUsage{
+						v1.UsageCertSign,
+						v1.UsageCRLSign,
+					},
 				},
 			},
-		},
+		)
+	}
+
+	return append(objs,
 		// Set the CA to our issuer
 		&v1.ClusterIssuer{
 			TypeMeta: common.TypeMetaCertificateClusterIssuer,
@@ -101,7 +139,7 @@ func certmanager(ctx *common.RenderContext) ([]runtime.Object, error) {
 				IsCA:       true,
 				Duration:   &metav1.Duration{Duration: time.Duration(2190 * time.Hour)}, // 90 days
 				CommonName: "ca.gitpod.cluster.local",
-				SecretName: fmt.Sprintf("%v-intermediate", secretCAName),
+				SecretName: fmt.Sprintf("%v-intermediate", common.InternalCASecret),
 				PrivateKey: &v1.CertificatePrivateKey{
 					Algorithm: v1.ECDSAKeyAlgorithm,
 					Size:      256,
@@ -129,17 +167,7 @@ func certmanager(ctx *common.RenderContext) ([]runtime.Object, error) {
 				Name: "gitpod-ca-bundle",
 			},
 			Spec: trust.BundleSpec{
-				Sources: []trust.BundleSource{
-					{
-						UseDefaultCAs: pointer.Bool(true),
-					},
-					{
-						Secret: &trust.SourceObjectKeySelector{
-							Name:        secretCAName,
-							KeySelector: trust.KeySelector{Key: "ca.crt"},
-						},
-					},
-				},
+				Sources: caBundleSources,
 				Target: trust.BundleTarget{
 					ConfigMap: &trust.KeySelector{
 						Key: "ca-certificates.crt",
@@ -154,14 +182,7 @@ func certmanager(ctx *common.RenderContext) ([]runtime.Object, error) {
 				Name: "gitpod-ca",
 			},
 			Spec: trust.BundleSpec{
-				Sources: []trust.BundleSource{
-					{
-						Secret: &trust.SourceObjectKeySelector{
-							Name:        secretCAName,
-							KeySelector: trust.KeySelector{Key: "ca.crt"},
-						},
-					},
-				},
+				Sources: []trust.BundleSource{gitpodCA},
 				Target: trust.BundleTarget{
 					ConfigMap: &trust.KeySelector{
 						Key: "gitpod-ca.crt",
@@ -169,5 +190,5 @@ func certmanager(ctx *common.RenderContext) ([]runtime.Object, error) {
 				},
 			},
 		},
-	}, nil
+	), nil
 }
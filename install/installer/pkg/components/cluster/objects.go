@@ -10,9 +10,11 @@ package cluster
 import "github.com/gitpod-io/gitpod/installer/pkg/common"
 
 var Objects = common.CompositeRenderFunc(
+	certificate,
 	certmanager,
 	clusterrole,
 	podsecuritypolicies,
+	priorityclasses,
 	resourcequota,
 	rolebinding,
 	common.DefaultServiceAccount(NobodyComponent),
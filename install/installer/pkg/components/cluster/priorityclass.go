@@ -0,0 +1,48 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package cluster
+
+import (
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	corev1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func priorityclasses(ctx *common.RenderContext) ([]runtime.Object, error) {
+	if ctx.Config.PriorityClasses == nil || !ctx.Config.PriorityClasses.Enabled {
+		return nil, nil
+	}
+
+	// Under node pressure, the workspaces, which run with the default priority of zero, are
+	// evicted before the components they depend on
+	classes := []struct {
+		Name        string
+		Value       int32
+		Description string
+	}{
+		{Name: common.PriorityClassWorkspaceInfra, Value: 1000000, Description: "Gitpod components that run on the workspace nodes"},
+		{Name: common.PriorityClassMeta, Value: 100000, Description: "Gitpod webapp and IDE components"},
+	}
+
+	var objects []runtime.Object
+	for _, class := range classes {
+		policy := corev1.PreemptLowerPriority
+		objects = append(objects, &schedulingv1.PriorityClass{
+			TypeMeta: common.TypeMetaPriorityClass,
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        class.Name,
+				Labels:      common.CustomizeLabel(ctx, Component, common.TypeMetaPriorityClass),
+				Annotations: common.CustomizeAnnotation(ctx, Component, common.TypeMetaPriorityClass),
+			},
+			Value:            class.Value,
+			GlobalDefault:    false,
+			Description:      class.Description,
+			PreemptionPolicy: &policy,
+		})
+	}
+	return objects, nil
+}
@@ -19,6 +19,7 @@ import (
 )
 
 var Objects = common.CompositeRenderFunc(
+	common.InfrastructureNamespaceObject,
 	agentsmith.Objects,
 	registryfacade.Objects,
 	workspace.Objects,
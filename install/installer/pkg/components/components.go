@@ -5,13 +5,46 @@
 package components
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+
 	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	agentsmith "github.com/gitpod-io/gitpod/installer/pkg/components/agent-smith"
+	"github.com/gitpod-io/gitpod/installer/pkg/components/blobserve"
 	"github.com/gitpod-io/gitpod/installer/pkg/components/cluster"
 	componentside "github.com/gitpod-io/gitpod/installer/pkg/components/components-ide"
 	componentswebapp "github.com/gitpod-io/gitpod/installer/pkg/components/components-webapp"
 	componentsworkspace "github.com/gitpod-io/gitpod/installer/pkg/components/components-workspace"
+	contentservice "github.com/gitpod-io/gitpod/installer/pkg/components/content-service"
+	"github.com/gitpod-io/gitpod/installer/pkg/components/dashboard"
+	"github.com/gitpod-io/gitpod/installer/pkg/components/database"
 	dockerregistry "github.com/gitpod-io/gitpod/installer/pkg/components/docker-registry"
 	"github.com/gitpod-io/gitpod/installer/pkg/components/gitpod"
+	idemetrics "github.com/gitpod-io/gitpod/installer/pkg/components/ide-metrics"
+	ideproxy "github.com/gitpod-io/gitpod/installer/pkg/components/ide-proxy"
+	ideservice "github.com/gitpod-io/gitpod/installer/pkg/components/ide-service"
+	imagebuildermk3 "github.com/gitpod-io/gitpod/installer/pkg/components/image-builder-mk3"
+	imagebuilderwsman "github.com/gitpod-io/gitpod/installer/pkg/components/image-builder-mk3-wsman"
+	"github.com/gitpod-io/gitpod/installer/pkg/components/migrations"
+	"github.com/gitpod-io/gitpod/installer/pkg/components/minio"
+	nodelabeler "github.com/gitpod-io/gitpod/installer/pkg/components/node-labeler"
+	openvsxproxy "github.com/gitpod-io/gitpod/installer/pkg/components/openvsx-proxy"
+	"github.com/gitpod-io/gitpod/installer/pkg/components/proxy"
+	publicapiserver "github.com/gitpod-io/gitpod/installer/pkg/components/public-api-server"
+	"github.com/gitpod-io/gitpod/installer/pkg/components/rabbitmq"
+	"github.com/gitpod-io/gitpod/installer/pkg/components/redis"
+	registryfacade "github.com/gitpod-io/gitpod/installer/pkg/components/registry-facade"
+	"github.com/gitpod-io/gitpod/installer/pkg/components/server"
+	smoketest "github.com/gitpod-io/gitpod/installer/pkg/components/smoke-test"
+	"github.com/gitpod-io/gitpod/installer/pkg/components/spicedb"
+	"github.com/gitpod-io/gitpod/installer/pkg/components/usage"
+	"github.com/gitpod-io/gitpod/installer/pkg/components/workspace"
+	wsdaemon "github.com/gitpod-io/gitpod/installer/pkg/components/ws-daemon"
+	wsmanager "github.com/gitpod-io/gitpod/installer/pkg/components/ws-manager"
+	wsmanagerbridge "github.com/gitpod-io/gitpod/installer/pkg/components/ws-manager-bridge"
+	wsmanagermk2 "github.com/gitpod-io/gitpod/installer/pkg/components/ws-manager-mk2"
+	wsproxy "github.com/gitpod-io/gitpod/installer/pkg/components/ws-proxy"
 )
 
 var MetaObjects = common.CompositeRenderFunc(
@@ -34,6 +67,7 @@ var WorkspaceObjects = common.CompositeRenderFunc(
 var FullObjects = common.CompositeRenderFunc(
 	MetaObjects,
 	WorkspaceObjects,
+	smoketest.Objects,
 )
 
 var MetaHelmDependencies = common.CompositeHelmFunc(
@@ -67,3 +101,78 @@ var CommonObjects = common.CompositeRenderFunc(
 var CommonHelmDependencies = common.CompositeHelmFunc(
 	dockerregistry.Helm,
 )
+
+// ComponentObjects maps the name of each individual component to its render funcs. This
+// allows a single component to be rendered in isolation of the rest of the installation.
+var ComponentObjects = map[string]common.RenderFunc{
+	agentsmith.Component:        agentsmith.Objects,
+	blobserve.Component:         blobserve.Objects,
+	cluster.Component:           cluster.Objects,
+	contentservice.Component:    contentservice.Objects,
+	dashboard.Component:         dashboard.Objects,
+	DatabaseComponent:           database.Objects,
+	dockerregistry.Component:    dockerregistry.Objects,
+	gitpod.Component:            gitpod.Objects,
+	idemetrics.Component:        idemetrics.Objects,
+	ideproxy.Component:          ideproxy.Objects,
+	ideservice.Component:        ideservice.Objects,
+	imagebuildermk3.Component:   imagebuildermk3.Objects,
+	imagebuilderwsman.Component: imagebuilderwsman.Objects,
+	migrations.Component:        migrations.Objects,
+	minio.Component:             minio.Objects,
+	nodelabeler.Component:       nodelabeler.Objects,
+	openvsxproxy.Component:      openvsxproxy.Objects,
+	proxy.Component:             proxy.Objects,
+	publicapiserver.Component:   publicapiserver.Objects,
+	rabbitmq.Component:          rabbitmq.Objects,
+	redis.Component:             redis.Objects,
+	registryfacade.Component:    registryfacade.Objects,
+	server.Component:            server.Objects,
+	smoketest.Component:         smoketest.Objects,
+	spicedb.Component:           spicedb.Objects,
+	usage.Component:             usage.Objects,
+	workspace.Component:         workspace.Objects,
+	wsdaemon.Component:          wsdaemon.Objects,
+	wsmanager.Component:         wsmanager.Objects,
+	wsmanagerbridge.Component:   wsmanagerbridge.Objects,
+	wsmanagermk2.Component:      wsmanagermk2.Objects,
+	wsproxy.Component:           wsproxy.Objects,
+}
+
+// ComponentHelmDependencies maps the name of a component to the Helm charts it depends upon
+var ComponentHelmDependencies = map[string]common.HelmFunc{
+	DatabaseComponent:        database.Helm,
+	dockerregistry.Component: dockerregistry.Helm,
+	minio.Component:          minio.Helm,
+	rabbitmq.Component:       rabbitmq.Helm,
+}
+
+// DatabaseComponent is the name used to select the database, regardless of whether it is
+// running in-cluster, via CloudSQL or is an external instance
+const DatabaseComponent = "database"
+
+// ComponentNames returns the sorted names of all the components that can be rendered individually
+func ComponentNames() []string {
+	names := make([]string, 0, len(ComponentObjects))
+	for name := range ComponentObjects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// ForComponent returns the render funcs and Helm dependencies of a single component
+func ForComponent(name string) (common.RenderFunc, common.HelmFunc, error) {
+	objects, ok := ComponentObjects[name]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown component %s - valid components are: %s", name, strings.Join(ComponentNames(), ", "))
+	}
+
+	helm, ok := ComponentHelmDependencies[name]
+	if !ok {
+		helm = common.CompositeHelmFunc()
+	}
+
+	return objects, helm, nil
+}
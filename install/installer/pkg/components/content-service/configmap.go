@@ -29,6 +29,18 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 		return nil, fmt.Errorf("failed to marshal content-service config: %w", err)
 	}
 
+	data := map[string]string{
+		"config.json": string(fc),
+	}
+	if ctx.Config.ObjectStorage.ConnectivityCheck {
+		// The test command of content-service reads the storage config on its own
+		storage, err := common.ToJSONString(cscfg.Storage)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal storage config: %w", err)
+		}
+		data[storageConfigFile] = string(storage)
+	}
+
 	return []runtime.Object{&corev1.ConfigMap{
 		TypeMeta: common.TypeMetaConfigmap,
 		ObjectMeta: metav1.ObjectMeta{
@@ -37,8 +49,6 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 			Labels:      common.CustomizeLabel(ctx, Component, common.TypeMetaConfigmap),
 			Annotations: common.CustomizeAnnotation(ctx, Component, common.TypeMetaConfigmap),
 		},
-		Data: map[string]string{
-			"config.json": string(fc),
-		},
+		Data: data,
 	}}, nil
 }
@@ -8,4 +8,10 @@ const (
 	Component      = "content-service"
 	RPCPort        = 8080
 	RPCServiceName = "rpc"
+
+	// StorageCheckComponent is the job that checks the connectivity to the external storage
+	StorageCheckComponent = "content-service-storage-check"
+
+	storageConfigFile = "storage.json"
+	storageCheckOwner = "00000000-0000-0000-0000-000000000000"
 )
@@ -26,7 +26,9 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 	}
 
 	podSpec := corev1.PodSpec{
-		Affinity:                      cluster.WithNodeAffinityHostnameAntiAffinity(Component, cluster.AffinityLabelMeta),
+		Affinity:                      common.Affinity(ctx, Component, cluster.WithNodeAffinityHostnameAntiAffinity(Component, cluster.AffinityLabelMeta)),
+		PriorityClassName:             common.PriorityClassName(ctx, Component, ""),
+		Tolerations:                   common.Tolerations(ctx, Component, nil),
 		TopologySpreadConstraints:     cluster.WithHostnameTopologySpread(Component),
 		ServiceAccountName:            Component,
 		EnableServiceLinks:            pointer.Bool(false),
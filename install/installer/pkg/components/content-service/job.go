@@ -0,0 +1,103 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package content_service
+
+import (
+	"fmt"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+)
+
+// storageCheckJob lists the objects of an owner with the storage config and credentials of
+// content-service. The owner has the length of a user ID, so that its bucket is named like
+// theirs where the storage has a bucket per owner.
+func storageCheckJob(ctx *common.RenderContext) ([]runtime.Object, error) {
+	if !ctx.Config.ObjectStorage.ConnectivityCheck {
+		return nil, nil
+	}
+
+	// The job is not labelled content-service, which would select it for the service
+	objectMeta := metav1.ObjectMeta{
+		Name:        StorageCheckComponent,
+		Namespace:   ctx.Namespace,
+		Labels:      common.CustomizeLabel(ctx, StorageCheckComponent, common.TypeMetaBatchJob),
+		Annotations: common.CustomizeAnnotation(ctx, StorageCheckComponent, common.TypeMetaBatchJob),
+	}
+
+	// Like the migrations, the job runs after the install or upgrade of a Helm release
+	jobMeta := objectMeta
+	if ctx.Config.ApplyOrder == nil {
+		jobMeta.Annotations = common.CustomizeAnnotation(ctx, StorageCheckComponent, common.TypeMetaBatchJob, func() map[string]string {
+			return map[string]string{
+				"helm.sh/hook":               "post-install,post-upgrade",
+				"helm.sh/hook-delete-policy": "before-hook-creation",
+			}
+		})
+	}
+
+	podSpec := corev1.PodSpec{
+		RestartPolicy:      corev1.RestartPolicyNever,
+		ServiceAccountName: Component,
+		EnableServiceLinks: pointer.Bool(false),
+		Volumes: []corev1.Volume{{
+			Name: "config",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: Component},
+				},
+			},
+		}},
+		Containers: []corev1.Container{{
+			Name:            StorageCheckComponent,
+			Image:           ctx.ImageName(ctx.Config.Repository, Component, ctx.VersionManifest.Components.ContentService.Version),
+			ImagePullPolicy: corev1.PullIfNotPresent,
+			Args: []string{
+				"test",
+				"--config",
+				"/config/" + storageConfigFile,
+				"direct",
+				"list",
+				"--owner",
+				storageCheckOwner,
+				fmt.Sprintf("%s/", storageCheckOwner),
+			},
+			Resources: common.ResourceRequirements(ctx, StorageCheckComponent, StorageCheckComponent, corev1.ResourceRequirements{}),
+			SecurityContext: &corev1.SecurityContext{
+				Privileged:               pointer.Bool(false),
+				AllowPrivilegeEscalation: pointer.Bool(false),
+				RunAsUser:                pointer.Int64(1000),
+			},
+			Env: common.CustomizeEnvvar(ctx, StorageCheckComponent, common.DefaultEnv(&ctx.Config)),
+			VolumeMounts: []corev1.VolumeMount{{
+				Name:      "config",
+				MountPath: "/config",
+				ReadOnly:  true,
+			}},
+		}},
+	}
+
+	if err := common.AddStorageMounts(ctx, &podSpec, StorageCheckComponent); err != nil {
+		return nil, err
+	}
+
+	return []runtime.Object{&batchv1.Job{
+		TypeMeta:   common.TypeMetaBatchJob,
+		ObjectMeta: jobMeta,
+		Spec: batchv1.JobSpec{
+			TTLSecondsAfterFinished: pointer.Int32(60),
+			BackoffLimit:            pointer.Int32(3),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: objectMeta,
+				Spec:       podSpec,
+			},
+		},
+	}}, nil
+}
@@ -24,8 +24,11 @@ func networkpolicy(ctx *common.RenderContext) ([]runtime.Object, error) {
 		},
 		Spec: networkingv1.NetworkPolicySpec{
 			PodSelector: metav1.LabelSelector{MatchLabels: labels},
-			PolicyTypes: []networkingv1.PolicyType{"Ingress"},
-			Ingress:     []networkingv1.NetworkPolicyIngressRule{{}},
+			PolicyTypes: common.NetworkPolicyTypes(ctx),
+			Egress: common.NetworkPolicyEgress(ctx,
+				common.AllowObjectStorageEgressRule(ctx),
+			),
+			Ingress: []networkingv1.NetworkPolicyIngressRule{{}},
 		},
 	}}, nil
 }
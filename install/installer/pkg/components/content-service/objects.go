@@ -14,6 +14,7 @@ var Objects = common.CompositeRenderFunc(
 	deployment,
 	networkpolicy,
 	rolebinding,
+	storageCheckJob,
 	common.GenerateService(Component, []common.ServicePort{
 		{
 			Name:          RPCServiceName,
@@ -41,7 +41,9 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 						Annotations: common.CustomizeAnnotation(ctx, Component, common.TypeMetaDeployment),
 					},
 					Spec: corev1.PodSpec{
-						Affinity:                      cluster.WithNodeAffinityHostnameAntiAffinity(Component, cluster.AffinityLabelMeta),
+						Affinity:                      common.Affinity(ctx, Component, cluster.WithNodeAffinityHostnameAntiAffinity(Component, cluster.AffinityLabelMeta)),
+						PriorityClassName:             common.PriorityClassName(ctx, Component, ""),
+						Tolerations:                   common.Tolerations(ctx, Component, nil),
 						TopologySpreadConstraints:     cluster.WithHostnameTopologySpread(Component),
 						ServiceAccountName:            Component,
 						EnableServiceLinks:            pointer.Bool(false),
@@ -8,6 +8,7 @@ import "github.com/gitpod-io/gitpod/installer/pkg/common"
 
 var Objects = common.CompositeRenderFunc(
 	deployment,
+	common.GenerateHorizontalPodAutoscaler(Component),
 	networkpolicy,
 	rolebinding,
 	common.GenerateService(Component, []common.ServicePort{
@@ -4,8 +4,10 @@
 
 package cloudsql
 
+import "github.com/gitpod-io/gitpod/installer/pkg/common"
+
 const (
-	Component    = "cloudsqlproxy"
+	Component    = common.CloudSQLProxyComponent
 	ImageRepo    = "b.gcr.io/cloudsql-docker"
 	ImageName    = "gce-proxy"
 	ImageVersion = "1.11"
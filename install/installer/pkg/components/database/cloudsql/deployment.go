@@ -85,7 +85,8 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 								MountPath: "/credentials",
 								Name:      "gcloud-sql-token",
 							}},
-							Env: common.CustomizeEnvvar(ctx, Component, []corev1.EnvVar{}),
+							Resources: common.ResourceRequirements(ctx, Component, "cloud-sql-proxy", corev1.ResourceRequirements{}),
+							Env:       common.CustomizeEnvvar(ctx, Component, []corev1.EnvVar{}),
 						}},
 					},
 				},
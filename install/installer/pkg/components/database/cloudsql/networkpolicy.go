@@ -0,0 +1,46 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package cloudsql
+
+import (
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// networkpolicy allows the Gitpod components to reach the database through the Cloud SQL proxy.
+func networkpolicy(ctx *common.RenderContext) ([]runtime.Object, error) {
+	if !common.StrictNetworkPolicy(ctx) {
+		return nil, nil
+	}
+
+	labels := common.DefaultLabels(Component)
+
+	return []runtime.Object{&networkingv1.NetworkPolicy{
+		TypeMeta: common.TypeMetaNetworkPolicy,
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      Component,
+			Namespace: ctx.Namespace,
+			Labels:    labels,
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: labels},
+			PolicyTypes: common.NetworkPolicyTypes(ctx),
+			Egress:      common.NetworkPolicyEgress(ctx, common.AllowExternalEgressRule()),
+			Ingress: []networkingv1.NetworkPolicyIngressRule{{
+				Ports: []networkingv1.NetworkPolicyPort{{
+					Protocol: common.TCPProtocol,
+					Port:     &intstr.IntOrString{IntVal: Port},
+				}},
+				From: []networkingv1.NetworkPolicyPeer{{
+					PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": common.AppName}},
+				}},
+			}},
+		},
+	}}, nil
+}
@@ -12,6 +12,7 @@ import (
 var Objects = common.CompositeRenderFunc(
 	deployment,
 	dbinit.Objects,
+	networkpolicy,
 	rolebinding,
 	common.DefaultServiceAccount(Component),
 	common.GenerateService(Component, []common.ServicePort{
@@ -7,8 +7,15 @@ package external
 import (
 	"github.com/gitpod-io/gitpod/installer/pkg/common"
 	dbinit "github.com/gitpod-io/gitpod/installer/pkg/components/database/init"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 var Objects = common.CompositeRenderFunc(
-	dbinit.Objects,
+	func(ctx *common.RenderContext) ([]runtime.Object, error) {
+		// The init scripts create the MySQL schema
+		if ctx.Config.Database.UsePostgres() {
+			return nil, nil
+		}
+		return dbinit.Objects(ctx)
+	},
 )
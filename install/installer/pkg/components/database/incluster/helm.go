@@ -29,7 +29,7 @@ var Helm = common.CompositeHelmFunc(
 		return &common.HelmConfig{
 			Enabled: true,
 			Values: &values.Options{
-				Values: []string{
+				Values: append([]string{
 					helm.KeyValue("mysql.auth.existingSecret", SQLPasswordName),
 					helm.KeyValue("mysql.auth.database", Database),
 					helm.KeyValue("mysql.auth.username", Username),
@@ -46,7 +46,7 @@ var Helm = common.CompositeHelmFunc(
 					// improve start time
 					helm.KeyValue("mysql.primary.startupProbe.enabled", "false"),
 					helm.KeyValue("mysql.primary.livenessProbe.initialDelaySeconds", "30"),
-				},
+				}, helm.OpenShiftValues(cfg, "mysql", "mysql.primary")...),
 				// This is too complex to be sent as a string
 				FileValues: []string{
 					primaryAffinityTemplate,
@@ -10,6 +10,9 @@ import (
 
 var Objects = common.CompositeRenderFunc(
 	configmap,
+	common.HelmDependencyNetworkPolicy(Component, map[string]string{
+		"app.kubernetes.io/name": "mysql",
+	}),
 	rolebinding,
 	secrets,
 	service,
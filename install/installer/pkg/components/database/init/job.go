@@ -76,6 +76,7 @@ func job(ctx *common.RenderContext) ([]runtime.Object, error) {
 						Name:            fmt.Sprintf("%s-session", Component),
 						Image:           ctx.ImageName(common.ThirdPartyContainerRepo(ctx.Config.Repository, ""), dbSessionsImage, dbSessionsTag),
 						ImagePullPolicy: corev1.PullIfNotPresent,
+						Resources:       common.ResourceRequirements(ctx, Component, fmt.Sprintf("%s-session", Component), corev1.ResourceRequirements{}),
 						Env: common.MergeEnv(
 							common.DatabaseEnv(&ctx.Config),
 						),
@@ -0,0 +1,38 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package init
+
+import (
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// networkpolicy allows the database init job to reach the database.
+func networkpolicy(ctx *common.RenderContext) ([]runtime.Object, error) {
+	if !common.StrictNetworkPolicy(ctx) {
+		return nil, nil
+	}
+
+	labels := common.DefaultLabels(Component)
+
+	return []runtime.Object{&networkingv1.NetworkPolicy{
+		TypeMeta: common.TypeMetaNetworkPolicy,
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      Component,
+			Namespace: ctx.Namespace,
+			Labels:    labels,
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: labels},
+			PolicyTypes: common.NetworkPolicyTypes(ctx),
+			Egress: common.NetworkPolicyEgress(ctx,
+				common.AllowDatabaseEgressRule(ctx),
+			),
+		},
+	}}, nil
+}
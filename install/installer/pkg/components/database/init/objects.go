@@ -11,6 +11,7 @@ import (
 var Objects = common.CompositeRenderFunc(
 	configmap,
 	job,
+	networkpolicy,
 	rolebinding,
 	common.DefaultServiceAccount(Component),
 )
@@ -9,6 +9,12 @@ import "github.com/gitpod-io/gitpod/installer/pkg/common"
 const (
 	BuiltInRegistryAuth  = common.RegistryAuthSecret
 	BuiltInRegistryCerts = common.RegistryTLSCertSecret
-	Component            = "docker-registry"
+	Component            = common.DockerRegistryComponent
 	RegistryName         = "registry"
+	// ImageTag is the version of the registry, which the garbage collection must run with too
+	ImageTag = "2.8.1"
+
+	garbageCollectionComponent = "registry-gc"
+	garbageCollectionSchedule  = "0 2 * * *"
+	storageMountPath           = "/var/lib/registry"
 )
@@ -0,0 +1,127 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package dockerregistry
+
+import (
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+)
+
+// usesVolume is true when the registry keeps the images on its persistent volume rather than in S3
+func usesVolume(ctx *common.RenderContext) bool {
+	return ctx.Config.ContainerRegistry.S3Storage == nil
+}
+
+// cronjob collects the garbage of the registry. The volume can only be mounted on one node, so the
+// pods run next to the registry.
+func cronjob(ctx *common.RenderContext) ([]runtime.Object, error) {
+	gc := ctx.Config.ContainerRegistry.GarbageCollection
+	if gc == nil || !gc.Enabled || !usesVolume(ctx) {
+		return nil, nil
+	}
+
+	schedule := garbageCollectionSchedule
+	if gc.Schedule != "" {
+		schedule = gc.Schedule
+	}
+
+	script := ""
+	if gc.RetentionDays != nil {
+		// A tag's link is written whenever the tag is pushed
+		script += fmt.Sprintf(`find %s/docker/registry/v2/repositories -path '*/_manifests/tags/*/current/link' -mtime +%d | while read -r link; do
+  echo "removing tag ${link%%/current/link}"
+  rm -rf "${link%%/current/link}"
+done
+`, storageMountPath, *gc.RetentionDays)
+	}
+	script += "registry garbage-collect --delete-untagged /etc/docker/registry/config.yml\n"
+
+	objectMeta := metav1.ObjectMeta{
+		Name:      garbageCollectionComponent,
+		Namespace: ctx.Namespace,
+		Labels:    common.CustomizeLabel(ctx, garbageCollectionComponent, common.TypeMetaBatchCronJob),
+	}
+
+	return []runtime.Object{
+		&batchv1.CronJob{
+			TypeMeta:   common.TypeMetaBatchCronJob,
+			ObjectMeta: objectMeta,
+			Spec: batchv1.CronJobSpec{
+				Schedule:                   schedule,
+				SuccessfulJobsHistoryLimit: pointer.Int32(1),
+				FailedJobsHistoryLimit:     pointer.Int32(1),
+				ConcurrencyPolicy:          batchv1.ForbidConcurrent,
+				JobTemplate: batchv1.JobTemplateSpec{
+					ObjectMeta: objectMeta,
+					Spec: batchv1.JobSpec{
+						Template: corev1.PodTemplateSpec{
+							ObjectMeta: objectMeta,
+							Spec: corev1.PodSpec{
+								RestartPolicy:      corev1.RestartPolicyOnFailure,
+								ServiceAccountName: Component,
+								EnableServiceLinks: pointer.Bool(false),
+								Affinity: &corev1.Affinity{
+									PodAffinity: &corev1.PodAffinity{
+										RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{
+											LabelSelector: &metav1.LabelSelector{
+												MatchLabels: map[string]string{"app": Component},
+											},
+											TopologyKey: "kubernetes.io/hostname",
+										}},
+									},
+								},
+								SecurityContext: &corev1.PodSecurityContext{
+									RunAsUser: pointer.Int64(1000),
+									FSGroup:   pointer.Int64(1000),
+								},
+								Containers: []corev1.Container{{
+									Name:            garbageCollectionComponent,
+									Image:           ctx.ImageName(common.ThirdPartyContainerRepo(ctx.Config.Repository, common.DockerRegistryURL), "library/registry", ImageTag),
+									ImagePullPolicy: corev1.PullIfNotPresent,
+									Resources:       common.ResourceRequirements(ctx, Component, garbageCollectionComponent, corev1.ResourceRequirements{}),
+									Command:         []string{"/bin/sh", "-c", script},
+									Env: []corev1.EnvVar{{
+										Name:  "REGISTRY_STORAGE_FILESYSTEM_ROOTDIRECTORY",
+										Value: storageMountPath,
+									}},
+									SecurityContext: &corev1.SecurityContext{
+										AllowPrivilegeEscalation: pointer.Bool(false),
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{Name: "data", MountPath: storageMountPath},
+										{Name: "config", MountPath: "/etc/docker/registry"},
+									},
+								}},
+								Volumes: []corev1.Volume{
+									{
+										Name: "data",
+										VolumeSource: corev1.VolumeSource{
+											PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: RegistryName},
+										},
+									},
+									{
+										Name: "config",
+										VolumeSource: corev1.VolumeSource{
+											ConfigMap: &corev1.ConfigMapVolumeSource{
+												LocalObjectReference: corev1.LocalObjectReference{Name: fmt.Sprintf("%s-config", RegistryName)},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package dockerregistry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/utils/pointer"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+	"github.com/gitpod-io/gitpod/installer/pkg/config/versions"
+)
+
+func TestCronJob(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Registry config.ContainerRegistry
+		Schedule string
+		Script   string
+	}{
+		{
+			Name:     "disabled",
+			Registry: config.ContainerRegistry{InCluster: pointer.Bool(true)},
+		},
+		{
+			Name: "untagged images",
+			Registry: config.ContainerRegistry{
+				InCluster:         pointer.Bool(true),
+				GarbageCollection: &config.RegistryGarbageCollection{Enabled: true},
+			},
+			Schedule: "0 2 * * *",
+			Script:   "registry garbage-collect --delete-untagged /etc/docker/registry/config.yml\n",
+		},
+		{
+			Name: "retention",
+			Registry: config.ContainerRegistry{
+				InCluster:         pointer.Bool(true),
+				GarbageCollection: &config.RegistryGarbageCollection{Enabled: true, Schedule: "0 4 * * 0", RetentionDays: pointer.Int32(30)},
+			},
+			Schedule: "0 4 * * 0",
+			Script: `find /var/lib/registry/docker/registry/v2/repositories -path '*/_manifests/tags/*/current/link' -mtime +30 | while read -r link; do
+  echo "removing tag ${link%/current/link}"
+  rm -rf "${link%/current/link}"
+done
+registry garbage-collect --delete-untagged /etc/docker/registry/config.yml
+`,
+		},
+		{
+			Name: "stored in S3",
+			Registry: config.ContainerRegistry{
+				InCluster:         pointer.Bool(true),
+				S3Storage:         &config.S3Storage{Bucket: "registry"},
+				GarbageCollection: &config.RegistryGarbageCollection{Enabled: true},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			ctx, err := common.NewRenderContext(config.Config{ContainerRegistry: test.Registry}, versions.Manifest{}, "test_namespace")
+			require.NoError(t, err)
+
+			objs, err := cronjob(ctx)
+			require.NoError(t, err)
+			if test.Script == "" {
+				require.Empty(t, objs)
+				return
+			}
+
+			require.Len(t, objs, 1)
+			job := objs[0].(*batchv1.CronJob)
+			require.Equal(t, test.Schedule, job.Spec.Schedule)
+			require.Equal(t, []string{"/bin/sh", "-c", test.Script}, job.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Command)
+		})
+	}
+}
@@ -6,6 +6,7 @@ package dockerregistry
 
 import (
 	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/utils/pointer"
 )
@@ -13,12 +14,23 @@ import (
 var Objects = common.CompositeRenderFunc(
 	certificate,
 	rolebinding,
-	secret,
+	AuthSecret,
 	func(ctx *common.RenderContext) ([]runtime.Object, error) {
 		if !pointer.BoolDeref(ctx.Config.ContainerRegistry.InCluster, false) {
 			return nil, nil
 		}
 
-		return common.DefaultServiceAccount(Component)(ctx)
+		var egress []networkingv1.NetworkPolicyEgressRule
+		if ctx.Config.ContainerRegistry.S3Storage != nil {
+			// the bucket the images are stored in
+			egress = append(egress, common.AllowExternalEgressRule())
+		}
+
+		return common.CompositeRenderFunc(
+			common.DefaultServiceAccount(Component),
+			cronjob,
+			prometheusrule,
+			common.HelmDependencyNetworkPolicy(Component, common.DefaultLabels(Component), egress...),
+		)(ctx)
 	},
 )
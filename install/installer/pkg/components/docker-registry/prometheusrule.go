@@ -0,0 +1,30 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package dockerregistry
+
+import (
+	"fmt"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func prometheusrule(ctx *common.RenderContext) ([]runtime.Object, error) {
+	if !usesVolume(ctx) {
+		return nil, nil
+	}
+
+	return common.GeneratePrometheusRule(Component, []common.PrometheusRule{
+		{
+			Alert: "GitpodRegistryVolumeFull",
+			Expr: fmt.Sprintf(`kubelet_volume_stats_used_bytes{namespace="%[1]s", persistentvolumeclaim="%[2]s"}
+  / kubelet_volume_stats_capacity_bytes{namespace="%[1]s", persistentvolumeclaim="%[2]s"} > 0.8`, ctx.Namespace, RegistryName),
+			For:         "15m",
+			Severity:    "warning",
+			Summary:     "The volume of the in-cluster registry is filling up",
+			Description: "More than 80% of the volume of the in-cluster registry is used. Enable the garbage collection of the registry or increase the size of the volume.",
+		},
+	})(ctx)
+}
@@ -11,6 +11,7 @@ import (
 	"fmt"
 
 	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	"golang.org/x/crypto/bcrypt"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -18,7 +19,8 @@ import (
 	"k8s.io/utils/pointer"
 )
 
-func secret(ctx *common.RenderContext) ([]runtime.Object, error) {
+// AuthSecret renders the pull secret of the in-cluster registry
+func AuthSecret(ctx *common.RenderContext) ([]runtime.Object, error) {
 	if !pointer.BoolDeref(ctx.Config.ContainerRegistry.InCluster, false) {
 		return nil, nil
 	}
@@ -33,6 +35,17 @@ func secret(ctx *common.RenderContext) ([]runtime.Object, error) {
 		return nil, fmt.Errorf("unknown value: internal registry password")
 	}
 
+	// bcrypt ignores everything after the first 72 bytes of a password
+	if len(password) > 72 {
+		return nil, fmt.Errorf("the internal registry password is longer than 72 bytes")
+	}
+	// The hash is generated once for the secret, the proxy reads it from there base64-encoded. Its
+	// salt is random, so it differs between renders, but every hash matches the same password.
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
 	// todo(sje): handle if bypassing registry with proxy
 	registryHost := "registry." + ctx.Config.Domain
 
@@ -59,6 +72,7 @@ func secret(ctx *common.RenderContext) ([]runtime.Object, error) {
 			".dockerconfigjson": config,
 			"user":              []byte(user),
 			"password":          []byte(password),
+			"passwordHash":      []byte(base64.StdEncoding.EncodeToString(passwordHash)),
 		},
 	}}, nil
 }
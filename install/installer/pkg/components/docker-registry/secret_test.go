@@ -0,0 +1,45 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package dockerregistry
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+	"github.com/gitpod-io/gitpod/installer/pkg/config/versions"
+)
+
+func TestAuthSecretPasswordHash(t *testing.T) {
+	ctx, err := common.NewSeededRenderContext(config.Config{
+		Domain:            "gitpod.example.com",
+		ContainerRegistry: config.ContainerRegistry{InCluster: pointer.Bool(true)},
+	}, versions.Manifest{}, "test_namespace", []byte("seed"))
+	require.NoError(t, err)
+
+	passwordHash := func() []byte {
+		objects, err := AuthSecret(ctx)
+		require.NoError(t, err)
+
+		hash, err := base64.StdEncoding.DecodeString(string(objects[0].(*corev1.Secret).Data["passwordHash"]))
+		require.NoError(t, err)
+		return hash
+	}
+
+	hash := passwordHash()
+	require.NoError(t, bcrypt.CompareHashAndPassword(hash, []byte(ctx.Values.InternalRegistryPassword)))
+	require.Error(t, bcrypt.CompareHashAndPassword(hash, []byte(ctx.Values.InternalRegistryUsername)))
+
+	// The salt is random, but the hash of every render matches the password
+	again := passwordHash()
+	require.NotEqual(t, hash, again)
+	require.NoError(t, bcrypt.CompareHashAndPassword(again, []byte(ctx.Values.InternalRegistryPassword)))
+}
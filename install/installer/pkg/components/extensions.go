@@ -0,0 +1,97 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package components
+
+import (
+	"fmt"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+)
+
+// Extension is a component that is not part of Gitpod. Downstream distributions register their
+// extensions before calling cmd.Execute in their own build of the installer, and the extension
+// objects are rendered with the same context, versions and customizations as Gitpod's components.
+type Extension struct {
+	// Name is used to render the extension on its own with --component
+	Name string
+	// Kinds are the installation kinds the extension is rendered for - all kinds when empty
+	Kinds []config.InstallationKind
+	// Objects renders the Kubernetes objects of the extension
+	Objects common.RenderFunc
+	// Helm renders the Helm charts the extension depends on, if any
+	Helm common.HelmFunc
+}
+
+var extensions []Extension
+
+// includedKinds lists the kinds of the components each installation kind is made of
+var includedKinds = map[config.InstallationKind][]config.InstallationKind{
+	config.InstallationFull: {config.InstallationFull, config.InstallationMeta, config.InstallationIDE, config.InstallationWebApp, config.InstallationWorkspace},
+	config.InstallationMeta: {config.InstallationMeta, config.InstallationIDE, config.InstallationWebApp},
+}
+
+// Register adds an extension to the components that are rendered
+func Register(ext Extension) error {
+	if ext.Name == "" {
+		return fmt.Errorf("extension requires a name")
+	}
+	if ext.Objects == nil {
+		return fmt.Errorf("extension %s requires a render func", ext.Name)
+	}
+	if _, ok := ComponentObjects[ext.Name]; ok {
+		return fmt.Errorf("component %s already exists", ext.Name)
+	}
+	if ext.Helm == nil {
+		ext.Helm = common.CompositeHelmFunc()
+	}
+
+	extensions = append(extensions, ext)
+	ComponentObjects[ext.Name] = ext.Objects
+	ComponentHelmDependencies[ext.Name] = ext.Helm
+
+	return nil
+}
+
+// ExtensionObjects returns the render funcs of the registered extensions for an installation kind
+func ExtensionObjects(kind config.InstallationKind) common.RenderFunc {
+	var funcs []common.RenderFunc
+	for _, ext := range extensions {
+		if ext.renderedFor(kind) {
+			funcs = append(funcs, ext.Objects)
+		}
+	}
+	return common.CompositeRenderFunc(funcs...)
+}
+
+// ExtensionHelmDependencies returns the Helm funcs of the registered extensions for an installation kind
+func ExtensionHelmDependencies(kind config.InstallationKind) common.HelmFunc {
+	var funcs []common.HelmFunc
+	for _, ext := range extensions {
+		if ext.renderedFor(kind) {
+			funcs = append(funcs, ext.Helm)
+		}
+	}
+	return common.CompositeHelmFunc(funcs...)
+}
+
+func (ext Extension) renderedFor(kind config.InstallationKind) bool {
+	if len(ext.Kinds) == 0 {
+		return true
+	}
+
+	included, ok := includedKinds[kind]
+	if !ok {
+		included = []config.InstallationKind{kind}
+	}
+	for _, k := range ext.Kinds {
+		for _, i := range included {
+			if k == i {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package components_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	"github.com/gitpod-io/gitpod/installer/pkg/components"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+	"github.com/gitpod-io/gitpod/installer/pkg/config/versions"
+)
+
+func TestRegister(t *testing.T) {
+	ext := components.Extension{
+		Name:  "auth-adapter",
+		Kinds: []config.InstallationKind{config.InstallationWebApp},
+		Objects: func(ctx *common.RenderContext) ([]runtime.Object, error) {
+			return []runtime.Object{&corev1.ConfigMap{
+				TypeMeta:   common.TypeMetaConfigmap,
+				ObjectMeta: metav1.ObjectMeta{Name: "auth-adapter", Namespace: ctx.Namespace},
+			}}, nil
+		},
+	}
+	require.NoError(t, components.Register(ext))
+	require.Error(t, components.Register(ext), "the name is already taken")
+	require.Error(t, components.Register(components.Extension{Name: "server", Objects: ext.Objects}), "the name is already taken by a Gitpod component")
+
+	require.Contains(t, components.ComponentNames(), "auth-adapter")
+	_, _, err := components.ForComponent("auth-adapter")
+	require.NoError(t, err)
+
+	ctx, err := common.NewRenderContext(config.Config{}, versions.Manifest{}, "test")
+	require.NoError(t, err)
+
+	for kind, expected := range map[config.InstallationKind]int{
+		config.InstallationFull:      1,
+		config.InstallationMeta:      1,
+		config.InstallationWebApp:    1,
+		config.InstallationWorkspace: 0,
+	} {
+		objs, err := components.ExtensionObjects(kind)(ctx)
+		require.NoError(t, err)
+		require.Len(t, objs, expected, kind)
+	}
+}
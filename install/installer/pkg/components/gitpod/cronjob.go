@@ -18,7 +18,8 @@ import (
 )
 
 func cronjob(ctx *common.RenderContext) ([]runtime.Object, error) {
-	if ctx.Config.Kind == config.InstallationWorkspace {
+	// The job reads the telemetry data from the installation-admin port of the server
+	if ctx.Config.Kind == config.InstallationWorkspace || ctx.Config.TelemetryDisabled() || ctx.Config.InstallationAdminExposure() == config.InstallationAdminDisabled {
 		return []runtime.Object{}, nil
 	}
 
@@ -61,13 +62,14 @@ func cronjob(ctx *common.RenderContext) ([]runtime.Object, error) {
 										Name:            installationTelemetryComponent,
 										Image:           ctx.ImageName(ctx.Config.Repository, "installation-telemetry", ctx.VersionManifest.Components.InstallationTelemetry.Version),
 										ImagePullPolicy: v1.PullIfNotPresent,
+										Resources:       common.ResourceRequirements(ctx, Component, installationTelemetryComponent, v1.ResourceRequirements{}),
 										Args: []string{
 											"send",
 										},
 										SecurityContext: &v1.SecurityContext{
 											AllowPrivilegeEscalation: pointer.Bool(false),
 										},
-										Env: []v1.EnvVar{
+										Env: append([]v1.EnvVar{
 											{
 												Name:  "GITPOD_INSTALLATION_VERSION",
 												Value: ctx.VersionManifest.Version,
@@ -80,7 +82,7 @@ func cronjob(ctx *common.RenderContext) ([]runtime.Object, error) {
 												Name:  "SERVER_URL",
 												Value: fmt.Sprintf("http://%s.%s.svc.cluster.local:%d", common.ServerComponent, ctx.Namespace, common.ServerInstallationAdminPort),
 											},
-										},
+										}, segmentEndpointEnv(ctx)...),
 									},
 								},
 							},
@@ -91,3 +93,14 @@ func cronjob(ctx *common.RenderContext) ([]runtime.Object, error) {
 		},
 	}, nil
 }
+
+// segmentEndpointEnv sends the telemetry to the endpoint of the analytics instead of Segment's API
+func segmentEndpointEnv(ctx *common.RenderContext) []v1.EnvVar {
+	if ctx.Config.Analytics == nil || ctx.Config.Analytics.SegmentEndpoint == "" {
+		return nil
+	}
+	return []v1.EnvVar{{
+		Name:  "GITPOD_ANALYTICS_SEGMENT_ENDPOINT",
+		Value: ctx.Config.Analytics.SegmentEndpoint,
+	}}
+}
@@ -0,0 +1,40 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package gitpod
+
+import (
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// networkpolicy allows the telemetry job to send the installation's telemetry.
+func networkpolicy(ctx *common.RenderContext) ([]runtime.Object, error) {
+	if !common.StrictNetworkPolicy(ctx) || ctx.Config.TelemetryDisabled() {
+		return nil, nil
+	}
+
+	labels := common.DefaultLabels(Component)
+
+	return []runtime.Object{&networkingv1.NetworkPolicy{
+		TypeMeta: common.TypeMetaNetworkPolicy,
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      Component,
+			Namespace: ctx.Namespace,
+			Labels:    labels,
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: labels},
+			PolicyTypes: common.NetworkPolicyTypes(ctx),
+			Egress: common.NetworkPolicyEgress(ctx,
+				common.AllowComponentsEgressRule(ctx, common.ServerComponent),
+				// the telemetry endpoint
+				common.AllowExternalEgressRule(),
+			),
+		},
+	}}, nil
+}
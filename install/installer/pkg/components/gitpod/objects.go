@@ -9,6 +9,9 @@ import "github.com/gitpod-io/gitpod/installer/pkg/common"
 var Objects = common.CompositeRenderFunc(
 	configmap,
 	cronjob,
+	common.DefaultDenyNetworkPolicy,
+	networkpolicy,
+	prometheusrule,
 	common.DefaultServiceAccount(Component),
 	rolebinding,
 )
@@ -0,0 +1,50 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package gitpod
+
+import (
+	"fmt"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func prometheusrule(ctx *common.RenderContext) ([]runtime.Object, error) {
+	return common.GeneratePrometheusRule(Component, []common.PrometheusRule{
+		{
+			Alert:       "GitpodComponentDown",
+			Expr:        fmt.Sprintf(`up{namespace="%s"} == 0`, ctx.Namespace),
+			For:         "5m",
+			Severity:    "critical",
+			Summary:     "A Gitpod component is down",
+			Description: "{{ $labels.job }} in {{ $labels.namespace }} has not been reachable by Prometheus for 5 minutes.",
+		},
+		{
+			Alert:       "GitpodPodCrashLooping",
+			Expr:        fmt.Sprintf(`increase(kube_pod_container_status_restarts_total{namespace="%s"}[15m]) > 3`, ctx.Namespace),
+			For:         "5m",
+			Severity:    "warning",
+			Summary:     "A Gitpod pod is restarting frequently",
+			Description: "{{ $labels.pod }} in {{ $labels.namespace }} has restarted more than 3 times in the last 15 minutes.",
+		},
+		{
+			Alert: "GitpodGRPCHighErrorRate",
+			Expr: fmt.Sprintf(`sum by (job) (rate(grpc_server_handled_total{namespace="%[1]s", grpc_code=~"Unknown|Internal|Unavailable|DeadlineExceeded"}[5m]))
+  / sum by (job) (rate(grpc_server_handled_total{namespace="%[1]s"}[5m])) > 0.05`, ctx.Namespace),
+			For:         "10m",
+			Severity:    "warning",
+			Summary:     "A Gitpod component has a high gRPC error rate",
+			Description: "More than 5% of the gRPC requests handled by {{ $labels.job }} are failing.",
+		},
+		{
+			Alert:       "GitpodCertificateNotRenewed",
+			Expr:        fmt.Sprintf(`time() > certmanager_certificate_renewal_timestamp_seconds{namespace="%s"} + 3600`, ctx.Namespace),
+			For:         "15m",
+			Severity:    "critical",
+			Summary:     "cert-manager has not renewed a Gitpod certificate",
+			Description: "{{ $labels.name }} in {{ $labels.namespace }} was due for renewal over an hour ago. The admission webhooks and internal connections that use it fail once it expires.",
+		},
+	})(ctx)
+}
@@ -4,8 +4,10 @@
 
 package ide_metrics
 
+import "github.com/gitpod-io/gitpod/installer/pkg/common"
+
 const (
-	Component     = "ide-metrics"
+	Component     = common.IDEMetricsComponent
 	ContainerPort = 3000
 	PortName      = "http"
 	ServicePort   = 3000
@@ -25,8 +25,8 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 		return nil, err
 	}
 
-	volumes := make([]corev1.Volume, 0)
-	volumeMounts := make([]corev1.VolumeMount, 0)
+	volumes := []corev1.Volume{common.CAVolume()}
+	volumeMounts := []corev1.VolumeMount{common.CAVolumeMount()}
 
 	volumes = append(volumes, corev1.Volume{
 		Name: VolumeConfig,
@@ -45,6 +45,7 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 
 	env := common.CustomizeEnvvar(ctx, Component, common.MergeEnv(
 		common.DefaultEnv(&ctx.Config),
+		common.WebappTracingEnv(ctx, Component),
 	))
 
 	if ctx.Config.Components != nil && ctx.Config.Components.IDE != nil && ctx.Config.Components.IDE.Metrics != nil && ctx.Config.Components.IDE.Metrics.ErrorReportingEnabled {
@@ -79,7 +80,9 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 						}),
 					},
 					Spec: corev1.PodSpec{
-						Affinity:                      cluster.WithNodeAffinityHostnameAntiAffinity(Component, cluster.AffinityLabelMeta),
+						Affinity:                      common.Affinity(ctx, Component, cluster.WithNodeAffinityHostnameAntiAffinity(Component, cluster.AffinityLabelMeta)),
+						PriorityClassName:             common.PriorityClassName(ctx, Component, ""),
+						Tolerations:                   common.Tolerations(ctx, Component, nil),
 						TopologySpreadConstraints:     cluster.WithHostnameTopologySpread(Component),
 						ServiceAccountName:            Component,
 						EnableServiceLinks:            pointer.Bool(false),
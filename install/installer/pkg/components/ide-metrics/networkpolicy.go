@@ -27,7 +27,8 @@ func networkpolicy(ctx *common.RenderContext) ([]runtime.Object, error) {
 		},
 		Spec: networkingv1.NetworkPolicySpec{
 			PodSelector: metav1.LabelSelector{MatchLabels: labels},
-			PolicyTypes: []networkingv1.PolicyType{"Ingress"},
+			PolicyTypes: common.NetworkPolicyTypes(ctx),
+			Egress:      common.NetworkPolicyEgress(ctx),
 			Ingress: []networkingv1.NetworkPolicyIngressRule{{
 				Ports: []networkingv1.NetworkPolicyPort{{
 					Protocol: common.TCPProtocol,
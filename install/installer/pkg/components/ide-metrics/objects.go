@@ -4,13 +4,22 @@
 
 package ide_metrics
 
-import "github.com/gitpod-io/gitpod/installer/pkg/common"
-
-var Objects = common.CompositeRenderFunc(
-	configmap,
-	deployment,
-	rolebinding,
-	service,
-	networkpolicy,
-	common.DefaultServiceAccount(Component),
+import (
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	"k8s.io/apimachinery/pkg/runtime"
 )
+
+func Objects(ctx *common.RenderContext) ([]runtime.Object, error) {
+	if !ctx.Config.IDEMetricsEnabled() {
+		return nil, nil
+	}
+
+	return common.CompositeRenderFunc(
+		configmap,
+		deployment,
+		rolebinding,
+		service,
+		networkpolicy,
+		common.DefaultServiceAccount(Component),
+	)(ctx)
+}
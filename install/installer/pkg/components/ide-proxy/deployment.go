@@ -5,6 +5,9 @@
 package ide_proxy
 
 import (
+	"strconv"
+	"time"
+
 	"github.com/gitpod-io/gitpod/installer/pkg/cluster"
 	"github.com/gitpod-io/gitpod/installer/pkg/common"
 
@@ -41,7 +44,9 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 						Annotations: common.CustomizeAnnotation(ctx, Component, common.TypeMetaDeployment),
 					},
 					Spec: corev1.PodSpec{
-						Affinity:                      cluster.WithNodeAffinityHostnameAntiAffinity(Component, cluster.AffinityLabelMeta),
+						Affinity:                      common.Affinity(ctx, Component, cluster.WithNodeAffinityHostnameAntiAffinity(Component, cluster.AffinityLabelMeta)),
+						PriorityClassName:             common.PriorityClassName(ctx, Component, ""),
+						Tolerations:                   common.Tolerations(ctx, Component, nil),
 						TopologySpreadConstraints:     cluster.WithHostnameTopologySpread(Component),
 						ServiceAccountName:            Component,
 						EnableServiceLinks:            pointer.Bool(false),
@@ -68,6 +73,7 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 							},
 							Env: common.CustomizeEnvvar(ctx, Component, common.MergeEnv(
 								common.DefaultEnv(&ctx.Config),
+								cacheEnv(ctx),
 							)),
 							ReadinessProbe: &corev1.Probe{
 								ProbeHandler: corev1.ProbeHandler{
@@ -88,3 +94,20 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 		},
 	}, nil
 }
+
+// cacheEnv sets the max-age of the Cache-Control headers in the Caddyfile of ide-proxy
+func cacheEnv(ctx *common.RenderContext) []corev1.EnvVar {
+	if ctx.Config.Components == nil || ctx.Config.Components.IDEProxy == nil || ctx.Config.Components.IDEProxy.Cache == nil {
+		return nil
+	}
+
+	cache := ctx.Config.Components.IDEProxy.Cache
+	var res []corev1.EnvVar
+	if cache.StaticMaxAge != nil {
+		res = append(res, corev1.EnvVar{Name: "STATIC_CACHE_MAX_AGE", Value: strconv.Itoa(int(time.Duration(*cache.StaticMaxAge).Seconds()))})
+	}
+	if cache.BinaryMaxAge != nil {
+		res = append(res, corev1.EnvVar{Name: "BINARY_CACHE_MAX_AGE", Value: strconv.Itoa(int(time.Duration(*cache.BinaryMaxAge).Seconds()))})
+	}
+	return res
+}
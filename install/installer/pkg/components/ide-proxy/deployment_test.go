@@ -0,0 +1,33 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package ide_proxy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/gitpod-io/gitpod/common-go/util"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+	rendertest "github.com/gitpod-io/gitpod/installer/pkg/testing"
+)
+
+func TestDeploymentCacheMaxAge(t *testing.T) {
+	staticMaxAge := util.Duration(time.Hour)
+	objects := rendertest.Render(t, deployment, config.Config{
+		Components: &config.Components{IDEProxy: &config.IDEProxyComponent{
+			Cache: &config.IDEProxyCache{StaticMaxAge: &staticMaxAge},
+		}},
+	})
+
+	env := rendertest.Object(t, objects, "Deployment", Component).(*appsv1.Deployment).Spec.Template.Spec.Containers[0].Env
+	require.Contains(t, env, corev1.EnvVar{Name: "STATIC_CACHE_MAX_AGE", Value: "3600"})
+	for _, e := range env {
+		require.NotEqual(t, "BINARY_CACHE_MAX_AGE", e.Name, "the max-age of the image is kept if it is not set")
+	}
+}
@@ -8,6 +8,7 @@ import "github.com/gitpod-io/gitpod/installer/pkg/common"
 
 var Objects = common.CompositeRenderFunc(
 	deployment,
+	networkpolicy,
 	rolebinding,
 	service,
 	common.DefaultServiceAccount(Component),
@@ -25,6 +25,7 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 					Address: fmt.Sprintf("0.0.0.0:%d", GRPCServicePort),
 				},
 			},
+			Builtin: common.BaseserverBuiltin(ctx),
 		},
 		IDEConfigPath: "/ide-config/config.json",
 	}
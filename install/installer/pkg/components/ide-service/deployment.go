@@ -51,7 +51,9 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 						}),
 					},
 					Spec: corev1.PodSpec{
-						Affinity:                      cluster.WithNodeAffinityHostnameAntiAffinity(Component, cluster.AffinityLabelMeta),
+						Affinity:                      common.Affinity(ctx, Component, cluster.WithNodeAffinityHostnameAntiAffinity(Component, cluster.AffinityLabelMeta)),
+						PriorityClassName:             common.PriorityClassName(ctx, Component, ""),
+						Tolerations:                   common.Tolerations(ctx, Component, nil),
 						TopologySpreadConstraints:     cluster.WithHostnameTopologySpread(Component),
 						ServiceAccountName:            Component,
 						EnableServiceLinks:            pointer.Bool(false),
@@ -80,8 +82,9 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 							Env: common.CustomizeEnvvar(ctx, Component, common.MergeEnv(
 								common.DefaultEnv(&ctx.Config),
 								common.ConfigcatEnv(ctx),
+								common.WebappTracingEnv(ctx, Component),
 							)),
-							VolumeMounts: []corev1.VolumeMount{
+							VolumeMounts: append([]corev1.VolumeMount{
 								{
 									Name:      VolumeConfig,
 									MountPath: "/config",
@@ -92,7 +95,8 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 									MountPath: "/ide-config",
 									ReadOnly:  true,
 								},
-							},
+								common.CAVolumeMount(),
+							}, common.MetricsTLSVolumeMounts(ctx)...),
 							ReadinessProbe: &corev1.Probe{
 								ProbeHandler: corev1.ProbeHandler{
 									HTTPGet: &corev1.HTTPGetAction{
@@ -107,7 +111,7 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 						},
 							*common.KubeRBACProxyContainerWithConfig(ctx),
 						},
-						Volumes: []corev1.Volume{
+						Volumes: append([]corev1.Volume{
 							{
 								Name: VolumeConfig,
 								VolumeSource: corev1.VolumeSource{
@@ -124,7 +128,8 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 									},
 								},
 							},
-						},
+							common.CAVolume(),
+						}, common.MetricsTLSVolumes(ctx, Component)...),
 					},
 				},
 			},
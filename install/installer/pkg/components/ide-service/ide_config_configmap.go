@@ -21,7 +21,7 @@ import (
 
 func ideConfigConfigmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 	getIdeLogoPath := func(name string) string {
-		return fmt.Sprintf("https://ide.%s/image/ide-logo/%s.svg", ctx.Config.Domain, name)
+		return fmt.Sprintf("https://%s/image/ide-logo/%s.svg", ctx.Config.IDEAssetsHost(), name)
 	}
 
 	codeDesktop := "code-desktop"
@@ -201,6 +201,36 @@ func ideConfigConfigmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 		},
 	}
 
+	if ctx.Config.Components != nil && ctx.Config.Components.IDE != nil {
+		ideCfg := ctx.Config.Components.IDE
+		for name, images := range ideCfg.Images {
+			option, ok := idecfg.IdeOptions.Options[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown IDE option '%s'", name)
+			}
+			if images == nil {
+				continue
+			}
+			if images.Image != "" {
+				option.Image = images.Image
+			}
+			if images.LatestImage != "" {
+				option.LatestImage = images.LatestImage
+			}
+			idecfg.IdeOptions.Options[name] = option
+		}
+
+		if ideCfg.LatestChannel != nil && !*ideCfg.LatestChannel {
+			// Users that opt into the latest versions get the stable images instead
+			for name, option := range idecfg.IdeOptions.Options {
+				option.LatestImage = option.Image
+				option.PluginLatestImage = option.PluginImage
+				option.LatestImageLayers = option.ImageLayers
+				idecfg.IdeOptions.Options[name] = option
+			}
+		}
+	}
+
 	if idecfg.IdeOptions.Options[idecfg.IdeOptions.DefaultIde].Type != ide_config.IDETypeBrowser {
 		return nil, fmt.Errorf("editor '%s' does not point to a browser IDE option", idecfg.IdeOptions.DefaultIde)
 	}
@@ -8,7 +8,6 @@ import (
 	"fmt"
 
 	"github.com/gitpod-io/gitpod/installer/pkg/common"
-	"github.com/gitpod-io/gitpod/installer/pkg/config/v1/experimental"
 
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -16,28 +15,17 @@ import (
 )
 
 func clusterrole(ctx *common.RenderContext) ([]runtime.Object, error) {
-	var resources []runtime.Object
+	rules := common.PodSecurityPolicyRules(ctx, fmt.Sprintf("%s-ns-privileged-unconfined", ctx.Namespace))
+	if len(rules) == 0 {
+		return nil, nil
+	}
 
-	_ = ctx.WithExperimental(func(cfg *experimental.Config) error {
-		if cfg.Common != nil && cfg.Common.UsePodSecurityPolicies {
-			resources = append(resources, &rbacv1.ClusterRole{
-				TypeMeta: common.TypeMetaClusterRole,
-				ObjectMeta: metav1.ObjectMeta{
-					Name:   fmt.Sprintf("%s-ns-%s", ctx.Namespace, Component),
-					Labels: common.DefaultLabels(Component),
-				},
-				Rules: []rbacv1.PolicyRule{
-					{
-						APIGroups:     []string{"policy"},
-						Resources:     []string{"podsecuritypolicies"},
-						Verbs:         []string{"use"},
-						ResourceNames: []string{fmt.Sprintf("%s-ns-privileged-unconfined", ctx.Namespace)},
-					},
-				},
-			})
-		}
-		return nil
-	})
-
-	return resources, nil
+	return []runtime.Object{&rbacv1.ClusterRole{
+		TypeMeta: common.TypeMetaClusterRole,
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   fmt.Sprintf("%s-ns-%s", ctx.Namespace, Component),
+			Labels: common.DefaultLabels(Component),
+		},
+		Rules: rules,
+	}}, nil
 }
@@ -55,7 +55,13 @@ func networkpolicy(ctx *common.RenderContext) ([]runtime.Object, error) {
 			},
 			Spec: networkingv1.NetworkPolicySpec{
 				PodSelector: metav1.LabelSelector{MatchLabels: labels},
-				PolicyTypes: []networkingv1.PolicyType{"Ingress"},
+				PolicyTypes: common.NetworkPolicyTypes(ctx),
+				Egress: common.NetworkPolicyEgress(ctx,
+					common.AllowComponentsEgressRule(ctx, common.WSManagerMk2Component),
+					common.AllowContainerRegistryEgressRule(ctx),
+					// the registries of the base images
+					common.AllowExternalEgressRule(),
+				),
 				Ingress: []networkingv1.NetworkPolicyIngressRule{
 					{
 						From: ingressRules,
@@ -6,19 +6,11 @@ package image_builder_mk3_wsman
 
 import (
 	"github.com/gitpod-io/gitpod/installer/pkg/common"
-	"github.com/gitpod-io/gitpod/installer/pkg/config/v1/experimental"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
 var Objects common.RenderFunc = func(cfg *common.RenderContext) ([]runtime.Object, error) {
-	var useMk2 bool
-	_ = cfg.WithExperimental(func(ucfg *experimental.Config) error {
-		if ucfg.Workspace != nil {
-			useMk2 = ucfg.Workspace.UseWsmanagerMk2
-		}
-		return nil
-	})
-	if !useMk2 {
+	if !common.UseWsManagerMk2(cfg) {
 		return nil, nil
 	}
 
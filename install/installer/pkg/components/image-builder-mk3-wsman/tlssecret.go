@@ -11,9 +11,6 @@ import (
 	imgbuilder "github.com/gitpod-io/gitpod/installer/pkg/components/image-builder-mk3"
 	"github.com/gitpod-io/gitpod/installer/pkg/config/v1"
 
-	certmanagerv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
-	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -33,26 +30,6 @@ func tlssecret(ctx *common.RenderContext) ([]runtime.Object, error) {
 	}
 
 	return []runtime.Object{
-		&certmanagerv1.Certificate{
-			TypeMeta: common.TypeMetaCertificate,
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      TLSSecretNameWsman,
-				Namespace: ctx.Namespace,
-				Labels:    common.DefaultLabels(Component),
-			},
-			Spec: certmanagerv1.CertificateSpec{
-				Duration:   common.InternalCertDuration,
-				SecretName: TLSSecretNameWsman,
-				DNSNames:   serverAltNames,
-				IssuerRef: cmmeta.ObjectReference{
-					Name:  common.CertManagerCAIssuer,
-					Kind:  certmanagerv1.ClusterIssuerKind,
-					Group: "cert-manager.io",
-				},
-				SecretTemplate: &certmanagerv1.CertificateSecretTemplate{
-					Labels: common.DefaultLabels(Component),
-				},
-			},
-		},
+		common.InternalCertificate(ctx, Component, TLSSecretNameWsman, TLSSecretNameWsman, serverAltNames),
 	}, nil
 }
@@ -48,12 +48,24 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 				Certificate: "/wsman-certs/tls.crt",
 				PrivateKey:  "/wsman-certs/tls.key",
 			},
+			GRPC: common.GRPCClientConfig(ctx),
 		},
-		PullSecret:               secretName,
-		PullSecretFile:           "/config/pull-secret/pull-secret.json",
-		BaseImageRepository:      fmt.Sprintf("%s/base-images", registryName),
-		BuilderImage:             ctx.ImageName(ctx.Config.Repository, BuilderImage, ctx.VersionManifest.Components.ImageBuilderMk3.BuilderImage.Version),
-		WorkspaceImageRepository: fmt.Sprintf("%s/workspace-images", registryName),
+		PullSecret:                 secretName,
+		PullSecretFile:             "/config/pull-secret/pull-secret.json",
+		BaseImageRepository:        fmt.Sprintf("%s/base-images", registryName),
+		BuilderImage:               ctx.ImageName(ctx.Config.Repository, BuilderImage, ctx.VersionManifest.Components.ImageBuilderMk3.BuilderImage.Version),
+		WorkspaceImageRepository:   fmt.Sprintf("%s/workspace-images", registryName),
+		AllowedBaseImageRegistries: ctx.Config.WorkspaceImageRegistries(),
+	}
+
+	if bk := common.BuildKit(ctx); bk != nil && (bk.MaxParallelism != nil || bk.Cache != nil) {
+		orchestrator.BuildKit = &config.BuildKitConfig{}
+		if bk.MaxParallelism != nil {
+			orchestrator.BuildKit.MaxParallelism = int(*bk.MaxParallelism)
+		}
+		if bk.Cache != nil {
+			orchestrator.BuildKit.CacheDir = common.ImageBuilderCacheDir
+		}
 	}
 
 	workspaceImage := ctx.Config.Workspace.WorkspaceImage
@@ -88,6 +100,7 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 					TLS:     tls,
 				},
 			},
+			Builtin: common.BaseserverBuiltin(ctx),
 		},
 	}
 
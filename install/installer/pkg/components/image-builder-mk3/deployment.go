@@ -124,6 +124,9 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 		})
 	}
 
+	volumes = append(volumes, common.MetricsTLSVolumes(ctx, Component)...)
+	volumeMounts = append(volumeMounts, common.MetricsTLSVolumeMounts(ctx)...)
+
 	return []runtime.Object{&appsv1.Deployment{
 		TypeMeta: common.TypeMetaDeployment,
 		ObjectMeta: metav1.ObjectMeta{
@@ -148,7 +151,9 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 					}),
 				},
 				Spec: corev1.PodSpec{
-					Affinity:                      cluster.WithNodeAffinityHostnameAntiAffinity(Component, cluster.AffinityLabelServices),
+					Affinity:                      common.Affinity(ctx, Component, cluster.WithNodeAffinityHostnameAntiAffinity(Component, cluster.AffinityLabelServices)),
+					PriorityClassName:             common.PriorityClassName(ctx, Component, ""),
+					Tolerations:                   common.Tolerations(ctx, Component, nil),
 					TopologySpreadConstraints:     cluster.WithHostnameTopologySpread(Component),
 					ServiceAccountName:            Component,
 					EnableServiceLinks:            pointer.Bool(false),
@@ -11,6 +11,7 @@ var Objects = common.CompositeRenderFunc(
 	configmap,
 	deployment,
 	networkpolicy,
+	pvc,
 	rolebinding,
 	common.GenerateService(Component, []common.ServicePort{
 		{
@@ -0,0 +1,48 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package image_builder_mk3
+
+import (
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// pvc is the claim of the BuildKit cache, which the image build workspaces mount through their
+// pod template
+func pvc(ctx *common.RenderContext) ([]runtime.Object, error) {
+	bk := common.BuildKit(ctx)
+	if bk == nil || bk.Cache == nil {
+		return nil, nil
+	}
+
+	var storageClass *string
+	if bk.Cache.StorageClass != "" {
+		storageClass = &bk.Cache.StorageClass
+	}
+
+	return []runtime.Object{
+		&corev1.PersistentVolumeClaim{
+			TypeMeta: common.TypeMetaPersistentVolumeClaim,
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        common.ImageBuilderCacheClaim,
+				Namespace:   ctx.Namespace,
+				Labels:      common.CustomizeLabel(ctx, Component, common.TypeMetaPersistentVolumeClaim),
+				Annotations: common.CustomizeAnnotation(ctx, Component, common.TypeMetaPersistentVolumeClaim),
+			},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany},
+				StorageClassName: storageClass,
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceStorage: bk.Cache.Size,
+					},
+				},
+			},
+		},
+	}, nil
+}
@@ -26,9 +26,22 @@ func job(ctx *common.RenderContext) ([]runtime.Object, error) {
 		Annotations: common.CustomizeAnnotation(ctx, Component, common.TypeMetaBatchJob),
 	}
 
+	// When installed as a Helm chart, the migrations complete before the new server is rolled
+	// out. On the first install there is no database to migrate until the release is created.
+	// Deployment tools that read the hooks follow the apply order instead, when it is set.
+	jobMeta := objectMeta
+	if ctx.Config.ApplyOrder == nil {
+		jobMeta.Annotations = common.CustomizeAnnotation(ctx, Component, common.TypeMetaBatchJob, func() map[string]string {
+			return map[string]string{
+				"helm.sh/hook":               "post-install,pre-upgrade",
+				"helm.sh/hook-delete-policy": "before-hook-creation",
+			}
+		})
+	}
+
 	return []runtime.Object{&batchv1.Job{
 		TypeMeta:   common.TypeMetaBatchJob,
-		ObjectMeta: objectMeta,
+		ObjectMeta: jobMeta,
 		Spec: batchv1.JobSpec{
 			TTLSecondsAfterFinished: pointer.Int32(60),
 			Template: corev1.PodTemplateSpec{
@@ -43,6 +56,7 @@ func job(ctx *common.RenderContext) ([]runtime.Object, error) {
 						Name:            Component,
 						Image:           ctx.ImageName(ctx.Config.Repository, "db-migrations", ctx.VersionManifest.Components.DBMigrations.Version),
 						ImagePullPolicy: corev1.PullIfNotPresent,
+						Resources:       common.ResourceRequirements(ctx, Component, Component, corev1.ResourceRequirements{}),
 						Env: common.CustomizeEnvvar(ctx, Component, common.MergeEnv(
 							common.DatabaseEnv(&ctx.Config),
 							common.DefaultEnv(&ctx.Config),
@@ -10,6 +10,7 @@ import (
 
 var Objects = common.CompositeRenderFunc(
 	job,
+	networkpolicy,
 	rolebinding,
 	common.DefaultServiceAccount(Component),
 )
@@ -8,6 +8,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/utils/pointer"
 
 	"github.com/gitpod-io/gitpod/installer/pkg/common"
@@ -34,6 +37,30 @@ func TestJob_IsRenderedWhenDisableMigrationIsFalse(t *testing.T) {
 	require.Len(t, objects, 1, "must render one object")
 }
 
+func TestJob_UsesResourcesFromPodConfig(t *testing.T) {
+	ctx := renderContextWithDisableMigration(t, false)
+	ctx.Config.Components = &config.Components{
+		PodConfig: map[string]*config.PodConfig{
+			Component: {
+				Resources: map[string]*corev1.ResourceRequirements{
+					Component: {
+						Requests: corev1.ResourceList{
+							corev1.ResourceMemory: resource.MustParse("256Mi"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	objects, err := job(ctx)
+	require.NoError(t, err)
+	require.Len(t, objects, 1, "must render one object")
+
+	container := objects[0].(*batchv1.Job).Spec.Template.Spec.Containers[0]
+	require.Equal(t, resource.MustParse("256Mi"), container.Resources.Requests[corev1.ResourceMemory])
+}
+
 func renderContextWithDisableMigration(t *testing.T, disableMigration bool) *common.RenderContext {
 	ctx, err := common.NewRenderContext(config.Config{
 		Database: config.Database{
@@ -58,3 +85,15 @@ func renderContextWithDisableMigration(t *testing.T, disableMigration bool) *com
 
 	return ctx
 }
+
+func TestJob_RunsAsHelmHook(t *testing.T) {
+	ctx := renderContextWithDisableMigration(t, false)
+
+	objects, err := job(ctx)
+	require.NoError(t, err)
+	require.Len(t, objects, 1, "must render one object")
+
+	job := objects[0].(*batchv1.Job)
+	require.Equal(t, "post-install,pre-upgrade", job.Annotations["helm.sh/hook"])
+	require.NotContains(t, job.Spec.Template.Annotations, "helm.sh/hook", "the pods are not hooks")
+}
@@ -24,6 +24,7 @@ var Helm = common.CompositeHelmFunc(
 			helm.ImagePullSecrets("minio.volumePermissions.image.pullSecrets", cfg),
 			helm.KeyValue("minio.volumePermissions.image.registry", imageRegistry),
 		}
+		commonHelmValues = append(commonHelmValues, helm.OpenShiftValues(cfg, "minio", "minio")...)
 
 		if cfg.Config.ObjectStorage.Resources != nil && cfg.Config.ObjectStorage.Resources.Requests.Memory() != nil {
 			memoryRequests := resource.MustParse(cfg.Config.ObjectStorage.Resources.Requests.Memory().String())
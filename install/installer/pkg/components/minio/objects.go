@@ -6,10 +6,21 @@ package minio
 
 import (
 	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
 )
 
 const Component = "minio"
 
-var Objects = common.CompositeRenderFunc(
-	rolebinding,
-)
+var Objects = func(ctx *common.RenderContext) ([]runtime.Object, error) {
+	if !pointer.BoolDeref(ctx.Config.ObjectStorage.InCluster, false) {
+		return nil, nil
+	}
+
+	return common.CompositeRenderFunc(
+		rolebinding,
+		common.HelmDependencyNetworkPolicy(Component, map[string]string{
+			"app.kubernetes.io/name": "minio",
+		}),
+	)(ctx)
+}
@@ -4,7 +4,12 @@
 
 package wsmanager
 
+import "time"
+
 const (
 	Component  = "node-labeler"
 	HealthPort = 8086
+
+	// defaultDrainTimeout is how long the workspaces of a draining node may run by default
+	defaultDrainTimeout = 3 * time.Hour
 )
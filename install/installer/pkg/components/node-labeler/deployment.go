@@ -6,6 +6,7 @@ package wsmanager
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/gitpod-io/gitpod/installer/pkg/cluster"
 	"github.com/gitpod-io/gitpod/installer/pkg/common"
@@ -22,9 +23,27 @@ import (
 func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 	labels := common.CustomizeLabel(ctx, Component, common.TypeMetaDeployment)
 
+	args := []string{
+		"run",
+		fmt.Sprintf("--registry-facade-port=%v", common.RegistryFacadeServicePort),
+		fmt.Sprintf("--ws-daemon-port=%v", wsdaemon.ServicePort),
+		fmt.Sprintf("--namespace=%v", ctx.Namespace),
+	}
+	if common.SeparateInfrastructureNamespace(ctx) {
+		args = append(args, fmt.Sprintf("--infrastructure-namespace=%v", common.InfrastructureNamespace(ctx)))
+	}
+	if drain := ctx.Config.Workspace.NodeDrain; drain != nil {
+		timeout := defaultDrainTimeout
+		if drain.Timeout != nil {
+			timeout = time.Duration(*drain.Timeout)
+		}
+		args = append(args, fmt.Sprintf("--workspace-drain-timeout=%v", timeout))
+	}
+
 	podSpec := corev1.PodSpec{
-		PriorityClassName:         common.SystemNodeCritical,
-		Affinity:                  cluster.WithNodeAffinityHostnameAntiAffinity(Component, cluster.AffinityLabelServices),
+		PriorityClassName:         common.PriorityClassName(ctx, Component, common.SystemNodeCritical),
+		Affinity:                  common.Affinity(ctx, Component, cluster.WithNodeAffinityHostnameAntiAffinity(Component, cluster.AffinityLabelServices)),
+		Tolerations:               common.Tolerations(ctx, Component, nil),
 		TopologySpreadConstraints: cluster.WithHostnameTopologySpread(Component),
 		EnableServiceLinks:        pointer.Bool(false),
 		ServiceAccountName:        Component,
@@ -39,12 +58,7 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 						"memory": resource.MustParse("32Mi"),
 					},
 				}),
-				Args: []string{
-					"run",
-					fmt.Sprintf("--registry-facade-port=%v", common.RegistryFacadeServicePort),
-					fmt.Sprintf("--ws-daemon-port=%v", wsdaemon.ServicePort),
-					fmt.Sprintf("--namespace=%v", ctx.Namespace),
-				},
+				Args: args,
 				Env: common.CustomizeEnvvar(ctx, Component, common.MergeEnv(
 					common.DefaultEnv(&ctx.Config),
 				)),
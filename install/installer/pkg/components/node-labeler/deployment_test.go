@@ -0,0 +1,64 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package wsmanager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	"github.com/gitpod-io/gitpod/common-go/util"
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+	"github.com/gitpod-io/gitpod/installer/pkg/config/versions"
+)
+
+func TestNodeDrain(t *testing.T) {
+	timeout := util.Duration(30 * time.Minute)
+	tests := []struct {
+		Name      string
+		NodeDrain *config.WorkspaceNodeDrain
+		Expected  string
+	}{
+		{Name: "disabled"},
+		{Name: "default timeout", NodeDrain: &config.WorkspaceNodeDrain{}, Expected: "--workspace-drain-timeout=3h0m0s"},
+		{Name: "timeout", NodeDrain: &config.WorkspaceNodeDrain{Timeout: &timeout}, Expected: "--workspace-drain-timeout=30m0s"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			ctx, err := common.NewRenderContext(config.Config{
+				Workspace: config.Workspace{NodeDrain: test.NodeDrain},
+			}, versions.Manifest{Components: versions.Components{NodeLabeler: versions.Versioned{Version: "test"}}}, "test_namespace")
+			require.NoError(t, err)
+
+			objs, err := deployment(ctx)
+			require.NoError(t, err)
+			args := objs[0].(*appsv1.Deployment).Spec.Template.Spec.Containers[0].Args
+
+			objs, err = role(ctx)
+			require.NoError(t, err)
+			var patchNodes bool
+			for _, rule := range objs[0].(*rbacv1.ClusterRole).Rules {
+				for _, verb := range rule.Verbs {
+					if rule.Resources[0] == "nodes" && verb == "patch" {
+						patchNodes = true
+					}
+				}
+			}
+
+			if test.Expected == "" {
+				require.Len(t, args, 4)
+				require.False(t, patchNodes)
+				return
+			}
+			require.Contains(t, args, test.Expected)
+			require.True(t, patchNodes)
+		})
+	}
+}
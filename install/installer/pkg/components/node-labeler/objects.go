@@ -13,6 +13,7 @@ import (
 
 var Objects = common.CompositeRenderFunc(
 	deployment,
+	networkpolicy,
 	role,
 	rolebinding,
 	common.DefaultServiceAccount(Component),
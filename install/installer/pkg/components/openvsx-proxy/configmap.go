@@ -22,10 +22,25 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 	if err != nil {
 		return nil, fmt.Errorf("cannot parse openvsx url: %w", err)
 	}
+	cacheDurationRegular := util.Duration(time.Minute * 5)
+	cacheDurationBackup := util.Duration(time.Hour * 72)
+	maxMemory := "100mb"
+	if proxy := ctx.Config.OpenVSX.Proxy; proxy != nil {
+		if proxy.CacheDurationRegular != nil {
+			cacheDurationRegular = *proxy.CacheDurationRegular
+		}
+		if proxy.CacheDurationBackup != nil {
+			cacheDurationBackup = *proxy.CacheDurationBackup
+		}
+		if proxy.CacheSize != nil {
+			maxMemory = fmt.Sprintf("%d", proxy.CacheSize.Value())
+		}
+	}
+
 	imgcfg := openvsx.Config{
 		LogDebug:             false,
-		CacheDurationRegular: util.Duration(time.Minute * 5),
-		CacheDurationBackup:  util.Duration(time.Hour * 72),
+		CacheDurationRegular: cacheDurationRegular,
+		CacheDurationBackup:  cacheDurationBackup,
 		URLUpstream:          ctx.Config.OpenVSX.URL,
 		URLLocal:             fmt.Sprintf("https://open-vsx.%s", ctx.Config.Domain),
 		MaxIdleConns:         1000,
@@ -35,10 +50,10 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 		AllowCacheDomain:     []string{domain.Host},
 	}
 
-	redisCfg := `
-maxmemory 100mb
+	redisCfg := fmt.Sprintf(`
+maxmemory %s
 maxmemory-policy allkeys-lfu
-	`
+	`, maxMemory)
 
 	fc, err := common.ToJSONString(imgcfg)
 	if err != nil {
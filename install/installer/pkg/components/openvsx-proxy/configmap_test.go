@@ -0,0 +1,44 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package openvsx_proxy
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/gitpod-io/gitpod/common-go/util"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+	openvsx "github.com/gitpod-io/gitpod/openvsx-proxy/pkg"
+)
+
+func TestConfigMap_Cache(t *testing.T) {
+	regular := util.Duration(time.Hour)
+	size := resource.MustParse("1Gi")
+
+	ctx := renderContextWithVSXProxyConfig(t, &config.OpenVSX{
+		URL: "https://vsx.example.com",
+		Proxy: &config.OpenVSXProxy{
+			CacheDurationRegular: &regular,
+			CacheSize:            &size,
+		},
+	})
+
+	objects, err := configmap(ctx)
+	require.NoError(t, err)
+	cm := objects[0].(*corev1.ConfigMap)
+
+	var cfg openvsx.Config
+	require.NoError(t, json.Unmarshal([]byte(cm.Data["config.json"]), &cfg))
+	require.Equal(t, "https://vsx.example.com", cfg.URLUpstream)
+	require.Equal(t, []string{"vsx.example.com"}, cfg.AllowCacheDomain)
+	require.Equal(t, regular, cfg.CacheDurationRegular)
+	require.Equal(t, util.Duration(72*time.Hour), cfg.CacheDurationBackup)
+	require.Contains(t, cm.Data["redis.conf"], "maxmemory 1073741824\n")
+}
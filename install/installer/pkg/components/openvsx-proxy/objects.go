@@ -4,15 +4,22 @@
 
 package openvsx_proxy
 
-import "github.com/gitpod-io/gitpod/installer/pkg/common"
+import (
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	"k8s.io/apimachinery/pkg/runtime"
+)
 
-// todo(sje): conditionally deploy this component
+func Objects(ctx *common.RenderContext) ([]runtime.Object, error) {
+	if !ctx.Config.OpenVSXProxyEnabled() {
+		return nil, nil
+	}
 
-var Objects = common.CompositeRenderFunc(
-	configmap,
-	networkpolicy,
-	rolebinding,
-	statefulset,
-	service,
-	common.DefaultServiceAccount(Component),
-)
+	return common.CompositeRenderFunc(
+		configmap,
+		networkpolicy,
+		rolebinding,
+		statefulset,
+		service,
+		common.DefaultServiceAccount(Component),
+	)(ctx)
+}
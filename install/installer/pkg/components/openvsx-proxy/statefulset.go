@@ -57,6 +57,7 @@ func statefulset(ctx *common.RenderContext) ([]runtime.Object, error) {
 				},
 			},
 		},
+		common.CAVolume(),
 	}
 
 	if ctx.Config.OpenVSX.Proxy != nil && ctx.Config.OpenVSX.Proxy.DisablePVC {
@@ -66,6 +67,12 @@ func statefulset(ctx *common.RenderContext) ([]runtime.Object, error) {
 
 	const redisContainerName = "redis"
 
+	// Redis holds the whole cache in memory
+	redisMemory := resource.MustParse("150Mi")
+	if ctx.Config.OpenVSX.Proxy != nil && ctx.Config.OpenVSX.Proxy.CacheSize != nil && ctx.Config.OpenVSX.Proxy.CacheSize.Cmp(redisMemory) > 0 {
+		redisMemory = *ctx.Config.OpenVSX.Proxy.CacheSize
+	}
+
 	return []runtime.Object{&appsv1.StatefulSet{
 		TypeMeta: common.TypeMetaStatefulSet,
 		ObjectMeta: metav1.ObjectMeta{
@@ -93,7 +100,9 @@ func statefulset(ctx *common.RenderContext) ([]runtime.Object, error) {
 					}),
 				},
 				Spec: v1.PodSpec{
-					Affinity:                      cluster.WithNodeAffinity(cluster.AffinityLabelIDE),
+					Affinity:                      common.Affinity(ctx, Component, cluster.WithNodeAffinity(cluster.AffinityLabelIDE)),
+					PriorityClassName:             common.PriorityClassName(ctx, Component, ""),
+					Tolerations:                   common.Tolerations(ctx, Component, nil),
 					ServiceAccountName:            Component,
 					EnableServiceLinks:            pointer.Bool(false),
 					DNSPolicy:                     v1.DNSClusterFirst,
@@ -132,7 +141,7 @@ func statefulset(ctx *common.RenderContext) ([]runtime.Object, error) {
 						VolumeMounts: []v1.VolumeMount{{
 							Name:      "config",
 							MountPath: "/config",
-						}},
+						}, common.CAVolumeMount()},
 						Env: common.CustomizeEnvvar(ctx, Component, common.MergeEnv(
 							common.DefaultEnv(&ctx.Config),
 							common.ConfigcatEnv(ctx),
@@ -158,7 +167,7 @@ func statefulset(ctx *common.RenderContext) ([]runtime.Object, error) {
 						Resources: common.ResourceRequirements(ctx, Component, redisContainerName, v1.ResourceRequirements{
 							Requests: v1.ResourceList{
 								"cpu":    resource.MustParse("1m"),
-								"memory": resource.MustParse("150Mi"),
+								"memory": redisMemory,
 							},
 						}),
 						VolumeMounts: []v1.VolumeMount{{
@@ -7,16 +7,18 @@ package proxy
 import (
 	"bytes"
 	_ "embed"
-	"encoding/base64"
 	"fmt"
+	"sort"
+	"strings"
 	"text/template"
 
+	"github.com/gitpod-io/gitpod/common-go/util"
 	"github.com/gitpod-io/gitpod/installer/pkg/common"
 	ideProxyComponent "github.com/gitpod-io/gitpod/installer/pkg/components/ide-proxy"
 	minioComponent "github.com/gitpod-io/gitpod/installer/pkg/components/minio"
 	openvsxproxy "github.com/gitpod-io/gitpod/installer/pkg/components/openvsx-proxy"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
 
-	"golang.org/x/crypto/bcrypt"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -38,9 +40,24 @@ var vhostOpenVSXTmpl []byte
 //go:embed templates/configmap/vhost.ide-proxy.tpl
 var ideProxyTmpl []byte
 
+//go:embed templates/configmap/vhost.public-api.tpl
+var vhostPublicAPITmpl []byte
+
+//go:embed templates/configmap/vhost.installation-admin.tpl
+var vhostInstallationAdminTmpl []byte
+
 //go:embed templates/configmap/vhost.payment-endpoint.tpl
 var vhostPaymentEndpointTmpl []byte
 
+//go:embed templates/configmap/vhost.workspace-domains.tpl
+var vhostWorkspaceDomainsTmpl []byte
+
+//go:embed templates/configmap/servers.options.tpl
+var serversOptionsTmpl []byte
+
+//go:embed templates/configmap/headers.security.tpl
+var headersSecurityTmpl []byte
+
 type commonTpl struct {
 	Domain       string
 	ReverseProxy string
@@ -49,8 +66,18 @@ type commonTpl struct {
 type dockerRegistryTpl struct {
 	Domain       string
 	ReverseProxy string
-	Username     string
-	Password     string
+	// Username is the user of the registry, the hash of their password is read from the
+	// registry's auth secret
+	Username string
+	// CertificatesPath is the directory with the registry's own certificate, if it has one
+	CertificatesPath string
+}
+
+type publicAPITpl struct {
+	Domain       string
+	Hostname     string
+	ReverseProxy string
+	GRPCWeb      bool
 }
 
 type openVSXTpl struct {
@@ -58,6 +85,58 @@ type openVSXTpl struct {
 	RepoURL string
 }
 
+type workspaceDomainsTpl struct {
+	Domains []string
+}
+
+type serversOptionsTpl struct {
+	TrustedProxies       string
+	ProxyProtocol        bool
+	ProxyProtocolTimeout string
+	ProxyProtocolAllow   string
+	Timeouts             *serversTimeoutsTpl
+}
+
+// serversTimeoutsTpl are the timeouts of the servers, an empty timeout keeps the default of Caddy
+type serversTimeoutsTpl struct {
+	ReadBody   string
+	ReadHeader string
+	Write      string
+	Idle       string
+}
+
+type headersSecurityTpl struct {
+	// Headers are the lines of the header directive
+	Headers []string
+}
+
+func durationString(d *util.Duration) string {
+	if d == nil {
+		return ""
+	}
+	return d.String()
+}
+
+// responseHeaders turns the headers into lines of the header directive in the order of their names
+func responseHeaders(headers map[string]string) []string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	res := make([]string, 0, len(names))
+	for _, name := range names {
+		value := headers[name]
+		if value == "" {
+			res = append(res, "-"+name)
+			continue
+		}
+		res = append(res, fmt.Sprintf(`%s "%s"`, name, strings.ReplaceAll(value, `"`, `\"`)))
+	}
+	return res
+}
+
 func renderTemplate(tpl []byte, values interface{}) (*string, error) {
 	t, err := template.New("template").Parse(string(tpl))
 	if err != nil {
@@ -83,14 +162,6 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 		return nil, err
 	}
 
-	openVSX, err := renderTemplate(vhostOpenVSXTmpl, openVSXTpl{
-		Domain:  ctx.Config.Domain,
-		RepoURL: fmt.Sprintf("openvsx-proxy.%s.%s:%d", ctx.Namespace, kubeDomain, openvsxproxy.ServicePort),
-	})
-	if err != nil {
-		return nil, err
-	}
-
 	ideProxy, err := renderTemplate(ideProxyTmpl, commonTpl{
 		Domain:       ctx.Config.Domain,
 		ReverseProxy: fmt.Sprintf("ide-proxy.%s.%s:%d", ctx.Namespace, kubeDomain, ideProxyComponent.ServicePort),
@@ -107,15 +178,107 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 		return nil, err
 	}
 
+	var proxyCfg *config.ProxyComponent
+	if ctx.Config.Components != nil {
+		proxyCfg = ctx.Config.Components.Proxy
+	}
+
+	// The files are always there, the Caddyfile imports them with glob patterns
+	serversOptions := serversOptionsTpl{
+		ProxyProtocolTimeout: defaultProxyProtocolTimeout.String(),
+	}
+	if proxyCfg != nil {
+		serversOptions.TrustedProxies = strings.Join(proxyCfg.TrustedProxies, " ")
+		if pp := proxyCfg.ProxyProtocol; pp != nil && pp.Enabled {
+			serversOptions.ProxyProtocol = true
+			serversOptions.ProxyProtocolAllow = strings.Join(pp.Allow, " ")
+			if pp.Timeout != nil {
+				serversOptions.ProxyProtocolTimeout = pp.Timeout.String()
+			}
+		}
+		if t := proxyCfg.Timeouts; t != nil {
+			serversOptions.Timeouts = &serversTimeoutsTpl{
+				ReadBody:   durationString(t.Read),
+				ReadHeader: durationString(t.ReadHeader),
+				Write:      durationString(t.Write),
+				Idle:       durationString(t.Idle),
+			}
+		}
+	}
+	servers, err := renderTemplate(serversOptionsTmpl, serversOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	var headers headersSecurityTpl
+	if proxyCfg != nil {
+		headers.Headers = responseHeaders(proxyCfg.ResponseHeaders)
+	}
+	securityHeaders, err := renderTemplate(headersSecurityTmpl, headers)
+	if err != nil {
+		return nil, err
+	}
+
 	data := map[string]string{
+		"headers.security":       *securityHeaders,
+		"servers.options":        *servers,
 		"vhost.empty":            *empty,
-		"vhost.open-vsx":         *openVSX,
 		"vhost.payment-endpoint": *paymentEndpoint,
 		"vhost.ide-proxy":        *ideProxy,
 	}
 
-	if ctx.Config.ObjectStorage.CloudStorage == nil {
-		// Don't expose Minio if using cloud storage
+	if ctx.Config.OpenVSXProxyEnabled() {
+		openVSX, err := renderTemplate(vhostOpenVSXTmpl, openVSXTpl{
+			Domain:  ctx.Config.Domain,
+			RepoURL: fmt.Sprintf("openvsx-proxy.%s.%s:%d", ctx.Namespace, kubeDomain, openvsxproxy.ServicePort),
+		})
+		if err != nil {
+			return nil, err
+		}
+		data["vhost.open-vsx"] = *openVSX
+	}
+
+	if ctx.Config.PublicAPIEnabled() {
+		publicAPI, err := renderTemplate(vhostPublicAPITmpl, publicAPITpl{
+			Domain:       ctx.Config.Domain,
+			Hostname:     ctx.Config.PublicAPIHostname(),
+			ReverseProxy: fmt.Sprintf("%s.%s.%s:%d", common.PublicApiComponent, ctx.Namespace, kubeDomain, common.PublicApiHTTPServicePort),
+			GRPCWeb:      ctx.Config.Components != nil && ctx.Config.Components.PublicAPI != nil && ctx.Config.Components.PublicAPI.GRPCWeb,
+		})
+		if err != nil {
+			return nil, err
+		}
+		data["vhost.public-api"] = *publicAPI
+	}
+
+	if ctx.Config.InstallationAdminExposure() == config.InstallationAdminProxy {
+		// The credentials are read from the environment when the Caddyfile is parsed
+		installationAdmin, err := renderTemplate(vhostInstallationAdminTmpl, commonTpl{
+			Domain:       ctx.Config.Domain,
+			ReverseProxy: fmt.Sprintf("%s.%s.%s:%d", common.ServerComponent, ctx.Namespace, kubeDomain, common.ServerInstallationAdminPort),
+		})
+		if err != nil {
+			return nil, err
+		}
+		data["vhost.installation-admin"] = *installationAdmin
+	}
+
+	var workspaceDomains []string
+	for _, d := range ctx.Config.WorkspaceDomains() {
+		if !ctx.Config.WorkspaceDomainCovered(d) {
+			workspaceDomains = append(workspaceDomains, d)
+		}
+	}
+	if len(workspaceDomains) > 0 {
+		vhost, err := renderTemplate(vhostWorkspaceDomainsTmpl, workspaceDomainsTpl{Domains: workspaceDomains})
+		if err != nil {
+			return nil, err
+		}
+		data["vhost.workspace-domains"] = *vhost
+	}
+
+	if pointer.BoolDeref(ctx.Config.ObjectStorage.InCluster, false) {
+		// Minio only runs for the in-cluster storage
 		minio, err := renderTemplate(vhostMinioTmpl, commonTpl{
 			Domain:       ctx.Config.Domain,
 			ReverseProxy: fmt.Sprintf("minio.%s.%s:%d", ctx.Namespace, kubeDomain, minioComponent.ServiceConsolePort),
@@ -132,21 +295,16 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 			return nil, fmt.Errorf("unknown value: internal registry username")
 		}
 
-		password := ctx.Values.InternalRegistryPassword
-		if password == "" {
-			return nil, fmt.Errorf("unknown value: internal registry password")
-		}
-
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-		if err != nil {
-			return nil, err
+		var certificatesPath string
+		if ctx.Config.RegistryCertificate != nil {
+			certificatesPath = RegistryDomainCertificatesPath
 		}
 
 		dockerRegistry, err := renderTemplate(vhostDockerRegistry, dockerRegistryTpl{
-			Domain:       ctx.Config.Domain,
-			ReverseProxy: fmt.Sprintf("https://%s.%s.%s", common.DockerRegistryName, ctx.Namespace, kubeDomain),
-			Username:     username,
-			Password:     base64.StdEncoding.EncodeToString(hashedPassword),
+			Domain:           ctx.Config.Domain,
+			ReverseProxy:     fmt.Sprintf("https://%s.%s.%s", common.DockerRegistryName, ctx.Namespace, kubeDomain),
+			Username:         username,
+			CertificatesPath: certificatesPath,
 		})
 		if err != nil {
 			return nil, err
@@ -0,0 +1,274 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package proxy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+
+	"github.com/gitpod-io/gitpod/common-go/util"
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+	"github.com/gitpod-io/gitpod/installer/pkg/config/versions"
+)
+
+func TestConfigMap_ServersOptions(t *testing.T) {
+	timeout := util.Duration(10 * time.Second)
+	idle := util.Duration(time.Hour)
+
+	testCases := []struct {
+		Name   string
+		Proxy  *config.ProxyComponent
+		Expect string
+	}{
+		{
+			Name:   "Not configured",
+			Expect: "# Options of all servers, imported in the global servers block\n",
+		},
+		{
+			Name: "Trusted proxies",
+			Proxy: &config.ProxyComponent{
+				TrustedProxies: []string{"10.0.0.0/8", "192.168.0.0/16"},
+			},
+			Expect: "# Options of all servers, imported in the global servers block\n" +
+				"trusted_proxies static 10.0.0.0/8 192.168.0.0/16\n",
+		},
+		{
+			Name: "PROXY protocol disabled",
+			Proxy: &config.ProxyComponent{
+				ProxyProtocol: &config.ProxyProtocol{Allow: []string{"10.0.0.0/8"}},
+			},
+			Expect: "# Options of all servers, imported in the global servers block\n",
+		},
+		{
+			Name: "PROXY protocol",
+			Proxy: &config.ProxyComponent{
+				ProxyProtocol: &config.ProxyProtocol{Enabled: true, Allow: []string{"10.0.0.0/8"}, Timeout: &timeout},
+			},
+			Expect: "# Options of all servers, imported in the global servers block\n" +
+				"listener_wrappers {\n\tproxy_protocol {\n\t\ttimeout 10s\n\t\tallow 10.0.0.0/8\n\t}\n\ttls\n}\n",
+		},
+		{
+			Name: "PROXY protocol with defaults",
+			Proxy: &config.ProxyComponent{
+				ProxyProtocol: &config.ProxyProtocol{Enabled: true},
+			},
+			Expect: "# Options of all servers, imported in the global servers block\n" +
+				"listener_wrappers {\n\tproxy_protocol {\n\t\ttimeout 5s\n\t}\n\ttls\n}\n",
+		},
+		{
+			Name: "Timeouts",
+			Proxy: &config.ProxyComponent{
+				Timeouts: &config.ServerTimeouts{Read: &timeout, Idle: &idle},
+			},
+			Expect: "# Options of all servers, imported in the global servers block\n" +
+				"timeouts {\n\tread_body 10s\n\tidle 1h0m0s\n}\n",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			cfg := config.Config{Domain: "gitpod.example.com"}
+			if testCase.Proxy != nil {
+				cfg.Components = &config.Components{Proxy: testCase.Proxy}
+			}
+			ctx, err := common.NewRenderContext(cfg, versions.Manifest{}, "test_namespace")
+			require.NoError(t, err)
+
+			objects, err := configmap(ctx)
+			require.NoError(t, err)
+			require.Len(t, objects, 1)
+
+			cm := objects[0].(*corev1.ConfigMap)
+			require.Equal(t, testCase.Expect, cm.Data["servers.options"])
+		})
+	}
+}
+
+func TestConfigMap_SecurityHeaders(t *testing.T) {
+	ctx, err := common.NewRenderContext(config.Config{
+		Domain: "gitpod.example.com",
+		Components: &config.Components{Proxy: &config.ProxyComponent{
+			ResponseHeaders: map[string]string{
+				"X-XSS-Protection":        "",
+				"X-Frame-Options":         "DENY",
+				"Content-Security-Policy": `default-src 'self'; frame-ancestors "self"`,
+			},
+		}},
+	}, versions.Manifest{}, "test_namespace")
+	require.NoError(t, err)
+
+	objects, err := configmap(ctx)
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+
+	cm := objects[0].(*corev1.ConfigMap)
+	require.Equal(t, "# Response headers of the installation, imported in the security_headers snippet\n"+
+		`Content-Security-Policy "default-src 'self'; frame-ancestors \"self\""`+"\n"+
+		`X-Frame-Options "DENY"`+"\n"+
+		"-X-XSS-Protection\n", cm.Data["headers.security"])
+}
+
+func TestConfigMap_PublicAPI(t *testing.T) {
+	testCases := []struct {
+		Name      string
+		PublicAPI *config.PublicAPIComponent
+		Expect    []string
+	}{
+		{
+			Name:   "Default",
+			Expect: []string{"https://api.gitpod.example.com {", "allowed_origins https://gitpod.example.com\n\t}"},
+		},
+		{
+			Name:      "Hostname and gRPC-Web",
+			PublicAPI: &config.PublicAPIComponent{Hostname: "gitpod-api.example.com", GRPCWeb: true},
+			Expect:    []string{"https://gitpod-api.example.com {", "grpc_web true"},
+		},
+		{
+			Name:      "Disabled",
+			PublicAPI: &config.PublicAPIComponent{Enabled: pointer.Bool(false)},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			ctx, err := common.NewRenderContext(config.Config{
+				Domain:     "gitpod.example.com",
+				Components: &config.Components{PublicAPI: testCase.PublicAPI},
+			}, versions.Manifest{}, "test_namespace")
+			require.NoError(t, err)
+
+			objects, err := configmap(ctx)
+			require.NoError(t, err)
+
+			vhost, ok := objects[0].(*corev1.ConfigMap).Data["vhost.public-api"]
+			if testCase.Expect == nil {
+				require.False(t, ok, "must not render the host of the public API")
+				return
+			}
+			for _, e := range testCase.Expect {
+				require.Contains(t, vhost, e)
+			}
+			require.Contains(t, vhost, "reverse_proxy public-api-server.test_namespace.svc.cluster.local:9002")
+		})
+	}
+}
+
+func TestConfigMap_OpenVSXProxy(t *testing.T) {
+	testCases := []struct {
+		Name         string
+		OpenVSXProxy *config.ToggleComponent
+		Expect       bool
+	}{
+		{Name: "Default", Expect: true},
+		{Name: "Enabled", OpenVSXProxy: &config.ToggleComponent{Enabled: pointer.Bool(true)}, Expect: true},
+		{Name: "Disabled", OpenVSXProxy: &config.ToggleComponent{Enabled: pointer.Bool(false)}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			ctx, err := common.NewRenderContext(config.Config{
+				Domain:     "gitpod.example.com",
+				Components: &config.Components{OpenVSXProxy: testCase.OpenVSXProxy},
+			}, versions.Manifest{}, "test_namespace")
+			require.NoError(t, err)
+
+			objects, err := configmap(ctx)
+			require.NoError(t, err)
+
+			vhost, ok := objects[0].(*corev1.ConfigMap).Data["vhost.open-vsx"]
+			require.Equal(t, testCase.Expect, ok)
+			if ok {
+				require.Contains(t, vhost, "https://open-vsx.gitpod.example.com {")
+			}
+		})
+	}
+}
+
+func TestConfigMap_WorkspaceDomains(t *testing.T) {
+	render := func(workspace config.Workspace) (string, bool) {
+		ctx, err := common.NewRenderContext(config.Config{
+			Domain:    "gitpod.example.com",
+			Workspace: workspace,
+		}, versions.Manifest{}, "test_namespace")
+		require.NoError(t, err)
+
+		objects, err := configmap(ctx)
+		require.NoError(t, err)
+
+		vhost, ok := objects[0].(*corev1.ConfigMap).Data["vhost.workspace-domains"]
+		return vhost, ok
+	}
+
+	_, ok := render(config.Workspace{AdditionalDomains: []string{"ws-eu.gitpod.example.com"}})
+	require.False(t, ok, "the wildcard of the domain covers the workspace domains")
+
+	vhost, ok := render(config.Workspace{
+		Domain:            "eu.ws.gitpod.example.com",
+		AdditionalDomains: []string{"ws.gitpod.example.com", "ws.example.org"},
+	})
+	require.True(t, ok)
+	require.Contains(t, vhost, "https://*.eu.ws.gitpod.example.com, https://*.ws.example.org {")
+	require.Contains(t, vhost, "import /etc/caddy/workspace-handler/*.{$WORKSPACE_HANDLER_FILE}")
+}
+
+func TestConfigMap_InstallationAdmin(t *testing.T) {
+	render := func(admin *config.ServerInstallationAdmin) (string, bool) {
+		ctx, err := common.NewRenderContext(config.Config{
+			Domain:     "gitpod.example.com",
+			Components: &config.Components{Server: &config.ServerComponent{InstallationAdmin: admin}},
+		}, versions.Manifest{}, "test_namespace")
+		require.NoError(t, err)
+
+		objects, err := configmap(ctx)
+		require.NoError(t, err)
+
+		vhost, ok := objects[0].(*corev1.ConfigMap).Data["vhost.installation-admin"]
+		return vhost, ok
+	}
+
+	_, ok := render(nil)
+	require.False(t, ok, "the port is only reachable in the cluster by default")
+
+	vhost, ok := render(&config.ServerInstallationAdmin{
+		Exposure:    config.InstallationAdminProxy,
+		Credentials: &config.ObjectRef{Kind: config.ObjectRefSecret, Name: "installation-admin"},
+	})
+	require.True(t, ok)
+	require.Contains(t, vhost, "https://installation-admin.gitpod.example.com {")
+	require.Contains(t, vhost, "{$INSTALLATION_ADMIN_USERNAME} {$INSTALLATION_ADMIN_PASSWORD_HASH}")
+	require.Contains(t, vhost, "reverse_proxy server.test_namespace.svc.cluster.local:9000")
+}
+
+func TestConfigMap_DockerRegistry(t *testing.T) {
+	ctx, err := common.NewRenderContext(config.Config{
+		Domain:            "gitpod.example.com",
+		ContainerRegistry: config.ContainerRegistry{InCluster: pointer.Bool(true)},
+	}, versions.Manifest{Components: versions.Components{
+		Proxy: versions.Versioned{Version: "commit-test-latest"},
+	}}, "test_namespace")
+	require.NoError(t, err)
+
+	objects, err := configmap(ctx)
+	require.NoError(t, err)
+
+	vhost := objects[0].(*corev1.ConfigMap).Data["vhost.docker-registry"]
+	require.Contains(t, vhost, ctx.Values.InternalRegistryUsername+" {$DOCKER_REGISTRY_PASSWORD_HASH}", "the hash is read from the auth secret")
+
+	objects, err = deployment(ctx)
+	require.NoError(t, err)
+	require.Contains(t, objects[0].(*appsv1.Deployment).Spec.Template.Spec.Containers[1].Env, corev1.EnvVar{
+		Name: "DOCKER_REGISTRY_PASSWORD_HASH",
+		ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: RegistryAuthSecret},
+			Key:                  "passwordHash",
+		}},
+	})
+}
@@ -4,7 +4,11 @@
 
 package proxy
 
-import "github.com/gitpod-io/gitpod/installer/pkg/common"
+import (
+	"time"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+)
 
 const (
 	Component             = common.ProxyComponent
@@ -22,4 +26,12 @@ const (
 	ReadinessPort         = 8003
 	RegistryAuthSecret    = common.RegistryAuthSecret
 	RegistryTLSCertSecret = common.RegistryTLSCertSecret
+
+	RegistryDomainCertificatesPath = "/etc/caddy/registry-domain-certificates"
+
+	// caddyDataPath is where a hardened Caddy keeps its certificates and autosaved config
+	caddyDataPath = "/var/lib/caddy"
 )
+
+// defaultProxyProtocolTimeout is how long the proxy waits for the PROXY protocol header of a connection
+const defaultProxyProtocolTimeout = 5 * time.Second
@@ -10,6 +10,7 @@ import (
 
 	"github.com/gitpod-io/gitpod/common-go/baseserver"
 	"github.com/gitpod-io/gitpod/installer/pkg/cluster"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
 	"github.com/gitpod-io/gitpod/installer/pkg/config/v1/experimental"
 
 	"github.com/gitpod-io/gitpod/installer/pkg/common"
@@ -52,7 +53,14 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 				SecretName: ctx.Config.Certificate.Name,
 			},
 		},
-	}}
+	}, {
+		Name: "workspace-certificates",
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: common.WorkspaceCertificate(ctx).Name,
+			},
+		},
+	}, common.CAVolume()}
 
 	volumeMounts := []corev1.VolumeMount{{
 		Name:      "vhosts",
@@ -60,7 +68,25 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 	}, {
 		Name:      "config-certificates",
 		MountPath: "/etc/caddy/certificates",
-	}}
+	}, {
+		Name:      "workspace-certificates",
+		MountPath: "/etc/caddy/workspace-certificates",
+	}, common.CAVolumeMount()}
+
+	if ctx.Config.RegistryCertificate != nil && pointer.BoolDeref(ctx.Config.ContainerRegistry.InCluster, false) {
+		volumes = append(volumes, corev1.Volume{
+			Name: "registry-domain-certificates",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: ctx.Config.RegistryCertificate.Name,
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      "registry-domain-certificates",
+			MountPath: RegistryDomainCertificatesPath,
+		})
+	}
 
 	if pointer.BoolDeref(ctx.Config.ContainerRegistry.InCluster, false) {
 		volumes = append(volumes, corev1.Volume{
@@ -93,6 +119,25 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 		}
 	}
 
+	if objs, err := featureFlags(ctx); err != nil {
+		return nil, err
+	} else if len(objs) > 0 {
+		hashObj = append(hashObj, objs...)
+		volumes = append(volumes, corev1.Volume{
+			Name: common.FeatureFlagsConfigMap,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: common.FeatureFlagsConfigMap},
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      common.FeatureFlagsConfigMap,
+			MountPath: common.FeatureFlagsMountPath,
+			ReadOnly:  true,
+		})
+	}
+
 	configHash, err := common.ObjectHash(hashObj, nil)
 	if err != nil {
 		return nil, err
@@ -106,6 +151,52 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 		return nil
 	})
 
+	podSecurityContext := &corev1.PodSecurityContext{
+		RunAsNonRoot: pointer.Bool(false),
+	}
+	initContainers := []corev1.Container{{
+		Name:            "sysctl",
+		Image:           ctx.ImageName(common.ThirdPartyContainerRepo(ctx.Config.Repository, common.DockerRegistryURL), InitContainerImage, InitContainerTag),
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		SecurityContext: &corev1.SecurityContext{
+			Privileged: pointer.Bool(true),
+		},
+		Command: []string{
+			"sh",
+			"-c",
+			"sysctl -w net.core.somaxconn=32768; sysctl -w net.ipv4.ip_local_port_range='1024 65000'",
+		},
+	}}
+	var caddyEnv []corev1.EnvVar
+	if common.Hardened(ctx, Component) {
+		// A hardened pod cannot have a privileged container, of the sysctls only the port range
+		// can be set without one. Caddy runs as non-root and may still bind the ports below 1024.
+		podSecurityContext = &corev1.PodSecurityContext{
+			RunAsNonRoot: pointer.Bool(true),
+			RunAsUser:    pointer.Int64(common.HardenedUser),
+			RunAsGroup:   pointer.Int64(common.HardenedUser),
+			Sysctls: []corev1.Sysctl{
+				{Name: "net.ipv4.ip_local_port_range", Value: "1024 65000"},
+				{Name: "net.ipv4.ip_unprivileged_port_start", Value: "0"},
+			},
+		}
+		initContainers = nil
+
+		// the data and config directories of Caddy are in the home of root otherwise
+		volumes = append(volumes, corev1.Volume{
+			Name:         "caddy-data",
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      "caddy-data",
+			MountPath: caddyDataPath,
+		})
+		caddyEnv = []corev1.EnvVar{
+			{Name: "XDG_DATA_HOME", Value: caddyDataPath + "/data"},
+			{Name: "XDG_CONFIG_HOME", Value: caddyDataPath + "/config"},
+		}
+	}
+
 	const kubeRbacProxyContainerName = "kube-rbac-proxy"
 	return []runtime.Object{
 		&appsv1.Deployment{
@@ -132,32 +223,18 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 						}),
 					},
 					Spec: corev1.PodSpec{
-						Affinity:                      cluster.WithNodeAffinityHostnameAntiAffinity(Component, cluster.AffinityLabelMeta),
+						Affinity:                      common.Affinity(ctx, Component, cluster.WithNodeAffinityHostnameAntiAffinity(Component, cluster.AffinityLabelMeta)),
+						Tolerations:                   common.Tolerations(ctx, Component, nil),
 						TopologySpreadConstraints:     cluster.WithHostnameTopologySpread(Component),
-						PriorityClassName:             common.SystemNodeCritical,
+						PriorityClassName:             common.PriorityClassName(ctx, Component, common.SystemNodeCritical),
 						ServiceAccountName:            Component,
 						EnableServiceLinks:            pointer.Bool(false),
 						DNSPolicy:                     corev1.DNSClusterFirst,
 						RestartPolicy:                 corev1.RestartPolicyAlways,
 						TerminationGracePeriodSeconds: pointer.Int64(30),
-						SecurityContext: &corev1.PodSecurityContext{
-
-							RunAsNonRoot: pointer.Bool(false),
-						},
-						Volumes: volumes,
-						InitContainers: []corev1.Container{{
-							Name:            "sysctl",
-							Image:           ctx.ImageName(common.ThirdPartyContainerRepo(ctx.Config.Repository, common.DockerRegistryURL), InitContainerImage, InitContainerTag),
-							ImagePullPolicy: corev1.PullIfNotPresent,
-							SecurityContext: &corev1.SecurityContext{
-								Privileged: pointer.Bool(true),
-							},
-							Command: []string{
-								"sh",
-								"-c",
-								"sysctl -w net.core.somaxconn=32768; sysctl -w net.ipv4.ip_local_port_range='1024 65000'",
-							},
-						}},
+						SecurityContext:               podSecurityContext,
+						Volumes:                       volumes,
+						InitContainers:                initContainers,
 						Containers: []corev1.Container{{
 							Name:            kubeRbacProxyContainerName,
 							Image:           ctx.ImageName(common.ThirdPartyContainerRepo(ctx.Config.Repository, KubeRBACProxyRepo), KubeRBACProxyImage, KubeRBACProxyTag),
@@ -244,6 +321,9 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 									Name:  "WORKSPACE_HANDLER_FILE",
 									Value: strings.ToLower(string(ctx.Config.Kind)),
 								}},
+								installationAdminEnv(ctx),
+								dockerRegistryEnv(ctx),
+								caddyEnv,
 							)),
 						}},
 					},
@@ -252,3 +332,40 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 		},
 	}, nil
 }
+
+// dockerRegistryEnv passes the hash of the in-cluster registry's password to the Caddyfile
+func dockerRegistryEnv(ctx *common.RenderContext) []corev1.EnvVar {
+	if !pointer.BoolDeref(ctx.Config.ContainerRegistry.InCluster, false) {
+		return nil
+	}
+
+	return []corev1.EnvVar{{
+		Name: "DOCKER_REGISTRY_PASSWORD_HASH",
+		ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: RegistryAuthSecret},
+			Key:                  "passwordHash",
+		}},
+	}}
+}
+
+// installationAdminEnv passes the credentials of the installation-admin route to the Caddyfile
+func installationAdminEnv(ctx *common.RenderContext) []corev1.EnvVar {
+	if ctx.Config.InstallationAdminExposure() != config.InstallationAdminProxy {
+		return nil
+	}
+
+	secret := ctx.Config.Components.Server.InstallationAdmin.Credentials.Name
+	env := func(name, key string) corev1.EnvVar {
+		return corev1.EnvVar{
+			Name: name,
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: secret},
+				Key:                  key,
+			}},
+		}
+	}
+	return []corev1.EnvVar{
+		env("INSTALLATION_ADMIN_USERNAME", "username"),
+		env("INSTALLATION_ADMIN_PASSWORD_HASH", "passwordHash"),
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package proxy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+	"github.com/gitpod-io/gitpod/installer/pkg/config/versions"
+)
+
+func TestDeploymentHardened(t *testing.T) {
+	ctx, err := common.NewRenderContext(config.Config{
+		Domain:   "gitpod.example.com",
+		Security: &config.Security{Hardened: true},
+	}, versions.Manifest{Components: versions.Components{
+		Proxy: versions.Versioned{Version: "commit-test-latest"},
+	}}, "test-namespace")
+	require.NoError(t, err)
+
+	objects, err := deployment(ctx)
+	require.NoError(t, err)
+	common.WorkloadSecurity(ctx, objects)
+
+	spec := objects[0].(*appsv1.Deployment).Spec.Template.Spec
+	require.Empty(t, spec.InitContainers, "the privileged sysctl container is not rendered")
+	require.Equal(t, pointer.Bool(true), spec.SecurityContext.RunAsNonRoot)
+	require.Equal(t, pointer.Int64(common.HardenedUser), spec.SecurityContext.RunAsUser)
+	require.Contains(t, spec.SecurityContext.Sysctls, corev1.Sysctl{Name: "net.ipv4.ip_unprivileged_port_start", Value: "0"})
+
+	for _, c := range spec.Containers {
+		require.False(t, pointer.BoolDeref(c.SecurityContext.Privileged, false), c.Name)
+		require.True(t, pointer.BoolDeref(c.SecurityContext.RunAsNonRoot, true), c.Name)
+		require.Equal(t, pointer.Bool(false), c.SecurityContext.AllowPrivilegeEscalation, c.Name)
+	}
+	require.Contains(t, spec.Containers[1].Env, corev1.EnvVar{Name: "XDG_DATA_HOME", Value: caddyDataPath + "/data"})
+	require.Contains(t, spec.Containers[1].VolumeMounts, corev1.VolumeMount{Name: "caddy-data", MountPath: caddyDataPath})
+}
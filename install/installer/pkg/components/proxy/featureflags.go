@@ -0,0 +1,78 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package proxy
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"path/filepath"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// The types of the settings of a ConfigCat config
+const (
+	configcatBool = iota
+	configcatString
+	configcatInt
+	configcatDouble
+)
+
+type configcatSetting struct {
+	Value interface{} `json:"v"`
+	Type  int         `json:"t"`
+}
+
+// featureFlags renders the static feature flags as a ConfigCat config, which the proxy serves
+// to the components instead of fetching it from ConfigCat
+func featureFlags(ctx *common.RenderContext) ([]runtime.Object, error) {
+	if ctx.Config.FeatureFlags == nil || ctx.Config.FeatureFlags.Static == nil {
+		return nil, nil
+	}
+
+	settings := make(map[string]configcatSetting, len(ctx.Config.FeatureFlags.Static))
+	for name, value := range ctx.Config.FeatureFlags.Static {
+		var t int
+		switch v := value.(type) {
+		case bool:
+			t = configcatBool
+		case string:
+			t = configcatString
+		case float64:
+			t = configcatDouble
+			if v == math.Trunc(v) {
+				t = configcatInt
+			}
+		default:
+			return nil, fmt.Errorf("unsupported value of feature flag %s: %v", name, value)
+		}
+		settings[name] = configcatSetting{Value: value, Type: t}
+	}
+
+	fc, err := json.Marshal(map[string]interface{}{"f": settings})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal feature flags: %w", err)
+	}
+
+	return []runtime.Object{
+		&corev1.ConfigMap{
+			TypeMeta: common.TypeMetaConfigmap,
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        common.FeatureFlagsConfigMap,
+				Namespace:   ctx.Namespace,
+				Labels:      common.CustomizeLabel(ctx, Component, common.TypeMetaConfigmap),
+				Annotations: common.CustomizeAnnotation(ctx, Component, common.TypeMetaConfigmap),
+			},
+			Data: map[string]string{
+				filepath.Base(common.FeatureFlagsFile): string(fc),
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package proxy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+	"github.com/gitpod-io/gitpod/installer/pkg/config/versions"
+)
+
+func TestFeatureFlags(t *testing.T) {
+	testCases := []struct {
+		Name   string
+		Flags  *config.FeatureFlags
+		Expect string
+		Env    []corev1.EnvVar
+	}{
+		{
+			Name: "Not configured",
+		},
+		{
+			Name: "Static",
+			Flags: &config.FeatureFlags{Static: map[string]interface{}{
+				"isUsageBasedBillingEnabled": true,
+				"workspace_class":            "default",
+				"maxParallelPrebuilds":       float64(10),
+				"prebuildRatio":              0.5,
+			}},
+			Expect: `{"f":{"isUsageBasedBillingEnabled":{"v":true,"t":0},"maxParallelPrebuilds":{"v":10,"t":2},"prebuildRatio":{"v":0.5,"t":3},"workspace_class":{"v":"default","t":1}}}`,
+			Env:    []corev1.EnvVar{{Name: "CONFIGCAT_CONFIG_FILE", Value: "/etc/caddy/feature-flags/config_v5.json"}},
+		},
+		{
+			Name: "ConfigCat proxy",
+			Flags: &config.FeatureFlags{ConfigCat: &config.FeatureFlagsConfigCat{
+				BaseURL: "https://configcat.example.com",
+				SDKKey:  config.ObjectRef{Kind: config.ObjectRefSecret, Name: "configcat"},
+			}},
+			Env: []corev1.EnvVar{
+				{Name: "CONFIGCAT_SDK_KEY", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "configcat"},
+					Key:                  "sdkKey",
+				}}},
+				{Name: "CONFIGCAT_BASE_URL", Value: "https://configcat.example.com"},
+				{Name: "CONFIGCAT_POLL_INTERVAL", Value: "1m0s"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			ctx, err := common.NewRenderContext(config.Config{
+				Domain:       "gitpod.example.com",
+				FeatureFlags: testCase.Flags,
+			}, versions.Manifest{}, "test_namespace")
+			require.NoError(t, err)
+
+			objects, err := featureFlags(ctx)
+			require.NoError(t, err)
+			if testCase.Expect == "" {
+				require.Empty(t, objects)
+			} else {
+				require.Len(t, objects, 1)
+				require.Equal(t, testCase.Expect, objects[0].(*corev1.ConfigMap).Data["config_v5.json"])
+			}
+
+			require.Equal(t, testCase.Env, common.ConfigcatProxyEnv(ctx))
+		})
+	}
+}
@@ -9,16 +9,40 @@ import (
 
 	"github.com/gitpod-io/gitpod/common-go/baseserver"
 	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	ideproxy "github.com/gitpod-io/gitpod/installer/pkg/components/ide-proxy"
+	openvsxproxy "github.com/gitpod-io/gitpod/installer/pkg/components/openvsx-proxy"
 
 	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
 )
 
 func networkpolicy(ctx *common.RenderContext) ([]runtime.Object, error) {
 	labels := common.DefaultLabels(Component)
 
+	egress := []networkingv1.NetworkPolicyEgressRule{common.AllowComponentsEgressRule(ctx,
+		common.ServerComponent,
+		common.PublicApiComponent,
+		common.DashboardComponent,
+		ideproxy.Component,
+		common.WSProxyComponent,
+		openvsxproxy.Component,
+		common.PaymentEndpointComponent,
+	)}
+	// the in-cluster storage and registry are exposed by the proxy
+	if pointer.BoolDeref(ctx.Config.ObjectStorage.InCluster, false) {
+		egress = append(egress, common.AllowObjectStorageEgressRule(ctx))
+	}
+	if pointer.BoolDeref(ctx.Config.ContainerRegistry.InCluster, false) {
+		egress = append(egress, common.AllowContainerRegistryEgressRule(ctx))
+	}
+	if ctx.Config.FeatureFlags != nil && ctx.Config.FeatureFlags.ConfigCat != nil {
+		// the ConfigCat proxy is outside the cluster
+		egress = append(egress, common.AllowExternalEgressRule())
+	}
+
 	return []runtime.Object{&networkingv1.NetworkPolicy{
 		TypeMeta: common.TypeMetaNetworkPolicy,
 		ObjectMeta: metav1.ObjectMeta{
@@ -28,7 +52,8 @@ func networkpolicy(ctx *common.RenderContext) ([]runtime.Object, error) {
 		},
 		Spec: networkingv1.NetworkPolicySpec{
 			PodSelector: metav1.LabelSelector{MatchLabels: labels},
-			PolicyTypes: []networkingv1.PolicyType{"Ingress"},
+			PolicyTypes: common.NetworkPolicyTypes(ctx),
+			Egress:      common.NetworkPolicyEgress(ctx, egress...),
 			Ingress: []networkingv1.NetworkPolicyIngressRule{{
 				Ports: []networkingv1.NetworkPolicyPort{{
 					Protocol: common.TCPProtocol,
@@ -11,8 +11,12 @@ import (
 var Objects = common.CompositeRenderFunc(
 	configmap,
 	deployment,
+	featureFlags,
+	common.GenerateHorizontalPodAutoscaler(Component),
+	common.GeneratePodDisruptionBudget(Component),
 	networkpolicy,
 	rolebinding,
+	route,
 	service,
 	common.DefaultServiceAccount(Component),
 )
@@ -0,0 +1,86 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package proxy
+
+import (
+	"fmt"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// route exposes the proxy through the OpenShift router instead of a LoadBalancer service.
+// TLS is passed through as the proxy terminates it. A "wildcard" host with the Subdomain
+// policy matches every subdomain of its parent domain.
+func route(ctx *common.RenderContext) ([]runtime.Object, error) {
+	if !common.IsOpenShift(ctx) {
+		return nil, nil
+	}
+
+	type openShiftRoute struct {
+		Name           string
+		Host           string
+		WildcardPolicy string
+	}
+	routes := []openShiftRoute{
+		{
+			Name:           Component,
+			Host:           ctx.Config.Domain,
+			WildcardPolicy: "None",
+		},
+		{
+			Name:           fmt.Sprintf("%s-wildcard", Component),
+			Host:           fmt.Sprintf("wildcard.%s", ctx.Config.Domain),
+			WildcardPolicy: "Subdomain",
+		},
+	}
+	for i, d := range ctx.Config.WorkspaceDomains() {
+		name := fmt.Sprintf("%s-ws-wildcard", Component)
+		if i > 0 {
+			name = fmt.Sprintf("%s-%d", name, i)
+		}
+		routes = append(routes, openShiftRoute{
+			Name:           name,
+			Host:           fmt.Sprintf("wildcard.%s", d),
+			WildcardPolicy: "Subdomain",
+		})
+	}
+
+	var objects []runtime.Object
+	for _, r := range routes {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"host":           r.Host,
+				"wildcardPolicy": r.WildcardPolicy,
+				"to": map[string]interface{}{
+					"kind":   "Service",
+					"name":   Component,
+					"weight": int64(100),
+				},
+				"port": map[string]interface{}{
+					"targetPort": ContainerHTTPSName,
+				},
+				"tls": map[string]interface{}{
+					"termination":                   "passthrough",
+					"insecureEdgeTerminationPolicy": "Redirect",
+				},
+			},
+		}}
+		obj.SetAPIVersion(common.TypeMetaRoute.APIVersion)
+		obj.SetKind(common.TypeMetaRoute.Kind)
+		obj.SetName(r.Name)
+		obj.SetNamespace(ctx.Namespace)
+		obj.SetLabels(common.CustomizeLabel(ctx, Component, common.TypeMetaRoute))
+		if annotations := common.CustomizeAnnotation(ctx, Component, common.TypeMetaRoute); len(annotations) > 0 {
+			obj.SetAnnotations(annotations)
+		}
+
+		objects = append(objects, obj)
+	}
+
+	return objects, nil
+}
@@ -5,7 +5,7 @@
 package proxy
 
 import (
-	"fmt"
+	"strings"
 
 	"github.com/gitpod-io/gitpod/common-go/baseserver"
 	"github.com/gitpod-io/gitpod/installer/pkg/common"
@@ -36,8 +36,14 @@ func service(ctx *common.RenderContext) ([]runtime.Object, error) {
 	})
 
 	serviceType := corev1.ServiceTypeLoadBalancer
+	if common.IsOpenShift(ctx) {
+		// The proxy is exposed through Routes instead
+		serviceType = corev1.ServiceTypeClusterIP
+	}
+	var serviceConfig *configv1.ComponentTypeService
 	if ctx.Config.Components != nil && ctx.Config.Components.Proxy != nil && ctx.Config.Components.Proxy.Service != nil {
-		st := ctx.Config.Components.Proxy.Service.ServiceType
+		serviceConfig = ctx.Config.Components.Proxy.Service
+		st := serviceConfig.ServiceType
 		if st != nil {
 			_, allowed := allowedServiceTypes[corev1.ServiceType(*st)]
 			if allowed {
@@ -71,11 +77,11 @@ func service(ctx *common.RenderContext) ([]runtime.Object, error) {
 			ServicePort:   baseserver.BuiltinMetricsPort,
 		},
 	}
-	if ctx.Config.SSHGatewayHostKey != nil {
+	if ctx.Config.SSHGatewayHostKeySecret() != nil {
 		ports = append(ports, common.ServicePort{
 			Name:          ContainerSSHName,
 			ContainerPort: ContainerSSHPort,
-			ServicePort:   ContainerSSHPort,
+			ServicePort:   ctx.Config.SSHGatewayPort(),
 		})
 	}
 
@@ -84,17 +90,16 @@ func service(ctx *common.RenderContext) ([]runtime.Object, error) {
 		if serviceType == corev1.ServiceTypeLoadBalancer {
 			service.Spec.LoadBalancerIP = loadBalancerIP
 
-			installationShortNameSuffix := ""
-			if ctx.Config.Metadata.InstallationShortname != "" && ctx.Config.Metadata.InstallationShortname != configv1.InstallationShortNameOldDefault {
-				installationShortNameSuffix = "-" + ctx.Config.Metadata.InstallationShortname
+			hostnames := []string{ctx.Config.Domain, "*." + ctx.Config.Domain}
+			for _, d := range ctx.Config.WorkspaceDomains() {
+				hostnames = append(hostnames, "*."+d)
 			}
-
-			service.Annotations["external-dns.alpha.kubernetes.io/hostname"] = fmt.Sprintf("%s,*.%s,*.ws%s.%s", ctx.Config.Domain, ctx.Config.Domain, installationShortNameSuffix, ctx.Config.Domain)
+			service.Annotations["external-dns.alpha.kubernetes.io/hostname"] = strings.Join(hostnames, ",")
 			service.Annotations["cloud.google.com/neg"] = `{"exposed_ports": {"80":{},"443": {}}}`
 		}
 
 		for k, v := range annotations {
 			service.Annotations[k] = v
 		}
-	})(ctx)
+	}, common.WithServiceConfig(serviceConfig))(ctx)
 }
@@ -95,6 +95,20 @@ func TestServiceAnnotations(t *testing.T) {
 				}
 			},
 		},
+		{
+			Name: "Configured annotations",
+			Components: &config.Components{
+				Proxy: &config.ProxyComponent{
+					Service: &config.ComponentTypeService{
+						ServiceType: (*corev1.ServiceType)(pointer.String(string(corev1.ServiceTypeLoadBalancer))),
+						Annotations: map[string]string{"service.beta.kubernetes.io/aws-load-balancer-internal": "true"},
+					},
+				},
+			},
+			Expect: func(ctx *common.RenderContext, svc *corev1.Service, annotations map[string]string) {
+				require.Equal(t, "true", svc.Annotations["service.beta.kubernetes.io/aws-load-balancer-internal"])
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -140,3 +154,35 @@ func renderContextWithProxyConfig(t *testing.T, proxyConfig *experimental.ProxyC
 
 	return ctx
 }
+
+func TestServiceSSHGatewayPort(t *testing.T) {
+	ctx := renderContextWithProxyConfig(t, nil, &config.Components{
+		WSProxy: &config.WSProxyComponent{
+			SSHGateway: &config.SSHGateway{
+				Port:    pointer.Int32(2222),
+				HostKey: &config.ObjectRef{Kind: config.ObjectRefSecret, Name: "host-key"},
+			},
+		},
+	})
+
+	objects, err := service(ctx)
+	require.NoError(t, err)
+
+	svc := objects[0].(*corev1.Service)
+	var sshPort *corev1.ServicePort
+	for i, p := range svc.Spec.Ports {
+		if p.Name == ContainerSSHName {
+			sshPort = &svc.Spec.Ports[i]
+		}
+	}
+	require.NotNil(t, sshPort, "must expose the SSH gateway")
+	require.Equal(t, int32(2222), sshPort.Port)
+	require.Equal(t, int32(ContainerSSHPort), sshPort.TargetPort.IntVal)
+
+	ctx.Config.Components.WSProxy.SSHGateway.Enabled = pointer.Bool(false)
+	objects, err = service(ctx)
+	require.NoError(t, err)
+	for _, p := range objects[0].(*corev1.Service).Spec.Ports {
+		require.NotEqual(t, ContainerSSHName, p.Name, "must not expose a disabled SSH gateway")
+	}
+}
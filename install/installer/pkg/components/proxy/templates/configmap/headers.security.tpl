@@ -0,0 +1,4 @@
+# Response headers of the installation, imported in the security_headers snippet
+{{- range .Headers }}
+{{ . }}
+{{- end }}
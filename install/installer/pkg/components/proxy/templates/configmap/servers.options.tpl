@@ -0,0 +1,31 @@
+# Options of all servers, imported in the global servers block
+{{- if .TrustedProxies }}
+trusted_proxies static {{ .TrustedProxies }}
+{{- end }}
+{{- if .ProxyProtocol }}
+listener_wrappers {
+	proxy_protocol {
+		timeout {{ .ProxyProtocolTimeout }}
+		{{- if .ProxyProtocolAllow }}
+		allow {{ .ProxyProtocolAllow }}
+		{{- end }}
+	}
+	tls
+}
+{{- end }}
+{{- with .Timeouts }}
+timeouts {
+	{{- if .ReadBody }}
+	read_body {{ .ReadBody }}
+	{{- end }}
+	{{- if .ReadHeader }}
+	read_header {{ .ReadHeader }}
+	{{- end }}
+	{{- if .Write }}
+	write {{ .Write }}
+	{{- end }}
+	{{- if .Idle }}
+	idle {{ .Idle }}
+	{{- end }}
+}
+{{- end }}
@@ -1,10 +1,16 @@
 https://registry.{{.Domain}} {
     import enable_log
     import remove_server_header
+{{- if .CertificatesPath }}
+    tls {{.CertificatesPath}}/tls.crt {{.CertificatesPath}}/tls.key {
+        protocols tls1.2
+    }
+{{- else }}
     import ssl_configuration
+{{- end }}
 
     basicauth bcrypt "Docker Registry" {
-        {{.Username }} {{.Password}}
+        {{.Username }} {$DOCKER_REGISTRY_PASSWORD_HASH}
     }
 
     reverse_proxy {{.ReverseProxy}} {
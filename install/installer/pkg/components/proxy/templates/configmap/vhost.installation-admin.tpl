@@ -0,0 +1,11 @@
+https://installation-admin.{{.Domain}} {
+    import enable_log
+    import remove_server_header
+    import ssl_configuration
+
+    basicauth bcrypt "Installation Admin" {
+        {$INSTALLATION_ADMIN_USERNAME} {$INSTALLATION_ADMIN_PASSWORD_HASH}
+    }
+
+    reverse_proxy {{.ReverseProxy}}
+}
\ No newline at end of file
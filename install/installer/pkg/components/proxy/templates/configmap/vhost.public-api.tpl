@@ -0,0 +1,17 @@
+https://{{.Hostname}} {
+	log {
+		level DEBUG
+		output stdout
+	}
+	import remove_server_header
+	import ssl_configuration
+
+	gitpod.cors_origin {
+		allowed_origins https://{{.Domain}}
+		{{- if .GRPCWeb }}
+		grpc_web true
+		{{- end }}
+	}
+
+	reverse_proxy {{.ReverseProxy}}
+}
@@ -0,0 +1,10 @@
+# workspaces of the workspace domains that the wildcard of the domain does not cover
+{{ range $i, $d := .Domains }}{{ if $i }}, {{ end }}https://*.{{ $d }}{{ end }} {
+	import enable_log
+	import workspace_security_headers
+	import remove_server_header
+	import workspace_ssl_configuration
+	import debug_headers
+
+	import /etc/caddy/workspace-handler/*.{$WORKSPACE_HANDLER_FILE}
+}
@@ -9,6 +9,7 @@ import (
 	"net"
 	"strconv"
 
+	configv1 "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
 	"github.com/gitpod-io/gitpod/installer/pkg/config/v1/experimental"
 	"k8s.io/utils/pointer"
 
@@ -51,7 +52,7 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 	_, _, databaseSecretMountPath := common.DatabaseEnvSecret(ctx.Config)
 
 	cfg := config.Configuration{
-		PublicURL:                         fmt.Sprintf("https://api.%s", ctx.Config.Domain),
+		PublicURL:                         fmt.Sprintf("https://%s", ctx.Config.PublicAPIHostname()),
 		GitpodServiceURL:                  fmt.Sprintf("ws://%s.%s.svc.cluster.local:%d", server.Component, ctx.Namespace, server.ContainerPort),
 		OIDCClientJWTSigningSecretPath:    oidcClientJWTSigningSecretPath,
 		StripeWebhookSigningSecretPath:    stripeSecretPath,
@@ -59,9 +60,8 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 		BillingServiceAddress:             net.JoinHostPort(fmt.Sprintf("%s.%s.svc.cluster.local", usage.Component, ctx.Namespace), strconv.Itoa(usage.GRPCServicePort)),
 		SessionServiceAddress:             net.JoinHostPort(fmt.Sprintf("%s.%s.svc.cluster.local", common.ServerComponent, ctx.Namespace), strconv.Itoa(common.ServerIAMSessionPort)),
 		DatabaseConfigPath:                databaseSecretMountPath,
-		Redis: config.RedisConfiguration{
-			Address: fmt.Sprintf("%s:%d", redis.Component, redis.Port),
-		},
+		Redis:                             redisConfig(&ctx.Config),
+		RateLimits:                        rateLimits(&ctx.Config),
 		Server: &baseserver.Configuration{
 			Services: baseserver.ServicesConfiguration{
 				GRPC: &baseserver.ServerConfiguration{
@@ -71,6 +71,7 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 					Address: fmt.Sprintf("0.0.0.0:%d", HTTPContainerPort),
 				},
 			},
+			Builtin: common.BaseserverBuiltin(ctx),
 		},
 	}
 
@@ -95,6 +96,63 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 	}, nil
 }
 
+func rateLimits(cfg *configv1.Config) map[string]config.RateLimit {
+	if cfg.Components == nil || cfg.Components.PublicAPI == nil || len(cfg.Components.PublicAPI.RateLimits) == 0 {
+		return nil
+	}
+
+	res := make(map[string]config.RateLimit, len(cfg.Components.PublicAPI.RateLimits))
+	for method, limit := range cfg.Components.PublicAPI.RateLimits {
+		res[method] = config.RateLimit{
+			Requests:      uint(limit.Requests),
+			PeriodSeconds: uint(limit.PeriodSeconds),
+		}
+	}
+	return res
+}
+
+func redisConfig(cfg *configv1.Config) config.RedisConfiguration {
+	res := config.RedisConfiguration{
+		Address: redis.Address(cfg),
+	}
+
+	if !redis.InCluster(cfg) && cfg.Redis.External != nil {
+		res.TLS = cfg.Redis.External.TLS
+		_, _, res.CredentialsPath, _ = getRedisCredentials(cfg)
+	}
+
+	return res
+}
+
+func getRedisCredentials(cfg *configv1.Config) (corev1.Volume, corev1.VolumeMount, string, bool) {
+	var volume corev1.Volume
+	var mount corev1.VolumeMount
+	var path string
+
+	if redis.InCluster(cfg) || cfg.Redis.External == nil || cfg.Redis.External.Credentials == nil {
+		return volume, mount, path, false
+	}
+
+	path = redisCredentialsMountPath
+
+	volume = corev1.Volume{
+		Name: "redis-credentials",
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: cfg.Redis.External.Credentials.Name,
+			},
+		},
+	}
+
+	mount = corev1.VolumeMount{
+		Name:      "redis-credentials",
+		MountPath: path,
+		ReadOnly:  true,
+	}
+
+	return volume, mount, path, true
+}
+
 func getStripeConfig(cfg *experimental.Config) (corev1.Volume, corev1.VolumeMount, string, bool) {
 	var volume corev1.Volume
 	var mount corev1.VolumeMount
@@ -5,10 +5,12 @@
 package public_api_server
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 
 	"github.com/gitpod-io/gitpod/installer/pkg/components/redis"
+	configv1 "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
 	"github.com/gitpod-io/gitpod/installer/pkg/config/v1/experimental"
 	"github.com/google/go-cmp/cmp"
 
@@ -18,6 +20,7 @@ import (
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
 )
 
 func TestConfigMap(t *testing.T) {
@@ -90,3 +93,48 @@ func TestConfigMap(t *testing.T) {
 		t.Errorf("configMap mismatch (-want +got):\n%s", diff)
 	}
 }
+
+func TestConfigMap_ExternalRedis(t *testing.T) {
+	ctx := renderContextWithPublicAPI(t)
+	ctx.Config.Redis = &configv1.Redis{
+		InCluster: pointer.Bool(false),
+		External: &configv1.RedisExternal{
+			Host: "redis.example.com",
+			TLS:  true,
+			Credentials: &configv1.ObjectRef{
+				Kind: configv1.ObjectRefSecret,
+				Name: "redis-credentials",
+			},
+		},
+	}
+
+	require.Equal(t, config.RedisConfiguration{
+		Address:         "redis.example.com:6379",
+		CredentialsPath: redisCredentialsMountPath,
+		TLS:             true,
+	}, redisConfig(&ctx.Config))
+
+	objs, err := redis.Objects(ctx)
+	require.NoError(t, err)
+	require.Len(t, objs, 0, "must not render redis when an external instance is used")
+}
+
+func TestConfigMap_PublicAPIComponent(t *testing.T) {
+	ctx := renderContextWithPublicAPI(t)
+	ctx.Config.Components = &configv1.Components{PublicAPI: &configv1.PublicAPIComponent{
+		Hostname: "gitpod-api.example.com",
+		RateLimits: map[string]configv1.PublicAPIRateLimit{
+			"/gitpod.experimental.v1.WorkspacesService/ListWorkspaces": {Requests: 10, PeriodSeconds: 60},
+		},
+	}}
+
+	objs, err := configmap(ctx)
+	require.NoError(t, err)
+
+	var cfg config.Configuration
+	require.NoError(t, json.Unmarshal([]byte(objs[0].(*corev1.ConfigMap).Data[configJSONFilename]), &cfg))
+	require.Equal(t, "https://gitpod-api.example.com", cfg.PublicURL)
+	require.Equal(t, map[string]config.RateLimit{
+		"/gitpod.experimental.v1.WorkspacesService/ListWorkspaces": {Requests: 10, PeriodSeconds: 60},
+	}, cfg.RateLimits)
+}
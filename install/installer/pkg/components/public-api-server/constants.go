@@ -4,6 +4,8 @@
 
 package public_api_server
 
+import "github.com/gitpod-io/gitpod/installer/pkg/common"
+
 const (
 	Component = "public-api-server"
 
@@ -11,10 +13,11 @@ const (
 	GRPCContainerPort = 9001
 	GRPCServicePort   = 9001
 	HTTPContainerPort = 9002
-	HTTPServicePort   = 9002
+	HTTPServicePort   = common.PublicApiHTTPServicePort
 	HTTPPortName      = "http"
 
 	oidcClientJWTSigningKeyMountPath       = "/secrets/oidc-client-jwt-signing-key"
 	stripeSecretMountPath                  = "/secrets/stripe-webhook-secret"
 	personalAccessTokenSigningKeyMountPath = "/secrets/personal-access-token-signing-key"
+	redisCredentialsMountPath              = "/secrets/redis-credentials"
 )
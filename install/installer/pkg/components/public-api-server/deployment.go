@@ -47,6 +47,7 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 			},
 		},
 		databaseSecretVolume,
+		common.CAVolume(),
 	}
 	volumeMounts := []corev1.VolumeMount{
 		{
@@ -56,6 +57,12 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 			SubPath:   configJSONFilename,
 		},
 		databaseSecretMount,
+		common.CAVolumeMount(),
+	}
+
+	if volume, mount, _, ok := getRedisCredentials(&ctx.Config); ok {
+		volumes = append(volumes, volume)
+		volumeMounts = append(volumeMounts, mount)
 	}
 
 	_ = ctx.WithExperimental(func(cfg *experimental.Config) error {
@@ -92,6 +99,9 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 	})
 
 	labels := common.CustomizeLabel(ctx, Component, common.TypeMetaDeployment)
+	volumes = append(volumes, common.MetricsTLSVolumes(ctx, Component)...)
+	volumeMounts = append(volumeMounts, common.MetricsTLSVolumeMounts(ctx)...)
+
 	return []runtime.Object{
 		&appsv1.Deployment{
 			TypeMeta: common.TypeMetaDeployment,
@@ -117,14 +127,16 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 						Annotations: common.CustomizeAnnotation(ctx, Component, common.TypeMetaDeployment),
 					},
 					Spec: corev1.PodSpec{
-						Affinity:                      cluster.WithNodeAffinityHostnameAntiAffinity(Component, cluster.AffinityLabelMeta),
+						Affinity:                      common.Affinity(ctx, Component, cluster.WithNodeAffinityHostnameAntiAffinity(Component, cluster.AffinityLabelMeta)),
+						PriorityClassName:             common.PriorityClassName(ctx, Component, ""),
+						Tolerations:                   common.Tolerations(ctx, Component, nil),
 						TopologySpreadConstraints:     cluster.WithHostnameTopologySpread(Component),
 						ServiceAccountName:            Component,
 						EnableServiceLinks:            pointer.Bool(false),
 						DNSPolicy:                     corev1.DNSClusterFirst,
 						RestartPolicy:                 corev1.RestartPolicyAlways,
 						TerminationGracePeriodSeconds: pointer.Int64(30),
-						InitContainers:                []corev1.Container{*common.DatabaseWaiterContainer(ctx)},
+						InitContainers:                common.DatabaseWaiterContainers(ctx),
 						Containers: []corev1.Container{
 							{
 								Name:  Component,
@@ -155,6 +167,7 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 									common.DefaultEnv(&ctx.Config),
 									common.ConfigcatEnv(ctx),
 									common.DatabaseEnv(&ctx.Config),
+									common.WebappTracingEnv(ctx, Component),
 								)),
 								LivenessProbe: &corev1.Probe{
 									ProbeHandler: corev1.ProbeHandler{
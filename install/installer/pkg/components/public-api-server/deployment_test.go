@@ -12,6 +12,8 @@ import (
 
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
 )
 
 func TestDeployment(t *testing.T) {
@@ -64,6 +66,7 @@ func TestDeployment_ServerArguments(t *testing.T) {
 				},
 			},
 		},
+		common.CAVolume(),
 		{
 			Name: "oidc-client-jwt-signing-key",
 			VolumeSource: corev1.VolumeSource{
@@ -25,7 +25,15 @@ func networkpolicy(ctx *common.RenderContext) ([]runtime.Object, error) {
 			},
 			Spec: networkingv1.NetworkPolicySpec{
 				PodSelector: metav1.LabelSelector{MatchLabels: labels},
-				PolicyTypes: []networkingv1.PolicyType{"Ingress"},
+				PolicyTypes: common.NetworkPolicyTypes(ctx),
+				Egress: common.NetworkPolicyEgress(ctx,
+					common.AllowComponentsEgressRule(ctx, common.ServerComponent, common.UsageComponent),
+					common.AllowDatabaseEgressRule(ctx),
+					common.AllowRedisEgressRule(ctx),
+					common.AllowSpiceDBEgressRule(ctx),
+					// the OIDC providers and webhooks
+					common.AllowExternalEgressRule(),
+				),
 				Ingress: []networkingv1.NetworkPolicyIngressRule{
 					{
 						Ports: []networkingv1.NetworkPolicyPort{
@@ -10,9 +10,14 @@ import (
 )
 
 func Objects(ctx *common.RenderContext) ([]runtime.Object, error) {
+	if !ctx.Config.PublicAPIEnabled() {
+		return nil, nil
+	}
+
 	return common.CompositeRenderFunc(
 		configmap,
 		deployment,
+		common.GenerateHorizontalPodAutoscaler(Component),
 		rolebinding,
 		common.DefaultServiceAccount(Component),
 		service,
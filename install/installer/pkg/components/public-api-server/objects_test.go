@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"k8s.io/utils/pointer"
 
 	"github.com/gitpod-io/gitpod/installer/pkg/common"
 	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
@@ -23,6 +24,15 @@ func TestObjects_RenderedByDefault(t *testing.T) {
 	require.NotEmpty(t, objects)
 }
 
+func TestObjects_Disabled(t *testing.T) {
+	ctx := renderContextWithPublicAPI(t)
+	ctx.Config.Components = &config.Components{PublicAPI: &config.PublicAPIComponent{Enabled: pointer.Bool(false)}}
+
+	objects, err := Objects(ctx)
+	require.NoError(t, err)
+	require.Empty(t, objects)
+}
+
 func renderContextWithPublicAPI(t *testing.T) *common.RenderContext {
 	ctx, err := common.NewRenderContext(config.Config{
 		Domain: "test.domain.everything.awesome.is",
@@ -146,7 +146,7 @@ var Helm = common.CompositeHelmFunc(
 		return &common.HelmConfig{
 			Enabled: true,
 			Values: &values.Options{
-				Values: []string{
+				Values: append([]string{
 					helm.KeyValue("rabbitmq.auth.username", rabbitMQUsername),
 					helm.KeyValue("rabbitmq.auth.existingPasswordSecret", msgBusPasswordSecret),
 					helm.KeyValue("rabbitmq.auth.existingErlangSecret", CookieSecret),
@@ -160,7 +160,7 @@ var Helm = common.CompositeHelmFunc(
 					helm.KeyValue(fmt.Sprintf("rabbitmq.podAnnotations.%s", strings.Replace(common.AnnotationConfigChecksum, ".", "\\.", -1)), podAnnotations),
 
 					helm.KeyValue("rabbitmq.livenessProbe.initialDelaySeconds", "30"),
-				},
+				}, helm.OpenShiftValues(cfg, "rabbitmq", "rabbitmq")...),
 				// This is too complex to be sent as a string
 				FileValues: []string{
 					affinityTemplate,
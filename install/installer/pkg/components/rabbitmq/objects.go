@@ -8,6 +8,9 @@ import "github.com/gitpod-io/gitpod/installer/pkg/common"
 
 var Objects = common.CompositeRenderFunc(
 	configuration,
+	common.HelmDependencyNetworkPolicy(Component, map[string]string{
+		"app.kubernetes.io/name": "rabbitmq",
+	}),
 	rolebinding,
 	secrets,
 )
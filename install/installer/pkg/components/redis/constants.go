@@ -4,8 +4,10 @@
 
 package redis
 
+import "github.com/gitpod-io/gitpod/installer/pkg/common"
+
 const (
-	Component = "redis"
+	Component = common.RedisComponent
 
 	PortName = "api"
 	Port     = 6379
@@ -5,11 +5,21 @@
 package redis
 
 import (
+	"fmt"
+	"net"
+	"strconv"
+
 	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
 )
 
 func Objects(ctx *common.RenderContext) ([]runtime.Object, error) {
+	if !InCluster(&ctx.Config) {
+		return nil, nil
+	}
+
 	return common.CompositeRenderFunc(
 		deployment,
 		service,
@@ -18,3 +28,21 @@ func Objects(ctx *common.RenderContext) ([]runtime.Object, error) {
 		networkpolicy,
 	)(ctx)
 }
+
+// InCluster returns whether Redis is deployed to the cluster rather than an external instance being used
+func InCluster(cfg *config.Config) bool {
+	return cfg.Redis == nil || pointer.BoolDeref(cfg.Redis.InCluster, true)
+}
+
+// Address returns the host:port of the Redis instance
+func Address(cfg *config.Config) string {
+	if InCluster(cfg) || cfg.Redis.External == nil {
+		return fmt.Sprintf("%s:%d", Component, Port)
+	}
+
+	port := cfg.Redis.External.Port
+	if port == 0 {
+		port = Port
+	}
+	return net.JoinHostPort(cfg.Redis.External.Host, strconv.Itoa(int(port)))
+}
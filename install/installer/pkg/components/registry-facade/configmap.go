@@ -11,6 +11,7 @@ import (
 	"github.com/gitpod-io/gitpod/installer/pkg/common"
 	wsmanager "github.com/gitpod-io/gitpod/installer/pkg/components/ws-manager"
 	wsmanagermk2 "github.com/gitpod-io/gitpod/installer/pkg/components/ws-manager-mk2"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
 	"github.com/gitpod-io/gitpod/installer/pkg/config/v1/experimental"
 	regfac "github.com/gitpod-io/gitpod/registry-facade/api/config"
 
@@ -25,15 +26,26 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 		redisCache *regfac.RedisCacheConfig
 	)
 
-	remoteSpecProviders := []*regfac.RSProvider{
-		{
-			Addr: fmt.Sprintf("dns:///ws-manager:%d", wsmanager.RPCPort),
+	var remoteSpecProviders []*regfac.RSProvider
+	if ctx.Config.Workspace.Manager != config.WorkspaceManagerMk2 {
+		remoteSpecProviders = append(remoteSpecProviders, &regfac.RSProvider{
+			Addr: fmt.Sprintf("dns:///%s:%d", serviceHost(ctx, wsmanager.Component), wsmanager.RPCPort),
 			TLS: &regfac.TLS{
 				Authority:   "/ws-manager-client-tls-certs/ca.crt",
 				Certificate: "/ws-manager-client-tls-certs/tls.crt",
 				PrivateKey:  "/ws-manager-client-tls-certs/tls.key",
 			},
-		},
+		})
+	}
+	if common.UseWsManagerMk2(ctx) {
+		remoteSpecProviders = append(remoteSpecProviders, &regfac.RSProvider{
+			Addr: fmt.Sprintf("dns:///%s:%d", serviceHost(ctx, wsmanagermk2.Component), wsmanagermk2.RPCPort),
+			TLS: &regfac.TLS{
+				Authority:   "/ws-manager-mk2-client-tls-certs/ca.crt",
+				Certificate: "/ws-manager-mk2-client-tls-certs/tls.crt",
+				PrivateKey:  "/ws-manager-mk2-client-tls-certs/tls.key",
+			},
+		})
 	}
 
 	_ = ctx.WithExperimental(func(ucfg *experimental.Config) error {
@@ -60,17 +72,6 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 			}
 		}
 
-		if ucfg.Workspace.UseWsmanagerMk2 {
-			remoteSpecProviders = append(remoteSpecProviders, &regfac.RSProvider{
-				Addr: fmt.Sprintf("dns:///ws-manager-mk2:%d", wsmanagermk2.RPCPort),
-				TLS: &regfac.TLS{
-					Authority:   "/ws-manager-mk2-client-tls-certs/ca.crt",
-					Certificate: "/ws-manager-mk2-client-tls-certs/tls.crt",
-					PrivateKey:  "/ws-manager-mk2-client-tls-certs/tls.key",
-				},
-			})
-		}
-
 		return nil
 	})
 
@@ -106,6 +107,9 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 		PrometheusAddr:     common.LocalhostPrometheusAddr(),
 		ReadinessProbeAddr: fmt.Sprintf(":%v", ReadinessPort),
 	}
+	if _, _, path, ok := common.UpstreamRegistryAuth(ctx); ok {
+		rfcfg.UpstreamAuthCfg = path
+	}
 
 	fc, err := common.ToJSONString(rfcfg)
 	if err != nil {
@@ -127,3 +131,12 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 		},
 	}, nil
 }
+
+// serviceHost is the host of a service of the installation, which is qualified with its
+// namespace if registry-facade runs in the infrastructure namespace
+func serviceHost(ctx *common.RenderContext, service string) string {
+	if !common.SeparateInfrastructureNamespace(ctx) {
+		return service
+	}
+	return fmt.Sprintf("%s.%s.svc", service, ctx.Namespace)
+}
@@ -35,13 +35,18 @@ func daemonset(ctx *common.RenderContext) ([]runtime.Object, error) {
 		hashObj = append(hashObj, objs...)
 	}
 
+	certSecret := common.RegistryFacadeTLSCertSecret
+	if ctx.Config.RegistryCertificate != nil {
+		certSecret = ctx.Config.RegistryCertificate.Name
+	}
+
 	var (
 		volumes = []corev1.Volume{
 			{
 				Name: "config-certificates",
 				VolumeSource: corev1.VolumeSource{
 					Secret: &corev1.SecretVolumeSource{
-						SecretName: "builtin-registry-facade-cert",
+						SecretName: certSecret,
 					},
 				},
 			},
@@ -114,29 +119,34 @@ func daemonset(ctx *common.RenderContext) ([]runtime.Object, error) {
 			}
 		}
 
-		if ucfg.Workspace.UseWsmanagerMk2 {
-			volumes = append(volumes, corev1.Volume{
-				Name: wsManagerMk2ClientTlsVolume,
-				VolumeSource: corev1.VolumeSource{
-					Secret: &corev1.SecretVolumeSource{
-						SecretName: wsmanagermk2.TLSSecretNameClient,
-					},
-				},
-			})
-
-			volumeMounts = append(volumeMounts, corev1.VolumeMount{
-				Name:      wsManagerMk2ClientTlsVolume,
-				MountPath: "/ws-manager-mk2-client-tls-certs",
-				ReadOnly:  true,
-			})
-		}
-
 		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	if common.UseWsManagerMk2(ctx) {
+		volumes = append(volumes, corev1.Volume{
+			Name: wsManagerMk2ClientTlsVolume,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: wsmanagermk2.TLSSecretNameClient,
+				},
+			},
+		})
+
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      wsManagerMk2ClientTlsVolume,
+			MountPath: "/ws-manager-mk2-client-tls-certs",
+			ReadOnly:  true,
+		})
+	}
+
+	if volume, mount, _, ok := common.UpstreamRegistryAuth(ctx); ok {
+		volumes = append(volumes, volume)
+		volumeMounts = append(volumeMounts, mount)
+	}
+
 	initContainers := []corev1.Container{
 		{
 			Name:  "setup",
@@ -185,8 +195,8 @@ func daemonset(ctx *common.RenderContext) ([]runtime.Object, error) {
 					}),
 				},
 				Spec: corev1.PodSpec{
-					PriorityClassName:             common.SystemNodeCritical,
-					Affinity:                      cluster.WithNodeAffinity(cluster.AffinityLabelWorkspacesRegular, cluster.AffinityLabelWorkspacesHeadless),
+					PriorityClassName:             common.PriorityClassName(ctx, Component, common.SystemNodeCritical),
+					Affinity:                      common.Affinity(ctx, Component, cluster.WithNodeAffinity(cluster.AffinityLabelWorkspacesRegular, cluster.AffinityLabelWorkspacesHeadless)),
 					ServiceAccountName:            Component,
 					EnableServiceLinks:            pointer.Bool(false),
 					DNSPolicy:                     corev1.DNSClusterFirst,
@@ -317,10 +327,10 @@ func daemonset(ctx *common.RenderContext) ([]runtime.Object, error) {
 						},
 						common.CAVolume(),
 					}, volumes...),
-					Tolerations: common.GPUToleration(),
+					Tolerations: common.Tolerations(ctx, Component, common.GPUToleration()),
 				},
 			},
-			UpdateStrategy: common.DaemonSetRolloutStrategy(),
+			UpdateStrategy: common.DaemonSetRolloutStrategy(ctx, Component),
 		},
 	}}, nil
 }
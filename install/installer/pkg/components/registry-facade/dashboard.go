@@ -0,0 +1,53 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package registryfacade
+
+import (
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func dashboard(ctx *common.RenderContext) ([]runtime.Object, error) {
+	return common.GenerateGrafanaDashboard(Component, []common.GrafanaPanel{
+		{
+			Title: "Blob pulls",
+			Unit:  "reqps",
+			Queries: []common.GrafanaQuery{
+				{Expr: `sum(rate(gitpod_registry_facade_registry_blob_req_total{$selector}[5m]))`, Legend: "upstream"},
+				{Expr: `sum(rate(gitpod_registry_facade_downstream_blob_req_total{$selector}[5m]))`, Legend: "downstream"},
+			},
+		},
+		{
+			Title: "Failed requests",
+			Unit:  "reqps",
+			Queries: []common.GrafanaQuery{
+				{Expr: `sum(rate(gitpod_registry_facade_upstream_req_failed_total{$selector}[5m])) by (type)`, Legend: "upstream {{type}}"},
+				{Expr: `sum(rate(gitpod_registry_facade_downstream_req_failed_total{$selector}[5m])) by (type)`, Legend: "downstream {{type}}"},
+			},
+		},
+		{
+			Title: "Manifest request duration",
+			Unit:  "s",
+			Queries: []common.GrafanaQuery{
+				{Expr: `histogram_quantile(0.95, sum(rate(gitpod_registry_facade_registry_manifest_req_seconds_bucket{$selector}[5m])) by (le))`, Legend: "p95 upstream"},
+				{Expr: `histogram_quantile(0.95, sum(rate(gitpod_registry_facade_downstream_manifest_req_seconds_bucket{$selector}[5m])) by (le))`, Legend: "p95 downstream"},
+			},
+		},
+		{
+			Title: "gRPC calls",
+			Unit:  "reqps",
+			Queries: []common.GrafanaQuery{
+				{Expr: `sum(rate(grpc_server_handled_total{$selector}[5m])) by (grpc_method, grpc_code)`, Legend: "{{grpc_method}} {{grpc_code}}"},
+			},
+		},
+		{
+			Title: "Goroutines",
+			Unit:  "short",
+			Queries: []common.GrafanaQuery{
+				{Expr: `go_goroutines{$selector}`, Legend: "{{pod}}"},
+			},
+		},
+	})(ctx)
+}
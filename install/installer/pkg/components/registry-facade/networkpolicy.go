@@ -24,8 +24,14 @@ func networkpolicy(ctx *common.RenderContext) ([]runtime.Object, error) {
 		},
 		Spec: networkingv1.NetworkPolicySpec{
 			PodSelector: metav1.LabelSelector{MatchLabels: labels},
-			PolicyTypes: []networkingv1.PolicyType{"Ingress"},
-			Ingress:     []networkingv1.NetworkPolicyIngressRule{{}},
+			PolicyTypes: common.NetworkPolicyTypes(ctx),
+			Egress: common.NetworkPolicyEgress(ctx,
+				common.AllowComponentsEgressRule(ctx, common.WSManagerComponent, common.WSManagerMk2Component),
+				common.AllowContainerRegistryEgressRule(ctx),
+				// the registries of the workspace images
+				common.AllowExternalEgressRule(),
+			),
+			Ingress: []networkingv1.NetworkPolicyIngressRule{{}},
 		},
 	}}, nil
 }
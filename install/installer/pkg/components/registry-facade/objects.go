@@ -11,27 +11,32 @@ import (
 )
 
 var Objects = common.CompositeRenderFunc(
-	clusterrole,
-	configmap,
-	daemonset,
-	networkpolicy,
-	podsecuritypolicy,
-	rolebinding,
-	certificate,
-	common.GenerateService(Component, []common.ServicePort{
-		{
-			Name:          ContainerPortName,
-			ContainerPort: ServicePort,
-			ServicePort:   ServicePort,
-		},
-	}, func(svc *corev1.Service) {
-		svc.Spec.Type = corev1.ServiceTypeNodePort
-		svc.Spec.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyTypeLocal
+	common.InInfrastructureNamespace(
+		clusterrole,
+		configmap,
+		daemonset,
+		networkpolicy,
+		podsecuritypolicy,
+		rolebinding,
+		certificate,
+		clientTLSSecrets,
+		pullSecret,
+		common.GenerateService(Component, []common.ServicePort{
+			{
+				Name:          ContainerPortName,
+				ContainerPort: ServicePort,
+				ServicePort:   ServicePort,
+			},
+		}, func(svc *corev1.Service) {
+			svc.Spec.Type = corev1.ServiceTypeNodePort
+			svc.Spec.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyTypeLocal
 
-		clusterInternalTrafficPolicy := corev1.ServiceInternalTrafficPolicyLocal
-		svc.Spec.InternalTrafficPolicy = &clusterInternalTrafficPolicy
+			clusterInternalTrafficPolicy := corev1.ServiceInternalTrafficPolicyLocal
+			svc.Spec.InternalTrafficPolicy = &clusterInternalTrafficPolicy
 
-		svc.Spec.Ports[0].NodePort = common.RegistryFacadeServicePort
-	}),
-	common.DefaultServiceAccount(Component),
+			svc.Spec.Ports[0].NodePort = common.RegistryFacadeServicePort
+		}),
+		common.DefaultServiceAccount(Component),
+	),
+	dashboard,
 )
@@ -0,0 +1,42 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package registryfacade
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	dockerregistry "github.com/gitpod-io/gitpod/installer/pkg/components/docker-registry"
+	wsmanager "github.com/gitpod-io/gitpod/installer/pkg/components/ws-manager"
+	wsmanagermk2 "github.com/gitpod-io/gitpod/installer/pkg/components/ws-manager-mk2"
+)
+
+// The secrets of the namespace of the installation cannot be mounted in the infrastructure
+// namespace, so the secrets the installer generates are rendered there as well. The secrets
+// of the config must be created in both namespaces.
+
+// clientTLSSecrets issues the client certificates registry-facade connects to ws-manager with
+func clientTLSSecrets(ctx *common.RenderContext) ([]runtime.Object, error) {
+	if !common.SeparateInfrastructureNamespace(ctx) {
+		return nil, nil
+	}
+
+	res := []runtime.Object{
+		common.InternalCertificate(ctx, wsmanager.Component, wsmanager.Component, wsmanager.TLSSecretNameClient, []string{Component}),
+	}
+	if common.UseWsManagerMk2(ctx) {
+		res = append(res, common.InternalCertificate(ctx, wsmanagermk2.Component, wsmanagermk2.Component, wsmanagermk2.TLSSecretNameClient, []string{Component}))
+	}
+	return res, nil
+}
+
+// pullSecret renders the pull secret of the in-cluster registry
+func pullSecret(ctx *common.RenderContext) ([]runtime.Object, error) {
+	if !common.SeparateInfrastructureNamespace(ctx) || !pointer.BoolDeref(ctx.Config.ContainerRegistry.InCluster, false) {
+		return nil, nil
+	}
+	return dockerregistry.AuthSecret(ctx)
+}
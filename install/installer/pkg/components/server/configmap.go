@@ -10,12 +10,14 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gitpod-io/gitpod/installer/pkg/common"
 	contentservice "github.com/gitpod-io/gitpod/installer/pkg/components/content-service"
 	ideservice "github.com/gitpod-io/gitpod/installer/pkg/components/ide-service"
 	"github.com/gitpod-io/gitpod/installer/pkg/components/usage"
 	"github.com/gitpod-io/gitpod/installer/pkg/components/workspace"
+	configv1 "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
 	"github.com/gitpod-io/gitpod/installer/pkg/config/v1/experimental"
 	"github.com/gitpod-io/gitpod/ws-manager/api/config"
 	corev1 "k8s.io/api/core/v1"
@@ -25,10 +27,7 @@ import (
 )
 
 func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
-	jwtSecret, err := common.RandomString(20)
-	if err != nil {
-		return nil, err
-	}
+	jwtSecret := ctx.Values.ServerJWTSecret
 	_ = ctx.WithExperimental(func(cfg *experimental.Config) error {
 		if cfg.WebApp != nil && cfg.WebApp.Server != nil && cfg.WebApp.Server.OAuthServer.JWTSecret != "" {
 			jwtSecret = cfg.WebApp.Server.OAuthServer.JWTSecret
@@ -41,7 +40,17 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 		workspaceImage = ctx.ImageName(common.ThirdPartyContainerRepo(ctx.Config.Repository, ""), workspace.DefaultWorkspaceImage, workspace.DefaultWorkspaceImageVersion)
 	}
 
+	defaultFeatureFlags := []NamedWorkspaceFeatureFlag{}
+	if ctx.Config.Workspace.EnablePVC {
+		defaultFeatureFlags = append(defaultFeatureFlags, NamedWorkspaceFeatureFlagPersistentVolumeClaim)
+	}
+
 	sessionSecret := "Important!Really-Change-This-Key!"
+	if ctx.Config.SecretRotations[configv1.SecretGroupServer] > 0 {
+		// Generating the session secret would sign everyone out on each render without a seed,
+		// so it is only generated once the server secrets are rotated
+		sessionSecret = ctx.Values.ServerSessionSecret
+	}
 	_ = ctx.WithExperimental(func(cfg *experimental.Config) error {
 		if cfg.WebApp != nil && cfg.WebApp.Server != nil && cfg.WebApp.Server.Session.Secret != "" {
 			sessionSecret = cfg.WebApp.Server.Session.Secret
@@ -142,6 +151,18 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 		}
 		return nil
 	})
+	if app := githubAppConfig(ctx); app != nil {
+		githubApp = GitHubApp{
+			Enabled:           true,
+			AppId:             app.AppID,
+			BaseUrl:           app.BaseURL,
+			WebhookSecretFile: filepath.Join(githubAppWebhookSecretMountPath, githubAppWebhookSecretKey),
+			AuthProviderId:    app.AuthProviderID,
+			CertPath:          filepath.Join(githubAppCertMountPath, githubAppPrivateKeyKey),
+			MarketplaceName:   app.MarketplaceName,
+			CertSecretName:    app.PrivateKey.Name,
+		}
+	}
 
 	workspaceClasses := []WorkspaceClass{
 		{
@@ -153,6 +174,23 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 			IsDefault:   true,
 		},
 	}
+	for _, cl := range ctx.Config.Workspace.Classes {
+		displayName := cl.DisplayName
+		if displayName == "" {
+			displayName = strings.Title(cl.Name)
+		}
+		if cl.Default {
+			workspaceClasses[0].IsDefault = false
+		}
+		workspaceClasses = append(workspaceClasses, WorkspaceClass{
+			Id:          cl.Name,
+			Category:    GeneralPurpose,
+			DisplayName: displayName,
+			Description: cl.Description,
+			PowerUps:    1,
+			IsDefault:   cl.Default,
+		})
+	}
 	ctx.WithExperimental(func(cfg *experimental.Config) error {
 		if cfg.WebApp != nil && cfg.WebApp.WorkspaceClasses != nil && len(cfg.WebApp.WorkspaceClasses) > 0 {
 			workspaceClasses = nil
@@ -210,27 +248,15 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 		WorkspaceDefaults: WorkspaceDefaults{
 			WorkspaceImage:      workspaceImage,
 			PreviewFeatureFlags: []NamedWorkspaceFeatureFlag{},
-			DefaultFeatureFlags: []NamedWorkspaceFeatureFlag{},
+			DefaultFeatureFlags: defaultFeatureFlags,
 			TimeoutDefault:      ctx.Config.Workspace.TimeoutDefault,
 			TimeoutExtended:     ctx.Config.Workspace.TimeoutExtended,
+			DisableDotfiles:     ctx.Config.Workspace.ImagePolicy != nil && ctx.Config.Workspace.ImagePolicy.DisableDotfiles,
 		},
-		Session: Session{
-			MaxAgeMs: 259200000,
-			Secret:   sessionSecret,
-		},
-		DefinitelyGpDisabled: ctx.Config.DisableDefinitelyGP,
-		GitHubApp:            githubApp,
-		WorkspaceGarbageCollection: WorkspaceGarbageCollection{
-			Disabled:                   disableWsGarbageCollection,
-			IntervalSeconds:            5 * 60,
-			MinAgeDays:                 14,
-			MinAgePrebuildDays:         7,
-			ChunkLimit:                 1000,
-			ContentRetentionPeriodDays: 21,
-			ContentChunkLimit:          100,
-			PurgeRetentionPeriodDays:   365,
-			PurgeChunkLimit:            5000,
-		},
+		Session:                    sessionConfig(ctx, sessionSecret),
+		DefinitelyGpDisabled:       ctx.Config.DisableDefinitelyGP,
+		GitHubApp:                  githubApp,
+		WorkspaceGarbageCollection: workspaceGarbageCollection(ctx, disableWsGarbageCollection),
 		LongRunningMigrationsJob: JobConfig{
 			Disabled: disableLongRunningMigrationsJob,
 		},
@@ -238,6 +264,37 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 			Disabled: disableCompleteSnapshotJob,
 		},
 		EnableLocalApp: enableLocalApp,
+		AuthProviderConfigs: func() []AuthProviderConfig {
+			if ctx.Config.Components == nil || ctx.Config.Components.Server == nil {
+				return nil
+			}
+
+			var providers []AuthProviderConfig
+			for i, provider := range ctx.Config.Components.Server.AuthProviders {
+				// the self-hosted Git hosts may serve HTTPS on another port
+				if scm := ctx.Config.SCMHost(provider.Host); scm != nil && !strings.Contains(provider.Host, ":") {
+					provider.Host = scm.Address()
+				}
+				oauth := AuthProviderOAuth{
+					ClientID:         provider.ClientID,
+					ClientSecretFile: authProviderSecretPath(i),
+					CallBackURL:      provider.CallbackURLForDomain(ctx.Config.Domain),
+					AuthorizationURL: provider.AuthorizationURL,
+					TokenURL:         provider.TokenURL,
+				}
+				if len(provider.Scopes) > 0 {
+					oauth.Scope = strings.Join(provider.Scopes, " ")
+					oauth.ScopeSeparator = " "
+				}
+				providers = append(providers, AuthProviderConfig{
+					ID:    provider.ID,
+					Host:  provider.Host,
+					Type:  provider.Type,
+					OAuth: oauth,
+				})
+			}
+			return providers
+		}(),
 		AuthProviderConfigFiles: func() []string {
 			providers := make([]string, 0)
 
@@ -278,7 +335,7 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 		IDEServiceAddr:               net.JoinHostPort(fmt.Sprintf("%s.%s.svc.cluster.local", ideservice.Component, ctx.Namespace), strconv.Itoa(ideservice.GRPCServicePort)),
 		MaximumEventLoopLag:          0.35,
 		CodeSync:                     CodeSync{},
-		VSXRegistryUrl:               fmt.Sprintf("https://open-vsx.%s", ctx.Config.Domain), // todo(sje): or "https://{{ .Values.vsxRegistry.host | default "open-vsx.org" }}" if not using OpenVSX proxy
+		VSXRegistryUrl:               vsxRegistryURL(ctx),
 		EnablePayment:                chargebeeSecret != "" || stripeSecret != "" || stripeConfig != "",
 		ChargebeeProviderOptionsFile: fmt.Sprintf("%s/providerOptions", chargebeeMountPath),
 		StripeSecretsFile:            fmt.Sprintf("%s/apikeys", stripeSecretMountPath),
@@ -300,6 +357,59 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 		ShowSetupModal: showSetupModal,
 	}
 
+	if ctx.Config.Components != nil && ctx.Config.Components.Server != nil && ctx.Config.Components.Server.RateLimits != nil {
+		limits := ctx.Config.Components.Server.RateLimits
+		for name, g := range limits.Groups {
+			scfg.RateLimiter.Groups[name] = GroupsConfig{
+				Points:       g.Points,
+				DurationsSec: g.DurationSeconds,
+			}
+		}
+		for name, m := range limits.Methods {
+			scfg.RateLimiter.Functions[name] = FunctionsConfig{
+				Group:  m.Group,
+				Points: pointer.Int32Deref(m.Points, 1),
+			}
+		}
+		for cloneURL, p := range limits.Prebuilds {
+			scfg.PrebuildLimiter[cloneURL] = PrebuildRateLimiterConfig{
+				Limit:  p.Limit,
+				Period: p.PeriodSeconds,
+			}
+		}
+	}
+
+	if ctx.Config.Components != nil && ctx.Config.Components.Server != nil {
+		for _, r := range ctx.Config.Components.Server.BlockedRepositories {
+			scfg.BlockedRepositories = append(scfg.BlockedRepositories, BlockedRepository{
+				URLRegExp: r.URLRegExp,
+				BlockUser: r.BlockUser,
+			})
+		}
+		scfg.BlockedDomains = ctx.Config.Components.Server.BlockedDomains
+		if interval := ctx.Config.Components.Server.WebSocketPingInterval; interval != nil {
+			scfg.WebsocketPingIntervalMs = time.Duration(*interval).Milliseconds()
+		}
+	}
+
+	if prebuilds := ctx.Config.Workspace.Prebuilds; prebuilds != nil {
+		if prebuilds.MaxConcurrentPerRef != nil {
+			scfg.MaxConcurrentPrebuildsPerRef = *prebuilds.MaxConcurrentPerRef
+		}
+		scfg.MaxConcurrentPrebuildsPerProject = pointer.Int32Deref(prebuilds.MaxConcurrentPerProject, 0)
+		scfg.MaxConcurrentPrebuildsPerTeam = pointer.Int32Deref(prebuilds.MaxConcurrentPerOrganization, 0)
+		scfg.PrebuildWorkspaceClass = prebuilds.Class
+	}
+	if ports := ctx.Config.Workspace.Ports; ports != nil {
+		scfg.WorkspacePorts = &WorkspacePorts{
+			DefaultVisibility: ports.DefaultVisibility,
+			DisablePublic:     ports.DisablePublic,
+		}
+		for _, r := range ports.AllowedRanges {
+			scfg.WorkspacePorts.AllowedRanges = append(scfg.WorkspacePorts.AllowedRanges, PortRange{From: r.From, To: r.To})
+		}
+	}
+
 	fc, err := common.ToJSONString(scfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal server config: %w", err)
@@ -372,3 +482,59 @@ func getAdminCredentials() (corev1.Volume, corev1.VolumeMount, string) {
 
 	return volume, mount, filepath.Join(AdminCredentialsSecretMountPath, AdminCredentialsSecretKey)
 }
+
+// githubAppConfig returns the GitHub App of the main config, which takes precedence over the experimental one
+func githubAppConfig(ctx *common.RenderContext) *configv1.ServerGitHubApp {
+	if ctx.Config.Components == nil || ctx.Config.Components.Server == nil {
+		return nil
+	}
+	return ctx.Config.Components.Server.GitHubApp
+}
+
+// authProviderSecretPath is the file of the client secret of the auth provider with the index in the config
+func authProviderSecretPath(i int) string {
+	return filepath.Join(authProviderSecretsPath, strconv.Itoa(i), "clientSecret")
+}
+
+// workspaceGarbageCollection configures the deletion of old workspaces, which is what removes their
+// backups from the object storage
+func workspaceGarbageCollection(ctx *common.RenderContext, disabled bool) WorkspaceGarbageCollection {
+	res := WorkspaceGarbageCollection{
+		Disabled:                   disabled,
+		IntervalSeconds:            5 * 60,
+		MinAgeDays:                 14,
+		MinAgePrebuildDays:         7,
+		ChunkLimit:                 1000,
+		ContentRetentionPeriodDays: 21,
+		ContentChunkLimit:          100,
+		PurgeRetentionPeriodDays:   365,
+		PurgeChunkLimit:            5000,
+	}
+
+	if ctx.Config.ObjectStorage.Backups == nil || ctx.Config.ObjectStorage.Backups.GarbageCollection == nil {
+		return res
+	}
+	gc := ctx.Config.ObjectStorage.Backups.GarbageCollection
+	if gc.Interval != nil {
+		res.IntervalSeconds = int32(time.Duration(*gc.Interval).Seconds())
+	}
+	if gc.MinAgeDays != nil {
+		res.MinAgeDays = *gc.MinAgeDays
+	}
+	if gc.MinAgePrebuildDays != nil {
+		res.MinAgePrebuildDays = *gc.MinAgePrebuildDays
+	}
+	if gc.ContentRetentionPeriodDays != nil {
+		res.ContentRetentionPeriodDays = *gc.ContentRetentionPeriodDays
+	}
+	return res
+}
+
+// vsxRegistryURL is the registry the extensions are installed from, the openvsx-proxy in front
+// of the OpenVSX URL or the OpenVSX URL itself if the proxy is disabled
+func vsxRegistryURL(ctx *common.RenderContext) string {
+	if !ctx.Config.OpenVSXProxyEnabled() {
+		return strings.TrimSuffix(ctx.Config.OpenVSX.URL, "/")
+	}
+	return fmt.Sprintf("https://open-vsx.%s", ctx.Config.Domain)
+}
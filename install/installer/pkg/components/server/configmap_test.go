@@ -7,12 +7,14 @@ package server
 import (
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/utils/pointer"
 
+	"github.com/gitpod-io/gitpod/common-go/util"
 	"github.com/gitpod-io/gitpod/installer/pkg/common"
 	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
 	"github.com/gitpod-io/gitpod/installer/pkg/config/v1/experimental"
@@ -126,3 +128,227 @@ func TestConfigMap(t *testing.T) {
 
 	assert.Equal(t, expectation, actual)
 }
+
+func TestConfigMap_RateLimits(t *testing.T) {
+	cfg := renderServerConfig(t, config.Config{
+		Components: &config.Components{
+			Server: &config.ServerComponent{
+				RateLimits: &config.ServerRateLimits{
+					Groups: map[string]config.ServerRateLimitGroup{
+						"startWorkspace": {Points: 30, DurationSeconds: 10},
+					},
+					Methods: map[string]config.ServerRateLimitMethod{
+						"triggerPrebuild": {Group: "startWorkspace"},
+					},
+					Prebuilds: map[string]config.ServerPrebuildRateLimit{
+						"*": {Limit: 500, PeriodSeconds: 600},
+					},
+				},
+			},
+		},
+	})
+
+	require.Equal(t, GroupsConfig{Points: 30, DurationsSec: 10}, cfg.RateLimiter.Groups["startWorkspace"])
+	require.Equal(t, GroupsConfig{Points: 10, DurationsSec: 2}, cfg.RateLimiter.Groups["inWorkspaceUserAction"], "the default groups are kept")
+	require.Equal(t, FunctionsConfig{Group: "startWorkspace", Points: 1}, cfg.RateLimiter.Functions["triggerPrebuild"])
+	require.Equal(t, PrebuildRateLimiterConfig{Limit: 500, Period: 600}, cfg.PrebuildLimiter["*"])
+}
+
+func TestConfigMap_BlockedRepositories(t *testing.T) {
+	cfg := renderServerConfig(t, config.Config{
+		Components: &config.Components{
+			Server: &config.ServerComponent{
+				BlockedRepositories: []config.ServerBlockedRepository{
+					{URLRegExp: "^https://github.com/spam/.*", BlockUser: true},
+				},
+				BlockedDomains: []string{"example.com"},
+			},
+		},
+	})
+
+	require.Equal(t, []BlockedRepository{{URLRegExp: "^https://github.com/spam/.*", BlockUser: true}}, cfg.BlockedRepositories)
+	require.Equal(t, []string{"example.com"}, cfg.BlockedDomains)
+}
+
+func TestConfigMap_WebSocketPingInterval(t *testing.T) {
+	require.Zero(t, renderServerConfig(t, config.Config{}).WebsocketPingIntervalMs)
+
+	interval := util.Duration(10 * time.Second)
+	cfg := renderServerConfig(t, config.Config{
+		Components: &config.Components{
+			Server: &config.ServerComponent{WebSocketPingInterval: &interval},
+		},
+	})
+	require.Equal(t, int64(10000), cfg.WebsocketPingIntervalMs)
+}
+
+func TestConfigMap_EnablePVC(t *testing.T) {
+	require.Empty(t, renderServerConfig(t, config.Config{}).WorkspaceDefaults.DefaultFeatureFlags)
+
+	cfg := renderServerConfig(t, config.Config{
+		Workspace: config.Workspace{EnablePVC: true},
+	})
+	require.Equal(t, []NamedWorkspaceFeatureFlag{NamedWorkspaceFeatureFlagPersistentVolumeClaim}, cfg.WorkspaceDefaults.DefaultFeatureFlags)
+}
+
+func TestConfigMap_DisableDotfiles(t *testing.T) {
+	require.False(t, renderServerConfig(t, config.Config{}).WorkspaceDefaults.DisableDotfiles)
+
+	cfg := renderServerConfig(t, config.Config{
+		Workspace: config.Workspace{ImagePolicy: &config.WorkspaceImagePolicy{DisableDotfiles: true}},
+	})
+	require.True(t, cfg.WorkspaceDefaults.DisableDotfiles)
+}
+
+func TestConfigMap_BackupGarbageCollection(t *testing.T) {
+	interval := util.Duration(time.Hour)
+	cfg := renderServerConfig(t, config.Config{
+		ObjectStorage: config.ObjectStorage{
+			Backups: &config.ObjectStorageBackups{
+				GarbageCollection: &config.BackupGarbageCollection{
+					Interval:                   &interval,
+					MinAgeDays:                 pointer.Int32(30),
+					ContentRetentionPeriodDays: pointer.Int32(0),
+				},
+			},
+		},
+	})
+
+	gc := cfg.WorkspaceGarbageCollection
+	require.Equal(t, int32(3600), gc.IntervalSeconds)
+	require.Equal(t, int32(30), gc.MinAgeDays)
+	require.Equal(t, int32(7), gc.MinAgePrebuildDays)
+	require.Equal(t, int32(0), gc.ContentRetentionPeriodDays)
+}
+
+func TestConfigMap_AuthProviders(t *testing.T) {
+	cfg := renderServerConfig(t, config.Config{
+		Domain: "gitpod.example.com",
+		Components: &config.Components{
+			Server: &config.ServerComponent{
+				AuthProviders: []config.ServerAuthProvider{
+					{
+						ID:           "GitLab-Internal",
+						Host:         "gitlab.example.com:8443",
+						Type:         "GitLab",
+						ClientID:     "client-id",
+						ClientSecret: config.ObjectRef{Kind: config.ObjectRefSecret, Name: "gitlab-oauth"},
+						Scopes:       []string{"api", "read_user"},
+					},
+				},
+			},
+		},
+	})
+
+	require.Equal(t, []AuthProviderConfig{{
+		ID:   "GitLab-Internal",
+		Host: "gitlab.example.com:8443",
+		Type: "GitLab",
+		OAuth: AuthProviderOAuth{
+			ClientID:         "client-id",
+			ClientSecretFile: "/gitpod/auth-provider-secrets/0/clientSecret",
+			CallBackURL:      "https://gitpod.example.com/auth/gitlab.example.com_8443/callback",
+			Scope:            "api read_user",
+			ScopeSeparator:   " ",
+		},
+	}}, cfg.AuthProviderConfigs)
+}
+
+func TestConfigMap_AuthProvidersSCMHost(t *testing.T) {
+	cfg := renderServerConfig(t, config.Config{
+		Domain:   "gitpod.example.com",
+		SCMHosts: []config.SCMHost{{Host: "gitlab.corp.example.com", HTTPSPort: 3000, CIDRs: []string{"10.20.0.10/32"}}},
+		Components: &config.Components{
+			Server: &config.ServerComponent{
+				AuthProviders: []config.ServerAuthProvider{
+					{
+						ID:           "GitLab-Corp",
+						Host:         "gitlab.corp.example.com",
+						Type:         "GitLab",
+						ClientID:     "client-id",
+						ClientSecret: config.ObjectRef{Kind: config.ObjectRefSecret, Name: "gitlab-oauth"},
+					},
+				},
+			},
+		},
+	})
+
+	require.Len(t, cfg.AuthProviderConfigs, 1)
+	require.Equal(t, "gitlab.corp.example.com:3000", cfg.AuthProviderConfigs[0].Host)
+	require.Equal(t, "https://gitpod.example.com/auth/gitlab.corp.example.com_3000/callback", cfg.AuthProviderConfigs[0].OAuth.CallBackURL)
+}
+
+func TestConfigMap_Prebuilds(t *testing.T) {
+	cfg := renderServerConfig(t, config.Config{})
+	require.Equal(t, int32(10), cfg.MaxConcurrentPrebuildsPerRef)
+	require.Zero(t, cfg.MaxConcurrentPrebuildsPerProject)
+	require.Empty(t, cfg.PrebuildWorkspaceClass)
+
+	cfg = renderServerConfig(t, config.Config{
+		Workspace: config.Workspace{
+			Classes: []config.WorkspaceClass{{Name: "large"}},
+			Prebuilds: &config.WorkspacePrebuilds{
+				Class:                        "large",
+				MaxConcurrentPerRef:          pointer.Int32(2),
+				MaxConcurrentPerProject:      pointer.Int32(5),
+				MaxConcurrentPerOrganization: pointer.Int32(20),
+			},
+		},
+	})
+	require.Equal(t, int32(2), cfg.MaxConcurrentPrebuildsPerRef)
+	require.Equal(t, int32(5), cfg.MaxConcurrentPrebuildsPerProject)
+	require.Equal(t, int32(20), cfg.MaxConcurrentPrebuildsPerTeam)
+	require.Equal(t, "large", cfg.PrebuildWorkspaceClass)
+}
+
+func renderServerConfig(t *testing.T, cfg config.Config) ConfigSerialized {
+	ctx, err := common.NewRenderContext(cfg, versions.Manifest{}, "test_namespace")
+	require.NoError(t, err)
+
+	objs, err := configmap(ctx)
+	require.NoError(t, err)
+
+	var res ConfigSerialized
+	require.NoError(t, json.Unmarshal([]byte(objs[0].(*corev1.ConfigMap).Data["config.json"]), &res))
+	return res
+}
+
+func TestConfigMap_Session(t *testing.T) {
+	cfg := renderServerConfig(t, config.Config{})
+	require.Equal(t, int32(259200000), cfg.Session.MaxAgeMs)
+	require.Nil(t, cfg.Session.Cookie, "the cookie is left to the server's defaults")
+
+	maxAge := util.Duration(12 * time.Hour)
+	cfg = renderServerConfig(t, config.Config{
+		Components: &config.Components{
+			Server: &config.ServerComponent{
+				Session: &config.ServerSession{
+					MaxAge: &maxAge,
+					Cookie: &config.ServerSessionCookie{SameSite: "None", Secure: pointer.Bool(true)},
+				},
+			},
+		},
+	})
+
+	require.Equal(t, int32(43200000), cfg.Session.MaxAgeMs)
+	require.Equal(t, &SessionCookie{SameSite: "none", Secure: true}, cfg.Session.Cookie)
+}
+
+func TestConfigMap_WorkspacePorts(t *testing.T) {
+	cfg := renderServerConfig(t, config.Config{})
+	require.Nil(t, cfg.WorkspacePorts)
+
+	cfg = renderServerConfig(t, config.Config{
+		Workspace: config.Workspace{
+			Ports: &config.WorkspacePorts{
+				DefaultVisibility: "public",
+				AllowedRanges:     []config.WorkspacePortRange{{From: 3000, To: 3999}},
+				DefaultProtocol:   "https",
+			},
+		},
+	})
+	require.Equal(t, &WorkspacePorts{
+		DefaultVisibility: "public",
+		AllowedRanges:     []PortRange{{From: 3000, To: 3999}},
+	}, cfg.WorkspacePorts)
+}
@@ -13,10 +13,16 @@ const (
 	ContainerPort                          = 3000
 	ContainerPortName                      = "http"
 	authProviderFilePath                   = "/gitpod/auth-providers"
+	authProviderSecretsPath                = "/gitpod/auth-provider-secrets"
 	licenseFilePath                        = "/gitpod/license"
 	chargebeeMountPath                     = "/chargebee"
 	stripeSecretMountPath                  = "/stripe-secret"
 	githubAppCertSecret                    = "github-app-cert-secret"
+	githubAppCertMountPath                 = "/github-app-cert"
+	githubAppPrivateKeyKey                 = "privateKey"
+	githubAppWebhookSecret                 = "github-app-webhook-secret"
+	githubAppWebhookSecretMountPath        = "/github-app-webhook-secret"
+	githubAppWebhookSecretKey              = "webhookSecret"
 	IAMSessionPort                         = common.ServerIAMSessionPort
 	IAMSessionPortName                     = "session"
 	InstallationAdminPort                  = common.ServerInstallationAdminPort
@@ -0,0 +1,57 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package server
+
+import (
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func dashboard(ctx *common.RenderContext) ([]runtime.Object, error) {
+	return common.GenerateGrafanaDashboard(Component, []common.GrafanaPanel{
+		{
+			Title: "API requests",
+			Unit:  "reqps",
+			Queries: []common.GrafanaQuery{
+				{Expr: `sum(rate(gitpod_server_api_calls_total{$selector}[5m])) by (method)`, Legend: "{{method}}"},
+			},
+		},
+		{
+			Title: "API errors",
+			Unit:  "reqps",
+			Queries: []common.GrafanaQuery{
+				{Expr: `sum(rate(gitpod_server_api_calls_total{$selector, statusCode!~"2.*|3.*|4.*"}[5m])) by (method)`, Legend: "{{method}}"},
+			},
+		},
+		{
+			Title: "API request duration",
+			Unit:  "s",
+			Queries: []common.GrafanaQuery{
+				{Expr: `histogram_quantile(0.95, sum(rate(gitpod_server_api_calls_duration_seconds_bucket{$selector}[5m])) by (method, le))`, Legend: "p95 {{method}}"},
+			},
+		},
+		{
+			Title: "HTTP requests",
+			Unit:  "reqps",
+			Queries: []common.GrafanaQuery{
+				{Expr: `sum(rate(gitpod_server_http_requests_total{$selector}[5m])) by (statusCode)`, Legend: "{{statusCode}}"},
+			},
+		},
+		{
+			Title: "Websocket connections",
+			Unit:  "short",
+			Queries: []common.GrafanaQuery{
+				{Expr: `sum(server_websocket_connection_count{$selector}) by (clientType)`, Legend: "{{clientType}}"},
+			},
+		},
+		{
+			Title: "Event loop lag",
+			Unit:  "s",
+			Queries: []common.GrafanaQuery{
+				{Expr: `nodejs_eventloop_lag_seconds{$selector}`, Legend: "{{pod}}"},
+			},
+		},
+	})(ctx)
+}
@@ -55,6 +55,9 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 								if ctx.Config.Database.CloudSQL != nil {
 									return "cloudsql"
 								}
+								if ctx.Config.Database.UsePostgres() {
+									return "external-postgres"
+								}
 								return "external"
 							}(),
 						},
@@ -84,6 +87,8 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 		common.MessageBusEnv(&ctx.Config),
 		common.ConfigcatEnv(ctx),
 		spicedb.Env(ctx),
+		common.NodeCAEnv(),
+		common.GRPCClientEnv(ctx),
 		[]corev1.EnvVar{
 			{
 				Name:  "CONFIG_PATH",
@@ -141,6 +146,26 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 		}
 	}
 
+	if ctx.Config.Components != nil && ctx.Config.Components.Server != nil {
+		for i, provider := range ctx.Config.Components.Server.AuthProviders {
+			volumeName := fmt.Sprintf("auth-provider-secret-%d", i)
+			volumes = append(volumes, corev1.Volume{
+				Name: volumeName,
+				VolumeSource: corev1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{
+						SecretName: provider.ClientSecret.Name,
+					},
+				},
+			})
+
+			volumeMounts = append(volumeMounts, corev1.VolumeMount{
+				Name:      volumeName,
+				MountPath: path.Dir(authProviderSecretPath(i)),
+				ReadOnly:  true,
+			})
+		}
+	}
+
 	// mount the optional twilio secret
 	truethy := true
 	volumes = append(volumes, corev1.Volume{
@@ -205,8 +230,40 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 		return nil
 	})
 
+	if app := githubAppConfig(ctx); app != nil {
+		volumes = append(volumes,
+			corev1.Volume{
+				Name: githubAppCertSecret,
+				VolumeSource: corev1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{
+						SecretName: app.PrivateKey.Name,
+					},
+				},
+			},
+			corev1.Volume{
+				Name: githubAppWebhookSecret,
+				VolumeSource: corev1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{
+						SecretName: app.WebhookSecret.Name,
+					},
+				},
+			})
+
+		volumeMounts = append(volumeMounts,
+			corev1.VolumeMount{
+				Name:      githubAppCertSecret,
+				MountPath: githubAppCertMountPath,
+				ReadOnly:  true,
+			},
+			corev1.VolumeMount{
+				Name:      githubAppWebhookSecret,
+				MountPath: githubAppWebhookSecretMountPath,
+				ReadOnly:  true,
+			})
+	}
+
 	_ = ctx.WithExperimental(func(cfg *experimental.Config) error {
-		if cfg.WebApp != nil && cfg.WebApp.Server != nil && cfg.WebApp.Server.GithubApp != nil {
+		if githubAppConfig(ctx) == nil && cfg.WebApp != nil && cfg.WebApp.Server != nil && cfg.WebApp.Server.GithubApp != nil {
 			volumes = append(volumes,
 				corev1.Volume{
 					Name: githubAppCertSecret,
@@ -254,10 +311,27 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 		})
 	}
 
+	clusterVolumes, clusterMounts := wsmanagerbridge.WorkspaceClusterVolumes(ctx)
+	volumes = append(volumes, clusterVolumes...)
+	volumeMounts = append(volumeMounts, clusterMounts...)
+
 	adminCredentialsVolume, adminCredentialsMount, _ := getAdminCredentials()
 	volumes = append(volumes, adminCredentialsVolume)
 	volumeMounts = append(volumeMounts, adminCredentialsMount)
 
+	strategy := *common.DeploymentStrategy.DeepCopy()
+	var terminationGracePeriod *int64
+	if ctx.Config.Components != nil && ctx.Config.Components.Server != nil {
+		server := ctx.Config.Components.Server
+		if server.MaxSurge != nil {
+			strategy.RollingUpdate.MaxSurge = server.MaxSurge
+		}
+		if server.MaxUnavailable != nil {
+			strategy.RollingUpdate.MaxUnavailable = server.MaxUnavailable
+		}
+		terminationGracePeriod = server.TerminationGracePeriodSeconds
+	}
+
 	return []runtime.Object{
 		&appsv1.Deployment{
 			TypeMeta: common.TypeMetaDeployment,
@@ -270,7 +344,7 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 			Spec: appsv1.DeploymentSpec{
 				Selector: &metav1.LabelSelector{MatchLabels: common.DefaultLabels(Component)},
 				Replicas: common.Replicas(ctx, Component),
-				Strategy: common.DeploymentStrategy,
+				Strategy: strategy,
 				Template: corev1.PodTemplateSpec{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      Component,
@@ -283,11 +357,13 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 						}),
 					},
 					Spec: corev1.PodSpec{
-						Affinity:                  cluster.WithNodeAffinityHostnameAntiAffinity(Component, cluster.AffinityLabelMeta),
-						TopologySpreadConstraints: cluster.WithHostnameTopologySpread(Component),
-						PriorityClassName:         common.SystemNodeCritical,
-						ServiceAccountName:        Component,
-						EnableServiceLinks:        pointer.Bool(false),
+						Affinity:                      common.Affinity(ctx, Component, cluster.WithNodeAffinityHostnameAntiAffinity(Component, cluster.AffinityLabelMeta)),
+						Tolerations:                   common.Tolerations(ctx, Component, nil),
+						TopologySpreadConstraints:     cluster.WithHostnameTopologySpread(Component),
+						PriorityClassName:             common.PriorityClassName(ctx, Component, common.SystemNodeCritical),
+						ServiceAccountName:            Component,
+						EnableServiceLinks:            pointer.Bool(false),
+						TerminationGracePeriodSeconds: terminationGracePeriod,
 						// todo(sje): do we need to cater for serverContainer.volumeMounts from values.yaml?
 						Volumes: append(
 							[]corev1.Volume{
@@ -303,7 +379,7 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 							},
 							volumes...,
 						),
-						InitContainers: []corev1.Container{*common.DatabaseWaiterContainer(ctx), *common.MessageBusWaiterContainer(ctx)},
+						InitContainers: append(common.DatabaseWaiterContainers(ctx), *common.MessageBusWaiterContainer(ctx)),
 						Containers: []corev1.Container{{
 							Name:            Component,
 							Image:           ctx.ImageName(ctx.Config.Repository, Component, ctx.VersionManifest.Components.Server.Version),
@@ -333,29 +409,7 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 								AllowPrivilegeEscalation: pointer.Bool(false),
 								RunAsUser:                pointer.Int64(31001),
 							},
-							Ports: []corev1.ContainerPort{{
-								Name:          ContainerPortName,
-								ContainerPort: ContainerPort,
-							}, {
-								Name:          baseserver.BuiltinMetricsPortName,
-								ContainerPort: baseserver.BuiltinMetricsPort,
-							}, {
-								Name:          InstallationAdminName,
-								ContainerPort: InstallationAdminPort,
-							}, {
-								Name:          IAMSessionPortName,
-								ContainerPort: IAMSessionPort,
-							}, {
-								Name:          DebugPortName,
-								ContainerPort: baseserver.BuiltinDebugPort,
-							}, {
-								Name:          DebugNodePortName,
-								ContainerPort: common.DebugNodePort,
-							}, {
-								Name:          GRPCAPIName,
-								ContainerPort: GRPCAPIPort,
-							},
-							},
+							Ports: containerPorts(ctx),
 							// todo(sje): do we need to cater for serverContainer.env from values.yaml?
 							Env: common.CustomizeEnvvar(ctx, Component, env),
 							// todo(sje): do we need to cater for serverContainer.volumeMounts from values.yaml?
@@ -377,3 +431,32 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 		},
 	}, nil
 }
+
+func containerPorts(ctx *common.RenderContext) []corev1.ContainerPort {
+	ports := []corev1.ContainerPort{{
+		Name:          ContainerPortName,
+		ContainerPort: ContainerPort,
+	}, {
+		Name:          baseserver.BuiltinMetricsPortName,
+		ContainerPort: baseserver.BuiltinMetricsPort,
+	}, {
+		Name:          InstallationAdminName,
+		ContainerPort: InstallationAdminPort,
+	}, {
+		Name:          IAMSessionPortName,
+		ContainerPort: IAMSessionPort,
+	}}
+	if common.DebugPortsEnabled(ctx, Component) {
+		ports = append(ports, corev1.ContainerPort{
+			Name:          DebugPortName,
+			ContainerPort: baseserver.BuiltinDebugPort,
+		}, corev1.ContainerPort{
+			Name:          DebugNodePortName,
+			ContainerPort: common.DebugNodePort,
+		})
+	}
+	return append(ports, corev1.ContainerPort{
+		Name:          GRPCAPIName,
+		ContainerPort: GRPCAPIPort,
+	})
+}
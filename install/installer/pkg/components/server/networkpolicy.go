@@ -5,9 +5,14 @@
 package server
 
 import (
+	"fmt"
+
 	"github.com/gitpod-io/gitpod/common-go/baseserver"
 	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	contentservice "github.com/gitpod-io/gitpod/installer/pkg/components/content-service"
 	"github.com/gitpod-io/gitpod/installer/pkg/components/gitpod"
+	ideservice "github.com/gitpod-io/gitpod/installer/pkg/components/ide-service"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
 
 	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -28,8 +33,25 @@ func Networkpolicy(ctx *common.RenderContext, component string) ([]runtime.Objec
 			},
 			Spec: networkingv1.NetworkPolicySpec{
 				PodSelector: metav1.LabelSelector{MatchLabels: labels},
-				PolicyTypes: []networkingv1.PolicyType{"Ingress"},
-				Ingress: []networkingv1.NetworkPolicyIngressRule{
+				PolicyTypes: common.NetworkPolicyTypes(ctx),
+				Egress: common.NetworkPolicyEgress(ctx,
+					common.AllowDatabaseEgressRule(ctx),
+					common.AllowRedisEgressRule(ctx),
+					common.AllowSpiceDBEgressRule(ctx),
+					common.AllowMessageBusEgressRule(ctx),
+					common.AllowComponentsEgressRule(ctx,
+						common.WSManagerComponent,
+						common.WSManagerMk2Component,
+						contentservice.Component,
+						common.ImageBuilderComponent,
+						common.ImageBuilderComponentWsman,
+						common.UsageComponent,
+						ideservice.Component,
+					),
+					// the Git hosts and the auth providers
+					common.AllowExternalEgressRule(),
+				),
+				Ingress: append([]networkingv1.NetworkPolicyIngressRule{
 					{
 						Ports: []networkingv1.NetworkPolicyPort{
 							{
@@ -86,23 +108,6 @@ func Networkpolicy(ctx *common.RenderContext, component string) ([]runtime.Objec
 							},
 						},
 					},
-					{
-						Ports: []networkingv1.NetworkPolicyPort{
-							{
-								Protocol: common.TCPProtocol,
-								Port:     &intstr.IntOrString{IntVal: InstallationAdminPort},
-							},
-						},
-						From: []networkingv1.NetworkPolicyPeer{
-							{
-								PodSelector: &metav1.LabelSelector{
-									MatchLabels: map[string]string{
-										"component": gitpod.Component,
-									},
-								},
-							},
-						},
-					},
 					{
 						Ports: []networkingv1.NetworkPolicyPort{
 							{
@@ -147,8 +152,59 @@ func Networkpolicy(ctx *common.RenderContext, component string) ([]runtime.Objec
 							},
 						},
 					},
+				}, common.DebugPortsIngressRules(ctx, component, baseserver.BuiltinDebugPort, common.DebugNodePort)...),
+			},
+		},
+	}, nil
+}
+
+// installationAdminNetworkpolicy lets the clients of the installation-admin port in. It is kept
+// apart from the policy of the other ports, so the port is closed when it is disabled.
+func installationAdminNetworkpolicy(ctx *common.RenderContext) ([]runtime.Object, error) {
+	exposure := ctx.Config.InstallationAdminExposure()
+	if exposure == config.InstallationAdminDisabled {
+		return nil, nil
+	}
+
+	from := []networkingv1.NetworkPolicyPeer{{
+		PodSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{
+				"component": gitpod.Component,
+			},
+		},
+	}}
+	if exposure == config.InstallationAdminProxy {
+		from = append(from, networkingv1.NetworkPolicyPeer{
+			PodSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"component": common.ProxyComponent,
 				},
 			},
+		})
+	}
+
+	labels := common.DefaultLabels(Component)
+	return []runtime.Object{
+		&networkingv1.NetworkPolicy{
+			TypeMeta: common.TypeMetaNetworkPolicy,
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-installation-admin", Component),
+				Namespace: ctx.Namespace,
+				Labels:    labels,
+			},
+			Spec: networkingv1.NetworkPolicySpec{
+				PodSelector: metav1.LabelSelector{MatchLabels: labels},
+				PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+				Ingress: []networkingv1.NetworkPolicyIngressRule{{
+					Ports: []networkingv1.NetworkPolicyPort{
+						{
+							Protocol: common.TCPProtocol,
+							Port:     &intstr.IntOrString{IntVal: InstallationAdminPort},
+						},
+					},
+					From: from,
+				}},
+			},
 		},
 	}, nil
 }
@@ -5,7 +5,6 @@
 package server
 
 import (
-	"github.com/gitpod-io/gitpod/common-go/baseserver"
 	"github.com/gitpod-io/gitpod/installer/pkg/common"
 	"k8s.io/apimachinery/pkg/runtime"
 )
@@ -13,51 +12,19 @@ import (
 var Objects = common.CompositeRenderFunc(
 	configmap,
 	deployment,
+	common.GenerateHorizontalPodAutoscaler(Component),
+	common.GeneratePodDisruptionBudget(Component),
 	func(ctx *common.RenderContext) ([]runtime.Object, error) {
 		return Networkpolicy(ctx, Component)
 	},
+	installationAdminNetworkpolicy,
 	func(ctx *common.RenderContext) ([]runtime.Object, error) {
 		return Role(ctx, Component)
 	},
 	func(ctx *common.RenderContext) ([]runtime.Object, error) {
 		return Rolebinding(ctx, Component)
 	},
-	common.GenerateService(Component, []common.ServicePort{
-		{
-			Name:          ContainerPortName,
-			ContainerPort: ContainerPort,
-			ServicePort:   ServicePort,
-		},
-		{
-			Name:          baseserver.BuiltinMetricsPortName,
-			ContainerPort: baseserver.BuiltinMetricsPort,
-			ServicePort:   baseserver.BuiltinMetricsPort,
-		},
-		{
-			Name:          InstallationAdminName,
-			ContainerPort: InstallationAdminPort,
-			ServicePort:   InstallationAdminPort,
-		},
-		{
-			Name:          IAMSessionPortName,
-			ContainerPort: IAMSessionPort,
-			ServicePort:   IAMSessionPort,
-		},
-		{
-			Name:          DebugPortName,
-			ContainerPort: baseserver.BuiltinDebugPort,
-			ServicePort:   baseserver.BuiltinDebugPort,
-		},
-		{
-			Name:          DebugNodePortName,
-			ContainerPort: common.DebugNodePort,
-			ServicePort:   common.DebugNodePort,
-		},
-		{
-			Name:          GRPCAPIName,
-			ContainerPort: GRPCAPIPort,
-			ServicePort:   GRPCAPIPort,
-		},
-	}),
+	service,
 	common.DefaultServiceAccount(Component),
+	dashboard,
 )
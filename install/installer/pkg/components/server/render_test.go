@@ -9,12 +9,18 @@ import (
 
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/pointer"
 
+	"github.com/gitpod-io/gitpod/common-go/baseserver"
 	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	"github.com/gitpod-io/gitpod/installer/pkg/components/gitpod"
 	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
 	"github.com/gitpod-io/gitpod/installer/pkg/config/v1/experimental"
-	"github.com/gitpod-io/gitpod/installer/pkg/config/versions"
+	rendertest "github.com/gitpod-io/gitpod/installer/pkg/testing"
 )
 
 func TestServerDeployment_MountsGithubAppSecret(t *testing.T) {
@@ -47,6 +53,43 @@ func TestServerDeployment_MountsGithubAppSecret(t *testing.T) {
 	require.Truef(t, foundMount, "failed to find expected volume mount %q on server container", githubAppCertSecret)
 }
 
+func TestServerDeployment_MountsGitHubAppConfig(t *testing.T) {
+	ctx := renderContext(t)
+	ctx.Config.Components = &config.Components{
+		Server: &config.ServerComponent{
+			GitHubApp: &config.ServerGitHubApp{
+				AppID:          1234,
+				AuthProviderID: "Public-GitHub",
+				WebhookSecret:  config.ObjectRef{Kind: config.ObjectRefSecret, Name: "github-app-webhook"},
+				PrivateKey:     config.ObjectRef{Kind: config.ObjectRefSecret, Name: "github-app-key"},
+			},
+		},
+	}
+
+	objects, err := deployment(ctx)
+	require.NoError(t, err)
+	require.Len(t, objects, 1, "must render only one object")
+
+	secrets := make(map[string]string)
+	for _, vol := range objects[0].(*appsv1.Deployment).Spec.Template.Spec.Volumes {
+		if vol.Secret != nil {
+			secrets[vol.Name] = vol.Secret.SecretName
+		}
+	}
+	require.Equal(t, "github-app-key", secrets[githubAppCertSecret], "the main config takes precedence over the experimental one")
+	require.Equal(t, "github-app-webhook", secrets[githubAppWebhookSecret])
+
+	cfg := renderServerConfig(t, ctx.Config)
+	require.Equal(t, GitHubApp{
+		Enabled:           true,
+		AppId:             1234,
+		AuthProviderId:    "Public-GitHub",
+		WebhookSecretFile: "/github-app-webhook-secret/webhookSecret",
+		CertPath:          "/github-app-cert/privateKey",
+		CertSecretName:    "github-app-key",
+	}, cfg.GitHubApp)
+}
+
 func TestServerDeployment_UsesTracingConfig(t *testing.T) {
 	ctx := renderContext(t)
 
@@ -71,6 +114,141 @@ func TestServerDeployment_UsesTracingConfig(t *testing.T) {
 	require.Equal(t, "12.5", actualSamplerParam)
 }
 
+func TestServerDeployment_UsesRolloutConfig(t *testing.T) {
+	ctx := renderContext(t)
+	maxSurge := intstr.FromString("25%")
+	ctx.Config.Components = &config.Components{
+		Server: &config.ServerComponent{
+			Replicas:                      pointer.Int32(4),
+			MaxSurge:                      &maxSurge,
+			TerminationGracePeriodSeconds: pointer.Int64(60),
+		},
+	}
+
+	objects, err := deployment(ctx)
+	require.NoError(t, err)
+	require.Len(t, objects, 1, "must render only one object")
+
+	deployment := objects[0].(*appsv1.Deployment)
+	require.Equal(t, pointer.Int32(4), deployment.Spec.Replicas)
+	require.Equal(t, &maxSurge, deployment.Spec.Strategy.RollingUpdate.MaxSurge)
+	require.Equal(t, common.DeploymentStrategy.RollingUpdate.MaxUnavailable, deployment.Spec.Strategy.RollingUpdate.MaxUnavailable)
+	require.Equal(t, pointer.Int64(60), deployment.Spec.Template.Spec.TerminationGracePeriodSeconds)
+	require.Equal(t, intstr.FromInt(1), *common.DeploymentStrategy.RollingUpdate.MaxSurge, "the default strategy is not modified")
+}
+
+func TestServerDebugPorts(t *testing.T) {
+	debugPorts := func(objs []runtime.Object) (service, container []int32, ingress [][]int32) {
+		for _, obj := range objs {
+			switch o := obj.(type) {
+			case *corev1.Service:
+				for _, p := range o.Spec.Ports {
+					if p.Name == DebugPortName || p.Name == DebugNodePortName {
+						service = append(service, p.Port)
+					}
+				}
+			case *appsv1.Deployment:
+				for _, p := range o.Spec.Template.Spec.Containers[0].Ports {
+					if p.Name == DebugPortName || p.Name == DebugNodePortName {
+						container = append(container, p.ContainerPort)
+					}
+				}
+			case *networkingv1.NetworkPolicy:
+				for _, rule := range o.Spec.Ingress {
+					if len(rule.From) == 0 || rule.From[0].NamespaceSelector == nil || rule.From[0].NamespaceSelector.MatchLabels["kubernetes.io/metadata.name"] != "debug" {
+						continue
+					}
+					var ports []int32
+					for _, p := range rule.Ports {
+						ports = append(ports, p.Port.IntVal)
+					}
+					ingress = append(ingress, ports)
+				}
+			}
+		}
+		return
+	}
+	all := []int32{baseserver.BuiltinDebugPort, common.DebugNodePort}
+
+	tests := []struct {
+		Name              string
+		DebugPorts        *config.DebugPorts
+		ExpectedPorts     []int32
+		ExpectedIngresses [][]int32
+	}{
+		{Name: "not configured", ExpectedPorts: all},
+		{Name: "disabled", DebugPorts: &config.DebugPorts{Namespace: "debug"}},
+		{Name: "enabled", DebugPorts: &config.DebugPorts{Enabled: true, Namespace: "debug"}, ExpectedPorts: all, ExpectedIngresses: [][]int32{all}},
+		{Name: "disabled for the server", DebugPorts: &config.DebugPorts{Enabled: true, Components: map[string]bool{Component: false}}},
+		{Name: "enabled for the server", DebugPorts: &config.DebugPorts{Components: map[string]bool{Component: true}}, ExpectedPorts: all},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			ctx := renderContext(t)
+			if test.DebugPorts != nil {
+				ctx.Config.Network = &config.Network{DebugPorts: test.DebugPorts}
+			}
+
+			objs, err := Objects(ctx)
+			require.NoError(t, err)
+
+			service, container, ingress := debugPorts(objs)
+			require.Equal(t, test.ExpectedPorts, service)
+			require.Equal(t, test.ExpectedPorts, container)
+			require.Equal(t, test.ExpectedIngresses, ingress)
+		})
+	}
+}
+
+func TestServerInstallationAdmin(t *testing.T) {
+	tests := []struct {
+		Name            string
+		Exposure        config.InstallationAdminExposure
+		ExpectedService bool
+		ExpectedFrom    []string
+	}{
+		{Name: "not configured", ExpectedService: true, ExpectedFrom: []string{gitpod.Component}},
+		{Name: "disabled", Exposure: config.InstallationAdminDisabled},
+		{Name: "cluster", Exposure: config.InstallationAdminCluster, ExpectedService: true, ExpectedFrom: []string{gitpod.Component}},
+		{Name: "proxy", Exposure: config.InstallationAdminProxy, ExpectedService: true, ExpectedFrom: []string{gitpod.Component, common.ProxyComponent}},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			ctx := renderContext(t)
+			if test.Exposure != "" {
+				ctx.Config.Components = &config.Components{Server: &config.ServerComponent{
+					InstallationAdmin: &config.ServerInstallationAdmin{Exposure: test.Exposure},
+				}}
+			}
+
+			objs, err := Objects(ctx)
+			require.NoError(t, err)
+
+			var service bool
+			var from []string
+			for _, obj := range objs {
+				switch o := obj.(type) {
+				case *corev1.Service:
+					for _, p := range o.Spec.Ports {
+						service = service || p.Name == InstallationAdminName
+					}
+				case *networkingv1.NetworkPolicy:
+					for _, rule := range o.Spec.Ingress {
+						if rule.Ports[0].Port.IntVal != InstallationAdminPort {
+							continue
+						}
+						for _, peer := range rule.From {
+							from = append(from, peer.PodSelector.MatchLabels["component"])
+						}
+					}
+				}
+			}
+			require.Equal(t, test.ExpectedService, service)
+			require.Equal(t, test.ExpectedFrom, from)
+		})
+	}
+}
+
 func renderContext(t *testing.T) *common.RenderContext {
 	var samplerType experimental.TracingSampleType = "probabilistic"
 
@@ -106,16 +284,7 @@ func renderContext(t *testing.T) *common.RenderContext {
 				},
 			},
 		},
-	}, versions.Manifest{
-		Components: versions.Components{
-			ServiceWaiter: versions.Versioned{
-				Version: "arbitrary",
-			},
-			Server: versions.Versioned{
-				Version: "arbitrary",
-			},
-		},
-	}, "test-namespace")
+	}, rendertest.Manifest(), "test-namespace")
 	require.NoError(t, err)
 
 	return ctx
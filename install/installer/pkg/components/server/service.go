@@ -0,0 +1,58 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package server
+
+import (
+	"github.com/gitpod-io/gitpod/common-go/baseserver"
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func service(ctx *common.RenderContext) ([]runtime.Object, error) {
+	ports := []common.ServicePort{
+		{
+			Name:          ContainerPortName,
+			ContainerPort: ContainerPort,
+			ServicePort:   ServicePort,
+		},
+		{
+			Name:          baseserver.BuiltinMetricsPortName,
+			ContainerPort: baseserver.BuiltinMetricsPort,
+			ServicePort:   baseserver.BuiltinMetricsPort,
+		},
+		{
+			Name:          IAMSessionPortName,
+			ContainerPort: IAMSessionPort,
+			ServicePort:   IAMSessionPort,
+		},
+	}
+	if ctx.Config.InstallationAdminExposure() != config.InstallationAdminDisabled {
+		ports = append(ports, common.ServicePort{
+			Name:          InstallationAdminName,
+			ContainerPort: InstallationAdminPort,
+			ServicePort:   InstallationAdminPort,
+		})
+	}
+	if common.DebugPortsEnabled(ctx, Component) {
+		ports = append(ports, common.ServicePort{
+			Name:          DebugPortName,
+			ContainerPort: baseserver.BuiltinDebugPort,
+			ServicePort:   baseserver.BuiltinDebugPort,
+		}, common.ServicePort{
+			Name:          DebugNodePortName,
+			ContainerPort: common.DebugNodePort,
+			ServicePort:   common.DebugNodePort,
+		})
+	}
+	ports = append(ports, common.ServicePort{
+		Name:          GRPCAPIName,
+		ContainerPort: GRPCAPIPort,
+		ServicePort:   GRPCAPIPort,
+	})
+
+	return common.GenerateService(Component, ports)(ctx)
+}
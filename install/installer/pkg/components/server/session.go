@@ -0,0 +1,51 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package server
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	configv1 "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+
+	"k8s.io/utils/pointer"
+)
+
+const sessionMaxAge = 3 * 24 * time.Hour
+
+func serverSession(ctx *common.RenderContext) *configv1.ServerSession {
+	if ctx.Config.Components == nil || ctx.Config.Components.Server == nil {
+		return nil
+	}
+	return ctx.Config.Components.Server.Session
+}
+
+// sessionConfig returns the session of the server config. The cookie is left to the server's
+// defaults unless it is configured.
+func sessionConfig(ctx *common.RenderContext, secret string) Session {
+	res := Session{
+		MaxAgeMs: int32(sessionMaxAge.Milliseconds()),
+		Secret:   secret,
+	}
+
+	session := serverSession(ctx)
+	if session == nil {
+		return res
+	}
+	if session.MaxAge != nil {
+		res.MaxAgeMs = int32(time.Duration(*session.MaxAge).Milliseconds())
+	}
+	if session.Cookie != nil {
+		res.Cookie = &SessionCookie{
+			SameSite: strings.ToLower(session.Cookie.SameSite),
+			Secure:   pointer.BoolDeref(session.Cookie.Secure, false),
+		}
+		if res.Cookie.SameSite == "" {
+			res.Cookie.SameSite = "lax"
+		}
+	}
+	return res
+}
@@ -24,6 +24,9 @@ type ConfigSerialized struct {
 	DisableDynamicAuthProviderLogin   bool     `json:"disableDynamicAuthProviderLogin"`
 	MaxEnvvarPerUserCount             int32    `json:"maxEnvvarPerUserCount"`
 	MaxConcurrentPrebuildsPerRef      int32    `json:"maxConcurrentPrebuildsPerRef"`
+	MaxConcurrentPrebuildsPerProject  int32    `json:"maxConcurrentPrebuildsPerProject,omitempty"`
+	MaxConcurrentPrebuildsPerTeam     int32    `json:"maxConcurrentPrebuildsPerTeam,omitempty"`
+	PrebuildWorkspaceClass            string   `json:"prebuildWorkspaceClass,omitempty"`
 	MakeNewUsersAdmin                 bool     `json:"makeNewUsersAdmin"`
 	DefaultBaseImageRegistryWhitelist []string `json:"defaultBaseImageRegistryWhitelist"`
 	RunDbDeleter                      bool     `json:"runDbDeleter"`
@@ -46,6 +49,7 @@ type ConfigSerialized struct {
 	WorkspaceGarbageCollection WorkspaceGarbageCollection `json:"workspaceGarbageCollection"`
 	CompleteSnapshotJob        JobConfig                  `json:"completeSnapshotJob"`
 	LongRunningMigrationsJob   JobConfig                  `json:"longRunningMigrationsJob"`
+	AuthProviderConfigs        []AuthProviderConfig       `json:"authProviderConfigs,omitempty"`
 	AuthProviderConfigFiles    []string                   `json:"authProviderConfigFiles"`
 	IncrementalPrebuilds       IncrementalPrebuilds       `json:"incrementalPrebuilds"`
 	BlockNewUsers              config.BlockNewUsers       `json:"blockNewUsers"`
@@ -59,6 +63,45 @@ type ConfigSerialized struct {
 	PrebuildLimiter                PrebuildRateLimiters `json:"prebuildLimiter"`
 	WorkspaceClasses               []WorkspaceClass     `json:"workspaceClasses"`
 	InactivityPeriodForReposInDays int                  `json:"inactivityPeriodForReposInDays"`
+	BlockedRepositories            []BlockedRepository  `json:"blockedRepositories,omitempty"`
+	BlockedDomains                 []string             `json:"blockedDomains,omitempty"`
+	WebsocketPingIntervalMs        int64                `json:"websocketPingIntervalMs,omitempty"`
+	WorkspacePorts                 *WorkspacePorts      `json:"workspacePorts,omitempty"`
+}
+
+// WorkspacePorts is the policy of the ports the workspaces expose
+type WorkspacePorts struct {
+	DefaultVisibility string      `json:"defaultVisibility,omitempty"`
+	DisablePublic     bool        `json:"disablePublic,omitempty"`
+	AllowedRanges     []PortRange `json:"allowedRanges,omitempty"`
+}
+
+type PortRange struct {
+	From int32 `json:"from"`
+	To   int32 `json:"to"`
+}
+
+// AuthProviderConfig interface from components/server/src/auth/auth-provider.ts
+type AuthProviderConfig struct {
+	ID    string            `json:"id"`
+	Host  string            `json:"host"`
+	Type  string            `json:"type"`
+	OAuth AuthProviderOAuth `json:"oauth"`
+}
+
+type AuthProviderOAuth struct {
+	ClientID         string `json:"clientId"`
+	ClientSecretFile string `json:"clientSecretFile"`
+	CallBackURL      string `json:"callBackUrl"`
+	AuthorizationURL string `json:"authorizationUrl,omitempty"`
+	TokenURL         string `json:"tokenUrl,omitempty"`
+	Scope            string `json:"scope,omitempty"`
+	ScopeSeparator   string `json:"scopeSeparator,omitempty"`
+}
+
+type BlockedRepository struct {
+	URLRegExp string `json:"urlRegExp"`
+	BlockUser bool   `json:"blockUser"`
 }
 type CodeSyncResources struct {
 	RevLimit int32 `json:"revLimit"`
@@ -113,20 +156,28 @@ type JobConfig struct {
 }
 
 type GitHubApp struct {
-	Enabled         bool   `json:"enabled"`
-	AppId           int32  `json:"appId"`
-	BaseUrl         string `json:"baseUrl"`
-	WebhookSecret   string `json:"webhookSecret"`
-	AuthProviderId  string `json:"authProviderId"`
-	CertPath        string `json:"certPath"`
-	MarketplaceName string `json:"marketplaceName"`
-	LogLevel        string `json:"logLevel"`
-	CertSecretName  string `json:"certSecretName"`
+	Enabled       bool   `json:"enabled"`
+	AppId         int32  `json:"appId"`
+	BaseUrl       string `json:"baseUrl"`
+	WebhookSecret string `json:"webhookSecret"`
+	// WebhookSecretFile takes precedence over WebhookSecret
+	WebhookSecretFile string `json:"webhookSecretFile,omitempty"`
+	AuthProviderId    string `json:"authProviderId"`
+	CertPath          string `json:"certPath"`
+	MarketplaceName   string `json:"marketplaceName"`
+	LogLevel          string `json:"logLevel"`
+	CertSecretName    string `json:"certSecretName"`
 }
 
 type Session struct {
-	MaxAgeMs int32  `json:"maxAgeMs"`
-	Secret   string `json:"secret"`
+	MaxAgeMs int32          `json:"maxAgeMs"`
+	Secret   string         `json:"secret"`
+	Cookie   *SessionCookie `json:"cookie,omitempty"`
+}
+
+type SessionCookie struct {
+	SameSite string `json:"sameSite"`
+	Secure   bool   `json:"secure"`
 }
 
 type WorkspaceHeartbeat struct {
@@ -140,6 +191,7 @@ type WorkspaceDefaults struct {
 	DefaultFeatureFlags []NamedWorkspaceFeatureFlag `json:"defaultFeatureFlags"`
 	TimeoutDefault      *util.Duration              `json:"timeoutDefault,omitempty"`
 	TimeoutExtended     *util.Duration              `json:"timeoutExtended,omitempty"`
+	DisableDotfiles     bool                        `json:"disableDotfiles,omitempty"`
 }
 
 type WorkspaceClass struct {
@@ -156,7 +208,8 @@ type WorkspaceClass struct {
 type NamedWorkspaceFeatureFlag string
 
 const (
-	NamedWorkspaceFeatureFlagFullWorkspaceBackup NamedWorkspaceFeatureFlag = "full_workspace_backup"
+	NamedWorkspaceFeatureFlagFullWorkspaceBackup   NamedWorkspaceFeatureFlag = "full_workspace_backup"
+	NamedWorkspaceFeatureFlagPersistentVolumeClaim NamedWorkspaceFeatureFlag = "persistent_volume_claim"
 )
 
 type WorkspaceClassCategory string
@@ -0,0 +1,14 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package smoketest
+
+import "time"
+
+const (
+	Component = "smoke-test"
+
+	defaultContextURL = "https://github.com/gitpod-io/empty"
+	defaultTimeout    = 5 * time.Minute
+)
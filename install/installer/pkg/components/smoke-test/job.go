@@ -0,0 +1,100 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package smoketest
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+)
+
+func job(ctx *common.RenderContext) ([]runtime.Object, error) {
+	cfg := getSmokeTestConfig(ctx)
+
+	contextURL := cfg.ContextURL
+	if contextURL == "" {
+		contextURL = defaultContextURL
+	}
+	timeout := defaultTimeout
+	if cfg.Timeout != nil {
+		timeout = time.Duration(*cfg.Timeout)
+	}
+
+	objectMeta := metav1.ObjectMeta{
+		Name:        Component,
+		Namespace:   ctx.Namespace,
+		Labels:      common.CustomizeLabel(ctx, Component, common.TypeMetaBatchJob),
+		Annotations: common.CustomizeAnnotation(ctx, Component, common.TypeMetaBatchJob),
+	}
+
+	// The test runs after every install and upgrade of the Helm chart, and the job of the last
+	// run is kept so its status and logs can be read
+	jobMeta := objectMeta
+	if ctx.Config.ApplyOrder == nil {
+		jobMeta.Annotations = common.CustomizeAnnotation(ctx, Component, common.TypeMetaBatchJob, func() map[string]string {
+			return map[string]string{
+				"helm.sh/hook":               "post-install,post-upgrade",
+				"helm.sh/hook-delete-policy": "before-hook-creation",
+			}
+		})
+	}
+
+	credential := func(name, key string) corev1.EnvVar {
+		return corev1.EnvVar{
+			Name: name,
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: cfg.Credentials.Name},
+				Key:                  key,
+			}},
+		}
+	}
+
+	return []runtime.Object{&batchv1.Job{
+		TypeMeta:   common.TypeMetaBatchJob,
+		ObjectMeta: jobMeta,
+		Spec: batchv1.JobSpec{
+			BackoffLimit: pointer.Int32(0),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: objectMeta,
+				Spec: corev1.PodSpec{
+					RestartPolicy:      corev1.RestartPolicyNever,
+					ServiceAccountName: Component,
+					EnableServiceLinks: pointer.Bool(false),
+					Containers: []corev1.Container{{
+						Name:            Component,
+						Image:           ctx.ImageName(ctx.Config.Repository, "integration-tests", ctx.VersionManifest.Components.IntegrationTests.Version),
+						ImagePullPolicy: corev1.PullIfNotPresent,
+						Resources:       common.ResourceRequirements(ctx, Component, Component, corev1.ResourceRequirements{}),
+						// The test reaches the components through port forwards of the Kubernetes API
+						Args: []string{
+							"-testPattern=smoke-test.test",
+							fmt.Sprintf("-namespace=%s", ctx.Namespace),
+							fmt.Sprintf("-wait-gitpod-timeout=%s", timeout),
+							"-test.run=TestStartRegularWorkspace",
+						},
+						Env: common.CustomizeEnvvar(ctx, Component, common.MergeEnv(
+							common.DefaultEnv(&ctx.Config),
+							[]corev1.EnvVar{
+								credential("USER_NAME", "username"),
+								credential("USER_TOKEN", "token"),
+								{Name: "SMOKE_TEST_CONTEXT_URL", Value: contextURL},
+							},
+						)),
+						SecurityContext: &corev1.SecurityContext{
+							AllowPrivilegeEscalation: pointer.Bool(false),
+						},
+					}},
+				},
+			},
+		},
+	}}, nil
+}
@@ -0,0 +1,35 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package smoketest
+
+import (
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func Objects(ctx *common.RenderContext) ([]runtime.Object, error) {
+	if getSmokeTestConfig(ctx) == nil {
+		return nil, nil
+	}
+
+	return common.CompositeRenderFunc(
+		job,
+		role,
+		rolebinding,
+		networkpolicy,
+		common.DefaultServiceAccount(Component),
+	)(ctx)
+}
+
+// getSmokeTestConfig returns the config of the smoke test if it is rendered. The test needs
+// both the server and the workspaces in the same cluster.
+func getSmokeTestConfig(ctx *common.RenderContext) *config.SmokeTestComponent {
+	if ctx.Config.Kind != config.InstallationFull || ctx.Config.Components == nil {
+		return nil
+	}
+	return ctx.Config.Components.SmokeTest
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package smoketest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+	"github.com/gitpod-io/gitpod/installer/pkg/config/versions"
+)
+
+func TestObjects(t *testing.T) {
+	var manifest versions.Manifest
+	manifest.Components.IntegrationTests.Version = "test"
+	render := func(kind config.InstallationKind, smokeTest *config.SmokeTestComponent) []*batchv1.Job {
+		ctx, err := common.NewRenderContext(config.Config{
+			Kind:       kind,
+			Components: &config.Components{SmokeTest: smokeTest},
+		}, manifest, "test_namespace")
+		require.NoError(t, err)
+
+		objs, err := Objects(ctx)
+		require.NoError(t, err)
+
+		var jobs []*batchv1.Job
+		for _, o := range objs {
+			if j, ok := o.(*batchv1.Job); ok {
+				jobs = append(jobs, j)
+			}
+		}
+		return jobs
+	}
+	smokeTest := &config.SmokeTestComponent{
+		Credentials: config.ObjectRef{Kind: config.ObjectRefSecret, Name: "smoke-test-user"},
+		ContextURL:  "https://git.example.com/gitpod/empty",
+	}
+
+	require.Empty(t, render(config.InstallationFull, nil))
+	require.Empty(t, render(config.InstallationMeta, smokeTest), "the test needs the workspaces in the same cluster")
+
+	jobs := render(config.InstallationFull, smokeTest)
+	require.Len(t, jobs, 1)
+	require.Equal(t, "post-install,post-upgrade", jobs[0].Annotations["helm.sh/hook"])
+
+	container := jobs[0].Spec.Template.Spec.Containers[0]
+	require.Equal(t, []string{
+		"-testPattern=smoke-test.test",
+		"-namespace=test_namespace",
+		"-wait-gitpod-timeout=5m0s",
+		"-test.run=TestStartRegularWorkspace",
+	}, container.Args)
+	require.Contains(t, container.Env, corev1.EnvVar{Name: "SMOKE_TEST_CONTEXT_URL", Value: "https://git.example.com/gitpod/empty"})
+	require.Contains(t, container.Env, corev1.EnvVar{
+		Name: "USER_TOKEN",
+		ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "smoke-test-user"},
+			Key:                  "token",
+		}},
+	})
+}
@@ -4,8 +4,10 @@
 
 package spicedb
 
+import "github.com/gitpod-io/gitpod/installer/pkg/common"
+
 const (
-	Component = "spicedb"
+	Component = common.SpiceDBComponent
 
 	ContainerHTTPPort = 8443
 	ContainerHTTPName = "http"
@@ -28,6 +30,9 @@ const (
 
 	ContainerName = "spicedb"
 
+	ZedRegistryImage = "authzed/zed"
+	ZedImageTag      = "v0.9.0"
+
 	CloudSQLProxyPort = 3306
 
 	SecretPresharedKeyName = "presharedKey"
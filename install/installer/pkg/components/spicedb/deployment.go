@@ -62,9 +62,10 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 						Annotations: common.CustomizeAnnotation(ctx, Component, common.TypeMetaDeployment),
 					},
 					Spec: corev1.PodSpec{
-						Affinity:                      cluster.WithNodeAffinityHostnameAntiAffinity(Component, cluster.AffinityLabelMeta),
+						Affinity:                      common.Affinity(ctx, Component, cluster.WithNodeAffinityHostnameAntiAffinity(Component, cluster.AffinityLabelMeta)),
+						Tolerations:                   common.Tolerations(ctx, Component, nil),
 						TopologySpreadConstraints:     cluster.WithHostnameTopologySpread(Component),
-						PriorityClassName:             common.SystemNodeCritical,
+						PriorityClassName:             common.PriorityClassName(ctx, Component, common.SystemNodeCritical),
 						ServiceAccountName:            Component,
 						EnableServiceLinks:            pointer.Bool(false),
 						DNSPolicy:                     corev1.DNSClusterFirst,
@@ -96,7 +97,7 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 									}
 
 									// Dispatching only makes sense, when we have more than one replica
-									if *replicas > 1 {
+									if pointer.Int32Deref(replicas, 1) > 1 {
 										args = append(args, fmt.Sprintf("--dispatch-upstream-addr=kubernetes:///spicedb:%d", ContainerDispatchPort))
 									}
 
@@ -0,0 +1,95 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package spicedb
+
+import (
+	"fmt"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+)
+
+// externalBootstrap writes the schema to the external SpiceDB, which the in-cluster one reads from
+// its bootstrap files when it starts
+func externalBootstrap(ctx *common.RenderContext) ([]runtime.Object, error) {
+	cfg := getExternalSpiceDBConfig(ctx)
+	if cfg == nil || !cfg.Bootstrap {
+		return nil, nil
+	}
+
+	bootstrapVolume, bootstrapVolumeMount, bootstrapFiles, err := getBootstrapConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bootstrap config: %w", err)
+	}
+	// Every import replaces the schema of the previous one
+	if len(bootstrapFiles) != 1 {
+		return nil, fmt.Errorf("expected the schema in one bootstrap file, found %d", len(bootstrapFiles))
+	}
+
+	args := []string{
+		"import",
+		"file://" + bootstrapFiles[0],
+		"--endpoint=" + cfg.Address,
+		"--token=$(SPICEDB_PRESHARED_KEY)",
+	}
+	if cfg.Insecure {
+		args = append(args, "--insecure")
+	}
+
+	name := fmt.Sprintf("%s-bootstrap", Component)
+	objectMeta := metav1.ObjectMeta{
+		Name:        name,
+		Namespace:   ctx.Namespace,
+		Labels:      common.CustomizeLabel(ctx, Component, common.TypeMetaBatchJob),
+		Annotations: common.CustomizeAnnotation(ctx, Component, common.TypeMetaBatchJob),
+	}
+
+	return []runtime.Object{
+		&batchv1.Job{
+			TypeMeta:   common.TypeMetaBatchJob,
+			ObjectMeta: objectMeta,
+			Spec: batchv1.JobSpec{
+				TTLSecondsAfterFinished: pointer.Int32(60),
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: objectMeta,
+					Spec: corev1.PodSpec{
+						RestartPolicy:      corev1.RestartPolicyOnFailure,
+						ServiceAccountName: Component,
+						EnableServiceLinks: pointer.Bool(false),
+						Containers: []corev1.Container{{
+							Name:            name,
+							Image:           ctx.ImageName(common.ThirdPartyContainerRepo(ctx.Config.Repository, RegistryRepo), ZedRegistryImage, ZedImageTag),
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							Resources:       common.ResourceRequirements(ctx, Component, name, corev1.ResourceRequirements{}),
+							Env: common.CustomizeEnvvar(ctx, Component, common.MergeEnv(
+								common.DefaultEnv(&ctx.Config),
+								[]corev1.EnvVar{{
+									Name: "SPICEDB_PRESHARED_KEY",
+									ValueFrom: &corev1.EnvVarSource{
+										SecretKeyRef: &corev1.SecretKeySelector{
+											LocalObjectReference: corev1.LocalObjectReference{Name: cfg.PresharedKey.Name},
+											Key:                  SecretPresharedKeyName,
+										},
+									},
+								}},
+							)),
+							SecurityContext: &corev1.SecurityContext{
+								AllowPrivilegeEscalation: pointer.Bool(false),
+							},
+							Args:         args,
+							VolumeMounts: []corev1.VolumeMount{bootstrapVolumeMount},
+						}},
+						Volumes: []corev1.Volume{bootstrapVolume},
+					},
+				},
+			},
+		},
+	}, nil
+}
@@ -53,6 +53,7 @@ func migrations(ctx *common.RenderContext) ([]runtime.Object, error) {
 							Name:            fmt.Sprintf("%s-migrations", Component),
 							Image:           ctx.ImageName(common.ThirdPartyContainerRepo(ctx.Config.Repository, RegistryRepo), RegistryImage, ImageTag),
 							ImagePullPolicy: corev1.PullIfNotPresent,
+							Resources:       common.ResourceRequirements(ctx, Component, fmt.Sprintf("%s-migrations", Component), corev1.ResourceRequirements{}),
 							Env: common.CustomizeEnvvar(ctx, Component, common.MergeEnv(
 								common.DefaultEnv(&ctx.Config),
 								spicedbEnvVars(ctx),
@@ -10,12 +10,24 @@ import (
 	"strconv"
 
 	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
 	"github.com/gitpod-io/gitpod/installer/pkg/config/v1/experimental"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
 func Objects(ctx *common.RenderContext) ([]runtime.Object, error) {
+	if external := getExternalSpiceDBConfig(ctx); external != nil {
+		if !external.Bootstrap {
+			return nil, nil
+		}
+		return common.CompositeRenderFunc(
+			common.DefaultServiceAccount(Component),
+			networkpolicy,
+			bootstrap,
+			externalBootstrap,
+		)(ctx)
+	}
 
 	spiceDBConfig := getExperimentalSpiceDBConfig(ctx)
 	if spiceDBConfig == nil {
@@ -44,7 +56,39 @@ func getExperimentalSpiceDBConfig(ctx *common.RenderContext) *experimental.Spice
 	return webappCfg.SpiceDB
 }
 
+// getExternalSpiceDBConfig returns the config of the SpiceDB that replaces the in-cluster one
+func getExternalSpiceDBConfig(ctx *common.RenderContext) *config.SpiceDBExternal {
+	if ctx.Config.Components == nil || ctx.Config.Components.SpiceDB == nil {
+		return nil
+	}
+	return ctx.Config.Components.SpiceDB.External
+}
+
 func Env(ctx *common.RenderContext) []corev1.EnvVar {
+	if external := getExternalSpiceDBConfig(ctx); external != nil {
+		return []corev1.EnvVar{
+			{
+				Name:  "SPICEDB_ADDRESS",
+				Value: external.Address,
+			},
+			{
+				Name: "SPICEDB_PRESHARED_KEY",
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{
+							Name: external.PresharedKey.Name,
+						},
+						Key: SecretPresharedKeyName,
+					},
+				},
+			},
+			{
+				Name:  "SPICEDB_TLS",
+				Value: strconv.FormatBool(!external.Insecure),
+			},
+		}
+	}
+
 	cfg := getExperimentalSpiceDBConfig(ctx)
 	if cfg == nil {
 		return nil
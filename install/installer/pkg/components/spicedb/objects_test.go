@@ -0,0 +1,58 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package spicedb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+	"github.com/gitpod-io/gitpod/installer/pkg/config/versions"
+)
+
+func TestObjects_External(t *testing.T) {
+	external := &config.SpiceDBExternal{
+		Address:      "grpc.authzed.example.com:443",
+		PresharedKey: config.ObjectRef{Kind: config.ObjectRefSecret, Name: "spicedb-external"},
+	}
+	ctx, err := common.NewRenderContext(config.Config{
+		Components: &config.Components{SpiceDB: &config.SpiceDBComponent{External: external}},
+	}, versions.Manifest{}, "test_namespace")
+	require.NoError(t, err)
+
+	objects, err := Objects(ctx)
+	require.NoError(t, err)
+	require.Empty(t, objects, "must not render the in-cluster SpiceDB")
+
+	env := make(map[string]corev1.EnvVar)
+	for _, e := range Env(ctx) {
+		env[e.Name] = e
+	}
+	require.Equal(t, "grpc.authzed.example.com:443", env["SPICEDB_ADDRESS"].Value)
+	require.Equal(t, "spicedb-external", env["SPICEDB_PRESHARED_KEY"].ValueFrom.SecretKeyRef.Name)
+	require.Equal(t, "true", env["SPICEDB_TLS"].Value)
+
+	external.Bootstrap = true
+	objects, err = Objects(ctx)
+	require.NoError(t, err)
+
+	var job *batchv1.Job
+	for _, o := range objects {
+		if j, ok := o.(*batchv1.Job); ok {
+			job = j
+		}
+	}
+	require.NotNil(t, job, "must render the bootstrap job")
+	require.Equal(t, []string{
+		"import",
+		"file:///bootstrap/schema.yaml",
+		"--endpoint=grpc.authzed.example.com:443",
+		"--token=$(SPICEDB_PRESHARED_KEY)",
+	}, job.Spec.Template.Spec.Containers[0].Args)
+}
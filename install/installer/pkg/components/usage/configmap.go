@@ -14,7 +14,6 @@ import (
 
 	db "github.com/gitpod-io/gitpod/components/gitpod-db/go"
 	"github.com/gitpod-io/gitpod/installer/pkg/common"
-	"github.com/gitpod-io/gitpod/installer/pkg/config/v1/experimental"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -30,6 +29,7 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 					Address: fmt.Sprintf("0.0.0.0:%d", gRPCContainerPort),
 				},
 			},
+			Builtin: common.BaseserverBuiltin(ctx),
 		},
 		DefaultSpendingLimit: db.DefaultSpendingLimit{
 			// because we only want spending limits in SaaS, if not configured we go with a very high (i.e. no) spending limit
@@ -65,6 +65,22 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 		}
 	}
 
+	if usageConfig := getUsageConfig(ctx); usageConfig != nil {
+		if usageConfig.LedgerSchedule != nil {
+			cfg.LedgerSchedule = usageConfig.LedgerSchedule.String()
+		}
+		if usageConfig.ResetUsageSchedule != nil {
+			cfg.ResetUsageSchedule = usageConfig.ResetUsageSchedule.String()
+		}
+		if limit := usageConfig.DefaultSpendingLimit; limit != nil {
+			cfg.DefaultSpendingLimit = db.DefaultSpendingLimit{
+				ForTeams:            limit.ForTeams,
+				ForUsers:            limit.ForUsers,
+				MinForUsersOnStripe: limit.MinForUsersOnStripe,
+			}
+		}
+	}
+
 	workspaceClassConfig := getExperimentalWorkspaceClassConfig(ctx)
 
 	cfg.CreditsPerMinuteByWorkspaceClass = make(map[string]float64)
@@ -74,15 +90,9 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 		}
 	}
 
-	_ = ctx.WithExperimental(func(ucfg *experimental.Config) error {
-		_, _, path, ok := getStripeConfig(ucfg)
-		if !ok {
-			return nil
-		}
-
+	if _, _, path, ok := getStripeConfig(ctx); ok {
 		cfg.StripeCredentialsFile = path
-		return nil
-	})
+	}
 
 	serialized, err := common.ToJSONString(cfg)
 	if err != nil {
@@ -5,9 +5,15 @@
 package usage
 
 import (
+	"encoding/json"
 	"testing"
+	"time"
 
+	"github.com/gitpod-io/gitpod/common-go/util"
+	db "github.com/gitpod-io/gitpod/components/gitpod-db/go"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
 	"github.com/gitpod-io/gitpod/installer/pkg/config/v1/experimental"
+	"github.com/gitpod-io/gitpod/usage/pkg/server"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 )
@@ -52,3 +58,29 @@ func TestConfigMap_ContainsSchedule(t *testing.T) {
 		cfgmap.Data[configJSONFilename],
 	)
 }
+
+func TestConfigMap_UsageConfig(t *testing.T) {
+	ctx := renderContextWithUsageConfig(t, nil)
+	ledger, reset := util.Duration(10*time.Minute), util.Duration(time.Hour)
+	ctx.Config.Components = &config.Components{Usage: &config.UsageComponent{
+		Enabled:              true,
+		LedgerSchedule:       &ledger,
+		ResetUsageSchedule:   &reset,
+		StripeCredentials:    &config.ObjectRef{Kind: config.ObjectRefSecret, Name: "stripe"},
+		DefaultSpendingLimit: &config.UsageSpendingLimit{ForTeams: 500, ForUsers: 100, MinForUsersOnStripe: 1000},
+	}}
+
+	objs, err := configmap(ctx)
+	require.NoError(t, err)
+
+	var cfg server.Config
+	require.NoError(t, json.Unmarshal([]byte(objs[0].(*corev1.ConfigMap).Data[configJSONFilename]), &cfg))
+	require.Equal(t, "10m0s", cfg.LedgerSchedule)
+	require.Equal(t, "1h0m0s", cfg.ResetUsageSchedule)
+	require.Equal(t, db.DefaultSpendingLimit{ForTeams: 500, ForUsers: 100, MinForUsersOnStripe: 1000}, cfg.DefaultSpendingLimit)
+	require.Equal(t, "stripe-secret/apikeys", cfg.StripeCredentialsFile)
+
+	volume, _, _, ok := getStripeConfig(ctx)
+	require.True(t, ok)
+	require.Equal(t, "stripe", volume.Secret.SecretName, "the secret of the usage config takes precedence")
+}
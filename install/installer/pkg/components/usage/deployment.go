@@ -10,7 +10,6 @@ import (
 	"github.com/gitpod-io/gitpod/common-go/baseserver"
 	"github.com/gitpod-io/gitpod/installer/pkg/cluster"
 	"github.com/gitpod-io/gitpod/installer/pkg/common"
-	"github.com/gitpod-io/gitpod/installer/pkg/config/v1/experimental"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -44,6 +43,7 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 				},
 			},
 		},
+		common.CAVolume(),
 	}
 	volumeMounts := []corev1.VolumeMount{
 		{
@@ -52,23 +52,21 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 			MountPath: usageConfigMountPath,
 			SubPath:   configJSONFilename,
 		},
+		common.CAVolumeMount(),
 	}
-	_ = ctx.WithExperimental(func(cfg *experimental.Config) error {
-		volume, mount, _, ok := getStripeConfig(cfg)
-		if !ok {
-			return nil
-		}
-
+	if volume, mount, _, ok := getStripeConfig(ctx); ok {
 		volumes = append(volumes, volume)
 		volumeMounts = append(volumeMounts, mount)
-		return nil
-	})
+	}
 
 	configHash, err := common.ObjectHash(configmap(ctx))
 	if err != nil {
 		return nil, err
 	}
 
+	volumes = append(volumes, common.MetricsTLSVolumes(ctx, Component)...)
+	volumeMounts = append(volumeMounts, common.MetricsTLSVolumeMounts(ctx)...)
+
 	return []runtime.Object{
 		&appsv1.Deployment{
 			TypeMeta: common.TypeMetaDeployment,
@@ -94,14 +92,16 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 						Annotations: common.CustomizeAnnotation(ctx, Component, common.TypeMetaDeployment),
 					},
 					Spec: corev1.PodSpec{
-						Affinity:                      cluster.WithNodeAffinityHostnameAntiAffinity(Component, cluster.AffinityLabelMeta),
+						Affinity:                      common.Affinity(ctx, Component, cluster.WithNodeAffinityHostnameAntiAffinity(Component, cluster.AffinityLabelMeta)),
+						PriorityClassName:             common.PriorityClassName(ctx, Component, ""),
+						Tolerations:                   common.Tolerations(ctx, Component, nil),
 						TopologySpreadConstraints:     cluster.WithHostnameTopologySpread(Component),
 						ServiceAccountName:            Component,
 						EnableServiceLinks:            pointer.Bool(false),
 						DNSPolicy:                     corev1.DNSClusterFirst,
 						RestartPolicy:                 corev1.RestartPolicyAlways,
 						TerminationGracePeriodSeconds: pointer.Int64(30),
-						InitContainers:                []corev1.Container{*common.DatabaseWaiterContainer(ctx)},
+						InitContainers:                common.DatabaseWaiterContainers(ctx),
 						Volumes:                       volumes,
 						Containers: []corev1.Container{{
 							Name:            Component,
@@ -123,6 +123,7 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 								common.DefaultEnv(&ctx.Config),
 								common.DatabaseEnv(&ctx.Config),
 								common.ConfigcatEnv(ctx),
+								common.WebappTracingEnv(ctx, Component),
 							)),
 							VolumeMounts: volumeMounts,
 							LivenessProbe: &corev1.Probe{
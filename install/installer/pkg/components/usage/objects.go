@@ -7,17 +7,20 @@ package usage
 import (
 	"github.com/gitpod-io/gitpod/common-go/log"
 	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
 	"github.com/gitpod-io/gitpod/installer/pkg/config/v1/experimental"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
 func Objects(ctx *common.RenderContext) ([]runtime.Object, error) {
-	cfg := getExperimentalUsageConfig(ctx)
-	if cfg == nil {
-		return nil, nil
+	if getUsageConfig(ctx) == nil {
+		cfg := getExperimentalUsageConfig(ctx)
+		if cfg == nil {
+			return nil, nil
+		}
+		log.Debug("Detected experimental.WebApp.Usage configuration", cfg)
 	}
 
-	log.Debug("Detected experimental.WebApp.Usage configuration", cfg)
 	return common.CompositeRenderFunc(
 		deployment,
 		rolebinding,
@@ -28,6 +31,15 @@ func Objects(ctx *common.RenderContext) ([]runtime.Object, error) {
 	)(ctx)
 }
 
+// getUsageConfig returns the config of the usage component if it is enabled
+func getUsageConfig(ctx *common.RenderContext) *config.UsageComponent {
+	if ctx.Config.Components == nil || ctx.Config.Components.Usage == nil || !ctx.Config.Components.Usage.Enabled {
+		return nil
+	}
+
+	return ctx.Config.Components.Usage
+}
+
 func getExperimentalUsageConfig(ctx *common.RenderContext) *experimental.UsageConfig {
 	experimentalWebAppCfg := common.ExperimentalWebappConfig(ctx)
 	if experimentalWebAppCfg == nil || experimentalWebAppCfg.Usage == nil {
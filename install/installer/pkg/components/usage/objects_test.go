@@ -33,6 +33,15 @@ func TestObjects_RenderedWhenExperimentalConfigSet(t *testing.T) {
 	require.Len(t, objects, 7, "should render expected k8s objects")
 }
 
+func TestObjects_RenderedWhenEnabled(t *testing.T) {
+	ctx := renderContextWithUsageConfig(t, nil)
+	ctx.Config.Components = &config.Components{Usage: &config.UsageComponent{Enabled: true}}
+
+	objects, err := Objects(ctx)
+	require.NoError(t, err)
+	require.Len(t, objects, 7, "must render objects because the usage component is enabled")
+}
+
 func renderContextWithUsageConfig(t *testing.T, usage *experimental.UsageConfig) *common.RenderContext {
 	ctx, err := common.NewRenderContext(config.Config{
 		Domain: "test.domain.everything.awesome.is",
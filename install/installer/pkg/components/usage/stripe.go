@@ -5,22 +5,34 @@
 package usage
 
 import (
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
 	"github.com/gitpod-io/gitpod/installer/pkg/config/v1/experimental"
 	corev1 "k8s.io/api/core/v1"
 	"path/filepath"
 )
 
-func getStripeConfig(cfg *experimental.Config) (corev1.Volume, corev1.VolumeMount, string, bool) {
+// getStripeConfig returns the volume of the Stripe secret and where its keys are mounted. The
+// secret of the usage config takes precedence over the experimental one of the server.
+func getStripeConfig(ctx *common.RenderContext) (corev1.Volume, corev1.VolumeMount, string, bool) {
 	var volume corev1.Volume
 	var mount corev1.VolumeMount
 	var path string
 
-	if cfg == nil || cfg.WebApp == nil || cfg.WebApp.Server == nil || cfg.WebApp.Server.StripeSecret == "" {
+	var stripeSecret string
+	if cfg := getUsageConfig(ctx); cfg != nil && cfg.StripeCredentials != nil {
+		stripeSecret = cfg.StripeCredentials.Name
+	} else {
+		_ = ctx.WithExperimental(func(cfg *experimental.Config) error {
+			if cfg.WebApp != nil && cfg.WebApp.Server != nil {
+				stripeSecret = cfg.WebApp.Server.StripeSecret
+			}
+			return nil
+		})
+	}
+	if stripeSecret == "" {
 		return volume, mount, path, false
 	}
 
-	stripeSecret := cfg.WebApp.Server.StripeSecret
-
 	volume = corev1.Volume{
 		Name: "stripe-secret",
 		VolumeSource: corev1.VolumeSource{
@@ -16,4 +16,5 @@ const (
 	SupervisorDebugPort          = 24999
 	IDEDebugPort                 = 25000
 	DebugWorkspaceProxyPort      = 25003
+	metadataEndpointCIDR         = "169.254.169.254/32"
 )
@@ -6,15 +6,19 @@ package workspace
 
 import (
 	"fmt"
+	"net"
 
 	"github.com/gitpod-io/gitpod/installer/pkg/common"
 	agentsmith "github.com/gitpod-io/gitpod/installer/pkg/components/agent-smith"
 	"github.com/gitpod-io/gitpod/installer/pkg/components/proxy"
 	wsdaemon "github.com/gitpod-io/gitpod/installer/pkg/components/ws-daemon"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
 )
 
 func networkpolicy(ctx *common.RenderContext) ([]runtime.Object, error) {
@@ -26,6 +30,14 @@ func networkpolicy(ctx *common.RenderContext) ([]runtime.Object, error) {
 		"gitpod.io/networkpolicy": "default",
 	}
 
+	egress, err := egressRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	egress = append(egress, nameserverEgressRules(ctx)...)
+	egress = append(egress, scmEgressRules(ctx)...)
+	egress = append(egress, common.AllowNodeLocalDNSEgressRules(ctx)...)
+
 	return []runtime.Object{&networkingv1.NetworkPolicy{
 		TypeMeta: common.TypeMetaNetworkPolicy,
 		ObjectMeta: metav1.ObjectMeta{
@@ -53,16 +65,12 @@ func networkpolicy(ctx *common.RenderContext) ([]runtime.Object, error) {
 				},
 				{
 					From: []networkingv1.NetworkPolicyPeer{
-						{
-							PodSelector: &metav1.LabelSelector{MatchLabels: common.DefaultLabels(agentsmith.Component)},
-						},
+						common.NamespacePodPeer(ctx.Namespace, common.InfrastructureNamespace(ctx), common.DefaultLabels(agentsmith.Component)),
 					},
 				},
 				{
 					From: []networkingv1.NetworkPolicyPeer{
-						{
-							PodSelector: &metav1.LabelSelector{MatchLabels: common.DefaultLabels(wsdaemon.Component)},
-						},
+						common.NamespacePodPeer(ctx.Namespace, common.InfrastructureNamespace(ctx), common.DefaultLabels(wsdaemon.Component)),
 					},
 				},
 				{
@@ -82,19 +90,8 @@ func networkpolicy(ctx *common.RenderContext) ([]runtime.Object, error) {
 					},
 				},
 			},
-			Egress: []networkingv1.NetworkPolicyEgressRule{
-				{
-					To: []networkingv1.NetworkPolicyPeer{
-						{
-							IPBlock: &networkingv1.IPBlock{
-								CIDR: "0.0.0.0/0",
-								// Google Compute engine special, reserved VM metadata IP
-								Except: []string{"169.254.169.254/32"},
-							},
-						},
-					},
-				},
-				{
+			Egress: append(egress,
+				networkingv1.NetworkPolicyEgressRule{
 					To: []networkingv1.NetworkPolicyPeer{
 						{
 							PodSelector: &metav1.LabelSelector{MatchLabels: common.DefaultLabels(proxy.Component)},
@@ -102,7 +99,136 @@ func networkpolicy(ctx *common.RenderContext) ([]runtime.Object, error) {
 					},
 				},
 				common.AllowKubeDnsEgressRule(),
-			},
+			),
 		},
 	}}, nil
 }
+
+// egressRules returns the rules that allow the workspaces to connect to the addresses outside
+// the cluster. The denied CIDRs are excluded from the allowed CIDRs that contain them.
+func egressRules(ctx *common.RenderContext) ([]networkingv1.NetworkPolicyEgressRule, error) {
+	cfg := ctx.Config.Workspace.Egress
+	if cfg == nil {
+		cfg = &config.WorkspaceEgress{}
+	}
+
+	var deny []string
+	if pointer.BoolDeref(cfg.BlockMetadataEndpoint, true) {
+		// the reserved VM metadata IP of the cloud providers
+		deny = append(deny, metadataEndpointCIDR)
+	}
+	deny = append(deny, cfg.Deny...)
+
+	allow := cfg.Allow
+	if len(allow) == 0 {
+		allow = []config.EgressRule{{CIDR: "0.0.0.0/0"}}
+	}
+
+	var res []networkingv1.NetworkPolicyEgressRule
+	for _, rule := range allow {
+		block := &networkingv1.IPBlock{CIDR: rule.CIDR}
+		denied := false
+		for _, d := range deny {
+			if contains, err := cidrContains(d, rule.CIDR); err != nil {
+				return nil, err
+			} else if contains {
+				denied = true
+				break
+			}
+			if contains, err := cidrContains(rule.CIDR, d); err != nil {
+				return nil, err
+			} else if contains {
+				block.Except = append(block.Except, d)
+			}
+		}
+		if denied {
+			continue
+		}
+
+		egress := networkingv1.NetworkPolicyEgressRule{
+			To: []networkingv1.NetworkPolicyPeer{{IPBlock: block}},
+		}
+		for _, p := range rule.Ports {
+			protocol := corev1.ProtocolTCP
+			if p.Protocol != "" {
+				protocol = p.Protocol
+			}
+			egress.Ports = append(egress.Ports, networkingv1.NetworkPolicyPort{
+				Protocol: &protocol,
+				Port:     &intstr.IntOrString{IntVal: p.Port},
+				EndPort:  p.EndPort,
+			})
+		}
+		res = append(res, egress)
+	}
+
+	return res, nil
+}
+
+// scmEgressRules allows the workspaces to reach the self-hosted Git hosts on their HTTPS and SSH
+// ports, regardless of the egress restrictions
+func scmEgressRules(ctx *common.RenderContext) []networkingv1.NetworkPolicyEgressRule {
+	var res []networkingv1.NetworkPolicyEgressRule
+	for _, scm := range ctx.Config.SCMHosts {
+		https, ssh := scm.HTTPSPort, scm.SSHPort
+		if https == 0 {
+			https = 443
+		}
+		if ssh == 0 {
+			ssh = 22
+		}
+
+		rule := networkingv1.NetworkPolicyEgressRule{
+			Ports: []networkingv1.NetworkPolicyPort{
+				{Protocol: common.TCPProtocol, Port: &intstr.IntOrString{IntVal: https}},
+				{Protocol: common.TCPProtocol, Port: &intstr.IntOrString{IntVal: ssh}},
+			},
+		}
+		for _, cidr := range scm.CIDRs {
+			rule.To = append(rule.To, networkingv1.NetworkPolicyPeer{IPBlock: &networkingv1.IPBlock{CIDR: cidr}})
+		}
+		res = append(res, rule)
+	}
+	return res
+}
+
+// nameserverEgressRules allows the workspaces to reach the nameservers of their DNS config,
+// regardless of the egress restrictions
+func nameserverEgressRules(ctx *common.RenderContext) []networkingv1.NetworkPolicyEgressRule {
+	dns := ctx.Config.Workspace.DNS
+	if dns == nil || len(dns.Nameservers) == 0 {
+		return nil
+	}
+
+	tcp, udp := corev1.ProtocolTCP, corev1.ProtocolUDP
+	rule := networkingv1.NetworkPolicyEgressRule{
+		Ports: []networkingv1.NetworkPolicyPort{
+			{Protocol: &tcp, Port: &intstr.IntOrString{IntVal: 53}},
+			{Protocol: &udp, Port: &intstr.IntOrString{IntVal: 53}},
+		},
+	}
+	for _, ns := range dns.Nameservers {
+		cidr := ns + "/32"
+		if net.ParseIP(ns).To4() == nil {
+			cidr = ns + "/128"
+		}
+		rule.To = append(rule.To, networkingv1.NetworkPolicyPeer{IPBlock: &networkingv1.IPBlock{CIDR: cidr}})
+	}
+	return []networkingv1.NetworkPolicyEgressRule{rule}
+}
+
+// cidrContains returns whether the first CIDR contains all the addresses of the second one
+func cidrContains(cidr, other string) (bool, error) {
+	_, n, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return false, err
+	}
+	_, o, err := net.ParseCIDR(other)
+	if err != nil {
+		return false, err
+	}
+
+	ones, bits := n.Mask.Size()
+	otherOnes, otherBits := o.Mask.Size()
+	return bits == otherBits && ones <= otherOnes && n.Contains(o.IP), nil
+}
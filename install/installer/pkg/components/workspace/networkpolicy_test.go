@@ -0,0 +1,137 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package workspace
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+	"github.com/gitpod-io/gitpod/installer/pkg/config/versions"
+)
+
+func TestNetworkPolicyEgress(t *testing.T) {
+	tcp, udp := corev1.ProtocolTCP, corev1.ProtocolUDP
+	ipBlock := func(cidr string, except ...string) []networkingv1.NetworkPolicyPeer {
+		return []networkingv1.NetworkPolicyPeer{{IPBlock: &networkingv1.IPBlock{CIDR: cidr, Except: except}}}
+	}
+
+	tests := []struct {
+		Name     string
+		Egress   *config.WorkspaceEgress
+		Expected []networkingv1.NetworkPolicyEgressRule
+	}{
+		{
+			Name:     "default",
+			Expected: []networkingv1.NetworkPolicyEgressRule{{To: ipBlock("0.0.0.0/0", "169.254.169.254/32")}},
+		},
+		{
+			Name:     "metadata endpoint allowed",
+			Egress:   &config.WorkspaceEgress{BlockMetadataEndpoint: pointer.Bool(false)},
+			Expected: []networkingv1.NetworkPolicyEgressRule{{To: ipBlock("0.0.0.0/0")}},
+		},
+		{
+			Name:     "deny list",
+			Egress:   &config.WorkspaceEgress{Deny: []string{"10.0.0.0/8", "fd00::/8"}},
+			Expected: []networkingv1.NetworkPolicyEgressRule{{To: ipBlock("0.0.0.0/0", "169.254.169.254/32", "10.0.0.0/8")}},
+		},
+		{
+			Name: "allow list",
+			Egress: &config.WorkspaceEgress{
+				Allow: []config.EgressRule{
+					{CIDR: "10.0.0.0/8", Ports: []config.EgressPort{{Port: 443}, {Port: 8000, EndPort: pointer.Int32(8100), Protocol: udp}}},
+					{CIDR: "169.254.0.0/16"},
+					{CIDR: "10.1.0.0/16"},
+				},
+				Deny: []string{"10.1.0.0/16"},
+			},
+			Expected: []networkingv1.NetworkPolicyEgressRule{
+				{
+					To: ipBlock("10.0.0.0/8", "10.1.0.0/16"),
+					Ports: []networkingv1.NetworkPolicyPort{
+						{Protocol: &tcp, Port: &intstr.IntOrString{IntVal: 443}},
+						{Protocol: &udp, Port: &intstr.IntOrString{IntVal: 8000}, EndPort: pointer.Int32(8100)},
+					},
+				},
+				{To: ipBlock("169.254.0.0/16", "169.254.169.254/32")},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			ctx, err := common.NewRenderContext(config.Config{Workspace: config.Workspace{Egress: test.Egress}}, versions.Manifest{}, "test_namespace")
+			require.NoError(t, err)
+
+			objs, err := networkpolicy(ctx)
+			require.NoError(t, err)
+
+			egress := objs[0].(*networkingv1.NetworkPolicy).Spec.Egress
+			// the workspaces can always reach the proxy and DNS
+			require.Len(t, egress, len(test.Expected)+2)
+			require.Equal(t, test.Expected, egress[:len(test.Expected)])
+		})
+	}
+}
+
+func TestNetworkPolicyNameservers(t *testing.T) {
+	ctx, err := common.NewRenderContext(config.Config{Workspace: config.Workspace{
+		Egress: &config.WorkspaceEgress{Allow: []config.EgressRule{{CIDR: "0.0.0.0/0", Ports: []config.EgressPort{{Port: 443}}}}},
+		DNS:    &config.WorkspaceDNS{Nameservers: []string{"10.10.0.53", "fd00::53"}},
+	}}, versions.Manifest{}, "test_namespace")
+	require.NoError(t, err)
+
+	objs, err := networkpolicy(ctx)
+	require.NoError(t, err)
+
+	egress := objs[0].(*networkingv1.NetworkPolicy).Spec.Egress
+	require.Len(t, egress, 4)
+	require.Equal(t, []networkingv1.NetworkPolicyPeer{
+		{IPBlock: &networkingv1.IPBlock{CIDR: "10.10.0.53/32"}},
+		{IPBlock: &networkingv1.IPBlock{CIDR: "fd00::53/128"}},
+	}, egress[1].To)
+	require.Len(t, egress[1].Ports, 2)
+}
+
+func TestNetworkPolicyNodeLocalDNSCache(t *testing.T) {
+	ctx, err := common.NewRenderContext(config.Config{
+		Network: &config.Network{NodeLocalDNSCache: &config.NodeLocalDNSCache{Enabled: true, Address: "169.254.25.10"}},
+	}, versions.Manifest{}, "test_namespace")
+	require.NoError(t, err)
+
+	objs, err := networkpolicy(ctx)
+	require.NoError(t, err)
+
+	egress := objs[0].(*networkingv1.NetworkPolicy).Spec.Egress
+	require.Len(t, egress, 4)
+	require.Equal(t, []networkingv1.NetworkPolicyPeer{{IPBlock: &networkingv1.IPBlock{CIDR: "169.254.25.10/32"}}}, egress[1].To)
+}
+
+func TestNetworkPolicySCMHosts(t *testing.T) {
+	ctx, err := common.NewRenderContext(config.Config{
+		Workspace: config.Workspace{
+			Egress: &config.WorkspaceEgress{Deny: []string{"10.0.0.0/8"}},
+		},
+		SCMHosts: []config.SCMHost{{Host: "gitlab.corp.example.com", HTTPSPort: 8443, CIDRs: []string{"10.20.0.10/32"}}},
+	}, versions.Manifest{}, "test_namespace")
+	require.NoError(t, err)
+
+	objs, err := networkpolicy(ctx)
+	require.NoError(t, err)
+
+	egress := objs[0].(*networkingv1.NetworkPolicy).Spec.Egress
+	require.Len(t, egress, 4)
+	require.Equal(t, []networkingv1.NetworkPolicyPeer{{IPBlock: &networkingv1.IPBlock{CIDR: "10.20.0.10/32"}}}, egress[1].To)
+	require.Equal(t, []networkingv1.NetworkPolicyPort{
+		{Protocol: common.TCPProtocol, Port: &intstr.IntOrString{IntVal: 8443}},
+		{Protocol: common.TCPProtocol, Port: &intstr.IntOrString{IntVal: 22}},
+	}, egress[1].Ports)
+}
@@ -8,7 +8,6 @@ import (
 	"fmt"
 
 	"github.com/gitpod-io/gitpod/installer/pkg/common"
-	"github.com/gitpod-io/gitpod/installer/pkg/config/v1/experimental"
 
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -18,21 +17,7 @@ import (
 func clusterrole(ctx *common.RenderContext) ([]runtime.Object, error) {
 	labels := common.DefaultLabels(Component)
 
-	var rules []rbacv1.PolicyRule
-
-	_ = ctx.WithExperimental(func(cfg *experimental.Config) error {
-		if cfg.Common != nil && cfg.Common.UsePodSecurityPolicies {
-			rules = append(rules, rbacv1.PolicyRule{
-				APIGroups: []string{"policy"},
-				Resources: []string{"podsecuritypolicies"},
-				Verbs:     []string{"use"},
-				ResourceNames: []string{
-					fmt.Sprintf("%s-ns-privileged-unconfined", ctx.Namespace),
-				},
-			})
-		}
-		return nil
-	})
+	rules := common.PodSecurityPolicyRules(ctx, fmt.Sprintf("%s-ns-privileged-unconfined", ctx.Namespace))
 
 	return []runtime.Object{
 		&rbacv1.ClusterRole{
@@ -23,6 +23,7 @@ import (
 	"github.com/gitpod-io/gitpod/ws-daemon/pkg/diskguard"
 	"github.com/gitpod-io/gitpod/ws-daemon/pkg/iws"
 	"github.com/gitpod-io/gitpod/ws-daemon/pkg/netlimit"
+	"github.com/gitpod-io/gitpod/ws-daemon/pkg/quota"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -40,6 +41,14 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 		return nil, fmt.Errorf("unknown fs shift method: %s", ctx.Config.Workspace.Runtime.FSShiftMethod)
 	}
 
+	var storageQuota content.StorageQuotaConfig
+	if q := ctx.Config.Workspace.StorageQuota; q != nil {
+		storageQuota.Backend = content.QuotaBackend(q.Backend)
+		if q.DefaultSize != nil {
+			storageQuota.DefaultSize = quota.Size(q.DefaultSize.Value())
+		}
+	}
+
 	cpuLimitConfig := cpulimit.Config{
 		Enabled:        false,
 		CGroupBasePath: "/mnt/node-cgroups",
@@ -104,10 +113,6 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 
 		procLimit = ucfg.Workspace.ProcLimit
 
-		wscontroller.Enabled = ucfg.Workspace.UseWsmanagerMk2
-		wscontroller.WorkingAreaSuffix = "-mk2"
-		wscontroller.MaxConcurrentReconciles = 15
-
 		if ucfg.Workspace.WorkspaceCIDR != "" {
 			workspaceCIDR = ucfg.Workspace.WorkspaceCIDR
 		}
@@ -115,6 +120,24 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 		return nil
 	})
 
+	if common.UseWsManagerMk2(ctx) {
+		wscontroller.Enabled = true
+		wscontroller.WorkingAreaSuffix = "-mk2"
+		wscontroller.MaxConcurrentReconciles = 15
+	}
+
+	if wsd := componentConfig(ctx); wsd != nil {
+		if wsd.CPULimits != nil {
+			applyCPULimits(&cpuLimitConfig, wsd.CPULimits)
+		}
+		if wsd.IOLimits != nil {
+			applyIOLimits(&ioLimitConfig, wsd.IOLimits)
+		}
+		if wsd.ProcLimit != nil {
+			procLimit = *wsd.ProcLimit
+		}
+	}
+
 	wsdcfg := wsdconfig.Config{
 		Daemon: daemon.Config{
 			Runtime: daemon.RuntimeConfig{
@@ -147,6 +170,7 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 				Initializer: content.InitializerConfig{
 					Command: "/app/content-initializer",
 				},
+				StorageQuota: storageQuota,
 			},
 			Uidmapper: iws.UidmapperConfig{
 				ProcLocation: "/proc",
@@ -201,3 +225,48 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 		},
 	}}, nil
 }
+
+// componentConfig is the ws-daemon section of the config, nil if there is none
+func componentConfig(ctx *common.RenderContext) *config.WSDaemonComponent {
+	if ctx.Config.Components == nil {
+		return nil
+	}
+	return ctx.Config.Components.WSDaemon
+}
+
+func applyCPULimits(res *cpulimit.Config, cfg *config.WSDaemonCPULimits) {
+	res.Enabled = cfg.Enabled
+	if cfg.NodeBandwidth != nil {
+		res.TotalBandwidth = *cfg.NodeBandwidth
+	}
+	if cfg.Limit != nil {
+		res.Limit = *cfg.Limit
+	}
+	if cfg.BurstLimit != nil {
+		res.BurstLimit = *cfg.BurstLimit
+	}
+	if cfg.ControlPeriod != nil {
+		res.ControlPeriod = *cfg.ControlPeriod
+	}
+	for _, b := range cfg.Buckets {
+		res.Buckets = append(res.Buckets, cpulimit.Bucket{
+			Budget: cpulimit.CPUTime(b.Budget),
+			Limit:  cpulimit.BandwidthFromQuantity(b.Limit),
+		})
+	}
+}
+
+func applyIOLimits(res *daemon.IOLimitConfig, cfg *config.WSDaemonIOLimits) {
+	if cfg.WriteBandwidthPerSecond != nil {
+		res.WriteBWPerSecond = *cfg.WriteBandwidthPerSecond
+	}
+	if cfg.ReadBandwidthPerSecond != nil {
+		res.ReadBWPerSecond = *cfg.ReadBandwidthPerSecond
+	}
+	if cfg.WriteIOPS != nil {
+		res.WriteIOPS = *cfg.WriteIOPS
+	}
+	if cfg.ReadIOPS != nil {
+		res.ReadIOPS = *cfg.ReadIOPS
+	}
+}
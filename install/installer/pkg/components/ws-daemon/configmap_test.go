@@ -0,0 +1,94 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package wsdaemon
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/pointer"
+
+	"github.com/gitpod-io/gitpod/common-go/util"
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+	"github.com/gitpod-io/gitpod/installer/pkg/config/v1/experimental"
+	"github.com/gitpod-io/gitpod/installer/pkg/config/versions"
+	wsdconfig "github.com/gitpod-io/gitpod/ws-daemon/pkg/config"
+	"github.com/gitpod-io/gitpod/ws-daemon/pkg/content"
+	"github.com/gitpod-io/gitpod/ws-daemon/pkg/cpulimit"
+	"github.com/gitpod-io/gitpod/ws-daemon/pkg/quota"
+)
+
+func TestConfigMap_Limits(t *testing.T) {
+	limit := resource.MustParse("2")
+	ctx, err := common.NewRenderContext(config.Config{
+		Workspace: config.Workspace{
+			Runtime: config.WorkspaceRuntime{FSShiftMethod: config.FSShiftShiftFS},
+		},
+		ObjectStorage: config.ObjectStorage{InCluster: pointer.Bool(true)},
+		Components: &config.Components{
+			WSDaemon: &config.WSDaemonComponent{
+				CPULimits: &config.WSDaemonCPULimits{
+					Enabled:       true,
+					NodeBandwidth: resource.NewQuantity(60, resource.DecimalSI),
+					BurstLimit:    &limit,
+					Buckets: []config.WSDaemonCPUBucket{
+						{Budget: util.Duration(2 * time.Hour), Limit: resource.MustParse("6")},
+						{Limit: limit},
+					},
+				},
+				IOLimits:  &config.WSDaemonIOLimits{WriteIOPS: pointer.Int64(1000)},
+				ProcLimit: pointer.Int64(0),
+			},
+		},
+		Experimental: &experimental.Config{
+			Workspace: &experimental.WorkspaceConfig{
+				ProcLimit: 500,
+			},
+		},
+	}, versions.Manifest{}, "test_namespace")
+	require.NoError(t, err)
+
+	objs, err := configmap(ctx)
+	require.NoError(t, err)
+
+	var cfg wsdconfig.Config
+	require.NoError(t, json.Unmarshal([]byte(objs[0].(*corev1.ConfigMap).Data["config.json"]), &cfg))
+
+	cpu := cfg.Daemon.CPULimit
+	require.True(t, cpu.Enabled)
+	require.Equal(t, int64(60), cpu.TotalBandwidth.Value())
+	require.Equal(t, int64(2), cpu.BurstLimit.Value())
+	require.Equal(t, util.Duration(15*time.Second), cpu.ControlPeriod)
+	require.Equal(t, []cpulimit.Bucket{
+		{Budget: cpulimit.CPUTime(2 * time.Hour), Limit: 6000},
+		{Limit: 2000},
+	}, cpu.Buckets)
+	require.Equal(t, int64(1000), cfg.Daemon.IOLimit.WriteIOPS)
+	require.Equal(t, int64(0), cfg.Daemon.ProcLimit)
+}
+
+func TestConfigMap_StorageQuota(t *testing.T) {
+	size := resource.MustParse("30Gi")
+	ctx, err := common.NewRenderContext(config.Config{
+		Workspace: config.Workspace{
+			Runtime:      config.WorkspaceRuntime{FSShiftMethod: config.FSShiftShiftFS},
+			StorageQuota: &config.WorkspaceStorageQuota{Backend: config.StorageQuotaXFS, DefaultSize: &size},
+		},
+		ObjectStorage: config.ObjectStorage{InCluster: pointer.Bool(true)},
+	}, versions.Manifest{}, "test_namespace")
+	require.NoError(t, err)
+
+	objs, err := configmap(ctx)
+	require.NoError(t, err)
+
+	var cfg wsdconfig.Config
+	require.NoError(t, json.Unmarshal([]byte(objs[0].(*corev1.ConfigMap).Data["config.json"]), &cfg))
+	require.Equal(t, content.StorageQuotaConfig{Backend: content.QuotaBackendXFS, DefaultSize: 30 * quota.Gigabyte}, cfg.Daemon.Content.StorageQuota)
+}
@@ -9,7 +9,6 @@ import (
 
 	"github.com/gitpod-io/gitpod/installer/pkg/cluster"
 	"github.com/gitpod-io/gitpod/installer/pkg/common"
-	"github.com/gitpod-io/gitpod/installer/pkg/config/v1/experimental"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -24,11 +23,22 @@ func daemonset(ctx *common.RenderContext) ([]runtime.Object, error) {
 	cfg := ctx.Config
 	labels := common.CustomizeLabel(ctx, Component, common.TypeMetaDaemonset)
 
-	configHash, err := common.ObjectHash(configmap(ctx))
+	// the hosts.toml of the registries are only installed when the pods start
+	configHash, err := common.ObjectHash(common.CompositeRenderFunc(configmap, registryHostsConfigMap)(ctx))
 	if err != nil {
 		return nil, err
 	}
 
+	nodeMountsPath, nodeCgroupPath := "/proc/mounts", "/sys/fs/cgroup"
+	if wsd := componentConfig(ctx); wsd != nil {
+		if wsd.NodeProcMountsPath != "" {
+			nodeMountsPath = wsd.NodeProcMountsPath
+		}
+		if wsd.NodeCgroupPath != "" {
+			nodeCgroupPath = wsd.NodeCgroupPath
+		}
+	}
+
 	initContainers := []corev1.Container{
 		{
 			Name:  "seccomp-profile-installer",
@@ -105,14 +115,14 @@ func daemonset(ctx *common.RenderContext) ([]runtime.Object, error) {
 		{
 			Name: "node-mounts",
 			VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{
-				Path: "/proc/mounts",
+				Path: nodeMountsPath,
 				Type: func() *corev1.HostPathType { r := corev1.HostPathFile; return &r }(),
 			}},
 		},
 		{
 			Name: "node-cgroups",
 			VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{
-				Path: "/sys/fs/cgroup",
+				Path: nodeCgroupPath,
 				Type: func() *corev1.HostPathType { r := corev1.HostPathDirectory; return &r }(),
 			}},
 		},
@@ -188,28 +198,30 @@ func daemonset(ctx *common.RenderContext) ([]runtime.Object, error) {
 		common.CAVolumeMount(),
 	}
 
-	_ = ctx.WithExperimental(func(cfg *experimental.Config) error {
-		if cfg.Workspace != nil && cfg.Workspace.UseWsmanagerMk2 {
-			mk2WorkingAreaVolume := corev1.Volume{
-				Name: "working-area-mk2",
-				VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{
-					Path: HostWorkingAreaMk2,
-					Type: func() *corev1.HostPathType { r := corev1.HostPathDirectoryOrCreate; return &r }(),
-				}},
-			}
+	if len(registryMirrors(ctx)) > 0 {
+		installer, installerVolumes := registryHostsInstaller(ctx)
+		initContainers = append(initContainers, installer)
+		volumes = append(volumes, installerVolumes...)
+	}
 
-			mk2WorkingAreaMount := corev1.VolumeMount{
-				Name:             "working-area-mk2",
-				MountPath:        ContainerWorkingAreaMk2,
-				MountPropagation: func() *corev1.MountPropagationMode { r := corev1.MountPropagationBidirectional; return &r }(),
-			}
+	if common.UseWsManagerMk2(ctx) {
+		mk2WorkingAreaVolume := corev1.Volume{
+			Name: "working-area-mk2",
+			VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{
+				Path: HostWorkingAreaMk2,
+				Type: func() *corev1.HostPathType { r := corev1.HostPathDirectoryOrCreate; return &r }(),
+			}},
+		}
 
-			volumes = append(volumes, mk2WorkingAreaVolume)
-			volumeMounts = append(volumeMounts, mk2WorkingAreaMount)
+		mk2WorkingAreaMount := corev1.VolumeMount{
+			Name:             "working-area-mk2",
+			MountPath:        ContainerWorkingAreaMk2,
+			MountPropagation: func() *corev1.MountPropagationMode { r := corev1.MountPropagationBidirectional; return &r }(),
 		}
 
-		return nil
-	})
+		volumes = append(volumes, mk2WorkingAreaVolume)
+		volumeMounts = append(volumeMounts, mk2WorkingAreaMount)
+	}
 
 	tolerations := []corev1.Toleration{
 		{
@@ -295,9 +307,9 @@ func daemonset(ctx *common.RenderContext) ([]runtime.Object, error) {
 		DNSPolicy:                     corev1.DNSClusterFirst,
 		ServiceAccountName:            Component,
 		HostPID:                       true,
-		Affinity:                      cluster.WithNodeAffinity(cluster.AffinityLabelWorkspacesRegular, cluster.AffinityLabelWorkspacesHeadless),
-		Tolerations:                   tolerations,
-		PriorityClassName:             common.SystemNodeCritical,
+		Affinity:                      common.Affinity(ctx, Component, cluster.WithNodeAffinity(cluster.AffinityLabelWorkspacesRegular, cluster.AffinityLabelWorkspacesHeadless)),
+		Tolerations:                   common.Tolerations(ctx, Component, tolerations),
+		PriorityClassName:             common.PriorityClassName(ctx, Component, common.SystemNodeCritical),
 		EnableServiceLinks:            pointer.Bool(false),
 	}
 
@@ -327,7 +339,7 @@ func daemonset(ctx *common.RenderContext) ([]runtime.Object, error) {
 				},
 				Spec: podSpec,
 			},
-			UpdateStrategy: common.DaemonSetRolloutStrategy(),
+			UpdateStrategy: common.DaemonSetRolloutStrategy(ctx, Component),
 		},
 	}}, nil
 }
@@ -0,0 +1,50 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package wsdaemon
+
+import (
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func dashboard(ctx *common.RenderContext) ([]runtime.Object, error) {
+	return common.GenerateGrafanaDashboard(Component, []common.GrafanaPanel{
+		{
+			Title: "gRPC calls",
+			Unit:  "reqps",
+			Queries: []common.GrafanaQuery{
+				{Expr: `sum(rate(grpc_server_handled_total{$selector}[5m])) by (grpc_method, grpc_code)`, Legend: "{{grpc_method}} {{grpc_code}}"},
+			},
+		},
+		{
+			Title: "gRPC call duration",
+			Unit:  "s",
+			Queries: []common.GrafanaQuery{
+				{Expr: `histogram_quantile(0.95, sum(rate(grpc_server_handling_seconds_bucket{$selector}[5m])) by (grpc_method, le))`, Legend: "p95 {{grpc_method}}"},
+			},
+		},
+		{
+			Title: "Reconciliations",
+			Unit:  "ops",
+			Queries: []common.GrafanaQuery{
+				{Expr: `sum(rate(controller_runtime_reconcile_total{$selector}[5m])) by (controller, result)`, Legend: "{{controller}} {{result}}"},
+			},
+		},
+		{
+			Title: "Work queue depth",
+			Unit:  "short",
+			Queries: []common.GrafanaQuery{
+				{Expr: `sum(workqueue_depth{$selector}) by (name)`, Legend: "{{name}}"},
+			},
+		},
+		{
+			Title: "Goroutines",
+			Unit:  "short",
+			Queries: []common.GrafanaQuery{
+				{Expr: `go_goroutines{$selector}`, Legend: "{{pod}}"},
+			},
+		},
+	})(ctx)
+}
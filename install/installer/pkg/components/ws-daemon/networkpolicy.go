@@ -0,0 +1,59 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package wsdaemon
+
+import (
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// networkpolicy allows ws-manager to reach the ws-daemon API and ws-daemon to back up workspace
+// content to the object storage. It's only needed in strict mode, when all traffic that isn't
+// explicitly allowed is denied.
+func networkpolicy(ctx *common.RenderContext) ([]runtime.Object, error) {
+	if !common.StrictNetworkPolicy(ctx) {
+		return nil, nil
+	}
+
+	labels := common.DefaultLabels(Component)
+	namespace := common.InfrastructureNamespace(ctx)
+
+	return []runtime.Object{&networkingv1.NetworkPolicy{
+		TypeMeta: common.TypeMetaNetworkPolicy,
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      Component,
+			Namespace: ctx.Namespace,
+			Labels:    labels,
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: labels},
+			PolicyTypes: common.NetworkPolicyTypes(ctx),
+			Egress: common.NetworkPolicyEgress(ctx,
+				common.AllowKubeAPIEgressRule(),
+				common.AllowObjectStorageEgressRule(ctx),
+				// the registries of the workspace images
+				common.AllowExternalEgressRule(),
+			),
+			Ingress: []networkingv1.NetworkPolicyIngressRule{
+				{
+					Ports: []networkingv1.NetworkPolicyPort{{
+						Protocol: common.TCPProtocol,
+						Port:     &intstr.IntOrString{IntVal: ServicePort},
+					}},
+					From: []networkingv1.NetworkPolicyPeer{
+						common.NamespacePodPeer(namespace, ctx.Namespace, common.DefaultLabels(common.WSManagerComponent)),
+						common.NamespacePodPeer(namespace, ctx.Namespace, common.DefaultLabels(common.WSManagerMk2Component)),
+						common.NamespacePodPeer(namespace, ctx.Namespace, common.DefaultLabels(common.NodeLabelerComponent)),
+					},
+				},
+				common.PrometheusIngressRule,
+			},
+		},
+	}}, nil
+}
@@ -9,18 +9,24 @@ import (
 )
 
 var Objects = common.CompositeRenderFunc(
-	role,
-	clusterrole,
-	configmap,
-	common.DefaultServiceAccount(Component),
-	daemonset,
-	rolebinding,
-	common.GenerateService(Component, []common.ServicePort{
-		{
-			Name:          "rpc",
-			ContainerPort: ServicePort,
-			ServicePort:   ServicePort,
-		},
-	}),
-	tlssecret,
+	common.InInfrastructureNamespace(
+		role,
+		clusterrole,
+		configmap,
+		registryHostsConfigMap,
+		common.DefaultServiceAccount(Component),
+		daemonset,
+		networkpolicy,
+		rolebinding,
+		common.GenerateService(Component, []common.ServicePort{
+			{
+				Name:          "rpc",
+				ContainerPort: ServicePort,
+				ServicePort:   ServicePort,
+			},
+		}),
+		tlssecret,
+	),
+	clientTLSSecret,
+	dashboard,
 )
@@ -0,0 +1,142 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package wsdaemon
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	RegistryHostsConfigMap = "ws-daemon-registry-hosts"
+
+	defaultRegistryConfigDir = "/etc/containerd/certs.d"
+	registryHostsMountPath   = "/mnt/registry-hosts"
+	registryConfigMountPath  = "/mnt/containerd-certs.d"
+)
+
+func registryMirrors(ctx *common.RenderContext) []config.RegistryMirror {
+	return ctx.Config.Workspace.Runtime.RegistryMirrors
+}
+
+// registryHostsKey is the key of the hosts.toml of the registry in the config map, which
+// cannot contain the colon of a port
+func registryHostsKey(registry string) string {
+	return strings.ReplaceAll(registry, ":", "_") + ".toml"
+}
+
+// hostsTOML returns the hosts.toml of the registry, which has containerd try the mirrors in
+// order before it falls back to the registry itself
+func hostsTOML(mirror config.RegistryMirror) string {
+	if mirror.HostsTOML != "" {
+		return mirror.HostsTOML
+	}
+
+	var b strings.Builder
+	for _, m := range mirror.Mirrors {
+		fmt.Fprintf(&b, "[host.%s]\n", strconv.Quote(m))
+		b.WriteString("  capabilities = [\"pull\", \"resolve\"]\n")
+		if mirror.SkipVerify {
+			b.WriteString("  skip_verify = true\n")
+		}
+	}
+	return b.String()
+}
+
+func registryHostsConfigMap(ctx *common.RenderContext) ([]runtime.Object, error) {
+	mirrors := registryMirrors(ctx)
+	if len(mirrors) == 0 {
+		return nil, nil
+	}
+
+	data := make(map[string]string, len(mirrors))
+	for _, m := range mirrors {
+		data[registryHostsKey(m.Registry)] = hostsTOML(m)
+	}
+
+	return []runtime.Object{&corev1.ConfigMap{
+		TypeMeta: common.TypeMetaConfigmap,
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        RegistryHostsConfigMap,
+			Namespace:   ctx.Namespace,
+			Labels:      common.CustomizeLabel(ctx, Component, common.TypeMetaConfigmap),
+			Annotations: common.CustomizeAnnotation(ctx, Component, common.TypeMetaConfigmap),
+		},
+		Data: data,
+	}}, nil
+}
+
+// registryHostsInstaller writes the hosts.toml of the registries to the registry config dir
+// of containerd on the node. containerd reads them on every pull, so it needs no restart.
+func registryHostsInstaller(ctx *common.RenderContext) (corev1.Container, []corev1.Volume) {
+	configDir := ctx.Config.Workspace.Runtime.RegistryConfigDir
+	if configDir == "" {
+		configDir = defaultRegistryConfigDir
+	}
+
+	mirrors := registryMirrors(ctx)
+	items := make([]corev1.KeyToPath, 0, len(mirrors))
+	for _, m := range mirrors {
+		items = append(items, corev1.KeyToPath{
+			Key:  registryHostsKey(m.Registry),
+			Path: fmt.Sprintf("%s/hosts.toml", m.Registry),
+		})
+	}
+
+	container := corev1.Container{
+		Name:  "registry-hosts-installer",
+		Image: ctx.ImageName(ctx.Config.Repository, Component, ctx.VersionManifest.Components.WSDaemon.Version),
+		Command: []string{
+			"sh",
+			"-c",
+			fmt.Sprintf(`for dir in %[1]s/*/; do
+	registry=$(basename "$dir") &&
+	mkdir -p "%[2]s/$registry" &&
+	cp -fL "$dir/hosts.toml" "%[2]s/$registry/hosts.toml" || exit 1
+done
+`, registryHostsMountPath, registryConfigMountPath),
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      "registry-hosts",
+				MountPath: registryHostsMountPath,
+				ReadOnly:  true,
+			},
+			{
+				Name:      "containerd-registry-config",
+				MountPath: registryConfigMountPath,
+			},
+		},
+		SecurityContext: &corev1.SecurityContext{Privileged: pointer.Bool(true)},
+	}
+
+	volumes := []corev1.Volume{
+		{
+			Name: "registry-hosts",
+			VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: RegistryHostsConfigMap},
+				Items:                items,
+			}},
+		},
+		{
+			Name: "containerd-registry-config",
+			VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{
+				Path: configDir,
+				Type: func() *corev1.HostPathType { r := corev1.HostPathDirectoryOrCreate; return &r }(),
+			}},
+		},
+	}
+
+	return container, volumes
+}
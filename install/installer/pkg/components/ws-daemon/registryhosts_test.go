@@ -0,0 +1,51 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package wsdaemon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+	"github.com/gitpod-io/gitpod/installer/pkg/config/versions"
+)
+
+func TestRegistryHosts(t *testing.T) {
+	var manifest versions.Manifest
+	manifest.Components.WSDaemon.Version = "test"
+	ctx, err := common.NewRenderContext(config.Config{
+		Workspace: config.Workspace{
+			Runtime: config.WorkspaceRuntime{
+				RegistryMirrors: []config.RegistryMirror{
+					{Registry: "docker.io", Mirrors: []string{"https://mirror.example.com"}, SkipVerify: true},
+					{Registry: "registry.example.com:5000", HostsTOML: "server = \"https://registry.example.com:5000\"\n"},
+				},
+				RegistryConfigDir: "/etc/containerd/registries",
+			},
+		},
+	}, manifest, "test_namespace")
+	require.NoError(t, err)
+
+	objs, err := registryHostsConfigMap(ctx)
+	require.NoError(t, err)
+	require.Len(t, objs, 1)
+	require.Equal(t, map[string]string{
+		"docker.io.toml": `[host."https://mirror.example.com"]
+  capabilities = ["pull", "resolve"]
+  skip_verify = true
+`,
+		"registry.example.com_5000.toml": "server = \"https://registry.example.com:5000\"\n",
+	}, objs[0].(*corev1.ConfigMap).Data)
+
+	_, volumes := registryHostsInstaller(ctx)
+	require.Equal(t, []corev1.KeyToPath{
+		{Key: "docker.io.toml", Path: "docker.io/hosts.toml"},
+		{Key: "registry.example.com_5000.toml", Path: "registry.example.com:5000/hosts.toml"},
+	}, volumes[0].ConfigMap.Items)
+	require.Equal(t, "/etc/containerd/registries", volumes[1].HostPath.Path)
+}
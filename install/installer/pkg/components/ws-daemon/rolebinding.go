@@ -8,7 +8,6 @@ import (
 	"fmt"
 
 	"github.com/gitpod-io/gitpod/installer/pkg/common"
-	"github.com/gitpod-io/gitpod/installer/pkg/config/v1/experimental"
 
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -34,7 +33,7 @@ func rolebinding(ctx *common.RenderContext) ([]runtime.Object, error) {
 				{
 					Kind:      "ServiceAccount",
 					Name:      Component,
-					Namespace: ctx.Namespace,
+					Namespace: common.InfrastructureNamespace(ctx),
 				},
 			},
 		},
@@ -52,36 +51,32 @@ func rolebinding(ctx *common.RenderContext) ([]runtime.Object, error) {
 			Subjects: []rbacv1.Subject{{
 				Kind:      "ServiceAccount",
 				Name:      Component,
-				Namespace: ctx.Namespace,
+				Namespace: common.InfrastructureNamespace(ctx),
 			}},
 		},
 	}
 
-	_ = ctx.WithExperimental(func(ucfg *experimental.Config) error {
-		if ucfg.Workspace != nil && ucfg.Workspace.UseWsmanagerMk2 {
-			bindings = append(bindings, &rbacv1.RoleBinding{
-				TypeMeta: common.TypeMetaRoleBinding,
-				ObjectMeta: metav1.ObjectMeta{
+	if common.UseWsManagerMk2(ctx) {
+		bindings = append(bindings, &rbacv1.RoleBinding{
+			TypeMeta: common.TypeMetaRoleBinding,
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      Component,
+				Namespace: common.WorkspaceSecretsNamespace,
+			},
+			RoleRef: rbacv1.RoleRef{
+				APIGroup: "rbac.authorization.k8s.io",
+				Kind:     "Role",
+				Name:     Component,
+			},
+			Subjects: []rbacv1.Subject{
+				{
+					Kind:      "ServiceAccount",
 					Name:      Component,
-					Namespace: common.WorkspaceSecretsNamespace,
-				},
-				RoleRef: rbacv1.RoleRef{
-					APIGroup: "rbac.authorization.k8s.io",
-					Kind:     "Role",
-					Name:     Component,
-				},
-				Subjects: []rbacv1.Subject{
-					{
-						Kind:      "ServiceAccount",
-						Name:      Component,
-						Namespace: ctx.Namespace,
-					},
+					Namespace: common.InfrastructureNamespace(ctx),
 				},
-			})
-		}
-
-		return nil
-	})
+			},
+		})
+	}
 
 	return bindings, nil
 }
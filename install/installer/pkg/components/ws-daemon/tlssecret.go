@@ -8,39 +8,29 @@ import (
 	"fmt"
 
 	"github.com/gitpod-io/gitpod/installer/pkg/common"
-	certmanagerv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
-	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
 func tlssecret(ctx *common.RenderContext) ([]runtime.Object, error) {
 	return []runtime.Object{
-		&certmanagerv1.Certificate{
-			TypeMeta: common.TypeMetaCertificate,
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      TLSSecretName,
-				Namespace: ctx.Namespace,
-				Labels:    common.DefaultLabels(Component),
-			},
-			Spec: certmanagerv1.CertificateSpec{
-				Duration:   common.InternalCertDuration,
-				SecretName: TLSSecretName,
-				DNSNames: []string{
-					fmt.Sprintf("gitpod.%s", ctx.Namespace),
-					fmt.Sprintf("%s.%s.svc", Component, ctx.Namespace),
-					Component,
-					"wsdaemon", // Seems this is hardcoded in WSManager
-				},
-				IssuerRef: cmmeta.ObjectReference{
-					Name:  common.CertManagerCAIssuer,
-					Kind:  certmanagerv1.ClusterIssuerKind,
-					Group: "cert-manager.io",
-				},
-				SecretTemplate: &certmanagerv1.CertificateSecretTemplate{
-					Labels: common.DefaultLabels(Component),
-				},
-			},
-		},
+		common.InternalCertificate(ctx, Component, TLSSecretName, TLSSecretName, altNames(ctx)),
 	}, nil
 }
+
+// clientTLSSecret issues the certificate in the namespace of the installation as well if
+// ws-daemon runs in the infrastructure namespace. ws-manager connects to ws-daemon with it.
+func clientTLSSecret(ctx *common.RenderContext) ([]runtime.Object, error) {
+	if !common.SeparateInfrastructureNamespace(ctx) {
+		return nil, nil
+	}
+	return tlssecret(ctx)
+}
+
+func altNames(ctx *common.RenderContext) []string {
+	return []string{
+		fmt.Sprintf("gitpod.%s", ctx.Namespace),
+		fmt.Sprintf("%s.%s.svc", Component, common.InfrastructureNamespace(ctx)),
+		Component,
+		"wsdaemon", // Seems this is hardcoded in WSManager
+	}
+}
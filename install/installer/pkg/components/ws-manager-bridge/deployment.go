@@ -50,6 +50,10 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 		})
 	}
 
+	clusterVolumes, clusterMounts := WorkspaceClusterVolumes(ctx)
+	volumes = append(volumes, clusterVolumes...)
+	volumeMounts = append(volumeMounts, clusterMounts...)
+
 	msgBugSecret := corev1.LocalObjectReference{Name: common.InClusterMessageQueueName}
 	if ctx.Config.MessageBus != nil && ctx.Config.MessageBus.Credentials != nil {
 		msgBugSecret = corev1.LocalObjectReference{Name: ctx.Config.MessageBus.Credentials.Name}
@@ -78,6 +82,9 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 								if ctx.Config.Database.CloudSQL != nil {
 									return "cloudsql"
 								}
+								if ctx.Config.Database.UsePostgres() {
+									return "external-postgres"
+								}
 								return "external"
 							}(),
 						},
@@ -117,10 +124,11 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 						}),
 					},
 					Spec: corev1.PodSpec{
-						Affinity:                      cluster.WithNodeAffinityHostnameAntiAffinity(Component, cluster.AffinityLabelMeta),
+						Affinity:                      common.Affinity(ctx, Component, cluster.WithNodeAffinityHostnameAntiAffinity(Component, cluster.AffinityLabelMeta)),
+						Tolerations:                   common.Tolerations(ctx, Component, nil),
 						TopologySpreadConstraints:     cluster.WithHostnameTopologySpread(Component),
 						ServiceAccountName:            Component,
-						PriorityClassName:             common.SystemNodeCritical,
+						PriorityClassName:             common.PriorityClassName(ctx, Component, common.SystemNodeCritical),
 						EnableServiceLinks:            pointer.Bool(false),
 						DNSPolicy:                     corev1.DNSClusterFirst,
 						RestartPolicy:                 corev1.RestartPolicyAlways,
@@ -139,7 +147,7 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 							},
 							volumes...,
 						),
-						InitContainers: []corev1.Container{*common.DatabaseWaiterContainer(ctx), *common.MessageBusWaiterContainer(ctx)},
+						InitContainers: append(common.DatabaseWaiterContainers(ctx), *common.MessageBusWaiterContainer(ctx)),
 						Containers: []corev1.Container{{
 							Name:            Component,
 							Image:           ctx.ImageName(ctx.Config.Repository, Component, ctx.VersionManifest.Components.WSManagerBridge.Version),
@@ -162,6 +170,8 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 								common.MessageBusEnv(&ctx.Config),
 								common.DatabaseEnv(&ctx.Config),
 								common.ConfigcatEnv(ctx),
+								common.NodeCAEnv(),
+								common.GRPCClientEnv(ctx),
 								[]corev1.EnvVar{{
 									Name:  "WSMAN_BRIDGE_CONFIGPATH",
 									Value: "/config/ws-manager-bridge.json",
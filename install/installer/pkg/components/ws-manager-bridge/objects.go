@@ -16,6 +16,7 @@ import (
 var Objects = common.CompositeRenderFunc(
 	configmap,
 	deployment,
+	networkpolicy,
 	rolebinding,
 	common.DefaultServiceAccount(Component),
 )
@@ -27,25 +28,24 @@ func WSManagerList(ctx *common.RenderContext) []WorkspaceCluster {
 		if cfg.WebApp != nil && cfg.WebApp.WorkspaceManagerBridge != nil {
 			skipSelf = cfg.WebApp.WorkspaceManagerBridge.SkipSelf
 		}
-
-		if !common.WithLocalWsManager(ctx) {
-			// Must skip self if cluster does not contain ws-manager.
-			skipSelf = true
-		}
-
-		if cfg.Workspace != nil && cfg.Workspace.UseWsmanagerMk2 {
-			wsmanagerAddr = fmt.Sprintf("dns:///%s:%d", wsmanagermk2.Component, wsmanagermk2.RPCPort)
-		}
 		return nil
 	})
+	if !common.WithLocalWsManager(ctx) {
+		// Must skip self if cluster does not contain ws-manager, which a Meta installation
+		// without the experimental config would otherwise register with its workspace clusters
+		skipSelf = true
+	}
+	if common.UseWsManagerMk2(ctx) {
+		wsmanagerAddr = fmt.Sprintf("dns:///%s:%d", wsmanagermk2.Component, wsmanagermk2.RPCPort)
+	}
 
 	// Registering a local cluster ws-manager only makes sense when we actually deploy one,
 	// (ie when we are doing a full self hosted installation rather than a SaaS install to gitpod.io).
 	if skipSelf {
-		return []WorkspaceCluster{}
+		return append([]WorkspaceCluster{}, workspaceClusters(ctx)...)
 	}
 
-	return []WorkspaceCluster{{
+	return append([]WorkspaceCluster{{
 		Name: ctx.Config.Metadata.InstallationShortname,
 		URL:  wsmanagerAddr,
 		TLS: WorkspaceClusterTLS{
@@ -59,5 +59,5 @@ func WSManagerList(ctx *common.RenderContext) []WorkspaceCluster {
 		Govern:               true,
 		AdmissionConstraints: nil,
 		ApplicationCluster:   ctx.Config.Metadata.InstallationShortname,
-	}}
+	}}, workspaceClusters(ctx)...)
 }
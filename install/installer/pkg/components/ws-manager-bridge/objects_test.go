@@ -39,6 +39,45 @@ func TestWorkspaceManagerList_WhenSkipSelfIsSet(t *testing.T) {
 	}
 }
 
+func TestWorkspaceManagerList_WorkspaceClusters(t *testing.T) {
+	ctx, err := common.NewRenderContext(config.Config{
+		Kind:     config.InstallationMeta,
+		Metadata: config.Metadata{InstallationShortname: "default"},
+		WorkspaceClusters: []config.WorkspaceCluster{{
+			Name: "eu02",
+			URL:  "dns:///ws-manager.eu02.example.com:443",
+			TLS:  config.ObjectRef{Kind: config.ObjectRefSecret, Name: "eu02-tls"},
+			AdmissionConstraints: []config.WorkspaceClusterAdmissionConstraint{
+				{Type: "has-permission", Permission: "new-workspace-cluster"},
+			},
+		}},
+	}, versions.Manifest{}, "test-namespace")
+	require.NoError(t, err)
+
+	require.Equal(t, []WorkspaceCluster{{
+		Name: "eu02",
+		URL:  "dns:///ws-manager.eu02.example.com:443",
+		TLS: WorkspaceClusterTLS{
+			Authority:   "/workspace-cluster-tls-certs/eu02/ca.crt",
+			Certificate: "/workspace-cluster-tls-certs/eu02/tls.crt",
+			Key:         "/workspace-cluster-tls-certs/eu02/tls.key",
+		},
+		State:    WorkspaceClusterStateAvailable,
+		MaxScore: 100,
+		Score:    50,
+		Govern:   true,
+		AdmissionConstraints: []AdmissionConstraint{
+			{Type: AdmissionConstraintHasRole, Permission: AdmissionConstraintPermissionNewWorkspaceCluster},
+		},
+		ApplicationCluster: "default",
+	}}, WSManagerList(ctx), "the clusters of the config are registered without the cluster of the installation")
+
+	volumes, mounts := WorkspaceClusterVolumes(ctx)
+	require.Len(t, volumes, 1)
+	require.Equal(t, "eu02-tls", volumes[0].Secret.SecretName)
+	require.Equal(t, "/workspace-cluster-tls-certs/eu02", mounts[0].MountPath)
+}
+
 func renderContextWithConfig(t *testing.T, kind config.InstallationKind, skipSelf bool) *common.RenderContext {
 	ctx, err := common.NewRenderContext(config.Config{
 		Kind: kind,
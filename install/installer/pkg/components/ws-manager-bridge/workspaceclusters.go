@@ -0,0 +1,87 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package wsmanagerbridge
+
+import (
+	"fmt"
+	"path/filepath"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+)
+
+const workspaceClusterTLSPath = "/workspace-cluster-tls-certs"
+
+// workspaceClusters are the registrations of the workspace clusters of the config. Their
+// client certificates are mounted by WorkspaceClusterVolumes.
+func workspaceClusters(ctx *common.RenderContext) []WorkspaceCluster {
+	var res []WorkspaceCluster
+	for _, c := range ctx.Config.WorkspaceClusters {
+		state := WorkspaceClusterStateAvailable
+		if c.State != "" {
+			state = WorkspaceClusterState(c.State)
+		}
+		score, maxScore := int32(50), int32(100)
+		if c.Score != nil {
+			score = *c.Score
+		}
+		if c.MaxScore != nil {
+			maxScore = *c.MaxScore
+		}
+
+		var constraints []AdmissionConstraint
+		for _, ac := range c.AdmissionConstraints {
+			constraints = append(constraints, AdmissionConstraint{
+				Type:       AdmissionConstraintType(ac.Type),
+				Permission: AdmissionConstraintPermission(ac.Permission),
+			})
+		}
+
+		certs := filepath.Join(workspaceClusterTLSPath, c.Name)
+		res = append(res, WorkspaceCluster{
+			Name: c.Name,
+			URL:  c.URL,
+			TLS: WorkspaceClusterTLS{
+				Authority:   filepath.Join(certs, "ca.crt"),
+				Certificate: filepath.Join(certs, "tls.crt"),
+				Key:         filepath.Join(certs, "tls.key"),
+			},
+			State:                state,
+			MaxScore:             maxScore,
+			Score:                score,
+			Govern:               pointer.BoolDeref(c.Govern, true),
+			AdmissionConstraints: constraints,
+			ApplicationCluster:   ctx.Config.Metadata.InstallationShortname,
+			Region:               c.Region,
+		})
+	}
+	return res
+}
+
+// WorkspaceClusterVolumes mount the client certificates of the workspace clusters of the config,
+// which ws-manager-bridge and server connect to their ws-managers with
+func WorkspaceClusterVolumes(ctx *common.RenderContext) ([]corev1.Volume, []corev1.VolumeMount) {
+	var (
+		volumes []corev1.Volume
+		mounts  []corev1.VolumeMount
+	)
+	for i, c := range ctx.Config.WorkspaceClusters {
+		name := fmt.Sprintf("workspace-cluster-tls-certs-%d", i)
+		volumes = append(volumes, corev1.Volume{
+			Name: name,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: c.TLS.Name},
+			},
+		})
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      name,
+			MountPath: filepath.Join(workspaceClusterTLSPath, c.Name),
+			ReadOnly:  true,
+		})
+	}
+	return volumes, mounts
+}
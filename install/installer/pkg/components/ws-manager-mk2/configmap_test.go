@@ -210,3 +210,23 @@ func TestWorkspaceURLTemplates(t *testing.T) {
 		})
 	}
 }
+
+func TestImagePullSecrets(t *testing.T) {
+	ctx, err := common.NewRenderContext(config.Config{
+		ObjectStorage: config.ObjectStorage{
+			InCluster: pointer.Bool(true),
+		},
+		ImagePullSecrets: []config.ObjectRef{
+			{Kind: config.ObjectRefSecret, Name: "mirror"},
+			{Kind: config.ObjectRefSecret, Name: "upstream"},
+		},
+	}, versions.Manifest{}, "test_namespace")
+	require.NoError(t, err)
+
+	objs, err := configmap(ctx)
+	require.NoError(t, err)
+
+	var serviceConfig wsmancfg.ServiceConfiguration
+	require.NoError(t, json.Unmarshal([]byte(objs[0].(*corev1.ConfigMap).Data["config.json"]), &serviceConfig))
+	require.Equal(t, []string{"mirror", "upstream"}, serviceConfig.Manager.ImagePullSecrets)
+}
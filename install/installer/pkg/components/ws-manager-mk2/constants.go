@@ -19,4 +19,10 @@ const (
 	WorkspaceTemplatePath      = "/workspace-templates"
 	WorkspaceTemplateConfigMap = "workspace-templates"
 	LabelMaintenanceConfig     = "gitpod.io/maintenanceConfig"
+	WebhookPort                = 9443
+	WebhookPortName            = "webhook"
+	WebhookServicePort         = 443
+	WebhookCertDir             = "/webhook-certs"
+	WebhookTLSSecretName       = "ws-manager-mk2-webhook-tls"
+	VolumeWebhookTLSCerts      = "webhook-tls-certs"
 )
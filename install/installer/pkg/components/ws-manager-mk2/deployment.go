@@ -45,9 +45,14 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 		})
 	}
 
+	caVolumes, caVolumeMounts := common.SSHUserCAVolume(ctx)
+	volumes = append(volumes, caVolumes...)
+	volumeMounts = append(volumeMounts, caVolumeMounts...)
+
 	podSpec := corev1.PodSpec{
-		PriorityClassName:         common.SystemNodeCritical,
-		Affinity:                  cluster.WithNodeAffinityHostnameAntiAffinity(Component, cluster.AffinityLabelServices),
+		PriorityClassName:         common.PriorityClassName(ctx, Component, common.SystemNodeCritical),
+		Affinity:                  common.Affinity(ctx, Component, cluster.WithNodeAffinityHostnameAntiAffinity(Component, cluster.AffinityLabelServices)),
+		Tolerations:               common.Tolerations(ctx, Component, nil),
 		TopologySpreadConstraints: cluster.WithHostnameTopologySpread(Component),
 		EnableServiceLinks:        pointer.Bool(false),
 		ServiceAccountName:        Component,
@@ -59,6 +64,8 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 			Args: []string{
 				"--config", "/config/config.json",
 				"--leader-elect",
+				"--enable-webhooks",
+				"--webhook-cert-dir", WebhookCertDir,
 			},
 			Image:           ctx.ImageName(ctx.Config.Repository, Component, ctx.VersionManifest.Components.WSManagerMk2.Version),
 			ImagePullPolicy: corev1.PullIfNotPresent,
@@ -93,6 +100,10 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 					Name:          RPCPortName,
 					ContainerPort: RPCPort,
 				},
+				{
+					Name:          WebhookPortName,
+					ContainerPort: WebhookPort,
+				},
 			},
 			SecurityContext: &corev1.SecurityContext{
 				Privileged: pointer.Bool(false),
@@ -123,6 +134,11 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 					MountPath: "/certs",
 					ReadOnly:  true,
 				},
+				{
+					Name:      VolumeWebhookTLSCerts,
+					MountPath: WebhookCertDir,
+					ReadOnly:  true,
+				},
 				common.CAVolumeMount(),
 			}, volumeMounts...),
 		},
@@ -157,6 +173,12 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 					Secret: &corev1.SecretVolumeSource{SecretName: TLSSecretNameSecret},
 				},
 			},
+			{
+				Name: VolumeWebhookTLSCerts,
+				VolumeSource: corev1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{SecretName: WebhookTLSSecretName},
+				},
+			},
 			common.CAVolume(),
 		}, volumes...),
 	}
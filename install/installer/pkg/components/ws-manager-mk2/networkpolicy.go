@@ -6,6 +6,8 @@ package wsmanagermk2
 
 import (
 	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	"github.com/gitpod-io/gitpod/installer/pkg/components/workspace"
+	wsdaemon "github.com/gitpod-io/gitpod/installer/pkg/components/ws-daemon"
 
 	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -26,8 +28,13 @@ func networkpolicy(ctx *common.RenderContext) ([]runtime.Object, error) {
 			},
 			Spec: networkingv1.NetworkPolicySpec{
 				PodSelector: metav1.LabelSelector{MatchLabels: labels},
-				PolicyTypes: []networkingv1.PolicyType{"Ingress"},
-				Ingress:     []networkingv1.NetworkPolicyIngressRule{{}},
+				PolicyTypes: common.NetworkPolicyTypes(ctx),
+				Egress: common.NetworkPolicyEgress(ctx,
+					common.AllowKubeAPIEgressRule(),
+					common.AllowComponentsEgressRule(ctx, wsdaemon.Component, common.ImageBuilderComponentWsman, workspace.Component),
+					common.AllowObjectStorageEgressRule(ctx),
+				),
+				Ingress: []networkingv1.NetworkPolicyIngressRule{{}},
 			},
 		},
 	}, nil
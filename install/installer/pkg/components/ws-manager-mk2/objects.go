@@ -6,19 +6,11 @@ package wsmanagermk2
 
 import (
 	"github.com/gitpod-io/gitpod/installer/pkg/common"
-	"github.com/gitpod-io/gitpod/installer/pkg/config/v1/experimental"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
 var Objects common.RenderFunc = func(cfg *common.RenderContext) ([]runtime.Object, error) {
-	var useMk2 bool
-	_ = cfg.WithExperimental(func(ucfg *experimental.Config) error {
-		if ucfg.Workspace != nil {
-			useMk2 = ucfg.Workspace.UseWsmanagerMk2
-		}
-		return nil
-	})
-	if !useMk2 {
+	if !common.UseWsManagerMk2(cfg) {
 		return nil, nil
 	}
 
@@ -37,8 +29,14 @@ var Objects common.RenderFunc = func(cfg *common.RenderContext) ([]runtime.Objec
 				ContainerPort: RPCPort,
 				ServicePort:   RPCPort,
 			},
+			{
+				Name:          WebhookPortName,
+				ContainerPort: WebhookPort,
+				ServicePort:   WebhookServicePort,
+			},
 		}),
 		tlssecret,
+		webhook,
 		unprivilegedRolebinding,
 	)(cfg)
 }
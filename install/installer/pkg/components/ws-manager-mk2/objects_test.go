@@ -0,0 +1,62 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package wsmanagermk2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	"k8s.io/utils/pointer"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+	"github.com/gitpod-io/gitpod/installer/pkg/config/versions"
+)
+
+func renderContext(t *testing.T, manager config.WorkspaceManager) *common.RenderContext {
+	ctx, err := common.NewRenderContext(config.Config{
+		Domain: "gitpod.example.com",
+		ObjectStorage: config.ObjectStorage{
+			InCluster: pointer.Bool(true),
+		},
+		Workspace: config.Workspace{
+			Manager: manager,
+		},
+	}, versions.Manifest{}, "test_namespace")
+	require.NoError(t, err)
+	return ctx
+}
+
+func TestObjects_Classic(t *testing.T) {
+	objs, err := Objects(renderContext(t, config.WorkspaceManagerClassic))
+	require.NoError(t, err)
+	require.Empty(t, objs)
+}
+
+func TestWebhook(t *testing.T) {
+	// The Workspace CRD is only embedded by the leeway build, so the webhooks are rendered on their own
+	objs, err := webhook(renderContext(t, config.WorkspaceManagerMk2))
+	require.NoError(t, err)
+
+	var webhooks int
+	for _, o := range objs {
+		var clientConfig admissionregistrationv1.WebhookClientConfig
+		switch webhook := o.(type) {
+		case *admissionregistrationv1.MutatingWebhookConfiguration:
+			require.Equal(t, "test_namespace/ws-manager-mk2-webhook", webhook.Annotations["cert-manager.io/inject-ca-from"])
+			clientConfig = webhook.Webhooks[0].ClientConfig
+		case *admissionregistrationv1.ValidatingWebhookConfiguration:
+			require.Equal(t, "test_namespace/ws-manager-mk2-webhook", webhook.Annotations["cert-manager.io/inject-ca-from"])
+			clientConfig = webhook.Webhooks[0].ClientConfig
+		default:
+			continue
+		}
+		require.Equal(t, Component, clientConfig.Service.Name)
+		require.Equal(t, int32(WebhookServicePort), *clientConfig.Service.Port)
+		webhooks++
+	}
+	require.Equal(t, 2, webhooks)
+}
@@ -10,9 +10,6 @@ import (
 	"github.com/gitpod-io/gitpod/installer/pkg/common"
 	wsman "github.com/gitpod-io/gitpod/installer/pkg/components/ws-manager"
 
-	certmanagerv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
-	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -36,44 +33,8 @@ func tlssecret(ctx *common.RenderContext) ([]runtime.Object, error) {
 		Component,
 	}
 
-	issuer := common.CertManagerCAIssuer
-
 	return []runtime.Object{
-		&certmanagerv1.Certificate{
-			TypeMeta: common.TypeMetaCertificate,
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      TLSSecretNameSecret,
-				Namespace: ctx.Namespace,
-				Labels:    common.DefaultLabels(Component),
-			},
-			Spec: certmanagerv1.CertificateSpec{
-				Duration:   common.InternalCertDuration,
-				SecretName: TLSSecretNameSecret,
-				DNSNames:   serverAltNames,
-				IssuerRef: cmmeta.ObjectReference{
-					Name:  issuer,
-					Kind:  certmanagerv1.ClusterIssuerKind,
-					Group: "cert-manager.io",
-				},
-			},
-		},
-		&certmanagerv1.Certificate{
-			TypeMeta: common.TypeMetaCertificate,
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      Component,
-				Namespace: ctx.Namespace,
-				Labels:    common.DefaultLabels(Component),
-			},
-			Spec: certmanagerv1.CertificateSpec{
-				Duration:   common.InternalCertDuration,
-				SecretName: TLSSecretNameClient,
-				DNSNames:   clientAltNames,
-				IssuerRef: cmmeta.ObjectReference{
-					Name:  issuer,
-					Kind:  certmanagerv1.ClusterIssuerKind,
-					Group: "cert-manager.io",
-				},
-			},
-		},
+		common.InternalCertificate(ctx, Component, TLSSecretNameSecret, TLSSecretNameSecret, serverAltNames),
+		common.InternalCertificate(ctx, Component, Component, TLSSecretNameClient, clientAltNames),
 	}, nil
 }
@@ -0,0 +1,94 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package wsmanagermk2
+
+import (
+	"fmt"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+)
+
+const (
+	webhookCertificateName = "ws-manager-mk2-webhook"
+	mutatingWebhookName    = "mworkspace.ws-manager-mk2.gitpod.io"
+	validatingWebhookName  = "vworkspace.ws-manager-mk2.gitpod.io"
+)
+
+// webhook renders the admission webhooks of the workspace resources and the certificate they
+// are served with. The controller-runtime webhook server picks up the renewed certificate
+// without a restart.
+func webhook(ctx *common.RenderContext) ([]runtime.Object, error) {
+	// The webhook configurations are cluster-scoped, they need the namespace in their name
+	name := fmt.Sprintf("%s-%s", Component, ctx.Namespace)
+	annotations := common.WebhookCAInjection(ctx, webhookCertificateName)
+
+	rules := []admissionregistrationv1.RuleWithOperations{{
+		Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.Create, admissionregistrationv1.Update},
+		Rule: admissionregistrationv1.Rule{
+			APIGroups:   []string{"workspace.gitpod.io"},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"workspaces"},
+		},
+	}}
+	// Workspaces of other installations are not ours to admit
+	namespaceSelector := &metav1.LabelSelector{
+		MatchLabels: map[string]string{"kubernetes.io/metadata.name": ctx.Namespace},
+	}
+	clientConfig := func(path string) admissionregistrationv1.WebhookClientConfig {
+		return admissionregistrationv1.WebhookClientConfig{
+			Service: &admissionregistrationv1.ServiceReference{
+				Namespace: ctx.Namespace,
+				Name:      Component,
+				Path:      pointer.String(path),
+				Port:      pointer.Int32(WebhookServicePort),
+			},
+		}
+	}
+	failurePolicy := admissionregistrationv1.Fail
+	sideEffects := admissionregistrationv1.SideEffectClassNone
+
+	return []runtime.Object{
+		common.WebhookCertificate(ctx, Component, webhookCertificateName, WebhookTLSSecretName),
+		&admissionregistrationv1.MutatingWebhookConfiguration{
+			TypeMeta: common.TypeMetaMutatingWebhookConfiguration,
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Labels:      common.DefaultLabels(Component),
+				Annotations: annotations,
+			},
+			Webhooks: []admissionregistrationv1.MutatingWebhook{{
+				Name:                    mutatingWebhookName,
+				ClientConfig:            clientConfig("/mutate-workspace-gitpod-io-v1-workspace"),
+				Rules:                   rules,
+				NamespaceSelector:       namespaceSelector,
+				FailurePolicy:           &failurePolicy,
+				SideEffects:             &sideEffects,
+				AdmissionReviewVersions: []string{"v1"},
+			}},
+		},
+		&admissionregistrationv1.ValidatingWebhookConfiguration{
+			TypeMeta: common.TypeMetaValidatingWebhookConfiguration,
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Labels:      common.DefaultLabels(Component),
+				Annotations: annotations,
+			},
+			Webhooks: []admissionregistrationv1.ValidatingWebhook{{
+				Name:                    validatingWebhookName,
+				ClientConfig:            clientConfig("/validate-workspace-gitpod-io-v1-workspace"),
+				Rules:                   rules,
+				NamespaceSelector:       namespaceSelector,
+				FailurePolicy:           &failurePolicy,
+				SideEffects:             &sideEffects,
+				AdmissionReviewVersions: []string{"v1"},
+			}},
+		},
+	}, nil
+}
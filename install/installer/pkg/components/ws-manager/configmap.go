@@ -26,11 +26,7 @@ import (
 )
 
 func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
-	cfgTpls := ctx.Config.Workspace.Templates
-	if cfgTpls == nil {
-		cfgTpls = &configv1.WorkspaceTemplates{}
-	}
-	templatesCfg, tpls, err := buildWorkspaceTemplates(ctx, cfgTpls, "")
+	templatesCfg, tpls, err := buildWorkspaceTemplates(ctx, common.WorkspaceTemplates(ctx), "")
 	if err != nil {
 		return nil, err
 	}
@@ -43,9 +39,12 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 		return (&q).String()
 	}
 
-	timeoutAfterClose := util.Duration(2 * time.Minute)
-	if ctx.Config.Workspace.TimeoutAfterClose != nil {
-		timeoutAfterClose = *ctx.Config.Workspace.TimeoutAfterClose
+	timeoutAfterClose := durationOrDefault(ctx.Config.Workspace.TimeoutAfterClose, 2*time.Minute)
+
+	// the prebuilds are the headless workspaces
+	timeoutHeadless := util.Duration(1 * time.Hour)
+	if ctx.Config.Workspace.Prebuilds != nil && ctx.Config.Workspace.Prebuilds.Timeout != nil {
+		timeoutHeadless = *ctx.Config.Workspace.Prebuilds.Timeout
 	}
 
 	classes := map[string]*config.WorkspaceClass{
@@ -81,16 +80,47 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 		},
 	}
 
-	installationShortNameSuffix := ""
-	if ctx.Config.Metadata.InstallationShortname != "" && ctx.Config.Metadata.InstallationShortname != configv1.InstallationShortNameOldDefault {
-		installationShortNameSuffix = "-" + ctx.Config.Metadata.InstallationShortname
+	for _, c := range ctx.Config.Workspace.Classes {
+		tplsCfg, ctpls, err := buildWorkspaceTemplates(ctx, common.WorkspaceClassTemplates(ctx, c), c.Name)
+		if err != nil {
+			return nil, err
+		}
+		name := c.DisplayName
+		if name == "" {
+			name = c.Name
+		}
+		classes[c.Name] = &config.WorkspaceClass{
+			Name: name,
+			Container: config.ContainerConfiguration{
+				Requests: &config.ResourceRequestConfiguration{
+					CPU:              quantityString(c.Resources.Requests, corev1.ResourceCPU),
+					Memory:           quantityString(c.Resources.Requests, corev1.ResourceMemory),
+					EphemeralStorage: quantityString(c.Resources.Requests, corev1.ResourceEphemeralStorage),
+				},
+				Limits: &config.ResourceLimitConfiguration{
+					CPU: &config.CpuResourceLimit{
+						MinLimit:   quantityString(c.Resources.Limits, corev1.ResourceCPU),
+						BurstLimit: quantityString(c.Resources.Limits, corev1.ResourceCPU),
+					},
+					Memory:           quantityString(c.Resources.Limits, corev1.ResourceMemory),
+					EphemeralStorage: quantityString(c.Resources.Limits, corev1.ResourceEphemeralStorage),
+					Storage:          quantityString(c.Resources.Limits, corev1.ResourceStorage),
+				},
+			},
+			Templates:   tplsCfg,
+			PrebuildPVC: classes[config.DefaultWorkspaceClass].PrebuildPVC,
+			PVC:         classes[config.DefaultWorkspaceClass].PVC,
+		}
+		for tmpl_n, tmpl_v := range ctpls {
+			tpls[tmpl_n] = tmpl_v
+		}
 	}
 
 	var schedulerName string
 	gitpodHostURL := "https://" + ctx.Config.Domain
-	workspaceClusterHost := fmt.Sprintf("ws%s.%s", installationShortNameSuffix, ctx.Config.Domain)
-	workspaceURLTemplate := fmt.Sprintf("https://{{ .Prefix }}.ws%s.%s", installationShortNameSuffix, ctx.Config.Domain)
-	workspacePortURLTemplate := fmt.Sprintf("https://{{ .WorkspacePort }}-{{ .Prefix }}.ws%s.%s", installationShortNameSuffix, ctx.Config.Domain)
+	workspaceClusterHost := ctx.Config.WorkspaceDomain()
+	workspaceURLTemplate := fmt.Sprintf("https://{{ .Prefix }}.%s", workspaceClusterHost)
+	workspacePortURLTemplate := fmt.Sprintf("https://{{ .WorkspacePort }}-{{ .Prefix }}.%s", workspaceClusterHost)
 
 	rateLimits := map[string]grpc.RateLimit{}
 
@@ -194,9 +224,10 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 					Certificate: "/ws-daemon-tls-certs/tls.crt",
 					PrivateKey:  "/ws-daemon-tls-certs/tls.key",
 				},
+				GRPC: common.GRPCClientConfig(ctx),
 			},
 			WorkspaceClasses:     classes,
-			HeartbeatInterval:    util.Duration(30 * time.Second),
+			HeartbeatInterval:    durationOrDefault(ctx.Config.Workspace.HeartbeatInterval, 30*time.Second),
 			GitpodHostURL:        gitpodHostURL,
 			WorkspaceClusterHost: workspaceClusterHost,
 			InitProbe: config.InitProbeConfiguration{
@@ -207,11 +238,11 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 			WorkspaceHostPath:        wsdaemon.HostWorkingArea,
 			Timeouts: config.WorkspaceTimeoutConfiguration{
 				AfterClose:          timeoutAfterClose,
-				HeadlessWorkspace:   util.Duration(1 * time.Hour),
-				Initialization:      util.Duration(30 * time.Minute),
-				RegularWorkspace:    util.Duration(30 * time.Minute),
+				HeadlessWorkspace:   timeoutHeadless,
+				Initialization:      durationOrDefault(ctx.Config.Workspace.TimeoutInitialization, 30*time.Minute),
+				RegularWorkspace:    durationOrDefault(ctx.Config.Workspace.TimeoutDefault, 30*time.Minute),
 				MaxLifetime:         ctx.Config.Workspace.MaxLifetime,
-				TotalStartup:        util.Duration(1 * time.Hour),
+				TotalStartup:        durationOrDefault(ctx.Config.Workspace.TimeoutStartup, 1*time.Hour),
 				ContentFinalization: util.Duration(1 * time.Hour),
 				Stopping:            util.Duration(1 * time.Hour),
 				Interrupted:         util.Duration(5 * time.Minute),
@@ -263,6 +294,14 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 		}{Addr: common.LocalhostPrometheusAddr()},
 	}
 
+	if common.SeparateInfrastructureNamespace(ctx) {
+		wsmcfg.Manager.WorkspaceDaemon.Namespace = common.InfrastructureNamespace(ctx)
+	}
+
+	if common.SSHUserCASecretName(ctx) != "" {
+		wsmcfg.Manager.SSHUserCAPath = common.SSHUserCAPublicKeyPath
+	}
+
 	fc, err := common.ToJSONString(wsmcfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal ws-manager config: %w", err)
@@ -331,3 +370,11 @@ func buildWorkspaceTemplates(ctx *common.RenderContext, cfgTpls *configv1.Worksp
 
 	return cfg, tpls, nil
 }
+
+// durationOrDefault returns the configured duration, or the default if it is not set
+func durationOrDefault(d *util.Duration, def time.Duration) util.Duration {
+	if d == nil {
+		return util.Duration(def)
+	}
+	return *d
+}
@@ -7,12 +7,14 @@ package wsmanager
 import (
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/utils/pointer"
 
+	"github.com/gitpod-io/gitpod/common-go/util"
 	"github.com/gitpod-io/gitpod/installer/pkg/common"
 	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
 	"github.com/gitpod-io/gitpod/installer/pkg/config/versions"
@@ -154,6 +156,7 @@ func TestWorkspaceURLTemplates(t *testing.T) {
 		Name                             string
 		Domain                           string
 		InstallationShortname            string
+		WorkspaceDomain                  string
 		ExpectedWorkspaceUrlTemplate     string
 		ExpectedWorkspacePortURLTemplate string
 	}{
@@ -171,6 +174,14 @@ func TestWorkspaceURLTemplates(t *testing.T) {
 			ExpectedWorkspaceUrlTemplate:     "https://{{ .Prefix }}.ws.example.com",
 			ExpectedWorkspacePortURLTemplate: "https://{{ .WorkspacePort }}-{{ .Prefix }}.ws.example.com",
 		},
+		{
+			Name:                             "With a workspace domain",
+			Domain:                           "example.com",
+			InstallationShortname:            "eu02",
+			WorkspaceDomain:                  "eu.ws.example.com",
+			ExpectedWorkspaceUrlTemplate:     "https://{{ .Prefix }}.eu.ws.example.com",
+			ExpectedWorkspacePortURLTemplate: "https://{{ .WorkspacePort }}-{{ .Prefix }}.eu.ws.example.com",
+		},
 		{
 			Name:                             "With old default installation shortname for existing self-hosted installations",
 			Domain:                           "example.com",
@@ -190,6 +201,9 @@ func TestWorkspaceURLTemplates(t *testing.T) {
 				ObjectStorage: config.ObjectStorage{
 					InCluster: pointer.Bool(true),
 				},
+				Workspace: config.Workspace{
+					Domain: test.WorkspaceDomain,
+				},
 			}, versions.Manifest{}, "test_namespace")
 			require.NoError(t, err)
 
@@ -210,3 +224,82 @@ func TestWorkspaceURLTemplates(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigMap_PrebuildTimeout(t *testing.T) {
+	timeout := util.Duration(3 * time.Hour)
+	ctx, err := common.NewRenderContext(config.Config{
+		Domain:        "example.com",
+		ObjectStorage: config.ObjectStorage{InCluster: pointer.Bool(true)},
+		Workspace:     config.Workspace{Prebuilds: &config.WorkspacePrebuilds{Timeout: &timeout}},
+	}, versions.Manifest{}, "test_namespace")
+	require.NoError(t, err)
+
+	objs, err := configmap(ctx)
+	require.NoError(t, err)
+
+	var serviceConfig wsmancfg.ServiceConfiguration
+	require.NoError(t, json.Unmarshal([]byte(objs[0].(*corev1.ConfigMap).Data["config.json"]), &serviceConfig))
+	require.Equal(t, timeout, serviceConfig.Manager.Timeouts.HeadlessWorkspace)
+}
+
+func TestConfigMap_Timeouts(t *testing.T) {
+	var (
+		timeoutDefault = util.Duration(2 * time.Hour)
+		startup        = util.Duration(90 * time.Minute)
+		initialization = util.Duration(45 * time.Minute)
+		heartbeat      = util.Duration(time.Minute)
+	)
+	tests := []struct {
+		Name      string
+		Workspace config.Workspace
+		Expected  wsmancfg.WorkspaceTimeoutConfiguration
+		Heartbeat util.Duration
+	}{
+		{
+			Name: "defaults",
+			Expected: wsmancfg.WorkspaceTimeoutConfiguration{
+				RegularWorkspace: util.Duration(30 * time.Minute),
+				TotalStartup:     util.Duration(time.Hour),
+				Initialization:   util.Duration(30 * time.Minute),
+			},
+			Heartbeat: util.Duration(30 * time.Second),
+		},
+		{
+			Name: "configured",
+			Workspace: config.Workspace{
+				TimeoutDefault:        &timeoutDefault,
+				TimeoutStartup:        &startup,
+				TimeoutInitialization: &initialization,
+				HeartbeatInterval:     &heartbeat,
+			},
+			Expected: wsmancfg.WorkspaceTimeoutConfiguration{
+				RegularWorkspace: timeoutDefault,
+				TotalStartup:     startup,
+				Initialization:   initialization,
+			},
+			Heartbeat: heartbeat,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			ctx, err := common.NewRenderContext(config.Config{
+				Domain:        "example.com",
+				ObjectStorage: config.ObjectStorage{InCluster: pointer.Bool(true)},
+				Workspace:     test.Workspace,
+			}, versions.Manifest{}, "test_namespace")
+			require.NoError(t, err)
+
+			objs, err := configmap(ctx)
+			require.NoError(t, err)
+
+			var serviceConfig wsmancfg.ServiceConfiguration
+			require.NoError(t, json.Unmarshal([]byte(objs[0].(*corev1.ConfigMap).Data["config.json"]), &serviceConfig))
+			timeouts := serviceConfig.Manager.Timeouts
+			require.Equal(t, test.Expected.RegularWorkspace, timeouts.RegularWorkspace)
+			require.Equal(t, test.Expected.TotalStartup, timeouts.TotalStartup)
+			require.Equal(t, test.Expected.Initialization, timeouts.Initialization)
+			require.Equal(t, test.Heartbeat, serviceConfig.Manager.HeartbeatInterval)
+		})
+	}
+}
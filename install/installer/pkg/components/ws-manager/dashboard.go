@@ -0,0 +1,58 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package wsmanager
+
+import (
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func dashboard(ctx *common.RenderContext) ([]runtime.Object, error) {
+	return common.GenerateGrafanaDashboard(Component, []common.GrafanaPanel{
+		{
+			Title: "Workspaces",
+			Unit:  "short",
+			Queries: []common.GrafanaQuery{
+				{Expr: `sum(gitpod_ws_manager_workspace_phase_total{$selector}) by (type, phase)`, Legend: "{{type}} {{phase}}"},
+			},
+		},
+		{
+			Title: "Workspace starts",
+			Unit:  "ops",
+			Queries: []common.GrafanaQuery{
+				{Expr: `sum(rate(gitpod_ws_manager_workspace_startup_seconds_count{$selector}[5m])) by (type)`, Legend: "{{type}}"},
+			},
+		},
+		{
+			Title: "Workspace stops",
+			Unit:  "ops",
+			Queries: []common.GrafanaQuery{
+				{Expr: `sum(rate(gitpod_ws_manager_workspace_stops_total{$selector}[5m])) by (reason)`, Legend: "{{reason}}"},
+			},
+		},
+		{
+			Title: "Workspace startup time",
+			Unit:  "s",
+			Queries: []common.GrafanaQuery{
+				{Expr: `histogram_quantile(0.95, sum(rate(gitpod_ws_manager_workspace_startup_seconds_bucket{$selector}[5m])) by (type, le))`, Legend: "p95 {{type}}"},
+				{Expr: `histogram_quantile(0.5, sum(rate(gitpod_ws_manager_workspace_startup_seconds_bucket{$selector}[5m])) by (type, le))`, Legend: "p50 {{type}}"},
+			},
+		},
+		{
+			Title: "gRPC calls",
+			Unit:  "reqps",
+			Queries: []common.GrafanaQuery{
+				{Expr: `sum(rate(grpc_server_handled_total{$selector}[5m])) by (grpc_method, grpc_code)`, Legend: "{{grpc_method}} {{grpc_code}}"},
+			},
+		},
+		{
+			Title: "Goroutines",
+			Unit:  "short",
+			Queries: []common.GrafanaQuery{
+				{Expr: `go_goroutines{$selector}`, Legend: "{{pod}}"},
+			},
+		},
+	})(ctx)
+}
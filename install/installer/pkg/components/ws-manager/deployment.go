@@ -44,9 +44,14 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 		})
 	}
 
+	caVolumes, caVolumeMounts := common.SSHUserCAVolume(ctx)
+	volumes = append(volumes, caVolumes...)
+	volumeMounts = append(volumeMounts, caVolumeMounts...)
+
 	podSpec := corev1.PodSpec{
-		PriorityClassName:         common.SystemNodeCritical,
-		Affinity:                  cluster.WithNodeAffinityHostnameAntiAffinity(Component, cluster.AffinityLabelServices),
+		PriorityClassName:         common.PriorityClassName(ctx, Component, common.SystemNodeCritical),
+		Affinity:                  common.Affinity(ctx, Component, cluster.WithNodeAffinityHostnameAntiAffinity(Component, cluster.AffinityLabelServices)),
+		Tolerations:               common.Tolerations(ctx, Component, nil),
 		TopologySpreadConstraints: cluster.WithHostnameTopologySpread(Component),
 		EnableServiceLinks:        pointer.Bool(false),
 		ServiceAccountName:        Component,
@@ -77,6 +82,7 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 			Env: common.CustomizeEnvvar(ctx, Component, common.MergeEnv(
 				common.DefaultEnv(&ctx.Config),
 				common.WorkspaceTracingEnv(ctx, Component),
+				common.ConfigcatEnv(ctx),
 				[]corev1.EnvVar{{Name: "GRPC_GO_RETRY", Value: "on"}},
 			)),
 			VolumeMounts: append(
@@ -5,23 +5,50 @@
 package wsmanager
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
 	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
 )
 
-var Objects = common.CompositeRenderFunc(
-	configmap,
-	deployment,
-	networkpolicy,
-	role,
-	rolebinding,
-	common.DefaultServiceAccount(Component),
+var Objects common.RenderFunc = func(ctx *common.RenderContext) ([]runtime.Object, error) {
+	if ctx.Config.Workspace.Manager == config.WorkspaceManagerMk2 {
+		return replacedObjects(ctx)
+	}
+
+	return common.CompositeRenderFunc(
+		configmap,
+		deployment,
+		networkpolicy,
+		role,
+		rolebinding,
+		daemonNamespaceRole,
+		common.DefaultServiceAccount(Component),
+		common.GenerateService(Component, []common.ServicePort{
+			{
+				Name:          RPCPortName,
+				ContainerPort: RPCPort,
+				ServicePort:   RPCPort,
+			},
+		}),
+		tlssecret,
+		unprivilegedRolebinding,
+		dashboard,
+	)(ctx)
+}
+
+// replacedObjects are what is left of ws-manager when ws-manager-mk2 replaces it. The clients
+// keep their certificates and the service, which sends them to ws-manager-mk2 instead.
+var replacedObjects = common.CompositeRenderFunc(
 	common.GenerateService(Component, []common.ServicePort{
 		{
 			Name:          RPCPortName,
 			ContainerPort: RPCPort,
 			ServicePort:   RPCPort,
 		},
+	}, func(service *corev1.Service) {
+		service.Spec.Selector = common.DefaultLabels(common.WSManagerMk2Component)
 	}),
 	tlssecret,
-	unprivilegedRolebinding,
 )
@@ -0,0 +1,42 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package wsmanager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+	"github.com/gitpod-io/gitpod/installer/pkg/config/versions"
+)
+
+func TestObjects_Mk2(t *testing.T) {
+	ctx, err := common.NewRenderContext(config.Config{
+		Domain: "gitpod.example.com",
+		ObjectStorage: config.ObjectStorage{
+			InCluster: pointer.Bool(true),
+		},
+		Workspace: config.Workspace{
+			Manager: config.WorkspaceManagerMk2,
+		},
+	}, versions.Manifest{}, "test_namespace")
+	require.NoError(t, err)
+
+	objs, err := Objects(ctx)
+	require.NoError(t, err)
+
+	var kinds []string
+	for _, o := range objs {
+		kinds = append(kinds, o.GetObjectKind().GroupVersionKind().Kind)
+		if service, ok := o.(*corev1.Service); ok {
+			require.Equal(t, common.DefaultLabels(common.WSManagerMk2Component), service.Spec.Selector)
+		}
+	}
+	require.Equal(t, []string{"Service", "Certificate", "Certificate"}, kinds)
+}
@@ -117,3 +117,75 @@ func role(ctx *common.RenderContext) ([]runtime.Object, error) {
 		},
 	}, nil
 }
+
+// daemonNamespaceRole lets ws-manager find the ws-daemon pods if they run in the infrastructure
+// namespace. Its cache watches the same resources in both namespaces, so it needs to read them all.
+func daemonNamespaceRole(ctx *common.RenderContext) ([]runtime.Object, error) {
+	if !common.SeparateInfrastructureNamespace(ctx) {
+		return nil, nil
+	}
+
+	labels := common.DefaultLabels(Component)
+	namespace := common.InfrastructureNamespace(ctx)
+	return []runtime.Object{
+		&rbacv1.Role{
+			TypeMeta: common.TypeMetaRole,
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      Component,
+				Namespace: namespace,
+				Labels:    labels,
+			},
+			Rules: []rbacv1.PolicyRule{
+				{
+					APIGroups: []string{""},
+					Resources: []string{
+						"pods",
+						"pods/log",
+						"events",
+						"services",
+						"endpoints",
+						"configmaps",
+						"persistentvolumeclaims",
+						"secrets",
+					},
+					Verbs: []string{
+						"get",
+						"list",
+						"watch",
+					},
+				},
+				{
+					APIGroups: []string{"snapshot.storage.k8s.io"},
+					Resources: []string{
+						"volumesnapshots",
+					},
+					Verbs: []string{
+						"get",
+						"list",
+						"watch",
+					},
+				},
+			},
+		},
+		&rbacv1.RoleBinding{
+			TypeMeta: common.TypeMetaRoleBinding,
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      Component,
+				Namespace: namespace,
+				Labels:    labels,
+			},
+			RoleRef: rbacv1.RoleRef{
+				APIGroup: "rbac.authorization.k8s.io",
+				Kind:     "Role",
+				Name:     Component,
+			},
+			Subjects: []rbacv1.Subject{
+				{
+					Kind:      "ServiceAccount",
+					Name:      Component,
+					Namespace: ctx.Namespace,
+				},
+			},
+		},
+	}, nil
+}
@@ -6,12 +6,13 @@ package wsproxy
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/gitpod-io/gitpod/installer/pkg/components/workspace"
 	wsmanager "github.com/gitpod-io/gitpod/installer/pkg/components/ws-manager"
 	wsmanagermk2 "github.com/gitpod-io/gitpod/installer/pkg/components/ws-manager-mk2"
-	configv1 "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
 	"github.com/gitpod-io/gitpod/installer/pkg/config/v1/experimental"
 
 	"github.com/gitpod-io/gitpod/common-go/baseserver"
@@ -27,25 +28,24 @@ import (
 
 func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 	header := HostHeader
-	blobServeHost := fmt.Sprintf("ide.%s", ctx.Config.Domain)
+	blobServeHost := ctx.Config.IDEAssetsHost()
 	gitpodInstallationHostName := ctx.Config.Domain
 
-	installationShortNameSuffix := ""
-	if ctx.Config.Metadata.InstallationShortname != "" && ctx.Config.Metadata.InstallationShortname != configv1.InstallationShortNameOldDefault {
-		installationShortNameSuffix = "-" + ctx.Config.Metadata.InstallationShortname
-	}
-
-	gitpodInstallationWorkspaceHostSuffix := fmt.Sprintf(".ws%s.%s", installationShortNameSuffix, ctx.Config.Domain)
+	gitpodInstallationWorkspaceHostSuffix := "." + ctx.Config.WorkspaceDomain()
 	gitpodInstallationWorkspaceHostSuffixRegex := fmt.Sprintf("\\.ws[^\\.]*\\.%s", ctx.Config.Domain)
+	if domains := ctx.Config.WorkspaceDomains(); ctx.Config.Workspace.Domain != "" || len(domains) > 1 {
+		// the IDEs of the workspaces of all workspace domains are served
+		suffixes := []string{gitpodInstallationWorkspaceHostSuffixRegex}
+		for _, d := range domains {
+			suffixes = append(suffixes, "\\."+regexp.QuoteMeta(d))
+		}
+		gitpodInstallationWorkspaceHostSuffixRegex = "(?:" + strings.Join(suffixes, "|") + ")"
+	}
 
 	wsmanagerAddr := fmt.Sprintf("ws-manager:%d", wsmanager.RPCPort)
-	_ = ctx.WithExperimental(func(cfg *experimental.Config) error {
-		if cfg.Workspace == nil || !cfg.Workspace.UseWsmanagerMk2 {
-			return nil
-		}
+	if common.UseWsManagerMk2(ctx) {
 		wsmanagerAddr = fmt.Sprintf("ws-manager-mk2:%d", wsmanagermk2.RPCPort)
-		return nil
-	})
+	}
 
 	wsManagerConfig := &config.WorkspaceManagerConn{
 		Addr: wsmanagerAddr,
@@ -60,7 +60,7 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 		},
 	}
 
-	var enableWorkspaceCRD bool
+	enableWorkspaceCRD := common.UseWsManagerMk2(ctx)
 
 	ctx.WithExperimental(func(ucfg *experimental.Config) error {
 		if ucfg.Workspace == nil {
@@ -82,17 +82,41 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 			gitpodInstallationWorkspaceHostSuffixRegex = ucfg.Workspace.WSProxy.GitpodInstallationWorkspaceHostSuffixRegex
 		}
 
-		enableWorkspaceCRD = ucfg.Workspace.UseWsmanagerMk2
-
 		return nil
 	})
 
+	listenHost := "0.0.0.0"
+	if ctx.Config.Network != nil && ctx.Config.Network.IPFamilyPolicy != nil {
+		// Listen on all IPv4 and IPv6 addresses
+		listenHost = ""
+	}
+
+	var portPolicy *proxy.PortPolicy
+	if ports := ctx.Config.Workspace.Ports; ports != nil {
+		portPolicy = &proxy.PortPolicy{DisablePublic: ports.DisablePublic, Protocol: ports.DefaultProtocol}
+		for _, r := range ports.AllowedRanges {
+			portPolicy.AllowedRanges = append(portPolicy.AllowedRanges, proxy.PortRange{From: uint16(r.From), To: uint16(r.To)})
+		}
+	}
+
+	var serverTimeouts *proxy.ServerTimeouts
+	if ctx.Config.Components != nil && ctx.Config.Components.WSProxy != nil {
+		if t := ctx.Config.Components.WSProxy.Timeouts; t != nil {
+			serverTimeouts = &proxy.ServerTimeouts{
+				ReadHeader: durationOrZero(t.ReadHeader),
+				Read:       durationOrZero(t.Read),
+				Write:      durationOrZero(t.Write),
+				Idle:       durationOrZero(t.Idle),
+			}
+		}
+	}
+
 	// todo(sje): wsManagerProxy seems to be unused
 	wspcfg := config.Config{
 		Namespace: ctx.Namespace,
 		Ingress: proxy.HostBasedIngressConfig{
-			HTTPAddress:  fmt.Sprintf("0.0.0.0:%d", HTTPProxyPort),
-			HTTPSAddress: fmt.Sprintf("0.0.0.0:%d", HTTPSProxyPort),
+			HTTPAddress:  fmt.Sprintf("%s:%d", listenHost, HTTPProxyPort),
+			HTTPSAddress: fmt.Sprintf("%s:%d", listenHost, HTTPSProxyPort),
 			Header:       header,
 		},
 		Proxy: proxy.Config{
@@ -128,6 +152,8 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 				DebugWorkspaceProxyPort: workspace.DebugWorkspaceProxyPort,
 				SupervisorImage:         ctx.ImageName(ctx.Config.Repository, workspace.SupervisorImage, ctx.VersionManifest.Components.Workspace.Supervisor.Version),
 			},
+			PortPolicy:     portPolicy,
+			ServerTimeouts: serverTimeouts,
 			BuiltinPages: proxy.BuiltinPagesConfig{
 				Location: "/app/public",
 			},
@@ -138,6 +164,9 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 		WorkspaceManager:   wsManagerConfig,
 		EnableWorkspaceCRD: enableWorkspaceCRD,
 	}
+	if common.SSHUserCASecretName(ctx) != "" {
+		wspcfg.SSHUserCA = common.SSHUserCAPublicKeyPath
+	}
 
 	fc, err := common.ToJSONString(wspcfg)
 	if err != nil {
@@ -159,3 +188,11 @@ func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
 		},
 	}, nil
 }
+
+// durationOrZero returns the duration, ws-proxy reads a zero duration as unset
+func durationOrZero(d *util.Duration) util.Duration {
+	if d == nil {
+		return 0
+	}
+	return *d
+}
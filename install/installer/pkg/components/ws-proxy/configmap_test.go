@@ -0,0 +1,83 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package wsproxy
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/gitpod-io/gitpod/common-go/util"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+	rendertest "github.com/gitpod-io/gitpod/installer/pkg/testing"
+	wsproxyconfig "github.com/gitpod-io/gitpod/ws-proxy/pkg/config"
+	"github.com/gitpod-io/gitpod/ws-proxy/pkg/proxy"
+)
+
+func renderConfig(t *testing.T, cfg config.Config) wsproxyconfig.Config {
+	objs := rendertest.Render(t, configmap, cfg)
+
+	var res wsproxyconfig.Config
+	require.NoError(t, json.Unmarshal([]byte(objs[0].(*corev1.ConfigMap).Data["config.json"]), &res))
+	return res
+}
+
+func TestConfigMap_PortPolicy(t *testing.T) {
+	require.Nil(t, renderConfig(t, config.Config{}).Proxy.PortPolicy)
+
+	cfg := renderConfig(t, config.Config{
+		Workspace: config.Workspace{
+			Ports: &config.WorkspacePorts{
+				DisablePublic:   true,
+				AllowedRanges:   []config.WorkspacePortRange{{From: 3000, To: 3999}, {From: 8080, To: 8080}},
+				DefaultProtocol: "https",
+			},
+		},
+	})
+	require.Equal(t, &proxy.PortPolicy{
+		DisablePublic: true,
+		AllowedRanges: []proxy.PortRange{{From: 3000, To: 3999}, {From: 8080, To: 8080}},
+		Protocol:      "https",
+	}, cfg.Proxy.PortPolicy)
+}
+
+func TestConfigMap_ServerTimeouts(t *testing.T) {
+	require.Nil(t, renderConfig(t, config.Config{}).Proxy.ServerTimeouts)
+
+	idle := util.Duration(10 * time.Minute)
+	cfg := renderConfig(t, config.Config{
+		Components: &config.Components{
+			WSProxy: &config.WSProxyComponent{
+				Timeouts: &config.ServerTimeouts{Idle: &idle},
+			},
+		},
+	})
+	require.Equal(t, &proxy.ServerTimeouts{Idle: idle}, cfg.Proxy.ServerTimeouts)
+}
+
+func TestConfigMap_WorkspaceDomains(t *testing.T) {
+	cfg := renderConfig(t, config.Config{Domain: "example.com"}).Proxy.GitpodInstallation
+	require.Equal(t, ".ws.example.com", cfg.WorkspaceHostSuffix)
+	require.Equal(t, "\\.ws[^\\.]*\\.example.com", cfg.WorkspaceHostSuffixRegex)
+
+	cfg = renderConfig(t, config.Config{
+		Domain: "example.com",
+		Workspace: config.Workspace{
+			Domain:            "eu.ws.example.com",
+			AdditionalDomains: []string{"us.ws.example.com"},
+		},
+	}).Proxy.GitpodInstallation
+	require.Equal(t, ".eu.ws.example.com", cfg.WorkspaceHostSuffix)
+
+	suffix := regexp.MustCompile("^[a-z0-9-]+" + cfg.WorkspaceHostSuffixRegex + "$")
+	for _, host := range []string{"id.ws.example.com", "id.ws-eu02.example.com", "id.eu.ws.example.com", "id.us.ws.example.com"} {
+		require.Truef(t, suffix.MatchString(host), "must serve %s", host)
+	}
+	require.False(t, suffix.MatchString("id.ap.ws.example.com"))
+}
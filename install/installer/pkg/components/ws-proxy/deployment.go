@@ -33,7 +33,7 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 			Name: "config-certificates",
 			VolumeSource: corev1.VolumeSource{
 				Secret: &corev1.SecretVolumeSource{
-					SecretName: ctx.Config.Certificate.Name,
+					SecretName: common.WorkspaceCertificate(ctx).Name,
 				},
 			},
 		},
@@ -45,12 +45,12 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 			MountPath: "/mnt/certificates"},
 	}
 
-	if ctx.Config.SSHGatewayHostKey != nil {
+	if hostKey := ctx.Config.SSHGatewayHostKeySecret(); hostKey != nil {
 		volumes = append(volumes, corev1.Volume{
 			Name: "host-key",
 			VolumeSource: corev1.VolumeSource{
 				Secret: &corev1.SecretVolumeSource{
-					SecretName: ctx.Config.SSHGatewayHostKey.Name,
+					SecretName: hostKey.Name,
 				},
 			},
 		})
@@ -61,9 +61,14 @@ func deployment(ctx *common.RenderContext) ([]runtime.Object, error) {
 		})
 	}
 
+	caVolumes, caVolumeMounts := common.SSHUserCAVolume(ctx)
+	volumes = append(volumes, caVolumes...)
+	volumeMounts = append(volumeMounts, caVolumeMounts...)
+
 	podSpec := corev1.PodSpec{
-		PriorityClassName:         common.SystemNodeCritical,
-		Affinity:                  cluster.WithNodeAffinityHostnameAntiAffinity(Component, cluster.AffinityLabelServices),
+		PriorityClassName:         common.PriorityClassName(ctx, Component, common.SystemNodeCritical),
+		Affinity:                  common.Affinity(ctx, Component, cluster.WithNodeAffinityHostnameAntiAffinity(Component, cluster.AffinityLabelServices)),
+		Tolerations:               common.Tolerations(ctx, Component, nil),
 		TopologySpreadConstraints: cluster.WithHostnameTopologySpread(Component),
 		EnableServiceLinks:        pointer.Bool(false),
 		ServiceAccountName:        Component,
@@ -6,6 +6,7 @@ package wsproxy
 
 import (
 	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	"github.com/gitpod-io/gitpod/installer/pkg/components/workspace"
 
 	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -25,7 +26,8 @@ func networkpolicy(ctx *common.RenderContext) ([]runtime.Object, error) {
 		},
 		Spec: networkingv1.NetworkPolicySpec{
 			PodSelector: metav1.LabelSelector{MatchLabels: labels},
-			PolicyTypes: []networkingv1.PolicyType{"Ingress"},
+			PolicyTypes: common.NetworkPolicyTypes(ctx),
+			Egress:      common.NetworkPolicyEgress(ctx, egressRules(ctx)...),
 			Ingress: []networkingv1.NetworkPolicyIngressRule{{
 				Ports: []networkingv1.NetworkPolicyPort{
 					{
@@ -43,3 +45,15 @@ func networkpolicy(ctx *common.RenderContext) ([]runtime.Object, error) {
 		},
 	}}, nil
 }
+
+func egressRules(ctx *common.RenderContext) []networkingv1.NetworkPolicyEgressRule {
+	rules := []networkingv1.NetworkPolicyEgressRule{
+		common.AllowKubeAPIEgressRule(),
+		common.AllowComponentsEgressRule(ctx, common.WSManagerComponent, common.WSManagerMk2Component, workspace.Component),
+	}
+	if ctx.Config.Components != nil && ctx.Config.Components.IDEProxy != nil && ctx.Config.Components.IDEProxy.CDN != nil {
+		// blobserve is reached through the CDN
+		rules = append(rules, common.AllowExternalEgressRule())
+	}
+	return rules
+}
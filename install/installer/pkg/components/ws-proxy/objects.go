@@ -7,12 +7,15 @@ package wsproxy
 import (
 	"github.com/gitpod-io/gitpod/common-go/baseserver"
 	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
 var Objects = common.CompositeRenderFunc(
 	configmap,
 	deployment,
+	common.GenerateHorizontalPodAutoscaler(Component),
+	common.GeneratePodDisruptionBudget(Component),
 	networkpolicy,
 	rolebinding,
 	role,
@@ -39,7 +42,12 @@ var Objects = common.CompositeRenderFunc(
 				ServicePort:   SSHServicePort,
 			},
 		}
-		return common.GenerateService(Component, ports)(cfg)
+		var serviceConfig *config.ComponentTypeService
+		if cfg.Config.Components != nil && cfg.Config.Components.WSProxy != nil {
+			serviceConfig = cfg.Config.Components.WSProxy.Service
+		}
+		return common.GenerateService(Component, ports, common.WithServiceConfig(serviceConfig))(cfg)
 	},
 	common.DefaultServiceAccount(Component),
+	common.SSHUserCAObjects,
 )
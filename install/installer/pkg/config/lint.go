@@ -0,0 +1,103 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package config
+
+import (
+	"fmt"
+	"sort"
+)
+
+// LintSeverity is how risky a setting the linter flags is
+type LintSeverity string
+
+const (
+	LintSeverityError   LintSeverity = "error"
+	LintSeverityWarning LintSeverity = "warning"
+	LintSeverityInfo    LintSeverity = "info"
+)
+
+var lintSeverityRank = map[LintSeverity]int{
+	LintSeverityError:   3,
+	LintSeverityWarning: 2,
+	LintSeverityInfo:    1,
+}
+
+// ParseLintSeverity returns the severity of the name
+func ParseLintSeverity(name string) (LintSeverity, error) {
+	s := LintSeverity(name)
+	if _, ok := lintSeverityRank[s]; !ok {
+		return "", fmt.Errorf("unknown severity %s, must be one of %s, %s or %s", name, LintSeverityError, LintSeverityWarning, LintSeverityInfo)
+	}
+	return s, nil
+}
+
+// AtLeast returns whether the severity is the same as or higher than the other
+func (s LintSeverity) AtLeast(other LintSeverity) bool {
+	return lintSeverityRank[s] >= lintSeverityRank[other]
+}
+
+// LintFinding is a setting of the config that is valid, but risky or deprecated
+type LintFinding struct {
+	// Rule identifies the check that flagged the setting, e.g. in-cluster-database
+	Rule     string       `json:"rule"`
+	Severity LintSeverity `json:"severity"`
+	// Field is the path of the setting in the config file
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type LintResult struct {
+	Findings []LintFinding `json:"findings"`
+}
+
+// Count returns the number of findings of the severity or higher
+func (r *LintResult) Count(min LintSeverity) int {
+	var res int
+	for _, f := range r.Findings {
+		if f.Severity.AtLeast(min) {
+			res++
+		}
+	}
+	return res
+}
+
+// Lint flags the risky settings of the config and the deprecated ones it uses, the most severe
+// findings first
+func Lint(version ConfigVersion, cfg interface{}) *LintResult {
+	res := &LintResult{Findings: version.Lint(cfg)}
+
+	deprecated, conflicts := version.CheckDeprecated(cfg)
+	for field := range deprecated {
+		res.Findings = append(res.Findings, LintFinding{
+			Rule:     "deprecated",
+			Severity: LintSeverityWarning,
+			Field:    field,
+			Message:  "The field is deprecated and will be removed, move the setting to the field that replaces it",
+		})
+	}
+	for _, c := range conflicts {
+		res.Findings = append(res.Findings, LintFinding{
+			Rule:     "deprecated-conflict",
+			Severity: LintSeverityError,
+			Message:  c,
+		})
+	}
+
+	sort.SliceStable(res.Findings, func(i, j int) bool {
+		fi, fj := res.Findings[i], res.Findings[j]
+		if fi.Severity != fj.Severity {
+			return fi.Severity.AtLeast(fj.Severity)
+		}
+		if fi.Field != fj.Field {
+			return fi.Field < fj.Field
+		}
+		return fi.Rule < fj.Rule
+	})
+	if res.Findings == nil {
+		res.Findings = []LintFinding{}
+	}
+
+	return res
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type lintTestVersion struct {
+	schemaTestVersion
+}
+
+func (lintTestVersion) Lint(interface{}) []LintFinding {
+	return []LintFinding{
+		{Rule: "in-cluster-registry", Severity: LintSeverityInfo, Field: "containerRegistry.inCluster"},
+		{Rule: "in-cluster-database", Severity: LintSeverityWarning, Field: "database.inCluster"},
+	}
+}
+
+func (lintTestVersion) CheckDeprecated(interface{}) (map[string]interface{}, []string) {
+	return map[string]interface{}{"experimental.agentSmith": true}, []string{"cannot configure agent smith in both components and experimental"}
+}
+
+func TestLint(t *testing.T) {
+	res := Lint(lintTestVersion{}, &schemaTestConfig{})
+
+	var rules []string
+	for _, f := range res.Findings {
+		rules = append(rules, f.Rule)
+	}
+	require.Equal(t, []string{"deprecated-conflict", "in-cluster-database", "deprecated", "in-cluster-registry"}, rules)
+
+	require.Equal(t, 1, res.Count(LintSeverityError))
+	require.Equal(t, 3, res.Count(LintSeverityWarning))
+	require.Equal(t, 4, res.Count(LintSeverityInfo))
+}
+
+func TestParseLintSeverity(t *testing.T) {
+	s, err := ParseLintSeverity("warning")
+	require.NoError(t, err)
+	require.Equal(t, LintSeverityWarning, s)
+
+	_, err = ParseLintSeverity("critical")
+	require.Error(t, err)
+}
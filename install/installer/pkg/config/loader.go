@@ -6,6 +6,7 @@ package config
 
 import (
 	"fmt"
+	"io/fs"
 
 	"github.com/gitpod-io/gitpod/installer/pkg/cluster"
 	"github.com/gitpod-io/gitpod/installer/pkg/yq"
@@ -51,6 +52,13 @@ type ConfigVersion interface {
 	// CheckDeprecated checks for deprecated config params.
 	// Returns key/value pair of deprecated params/values and any error messages (used for conflicting params)
 	CheckDeprecated(cfg interface{}) (map[string]interface{}, []string)
+
+	// Lint flags the settings that are valid, but risky for a production installation
+	Lint(cfg interface{}) []LintFinding
+
+	// Sources provides the Go sources of the config structs by their package path.
+	// The doc comments of the fields describe them in the schema.
+	Sources() map[string]fs.FS
 }
 
 // AddVersion adds a new version.
@@ -0,0 +1,160 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Migration upgrades a config from one API version to the next. It works on the
+// raw config as fields that no longer exist cannot be unmarshalled into the new
+// version's struct.
+type Migration struct {
+	From string
+	To   string
+	// Migrate modifies the config in place and returns the changes made
+	Migrate func(cfg map[string]interface{}) ([]MigrationChange, error)
+}
+
+type MigrationChangeType string
+
+const (
+	MigrationChangeRemoved MigrationChangeType = "removed"
+	MigrationChangeRenamed MigrationChangeType = "renamed"
+)
+
+// MigrationChange describes a single change to the config made by a migration
+type MigrationChange struct {
+	Type     MigrationChangeType `json:"type"`
+	Field    string              `json:"field"`
+	NewField string              `json:"newField,omitempty"`
+}
+
+func (c MigrationChange) String() string {
+	if c.Type == MigrationChangeRenamed {
+		return fmt.Sprintf("%s: %s -> %s", c.Type, c.Field, c.NewField)
+	}
+	return fmt.Sprintf("%s: %s", c.Type, c.Field)
+}
+
+// MigrationResult is the migrated config and the changes made to it
+type MigrationResult struct {
+	FromVersion string
+	Version     string
+	Config      map[string]interface{}
+	Changes     []MigrationChange
+}
+
+var migrations map[string]Migration
+
+// AddMigration adds a migration from one version to the next.
+// Expected to be called from the init package of a config package.
+func AddMigration(m Migration) {
+	if migrations == nil {
+		migrations = make(map[string]Migration)
+	}
+	migrations[m.From] = m
+}
+
+// Migrate upgrades the config to the CurrentVersion by running every migration
+// between the config's version and the CurrentVersion in order
+func Migrate(rawConfig string) (*MigrationResult, error) {
+	var cfg map[string]interface{}
+	if err := yaml.Unmarshal([]byte(rawConfig), &cfg); err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		cfg = make(map[string]interface{})
+	}
+
+	version := CurrentVersion
+	if v, ok := cfg["apiVersion"].(string); ok && v != "" {
+		version = v
+	}
+	delete(cfg, "apiVersion")
+
+	res := &MigrationResult{
+		FromVersion: version,
+		Config:      cfg,
+	}
+
+	// Protect against migrations that form a loop
+	for i := 0; version != CurrentVersion; i++ {
+		m, ok := migrations[version]
+		if !ok || i > len(migrations) {
+			return nil, fmt.Errorf("cannot migrate config from %s to %s: no migration found for %s", res.FromVersion, CurrentVersion, version)
+		}
+
+		changes, err := m.Migrate(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("cannot migrate config from %s to %s: %w", m.From, m.To, err)
+		}
+		res.Changes = append(res.Changes, changes...)
+		version = m.To
+	}
+	res.Version = version
+
+	return res, nil
+}
+
+// RenameField moves the value at the dotted path from to the dotted path to
+func RenameField(cfg map[string]interface{}, from, to string) ([]MigrationChange, error) {
+	val, ok := removeField(cfg, strings.Split(from, "."))
+	if !ok {
+		return nil, nil
+	}
+
+	toPath := strings.Split(to, ".")
+	parent := cfg
+	for _, key := range toPath[:len(toPath)-1] {
+		next, ok := parent[key]
+		if !ok {
+			next = make(map[string]interface{})
+			parent[key] = next
+		}
+		nextMap, ok := next.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("cannot rename %s to %s: %s is not an object", from, to, key)
+		}
+		parent = nextMap
+	}
+
+	last := toPath[len(toPath)-1]
+	if _, exists := parent[last]; exists {
+		return nil, fmt.Errorf("cannot rename %s to %s: both are set", from, to)
+	}
+	parent[last] = val
+
+	return []MigrationChange{{Type: MigrationChangeRenamed, Field: from, NewField: to}}, nil
+}
+
+// RemoveField removes the value at the dotted path
+func RemoveField(cfg map[string]interface{}, path string) []MigrationChange {
+	if _, ok := removeField(cfg, strings.Split(path, ".")); !ok {
+		return nil
+	}
+	return []MigrationChange{{Type: MigrationChangeRemoved, Field: path}}
+}
+
+func removeField(cfg map[string]interface{}, path []string) (interface{}, bool) {
+	parent := cfg
+	for _, key := range path[:len(path)-1] {
+		next, ok := parent[key].(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		parent = next
+	}
+
+	last := path[len(path)-1]
+	val, ok := parent[last]
+	if ok {
+		delete(parent, last)
+	}
+	return val, ok
+}
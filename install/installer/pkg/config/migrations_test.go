@@ -0,0 +1,64 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrate(t *testing.T) {
+	original := migrations
+	t.Cleanup(func() { migrations = original })
+
+	migrations = nil
+	AddMigration(Migration{
+		From: "v0",
+		To:   CurrentVersion,
+		Migrate: func(cfg map[string]interface{}) ([]MigrationChange, error) {
+			changes, err := RenameField(cfg, "experimental.common.podConfig", "components.podConfig")
+			if err != nil {
+				return nil, err
+			}
+			return append(changes, RemoveField(cfg, "installPodSecurityPolicies")...), nil
+		},
+	})
+
+	res, err := Migrate(`apiVersion: v0
+domain: gitpod.example.com
+installPodSecurityPolicies: true
+experimental:
+  common:
+    podConfig:
+      server:
+        replicas: 2
+`)
+	require.NoError(t, err)
+	require.Equal(t, "v0", res.FromVersion)
+	require.Equal(t, CurrentVersion, res.Version)
+	require.Equal(t, []MigrationChange{
+		{Type: MigrationChangeRenamed, Field: "experimental.common.podConfig", NewField: "components.podConfig"},
+		{Type: MigrationChangeRemoved, Field: "installPodSecurityPolicies"},
+	}, res.Changes)
+	require.Equal(t, map[string]interface{}{
+		"domain": "gitpod.example.com",
+		"experimental": map[string]interface{}{
+			"common": map[string]interface{}{},
+		},
+		"components": map[string]interface{}{
+			"podConfig": map[string]interface{}{
+				"server": map[string]interface{}{"replicas": float64(2)},
+			},
+		},
+	}, res.Config)
+
+	_, err = Migrate("apiVersion: v9\n")
+	require.Error(t, err, "unknown versions must not be migrated")
+
+	res, err = Migrate("domain: gitpod.example.com\n")
+	require.NoError(t, err)
+	require.Empty(t, res.Changes, "configs without a version are at the current version")
+}
@@ -0,0 +1,299 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const schemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// Schema returns the JSON Schema of the config version. The fields are described by their doc
+// comments and the defaults are the values of a config that has nothing overridden.
+func Schema(version string) (map[string]interface{}, error) {
+	v, err := LoadConfigVersion(version)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := v.Factory()
+	err = v.Defaults(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	docs, err := parseFieldDocs(v.Sources())
+	if err != nil {
+		return nil, err
+	}
+
+	g := &schemaGenerator{
+		docs: docs,
+		defs: make(map[string]map[string]interface{}),
+	}
+	for pkg := range v.Sources() {
+		g.local = append(g.local, pkg)
+	}
+
+	res := g.schema(reflect.TypeOf(cfg), reflect.ValueOf(cfg))
+	res["$schema"] = schemaDraft
+	res["title"] = fmt.Sprintf("Gitpod installer config %s", version)
+	res["properties"].(map[string]interface{})["apiVersion"] = map[string]interface{}{
+		"description": "API version of the config",
+		"const":       version,
+	}
+	if len(g.defs) > 0 {
+		res["$defs"] = g.defs
+	}
+
+	return res, nil
+}
+
+// parseFieldDocs returns the doc comments of the struct fields in the sources,
+// keyed by the package path, the name of the struct and the name of the field
+func parseFieldDocs(sources map[string]fs.FS) (map[string]string, error) {
+	res := make(map[string]string)
+	fset := token.NewFileSet()
+	for pkg, src := range sources {
+		fns, err := fs.Glob(src, "*.go")
+		if err != nil {
+			return nil, err
+		}
+		for _, fn := range fns {
+			fc, err := fs.ReadFile(src, fn)
+			if err != nil {
+				return nil, err
+			}
+			f, err := parser.ParseFile(fset, fn, fc, parser.ParseComments)
+			if err != nil {
+				return nil, err
+			}
+
+			ast.Inspect(f, func(n ast.Node) bool {
+				ts, ok := n.(*ast.TypeSpec)
+				if !ok {
+					return true
+				}
+				st, ok := ts.Type.(*ast.StructType)
+				if !ok {
+					return true
+				}
+				for _, field := range st.Fields.List {
+					doc := strings.Join(strings.Fields(field.Doc.Text()), " ")
+					if doc == "" {
+						continue
+					}
+					for _, name := range field.Names {
+						res[fmt.Sprintf("%s.%s.%s", pkg, ts.Name.Name, name.Name)] = doc
+					}
+				}
+				return true
+			})
+		}
+	}
+	return res, nil
+}
+
+type schemaGenerator struct {
+	// local are the packages of the config, their structs are inlined so the defaults can be
+	// set on each field. The structs of other packages are referenced from the definitions.
+	local []string
+	docs  map[string]string
+	defs  map[string]map[string]interface{}
+}
+
+func (g *schemaGenerator) isLocal(t reflect.Type) bool {
+	if t.Name() == "" {
+		return true
+	}
+	for _, pkg := range g.local {
+		if t.PkgPath() == pkg {
+			return true
+		}
+	}
+	return false
+}
+
+// schema returns the schema of the type. The value is the default, it is invalid if there is none.
+func (g *schemaGenerator) schema(t reflect.Type, v reflect.Value) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		if v.IsValid() && !v.IsNil() {
+			v = v.Elem()
+		} else {
+			v = reflect.Value{}
+		}
+		t = t.Elem()
+	}
+
+	res := g.typeSchema(t, v)
+	if v.IsValid() && !v.IsZero() && !(t.Kind() == reflect.Struct && g.isLocal(t)) {
+		var dflt interface{}
+		if fc, err := json.Marshal(v.Interface()); err == nil && json.Unmarshal(fc, &dflt) == nil {
+			res["default"] = dflt
+		}
+	}
+	return res
+}
+
+func (g *schemaGenerator) typeSchema(t reflect.Type, v reflect.Value) map[string]interface{} {
+	switch t {
+	case reflect.TypeOf(resource.Quantity{}):
+		return map[string]interface{}{"type": []string{"string", "number"}}
+	case reflect.TypeOf(intstr.IntOrString{}):
+		return map[string]interface{}{"type": []string{"integer", "string"}}
+	case reflect.TypeOf(runtime.RawExtension{}):
+		return map[string]interface{}{}
+	}
+	if t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType) {
+		// e.g. durations and timestamps
+		return map[string]interface{}{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]interface{}{"type": "array", "items": g.schema(t.Elem(), reflect.Value{})}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.schema(t.Elem(), reflect.Value{})}
+	case reflect.Struct:
+		if g.isLocal(t) {
+			return g.structSchema(t, v)
+		}
+
+		name := strings.ReplaceAll(t.PkgPath(), "/", ".") + "." + t.Name()
+		if _, ok := g.defs[name]; !ok {
+			// set before the fields are added in case the struct refers to itself
+			g.defs[name] = map[string]interface{}{}
+			for k, s := range g.structSchema(t, reflect.Value{}) {
+				g.defs[name][k] = s
+			}
+		}
+		return map[string]interface{}{"$ref": "#/$defs/" + name}
+	}
+
+	return map[string]interface{}{}
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type, v reflect.Value) map[string]interface{} {
+	props := make(map[string]interface{})
+	var required []string
+
+	var addFields func(t reflect.Type, v reflect.Value)
+	addFields = func(t reflect.Type, v reflect.Value) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			var fv reflect.Value
+			if v.IsValid() {
+				fv = v.Field(i)
+			}
+
+			name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if field.Anonymous && name == "" {
+				ft := field.Type
+				if ft.Kind() == reflect.Ptr {
+					ft = ft.Elem()
+					if fv.IsValid() && !fv.IsNil() {
+						fv = fv.Elem()
+					} else {
+						fv = reflect.Value{}
+					}
+				}
+				if ft.Kind() == reflect.Struct {
+					addFields(ft, fv)
+					continue
+				}
+			}
+			if !field.IsExported() {
+				continue
+			}
+			if opts == "inline" && field.Type.Kind() == reflect.Struct {
+				addFields(field.Type, fv)
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+
+			prop := g.schema(field.Type, fv)
+			if doc, ok := g.docs[fmt.Sprintf("%s.%s.%s", t.PkgPath(), t.Name(), field.Name)]; ok {
+				prop["description"] = doc
+			}
+			for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+				if rule == "dive" {
+					// the rules that follow are for the elements
+					break
+				}
+				if rule == "required" && (!fv.IsValid() || fv.IsZero()) {
+					// the defaults are set when the config is loaded
+					required = append(required, name)
+				}
+				if strings.HasPrefix(rule, "oneof=") {
+					prop["enum"] = enumValues(field.Type, strings.Fields(strings.TrimPrefix(rule, "oneof=")))
+				}
+			}
+			props[name] = prop
+		}
+	}
+	addFields(t, v)
+
+	res := map[string]interface{}{
+		"type":       "object",
+		"properties": props,
+	}
+	if len(required) > 0 {
+		res["required"] = required
+	}
+	return res
+}
+
+// enumValues returns the values of a oneof validation as the type of the field
+func enumValues(t reflect.Type, values []string) []interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	res := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+				res = append(res, i)
+				continue
+			}
+		}
+		res = append(res, value)
+	}
+	return res
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package config
+
+import (
+	"io/fs"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/cluster"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+type schemaTestConfig struct {
+	// Domain is where it runs
+	Domain   string            `json:"domain" validate:"required"`
+	Kind     string            `json:"kind" validate:"required,oneof=Full Meta"`
+	Replicas *int32            `json:"replicas,omitempty"`
+	Storage  resource.Quantity `json:"storage"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Ignored  string            `json:"-"`
+}
+
+type schemaTestVersion struct{}
+
+func (schemaTestVersion) Factory() interface{} { return &schemaTestConfig{} }
+func (schemaTestVersion) Defaults(in interface{}) error {
+	in.(*schemaTestConfig).Kind = "Full"
+	return nil
+}
+func (schemaTestVersion) LoadValidationFuncs(*validator.Validate) error { return nil }
+func (schemaTestVersion) ClusterValidation(interface{}) cluster.ValidationChecks {
+	return nil
+}
+func (schemaTestVersion) CheckDeprecated(interface{}) (map[string]interface{}, []string) {
+	return nil, nil
+}
+func (schemaTestVersion) Lint(interface{}) []LintFinding { return nil }
+func (schemaTestVersion) Sources() map[string]fs.FS {
+	return map[string]fs.FS{
+		reflect.TypeOf(schemaTestConfig{}).PkgPath(): fstest.MapFS{
+			"config.go": {Data: []byte(`package config
+
+type schemaTestConfig struct {
+	// Domain is where it runs
+	Domain string
+}
+`)},
+		},
+	}
+}
+
+func TestSchema(t *testing.T) {
+	AddVersion("schema-test", schemaTestVersion{})
+	t.Cleanup(func() { delete(versions, "schema-test") })
+
+	res, err := Schema("schema-test")
+	require.NoError(t, err)
+
+	require.Equal(t, schemaDraft, res["$schema"])
+	require.Equal(t, []string{"domain"}, res["required"], "fields with a default are not required")
+
+	props := res["properties"].(map[string]interface{})
+	require.Equal(t, map[string]interface{}{"const": "schema-test", "description": "API version of the config"}, props["apiVersion"])
+	require.Equal(t, map[string]interface{}{"type": "string", "description": "Domain is where it runs"}, props["domain"])
+	require.Equal(t, map[string]interface{}{"type": "string", "default": "Full", "enum": []interface{}{"Full", "Meta"}}, props["kind"])
+	require.Equal(t, map[string]interface{}{"type": "integer"}, props["replicas"])
+	require.Equal(t, map[string]interface{}{"type": []string{"string", "number"}}, props["storage"])
+	require.Equal(t, map[string]interface{}{"type": "object", "additionalProperties": map[string]interface{}{"type": "string"}}, props["labels"])
+	require.NotContains(t, props, "Ignored")
+}
@@ -5,8 +5,18 @@
 package config
 
 import (
+	"crypto/rsa"
+	"crypto/x509"
+	"embed"
+	"encoding/pem"
+	"fmt"
+	"io/fs"
+	"net"
+	"reflect"
+	"strings"
 	"time"
 
+	"github.com/Masterminds/semver"
 	agentSmith "github.com/gitpod-io/gitpod/agent-smith/pkg/config"
 	"github.com/gitpod-io/gitpod/common-go/util"
 	"github.com/gitpod-io/gitpod/installer/pkg/config"
@@ -17,6 +27,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/pointer"
 )
 
@@ -36,6 +47,16 @@ func (v version) Factory() interface{} {
 	}
 }
 
+//go:embed config.go
+var source embed.FS
+
+func (v version) Sources() map[string]fs.FS {
+	return map[string]fs.FS{
+		reflect.TypeOf(Config{}).PkgPath():              source,
+		reflect.TypeOf(experimental.Config{}).PkgPath(): experimental.Source,
+	}
+}
+
 const (
 	defaultRepositoryUrl  = "eu.gcr.io/gitpod-core-dev/build"
 	defaultOpenVSXURL     = "https://open-vsx.org"
@@ -122,6 +143,9 @@ type Config struct {
 	Metadata   Metadata `json:"metadata"`
 	Repository string   `json:"repository" validate:"required,ascii"`
 
+	// Platform defaults to Kubernetes if not set
+	Platform *Platform `json:"platform,omitempty"`
+
 	Observability Observability `json:"observability"`
 	Analytics     *Analytics    `json:"analytics,omitempty"`
 
@@ -129,18 +153,71 @@ type Config struct {
 
 	MessageBus *MessageBus `json:"messageBus,omitempty"`
 
+	// Redis defaults to an in-cluster deployment if not set
+	Redis *Redis `json:"redis,omitempty"`
+
 	ObjectStorage ObjectStorage `json:"objectStorage" validate:"required"`
 
 	ContainerRegistry ContainerRegistry `json:"containerRegistry" validate:"required"`
 
 	Certificate ObjectRef `json:"certificate" validate:"required"`
+	// WorkspaceCertificate is used for the *.ws domain instead of the certificate
+	WorkspaceCertificate *ObjectRef `json:"workspaceCertificate,omitempty"`
+	// RegistryCertificate is used for the registry domains instead of the certificate and the registry-facade's internal certificate
+	RegistryCertificate *ObjectRef `json:"registryCertificate,omitempty"`
+
+	// CertificateIssuer lets cert-manager issue the certificate for the domains, stored in the certificate secret
+	CertificateIssuer *CertificateIssuer `json:"certificateIssuer,omitempty"`
+
+	// WebhookCertificate configures the rotation of the certificates the admission webhooks are served with
+	WebhookCertificate *WebhookCertificate `json:"webhookCertificate,omitempty"`
+
+	// InternalPKI configures the CA and the certificates the components authenticate each other with
+	InternalPKI *InternalPKI `json:"internalPKI,omitempty"`
+
+	Network *Network `json:"network,omitempty"`
+
+	// Mesh makes the installation work in a namespace where a service mesh injects sidecars
+	Mesh *Mesh `json:"mesh,omitempty"`
+
+	// RBAC limits the permissions the installation requires to its namespace
+	RBAC *RBAC `json:"rbac,omitempty"`
+
+	// Security hardens the security contexts of the components' pods
+	Security *Security `json:"security,omitempty"`
 
+	// ClusterAutoscaler annotates the pods so the cluster autoscaler knows which it may evict
+	ClusterAutoscaler *ClusterAutoscaler `json:"clusterAutoscaler,omitempty"`
+
+	// SecretsBackend renders the secrets, which hold the generated credentials, as resources of
+	// a secrets operator so the rendered objects can be committed to Git
+	SecretsBackend *SecretsBackend `json:"secretsBackend,omitempty"`
+
+	// ApplyOrder annotates the objects with the wave they are applied in, for deployment tools
+	// that apply all objects at once
+	ApplyOrder *ApplyOrder `json:"applyOrder,omitempty"`
+
+	// SecretRotations counts how often each group of generated secrets has been rotated. The
+	// generated secrets are derived from the seed and their rotation, see `secrets rotate`.
+	SecretRotations map[SecretGroup]int `json:"secretRotations,omitempty" validate:"omitempty,secret_groups,dive,gte=0"`
+
+	// HTTPProxy is a secret with the optional httpProxy, httpsProxy and noProxy keys. The
+	// proxy is used by every component, except for the cluster-internal hosts.
 	HTTPProxy *ObjectRef `json:"httpProxy,omitempty"`
 
-	ImagePullSecrets []ObjectRef `json:"imagePullSecrets,omitempty"`
+	// ImagePullSecrets are added to the service accounts of the components and to the workspace
+	// pods, so images can be pulled from several registries
+	ImagePullSecrets []ObjectRef `json:"imagePullSecrets,omitempty" validate:"omitempty,dive"`
+
+	// PriorityClasses renders the Gitpod priority classes and assigns them to the components
+	PriorityClasses *PriorityClasses `json:"priorityClasses,omitempty"`
 
 	Workspace Workspace `json:"workspace" validate:"required"`
 
+	// WorkspaceClusters are the workspace clusters of other installations that ws-manager-bridge
+	// registers, next to the workspace cluster of a Full installation
+	WorkspaceClusters []WorkspaceCluster `json:"workspaceClusters,omitempty" validate:"dive"`
+
 	OpenVSX OpenVSX `json:"openVSX"`
 
 	AuthProviders []ObjectRef   `json:"authProviders" validate:"dive"`
@@ -150,11 +227,32 @@ type Config struct {
 
 	DisableDefinitelyGP bool `json:"disableDefinitelyGp"`
 
+	// FeatureFlags are read from a self-hosted ConfigCat proxy or a static file instead of the ConfigCat CDN
+	FeatureFlags *FeatureFlags `json:"featureFlags,omitempty"`
+
+	// CustomCACert is a secret with a ca.crt entry that is trusted by every component. It
+	// must be in the trust-manager trust namespace, which is cert-manager by default.
 	CustomCACert *ObjectRef `json:"customCACert,omitempty"`
 
+	// SCMHosts are self-hosted Git hosts, e.g. GitLab, Bitbucket Server or Gitea, that are only
+	// reachable on a private network
+	SCMHosts []SCMHost `json:"scmHosts,omitempty" validate:"unique=Host,dive"`
+
+	// KubernetesVersion is the version of the cluster the objects are rendered for, e.g. 1.24. The
+	// API versions of the objects and the objects themselves depend on it. It is detected from the
+	// cluster when the installer connects to one, and defaults to the latest version otherwise.
+	KubernetesVersion string `json:"kubernetesVersion,omitempty" validate:"omitempty,kubernetes_version"`
+
 	DropImageRepo *bool `json:"dropImageRepo,omitempty"`
 
-	Customization *[]Customization `json:"customization,omitempty"`
+	// PinImageDigests replaces the tag of every image with the digest it currently resolves to
+	PinImageDigests *bool `json:"pinImageDigests,omitempty"`
+
+	// ImageVariant selects a hardened build of Gitpod's images. Every image that is rendered
+	// must be published in the variant.
+	ImageVariant ImageVariant `json:"imageVariant,omitempty" validate:"omitempty,oneof=default fips distroless"`
+
+	Customization *[]Customization `json:"customization,omitempty" validate:"omitempty,dive"`
 
 	Components *Components `json:"components,omitempty"`
 
@@ -176,12 +274,62 @@ const (
 
 type Observability struct {
 	LogLevel LogLevel `json:"logLevel" validate:"required,log_level"`
-	Tracing  *Tracing `json:"tracing,omitempty"`
+	// LogFormat is the format the components write their logs in. Defaults to the format
+	// of each component, which is json for most of them.
+	LogFormat LogFormat `json:"logFormat,omitempty" validate:"omitempty,log_format"`
+	Tracing   *Tracing  `json:"tracing,omitempty"`
+	// PrometheusOperator renders ServiceMonitor and PrometheusRule resources, which requires
+	// the Prometheus Operator CRDs to be installed in the cluster
+	PrometheusOperator bool `json:"prometheusOperator,omitempty"`
+	// ScrapeAnnotations sets the prometheus.io annotations a Prometheus without the Operator
+	// discovers the metrics ports by
+	ScrapeAnnotations *ScrapeAnnotations `json:"scrapeAnnotations,omitempty"`
+	// MetricLabels are added to the metrics of a component, keyed by its name. They are the
+	// relabelings of its ServiceMonitor, and the prometheus.io/label-<name> annotations that the
+	// scrape config maps to labels.
+	MetricLabels map[string]map[string]string `json:"metricLabels,omitempty"`
+	// GrafanaDashboards renders the dashboards of the components into config maps, which the
+	// dashboard sidecar of the Grafana chart loads
+	GrafanaDashboards *GrafanaDashboards `json:"grafanaDashboards,omitempty"`
+	// MetricsTLS serves the metrics ports, and the debug ports of the components built on
+	// baseserver, over TLS with certificates of the internal CA
+	MetricsTLS bool `json:"metricsTLS,omitempty"`
+}
+
+type GrafanaDashboards struct {
+	Enabled bool `json:"enabled"`
+	// Namespace is where the sidecar looks for the dashboards. Defaults to the namespace of the installation.
+	Namespace string `json:"namespace,omitempty" validate:"omitempty,hostname_rfc1123"`
+}
+
+type ScrapeTarget string
+
+const (
+	ScrapeTargetPod     ScrapeTarget = "pod"
+	ScrapeTargetService ScrapeTarget = "service"
+)
+
+// scrapeLabelAnnotationPrefix is the prefix of the annotations with the labels of the metrics
+const scrapeLabelAnnotationPrefix = "label-"
+
+type ScrapeAnnotations struct {
+	Enabled bool `json:"enabled"`
+	// Target is whether the pods or the services with a metrics port are annotated, pod or
+	// service. Defaults to pod, annotating both would have every pod scraped twice.
+	Target ScrapeTarget `json:"target,omitempty" validate:"omitempty,oneof=pod service"`
+}
+
+// ScrapeLabelAnnotation is the annotation the label of the metrics is set as
+func ScrapeLabelAnnotation(name string) string {
+	return "prometheus.io/" + scrapeLabelAnnotationPrefix + name
 }
 
 type Analytics struct {
 	SegmentKey string `json:"segmentKey"`
 	Writer     string `json:"writer"`
+	// SegmentEndpoint replaces Segment's API for the analytics and the installation's telemetry,
+	// e.g. with a proxy in the installation's network
+	SegmentEndpoint string `json:"segmentEndpoint,omitempty" validate:"omitempty,url"`
 }
 
 type Tracing struct {
@@ -190,21 +338,77 @@ type Tracing struct {
 	// Name of the kubernetes secret to use for Jaeger authentication
 	// The secret should contains two definitions: JAEGER_USER and JAEGER_PASSWORD
 	SecretName *string `json:"secretName,omitempty"`
+	// OTLPEndpoint is the endpoint OpenTelemetry traces are exported to, e.g. a Tempo distributor.
+	// Defaults to Endpoint.
+	OTLPEndpoint *string `json:"otlpEndpoint,omitempty" validate:"omitempty,url"`
+	// SamplingRate is the fraction of traces to sample, between 0 and 1. Defaults to sampling all traces.
+	SamplingRate *float64 `json:"samplingRate,omitempty" validate:"omitempty,gte=0,lte=1"`
+	// ServiceNamePrefix is prepended to the component name to build the service name of its traces
+	ServiceNamePrefix string `json:"serviceNamePrefix,omitempty"`
 }
 
 type MessageBus struct {
 	Credentials *ObjectRef `json:"credentials"`
 }
 
+type Redis struct {
+	InCluster *bool          `json:"inCluster,omitempty"`
+	External  *RedisExternal `json:"external,omitempty" validate:"required_if=InCluster false"`
+}
+
+type RedisExternal struct {
+	Host string `json:"host" validate:"required"`
+	// Defaults to 6379
+	Port int32 `json:"port,omitempty" validate:"omitempty,min=1,max=65535"`
+	TLS  bool  `json:"tls,omitempty"`
+	// Secret with a "password" and an optional "username" key
+	Credentials *ObjectRef `json:"credentials,omitempty"`
+}
+
 type Database struct {
 	InCluster *bool             `json:"inCluster,omitempty"`
 	External  *DatabaseExternal `json:"external,omitempty"`
 	CloudSQL  *DatabaseCloudSQL `json:"cloudSQL,omitempty"`
 	SSL       *SSLOptions       `json:"ssl,omitempty"`
+	// Pool limits the connections that each pod of the components opens to the database
+	Pool *DatabasePool `json:"pool,omitempty"`
+	// Wait configures the init containers that the server, usage, public-api-server and
+	// ws-manager-bridge wait for the database with before they start
+	Wait *DatabaseWait `json:"wait,omitempty"`
+}
+
+type DatabaseWait struct {
+	// Timeout is how long the init containers wait before they fail and the pod is restarted.
+	// Defaults to 5m.
+	Timeout *util.Duration `json:"timeout,omitempty" validate:"omitempty,gt=0"`
+	// Migrations also waits until the migrations of the version are applied, so that the
+	// components of a new installation do not start against an empty database
+	Migrations bool `json:"migrations,omitempty"`
+}
+
+type DatabasePool struct {
+	// MaxConnections is the size of the connection pool of each pod. The TypeScript components
+	// default to 20 connections, the Go components are not limited.
+	MaxConnections *int32 `json:"maxConnections,omitempty" validate:"omitempty,gt=0"`
+	// IdleTimeoutSeconds closes the connections that have been idle for longer. This is only
+	// supported by the Go components, such as usage.
+	IdleTimeoutSeconds *int32 `json:"idleTimeoutSeconds,omitempty" validate:"omitempty,gt=0"`
 }
 
 type DatabaseExternal struct {
-	Certificate ObjectRef `json:"certificate"`
+	Certificate *ObjectRef `json:"certificate,omitempty" validate:"required_without=Postgres"`
+	// Postgres connects to an external PostgreSQL database instead of MySQL
+	Postgres *DatabaseExternalPostgres `json:"postgres,omitempty"`
+}
+
+type DatabaseExternalPostgres struct {
+	// Certificate is a secret with the database, encryptionKeys, host, password, port and username
+	Certificate ObjectRef `json:"certificate" validate:"required"`
+}
+
+// UsePostgres returns true if the components connect to an external PostgreSQL database
+func (d *Database) UsePostgres() bool {
+	return !pointer.BoolDeref(d.InCluster, false) && d.External != nil && d.External.Postgres != nil
 }
 
 type DatabaseCloudSQL struct {
@@ -214,16 +418,52 @@ type DatabaseCloudSQL struct {
 
 type SSLOptions struct {
 	CaCert *ObjectRef `json:"caCert,omitempty"`
+	// Mode requires TLS for the database connections, which is otherwise only used with a CA
+	// certificate. The server certificate is not verified in skip-verify mode.
+	Mode DatabaseSSLMode `json:"mode,omitempty" validate:"omitempty,database_ssl_mode"`
 }
 
+type DatabaseSSLMode string
+
+const (
+	DatabaseSSLModeRequired   DatabaseSSLMode = "required"
+	DatabaseSSLModeSkipVerify DatabaseSSLMode = "skip-verify"
+)
+
 type ObjectStorage struct {
 	InCluster    *bool                      `json:"inCluster,omitempty"`
 	S3           *ObjectStorageS3           `json:"s3,omitempty"`
 	CloudStorage *ObjectStorageCloudStorage `json:"cloudStorage,omitempty"`
 	// DEPRECATED
-	MaximumBackupCount *int       `json:"maximumBackupCount,omitempty"`
-	BlobQuota          *int64     `json:"blobQuota,omitempty"`
-	Resources          *Resources `json:"resources,omitempty"`
+	MaximumBackupCount *int                  `json:"maximumBackupCount,omitempty"`
+	BlobQuota          *int64                `json:"blobQuota,omitempty"`
+	Resources          *Resources            `json:"resources,omitempty"`
+	Backups            *ObjectStorageBackups `json:"backups,omitempty"`
+	// ConnectivityCheck renders a job that accesses the external storage with the config and
+	// credentials of content-service, so that a wrong endpoint or secret fails the job on install
+	ConnectivityCheck bool `json:"connectivityCheck,omitempty"`
+}
+
+// ObjectStorageBackups configures how the backups of the workspaces are uploaded and when they
+// are deleted. A workspace only has one backup, which is replaced when the workspace stops again.
+type ObjectStorageBackups struct {
+	// ParallelUpload is the number of parts of a backup that are uploaded at the same time. It
+	// applies to S3 and the in-cluster storage and defaults to 10 and 6 respectively.
+	ParallelUpload *int32 `json:"parallelUpload,omitempty" validate:"omitempty,gt=0"`
+	// GarbageCollection configures when the server deletes old workspaces and their backups
+	GarbageCollection *BackupGarbageCollection `json:"garbageCollection,omitempty"`
+}
+
+type BackupGarbageCollection struct {
+	// Interval is how often the garbage collection runs. Defaults to 5 minutes.
+	Interval *util.Duration `json:"interval,omitempty" validate:"omitempty,gt=0"`
+	// MinAgeDays is the number of days after their last use when workspaces are deleted. Defaults to 14.
+	MinAgeDays *int32 `json:"minAgeDays,omitempty" validate:"omitempty,gt=0"`
+	// MinAgePrebuildDays is the number of days after which the backups of prebuilds are deleted. Defaults to 7.
+	MinAgePrebuildDays *int32 `json:"minAgePrebuildDays,omitempty" validate:"omitempty,gt=0"`
+	// ContentRetentionPeriodDays is the number of days the backup of a deleted workspace is kept,
+	// in which the workspace can still be restored. Defaults to 21.
+	ContentRetentionPeriodDays *int32 `json:"contentRetentionPeriodDays,omitempty" validate:"omitempty,gte=0"`
 }
 
 type ObjectStorageS3 struct {
@@ -233,6 +473,15 @@ type ObjectStorageS3 struct {
 	BucketName string `json:"bucket" validate:"required"`
 
 	AllowInsecureConnection bool `json:"allowInsecureConnection"`
+
+	// SSEKMSKeyARN is the ARN of the AWS KMS key the workspace backups and blobs are encrypted with
+	SSEKMSKeyARN string `json:"sseKmsKeyArn,omitempty"`
+	// ForcePathStyle addresses the bucket in the path of the URLs rather than in their host, for
+	// S3-compatible storage without virtual-hosted buckets
+	ForcePathStyle bool `json:"forcePathStyle,omitempty"`
+	// BucketPerOwner stores the objects of each user and team in a bucket of their own, which is
+	// named bucket followed by their ID. The bucket is then a prefix of at most 27 characters.
+	BucketPerOwner bool `json:"bucketPerOwner,omitempty"`
 }
 
 type ObjectStorageCloudStorage struct {
@@ -250,6 +499,171 @@ const (
 	InstallationFull      InstallationKind = "Full"
 )
 
+type CertificateIssuer struct {
+	// Name is the issuer that issues the certificate. This is an existing ClusterIssuer, unless DNS01 is set.
+	Name string `json:"name" validate:"required"`
+	// DNS01 renders an ACME Issuer that solves the DNS01 challenges with the DNS provider
+	DNS01 *CertificateIssuerDNS01 `json:"dns01,omitempty"`
+}
+
+type WebhookCertificate struct {
+	// Duration is how long a certificate is valid. Defaults to 90 days, cert-manager requires at least an hour.
+	Duration *util.Duration `json:"duration,omitempty"`
+	// RenewBefore is how long before its expiry a certificate is renewed. Defaults to a third of the duration.
+	RenewBefore *util.Duration `json:"renewBefore,omitempty"`
+}
+
+type InternalPKI struct {
+	// Duration is how long a certificate of the components is valid. Defaults to 90 days.
+	Duration *util.Duration `json:"duration,omitempty"`
+	// RenewBefore is how long before its expiry a certificate is renewed. Defaults to a third of the duration.
+	RenewBefore *util.Duration `json:"renewBefore,omitempty"`
+	// CADuration is how long the self-signed root CA is valid. Defaults to 365 days.
+	CADuration *util.Duration `json:"caDuration,omitempty"`
+	// CA is a secret in the namespace of cert-manager with the tls.crt and tls.key of an existing
+	// CA, which issues the certificates instead of the self-signed root CA
+	CA *ObjectRef `json:"ca,omitempty"`
+}
+
+type DNS01Provider string
+
+const (
+	DNS01ProviderCloudDNS   DNS01Provider = "clouddns"
+	DNS01ProviderCloudflare DNS01Provider = "cloudflare"
+	DNS01ProviderRoute53    DNS01Provider = "route53"
+)
+
+type CertificateIssuerDNS01 struct {
+	// Server is the ACME directory URL. Defaults to Let's Encrypt.
+	Server string `json:"server,omitempty" validate:"omitempty,url"`
+	Email  string `json:"email" validate:"required,email"`
+
+	Provider DNS01Provider `json:"provider" validate:"required,dns01_provider"`
+	// Credentials is a secret with the credentials of the DNS provider. The keys are key.json for
+	// clouddns, apiToken for cloudflare and secretAccessKey for route53.
+	Credentials ObjectRef `json:"credentials" validate:"required"`
+
+	// Project is the Google Cloud project of the clouddns provider
+	Project string `json:"project,omitempty" validate:"required_if=Provider clouddns"`
+	// Region and AccessKeyID are used by the route53 provider
+	Region      string `json:"region,omitempty" validate:"required_if=Provider route53"`
+	AccessKeyID string `json:"accessKeyID,omitempty"`
+}
+
+// DNS01CredentialsKey is the key of the provider credentials in the DNS01 credentials secret
+func DNS01CredentialsKey(provider DNS01Provider) string {
+	switch provider {
+	case DNS01ProviderCloudDNS:
+		return "key.json"
+	case DNS01ProviderCloudflare:
+		return "apiToken"
+	case DNS01ProviderRoute53:
+		return "secretAccessKey"
+	}
+	return ""
+}
+
+type SecretGroup string
+
+const (
+	// SecretGroupRegistry are the credentials of the in-cluster container registry
+	SecretGroupRegistry SecretGroup = "registry"
+	// SecretGroupStorage are the credentials of the in-cluster object storage
+	SecretGroupStorage SecretGroup = "storage"
+	// SecretGroupServer are the keys the server signs its sessions and OAuth tokens with
+	SecretGroupServer SecretGroup = "server"
+)
+
+type RBACScope string
+
+const (
+	RBACScopeCluster RBACScope = "cluster"
+	// RBACScopeNamespace renders roles instead of cluster roles and skips the cluster-scoped
+	// objects, which must then be created by a cluster administrator if they are required. The
+	// kube-rbac-proxy sidecars cannot review the tokens of metrics requests in this scope.
+	RBACScopeNamespace RBACScope = "namespace"
+)
+
+type RBAC struct {
+	Scope RBACScope `json:"scope" validate:"required,rbac_scope"`
+}
+
+type ApplyOrder struct {
+	// Annotation is set to the wave of each object. Defaults to the ArgoCD sync wave,
+	// argocd.argoproj.io/sync-wave.
+	Annotation string `json:"annotation,omitempty"`
+}
+
+type MeshKind string
+
+const (
+	MeshIstio   MeshKind = "istio"
+	MeshLinkerd MeshKind = "linkerd"
+)
+
+type Mesh struct {
+	Kind MeshKind `json:"kind" validate:"required,mesh_kind"`
+	// ControlPlaneNamespace is allowed to reach the sidecars by the network policies. Defaults to istio-system or linkerd.
+	ControlPlaneNamespace string `json:"controlPlaneNamespace,omitempty"`
+	// Sidecars overrides whether a component gets a sidecar, keyed by the component name. Components
+	// running on every node, jobs and workspaces don't get a sidecar by default.
+	Sidecars map[string]bool `json:"sidecars,omitempty"`
+}
+
+type ClusterAutoscaler struct {
+	// Enabled marks the pods of the deployments and daemon sets as safe to evict, and those of the
+	// stateful sets and the workspaces as not. The nodes of the meta components can then be scaled
+	// down, and the workspace nodes as soon as they have no workspaces.
+	Enabled bool `json:"enabled"`
+}
+
+type SecretsBackend struct {
+	Kind            SecretsBackendKind      `json:"kind" validate:"required,oneof=external-secrets sealed-secrets"`
+	ExternalSecrets *ExternalSecretsBackend `json:"externalSecrets,omitempty" validate:"required_if=Kind external-secrets"`
+	SealedSecrets   *SealedSecretsBackend   `json:"sealedSecrets,omitempty" validate:"required_if=Kind sealed-secrets"`
+}
+
+type SecretsBackendKind string
+
+const (
+	SecretsBackendExternalSecrets SecretsBackendKind = "external-secrets"
+	SecretsBackendSealedSecrets   SecretsBackendKind = "sealed-secrets"
+)
+
+type ExternalSecretsBackend struct {
+	// SecretStore is the name of the store in the namespace the values are read from
+	SecretStore string `json:"secretStore" validate:"required"`
+	// ClusterSecretStore is true when the store is a ClusterSecretStore
+	ClusterSecretStore bool `json:"clusterSecretStore,omitempty"`
+	// KeyPrefix is prepended to the name of a secret to get the key of its values in the store, e.g. gitpod/
+	KeyPrefix string `json:"keyPrefix,omitempty"`
+	// RefreshInterval is how often the values are read from the store. Defaults to an hour.
+	RefreshInterval *util.Duration `json:"refreshInterval,omitempty"`
+}
+
+type SealedSecretsBackend struct {
+	// Certificate is the PEM encoded certificate of the sealed-secrets controller the values are
+	// encrypted with, as fetched by kubeseal --fetch-cert
+	Certificate string `json:"certificate" validate:"required"`
+}
+
+// PublicKey returns the RSA key of the certificate
+func (s *SealedSecretsBackend) PublicKey() (*rsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(s.Certificate))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("sealed-secrets certificate is not PEM encoded")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse sealed-secrets certificate: %w", err)
+	}
+	key, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("sealed-secrets certificate does not have an RSA key")
+	}
+	return key, nil
+}
+
 type ObjectRef struct {
 	Kind ObjectRefKind `json:"kind" validate:"required,objectref_kind"`
 	Name string        `json:"name" validate:"required"`
@@ -266,6 +680,21 @@ type ContainerRegistry struct {
 	External                  *ContainerRegistryExternal `json:"external,omitempty" validate:"required_if=InCluster false"`
 	S3Storage                 *S3Storage                 `json:"s3storage,omitempty"`
 	PrivateBaseImageAllowList []string                   `json:"privateBaseImageAllowList"`
+	// UpstreamCredentials is a secret with a .dockerconfigjson key with the credentials of the
+	// registries the workspace images are pulled from, e.g. Docker Hub, to avoid the limits of
+	// anonymous pulls
+	UpstreamCredentials *ObjectRef `json:"upstreamCredentials,omitempty"`
+	// GarbageCollection removes the unused images from the volume of the in-cluster registry
+	GarbageCollection *RegistryGarbageCollection `json:"garbageCollection,omitempty"`
+}
+
+type RegistryGarbageCollection struct {
+	Enabled bool `json:"enabled"`
+	// Schedule is the cron schedule of the garbage collection. Defaults to every night at 2am.
+	Schedule string `json:"schedule,omitempty"`
+	// RetentionDays removes the tags that have not been pushed for the number of days, so the
+	// images are collected too. By default only the untagged images are collected.
+	RetentionDays *int32 `json:"retentionDays,omitempty" validate:"omitempty,gte=1"`
 }
 
 type ContainerRegistryExternal struct {
@@ -296,6 +725,83 @@ const (
 	LogLevelPanic   LogLevel = "panic"
 )
 
+type LogFormat string
+
+const (
+	LogFormatJSON LogFormat = "json"
+	LogFormatText LogFormat = "text"
+)
+
+type ImageVariant string
+
+const (
+	ImageVariantDefault    ImageVariant = "default"
+	ImageVariantFIPS       ImageVariant = "fips"
+	ImageVariantDistroless ImageVariant = "distroless"
+)
+
+type WorkspaceManager string
+
+const (
+	WorkspaceManagerClassic WorkspaceManager = "classic"
+	WorkspaceManagerMk2     WorkspaceManager = "mk2"
+)
+
+type PlatformKind string
+
+const (
+	PlatformKubernetes PlatformKind = "Kubernetes"
+	// PlatformOpenShift renders SecurityContextConstraints and Routes and leaves the user and
+	// group IDs of the in-cluster dependencies to OpenShift
+	PlatformOpenShift PlatformKind = "OpenShift"
+)
+
+type Platform struct {
+	Kind PlatformKind `json:"kind" validate:"required,platform_kind"`
+}
+
+type NetworkPolicyMode string
+
+const (
+	// NetworkPolicyDefault restricts the ingress of the components that are exposed to other components
+	NetworkPolicyDefault NetworkPolicyMode = "default"
+	// NetworkPolicyStrict denies all ingress and egress in the namespace that is not explicitly allowed
+	NetworkPolicyStrict NetworkPolicyMode = "strict"
+)
+
+type Network struct {
+	// Policy defaults to "default" if not set. In "strict" mode, every component only accepts
+	// ingress from the components that use it and its egress is limited to the components and
+	// dependencies it connects to.
+	Policy NetworkPolicyMode `json:"policy,omitempty" validate:"omitempty,network_policy_mode"`
+	// IPFamilyPolicy and IPFamilies are set on all services. Use PreferDualStack or
+	// RequireDualStack on dual-stack clusters to make Gitpod reachable over IPv6.
+	IPFamilyPolicy *corev1.IPFamilyPolicyType `json:"ipFamilyPolicy,omitempty" validate:"omitempty,ip_family_policy"`
+	IPFamilies     []corev1.IPFamily          `json:"ipFamilies,omitempty" validate:"omitempty,max=2,dive,ip_family"`
+	// DebugPorts controls the debug and profiling ports of the components. Without it, the
+	// ports are exposed but not accepted by the NetworkPolicies.
+	DebugPorts *DebugPorts `json:"debugPorts,omitempty"`
+	// NodeLocalDNSCache points the DNS of the components and workspaces at the NodeLocal
+	// DNSCache on their node instead of the cluster DNS service
+	NodeLocalDNSCache *NodeLocalDNSCache `json:"nodeLocalDNSCache,omitempty"`
+}
+
+type NodeLocalDNSCache struct {
+	Enabled bool `json:"enabled"`
+	// Address is the link-local IP the cache listens on, it defaults to 169.254.20.10
+	Address string `json:"address,omitempty" validate:"omitempty,ip"`
+}
+
+type DebugPorts struct {
+	// Enabled exposes the debug ports of every component
+	Enabled bool `json:"enabled"`
+	// Components overrides Enabled for a component, keyed by its name, e.g. server
+	Components map[string]bool `json:"components,omitempty"`
+	// Namespace is where the debugging pods run, the NetworkPolicies accept connections to the
+	// debug ports from the pods in it
+	Namespace string `json:"namespace,omitempty" validate:"omitempty,hostname_rfc1123"`
+}
+
 type Resources struct {
 	// todo(sje): add custom validation to corev1.ResourceList
 	Requests corev1.ResourceList `json:"requests" validate:"required"`
@@ -309,6 +815,25 @@ type WorkspaceRuntime struct {
 	ContainerDRuntimeDir string `json:"containerdRuntimeDir" validate:"required,startswith=/"`
 	// The location of containerd socket on the host machine
 	ContainerDSocketDir string `json:"containerdSocketDir" validate:"required,startswith=/"`
+	// RegistryMirrors are written to the registry host configs of containerd on the workspace
+	// nodes, which containerd reads on every pull if its config_path is RegistryConfigDir
+	RegistryMirrors []RegistryMirror `json:"registryMirrors,omitempty" validate:"unique=Registry,dive"`
+	// RegistryConfigDir is the config_path of the registries of containerd on the workspace
+	// nodes, defaults to /etc/containerd/certs.d
+	RegistryConfigDir string `json:"registryConfigDir,omitempty" validate:"omitempty,startswith=/"`
+}
+
+// RegistryMirror is the hosts.toml of a registry, either generated from the mirrors or as is
+type RegistryMirror struct {
+	// Registry is the host of the images that are pulled through the mirrors, e.g. docker.io
+	// or registry.example.com:5000
+	Registry string `json:"registry" validate:"required,hostname_port|hostname_rfc1123"`
+	// Mirrors are the URLs of the mirrors, which are tried in order before the registry
+	Mirrors []string `json:"mirrors,omitempty" validate:"required_without=HostsTOML,excluded_with=HostsTOML,dive,url"`
+	// SkipVerify turns off the verification of the certificates of the mirrors
+	SkipVerify bool `json:"skipVerify,omitempty"`
+	// HostsTOML is written as the hosts.toml of the registry instead of the generated one
+	HostsTOML string `json:"hostsToml,omitempty"`
 }
 
 type WorkspaceResources struct {
@@ -334,6 +859,17 @@ type WorkspaceTemplates struct {
 	Prebuild   *corev1.Pod `json:"prebuild"`
 	ImageBuild *corev1.Pod `json:"imagebuild"`
 	Regular    *corev1.Pod `json:"regular"`
+
+	// Files references YAML files with pod templates, which are read when the config is loaded. Relative
+	// paths are resolved from the directory of the config file.
+	Files *WorkspaceTemplateFiles `json:"files,omitempty"`
+}
+
+type WorkspaceTemplateFiles struct {
+	Default    string `json:"default,omitempty"`
+	Prebuild   string `json:"prebuild,omitempty"`
+	ImageBuild string `json:"imagebuild,omitempty"`
+	Regular    string `json:"regular,omitempty"`
 }
 
 type PersistentVolumeClaim struct {
@@ -347,17 +883,76 @@ type PersistentVolumeClaim struct {
 	SnapshotClass string `json:"snapshotClass"`
 }
 
+type WorkspaceImagePolicy struct {
+	// AllowedRegistries are the only registries the images of the workspaces can be from, e.g.
+	// docker.io. It needs a workspaceImage from one of them.
+	AllowedRegistries []string `json:"allowedRegistries,omitempty" validate:"dive,hostname_port|hostname_rfc1123"`
+	// DisableDotfiles ignores the dotfiles repositories that the users set in their preferences
+	DisableDotfiles bool `json:"disableDotfiles,omitempty"`
+}
+
+type WorkspaceClusterState string
+
+const (
+	WorkspaceClusterStateAvailable WorkspaceClusterState = "available"
+	WorkspaceClusterStateCordoned  WorkspaceClusterState = "cordoned"
+	WorkspaceClusterStateDraining  WorkspaceClusterState = "draining"
+)
+
+type WorkspaceCluster struct {
+	// Name is the name the cluster is registered with, which its workspace instances refer to
+	Name string `json:"name" validate:"required,hostname_rfc1123"`
+	// URL is the address of the ws-manager of the cluster, e.g. dns:///ws-manager.eu.example.com:443
+	URL string `json:"url" validate:"required"`
+	// TLS is the secret with the ca.crt, tls.crt and tls.key of the client certificate
+	// ws-manager-bridge and server connect to ws-manager with
+	TLS ObjectRef `json:"tls" validate:"required"`
+	// State defaults to available. Cordoned clusters start no new workspaces, and draining
+	// clusters stop theirs.
+	State WorkspaceClusterState `json:"state,omitempty" validate:"omitempty,oneof=available cordoned draining"`
+	// Score weighs the cluster against the others when a workspace starts, defaults to 50
+	Score *int32 `json:"score,omitempty" validate:"omitempty,gte=0"`
+	// MaxScore is the highest score of the cluster, defaults to 100
+	MaxScore *int32 `json:"maxScore,omitempty" validate:"omitempty,gte=0"`
+	// Govern has ws-manager-bridge update the state of the workspace instances of the cluster,
+	// defaults to true
+	Govern *bool `json:"govern,omitempty"`
+	// AdmissionConstraints restrict the users whose workspaces start in the cluster
+	AdmissionConstraints []WorkspaceClusterAdmissionConstraint `json:"admissionConstraints,omitempty" validate:"dive"`
+	Region               string                                `json:"region,omitempty"`
+}
+
+type WorkspaceClusterAdmissionConstraint struct {
+	// Type is has-feature-preview for the users with the feature preview, or has-permission
+	// for the users with the permission
+	Type       string `json:"type" validate:"required,oneof=has-feature-preview has-permission"`
+	Permission string `json:"permission,omitempty"`
+}
+
 type Workspace struct {
+	// Manager is the component that manages the workspaces. Defaults to classic, mk2 replaces
+	// ws-manager with ws-manager-mk2, which keeps the workspaces in custom resources.
+	Manager WorkspaceManager `json:"manager,omitempty" validate:"omitempty,oneof=classic mk2"`
+
 	Runtime   WorkspaceRuntime    `json:"runtime" validate:"required"`
 	Resources Resources           `json:"resources" validate:"required"`
 	Templates *WorkspaceTemplates `json:"templates,omitempty"`
 
+	// InfrastructureNamespace is the namespace ws-daemon, registry-facade and agent-smith run in.
+	// Defaults to the namespace of the installation.
+	InfrastructureNamespace string `json:"infrastructureNamespace,omitempty" validate:"omitempty,hostname_rfc1123"`
+
 	// PrebuildPVC is the struct that describes how to setup persistent volume claim for prebuild workspace
 	PrebuildPVC PersistentVolumeClaim `json:"prebuildPVC" validate:"required"`
 
 	// PVC is the struct that describes how to setup persistent volume claim for regular workspace
 	PVC PersistentVolumeClaim `json:"pvc" validate:"required"`
 
+	// EnablePVC backs new workspaces and prebuilds with a persistent volume claim, which is
+	// backed up and restored as a CSI volume snapshot. Needs the storage and snapshot classes
+	// of pvc and prebuildPVC.
+	EnablePVC bool `json:"enablePVC,omitempty"`
+
 	// MaxLifetime is the maximum time a workspace is allowed to run. After that, the workspace times out despite activity
 	MaxLifetime util.Duration `json:"maxLifetime" validate:"required"`
 
@@ -370,10 +965,188 @@ type Workspace struct {
 	// TimeoutAfterClose is the time a workspace timed out after it has been closed (“closed” means that it does not get a heartbeat from an IDE anymore)
 	TimeoutAfterClose *util.Duration `json:"timeoutAfterClose,omitempty"`
 
+	// TimeoutStartup is the time a workspace may take from its creation until it is running,
+	// including the pending and creating phases. Defaults to 1h.
+	TimeoutStartup *util.Duration `json:"timeoutStartup,omitempty" validate:"omitempty,gt=0"`
+
+	// TimeoutInitialization is the time the content of a workspace may take to be initialized,
+	// e.g. to clone the repository. Defaults to 30m.
+	TimeoutInitialization *util.Duration `json:"timeoutInitialization,omitempty" validate:"omitempty,gt=0"`
+
+	// HeartbeatInterval is how often the IDE of an active user sends a heartbeat, a workspace
+	// without heartbeats is inactive. Defaults to 30s.
+	HeartbeatInterval *util.Duration `json:"heartbeatInterval,omitempty" validate:"omitempty,gt=0"`
+
 	WorkspaceImage string `json:"workspaceImage,omitempty"`
+
+	// ImagePolicy restricts the images of the workspaces and the dotfiles of the users
+	ImagePolicy *WorkspaceImagePolicy `json:"imagePolicy,omitempty"`
+
+	// Classes are additional workspace classes that users can choose from next to the default class
+	Classes []WorkspaceClass `json:"classes,omitempty" validate:"omitempty,workspace_classes,dive"`
+
+	// GPU configures how the workspaces of classes with GPUs are scheduled
+	GPU *WorkspaceGPU `json:"gpu,omitempty"`
+
+	// Egress restricts the addresses outside the cluster the workspaces can connect to
+	Egress *WorkspaceEgress `json:"egress,omitempty"`
+
+	// DNS configures the name resolution of the workspaces, e.g. to resolve internal domains
+	// with other nameservers than those of the cluster
+	DNS *WorkspaceDNS `json:"dns,omitempty"`
+
+	// Prebuilds sets the class, concurrency and timeout of the prebuilds apart from the regular workspaces
+	Prebuilds *WorkspacePrebuilds `json:"prebuilds,omitempty"`
+
+	// Ports sets the default visibility and protocol of the ports the workspaces expose, and
+	// which of them can be exposed
+	Ports *WorkspacePorts `json:"ports,omitempty"`
+
+	// Domain the workspaces of this cluster are served under, they get the hosts of its
+	// subdomains. Defaults to ws.<domain>, or ws-<shortname>.<domain> with an installation shortname.
+	Domain string `json:"domain,omitempty" validate:"omitempty,fqdn"`
+	// AdditionalDomains are the domains of the workspaces of the other clusters that are served
+	// through this cluster, e.g. the workspace domains of other regions
+	AdditionalDomains []string `json:"additionalDomains,omitempty" validate:"unique,dive,fqdn"`
+
+	// NodeDrain lets node-labeler drain the nodes labelled gitpod.io/drain=true of workspaces,
+	// so that node pool upgrades do not kill the running workspaces
+	NodeDrain *WorkspaceNodeDrain `json:"nodeDrain,omitempty"`
+
+	// StorageQuota configures how ws-daemon enforces the storage limit of the workspaces on
+	// their nodes
+	StorageQuota *WorkspaceStorageQuota `json:"storageQuota,omitempty"`
+
+	// SSHUserCA lets the SSH gateway and the workspaces accept the user certificates of a CA,
+	// so that users connect with short-lived certificates instead of the keys in their settings
+	SSHUserCA *SSHUserCA `json:"sshUserCA,omitempty"`
+}
+
+// SSHUserCA is the certificate authority of the SSH user certificates. A certificate
+// authenticates the Gitpod user whose ID is one of its principals.
+type SSHUserCA struct {
+	// Secret has the public keys of the CAs in ca.pub, in the format of an authorized_keys file
+	Secret *ObjectRef `json:"secret,omitempty"`
+	// Generate lets the installer generate the CA into the ssh-user-ca secret, with the private
+	// key in ca and the public key in ca.pub. The key is derived from the seed of the render.
+	Generate bool `json:"generate,omitempty"`
+}
+
+type StorageQuotaBackend string
+
+const (
+	// StorageQuotaXFS sets XFS project quotas, the working area /var/gitpod/workspaces of the
+	// nodes must be on an XFS filesystem that is mounted with prjquota
+	StorageQuotaXFS StorageQuotaBackend = "xfs"
+	// StorageQuotaNone does not enforce the storage limit
+	StorageQuotaNone StorageQuotaBackend = "none"
+)
+
+type WorkspaceStorageQuota struct {
+	Backend StorageQuotaBackend `json:"backend" validate:"required,oneof=xfs none"`
+	// DefaultSize is the quota of the workspaces whose class sets no storage limit
+	DefaultSize *resource.Quantity `json:"defaultSize,omitempty"`
+}
+
+type WorkspaceNodeDrain struct {
+	// Timeout is how long the workspaces of a draining node may keep running before they are
+	// stopped. Defaults to 3h.
+	Timeout *util.Duration `json:"timeout,omitempty" validate:"omitempty,gt=0"`
+}
+
+type WorkspacePorts struct {
+	// DefaultVisibility of the ports that .gitpod.yml does not configure, private or public.
+	// Defaults to private.
+	DefaultVisibility string `json:"defaultVisibility,omitempty" validate:"omitempty,oneof=private public"`
+	// DisablePublic makes every port private, so only the owner of a workspace can access them
+	DisablePublic bool `json:"disablePublic,omitempty"`
+	// AllowedRanges are the ports that can be exposed, any port can be exposed if there are none
+	AllowedRanges []WorkspacePortRange `json:"allowedRanges,omitempty" validate:"dive"`
+	// DefaultProtocol ws-proxy connects to the ports with, http or https. Defaults to http.
+	DefaultProtocol string `json:"defaultProtocol,omitempty" validate:"omitempty,oneof=http https"`
+}
+
+// WorkspacePortRange includes the From and To ports
+type WorkspacePortRange struct {
+	From int32 `json:"from" validate:"required,min=1,max=65535"`
+	To   int32 `json:"to" validate:"required,min=1,max=65535,gtefield=From"`
+}
+
+type WorkspacePrebuilds struct {
+	// Class is the workspace class the prebuilds run in, default or the name of one of the classes
+	Class string `json:"class,omitempty"`
+	// MaxConcurrentPerRef limits the prebuilds that run for a branch at once, defaults to 10
+	MaxConcurrentPerRef *int32 `json:"maxConcurrentPerRef,omitempty" validate:"omitempty,min=1"`
+	// MaxConcurrentPerProject limits the prebuilds that run for a project at once, unlimited if not set
+	MaxConcurrentPerProject *int32 `json:"maxConcurrentPerProject,omitempty" validate:"omitempty,min=1"`
+	// MaxConcurrentPerOrganization limits the prebuilds that run for all projects of an organization
+	// at once, unlimited if not set
+	MaxConcurrentPerOrganization *int32 `json:"maxConcurrentPerOrganization,omitempty" validate:"omitempty,min=1"`
+	// Timeout is how long a prebuild may run, including its startup, defaults to an hour
+	Timeout *util.Duration `json:"timeout,omitempty" validate:"omitempty,gt=0"`
+}
+
+type WorkspaceDNS struct {
+	// Policy defaults to ClusterFirst. With None, the workspaces only use the nameservers and
+	// search domains that are configured here.
+	Policy corev1.DNSPolicy `json:"policy,omitempty" validate:"omitempty,oneof=ClusterFirst Default None"`
+	// Nameservers are added to those of the policy
+	Nameservers []string `json:"nameservers,omitempty" validate:"omitempty,max=3,dive,ip"`
+	// Searches are added to the search domains of the policy
+	Searches []string `json:"searches,omitempty" validate:"omitempty,dive,hostname_rfc1123"`
+	// Options are added to the resolver options of the policy, e.g. ndots
+	Options []corev1.PodDNSConfigOption `json:"options,omitempty" validate:"omitempty,dive"`
+}
+
+type WorkspaceEgress struct {
+	// Allow are the only destinations the workspaces can connect to. Defaults to any address,
+	// 0.0.0.0/0 on every port.
+	Allow []EgressRule `json:"allow,omitempty" validate:"omitempty,dive"`
+	// Deny are the CIDRs that are excluded from the allowed destinations
+	Deny []string `json:"deny,omitempty" validate:"omitempty,dive,cidr"`
+	// BlockMetadataEndpoint excludes the metadata endpoint of the cloud providers, 169.254.169.254,
+	// from the allowed destinations. Defaults to true.
+	BlockMetadataEndpoint *bool `json:"blockMetadataEndpoint,omitempty"`
+}
+
+type EgressRule struct {
+	CIDR string `json:"cidr" validate:"required,cidr"`
+	// Ports limits the rule to the ports, it allows every port if there are none
+	Ports []EgressPort `json:"ports,omitempty" validate:"omitempty,dive"`
+}
+
+type EgressPort struct {
+	Port int32 `json:"port" validate:"required,min=1,max=65535"`
+	// EndPort makes the rule allow the range of ports from Port to EndPort
+	EndPort *int32 `json:"endPort,omitempty" validate:"omitempty,gtefield=Port,max=65535"`
+	// Protocol defaults to TCP
+	Protocol corev1.Protocol `json:"protocol,omitempty" validate:"omitempty,oneof=TCP UDP SCTP"`
+}
+
+type WorkspaceGPU struct {
+	// RuntimeClassName is the runtime class of workspaces with GPUs, e.g. nvidia
+	RuntimeClassName *string `json:"runtimeClassName,omitempty"`
+	// Tolerations let workspaces with GPUs be scheduled on tainted GPU nodes. Defaults to tolerating the nvidia.com/gpu taint.
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+}
+
+type WorkspaceClass struct {
+	// Name identifies the class and must be unique
+	Name        string `json:"name" validate:"required,hostname_rfc1123"`
+	DisplayName string `json:"displayName,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Default makes this class the one that is selected for users by default
+	Default   bool      `json:"default,omitempty"`
+	Resources Resources `json:"resources" validate:"required"`
+	// NodeSelector restricts workspaces of this class to the matching nodes
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// GPUs is the number of nvidia.com/gpu resources that workspaces of this class request
+	GPUs int64 `json:"gpus,omitempty" validate:"gte=0"`
 }
 
 type OpenVSX struct {
+	// URL is the registry the extensions are fetched from, e.g. an internal mirror for air-gapped
+	// installations. Defaults to https://open-vsx.org.
 	URL   string        `json:"url" validate:"url"`
 	Proxy *OpenVSXProxy `json:"proxy,omitempty"`
 }
@@ -381,6 +1154,16 @@ type OpenVSX struct {
 type OpenVSXProxy struct {
 	DisablePVC bool `json:"disablePVC"`
 	Proxy      `json:",inline"`
+
+	// CacheDurationRegular is how long a cached response is served before the registry is asked
+	// again. Defaults to 5 minutes.
+	CacheDurationRegular *util.Duration `json:"cacheDurationRegular,omitempty" validate:"omitempty,gt=0"`
+	// CacheDurationBackup is how long a cached response is served while the registry is
+	// unavailable. Defaults to 72 hours.
+	CacheDurationBackup *util.Duration `json:"cacheDurationBackup,omitempty" validate:"omitempty,gt=0"`
+	// CacheSize is the memory of the cache, above which the least frequently used responses are
+	// evicted. The cache is saved to an 8Gi volume. Defaults to 100Mi.
+	CacheSize *resource.Quantity `json:"cacheSize,omitempty"`
 }
 
 type Proxy struct {
@@ -415,49 +1198,831 @@ type OAuth struct {
 	CallBackUrl  string `json:"callBackUrl" validate:"required"`
 }
 
+type SCMHost struct {
+	// Host is the name of the Git host without a port, the auth providers of the host get its
+	// HTTPS port
+	Host string `json:"host" validate:"required,fqdn"`
+	// HTTPSPort defaults to 443
+	HTTPSPort int32 `json:"httpsPort,omitempty" validate:"omitempty,min=1,max=65535"`
+	// SSHPort is the port Git is served over SSH on, defaults to 22
+	SSHPort int32 `json:"sshPort,omitempty" validate:"omitempty,min=1,max=65535"`
+	// CIDRs are the addresses of the host, the workspaces can reach them on the HTTPS and SSH
+	// ports regardless of their egress restrictions
+	CIDRs []string `json:"cidrs" validate:"required,min=1,dive,cidr"`
+	// CACert is a secret with a ca.crt entry of the CA that issued the certificate of the host,
+	// it is trusted like the customCACert and must be in the same namespace
+	CACert *ObjectRef `json:"caCert,omitempty"`
+}
+
+// Address returns the host with its HTTPS port, unless that is the default port
+func (h SCMHost) Address() string {
+	if h.HTTPSPort == 0 || h.HTTPSPort == 443 {
+		return h.Host
+	}
+	return fmt.Sprintf("%s:%d", h.Host, h.HTTPSPort)
+}
+
+// SCMHost returns the self-hosted Git host of an auth provider host, if there is one
+func (c *Config) SCMHost(host string) *SCMHost {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	for i := range c.SCMHosts {
+		if strings.EqualFold(c.SCMHosts[i].Host, host) {
+			return &c.SCMHosts[i]
+		}
+	}
+	return nil
+}
+
 // Customization is a stripped-down version of the Kubernetes YAML
 type Customization struct {
 	metav1.TypeMeta `json:",inline"`
 	Metadata        metav1.ObjectMeta `json:"metadata"`
 	Spec            CustomizationSpec `json:"spec,omitempty"`
+	// Patches are applied in order to the rendered objects that match the
+	// apiVersion, kind and name
+	Patches []CustomizationPatch `json:"patches,omitempty" validate:"omitempty,dive"`
 }
 
 type CustomizationSpec struct {
 	Env []corev1.EnvVar `json:"env"`
 }
 
+type CustomizationPatchType string
+
+const (
+	// CustomizationPatchStrategic is a strategic merge patch. Objects that are not known
+	// Kubernetes types, such as custom resources, are patched with a JSON merge patch.
+	CustomizationPatchStrategic CustomizationPatchType = "strategic"
+	// CustomizationPatchJSON is an RFC 6902 JSON patch
+	CustomizationPatchJSON CustomizationPatchType = "json"
+)
+
+type CustomizationPatch struct {
+	Type CustomizationPatchType `json:"type" validate:"required,customization_patch_type"`
+	// Patch is the patch document as YAML or JSON
+	Patch string `json:"patch" validate:"required"`
+}
+
 type Components struct {
-	AgentSmith *agentSmith.Config    `json:"agentSmith,omitempty"`
-	IDE        *IDEComponents        `json:"ide"`
-	PodConfig  map[string]*PodConfig `json:"podConfig,omitempty"`
-	Proxy      *ProxyComponent       `json:"proxy,omitempty"`
+	AgentSmith     *AgentSmithComponent   `json:"agentSmith,omitempty"`
+	Blobserve      *BlobserveComponent    `json:"blobserve,omitempty"`
+	DockerRegistry *ToggleComponent       `json:"dockerRegistry,omitempty"`
+	IDE            *IDEComponents         `json:"ide"`
+	IDEProxy       *IDEProxyComponent     `json:"ideProxy,omitempty"`
+	ImageBuilder   *ImageBuilderComponent `json:"imageBuilder,omitempty"`
+	Minio          *ToggleComponent       `json:"minio,omitempty"`
+	OpenVSXProxy   *ToggleComponent       `json:"openvsxProxy,omitempty"`
+	PodConfig      map[string]*PodConfig  `json:"podConfig,omitempty" validate:"omitempty,autoscaling_components,daemon_set_components,pod_disruption_budgets,dive"`
+	Proxy          *ProxyComponent        `json:"proxy,omitempty"`
+	PublicAPI      *PublicAPIComponent    `json:"publicApi,omitempty"`
+	Server         *ServerComponent       `json:"server,omitempty"`
+	SmokeTest      *SmokeTestComponent    `json:"smokeTest,omitempty"`
+	SpiceDB        *SpiceDBComponent      `json:"spicedb,omitempty"`
+	Usage          *UsageComponent        `json:"usage,omitempty"`
+	WSDaemon       *WSDaemonComponent     `json:"wsDaemon,omitempty"`
+	WSProxy        *WSProxyComponent      `json:"wsProxy,omitempty"`
+	// GRPC overrides the defaults of the gRPC clients the components connect to each other with
+	GRPC *GRPCClients `json:"grpc,omitempty"`
+	// Images overrides the images of the components, keyed by the image name (e.g. server, ws-daemon)
+	Images map[string]*ComponentImage `json:"images,omitempty" validate:"omitempty,dive"`
+}
+
+type ComponentImage struct {
+	// Repository replaces the repository and the name of the image, e.g. registry.example.com/gitpod/server
+	Repository string `json:"repository,omitempty"`
+	// Tag replaces the version of the image
+	Tag string `json:"tag,omitempty"`
+	// Digest pins the image and takes precedence over the tag
+	Digest string `json:"digest,omitempty" validate:"omitempty,startswith=sha256:"`
+}
+
+// ToggleComponent switches off an optional component that has no other settings, e.g. to run it
+// outside of the installation. It is used for docker-registry, minio and openvsx-proxy.
+type ToggleComponent struct {
+	// Enabled renders the component, defaults to true
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+type PublicAPIComponent struct {
+	// Enabled renders the public API server and its host in the proxy. Defaults to true. The SSO
+	// with OIDC and the access tokens of the dashboard depend on it.
+	Enabled *bool `json:"enabled,omitempty"`
+	// Hostname is where the API is served, defaults to api.<domain>. The certificate of the
+	// installation has to cover it.
+	Hostname string `json:"hostname,omitempty" validate:"omitempty,fqdn"`
+	// GRPCWeb allows browsers to call the API with gRPC-Web and Connect from the dashboard's origin
+	GRPCWeb bool `json:"grpcWeb,omitempty"`
+	// RateLimits limit the calls of each access token to a method, keyed by the full name of the
+	// method, e.g. /gitpod.experimental.v1.WorkspacesService/ListWorkspaces
+	RateLimits map[string]PublicAPIRateLimit `json:"rateLimits,omitempty" validate:"dive"`
+}
+
+type PublicAPIRateLimit struct {
+	Requests      uint32 `json:"requests" validate:"required"`
+	PeriodSeconds uint32 `json:"periodSeconds" validate:"required"`
+}
+
+// SmokeTestComponent renders a job that starts a workspace once the installation is deployed.
+// It only runs in full installations.
+type SmokeTestComponent struct {
+	// Credentials is a secret with the name of the user the workspace is started for in username,
+	// and a token of the Git host the user signs in with in token. The user is created if it does
+	// not exist.
+	Credentials ObjectRef `json:"credentials" validate:"required"`
+	// ContextURL is the repository the workspace is started from, defaults to https://github.com/gitpod-io/empty
+	ContextURL string `json:"contextURL,omitempty" validate:"omitempty,url"`
+	// Timeout is how long the components have to become ready before the test starts, defaults to 5m
+	Timeout *util.Duration `json:"timeout,omitempty" validate:"omitempty,gt=0"`
+}
+
+type SpiceDBComponent struct {
+	// External replaces the in-cluster SpiceDB with one that is managed elsewhere, e.g. by Authzed
+	External *SpiceDBExternal `json:"external,omitempty"`
+}
+
+type SpiceDBExternal struct {
+	// Address is the host and port of the gRPC API
+	Address string `json:"address" validate:"required,hostname_port"`
+	// PresharedKey is a secret with the key in presharedKey
+	PresharedKey ObjectRef `json:"presharedKey" validate:"required"`
+	// Insecure connects without TLS
+	Insecure bool `json:"insecure,omitempty"`
+	// Bootstrap writes the schema with a job on every deployment. Without it, the schema has to be
+	// written before the installation is updated.
+	Bootstrap bool `json:"bootstrap,omitempty"`
+}
+
+// UsageComponent is the config of the usage component, which records the usage of the workspaces
+// and bills it with Stripe
+type UsageComponent struct {
+	Enabled bool `json:"enabled"`
+	// LedgerSchedule is how often the usage of the workspaces is recorded. No usage is recorded
+	// without it.
+	LedgerSchedule *util.Duration `json:"ledgerSchedule,omitempty" validate:"omitempty,gt=0"`
+	// ResetUsageSchedule is how often the usage of the billing periods that have ended is reset,
+	// defaults to 15m
+	ResetUsageSchedule *util.Duration `json:"resetUsageSchedule,omitempty" validate:"omitempty,gt=0"`
+	// StripeCredentials is a secret with the publishable and secret keys of Stripe in apikeys.
+	// Without it, the usage is not billed.
+	StripeCredentials *ObjectRef `json:"stripeCredentials,omitempty"`
+	// DefaultSpendingLimit is the credits the users and teams can spend in a billing period,
+	// defaults to no limit
+	DefaultSpendingLimit *UsageSpendingLimit `json:"defaultSpendingLimit,omitempty"`
+}
+
+type UsageSpendingLimit struct {
+	ForTeams int32 `json:"forTeams" validate:"min=0"`
+	ForUsers int32 `json:"forUsers" validate:"min=0"`
+	// MinForUsersOnStripe is the lowest limit the users who pay with Stripe can set
+	MinForUsersOnStripe int32 `json:"minForUsersOnStripe" validate:"min=0"`
+}
+
+// AgentSmithComponent is the config of agent-smith. Its enforcement settings choose the penalties
+// for the signatures of each blocklist, or only report them in audit only mode.
+type AgentSmithComponent struct {
+	agentSmith.Config `json:",inline"`
+
+	// SlackWebhooksSecret is a secret with the webhooks of the notifications in the audit and
+	// warning keys. It takes precedence over slackWebhooks.
+	SlackWebhooksSecret *ObjectRef `json:"slackWebhooksSecret,omitempty"`
+}
+
+type IDEProxyComponent struct {
+	// CDN fronts the static content of ide-proxy and blobserve with a CDN that has the host of
+	// ide-proxy, ide.<domain>, as its origin
+	CDN *IDEProxyCDN `json:"cdn,omitempty"`
+	// Cache sets how long the browsers and the CDN cache the static content of ide-proxy
+	Cache *IDEProxyCache `json:"cache,omitempty"`
+}
+
+type IDEProxyCDN struct {
+	// Host is where the browsers load the IDEs from, e.g. cdn.example.com
+	Host string `json:"host" validate:"required,fqdn"`
+}
+
+type IDEProxyCache struct {
+	// StaticMaxAge is the max-age of the IDE images, logos and assets, defaults to a year. They
+	// are not versioned, so they may be served from a cache for as long after an IDE update.
+	StaticMaxAge *util.Duration `json:"staticMaxAge,omitempty" validate:"omitempty,gte=0"`
+	// BinaryMaxAge is the max-age of the downloads of the local companion, defaults to 10m
+	BinaryMaxAge *util.Duration `json:"binaryMaxAge,omitempty" validate:"omitempty,gte=0"`
+}
+
+type BlobserveComponent struct {
+	Cache *BlobserveCache `json:"cache,omitempty"`
+}
+
+// BlobserveCache configures where blobserve keeps the unpacked layers of the IDE and supervisor images
+type BlobserveCache struct {
+	// MaxSize is the size above which blobs are evicted. Defaults to 1Gi.
+	MaxSize *resource.Quantity `json:"maxSize,omitempty"`
+	// EvictionPolicy decides which blobs are evicted first, the least recently used (lru, the
+	// default) or those added first (fifo)
+	EvictionPolicy string `json:"evictionPolicy,omitempty" validate:"omitempty,oneof=lru fifo"`
+	// PVC keeps the cache on a persistent volume claim, so it survives the restarts of the pod.
+	// The claim can only be mounted by a single replica. Defaults to an emptyDir.
+	PVC *PersistentVolumeClaim `json:"pvc,omitempty"`
+}
+
+// ImageBuilderComponent configures the builds of the workspace images
+type ImageBuilderComponent struct {
+	BuildKit *BuildKit `json:"buildKit,omitempty"`
+}
+
+// BuildKit configures the BuildKit daemon that runs in the image build workspaces
+type BuildKit struct {
+	// Resources replace those of the workspace class for the image builds
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+	// MaxParallelism limits the build steps that run at once. Not limited by default.
+	MaxParallelism *int32 `json:"maxParallelism,omitempty" validate:"omitempty,gt=0"`
+	// Cache keeps the build cache on a claim that is shared by the image builds, so that the
+	// unchanged layers are reused. The claim is mounted by every running build and must support
+	// the ReadWriteMany access mode.
+	Cache *PersistentVolumeClaim `json:"cache,omitempty"`
 }
 
 type IDEComponents struct {
 	Metrics       *IDEMetrics `json:"metrics,omitempty"`
 	Proxy         *Proxy      `json:"proxy,omitempty"`
 	ResolveLatest *bool       `json:"resolveLatest,omitempty"`
+	// Images overrides the images of an IDE, keyed by the IDE option name (e.g. code, intellij, goland)
+	Images map[string]*IDEImages `json:"images,omitempty" validate:"omitempty,ide_images"`
+	// LatestChannel allows users to opt into the latest IDE versions. When disabled, the
+	// latest images are the same as the stable images. Defaults to true.
+	LatestChannel *bool `json:"latestChannel,omitempty"`
+}
+
+type IDEImages struct {
+	Image       string `json:"image,omitempty"`
+	LatestImage string `json:"latestImage,omitempty"`
 }
 
 type IDEMetrics struct {
-	ErrorReportingEnabled bool `json:"errorReportingEnabled,omitempty"`
+	// Enabled renders ide-metrics, which collects the metrics and errors of the IDEs in the
+	// workspaces. Defaults to true.
+	Enabled               *bool `json:"enabled,omitempty"`
+	ErrorReportingEnabled bool  `json:"errorReportingEnabled,omitempty"`
 }
 
 type PodConfig struct {
-	Replicas  *int32                                  `json:"replicas,omitempty"`
-	Resources map[string]*corev1.ResourceRequirements `json:"resources,omitempty"`
+	Replicas    *int32                                  `json:"replicas,omitempty"`
+	Resources   map[string]*corev1.ResourceRequirements `json:"resources,omitempty"`
+	Autoscaling *Autoscaling                            `json:"autoscaling,omitempty"`
+	// PodDisruptionBudget overrides the default budget of one unavailable pod, which is
+	// only rendered for the deployments and stateful sets with more than one replica
+	PodDisruptionBudget *PodDisruptionBudget `json:"podDisruptionBudget,omitempty"`
+	// NodeAffinity replaces the default node affinity of the component's pods
+	NodeAffinity *corev1.NodeAffinity `json:"nodeAffinity,omitempty"`
+	// Tolerations are added to the default tolerations of the component's pods
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// ServiceAccountAnnotations are added to the component's service account, e.g. to bind it
+	// to a cloud identity with iam.gke.io/gcp-service-account or eks.amazonaws.com/role-arn
+	ServiceAccountAnnotations map[string]string `json:"serviceAccountAnnotations,omitempty"`
+	// PriorityClassName replaces the priority class of the component's pods
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+	// LogLevel overrides observability.logLevel for the component
+	LogLevel LogLevel `json:"logLevel,omitempty" validate:"omitempty,log_level"`
+	// Probes overrides the settings of the probes of the component's containers, keyed by the
+	// name of the container
+	Probes map[string]*ContainerProbes `json:"probes,omitempty" validate:"omitempty,dive"`
+	// Env is added to the containers of the component's pods, replacing the env vars of the
+	// same name, e.g. to configure the agent of an APM vendor
+	Env []corev1.EnvVar `json:"env,omitempty"`
+	// EnvFrom adds the keys of the config maps and secrets as env vars of the component's containers
+	EnvFrom []corev1.EnvFromSource `json:"envFrom,omitempty"`
+	// Sidecars are added to the containers of the component's pods, after those of the component
+	Sidecars []corev1.Container `json:"sidecars,omitempty" validate:"unique=Name,dive"`
+	// InitContainers run after the init containers of the component's pods
+	InitContainers []corev1.Container `json:"initContainers,omitempty" validate:"unique=Name,dive"`
+	// Volumes are added to the component's pods for the sidecars and init containers to mount,
+	// which can also mount the volumes of the component
+	Volumes []corev1.Volume `json:"volumes,omitempty" validate:"unique=Name"`
+	// NodeSelector is added to the node selector of the component's pods
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// UpdateStrategy replaces the default rolling update of 20% of the nodes at a time. This is
+	// only supported for the daemon sets in DaemonSetComponentList.
+	UpdateStrategy *DaemonSetUpdateStrategy `json:"updateStrategy,omitempty"`
+}
+
+type DaemonSetUpdateStrategyType string
+
+const (
+	// DaemonSetUpdateRolling replaces the pods node by node with at most maxUnavailable nodes
+	// without a ready pod at a time
+	DaemonSetUpdateRolling DaemonSetUpdateStrategyType = "RollingUpdate"
+	// DaemonSetUpdateOnDelete only replaces a pod once it is deleted, e.g. when its node is
+	// drained or replaced
+	DaemonSetUpdateOnDelete DaemonSetUpdateStrategyType = "OnDelete"
+)
+
+type DaemonSetUpdateStrategy struct {
+	Type DaemonSetUpdateStrategyType `json:"type" validate:"required,daemon_set_update_strategy"`
+	// MaxUnavailable is a number of nodes or a percentage, only for the RollingUpdate
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+type Security struct {
+	// Hardened sets the security contexts the Pod Security Standard "restricted" requires. The
+	// components that need access to the nodes, such as ws-daemon and registry-facade, are
+	// not hardened.
+	Hardened bool `json:"hardened"`
+	// OptOut are the components whose pods are not hardened
+	OptOut []string `json:"optOut,omitempty" validate:"dive,required"`
+	// WritableRootFilesystem are the components whose containers keep a writable root
+	// filesystem, in addition to those that write to theirs
+	WritableRootFilesystem []string `json:"writableRootFilesystem,omitempty" validate:"dive,required"`
+}
+
+type ContainerProbes struct {
+	Liveness  *ProbeSettings `json:"liveness,omitempty"`
+	Readiness *ProbeSettings `json:"readiness,omitempty"`
+	// Startup adds a startup probe that checks the same as the liveness or readiness probe if
+	// the container has none, so a slow start does not fail the other probes
+	Startup *ProbeSettings `json:"startup,omitempty"`
+}
+
+// ProbeSettings overrides the timing of a probe, the settings that are not set keep their default
+type ProbeSettings struct {
+	InitialDelaySeconds *int32 `json:"initialDelaySeconds,omitempty" validate:"omitempty,min=0"`
+	PeriodSeconds       *int32 `json:"periodSeconds,omitempty" validate:"omitempty,min=1"`
+	TimeoutSeconds      *int32 `json:"timeoutSeconds,omitempty" validate:"omitempty,min=1"`
+	SuccessThreshold    *int32 `json:"successThreshold,omitempty" validate:"omitempty,min=1"`
+	FailureThreshold    *int32 `json:"failureThreshold,omitempty" validate:"omitempty,min=1"`
+}
+
+// FeatureFlags sets exactly one of the sources of the flags
+type FeatureFlags struct {
+	ConfigCat *FeatureFlagsConfigCat `json:"configcat,omitempty"`
+	// Static are the values of the flags by their name, they are the same for every user
+	Static map[string]interface{} `json:"static,omitempty"`
+}
+
+type FeatureFlagsConfigCat struct {
+	// BaseURL of the ConfigCat proxy, e.g. https://configcat.example.com
+	BaseURL string `json:"baseUrl" validate:"required,url"`
+	// SDKKey is a secret with the key of the flags in the sdkKey key
+	SDKKey ObjectRef `json:"sdkKey" validate:"required"`
+	// PollInterval is how often the flags are read from the proxy, defaults to a minute
+	PollInterval *util.Duration `json:"pollInterval,omitempty" validate:"omitempty,gt=0"`
+}
+
+type PriorityClasses struct {
+	Enabled bool `json:"enabled"`
+}
+
+type GRPCClients struct {
+	// DialTimeout is how long image-builder waits for the connection to ws-manager and
+	// ws-manager for those to ws-daemon, which it otherwise gives 10s
+	DialTimeout *util.Duration `json:"dialTimeout,omitempty" validate:"omitempty,gt=0"`
+	// MaxMessageSize is the size of the largest message the clients receive, including those
+	// of server and ws-manager-bridge. Defaults to 16Mi, which the logs of large image builds
+	// can exceed.
+	MaxMessageSize *resource.Quantity `json:"maxMessageSize,omitempty"`
+	// Retry retries the calls of image-builder and ws-manager that fail as the server is
+	// unavailable, e.g. while it restarts
+	Retry *GRPCRetryPolicy `json:"retry,omitempty"`
+}
+
+type GRPCRetryPolicy struct {
+	// MaxAttempts includes the first attempt, gRPC allows at most 5
+	MaxAttempts int `json:"maxAttempts" validate:"required,min=2,max=5"`
+	// InitialBackoff is the delay before the first retry, which doubles for every retry up to MaxBackoff
+	InitialBackoff util.Duration `json:"initialBackoff" validate:"required,gt=0"`
+	MaxBackoff     util.Duration `json:"maxBackoff" validate:"required,gtefield=InitialBackoff"`
+}
+
+type PodDisruptionBudget struct {
+	MinAvailable   *intstr.IntOrString `json:"minAvailable,omitempty" validate:"required_without=MaxUnavailable"`
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty" validate:"required_without=MinAvailable"`
+}
+
+// Autoscaling renders a HorizontalPodAutoscaler for the component, which then manages
+// the number of replicas instead of the replicas value.
+// This is only supported for the stateless components in AutoscalingComponentList.
+type Autoscaling struct {
+	MinReplicas *int32 `json:"minReplicas,omitempty" validate:"omitempty,min=1"`
+	MaxReplicas int32  `json:"maxReplicas" validate:"required,min=1"`
+	// Defaults to 80% CPU utilization if no target is configured
+	TargetCPUUtilizationPercentage    *int32 `json:"targetCPUUtilizationPercentage,omitempty" validate:"omitempty,min=1"`
+	TargetMemoryUtilizationPercentage *int32 `json:"targetMemoryUtilizationPercentage,omitempty" validate:"omitempty,min=1"`
 }
 
 type ProxyComponent struct {
 	Service *ComponentTypeService `json:"service,omitempty"`
+	// ProxyProtocol reads the address of the clients from the PROXY protocol header that a load
+	// balancer sends at the start of each connection
+	ProxyProtocol *ProxyProtocol `json:"proxyProtocol,omitempty"`
+	// TrustedProxies are the CIDRs of the load balancers whose X-Forwarded-For header is passed on.
+	// The header of any other client is replaced with the address of the client.
+	TrustedProxies []string `json:"trustedProxies,omitempty" validate:"dive,cidr"`
+	// ResponseHeaders are set on the responses of the dashboard and the workspaces. They replace the
+	// security headers of the proxy with the same name, and an empty value removes the header.
+	ResponseHeaders map[string]string `json:"responseHeaders,omitempty"`
+	// Timeouts of the connections of the clients. Raise the idle timeout above that of the load
+	// balancer in front of the proxy.
+	Timeouts *ServerTimeouts `json:"timeouts,omitempty"`
+}
+
+// ServerTimeouts are the timeouts of the connections of the clients of a proxy. Unset timeouts
+// keep the defaults of the proxy. The read and write timeouts do not end WebSocket connections.
+type ServerTimeouts struct {
+	// ReadHeader is how long the client may take to send the headers of a request
+	ReadHeader *util.Duration `json:"readHeader,omitempty" validate:"omitempty,gt=0"`
+	// Read is how long the client may take to send a request, including its body
+	Read *util.Duration `json:"read,omitempty" validate:"omitempty,gt=0"`
+	// Write is how long the proxy may take to write a response
+	Write *util.Duration `json:"write,omitempty" validate:"omitempty,gt=0"`
+	// Idle is how long a kept-alive connection is kept open without a request
+	Idle *util.Duration `json:"idle,omitempty" validate:"omitempty,gt=0"`
+}
+
+type ProxyProtocol struct {
+	Enabled bool `json:"enabled"`
+	// Allow are the CIDRs that may send the header. Defaults to any address.
+	Allow []string `json:"allow,omitempty" validate:"dive,cidr"`
+	// Timeout is how long to wait for the header. Defaults to 5 seconds.
+	Timeout *util.Duration `json:"timeout,omitempty" validate:"omitempty,gt=0"`
+}
+
+type ServerComponent struct {
+	// Replicas takes precedence over podConfig.server.replicas
+	Replicas *int32 `json:"replicas,omitempty" validate:"omitempty,gte=0"`
+	// MaxSurge and MaxUnavailable control the rolling update of the server pods. They default to
+	// one additional pod and none unavailable.
+	MaxSurge       *intstr.IntOrString `json:"maxSurge,omitempty"`
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+	// TerminationGracePeriodSeconds is the time the server pods have to finish their requests
+	// when they are stopped. Defaults to 30 seconds.
+	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty" validate:"omitempty,gte=0"`
+	// RateLimits are merged into the default rate limits of the server's API
+	RateLimits *ServerRateLimits `json:"rateLimits,omitempty"`
+	// BlockedRepositories are the repositories no workspace can be started from. They are checked
+	// before the blocked repositories that are added in the admin dashboard.
+	BlockedRepositories []ServerBlockedRepository `json:"blockedRepositories,omitempty" validate:"dive"`
+	// BlockedDomains are the hosts, including their subdomains, that no workspace can be started from
+	BlockedDomains []string `json:"blockedDomains,omitempty" validate:"dive,fqdn"`
+	// GitHubApp enables the prebuilds of the repositories the app is installed on
+	GitHubApp *ServerGitHubApp `json:"githubApp,omitempty"`
+	// AuthProviders are OAuth apps of the Git hosts that users sign in with. Unlike the secrets in
+	// authProviders, only the client secret has to be kept in a secret.
+	AuthProviders []ServerAuthProvider `json:"authProviders,omitempty" validate:"unique=ID,dive"`
+	// Session sets the lifetime and the cookie of the sessions of the users
+	Session *ServerSession `json:"session,omitempty"`
+	// WebSocketPingInterval is how often the server pings the clients of its WebSocket API, and
+	// how long they have to answer. Defaults to 30 seconds.
+	WebSocketPingInterval *util.Duration `json:"webSocketPingInterval,omitempty" validate:"omitempty,gt=0"`
+	// InstallationAdmin controls how the installation-admin port, which serves the telemetry
+	// data of the installation, is exposed. Defaults to cluster.
+	InstallationAdmin *ServerInstallationAdmin `json:"installationAdmin,omitempty"`
+}
+
+type InstallationAdminExposure string
+
+const (
+	// InstallationAdminDisabled removes the port from the service, which also stops the telemetry
+	InstallationAdminDisabled InstallationAdminExposure = "disabled"
+	// InstallationAdminCluster only lets the installation telemetry job in
+	InstallationAdminCluster InstallationAdminExposure = "cluster"
+	// InstallationAdminProxy also routes installation-admin.<domain> to the port behind basic auth
+	InstallationAdminProxy InstallationAdminExposure = "proxy"
+)
+
+type ServerInstallationAdmin struct {
+	Exposure InstallationAdminExposure `json:"exposure" validate:"required,oneof=disabled cluster proxy"`
+	// Credentials is a secret with the username and the bcrypt hash of the password the proxy
+	// route asks for, in username and passwordHash
+	Credentials *ObjectRef `json:"credentials,omitempty" validate:"required_if=Exposure proxy"`
+}
+
+type ServerSession struct {
+	// MaxAge is how long a session lasts without a request, defaults to 3 days. The server keeps
+	// it in milliseconds, so it is at most 24 days.
+	MaxAge *util.Duration       `json:"maxAge,omitempty" validate:"omitempty,gt=0"`
+	Cookie *ServerSessionCookie `json:"cookie,omitempty"`
+}
+
+type ServerSessionCookie struct {
+	// SameSite defaults to Lax, which the sign-in with the OAuth apps of the Git hosts needs.
+	// None requires a secure cookie.
+	SameSite string `json:"sameSite,omitempty" validate:"omitempty,oneof=Lax Strict None"`
+	// Secure sends the cookie only over HTTPS
+	Secure *bool `json:"secure,omitempty"`
+}
+
+type ServerAuthProvider struct {
+	ID   string `json:"id" validate:"required"`
+	Host string `json:"host" validate:"required,hostname_port|fqdn"`
+	Type string `json:"type" validate:"required,oneof=GitHub GitLab Bitbucket BitbucketServer"`
+	// ClientID is the ID of the OAuth app on the host
+	ClientID string `json:"clientId" validate:"required"`
+	// ClientSecret is a secret with the client secret of the OAuth app in the clientSecret key
+	ClientSecret ObjectRef `json:"clientSecret" validate:"required"`
+	// Scopes default to the scopes the server needs for the type of the host
+	Scopes []string `json:"scopes,omitempty"`
+	// AuthorizationURL and TokenURL default to the endpoints of the type of the host
+	AuthorizationURL string `json:"authorizationUrl,omitempty" validate:"omitempty,url"`
+	TokenURL         string `json:"tokenUrl,omitempty" validate:"omitempty,url"`
+	// CallbackURL defaults to https://<domain>/auth/<host>/callback and must be on the domain
+	CallbackURL string `json:"callbackUrl,omitempty" validate:"omitempty,url"`
+}
+
+// CallbackURLForDomain returns the URL the host redirects to after the user authorized the OAuth app
+func (p ServerAuthProvider) CallbackURLForDomain(domain string) string {
+	if p.CallbackURL != "" {
+		return p.CallbackURL
+	}
+	return fmt.Sprintf("https://%s/auth/%s/callback", domain, strings.ReplaceAll(p.Host, ":", "_"))
+}
+
+// ServerGitHubApp is the GitHub App that receives the webhooks of the repositories for prebuilds.
+// It takes precedence over experimental.webapp.server.githubApp.
+type ServerGitHubApp struct {
+	AppID int32 `json:"appId" validate:"required"`
+	// AuthProviderID is the ID of the auth provider of the GitHub host the app is installed on
+	AuthProviderID string `json:"authProviderId" validate:"required"`
+	// BaseURL is the API URL of a GitHub Enterprise host
+	BaseURL string `json:"baseUrl,omitempty" validate:"omitempty,url"`
+	// WebhookSecret is a secret with the webhook secret of the app in the webhookSecret key
+	WebhookSecret ObjectRef `json:"webhookSecret" validate:"required"`
+	// PrivateKey is a secret with the private key of the app in the privateKey key
+	PrivateKey      ObjectRef `json:"privateKey" validate:"required"`
+	MarketplaceName string    `json:"marketplaceName,omitempty"`
+}
+
+type ServerBlockedRepository struct {
+	// URLRegExp is matched against the context URL of the workspace
+	URLRegExp string `json:"urlRegExp" validate:"required"`
+	// BlockUser also blocks the user that tries to start the workspace
+	BlockUser bool `json:"blockUser"`
+}
+
+type ServerRateLimits struct {
+	// Groups are budgets of points per user that the methods of the group share, keyed by the
+	// group name. The server's groups, such as default and startWorkspace, can be overridden.
+	Groups map[string]ServerRateLimitGroup `json:"groups,omitempty" validate:"dive"`
+	// Methods assign API methods to a group, keyed by the method name, eg startWorkspace
+	Methods map[string]ServerRateLimitMethod `json:"methods,omitempty" validate:"dive"`
+	// Prebuilds limits the prebuilds that are started for each clone URL. The "*" key is the
+	// limit of the repositories without their own limit.
+	Prebuilds map[string]ServerPrebuildRateLimit `json:"prebuilds,omitempty" validate:"dive"`
+}
+
+type ServerRateLimitGroup struct {
+	Points          int32 `json:"points" validate:"required,gt=0"`
+	DurationSeconds int32 `json:"durationSeconds" validate:"required,gt=0"`
+}
+
+type ServerRateLimitMethod struct {
+	Group string `json:"group" validate:"required"`
+	// Points is the cost of each call. Defaults to 1.
+	Points *int32 `json:"points,omitempty" validate:"omitempty,gte=0"`
+}
+
+type ServerPrebuildRateLimit struct {
+	Limit         uint32 `json:"limit" validate:"required"`
+	PeriodSeconds uint32 `json:"periodSeconds" validate:"required"`
+}
+
+// WSDaemonComponent configures how ws-daemon shares the CPU, disks and processes of a node
+// between the workspaces on it. It takes precedence over the limits of the experimental config.
+type WSDaemonComponent struct {
+	CPULimits *WSDaemonCPULimits `json:"cpuLimits,omitempty"`
+	IOLimits  *WSDaemonIOLimits  `json:"ioLimits,omitempty"`
+	// ProcLimit is the maximum number of processes in a workspace, 0 means no limit
+	ProcLimit *int64 `json:"procLimit,omitempty" validate:"omitempty,gte=0"`
+	// NodeCgroupPath is where the cgroup filesystem is mounted on the nodes. Defaults to /sys/fs/cgroup.
+	NodeCgroupPath string `json:"nodeCgroupPath,omitempty" validate:"omitempty,startswith=/"`
+	// NodeProcMountsPath is the mount table of the nodes. Defaults to /proc/mounts.
+	NodeProcMountsPath string `json:"nodeProcMountsPath,omitempty" validate:"omitempty,startswith=/"`
+}
+
+type WSDaemonCPULimits struct {
+	Enabled bool `json:"enabled"`
+	// NodeBandwidth is the CPU of a node that is shared between its workspaces, e.g. 60 on a node with 64 cores
+	NodeBandwidth *resource.Quantity `json:"nodeBandwidth,omitempty"`
+	// Limit is the CPU every workspace gets when the node is busy
+	Limit *resource.Quantity `json:"limit,omitempty"`
+	// BurstLimit is the CPU a workspace can use while the node has CPU to spare
+	BurstLimit *resource.Quantity `json:"burstLimit,omitempty"`
+	// Buckets replace the limit with limits that go down as a workspace uses up the budget of
+	// each bucket in turn. The budget of the last bucket is ignored, its limit applies from then on.
+	Buckets []WSDaemonCPUBucket `json:"buckets,omitempty" validate:"omitempty,dive"`
+	// ControlPeriod is how often the limits are adjusted. Defaults to 15 seconds.
+	ControlPeriod *util.Duration `json:"controlPeriod,omitempty" validate:"omitempty,gt=0"`
+}
+
+type WSDaemonCPUBucket struct {
+	// Budget is the CPU time, e.g. 2h for two hours of one core or one hour of two cores
+	Budget util.Duration     `json:"budget"`
+	Limit  resource.Quantity `json:"limit"`
+}
+
+type WSDaemonIOLimits struct {
+	WriteBandwidthPerSecond *resource.Quantity `json:"writeBandwidthPerSecond,omitempty"`
+	ReadBandwidthPerSecond  *resource.Quantity `json:"readBandwidthPerSecond,omitempty"`
+	WriteIOPS               *int64             `json:"writeIOPS,omitempty" validate:"omitempty,gte=0"`
+	ReadIOPS                *int64             `json:"readIOPS,omitempty" validate:"omitempty,gte=0"`
+}
+
+type WSProxyComponent struct {
+	Service    *ComponentTypeService `json:"service,omitempty"`
+	SSHGateway *SSHGateway           `json:"sshGateway,omitempty"`
+	// Timeouts of the connections from the proxy to ws-proxy
+	Timeouts *ServerTimeouts `json:"timeouts,omitempty"`
+}
+
+type SSHGateway struct {
+	// Enabled defaults to true when a host key is set
+	Enabled *bool `json:"enabled,omitempty"`
+	// Port is the port of the proxy service that the SSH gateway is served on. Defaults to 22.
+	Port *int32 `json:"port,omitempty" validate:"omitempty,min=1,max=65535"`
+	// HostKey is a secret with the SSH host keys. Takes precedence over sshGatewayHostKey.
+	HostKey *ObjectRef `json:"hostKey,omitempty"`
+}
+
+// SSHGatewayHostKeySecret returns the secret with the host keys of the SSH gateway, or nil if the SSH gateway is disabled
+func (c *Config) SSHGatewayHostKeySecret() *ObjectRef {
+	hostKey := c.SSHGatewayHostKey
+	if c.Components == nil || c.Components.WSProxy == nil || c.Components.WSProxy.SSHGateway == nil {
+		return hostKey
+	}
+
+	gw := c.Components.WSProxy.SSHGateway
+	if gw.HostKey != nil {
+		hostKey = gw.HostKey
+	}
+	if !pointer.BoolDeref(gw.Enabled, true) {
+		return nil
+	}
+	return hostKey
+}
+
+// NamespaceScoped returns whether the installation only has permissions in its namespace
+func (c *Config) NamespaceScoped() bool {
+	return c.RBAC != nil && c.RBAC.Scope == RBACScopeNamespace
+}
+
+// PublicAPIEnabled returns whether the public API server is rendered
+func (c *Config) PublicAPIEnabled() bool {
+	return c.Components == nil || c.Components.PublicAPI == nil || pointer.BoolDeref(c.Components.PublicAPI.Enabled, true)
+}
+
+// toggleEnabled returns whether a component with the optional switch is rendered
+func toggleEnabled(t *ToggleComponent) bool {
+	return t == nil || pointer.BoolDeref(t.Enabled, true)
+}
+
+// DockerRegistryEnabled returns whether the in-cluster registry may be rendered. It is only
+// rendered if the container registry is in-cluster.
+func (c *Config) DockerRegistryEnabled() bool {
+	return c.Components == nil || toggleEnabled(c.Components.DockerRegistry)
+}
+
+// MinioEnabled returns whether minio may be rendered. It is only rendered if the object storage
+// is in-cluster.
+func (c *Config) MinioEnabled() bool {
+	return c.Components == nil || toggleEnabled(c.Components.Minio)
+}
+
+// OpenVSXProxyEnabled returns whether the openvsx-proxy is rendered. Without it, the extensions
+// are fetched from the OpenVSX URL.
+func (c *Config) OpenVSXProxyEnabled() bool {
+	return c.Components == nil || toggleEnabled(c.Components.OpenVSXProxy)
+}
+
+// IDEMetricsEnabled returns whether ide-metrics is rendered
+func (c *Config) IDEMetricsEnabled() bool {
+	if c.Components == nil || c.Components.IDE == nil || c.Components.IDE.Metrics == nil {
+		return true
+	}
+	return pointer.BoolDeref(c.Components.IDE.Metrics.Enabled, true)
+}
+
+// WorkspaceImageRegistries returns the registries the images of the workspaces are restricted to,
+// or nil if they can be from any registry
+func (c *Config) WorkspaceImageRegistries() []string {
+	if c.Workspace.ImagePolicy == nil {
+		return nil
+	}
+	return c.Workspace.ImagePolicy.AllowedRegistries
+}
+
+// InstallationAdminExposure returns how the installation-admin port of the server is exposed
+func (c *Config) InstallationAdminExposure() InstallationAdminExposure {
+	if c.Components == nil || c.Components.Server == nil || c.Components.Server.InstallationAdmin == nil {
+		return InstallationAdminCluster
+	}
+	return c.Components.Server.InstallationAdmin.Exposure
+}
+
+const (
+	// MinKubernetesVersion is the oldest Kubernetes version the objects can be rendered for, which
+	// serves the policy/v1 pod disruption budgets
+	MinKubernetesVersion = "1.21"
+	// KubernetesVersionAutoscalingV2 is the first Kubernetes version that serves autoscaling/v2
+	KubernetesVersionAutoscalingV2 = "1.23"
+	// KubernetesVersionNoPodSecurityPolicies is the Kubernetes version the pod security policies
+	// were removed in
+	KubernetesVersionNoPodSecurityPolicies = "1.25"
+)
+
+// KubernetesVersionAtLeast returns whether the objects are rendered for the Kubernetes version,
+// e.g. 1.25, or a later one. Only the minor versions are compared, so that the releases of the
+// cloud providers match.
+func (c *Config) KubernetesVersionAtLeast(version string) bool {
+	if c.KubernetesVersion == "" {
+		return true
+	}
+	current, err := semver.NewVersion(c.KubernetesVersion)
+	if err != nil {
+		// rejected by the validation
+		return true
+	}
+	min := semver.MustParse(version)
+	if current.Major() != min.Major() {
+		return current.Major() > min.Major()
+	}
+	return current.Minor() >= min.Minor()
+}
+
+// PublicAPIHostname returns the host the public API is served on
+func (c *Config) PublicAPIHostname() string {
+	if c.Components != nil && c.Components.PublicAPI != nil && c.Components.PublicAPI.Hostname != "" {
+		return c.Components.PublicAPI.Hostname
+	}
+	return "api." + c.Domain
+}
+
+// WorkspaceDomain returns the domain the workspaces of the cluster are served under
+func (c *Config) WorkspaceDomain() string {
+	if c.Workspace.Domain != "" {
+		return c.Workspace.Domain
+	}
+	if c.Metadata.InstallationShortname != "" && c.Metadata.InstallationShortname != InstallationShortNameOldDefault {
+		return fmt.Sprintf("ws-%s.%s", c.Metadata.InstallationShortname, c.Domain)
+	}
+	return "ws." + c.Domain
+}
+
+// WorkspaceDomains returns the domain of the workspaces of the cluster and the additional
+// workspace domains
+func (c *Config) WorkspaceDomains() []string {
+	res := []string{c.WorkspaceDomain()}
+	for _, d := range c.Workspace.AdditionalDomains {
+		if d != res[0] {
+			res = append(res, d)
+		}
+	}
+	return res
+}
+
+// WorkspaceDomainCovered returns whether the wildcard of the workspaces of the domain covers
+// the hosts of the workspaces of the workspace domain
+func (c *Config) WorkspaceDomainCovered(domain string) bool {
+	sub := strings.TrimSuffix(domain, "."+c.Domain)
+	return sub != domain && !strings.Contains(sub, ".")
+}
+
+// IDEAssetsHost returns the host the browsers load the IDEs and their assets from
+func (c *Config) IDEAssetsHost() string {
+	if c.Components != nil && c.Components.IDEProxy != nil && c.Components.IDEProxy.CDN != nil {
+		return c.Components.IDEProxy.CDN.Host
+	}
+	return "ide." + c.Domain
+}
+
+// PublicAPIHostnameCovered returns whether the wildcard of the domain covers the host of the public API
+func (c *Config) PublicAPIHostnameCovered() bool {
+	sub := strings.TrimSuffix(c.PublicAPIHostname(), "."+c.Domain)
+	return sub != c.PublicAPIHostname() && !strings.Contains(sub, ".")
+}
+
+// TelemetryDisabled is true when nothing is sent to Gitpod or to Segment
+func (c *Config) TelemetryDisabled() bool {
+	return c.Telemetry != nil && c.Telemetry.Disabled
+}
+
+// SSHGatewayPort returns the port that the SSH gateway is served on
+func (c *Config) SSHGatewayPort() int32 {
+	if c.Components != nil && c.Components.WSProxy != nil && c.Components.WSProxy.SSHGateway != nil && c.Components.WSProxy.SSHGateway.Port != nil {
+		return *c.Components.WSProxy.SSHGateway.Port
+	}
+	return 22
 }
 
 type ComponentTypeService struct {
 	ServiceType *corev1.ServiceType `json:"serviceType,omitempty" validate:"omitempty,service_config_type"`
+	// Annotations are added to the service, e.g. to configure the cloud provider's load balancer
+	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
 type TelemetryConfig struct {
-	Data *TelemetryData `json:"data,omitempty"`
+	// Disabled removes the job that sends the installation's telemetry and turns off the analytics
+	// of the components
+	Disabled bool           `json:"disabled,omitempty"`
+	Data     *TelemetryData `json:"data,omitempty"`
 }
 
 type TelemetryData struct {
@@ -29,7 +29,7 @@ var deprecatedFields = map[string]deprecatedField{
 			if cfg.Components == nil {
 				cfg.Components = &Components{}
 			}
-			cfg.Components.AgentSmith = cfg.Experimental.AgentSmith
+			cfg.Components.AgentSmith = &AgentSmithComponent{Config: *cfg.Experimental.AgentSmith}
 			return nil
 		},
 	},
@@ -11,6 +11,7 @@
 package experimental
 
 import (
+	"embed"
 	"time"
 
 	agentSmith "github.com/gitpod-io/gitpod/agent-smith/pkg/config"
@@ -22,6 +23,11 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// Source is the source of the config structs, for the descriptions of the schema
+//
+//go:embed experimental.go
+var Source embed.FS
+
 // Config contains all experimental configuration.
 type Config struct {
 	Workspace  *WorkspaceConfig   `json:"workspace,omitempty"`
@@ -257,7 +263,7 @@ type ServerConfig struct {
 	WorkspaceDefaults                 WorkspaceDefaults `json:"workspaceDefaults"`
 	OAuthServer                       OAuthServer       `json:"oauthServer"`
 	Session                           Session           `json:"session"`
-	GithubApp                         *GithubApp        `json:"githubApp"`
+	GithubApp                         *GithubApp        `json:"githubApp"` // @deprecated use components.server.githubApp instead
 	ChargebeeSecret                   string            `json:"chargebeeSecret"`
 	StripeSecret                      string            `json:"stripeSecret"`
 	StripeConfig                      string            `json:"stripeConfig"`
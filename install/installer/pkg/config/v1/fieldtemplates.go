@@ -0,0 +1,161 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// FieldTemplateValues are the values the templated fields of the config are executed with,
+// e.g. {{ .Namespace }}
+type FieldTemplateValues struct {
+	// Namespace is the namespace the installation is rendered for
+	Namespace string
+	// Version is the version of the installation
+	Version string
+	// Kind is the kind of the installation
+	Kind InstallationKind
+}
+
+// templatedString is a field of the config that may be a Go template
+type templatedString struct {
+	Field string
+	Value *string
+}
+
+// templatedMap is a map of the config whose values may be Go templates
+type templatedMap struct {
+	Field string
+	Value map[string]string
+}
+
+// templatedFields returns the domains, the bucket names and the annotations of the config,
+// which are the fields that differ between the environments an installation is rendered for
+func (c *Config) templatedFields() ([]templatedString, []templatedMap) {
+	strs := []templatedString{
+		{Field: "domain", Value: &c.Domain},
+		{Field: "workspace.domain", Value: &c.Workspace.Domain},
+	}
+	for i := range c.Workspace.AdditionalDomains {
+		strs = append(strs, templatedString{Field: fmt.Sprintf("workspace.additionalDomains[%d]", i), Value: &c.Workspace.AdditionalDomains[i]})
+	}
+	if c.ObjectStorage.S3 != nil {
+		strs = append(strs, templatedString{Field: "objectStorage.s3.bucket", Value: &c.ObjectStorage.S3.BucketName})
+	}
+	if c.ContainerRegistry.S3Storage != nil {
+		strs = append(strs, templatedString{Field: "containerRegistry.s3storage.bucket", Value: &c.ContainerRegistry.S3Storage.Bucket})
+	}
+
+	maps := []templatedMap{}
+	if c.OpenVSX.Proxy != nil {
+		maps = append(maps, templatedMap{Field: "openVSX.proxy.serviceAnnotations", Value: c.OpenVSX.Proxy.ServiceAnnotations})
+	}
+	if c.Customization != nil {
+		for i, cust := range *c.Customization {
+			maps = append(maps, templatedMap{Field: fmt.Sprintf("customization[%d].metadata.annotations", i), Value: cust.Metadata.Annotations})
+		}
+	}
+
+	comps := c.Components
+	if comps == nil {
+		return strs, maps
+	}
+	if comps.PublicAPI != nil {
+		strs = append(strs, templatedString{Field: "components.publicApi.hostname", Value: &comps.PublicAPI.Hostname})
+	}
+	if comps.IDE != nil && comps.IDE.Proxy != nil {
+		maps = append(maps, templatedMap{Field: "components.ide.proxy.serviceAnnotations", Value: comps.IDE.Proxy.ServiceAnnotations})
+	}
+	if comps.Proxy != nil && comps.Proxy.Service != nil {
+		maps = append(maps, templatedMap{Field: "components.proxy.service.annotations", Value: comps.Proxy.Service.Annotations})
+	}
+	if comps.WSProxy != nil && comps.WSProxy.Service != nil {
+		maps = append(maps, templatedMap{Field: "components.wsProxy.service.annotations", Value: comps.WSProxy.Service.Annotations})
+	}
+	names := make([]string, 0, len(comps.PodConfig))
+	for name := range comps.PodConfig {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if pc := comps.PodConfig[name]; pc != nil {
+			maps = append(maps, templatedMap{Field: fmt.Sprintf("components.podConfig.%s.serviceAccountAnnotations", name), Value: pc.ServiceAccountAnnotations})
+		}
+	}
+
+	return strs, maps
+}
+
+// HasFieldTemplates returns whether any of the templated fields of the config is a Go template
+func (c *Config) HasFieldTemplates() bool {
+	strs, maps := c.templatedFields()
+	for _, s := range strs {
+		if isFieldTemplate(*s.Value) {
+			return true
+		}
+	}
+	for _, m := range maps {
+		for _, v := range m.Value {
+			if isFieldTemplate(v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ExecuteFieldTemplates replaces the Go templates in the domains, the bucket names and the
+// annotations of the config with their result, so that one config can be rendered for several
+// environments. The config is validated afterwards, an unknown value fails the template.
+func (c *Config) ExecuteFieldTemplates(values FieldTemplateValues) error {
+	strs, maps := c.templatedFields()
+	for _, s := range strs {
+		res, err := executeFieldTemplate(s.Field, *s.Value, values)
+		if err != nil {
+			return err
+		}
+		*s.Value = res
+	}
+	for _, m := range maps {
+		// the keys are sorted so that the same template always fails first
+		keys := make([]string, 0, len(m.Value))
+		for k := range m.Value {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			res, err := executeFieldTemplate(fmt.Sprintf("%s[%s]", m.Field, k), m.Value[k], values)
+			if err != nil {
+				return err
+			}
+			m.Value[k] = res
+		}
+	}
+	return nil
+}
+
+func isFieldTemplate(value string) bool {
+	return strings.Contains(value, "{{")
+}
+
+func executeFieldTemplate(field, value string, values FieldTemplateValues) (string, error) {
+	if !isFieldTemplate(value) {
+		return value, nil
+	}
+
+	tpl, err := template.New(field).Option("missingkey=error").Parse(value)
+	if err != nil {
+		return "", fmt.Errorf("cannot parse the template of %s: %w", field, err)
+	}
+	var res bytes.Buffer
+	if err := tpl.Execute(&res, values); err != nil {
+		return "", fmt.Errorf("cannot execute the template of %s: %w", field, err)
+	}
+	return res.String(), nil
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/config"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+)
+
+func (v version) Lint(rcfg interface{}) []config.LintFinding {
+	cfg := rcfg.(*Config)
+
+	var res []config.LintFinding
+	add := func(rule string, severity config.LintSeverity, field string, message string) {
+		res = append(res, config.LintFinding{Rule: rule, Severity: severity, Field: field, Message: message})
+	}
+
+	if pointer.BoolDeref(cfg.Database.InCluster, false) {
+		add("in-cluster-database", config.LintSeverityWarning, "database.inCluster", "The in-cluster MySQL is a single pod without backups, use CloudSQL or an external database in production")
+	}
+	if pointer.BoolDeref(cfg.ObjectStorage.InCluster, false) {
+		add("in-cluster-storage", config.LintSeverityWarning, "objectStorage.inCluster", "The in-cluster MinIO is a single pod without backups, use S3 or Cloud Storage in production")
+	}
+	if pointer.BoolDeref(cfg.ContainerRegistry.InCluster, false) {
+		add("in-cluster-registry", config.LintSeverityInfo, "containerRegistry.inCluster", "The images of the workspaces are stored in the in-cluster registry, an external registry survives the loss of the cluster")
+	}
+
+	if cfg.Experimental != nil {
+		add("experimental", config.LintSeverityWarning, "experimental", "The experimental config changes between releases without notice and is not supported for stable installations")
+	}
+
+	if cfg.Observability.LogLevel == LogLevelDebug || cfg.Observability.LogLevel == LogLevelTrace {
+		add("debug-logging", config.LintSeverityInfo, "observability.logLevel", fmt.Sprintf("The %s log level logs the details of every request, use info in production", cfg.Observability.LogLevel))
+	}
+
+	if ports := debugPortComponents(cfg); len(ports) > 0 {
+		add("debug-ports", config.LintSeverityWarning, "network.debugPorts", fmt.Sprintf("The debug and profiling ports of %s accept connections, which expose the internals of the components", strings.Join(ports, ", ")))
+	}
+
+	if missing := missingRequests(cfg.Workspace.Resources.Requests); len(missing) > 0 {
+		add("resource-requests", config.LintSeverityWarning, "workspace.resources.requests", fmt.Sprintf("The workspaces request no %s, so the scheduler overcommits the nodes", strings.Join(missing, " or ")))
+	}
+	for i, class := range cfg.Workspace.Classes {
+		if missing := missingRequests(class.Resources.Requests); len(missing) > 0 {
+			add("resource-requests", config.LintSeverityWarning, fmt.Sprintf("workspace.classes[%d].resources.requests", i), fmt.Sprintf("The workspaces of class %s request no %s, so the scheduler overcommits the nodes", class.Name, strings.Join(missing, " or ")))
+		}
+	}
+	if cfg.Components != nil {
+		for component, pod := range cfg.Components.PodConfig {
+			if pod == nil {
+				continue
+			}
+			for container, resources := range pod.Resources {
+				// Kubernetes requests the limits of a container that only has limits
+				if resources == nil || len(resources.Limits) > 0 {
+					continue
+				}
+				if missing := missingRequests(resources.Requests); len(missing) > 0 {
+					add("resource-requests", config.LintSeverityWarning, fmt.Sprintf("components.podConfig.%s.resources.%s.requests", component, container), fmt.Sprintf("The %s container of %s requests no %s, which replaces the requests of the installer", container, component, strings.Join(missing, " or ")))
+				}
+			}
+		}
+	}
+
+	return res
+}
+
+// debugPortComponents returns the components whose debug ports are enabled
+func debugPortComponents(cfg *Config) []string {
+	if cfg.Network == nil || cfg.Network.DebugPorts == nil {
+		return nil
+	}
+	ports := cfg.Network.DebugPorts
+
+	// the components that differ from the default
+	var overrides []string
+	for component, enabled := range ports.Components {
+		if enabled != ports.Enabled {
+			overrides = append(overrides, component)
+		}
+	}
+	sort.Strings(overrides)
+
+	if !ports.Enabled {
+		return overrides
+	}
+	if len(overrides) == 0 {
+		return []string{"every component"}
+	}
+	return []string{"every component but " + strings.Join(overrides, ", ")}
+}
+
+func missingRequests(requests corev1.ResourceList) []string {
+	var res []string
+	for _, r := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		if _, ok := requests[r]; !ok {
+			res = append(res, string(r))
+		}
+	}
+	return res
+}
@@ -5,8 +5,11 @@
 package config
 
 import (
+	"fmt"
 	"io/ioutil"
+	"path/filepath"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/utils/pointer"
 	"sigs.k8s.io/yaml"
 )
@@ -25,6 +28,50 @@ func Load(fn string) (*Config, error) {
 	return &cfg, nil
 }
 
+// LoadFiles reads the pod templates that are referenced by files. Relative paths are resolved from dir.
+// A template cannot be set both in the config and in a file.
+func (t *WorkspaceTemplates) LoadFiles(dir string) error {
+	if t == nil || t.Files == nil {
+		return nil
+	}
+
+	ops := []struct {
+		Name string
+		File string
+		Tpl  **corev1.Pod
+	}{
+		{Name: "default", File: t.Files.Default, Tpl: &t.Default},
+		{Name: "prebuild", File: t.Files.Prebuild, Tpl: &t.Prebuild},
+		{Name: "imagebuild", File: t.Files.ImageBuild, Tpl: &t.ImageBuild},
+		{Name: "regular", File: t.Files.Regular, Tpl: &t.Regular},
+	}
+	for _, op := range ops {
+		if op.File == "" {
+			continue
+		}
+		if *op.Tpl != nil {
+			return fmt.Errorf("%s workspace template is set both in the config and in file %s", op.Name, op.File)
+		}
+
+		fn := op.File
+		if !filepath.IsAbs(fn) {
+			fn = filepath.Join(dir, fn)
+		}
+		fc, err := ioutil.ReadFile(fn)
+		if err != nil {
+			return fmt.Errorf("cannot read %s workspace template: %w", op.Name, err)
+		}
+		var pod corev1.Pod
+		err = yaml.UnmarshalStrict(fc, &pod)
+		if err != nil {
+			return fmt.Errorf("cannot parse %s workspace template %s: %w", op.Name, op.File, err)
+		}
+		*op.Tpl = &pod
+	}
+
+	return nil
+}
+
 // LoadMock produces a valid but non-sensical configuration useful for testing
 func LoadMock() *Config {
 	return &Config{
@@ -0,0 +1,83 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestWorkspaceTemplatesLoadFiles(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "templates"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "templates", "default.yaml"), []byte("metadata:\n  labels:\n    template: default\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "invalid.yaml"), []byte("spec:\n  unknownField: true\n"), 0644))
+
+	inline := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"template": "inline"}}}
+	fromFile := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"template": "default"}}}
+
+	tests := []struct {
+		Name      string
+		Templates *WorkspaceTemplates
+		Expected  *WorkspaceTemplates
+		Error     string
+	}{
+		{
+			Name: "no templates",
+		},
+		{
+			Name:      "no files",
+			Templates: &WorkspaceTemplates{Default: inline},
+			Expected:  &WorkspaceTemplates{Default: inline},
+		},
+		{
+			Name:      "relative path",
+			Templates: &WorkspaceTemplates{Files: &WorkspaceTemplateFiles{Default: "templates/default.yaml"}},
+			Expected:  &WorkspaceTemplates{Default: fromFile, Files: &WorkspaceTemplateFiles{Default: "templates/default.yaml"}},
+		},
+		{
+			Name:      "absolute path",
+			Templates: &WorkspaceTemplates{Files: &WorkspaceTemplateFiles{Prebuild: filepath.Join(dir, "templates", "default.yaml")}},
+			Expected:  &WorkspaceTemplates{Prebuild: fromFile, Files: &WorkspaceTemplateFiles{Prebuild: filepath.Join(dir, "templates", "default.yaml")}},
+		},
+		{
+			Name:      "inline and file of different templates",
+			Templates: &WorkspaceTemplates{Default: inline, Files: &WorkspaceTemplateFiles{Regular: "templates/default.yaml"}},
+			Expected:  &WorkspaceTemplates{Default: inline, Regular: fromFile, Files: &WorkspaceTemplateFiles{Regular: "templates/default.yaml"}},
+		},
+		{
+			Name:      "inline and file of the same template",
+			Templates: &WorkspaceTemplates{ImageBuild: inline, Files: &WorkspaceTemplateFiles{ImageBuild: "templates/default.yaml"}},
+			Error:     "imagebuild workspace template is set both in the config and in file templates/default.yaml",
+		},
+		{
+			Name:      "missing file",
+			Templates: &WorkspaceTemplates{Files: &WorkspaceTemplateFiles{Default: "templates/missing.yaml"}},
+			Error:     "cannot read default workspace template",
+		},
+		{
+			Name:      "invalid template",
+			Templates: &WorkspaceTemplates{Files: &WorkspaceTemplateFiles{Default: "invalid.yaml"}},
+			Error:     "cannot parse default workspace template invalid.yaml",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			err := test.Templates.LoadFiles(dir)
+			if test.Error != "" {
+				require.ErrorContains(t, err, test.Error)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.Expected, test.Templates)
+		})
+	}
+}
@@ -5,10 +5,17 @@
 package config
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"math"
+	"net/url"
 	"regexp"
+	"strings"
+	"time"
 
+	"github.com/Masterminds/semver"
+	"github.com/docker/distribution/reference"
 	"github.com/gitpod-io/gitpod/installer/pkg/cluster"
 	"github.com/gitpod-io/gitpod/installer/pkg/config/v1/experimental"
 	"golang.org/x/crypto/ssh"
@@ -16,7 +23,10 @@ import (
 
 	"github.com/go-playground/validator/v10"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/rest"
+	"k8s.io/utils/pointer"
 )
 
 var InstallationKindList = map[InstallationKind]struct{}{
@@ -37,6 +47,11 @@ var LogLevelList = map[LogLevel]struct{}{
 	LogLevelPanic:   {},
 }
 
+var LogFormatList = map[LogFormat]struct{}{
+	LogFormatJSON: {},
+	LogFormatText: {},
+}
+
 var ObjectRefKindList = map[ObjectRefKind]struct{}{
 	ObjectRefSecret: {},
 }
@@ -46,6 +61,118 @@ var FSShiftMethodList = map[FSShiftMethod]struct{}{
 	FSShiftShiftFS: {},
 }
 
+var PlatformKindList = map[PlatformKind]struct{}{
+	PlatformKubernetes: {},
+	PlatformOpenShift:  {},
+}
+
+var NetworkPolicyModeList = map[NetworkPolicyMode]struct{}{
+	NetworkPolicyDefault: {},
+	NetworkPolicyStrict:  {},
+}
+
+var DNS01ProviderList = map[DNS01Provider]struct{}{
+	DNS01ProviderCloudDNS:   {},
+	DNS01ProviderCloudflare: {},
+	DNS01ProviderRoute53:    {},
+}
+
+var MeshKindList = map[MeshKind]struct{}{
+	MeshIstio:   {},
+	MeshLinkerd: {},
+}
+
+var DatabaseSSLModeList = map[DatabaseSSLMode]struct{}{
+	DatabaseSSLModeRequired:   {},
+	DatabaseSSLModeSkipVerify: {},
+}
+
+var RBACScopeList = map[RBACScope]struct{}{
+	RBACScopeCluster:   {},
+	RBACScopeNamespace: {},
+}
+
+// ServerRateLimitGroupList are the rate limit groups the server defines itself
+var ServerRateLimitGroupList = map[string]struct{}{
+	"default":               {},
+	"startWorkspace":        {},
+	"createWorkspace":       {},
+	"phoneVerification":     {},
+	"sendHeartBeat":         {},
+	"inWorkspaceUserAction": {},
+}
+
+var SecretGroupList = map[SecretGroup]struct{}{
+	SecretGroupRegistry: {},
+	SecretGroupStorage:  {},
+	SecretGroupServer:   {},
+}
+
+var IPFamilyPolicyList = map[corev1.IPFamilyPolicyType]struct{}{
+	corev1.IPFamilyPolicySingleStack:      {},
+	corev1.IPFamilyPolicyPreferDualStack:  {},
+	corev1.IPFamilyPolicyRequireDualStack: {},
+}
+
+var IPFamilyList = map[corev1.IPFamily]struct{}{
+	corev1.IPv4Protocol: {},
+	corev1.IPv6Protocol: {},
+}
+
+var CustomizationPatchTypeList = map[CustomizationPatchType]struct{}{
+	CustomizationPatchStrategic: {},
+	CustomizationPatchJSON:      {},
+}
+
+var AutoscalingComponentList = map[string]struct{}{
+	"dashboard":         {},
+	"proxy":             {},
+	"public-api-server": {},
+	"server":            {},
+	"ws-proxy":          {},
+}
+
+// DaemonSetComponentList are the components that are rendered as daemon sets
+var DaemonSetComponentList = map[string]struct{}{
+	"agent-smith":     {},
+	"registry-facade": {},
+	"ws-daemon":       {},
+}
+
+var DaemonSetUpdateStrategyTypeList = map[DaemonSetUpdateStrategyType]struct{}{
+	DaemonSetUpdateRolling:  {},
+	DaemonSetUpdateOnDelete: {},
+}
+
+var IDEOptionList = map[string]struct{}{
+	"code":         {},
+	"code-desktop": {},
+	"intellij":     {},
+	"goland":       {},
+	"pycharm":      {},
+	"phpstorm":     {},
+	"rubymine":     {},
+	"webstorm":     {},
+	"rider":        {},
+	"clion":        {},
+}
+
+// workspaceClassDefaultName is reserved for the class that is built from the workspace resources
+const workspaceClassDefaultName = "default"
+
+// httpHeaderNameRegexp matches the tokens of RFC 7230 that header names consist of
+var httpHeaderNameRegexp = regexp.MustCompile("^[!#$%&'*+\\-.^_`|~0-9A-Za-z]+$")
+
+// prometheusLabelNameRegexp matches the Prometheus label names that are not reserved for internal use
+var prometheusLabelNameRegexp = regexp.MustCompile("^[a-zA-Z][a-zA-Z0-9_]*$")
+
+// kmsKeyARNRegexp matches the ARNs of the keys and aliases of AWS KMS
+var kmsKeyARNRegexp = regexp.MustCompile(`^arn:aws[a-z-]*:kms:[a-z0-9-]+:[0-9]{12}:(key|alias)/[a-zA-Z0-9/_-]+$`)
+
+// s3BucketOwnerIDLength is the length of the IDs of the users and teams, the UUIDs that are
+// appended to the bucket prefix
+const s3BucketOwnerIDLength = 36
+
 // LoadValidationFuncs load custom validation functions for this version of the config API
 func (v version) LoadValidationFuncs(validate *validator.Validate) error {
 	funcs := map[string]validator.Func{
@@ -65,6 +192,155 @@ func (v version) LoadValidationFuncs(validate *validator.Validate) error {
 			_, ok := LogLevelList[LogLevel(fl.Field().String())]
 			return ok
 		},
+		"log_format": func(fl validator.FieldLevel) bool {
+			_, ok := LogFormatList[LogFormat(fl.Field().String())]
+			return ok
+		},
+		"platform_kind": func(fl validator.FieldLevel) bool {
+			_, ok := PlatformKindList[PlatformKind(fl.Field().String())]
+			return ok
+		},
+		"network_policy_mode": func(fl validator.FieldLevel) bool {
+			_, ok := NetworkPolicyModeList[NetworkPolicyMode(fl.Field().String())]
+			return ok
+		},
+		"dns01_provider": func(fl validator.FieldLevel) bool {
+			_, ok := DNS01ProviderList[DNS01Provider(fl.Field().String())]
+			return ok
+		},
+		"database_ssl_mode": func(fl validator.FieldLevel) bool {
+			_, ok := DatabaseSSLModeList[DatabaseSSLMode(fl.Field().String())]
+			return ok
+		},
+		"rbac_scope": func(fl validator.FieldLevel) bool {
+			_, ok := RBACScopeList[RBACScope(fl.Field().String())]
+			return ok
+		},
+		"mesh_kind": func(fl validator.FieldLevel) bool {
+			_, ok := MeshKindList[MeshKind(fl.Field().String())]
+			return ok
+		},
+		"ip_family_policy": func(fl validator.FieldLevel) bool {
+			_, ok := IPFamilyPolicyList[corev1.IPFamilyPolicyType(fl.Field().String())]
+			return ok
+		},
+		"ip_family": func(fl validator.FieldLevel) bool {
+			_, ok := IPFamilyList[corev1.IPFamily(fl.Field().String())]
+			return ok
+		},
+		"customization_patch_type": func(fl validator.FieldLevel) bool {
+			_, ok := CustomizationPatchTypeList[CustomizationPatchType(fl.Field().String())]
+			return ok
+		},
+		"kubernetes_version": func(fl validator.FieldLevel) bool {
+			version := fl.Field().String()
+			if _, err := semver.NewVersion(version); err != nil {
+				return false
+			}
+			cfg := Config{KubernetesVersion: version}
+			return cfg.KubernetesVersionAtLeast(MinKubernetesVersion)
+		},
+		"autoscaling_components": func(fl validator.FieldLevel) bool {
+			podConfig, ok := fl.Field().Interface().(map[string]*PodConfig)
+			if !ok {
+				return false
+			}
+
+			for component, cfg := range podConfig {
+				if cfg == nil || cfg.Autoscaling == nil {
+					continue
+				}
+				if _, ok := AutoscalingComponentList[component]; !ok {
+					return false
+				}
+			}
+			return true
+		},
+		"daemon_set_components": func(fl validator.FieldLevel) bool {
+			podConfig, ok := fl.Field().Interface().(map[string]*PodConfig)
+			if !ok {
+				return false
+			}
+
+			for component, cfg := range podConfig {
+				if cfg == nil || cfg.UpdateStrategy == nil {
+					continue
+				}
+				if _, ok := DaemonSetComponentList[component]; !ok {
+					return false
+				}
+			}
+			return true
+		},
+		"daemon_set_update_strategy": func(fl validator.FieldLevel) bool {
+			_, ok := DaemonSetUpdateStrategyTypeList[DaemonSetUpdateStrategyType(fl.Field().String())]
+			return ok
+		},
+		"pod_disruption_budgets": func(fl validator.FieldLevel) bool {
+			podConfig, ok := fl.Field().Interface().(map[string]*PodConfig)
+			if !ok {
+				return false
+			}
+
+			for component, cfg := range podConfig {
+				if cfg == nil || cfg.PodDisruptionBudget == nil {
+					continue
+				}
+				if cfg.PodDisruptionBudget.MinAvailable != nil && cfg.PodDisruptionBudget.MaxUnavailable != nil {
+					return false
+				}
+				// The pods of a daemon set are not evicted when a node is drained, a budget would not apply
+				if _, ok := DaemonSetComponentList[component]; ok {
+					return false
+				}
+			}
+			return true
+		},
+		"ide_images": func(fl validator.FieldLevel) bool {
+			images, ok := fl.Field().Interface().(map[string]*IDEImages)
+			if !ok {
+				return false
+			}
+
+			for name := range images {
+				if _, ok := IDEOptionList[name]; !ok {
+					return false
+				}
+			}
+			return true
+		},
+		"secret_groups": func(fl validator.FieldLevel) bool {
+			rotations, ok := fl.Field().Interface().(map[SecretGroup]int)
+			if !ok {
+				return false
+			}
+
+			for group := range rotations {
+				if _, ok := SecretGroupList[group]; !ok {
+					return false
+				}
+			}
+			return true
+		},
+		"workspace_classes": func(fl validator.FieldLevel) bool {
+			classes, ok := fl.Field().Interface().([]WorkspaceClass)
+			if !ok {
+				return false
+			}
+
+			names := make(map[string]struct{}, len(classes))
+			var defaults int
+			for _, c := range classes {
+				if _, ok := names[c.Name]; ok || c.Name == workspaceClassDefaultName {
+					return false
+				}
+				names[c.Name] = struct{}{}
+				if c.Default {
+					defaults++
+				}
+			}
+			return defaults <= 1
+		},
 		"block_new_users_passlist": func(fl validator.FieldLevel) bool {
 			if !fl.Parent().FieldByName("Enabled").Bool() {
 				// Not enabled - it's valid
@@ -110,6 +386,467 @@ func (v version) LoadValidationFuncs(validate *validator.Validate) error {
 		}
 	}
 
+	validate.RegisterStructValidation(func(sl validator.StructLevel) {
+		db := sl.Current().Interface().(Database)
+		if db.External == nil || db.External.Postgres == nil {
+			return
+		}
+
+		// Postgres cannot be mixed with any of the MySQL databases
+		if pointer.BoolDeref(db.InCluster, false) || db.CloudSQL != nil || db.External.Certificate != nil {
+			sl.ReportError(db.External.Postgres, "External.Postgres", "Postgres", "database_engine", "")
+		}
+	}, Database{})
+
+	validate.RegisterStructValidation(func(sl validator.StructLevel) {
+		server := sl.Current().Interface().(ServerComponent)
+
+		// A rolling update must be able to either add or remove a pod
+		isZero := func(v *intstr.IntOrString) bool {
+			return v != nil && (v.String() == "0" || v.String() == "0%")
+		}
+		if isZero(server.MaxSurge) && isZero(server.MaxUnavailable) {
+			sl.ReportError(server.MaxUnavailable, "MaxUnavailable", "MaxUnavailable", "server_rollout", "")
+		}
+
+		for i, r := range server.BlockedRepositories {
+			if _, err := regexp.Compile(r.URLRegExp); err != nil {
+				sl.ReportError(r.URLRegExp, fmt.Sprintf("BlockedRepositories[%d].URLRegExp", i), "URLRegExp", "server_blocked_repository", "")
+			}
+		}
+	}, ServerComponent{})
+
+	validate.RegisterStructValidation(func(sl validator.StructLevel) {
+		ws := sl.Current().Interface().(Workspace)
+
+		// The prebuilds can only run in a class that exists
+		if ws.Prebuilds != nil && ws.Prebuilds.Class != "" && ws.Prebuilds.Class != workspaceClassDefaultName {
+			found := false
+			for _, c := range ws.Classes {
+				found = found || c.Name == ws.Prebuilds.Class
+			}
+			if !found {
+				sl.ReportError(ws.Prebuilds.Class, "Prebuilds.Class", "Class", "prebuild_workspace_class", "")
+			}
+		}
+
+		// The ports cannot be public by default if they cannot be public at all
+		if ws.Ports != nil && ws.Ports.DisablePublic && ws.Ports.DefaultVisibility == "public" {
+			sl.ReportError(ws.Ports.DefaultVisibility, "Ports.DefaultVisibility", "DefaultVisibility", "workspace_ports_public", "")
+		}
+
+		// The content is initialized while the workspace starts
+		if ws.TimeoutInitialization != nil && ws.TimeoutStartup != nil && *ws.TimeoutInitialization > *ws.TimeoutStartup {
+			sl.ReportError(ws.TimeoutInitialization, "TimeoutInitialization", "TimeoutInitialization", "workspace_timeout_initialization", "")
+		}
+		// A workspace would time out between two heartbeats
+		if ws.HeartbeatInterval != nil && ws.TimeoutDefault != nil && *ws.HeartbeatInterval >= *ws.TimeoutDefault {
+			sl.ReportError(ws.HeartbeatInterval, "HeartbeatInterval", "HeartbeatInterval", "workspace_heartbeat_interval", "")
+		}
+
+		// ws-manager cannot create the claims and snapshots without the classes
+		check := func(name string, pvc PersistentVolumeClaim) {
+			if pvc.StorageClass == "" {
+				sl.ReportError(pvc.StorageClass, name+".StorageClass", "StorageClass", "workspace_pvc", "")
+			}
+			if pvc.SnapshotClass == "" {
+				sl.ReportError(pvc.SnapshotClass, name+".SnapshotClass", "SnapshotClass", "workspace_pvc", "")
+			}
+		}
+		if ws.EnablePVC {
+			check("PVC", ws.PVC)
+			check("PrebuildPVC", ws.PrebuildPVC)
+		}
+
+		// The default image would be from a registry outside of the allowed ones
+		if ws.ImagePolicy != nil && len(ws.ImagePolicy.AllowedRegistries) > 0 {
+			var allowed bool
+			if ref, err := reference.ParseNormalizedNamed(ws.WorkspaceImage); err == nil {
+				for _, registry := range ws.ImagePolicy.AllowedRegistries {
+					allowed = allowed || reference.Domain(ref) == registry
+				}
+			}
+			if !allowed {
+				sl.ReportError(ws.WorkspaceImage, "WorkspaceImage", "WorkspaceImage", "workspace_image_registry", "")
+			}
+		}
+
+		// The CA is either given or generated
+		if ws.SSHUserCA != nil && (ws.SSHUserCA.Secret != nil) == ws.SSHUserCA.Generate {
+			sl.ReportError(ws.SSHUserCA, "SSHUserCA", "SSHUserCA", "ssh_user_ca", "")
+		}
+	}, Workspace{})
+
+	validate.RegisterStructValidation(func(sl validator.StructLevel) {
+		flags := sl.Current().Interface().(FeatureFlags)
+
+		if (flags.ConfigCat == nil) == (flags.Static == nil) {
+			sl.ReportError(flags, "FeatureFlags", "FeatureFlags", "feature_flags_source", "")
+		}
+
+		// The static flags are served in the format of ConfigCat, which only has these types
+		for name, value := range flags.Static {
+			switch value.(type) {
+			case bool, string, float64:
+			default:
+				sl.ReportError(flags.Static[name], "Static."+name, name, "feature_flag_value", "")
+			}
+		}
+	}, FeatureFlags{})
+
+	validate.RegisterStructValidation(func(sl validator.StructLevel) {
+		session := sl.Current().Interface().(ServerSession)
+
+		// The server keeps the max age in milliseconds in a 32-bit integer
+		if session.MaxAge != nil && time.Duration(*session.MaxAge).Milliseconds() > math.MaxInt32 {
+			sl.ReportError(session.MaxAge, "MaxAge", "MaxAge", "server_session_max_age", "")
+		}
+		// The browsers reject the cookies with SameSite=None that are not secure
+		if session.Cookie != nil && session.Cookie.SameSite == "None" && !pointer.BoolDeref(session.Cookie.Secure, false) {
+			sl.ReportError(session.Cookie.Secure, "Cookie.Secure", "Secure", "server_session_cookie", "")
+		}
+	}, ServerSession{})
+
+	validate.RegisterStructValidation(func(sl validator.StructLevel) {
+		proxy := sl.Current().Interface().(ProxyComponent)
+
+		// The headers are written into the Caddyfile, a line break would start a new directive
+		for name, value := range proxy.ResponseHeaders {
+			if !httpHeaderNameRegexp.MatchString(name) || strings.ContainsAny(value, "\r\n") {
+				sl.ReportError(value, fmt.Sprintf("ResponseHeaders[%s]", name), "ResponseHeaders", "proxy_response_header", "")
+			}
+		}
+	}, ProxyComponent{})
+
+	validate.RegisterStructValidation(func(sl validator.StructLevel) {
+		cert := sl.Current().Interface().(WebhookCertificate)
+
+		// cert-manager refuses to issue certificates outside of these bounds
+		duration := 90 * 24 * time.Hour
+		if cert.Duration != nil {
+			duration = time.Duration(*cert.Duration)
+			if duration < time.Hour {
+				sl.ReportError(cert.Duration, "Duration", "Duration", "webhook_certificate", "")
+			}
+		}
+		if cert.RenewBefore != nil {
+			renewBefore := time.Duration(*cert.RenewBefore)
+			if renewBefore < 5*time.Minute || renewBefore >= duration {
+				sl.ReportError(cert.RenewBefore, "RenewBefore", "RenewBefore", "webhook_certificate", "")
+			}
+		}
+	}, WebhookCertificate{})
+
+	validate.RegisterStructValidation(func(sl validator.StructLevel) {
+		observability := sl.Current().Interface().(Observability)
+
+		// The labels become the names of annotations, which are at most 63 characters long
+		for component, labels := range observability.MetricLabels {
+			for name := range labels {
+				if !prometheusLabelNameRegexp.MatchString(name) || len(scrapeLabelAnnotationPrefix)+len(name) > 63 {
+					sl.ReportError(labels, fmt.Sprintf("MetricLabels[%s][%s]", component, name), "MetricLabels", "metric_label", "")
+				}
+			}
+		}
+	}, Observability{})
+
+	validate.RegisterStructValidation(func(sl validator.StructLevel) {
+		pki := sl.Current().Interface().(InternalPKI)
+
+		duration := 90 * 24 * time.Hour
+		if pki.Duration != nil {
+			duration = time.Duration(*pki.Duration)
+			if duration < time.Hour {
+				sl.ReportError(pki.Duration, "Duration", "Duration", "internal_pki", "")
+			}
+		}
+		if pki.RenewBefore != nil {
+			renewBefore := time.Duration(*pki.RenewBefore)
+			if renewBefore < 5*time.Minute || renewBefore >= duration {
+				sl.ReportError(pki.RenewBefore, "RenewBefore", "RenewBefore", "internal_pki", "")
+			}
+		}
+		// cert-manager shortens the certificates that would outlive the CA that issues them
+		if pki.CADuration != nil && pki.CA == nil && time.Duration(*pki.CADuration) < duration {
+			sl.ReportError(pki.CADuration, "CADuration", "CADuration", "internal_pki", "")
+		}
+		// the validity of an external CA is not up to cert-manager
+		if pki.CA != nil && pki.CADuration != nil {
+			sl.ReportError(pki.CADuration, "CADuration", "CADuration", "internal_pki_ca", "")
+		}
+	}, InternalPKI{})
+
+	validate.RegisterStructValidation(func(sl validator.StructLevel) {
+		backend := sl.Current().Interface().(SealedSecretsBackend)
+
+		if backend.Certificate == "" {
+			return
+		}
+		if _, err := backend.PublicKey(); err != nil {
+			sl.ReportError(backend.Certificate, "Certificate", "Certificate", "sealed_secrets_certificate", "")
+		}
+	}, SealedSecretsBackend{})
+
+	validate.RegisterStructValidation(func(sl validator.StructLevel) {
+		dns := sl.Current().Interface().(WorkspaceDNS)
+
+		// Without the cluster's nameservers, the pods could not resolve anything
+		if dns.Policy == corev1.DNSNone && len(dns.Nameservers) == 0 {
+			sl.ReportError(dns.Nameservers, "Nameservers", "Nameservers", "workspace_dns", "")
+		}
+	}, WorkspaceDNS{})
+
+	validate.RegisterStructValidation(func(sl validator.StructLevel) {
+		pod := sl.Current().Interface().(PodConfig)
+
+		// The API server rejects the env vars without a name or with both a value and its source
+		for i, e := range pod.Env {
+			if e.Name == "" || (e.Value != "" && e.ValueFrom != nil) {
+				sl.ReportError(e, fmt.Sprintf("Env[%d]", i), "Env", "pod_config_env", "")
+			}
+		}
+		for i, e := range pod.EnvFrom {
+			switch {
+			case e.ConfigMapRef != nil && e.SecretRef == nil && e.ConfigMapRef.Name != "":
+			case e.SecretRef != nil && e.ConfigMapRef == nil && e.SecretRef.Name != "":
+			default:
+				sl.ReportError(e, fmt.Sprintf("EnvFrom[%d]", i), "EnvFrom", "pod_config_env", "")
+			}
+		}
+
+		checkContainers := func(field string, containers []corev1.Container) {
+			for i, c := range containers {
+				if c.Name == "" || c.Image == "" {
+					sl.ReportError(c, fmt.Sprintf("%s[%d]", field, i), field, "pod_config_container", "")
+				}
+			}
+		}
+		checkContainers("Sidecars", pod.Sidecars)
+		checkContainers("InitContainers", pod.InitContainers)
+	}, PodConfig{})
+
+	validate.RegisterStructValidation(func(sl validator.StructLevel) {
+		storage := sl.Current().Interface().(ObjectStorage)
+
+		backends := 0
+		for _, configured := range []bool{pointer.BoolDeref(storage.InCluster, false), storage.S3 != nil, storage.CloudStorage != nil} {
+			if configured {
+				backends++
+			}
+		}
+		// The in-cluster storage would otherwise take precedence over the external one
+		if backends != 1 {
+			sl.ReportError(storage.InCluster, "InCluster", "InCluster", "object_storage", "")
+		}
+		if storage.ConnectivityCheck && pointer.BoolDeref(storage.InCluster, false) {
+			sl.ReportError(storage.ConnectivityCheck, "ConnectivityCheck", "ConnectivityCheck", "object_storage_connectivity_check", "")
+		}
+	}, ObjectStorage{})
+
+	validate.RegisterStructValidation(func(sl validator.StructLevel) {
+		s3 := sl.Current().Interface().(ObjectStorageS3)
+
+		if s3.SSEKMSKeyARN != "" && !kmsKeyARNRegexp.MatchString(s3.SSEKMSKeyARN) {
+			sl.ReportError(s3.SSEKMSKeyARN, "SSEKMSKeyARN", "SSEKMSKeyARN", "s3_kms_key_arn", "")
+		}
+		// S3 bucket names are at most 63 characters long
+		if s3.BucketPerOwner && len(s3.BucketName)+s3BucketOwnerIDLength > 63 {
+			sl.ReportError(s3.BucketName, "BucketName", "BucketName", "s3_bucket_per_owner", "")
+		}
+	}, ObjectStorageS3{})
+
+	validate.RegisterStructValidation(func(sl validator.StructLevel) {
+		img := sl.Current().Interface().(ComponentImage)
+
+		if img.Repository == "" {
+			return
+		}
+		if ref, err := reference.ParseNormalizedNamed(img.Repository); err != nil || !reference.IsNameOnly(ref) {
+			sl.ReportError(img.Repository, "Repository", "Repository", "component_image", "")
+		}
+	}, ComponentImage{})
+
+	validate.RegisterStructValidation(func(sl validator.StructLevel) {
+		a := sl.Current().Interface().(Autoscaling)
+
+		// The autoscaler rejects a range it cannot scale in
+		if a.MinReplicas != nil && *a.MinReplicas > a.MaxReplicas {
+			sl.ReportError(a.MinReplicas, "MinReplicas", "MinReplicas", "autoscaling_replicas", "")
+		}
+	}, Autoscaling{})
+
+	validate.RegisterStructValidation(func(sl validator.StructLevel) {
+		q := sl.Current().Interface().(WorkspaceStorageQuota)
+
+		// A default size needs a backend that enforces it
+		if q.DefaultSize != nil && (q.DefaultSize.Sign() <= 0 || q.Backend == StorageQuotaNone) {
+			sl.ReportError(q.DefaultSize, "DefaultSize", "DefaultSize", "storage_quota_default_size", "")
+		}
+	}, WorkspaceStorageQuota{})
+
+	validate.RegisterStructValidation(func(sl validator.StructLevel) {
+		clients := sl.Current().Interface().(GRPCClients)
+
+		// The size is an int32 in the clients of server and ws-manager-bridge
+		if size := clients.MaxMessageSize; size != nil && (size.Sign() <= 0 || size.Cmp(*resource.NewQuantity(math.MaxInt32, resource.BinarySI)) > 0) {
+			sl.ReportError(clients.MaxMessageSize, "MaxMessageSize", "MaxMessageSize", "grpc_max_message_size", "")
+		}
+	}, GRPCClients{})
+
+	validate.RegisterStructValidation(func(sl validator.StructLevel) {
+		strategy := sl.Current().Interface().(DaemonSetUpdateStrategy)
+		if strategy.MaxUnavailable == nil {
+			return
+		}
+
+		// Only a rolling update has a number of unavailable nodes, which must not be zero
+		if strategy.Type != DaemonSetUpdateRolling {
+			sl.ReportError(strategy.MaxUnavailable, "MaxUnavailable", "MaxUnavailable", "daemon_set_max_unavailable", "")
+			return
+		}
+		if v, err := intstr.GetScaledValueFromIntOrPercent(strategy.MaxUnavailable, 100, true); err != nil || v <= 0 {
+			sl.ReportError(strategy.MaxUnavailable, "MaxUnavailable", "MaxUnavailable", "daemon_set_max_unavailable", "")
+		}
+	}, DaemonSetUpdateStrategy{})
+
+	validate.RegisterStructValidation(func(sl validator.StructLevel) {
+		cluster := sl.Current().Interface().(WorkspaceCluster)
+
+		score, maxScore := int32(50), int32(100)
+		if cluster.Score != nil {
+			score = *cluster.Score
+		}
+		if cluster.MaxScore != nil {
+			maxScore = *cluster.MaxScore
+		}
+		if score > maxScore {
+			sl.ReportError(cluster.Score, "Score", "Score", "workspace_cluster_score", "")
+		}
+	}, WorkspaceCluster{})
+
+	validate.RegisterStructValidation(func(sl validator.StructLevel) {
+		constraint := sl.Current().Interface().(WorkspaceClusterAdmissionConstraint)
+
+		// Only the has-permission constraint names a permission
+		if (constraint.Type == "has-permission") != (constraint.Permission != "") {
+			sl.ReportError(constraint.Permission, "Permission", "Permission", "workspace_cluster_permission", "")
+		}
+	}, WorkspaceClusterAdmissionConstraint{})
+
+	validate.RegisterStructValidation(func(sl validator.StructLevel) {
+		limits := sl.Current().Interface().(ServerRateLimits)
+
+		// The methods can only be moved to the server's groups or the groups in the config
+		for method, m := range limits.Methods {
+			if _, ok := limits.Groups[m.Group]; ok {
+				continue
+			}
+			if _, ok := ServerRateLimitGroupList[m.Group]; !ok {
+				sl.ReportError(m.Group, fmt.Sprintf("Methods[%s].Group", method), "Group", "server_rate_limit_group", "")
+			}
+		}
+	}, ServerRateLimits{})
+
+	validate.RegisterStructValidation(func(sl validator.StructLevel) {
+		cfg := sl.Current().Interface().(Config)
+
+		// The SSH gateway cannot be enabled without host keys
+		if cfg.Components != nil && cfg.Components.WSProxy != nil && cfg.Components.WSProxy.SSHGateway != nil {
+			gw := cfg.Components.WSProxy.SSHGateway
+			if pointer.BoolDeref(gw.Enabled, false) && gw.HostKey == nil && cfg.SSHGatewayHostKey == nil {
+				sl.ReportError(gw.Enabled, "Components.WSProxy.SSHGateway.Enabled", "Enabled", "ssh_gateway_host_key", "")
+			}
+		}
+
+		// An external SpiceDB replaces the in-cluster one
+		if cfg.Components != nil && cfg.Components.SpiceDB != nil && cfg.Components.SpiceDB.External != nil &&
+			cfg.Experimental != nil && cfg.Experimental.WebApp != nil && cfg.Experimental.WebApp.SpiceDB != nil {
+			sl.ReportError(cfg.Components.SpiceDB.External, "Components.SpiceDB.External", "External", "spicedb_external", "")
+		}
+
+		// The pod security policies cannot be applied once they are removed
+		if cfg.KubernetesVersion != "" && cfg.KubernetesVersionAtLeast(KubernetesVersionNoPodSecurityPolicies) &&
+			cfg.Experimental != nil && cfg.Experimental.Common != nil && cfg.Experimental.Common.UsePodSecurityPolicies {
+			sl.ReportError(cfg.Experimental.Common.UsePodSecurityPolicies, "Experimental.Common.UsePodSecurityPolicies", "UsePodSecurityPolicies", "kubernetes_pod_security_policies", "")
+		}
+
+		// Only the installations with the workspace components have the infrastructure namespace
+		if cfg.Workspace.InfrastructureNamespace != "" && cfg.Kind != InstallationFull && cfg.Kind != InstallationWorkspace {
+			sl.ReportError(cfg.Workspace.InfrastructureNamespace, "Workspace.InfrastructureNamespace", "InfrastructureNamespace", "infrastructure_namespace_kind", "")
+		}
+
+		// The workspace clusters are registered by ws-manager-bridge, which only runs in the
+		// installations with the webapp. Their names must not collide with each other, or with
+		// the workspace cluster of a Full installation.
+		if len(cfg.WorkspaceClusters) > 0 && cfg.Kind != InstallationFull && cfg.Kind != InstallationMeta {
+			sl.ReportError(cfg.WorkspaceClusters, "WorkspaceClusters", "WorkspaceClusters", "workspace_clusters_kind", "")
+		}
+		names := make(map[string]struct{}, len(cfg.WorkspaceClusters))
+		if cfg.Kind == InstallationFull {
+			names[cfg.Metadata.InstallationShortname] = struct{}{}
+		}
+		for i, c := range cfg.WorkspaceClusters {
+			if _, ok := names[c.Name]; ok {
+				sl.ReportError(c.Name, fmt.Sprintf("WorkspaceClusters[%d].Name", i), "Name", "workspace_cluster_name", "")
+			}
+			names[c.Name] = struct{}{}
+		}
+
+		// The components that others depend on cannot be switched off
+		if !cfg.MinioEnabled() && pointer.BoolDeref(cfg.ObjectStorage.InCluster, false) {
+			sl.ReportError(cfg.Components.Minio.Enabled, "Components.Minio.Enabled", "Enabled", "minio_disabled", "")
+		}
+		if !cfg.DockerRegistryEnabled() && pointer.BoolDeref(cfg.ContainerRegistry.InCluster, false) {
+			sl.ReportError(cfg.Components.DockerRegistry.Enabled, "Components.DockerRegistry.Enabled", "Enabled", "docker_registry_disabled", "")
+		}
+		if !cfg.OpenVSXProxyEnabled() && cfg.OpenVSX.Proxy != nil {
+			sl.ReportError(cfg.Components.OpenVSXProxy.Enabled, "Components.OpenVSXProxy.Enabled", "Enabled", "openvsx_proxy_disabled", "")
+		}
+		if !cfg.IDEMetricsEnabled() && cfg.Components.IDE.Metrics.ErrorReportingEnabled {
+			sl.ReportError(cfg.Components.IDE.Metrics.Enabled, "Components.IDE.Metrics.Enabled", "Enabled", "ide_metrics_disabled", "")
+		}
+		if !cfg.PublicAPIEnabled() && cfg.Experimental != nil && cfg.Experimental.WebApp != nil && cfg.Experimental.WebApp.PublicAPI != nil {
+			sl.ReportError(cfg.Components.PublicAPI.Enabled, "Components.PublicAPI.Enabled", "Enabled", "public_api_disabled", "")
+		}
+
+		// The analytics would be turned off without notice
+		if cfg.TelemetryDisabled() && cfg.Analytics != nil && cfg.Analytics.Writer == "segment" {
+			sl.ReportError(cfg.Analytics.Writer, "Analytics.Writer", "Writer", "telemetry_disabled", "")
+		}
+
+		if cfg.NamespaceScoped() {
+			// The workspace components label the nodes and manage the workspace resources, which
+			// is only possible with cluster roles
+			if cfg.Kind == InstallationFull || cfg.Kind == InstallationWorkspace {
+				sl.ReportError(cfg.RBAC.Scope, "RBAC.Scope", "Scope", "rbac_scope_kind", "")
+			}
+			// The pods would reference priority classes that cannot be created
+			if cfg.PriorityClasses != nil && cfg.PriorityClasses.Enabled {
+				sl.ReportError(cfg.PriorityClasses.Enabled, "PriorityClasses.Enabled", "Enabled", "rbac_scope_priority_classes", "")
+			}
+		}
+
+		// A claim that is mounted on one node cannot be shared by the blobserve pods
+		if cfg.Components != nil && cfg.Components.Blobserve != nil && cfg.Components.Blobserve.Cache != nil && cfg.Components.Blobserve.Cache.PVC != nil {
+			if pc := cfg.Components.PodConfig["blobserve"]; pc != nil && (pc.Autoscaling != nil || pointer.Int32Deref(pc.Replicas, 1) > 1) {
+				sl.ReportError(cfg.Components.Blobserve.Cache.PVC, "Components.Blobserve.Cache.PVC", "PVC", "blobserve_cache_pvc", "")
+			}
+		}
+
+		// The hosts redirect back to the server, which is only reachable on the domain
+		if cfg.Components != nil && cfg.Components.Server != nil {
+			for i, p := range cfg.Components.Server.AuthProviders {
+				if p.CallbackURL == "" {
+					continue
+				}
+				u, err := url.Parse(p.CallbackURL)
+				if err != nil || u.Scheme != "https" || u.Host != cfg.Domain {
+					sl.ReportError(p.CallbackURL, fmt.Sprintf("Components.Server.AuthProviders[%d].CallbackURL", i), "CallbackURL", "auth_provider_callback_url", "")
+				}
+			}
+		}
+	}, Config{})
+
 	return nil
 }
 
@@ -118,7 +855,33 @@ func (v version) ClusterValidation(rcfg interface{}) cluster.ValidationChecks {
 	cfg := rcfg.(*Config)
 
 	var res cluster.ValidationChecks
-	res = append(res, cluster.CheckSecret(cfg.Certificate.Name, cluster.CheckSecretRequiredData("tls.crt", "tls.key")))
+	domainNames := []string{cfg.Domain, "*." + cfg.Domain}
+	if cfg.PublicAPIEnabled() && !cfg.PublicAPIHostnameCovered() {
+		domainNames = append(domainNames, cfg.PublicAPIHostname())
+	}
+	var workspaceNames []string
+	for _, d := range cfg.WorkspaceDomains() {
+		workspaceNames = append(workspaceNames, "*."+d)
+	}
+	registryNames := []string{"reg." + cfg.Domain}
+	if pointer.BoolDeref(cfg.ContainerRegistry.InCluster, false) {
+		registryNames = append(registryNames, "registry."+cfg.Domain)
+	}
+
+	if cfg.WorkspaceCertificate == nil {
+		domainNames = append(domainNames, workspaceNames...)
+	} else {
+		res = append(res, cluster.CheckSecret(cfg.WorkspaceCertificate.Name, cluster.CheckSecretRequiredData("tls.crt", "tls.key"), cluster.CheckSecretCertificateNames(workspaceNames...)))
+	}
+	if cfg.RegistryCertificate != nil {
+		res = append(res, cluster.CheckSecret(cfg.RegistryCertificate.Name, cluster.CheckSecretRequiredData("tls.crt", "tls.key"), cluster.CheckSecretCertificateNames(registryNames...)))
+	}
+
+	if cfg.CertificateIssuer == nil {
+		res = append(res, cluster.CheckSecret(cfg.Certificate.Name, cluster.CheckSecretRequiredData("tls.crt", "tls.key"), cluster.CheckSecretCertificateNames(domainNames...)))
+	} else if dns01 := cfg.CertificateIssuer.DNS01; dns01 != nil {
+		res = append(res, cluster.CheckSecret(dns01.Credentials.Name, cluster.CheckSecretRequiredData(DNS01CredentialsKey(dns01.Provider))))
+	}
 
 	res = append(res, cluster.ValidationCheck{
 		Name:        "affinity labels",
@@ -126,12 +889,17 @@ func (v version) ClusterValidation(rcfg interface{}) cluster.ValidationChecks {
 		Description: "all required affinity node labels " + fmt.Sprint(getAffinityListByKind(cfg.Kind)) + " are present in the cluster",
 	})
 
+	if cfg.Kind == InstallationFull || cfg.Kind == InstallationWorkspace {
+		res = append(res, cluster.CheckContainerdLocation(cfg.Workspace.Runtime.ContainerDSocketDir, cfg.Workspace.Runtime.ContainerDRuntimeDir))
+	}
+
 	if cfg.ObjectStorage.CloudStorage != nil {
 		secretName := cfg.ObjectStorage.CloudStorage.ServiceAccount.Name
 		res = append(res, cluster.CheckSecret(secretName, cluster.CheckSecretRequiredData("service-account.json")))
 	}
 
-	if cfg.ObjectStorage.S3 != nil {
+	// Without credentials, S3 is accessed with the identity of the service account
+	if cfg.ObjectStorage.S3 != nil && cfg.ObjectStorage.S3.Credentials != nil {
 		secretName := cfg.ObjectStorage.S3.Credentials.Name
 		res = append(res, cluster.CheckSecret(secretName, cluster.CheckSecretRequiredData("accessKeyId", "secretAccessKey")))
 	}
@@ -146,17 +914,55 @@ func (v version) ClusterValidation(rcfg interface{}) cluster.ValidationChecks {
 		}
 	}
 
+	if cfg.ContainerRegistry.UpstreamCredentials != nil {
+		secretName := cfg.ContainerRegistry.UpstreamCredentials.Name
+		res = append(res, cluster.CheckSecret(secretName, cluster.CheckSecretRequiredData(".dockerconfigjson")))
+	}
+
+	for _, secret := range cfg.ImagePullSecrets {
+		res = append(res, cluster.CheckSecret(secret.Name, cluster.CheckSecretRequiredData(".dockerconfigjson")))
+	}
+
 	if cfg.ContainerRegistry.S3Storage != nil {
 		secretName := cfg.ContainerRegistry.S3Storage.Certificate.Name
 		res = append(res, cluster.CheckSecret(secretName, cluster.CheckSecretRequiredData("s3AccessKey", "s3SecretKey")))
 	}
 
+	if cfg.Redis != nil && cfg.Redis.External != nil && cfg.Redis.External.Credentials != nil {
+		secretName := cfg.Redis.External.Credentials.Name
+		res = append(res, cluster.CheckSecret(secretName, cluster.CheckSecretRequiredData("password")))
+	}
+
+	if cfg.Components != nil && cfg.Components.AgentSmith != nil && cfg.Components.AgentSmith.SlackWebhooksSecret != nil {
+		// Either of the webhooks can be left out
+		res = append(res, cluster.CheckSecret(cfg.Components.AgentSmith.SlackWebhooksSecret.Name))
+	}
+
+	for _, c := range cfg.WorkspaceClusters {
+		res = append(res, cluster.CheckSecret(c.TLS.Name, cluster.CheckSecretRequiredData("ca.crt", "tls.crt", "tls.key")))
+	}
+
+	if cfg.Components != nil && cfg.Components.SpiceDB != nil && cfg.Components.SpiceDB.External != nil {
+		res = append(res, cluster.CheckSecret(cfg.Components.SpiceDB.External.PresharedKey.Name, cluster.CheckSecretRequiredData("presharedKey")))
+	}
+
+	if cfg.Components != nil && cfg.Components.SmokeTest != nil {
+		res = append(res, cluster.CheckSecret(cfg.Components.SmokeTest.Credentials.Name, cluster.CheckSecretRequiredData("username", "token")))
+	}
+
+	if cfg.Components != nil && cfg.Components.Usage != nil && cfg.Components.Usage.Enabled && cfg.Components.Usage.StripeCredentials != nil {
+		res = append(res, cluster.CheckSecret(cfg.Components.Usage.StripeCredentials.Name, cluster.CheckSecretRequiredData("apikeys")))
+	}
+
 	if cfg.Database.CloudSQL != nil {
 		secretName := cfg.Database.CloudSQL.ServiceAccount.Name
 		res = append(res, cluster.CheckSecret(secretName, cluster.CheckSecretRequiredData("credentials.json", "encryptionKeys", "password", "username")))
 	}
 
-	if cfg.Database.External != nil {
+	if cfg.Database.UsePostgres() {
+		secretName := cfg.Database.External.Postgres.Certificate.Name
+		res = append(res, cluster.CheckSecret(secretName, cluster.CheckSecretRequiredData("database", "encryptionKeys", "host", "password", "port", "username")))
+	} else if cfg.Database.External != nil && cfg.Database.External.Certificate != nil {
 		secretName := cfg.Database.External.Certificate.Name
 		res = append(res, cluster.CheckSecret(secretName, cluster.CheckSecretRequiredData("encryptionKeys", "host", "password", "port", "username")))
 	}
@@ -166,6 +972,26 @@ func (v version) ClusterValidation(rcfg interface{}) cluster.ValidationChecks {
 		res = append(res, cluster.CheckSecret(secretName, cluster.CheckSecretRequiredData("ca.crt")))
 	}
 
+	if cfg.Components != nil && cfg.Components.Server != nil && cfg.Components.Server.GitHubApp != nil {
+		app := cfg.Components.Server.GitHubApp
+		res = append(res, cluster.CheckSecret(app.WebhookSecret.Name, cluster.CheckSecretRequiredData("webhookSecret")))
+		res = append(res, cluster.CheckSecret(app.PrivateKey.Name, cluster.CheckSecretRequiredData("privateKey")))
+	}
+
+	if cfg.InstallationAdminExposure() == InstallationAdminProxy {
+		res = append(res, cluster.CheckSecret(cfg.Components.Server.InstallationAdmin.Credentials.Name, cluster.CheckSecretRequiredData("username", "passwordHash")))
+	}
+
+	if cfg.FeatureFlags != nil && cfg.FeatureFlags.ConfigCat != nil {
+		res = append(res, cluster.CheckSecret(cfg.FeatureFlags.ConfigCat.SDKKey.Name, cluster.CheckSecretRequiredData("sdkKey")))
+	}
+
+	if cfg.Components != nil && cfg.Components.Server != nil {
+		for _, provider := range cfg.Components.Server.AuthProviders {
+			res = append(res, cluster.CheckSecret(provider.ClientSecret.Name, cluster.CheckSecretRequiredData("clientSecret")))
+		}
+	}
+
 	if len(cfg.AuthProviders) > 0 {
 		for _, provider := range cfg.AuthProviders {
 			secretName := provider.Name
@@ -205,8 +1031,27 @@ func (v version) ClusterValidation(rcfg interface{}) cluster.ValidationChecks {
 		}
 	}
 
-	if cfg.SSHGatewayHostKey != nil {
-		secretName := cfg.SSHGatewayHostKey.Name
+	if cfg.Workspace.SSHUserCA != nil && cfg.Workspace.SSHUserCA.Secret != nil {
+		secretName := cfg.Workspace.SSHUserCA.Secret.Name
+		res = append(res, cluster.CheckSecret(secretName, cluster.CheckSecretRequiredData("ca.pub"), cluster.CheckSecretRule(func(s *corev1.Secret) ([]cluster.ValidationError, error) {
+			errors := make([]cluster.ValidationError, 0)
+			rest := s.Data["ca.pub"]
+			for len(bytes.TrimSpace(rest)) > 0 {
+				var err error
+				if _, _, _, rest, err = ssh.ParseAuthorizedKey(rest); err != nil {
+					errors = append(errors, cluster.ValidationError{
+						Message: fmt.Sprintf("Secret '%s' contains an invalid CA public key: %v", secretName, err),
+						Type:    cluster.ValidationStatusError,
+					})
+					break
+				}
+			}
+			return errors, nil
+		})))
+	}
+
+	if hostKey := cfg.SSHGatewayHostKeySecret(); hostKey != nil {
+		secretName := hostKey.Name
 		res = append(res, cluster.CheckSecret(secretName, cluster.CheckSecretRule(func(s *corev1.Secret) ([]cluster.ValidationError, error) {
 			var signers []ssh.Signer
 			errors := make([]cluster.ValidationError, 0)
@@ -0,0 +1,202 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package config
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gitpod-io/gitpod/common-go/util"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+)
+
+func validConfig(t *testing.T) *Config {
+	cfg := version{}.Factory().(*Config)
+	require.NoError(t, version{}.Defaults(cfg))
+	cfg.Domain = "gitpod.example.com"
+	return cfg
+}
+
+// validationErrors returns the namespaces and tags of the errors of the config
+func validationErrors(t *testing.T, cfg *Config) map[string]string {
+	validate := validator.New()
+	require.NoError(t, version{}.LoadValidationFuncs(validate))
+
+	res := make(map[string]string)
+	err := validate.Struct(cfg)
+	if err == nil {
+		return res
+	}
+	var errs validator.ValidationErrors
+	require.True(t, errors.As(err, &errs), "unexpected error: %v", err)
+	for _, e := range errs {
+		res[e.Namespace()] = e.Tag()
+	}
+	return res
+}
+
+func duration(d time.Duration) *util.Duration {
+	res := util.Duration(d)
+	return &res
+}
+
+func TestValidConfig(t *testing.T) {
+	require.Empty(t, validationErrors(t, validConfig(t)))
+}
+
+func TestStructValidation(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Config   func(cfg *Config)
+		Expected map[string]string
+	}{
+		{
+			Name: "unknown prebuild class",
+			Config: func(cfg *Config) {
+				cfg.Workspace.Prebuilds = &WorkspacePrebuilds{Class: "large"}
+			},
+			Expected: map[string]string{"Config.Workspace.Prebuilds.Class": "prebuild_workspace_class"},
+		},
+		{
+			Name: "initialization longer than the startup",
+			Config: func(cfg *Config) {
+				cfg.Workspace.TimeoutStartup = duration(time.Hour)
+				cfg.Workspace.TimeoutInitialization = duration(2 * time.Hour)
+			},
+			Expected: map[string]string{"Config.Workspace.TimeoutInitialization": "workspace_timeout_initialization"},
+		},
+		{
+			Name: "heartbeat interval longer than the timeout",
+			Config: func(cfg *Config) {
+				cfg.Workspace.TimeoutDefault = duration(30 * time.Minute)
+				cfg.Workspace.HeartbeatInterval = duration(time.Hour)
+			},
+			Expected: map[string]string{"Config.Workspace.HeartbeatInterval": "workspace_heartbeat_interval"},
+		},
+		{
+			Name: "pvc without classes",
+			Config: func(cfg *Config) {
+				cfg.Workspace.EnablePVC = true
+				cfg.Workspace.PrebuildPVC.StorageClass = "ssd"
+				cfg.Workspace.PrebuildPVC.SnapshotClass = "ssd-snapshot"
+			},
+			Expected: map[string]string{
+				"Config.Workspace.PVC.StorageClass":  "workspace_pvc",
+				"Config.Workspace.PVC.SnapshotClass": "workspace_pvc",
+			},
+		},
+		{
+			Name: "workspace image outside of the allowed registries",
+			Config: func(cfg *Config) {
+				cfg.Workspace.WorkspaceImage = "gitpod/workspace-full"
+				cfg.Workspace.ImagePolicy = &WorkspaceImagePolicy{AllowedRegistries: []string{"registry.example.com"}}
+			},
+			Expected: map[string]string{"Config.Workspace.WorkspaceImage": "workspace_image_registry"},
+		},
+		{
+			Name: "ssh user ca given and generated",
+			Config: func(cfg *Config) {
+				cfg.Workspace.SSHUserCA = &SSHUserCA{Secret: &ObjectRef{Kind: ObjectRefSecret, Name: "ssh-ca"}, Generate: true}
+			},
+			Expected: map[string]string{"Config.Workspace.SSHUserCA": "ssh_user_ca"},
+		},
+		{
+			// The checks of a struct are all in one validation, a second one would replace it
+			Name: "every check of the workspace",
+			Config: func(cfg *Config) {
+				cfg.Workspace.Prebuilds = &WorkspacePrebuilds{Class: "large"}
+				cfg.Workspace.TimeoutStartup = duration(time.Hour)
+				cfg.Workspace.TimeoutInitialization = duration(2 * time.Hour)
+				cfg.Workspace.EnablePVC = true
+				cfg.Workspace.WorkspaceImage = "gitpod/workspace-full"
+				cfg.Workspace.ImagePolicy = &WorkspaceImagePolicy{AllowedRegistries: []string{"registry.example.com"}}
+				cfg.Workspace.SSHUserCA = &SSHUserCA{}
+			},
+			Expected: map[string]string{
+				"Config.Workspace.Prebuilds.Class":           "prebuild_workspace_class",
+				"Config.Workspace.TimeoutInitialization":     "workspace_timeout_initialization",
+				"Config.Workspace.PVC.StorageClass":          "workspace_pvc",
+				"Config.Workspace.PVC.SnapshotClass":         "workspace_pvc",
+				"Config.Workspace.PrebuildPVC.StorageClass":  "workspace_pvc",
+				"Config.Workspace.PrebuildPVC.SnapshotClass": "workspace_pvc",
+				"Config.Workspace.WorkspaceImage":            "workspace_image_registry",
+				"Config.Workspace.SSHUserCA":                 "ssh_user_ca",
+			},
+		},
+		{
+			Name: "two object storages",
+			Config: func(cfg *Config) {
+				cfg.ObjectStorage.S3 = &ObjectStorageS3{Endpoint: "s3.amazonaws.com", BucketName: "gitpod"}
+			},
+			Expected: map[string]string{"Config.ObjectStorage.InCluster": "object_storage"},
+		},
+		{
+			Name: "connectivity check of the in-cluster storage",
+			Config: func(cfg *Config) {
+				cfg.ObjectStorage.ConnectivityCheck = true
+			},
+			Expected: map[string]string{"Config.ObjectStorage.ConnectivityCheck": "object_storage_connectivity_check"},
+		},
+		{
+			Name: "public ports by default with public ports disabled",
+			Config: func(cfg *Config) {
+				cfg.Workspace.Ports = &WorkspacePorts{DefaultVisibility: "public", DisablePublic: true}
+			},
+			Expected: map[string]string{"Config.Workspace.Ports.DefaultVisibility": "workspace_ports_public"},
+		},
+		{
+			Name: "autoscaling with more min than max replicas",
+			Config: func(cfg *Config) {
+				cfg.Components = &Components{PodConfig: map[string]*PodConfig{
+					"server": {Autoscaling: &Autoscaling{MinReplicas: pointer.Int32(3), MaxReplicas: 2}},
+				}}
+			},
+			Expected: map[string]string{"Config.Components.PodConfig[server].Autoscaling.MinReplicas": "autoscaling_replicas"},
+		},
+		{
+			Name: "autoscaling to a single replica",
+			Config: func(cfg *Config) {
+				cfg.Components = &Components{PodConfig: map[string]*PodConfig{
+					"server": {Autoscaling: &Autoscaling{MinReplicas: pointer.Int32(1), MaxReplicas: 1}},
+				}}
+			},
+			Expected: map[string]string{},
+		},
+		{
+			Name: "pod disruption budget of a daemon set",
+			Config: func(cfg *Config) {
+				cfg.Components = &Components{PodConfig: map[string]*PodConfig{
+					"registry-facade": {PodDisruptionBudget: &PodDisruptionBudget{MaxUnavailable: &intstr.IntOrString{IntVal: 1}}},
+				}}
+			},
+			Expected: map[string]string{"Config.Components.PodConfig": "pod_disruption_budgets"},
+		},
+		{
+			Name: "insecure cookie with sameSite none",
+			Config: func(cfg *Config) {
+				cfg.Components = &Components{Server: &ServerComponent{Session: &ServerSession{
+					MaxAge: duration(25 * 24 * time.Hour),
+					Cookie: &ServerSessionCookie{SameSite: "None"},
+				}}}
+			},
+			Expected: map[string]string{
+				"Config.Components.Server.Session.MaxAge":        "server_session_max_age",
+				"Config.Components.Server.Session.Cookie.Secure": "server_session_cookie",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			cfg := validConfig(t)
+			test.Config(cfg)
+			require.Equal(t, test.Expected, validationErrors(t, cfg))
+		})
+	}
+}
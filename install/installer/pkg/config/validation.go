@@ -50,13 +50,115 @@ func Validate(version ConfigVersion, cfg interface{}) (r *ValidationResult, err
 				switch v.Tag() {
 				case "required":
 					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' is required", v.Namespace()))
-				case "required_if", "required_unless", "required_with":
+				case "required_if", "required_unless", "required_with", "required_without":
 					tag := strings.Replace(v.Tag(), "_", " ", -1)
 					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' is %s '%s'", v.Namespace(), tag, v.Param()))
+				case "pod_disruption_budgets":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. A pod disruption budget can only set one of minAvailable or maxUnavailable, and is only rendered for deployments and stateful sets", v.Namespace()))
 				case "startswith":
 					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' must start with '%s'", v.Namespace(), v.Param()))
+				case "auth_provider_callback_url":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. The callback URL must be an https URL on the domain of the installation", v.Namespace()))
+				case "blobserve_cache_pvc":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. The cache claim can only be mounted by a single blobserve replica", v.Namespace()))
 				case "block_new_users_passlist":
 					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. If 'Enabled = true', there must be at least one fully-qualified domain name in the passlist", v.Namespace()))
+				case "component_image":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. The repository must be an image name without a tag or digest", v.Namespace()))
+				case "autoscaling_replicas":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. The minReplicas cannot be more than the maxReplicas", v.Namespace()))
+				case "pod_config_env":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. An env var needs a name and either a value or a source, and envFrom exactly one named config map or secret", v.Namespace()))
+				case "pod_config_container":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. A sidecar or init container needs a name and an image", v.Namespace()))
+				case "metric_label":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. A label must start with a letter, consist of letters, digits and underscores and be at most 57 characters long", v.Namespace()))
+				case "internal_pki":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. The internal certificates must be valid for at least an hour, be renewed between 5 minutes and their duration before they expire, and not outlive the CA", v.Namespace()))
+				case "internal_pki_ca":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. The duration of an external CA cannot be set, cert-manager does not issue it", v.Namespace()))
+				case "server_session_max_age":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. The server keeps the max age in milliseconds, so it can be at most 24 days", v.Namespace()))
+				case "server_session_cookie":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. A cookie with sameSite None must be secure", v.Namespace()))
+				case "workspace_ports_public":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. The ports cannot be public by default when public ports are disabled", v.Namespace()))
+				case "workspace_timeout_initialization":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. The initialization is part of the startup and cannot take longer than timeoutStartup", v.Namespace()))
+				case "workspace_heartbeat_interval":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. The heartbeats must be sent more often than the timeoutDefault", v.Namespace()))
+				case "object_storage":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. Exactly one of the in-cluster storage, s3 or cloudStorage must be configured, set inCluster to false to use an external storage", v.Namespace()))
+				case "object_storage_connectivity_check":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. The connectivity check is for an external S3 or Cloud Storage, it cannot be enabled with the in-cluster storage", v.Namespace()))
+				case "s3_kms_key_arn":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. The key must be the ARN of an AWS KMS key or alias", v.Namespace()))
+				case "s3_bucket_per_owner":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. With a bucket per owner, the bucket is a prefix of at most 27 characters", v.Namespace()))
+				case "database_engine":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. A PostgreSQL database cannot be combined with the in-cluster, CloudSQL or external MySQL database", v.Namespace()))
+				case "rbac_scope_kind":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. The workspace components need cluster roles to label the nodes, so a namespace-scoped installation must be of kind Meta, WebApp or IDE", v.Namespace()))
+				case "rbac_scope_priority_classes":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. Priority classes are cluster-scoped and cannot be rendered for a namespace-scoped installation", v.Namespace()))
+				case "server_rate_limit_group":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. The group must be one of the server's groups or be defined in the rate limit groups", v.Namespace()))
+				case "server_blocked_repository":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. The URL of a blocked repository must be a valid regular expression", v.Namespace()))
+				case "server_rollout":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. maxSurge and maxUnavailable cannot both be zero", v.Namespace()))
+				case "ssh_user_ca":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. The SSH user CA needs either a secret or generate", v.Namespace()))
+				case "ssh_gateway_host_key":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. The SSH gateway requires a host key secret", v.Namespace()))
+				case "ide_images":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. Images can only be set for the known IDE options", v.Namespace()))
+				case "secret_groups":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. Secrets can only be rotated for the groups registry, storage and server", v.Namespace()))
+				case "workspace_classes":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. Workspace class names must be unique and not be 'default', and only one class can be the default", v.Namespace()))
+				case "prebuild_workspace_class":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. The prebuilds must run in the default class or one of the workspace classes", v.Namespace()))
+				case "workspace_image_registry":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' must be an image of one of the allowed registries of the image policy", v.Namespace()))
+				case "workspace_pvc":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' is required when workspaces use persistent volume claims", v.Namespace()))
+				case "storage_quota_default_size":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. The default size must be above zero and needs the xfs backend", v.Namespace()))
+				case "grpc_max_message_size":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. The size must be above zero and below 2Gi", v.Namespace()))
+				case "daemon_set_components":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. An update strategy is only supported for the daemon sets agent-smith, registry-facade and ws-daemon", v.Namespace()))
+				case "daemon_set_update_strategy":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' must be RollingUpdate or OnDelete", v.Namespace()))
+				case "daemon_set_max_unavailable":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. maxUnavailable must be a number or percentage of nodes above zero and is only supported for the RollingUpdate", v.Namespace()))
+				case "workspace_clusters_kind":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. The workspace clusters are only registered by the Full and Meta installations", v.Namespace()))
+				case "workspace_cluster_name":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. The name of the workspace cluster is already taken by another cluster or the installation", v.Namespace()))
+				case "workspace_cluster_score":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. The score of the workspace cluster must not be greater than its maxScore, which defaults to 100", v.Namespace()))
+				case "workspace_cluster_permission":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. A permission must be set for the has-permission constraint only", v.Namespace()))
+				case "minio_disabled":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. Minio stores the workspace content while objectStorage.inCluster is true, configure an external object storage to disable it", v.Namespace()))
+				case "docker_registry_disabled":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. The registry stores the workspace images while containerRegistry.inCluster is true, configure an external registry to disable it", v.Namespace()))
+				case "openvsx_proxy_disabled":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. openVSX.proxy configures the openvsx-proxy, remove it to disable the proxy", v.Namespace()))
+				case "ide_metrics_disabled":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. The IDE errors are reported to ide-metrics, disable components.ide.metrics.errorReportingEnabled first", v.Namespace()))
+				case "public_api_disabled":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. experimental.webapp.publicApi configures the Stripe webhooks, the OIDC clients and the access tokens of the public API, remove it to disable the public API", v.Namespace()))
+				case "infrastructure_namespace_kind":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. The infrastructure namespace is only supported for the Full and Workspace installations", v.Namespace()))
+				case "kubernetes_version":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. The Kubernetes version must be 1.21 or later, e.g. 1.24", v.Namespace()))
+				case "kubernetes_pod_security_policies":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. Pod security policies were removed in Kubernetes 1.25", v.Namespace()))
+				case "autoscaling_components":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed. Autoscaling is only supported for the stateless components", v.Namespace()))
 				default:
 					// General error message
 					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' failed %s validation", v.Namespace(), v.Tag()))
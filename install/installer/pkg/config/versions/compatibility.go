@@ -0,0 +1,94 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package versions
+
+import (
+	_ "embed"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"sigs.k8s.io/yaml"
+)
+
+//go:embed compatibility.yaml
+var compatibilityMatrix []byte
+
+// Compatibility lists the upgrades between releases that are known to break an installation
+type Compatibility struct {
+	Blocked []BlockedUpgrade `json:"blocked"`
+}
+
+// BlockedUpgrade requires the installations of a version before MinUpgradeFrom to upgrade
+// to MinUpgradeFrom before they upgrade to the To release
+type BlockedUpgrade struct {
+	// To and MinUpgradeFrom are the releases in the year.month form, e.g. 2022.11
+	To             string `json:"to"`
+	MinUpgradeFrom string `json:"minUpgradeFrom"`
+	Reason         string `json:"reason"`
+}
+
+// EmbeddedCompatibility returns the compatibility matrix of the installer
+func EmbeddedCompatibility() (*Compatibility, error) {
+	var res Compatibility
+	if err := yaml.Unmarshal(compatibilityMatrix, &res); err != nil {
+		return nil, fmt.Errorf("cannot read the compatibility matrix: %w", err)
+	}
+	return &res, nil
+}
+
+// releasePattern matches the versions of the releases, e.g. 2022.11.2. The other versions,
+// such as those of the branch builds, are not compared.
+var releasePattern = regexp.MustCompile(`^(\d{4})\.(\d{1,2})(?:\.(\d+))?`)
+
+// release returns the number of the monthly release of the version
+func release(version string) (int, bool) {
+	m := releasePattern.FindStringSubmatch(version)
+	if m == nil {
+		return 0, false
+	}
+	year, _ := strconv.Atoi(m[1])
+	month, _ := strconv.Atoi(m[2])
+	if month < 1 || month > 12 {
+		return 0, false
+	}
+	return year*12 + month - 1, true
+}
+
+// MaxReleaseSkew is the number of releases an installation can be upgraded by at once
+const MaxReleaseSkew = 1
+
+// CheckUpgrade warns about an upgrade of the installed version to the target that skips
+// releases or goes back, and fails if the upgrade is blocked. Versions that are not of a
+// release are not checked.
+func (c *Compatibility) CheckUpgrade(installed, target string) (warnings []string, err error) {
+	from, ok := release(installed)
+	if !ok {
+		return nil, nil
+	}
+	to, ok := release(target)
+	if !ok {
+		return nil, nil
+	}
+
+	switch {
+	case to < from:
+		warnings = append(warnings, fmt.Sprintf("version %s is older than the installed version %s, downgrades are not supported", target, installed))
+	case to-from > MaxReleaseSkew:
+		warnings = append(warnings, fmt.Sprintf("version %s is %d releases ahead of the installed version %s, upgrade by one release at a time", target, to-from, installed))
+	}
+
+	for _, b := range c.Blocked {
+		bto, ok := release(b.To)
+		if !ok || bto != to {
+			continue
+		}
+		if min, ok := release(b.MinUpgradeFrom); ok && from < min {
+			return warnings, fmt.Errorf("version %s cannot be upgraded to %s directly, upgrade to %s first: %s", installed, target, b.MinUpgradeFrom, b.Reason)
+		}
+	}
+
+	return warnings, nil
+}
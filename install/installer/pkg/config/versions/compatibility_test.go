@@ -0,0 +1,55 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package versions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddedCompatibility(t *testing.T) {
+	c, err := EmbeddedCompatibility()
+	require.NoError(t, err)
+	for _, b := range c.Blocked {
+		_, ok := release(b.To)
+		require.True(t, ok, "to must be a release: %s", b.To)
+		_, ok = release(b.MinUpgradeFrom)
+		require.True(t, ok, "minUpgradeFrom must be a release: %s", b.MinUpgradeFrom)
+	}
+}
+
+func TestCheckUpgrade(t *testing.T) {
+	c := &Compatibility{Blocked: []BlockedUpgrade{{To: "2023.2", MinUpgradeFrom: "2023.1", Reason: "migrations"}}}
+
+	tests := []struct {
+		Name      string
+		Installed string
+		Target    string
+		Warnings  int
+		Blocked   bool
+	}{
+		{Name: "same release", Installed: "2022.11.1", Target: "2022.11.3"},
+		{Name: "next release", Installed: "2022.11.1", Target: "2022.12.0"},
+		{Name: "next year", Installed: "2022.12.1", Target: "2023.1.0"},
+		{Name: "skips a release", Installed: "2022.10.0", Target: "2022.12.0", Warnings: 1},
+		{Name: "downgrade", Installed: "2022.12.0", Target: "2022.11.0", Warnings: 1},
+		{Name: "blocked", Installed: "2022.12.0", Target: "2023.2.0", Warnings: 1, Blocked: true},
+		{Name: "blocked target from allowed release", Installed: "2023.1.2", Target: "2023.2.0"},
+		{Name: "branch build", Installed: "main.1234", Target: "2023.2.0"},
+		{Name: "not installed", Installed: "", Target: "2023.2.0"},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			warnings, err := c.CheckUpgrade(test.Installed, test.Target)
+			require.Len(t, warnings, test.Warnings)
+			if test.Blocked {
+				require.ErrorContains(t, err, "upgrade to 2023.1 first")
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -4,9 +4,15 @@
 
 package versions
 
+import "reflect"
+
 type Manifest struct {
 	Version    string     `json:"version"`
 	Components Components `json:"components"`
+
+	// Variants lists the variants each image is also published in, by the name of the image.
+	// The tag of a variant is the version followed by a dash and the variant.
+	Variants map[string][]string `json:"variants,omitempty"`
 }
 
 type Versioned struct {
@@ -85,6 +91,39 @@ type Components struct {
 	NodeLabeler     Versioned `json:"node-labeler"`
 }
 
+// Versions returns the set of versions of the components
+func (c Components) Versions() map[string]struct{} {
+	res := make(map[string]struct{})
+
+	var walk func(v reflect.Value)
+	walk = func(v reflect.Value) {
+		if ver, ok := v.Interface().(Versioned); ok {
+			if ver.Version != "" {
+				res[ver.Version] = struct{}{}
+			}
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Field(i).Kind() == reflect.Struct {
+				walk(v.Field(i))
+			}
+		}
+	}
+	walk(reflect.ValueOf(c))
+
+	return res
+}
+
+// HasVariant is true when the image is published in the variant
+func (m Manifest) HasVariant(name, variant string) bool {
+	for _, v := range m.Variants[name] {
+		if v == variant {
+			return true
+		}
+	}
+	return false
+}
+
 // var embedded embed.FS
 
 func Embedded() (*Manifest, error) {
@@ -0,0 +1,46 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+/// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package containerd
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Distribution is the Kubernetes distribution or node OS whose containerd layout is known
+type Distribution struct {
+	Name       string
+	Socket     ContainerdSocketLocation
+	Containerd ContainerdLocation
+}
+
+// DetectDistribution recognises the distribution of a node from what the kubelet reports. The
+// Kubernetes distribution takes precedence over the OS, as it brings its own containerd.
+func DetectDistribution(info corev1.NodeSystemInfo) *Distribution {
+	switch {
+	case strings.Contains(info.KubeletVersion, "+k3s"):
+		return &Distribution{Name: "k3s", Socket: ContainerdSocketLocationK3s, Containerd: ContainerdLocationK3s}
+	case strings.Contains(info.KubeletVersion, "+rke2"):
+		return &Distribution{Name: "RKE2", Socket: ContainerdSocketLocationK3s, Containerd: ContainerdLocationK3s}
+	case strings.HasPrefix(info.OSImage, "Bottlerocket"):
+		return &Distribution{Name: "Bottlerocket", Socket: ContainerdSocketLocationDefault, Containerd: ContainerdLocationAmazonLinux}
+	case strings.HasPrefix(info.OSImage, "Amazon Linux"):
+		return &Distribution{Name: "Amazon Linux", Socket: ContainerdSocketLocationDefault, Containerd: ContainerdLocationAmazonLinux}
+	case strings.HasPrefix(info.OSImage, "Container-Optimized OS"):
+		return &Distribution{Name: "GKE Container-Optimized OS", Socket: ContainerdSocketLocationDefault, Containerd: ContainerdLocationDefault}
+	}
+	return nil
+}
+
+// SocketLocations are the socket locations that are looked for, the default last
+func SocketLocations() []ContainerdSocketLocation {
+	return loc.GetSocketList()
+}
+
+// ContainerdLocations are the runtime directories that are looked for, the default last
+func ContainerdLocations() []ContainerdLocation {
+	return loc.GetContainerdList()
+}
@@ -81,7 +81,7 @@ func detectContainerdLocation(mountPath string) (*ContainerdLocation, error) {
 func detectContainerdSocketLocation(mountPath string) (*ContainerdSocketLocation, error) {
 	for _, location := range loc.GetSocketList() {
 		// Ignore errors - the only error we're interested in is if cannot detect a location
-		fileInfo, err := os.Stat(path.Join(mountPath, location.String()))
+		fileInfo, err := os.Stat(path.Join(mountPath, location.String(), "containerd.sock"))
 		if err != nil || fileInfo.Mode().Type() != fs.ModeSocket {
 			// Not a socket - go to the next one
 			continue
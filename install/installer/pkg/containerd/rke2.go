@@ -0,0 +1,15 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+/// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package containerd
+
+// RKE2 keeps the images and snapshots under /var/lib/rancher, the socket and the runtime
+// directory are in the same place as for k3s
+const (
+	ContainerdLocationRKE2 ContainerdLocation = "/var/lib/rancher/rke2/agent/containerd/io.containerd.runtime.v2.task/k8s.io"
+)
+
+func init() {
+	loc.AddContainerd(ContainerdLocationRKE2)
+}
@@ -0,0 +1,339 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package estimate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/yaml"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+)
+
+// DefaultWorkspaceClass is the class of the workspace resources, next to the additional classes
+const DefaultWorkspaceClass = "default"
+
+// Resources are the CPU and memory that the pods request
+type Resources struct {
+	CPU    resource.Quantity `json:"cpu"`
+	Memory resource.Quantity `json:"memory"`
+}
+
+// Workload is a deployment, stateful set or daemon set and what each of its pods requests
+type Workload struct {
+	Kind     string    `json:"kind"`
+	Name     string    `json:"name"`
+	Replicas int32     `json:"replicas"`
+	Pod      Resources `json:"pod"`
+	// MaxReplicas is the maximum of the autoscaler of the workload, or the replicas without one
+	MaxReplicas int32 `json:"maxReplicas"`
+}
+
+// WorkspaceClass is the number of workspaces of a class that run at the same time and what each
+// of them requests
+type WorkspaceClass struct {
+	Name      string            `json:"name"`
+	Count     int64             `json:"count"`
+	Workspace Resources         `json:"workspace"`
+	Storage   resource.Quantity `json:"storage"`
+	GPUs      int64             `json:"gpus,omitempty"`
+}
+
+// Volume is a persistent volume claim, or the claims of the replicas of a stateful set
+type Volume struct {
+	Name         string            `json:"name"`
+	StorageClass string            `json:"storageClass,omitempty"`
+	Size         resource.Quantity `json:"size"`
+	Count        int32             `json:"count"`
+}
+
+// Estimate is the capacity that an installation needs
+type Estimate struct {
+	// Components are the deployments and stateful sets, they are totalled in Components
+	Components []Workload `json:"components"`
+	// Nodes are the daemon sets, which run on every node
+	Nodes      []Workload       `json:"nodes"`
+	Workspaces []WorkspaceClass `json:"workspaces"`
+	// Volumes are the claims of the components, and of the workspaces if they are backed by claims
+	Volumes       []Volume `json:"volumes"`
+	LoadBalancers []string `json:"loadBalancers"`
+
+	Total Total `json:"total"`
+}
+
+// Total sums up the estimate
+type Total struct {
+	Components Resources `json:"components"`
+	// ComponentsMax is what the components request once they are scaled up to their maximum
+	ComponentsMax    Resources         `json:"componentsMax"`
+	Nodes            Resources         `json:"nodes"`
+	Workspaces       Resources         `json:"workspaces"`
+	WorkspaceStorage resource.Quantity `json:"workspaceStorage"`
+	WorkspaceGPUs    int64             `json:"workspaceGPUs,omitempty"`
+	Volumes          resource.Quantity `json:"volumes"`
+}
+
+// scaledObject is the part of a horizontal pod autoscaler that the estimate needs, which is the
+// same in every version of the autoscaler
+type scaledObject struct {
+	Spec struct {
+		ScaleTargetRef struct {
+			Kind string `json:"kind"`
+			Name string `json:"name"`
+		} `json:"scaleTargetRef"`
+		MinReplicas *int32 `json:"minReplicas"`
+		MaxReplicas int32  `json:"maxReplicas"`
+	} `json:"spec"`
+}
+
+// Compute estimates the capacity from the rendered objects of the config and the number of
+// workspaces of each class that run at the same time. The workspaces request the resources of
+// their class, the components what their pods request.
+func Compute(cfg *config.Config, objects []string, workspaces map[string]int64) (*Estimate, error) {
+	objs, err := common.YamlToRuntimeObject(objects)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Estimate{}
+	autoscalers := make(map[string]scaledObject)
+	for _, o := range objs {
+		switch o.Kind {
+		case "Deployment":
+			var d appsv1.Deployment
+			if err := yaml.Unmarshal([]byte(o.Content), &d); err != nil {
+				return nil, fmt.Errorf("cannot read deployment %s: %w", o.Metadata.Name, err)
+			}
+			res.Components = append(res.Components, workload(o.Kind, d.Name, d.Spec.Replicas, d.Spec.Template.Spec))
+		case "StatefulSet":
+			var s appsv1.StatefulSet
+			if err := yaml.Unmarshal([]byte(o.Content), &s); err != nil {
+				return nil, fmt.Errorf("cannot read stateful set %s: %w", o.Metadata.Name, err)
+			}
+			wl := workload(o.Kind, s.Name, s.Spec.Replicas, s.Spec.Template.Spec)
+			res.Components = append(res.Components, wl)
+			for _, pvc := range s.Spec.VolumeClaimTemplates {
+				res.Volumes = append(res.Volumes, volume(s.Name+"/"+pvc.Name, pvc.Spec, wl.Replicas))
+			}
+		case "DaemonSet":
+			var d appsv1.DaemonSet
+			if err := yaml.Unmarshal([]byte(o.Content), &d); err != nil {
+				return nil, fmt.Errorf("cannot read daemon set %s: %w", o.Metadata.Name, err)
+			}
+			one := int32(1)
+			res.Nodes = append(res.Nodes, workload(o.Kind, d.Name, &one, d.Spec.Template.Spec))
+		case "HorizontalPodAutoscaler":
+			var hpa scaledObject
+			if err := yaml.Unmarshal([]byte(o.Content), &hpa); err != nil {
+				return nil, fmt.Errorf("cannot read autoscaler %s: %w", o.Metadata.Name, err)
+			}
+			autoscalers[hpa.Spec.ScaleTargetRef.Kind+"/"+hpa.Spec.ScaleTargetRef.Name] = hpa
+		case "PersistentVolumeClaim":
+			var pvc corev1.PersistentVolumeClaim
+			if err := yaml.Unmarshal([]byte(o.Content), &pvc); err != nil {
+				return nil, fmt.Errorf("cannot read claim %s: %w", o.Metadata.Name, err)
+			}
+			res.Volumes = append(res.Volumes, volume(pvc.Name, pvc.Spec, 1))
+		case "Service":
+			var svc corev1.Service
+			if err := yaml.Unmarshal([]byte(o.Content), &svc); err != nil {
+				return nil, fmt.Errorf("cannot read service %s: %w", o.Metadata.Name, err)
+			}
+			if svc.Spec.Type == corev1.ServiceTypeLoadBalancer {
+				res.LoadBalancers = append(res.LoadBalancers, svc.Name)
+			}
+		}
+	}
+
+	// the autoscaled deployments are rendered without replicas, they start with the minimum
+	for i, wl := range res.Components {
+		hpa, ok := autoscalers[wl.Kind+"/"+wl.Name]
+		if !ok {
+			continue
+		}
+		if hpa.Spec.MinReplicas != nil {
+			res.Components[i].Replicas = *hpa.Spec.MinReplicas
+		}
+		res.Components[i].MaxReplicas = hpa.Spec.MaxReplicas
+	}
+
+	res.Workspaces, err = workspaceClasses(cfg, workspaces)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Workspace.EnablePVC {
+		var count int64
+		for _, c := range res.Workspaces {
+			count += c.Count
+		}
+		if count > 0 {
+			res.Volumes = append(res.Volumes, Volume{
+				Name:         "workspaces",
+				StorageClass: cfg.Workspace.PVC.StorageClass,
+				Size:         cfg.Workspace.PVC.Size,
+				Count:        int32(count),
+			})
+		}
+	}
+
+	sortWorkloads(res.Components)
+	sortWorkloads(res.Nodes)
+	sort.Slice(res.Volumes, func(i, j int) bool { return res.Volumes[i].Name < res.Volumes[j].Name })
+	sort.Strings(res.LoadBalancers)
+
+	res.Total = total(res)
+	return res, nil
+}
+
+// workspaceClasses returns the workspaces of the classes, the default class has the resources of
+// the workspaces. It fails for the classes that are not configured.
+func workspaceClasses(cfg *config.Config, workspaces map[string]int64) ([]WorkspaceClass, error) {
+	classes := []WorkspaceClass{workspaceClass(cfg, DefaultWorkspaceClass, cfg.Workspace.Resources, 0)}
+	for _, c := range cfg.Workspace.Classes {
+		classes = append(classes, workspaceClass(cfg, c.Name, c.Resources, c.GPUs))
+	}
+
+	names := make([]string, 0, len(classes))
+	for _, c := range classes {
+		names = append(names, c.Name)
+	}
+	for name, count := range workspaces {
+		found := false
+		for i := range classes {
+			if classes[i].Name == name {
+				classes[i].Count = count
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown workspace class %s - the classes are: %s", name, strings.Join(names, ", "))
+		}
+		if count < 0 {
+			return nil, fmt.Errorf("the number of workspaces of class %s cannot be negative", name)
+		}
+	}
+
+	res := make([]WorkspaceClass, 0, len(classes))
+	for _, c := range classes {
+		if c.Count > 0 {
+			res = append(res, c)
+		}
+	}
+	return res, nil
+}
+
+// workspaceClass is what a workspace of the class requests. Its content is stored on a claim
+// if they are enabled, or on the node.
+func workspaceClass(cfg *config.Config, name string, resources config.Resources, gpus int64) WorkspaceClass {
+	res := WorkspaceClass{
+		Name: name,
+		Workspace: Resources{
+			CPU:    resources.Requests[corev1.ResourceCPU],
+			Memory: resources.Requests[corev1.ResourceMemory],
+		},
+		GPUs: gpus,
+	}
+	if !cfg.Workspace.EnablePVC {
+		res.Storage = resources.Requests[corev1.ResourceEphemeralStorage]
+	}
+	return res
+}
+
+func workload(kind, name string, replicas *int32, spec corev1.PodSpec) Workload {
+	r := int32(1)
+	if replicas != nil {
+		r = *replicas
+	}
+	return Workload{
+		Kind:        kind,
+		Name:        name,
+		Replicas:    r,
+		MaxReplicas: r,
+		Pod:         podRequests(spec),
+	}
+}
+
+// podRequests is what the pod is scheduled with: the sum of its containers, or the largest of
+// its init containers if that is more, as they run one after the other before the containers
+func podRequests(spec corev1.PodSpec) Resources {
+	var res Resources
+	for _, c := range spec.Containers {
+		res.CPU.Add(c.Resources.Requests[corev1.ResourceCPU])
+		res.Memory.Add(c.Resources.Requests[corev1.ResourceMemory])
+	}
+	for _, c := range spec.InitContainers {
+		if cpu := c.Resources.Requests[corev1.ResourceCPU]; cpu.Cmp(res.CPU) > 0 {
+			res.CPU = cpu
+		}
+		if mem := c.Resources.Requests[corev1.ResourceMemory]; mem.Cmp(res.Memory) > 0 {
+			res.Memory = mem
+		}
+	}
+	return res
+}
+
+func volume(name string, spec corev1.PersistentVolumeClaimSpec, count int32) Volume {
+	res := Volume{
+		Name:  name,
+		Size:  spec.Resources.Requests[corev1.ResourceStorage],
+		Count: count,
+	}
+	if spec.StorageClassName != nil {
+		res.StorageClass = *spec.StorageClassName
+	}
+	return res
+}
+
+func sortWorkloads(wls []Workload) {
+	sort.Slice(wls, func(i, j int) bool {
+		if wls[i].Kind != wls[j].Kind {
+			return wls[i].Kind < wls[j].Kind
+		}
+		return wls[i].Name < wls[j].Name
+	})
+}
+
+func total(e *Estimate) Total {
+	var res Total
+	for _, wl := range e.Components {
+		res.Components.add(wl.Pod, int64(wl.Replicas))
+		res.ComponentsMax.add(wl.Pod, int64(wl.MaxReplicas))
+	}
+	for _, wl := range e.Nodes {
+		res.Nodes.add(wl.Pod, 1)
+	}
+	for _, c := range e.Workspaces {
+		res.Workspaces.add(c.Workspace, c.Count)
+		res.WorkspaceStorage.Add(Scale(c.Storage, c.Count))
+		res.WorkspaceGPUs += c.GPUs * c.Count
+	}
+	for _, v := range e.Volumes {
+		res.Volumes.Add(Scale(v.Size, int64(v.Count)))
+	}
+	return res
+}
+
+func (r *Resources) add(o Resources, n int64) {
+	r.CPU.Add(Scale(o.CPU, n))
+	r.Memory.Add(Scale(o.Memory, n))
+}
+
+// Scale multiplies the quantity, which keeps its format. Decimal quantities below a thousand
+// units, like the CPUs, are multiplied in milli units so that their fractions are kept.
+func Scale(q resource.Quantity, n int64) resource.Quantity {
+	if q.IsZero() || n == 0 {
+		return resource.Quantity{Format: q.Format}
+	}
+	if q.Format == resource.DecimalSI && q.MilliValue() < 1000*1000 {
+		return *resource.NewMilliQuantity(q.MilliValue()*n, q.Format)
+	}
+	return *resource.NewQuantity(q.Value()*n, q.Format)
+}
@@ -0,0 +1,186 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package estimate_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+	"github.com/gitpod-io/gitpod/installer/pkg/estimate"
+)
+
+const objects = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: server
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: database-waiter
+        resources:
+          requests:
+            cpu: 50m
+            memory: 4Gi
+      containers:
+      - name: server
+        resources:
+          requests:
+            cpu: 200m
+            memory: 512Mi
+      - name: kube-rbac-proxy
+        resources:
+          requests:
+            cpu: 50m
+            memory: 32Mi
+---
+apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: server
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: server
+  minReplicas: 2
+  maxReplicas: 4
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: redis
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - name: redis
+        resources:
+          requests:
+            cpu: 100m
+            memory: 128Mi
+  volumeClaimTemplates:
+  - metadata:
+      name: data
+    spec:
+      storageClassName: ssd
+      resources:
+        requests:
+          storage: 8Gi
+---
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: ws-daemon
+spec:
+  template:
+    spec:
+      containers:
+      - name: ws-daemon
+        resources:
+          requests:
+            cpu: 500m
+            memory: 1Gi
+---
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: minio
+spec:
+  resources:
+    requests:
+      storage: 50Gi
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: proxy
+spec:
+  type: LoadBalancer
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: server
+spec:
+  type: ClusterIP
+`
+
+func TestCompute(t *testing.T) {
+	cfg := config.Config{
+		Workspace: config.Workspace{
+			Resources: config.Resources{Requests: corev1.ResourceList{
+				corev1.ResourceCPU:              resource.MustParse("1"),
+				corev1.ResourceMemory:           resource.MustParse("2Gi"),
+				corev1.ResourceEphemeralStorage: resource.MustParse("10Gi"),
+			}},
+			Classes: []config.WorkspaceClass{{
+				Name: "large",
+				Resources: config.Resources{Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("3500m"),
+					corev1.ResourceMemory: resource.MustParse("8Gi"),
+				}},
+				GPUs: 1,
+			}},
+			PVC: config.PersistentVolumeClaim{Size: resource.MustParse("30Gi"), StorageClass: "ssd"},
+		},
+	}
+
+	t.Run("ephemeral storage", func(t *testing.T) {
+		est, err := estimate.Compute(&cfg, []string{objects}, map[string]int64{"default": 10, "large": 2})
+		require.NoError(t, err)
+
+		require.Len(t, est.Components, 2)
+		require.Equal(t, "server", est.Components[0].Name)
+		require.Equal(t, int32(2), est.Components[0].Replicas)
+		require.Equal(t, int32(4), est.Components[0].MaxReplicas)
+		requireQuantity(t, "250m", est.Components[0].Pod.CPU)
+		requireQuantity(t, "4Gi", est.Components[0].Pod.Memory)
+
+		requireQuantity(t, "800m", est.Total.Components.CPU)
+		requireQuantity(t, "8576Mi", est.Total.Components.Memory)
+		requireQuantity(t, "1300m", est.Total.ComponentsMax.CPU)
+		requireQuantity(t, "16768Mi", est.Total.ComponentsMax.Memory)
+		requireQuantity(t, "500m", est.Total.Nodes.CPU)
+
+		requireQuantity(t, "17", est.Total.Workspaces.CPU)
+		requireQuantity(t, "36Gi", est.Total.Workspaces.Memory)
+		requireQuantity(t, "100Gi", est.Total.WorkspaceStorage)
+		require.Equal(t, int64(2), est.Total.WorkspaceGPUs)
+
+		require.Equal(t, []estimate.Volume{
+			{Name: "minio", Size: resource.MustParse("50Gi"), Count: 1},
+			{Name: "redis/data", StorageClass: "ssd", Size: resource.MustParse("8Gi"), Count: 3},
+		}, est.Volumes)
+		requireQuantity(t, "74Gi", est.Total.Volumes)
+		require.Equal(t, []string{"proxy"}, est.LoadBalancers)
+	})
+
+	t.Run("persistent volume claims", func(t *testing.T) {
+		pvc := cfg
+		pvc.Workspace.EnablePVC = true
+		est, err := estimate.Compute(&pvc, []string{objects}, map[string]int64{"default": 10, "large": 2})
+		require.NoError(t, err)
+
+		require.True(t, est.Total.WorkspaceStorage.IsZero())
+		requireQuantity(t, "434Gi", est.Total.Volumes)
+	})
+
+	t.Run("unknown class", func(t *testing.T) {
+		_, err := estimate.Compute(&cfg, []string{objects}, map[string]int64{"small": 1})
+		require.EqualError(t, err, "unknown workspace class small - the classes are: default, large")
+	})
+}
+
+func requireQuantity(t *testing.T, expected string, actual resource.Quantity) {
+	t.Helper()
+	q := resource.MustParse(expected)
+	require.Zero(t, q.Cmp(actual), "expected %s, got %s", expected, actual.String())
+}
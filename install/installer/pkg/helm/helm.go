@@ -162,6 +162,21 @@ func ImagePullSecrets(key string, ctx *common.RenderContext) string {
 	return ""
 }
 
+// OpenShiftValues disables the fixed user and group IDs of a Bitnami chart on OpenShift, so they are
+// assigned from the namespace's range instead. The volume permissions init container runs as root,
+// so it's disabled too. The key is the chart value that contains the pod's security contexts.
+func OpenShiftValues(ctx *common.RenderContext, chart string, key string) []string {
+	if !common.IsOpenShift(ctx) {
+		return nil
+	}
+
+	return []string{
+		KeyValue(fmt.Sprintf("%s.volumePermissions.enabled", chart), "false"),
+		KeyValue(fmt.Sprintf("%s.podSecurityContext.enabled", key), "false"),
+		KeyValue(fmt.Sprintf("%s.containerSecurityContext.enabled", key), "false"),
+	}
+}
+
 // ImportTemplate allows for Helm charts to be imported into the installer manifest
 func ImportTemplate(chart *charts.Chart, templateCfg TemplateConfig, pkgConfig PkgConfig) common.HelmFunc {
 	return func(cfg *common.RenderContext) (r []string, err error) {
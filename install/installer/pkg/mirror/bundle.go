@@ -0,0 +1,261 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package mirror
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/containerd/containerd/content/local"
+	"github.com/containerd/containerd/images"
+	"github.com/containerd/containerd/platforms"
+	"github.com/containerd/containerd/remotes"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// ManifestFile is the file in the bundle that lists the images it contains
+const ManifestFile = "gitpod-mirror.json"
+
+// Image is an image in the bundle
+type Image struct {
+	// Original is the image reference that was pulled
+	Original string `json:"original"`
+	// Target is the image reference that the image is pushed to
+	Target string `json:"target"`
+	// Digest is the digest of the manifest or index of the image
+	Digest digest.Digest `json:"digest,omitempty"`
+}
+
+// Bundle pulls the images and writes them as an OCI image layout tarball. The images
+// are listed in the bundle's manifest file, including the digests they were pulled at.
+func Bundle(ctx context.Context, resolver remotes.Resolver, imgs []Image, w io.Writer, progress func(img Image)) error {
+	dir, err := os.MkdirTemp("", "gitpod-mirror")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := local.NewStore(dir)
+	if err != nil {
+		return err
+	}
+
+	index := ocispec.Index{
+		MediaType: ocispec.MediaTypeImageIndex,
+	}
+	index.SchemaVersion = 2
+
+	bundled := make([]Image, 0, len(imgs))
+	for _, img := range imgs {
+		name, desc, err := resolver.Resolve(ctx, img.Original)
+		if err != nil {
+			return fmt.Errorf("cannot resolve image %s: %w", img.Original, err)
+		}
+		fetcher, err := resolver.Fetcher(ctx, name)
+		if err != nil {
+			return err
+		}
+
+		err = images.Dispatch(ctx, images.Handlers(remotes.FetchHandler(store, fetcher), images.ChildrenHandler(store)), nil, desc)
+		if err != nil {
+			return fmt.Errorf("cannot pull image %s: %w", img.Original, err)
+		}
+
+		desc.Annotations = map[string]string{
+			ocispec.AnnotationRefName: img.Original,
+		}
+		index.Manifests = append(index.Manifests, desc)
+
+		img.Digest = desc.Digest
+		bundled = append(bundled, img)
+		if progress != nil {
+			progress(img)
+		}
+	}
+
+	tw := tar.NewWriter(w)
+
+	layout, err := json.Marshal(ocispec.ImageLayout{Version: ocispec.ImageLayoutVersion})
+	if err != nil {
+		return err
+	}
+	indexJSON, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	manifestJSON, err := json.MarshalIndent(bundled, "", "  ")
+	if err != nil {
+		return err
+	}
+	files := []struct {
+		Name string
+		Data []byte
+	}{
+		{Name: ocispec.ImageLayoutFile, Data: layout},
+		{Name: "index.json", Data: indexJSON},
+		{Name: ManifestFile, Data: manifestJSON},
+	}
+	for _, f := range files {
+		if err := writeTarFile(tw, f.Name, f.Data); err != nil {
+			return err
+		}
+	}
+
+	// The content store keeps the blobs in the same structure as the OCI image layout
+	err = filepath.WalkDir(filepath.Join(dir, "blobs"), func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		err = tw.WriteHeader(&tar.Header{
+			Name:     filepath.ToSlash(rel),
+			Mode:     0644,
+			Size:     info.Size(),
+			Typeflag: tar.TypeReg,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return tw.Close()
+}
+
+// Push pushes every image in the bundle to its target
+func Push(ctx context.Context, resolver remotes.Resolver, r io.Reader, progress func(img Image)) error {
+	dir, err := os.MkdirTemp("", "gitpod-mirror")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	if err := extract(r, dir); err != nil {
+		return fmt.Errorf("cannot read bundle: %w", err)
+	}
+
+	var imgs []Image
+	if err := readJSONFile(filepath.Join(dir, ManifestFile), &imgs); err != nil {
+		return fmt.Errorf("cannot read bundle manifest: %w", err)
+	}
+	var index ocispec.Index
+	if err := readJSONFile(filepath.Join(dir, "index.json"), &index); err != nil {
+		return fmt.Errorf("cannot read bundle index: %w", err)
+	}
+	descs := make(map[digest.Digest]ocispec.Descriptor, len(index.Manifests))
+	for _, desc := range index.Manifests {
+		descs[desc.Digest] = desc
+	}
+
+	store, err := local.NewStore(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, img := range imgs {
+		desc, ok := descs[img.Digest]
+		if !ok {
+			return fmt.Errorf("image %s is not in the bundle", img.Original)
+		}
+
+		pusher, err := resolver.Pusher(ctx, img.Target)
+		if err != nil {
+			return err
+		}
+		desc.Annotations = nil
+		if err := remotes.PushContent(ctx, pusher, desc, store, nil, platforms.All, nil); err != nil {
+			return fmt.Errorf("cannot push image %s: %w", img.Target, err)
+		}
+
+		if progress != nil {
+			progress(img)
+		}
+	}
+
+	return nil
+}
+
+func writeTarFile(tw *tar.Writer, name string, data []byte) error {
+	err := tw.WriteHeader(&tar.Header{
+		Name:     name,
+		Mode:     0644,
+		Size:     int64(len(data)),
+		Typeflag: tar.TypeReg,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = tw.Write(data)
+	return err
+}
+
+func readJSONFile(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// extract writes the regular files in the tarball to the directory
+func extract(r io.Reader, dir string) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		path := filepath.Join(dir, filepath.FromSlash(hdr.Name))
+		if !strings.HasPrefix(path, filepath.Clean(dir)+string(os.PathSeparator)) {
+			return fmt.Errorf("invalid file path in bundle: %s", hdr.Name)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(f, tr)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+}
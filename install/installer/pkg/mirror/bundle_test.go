@@ -0,0 +1,50 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package mirror_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/mirror"
+	rendertest "github.com/gitpod-io/gitpod/installer/pkg/testing"
+)
+
+func TestBundleAndPush(t *testing.T) {
+	source := rendertest.NewMemoryRegistry()
+
+	manifest := ocispec.Manifest{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    source.Add(ocispec.MediaTypeImageConfig, []byte(`{"architecture":"amd64","os":"linux"}`)),
+		Layers:    []ocispec.Descriptor{source.Add(ocispec.MediaTypeImageLayer, []byte("layer"))},
+	}
+	manifest.SchemaVersion = 2
+	source.Tag("eu.gcr.io/gitpod-core-dev/build/server:v1", source.AddJSON(t, ocispec.MediaTypeImageManifest, manifest))
+
+	images := []mirror.Image{{
+		Original: "eu.gcr.io/gitpod-core-dev/build/server:v1",
+		Target:   "registry.example.com/gitpod/build/server:v1",
+	}}
+
+	var bundle bytes.Buffer
+	err := mirror.Bundle(context.Background(), source, images, &bundle, nil)
+	require.NoError(t, err)
+
+	target := rendertest.NewMemoryRegistry()
+	var pushed []mirror.Image
+	err = mirror.Push(context.Background(), target, &bundle, func(img mirror.Image) {
+		pushed = append(pushed, img)
+	})
+	require.NoError(t, err)
+
+	require.Len(t, pushed, 1)
+	require.Equal(t, source.Ref(images[0].Original).Digest, pushed[0].Digest)
+	require.Equal(t, source.Ref(images[0].Original), target.Ref(images[0].Target))
+	require.Equal(t, source.Blobs(), target.Blobs())
+}
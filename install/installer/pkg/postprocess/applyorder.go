@@ -0,0 +1,114 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package postprocess
+
+import (
+	"fmt"
+	"strconv"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+)
+
+const defaultApplyOrderAnnotation = "argocd.argoproj.io/sync-wave"
+
+// The waves the objects are applied in. Each wave is only applied once the objects of the
+// previous waves are ready.
+const (
+	// waveCluster has the definitions and cluster-wide objects the other objects refer to
+	waveCluster = iota
+	// waveConfig has the objects that are not running anything, such as secrets and roles
+	waveConfig
+	// waveDependencies has the workloads of the database, storage and message bus
+	waveDependencies
+	// waveMigrations has the database migrations
+	waveMigrations
+	// waveComponents has the Gitpod components
+	waveComponents
+	// waveSmokeTest has the smoke test, which needs all the components
+	waveSmokeTest
+)
+
+var clusterWaveKinds = map[string]struct{}{
+	common.TypeMetaNamespace.Kind:                  {},
+	common.TypeMetaPriorityClass.Kind:              {},
+	common.TypeMetaPodSecurityPolicy.Kind:          {},
+	common.TypeMetaSecurityContextConstraints.Kind: {},
+	common.TypeMetaCertificateClusterIssuer.Kind:   {},
+	common.TypeMetaCertificateIssuer.Kind:          {},
+	common.TypeMetaBundle.Kind:                     {},
+	"CustomResourceDefinition":                     {},
+	"StorageClass":                                 {},
+}
+
+// dependencyComponents are the components the Gitpod components cannot start without, by
+// their component label or, for the Helm charts, their name label
+var dependencyComponents = map[string]struct{}{
+	"db":              {},
+	"dbinit":          {},
+	"mysql":           {},
+	"cloudsqlproxy":   {},
+	"minio":           {},
+	"rabbitmq":        {},
+	"redis":           {},
+	"docker-registry": {},
+	"spicedb":         {},
+}
+
+// ApplyOrder annotates each object with the wave it is applied in
+func ApplyOrder(order *config.ApplyOrder, objects []common.RuntimeObject) ([]common.RuntimeObject, error) {
+	if order == nil {
+		return objects, nil
+	}
+
+	annotation := order.Annotation
+	if annotation == "" {
+		annotation = defaultApplyOrderAnnotation
+	}
+
+	for k, v := range objects {
+		var obj map[string]interface{}
+		if err := yaml.Unmarshal([]byte(v.Content), &obj); err != nil {
+			return nil, err
+		}
+		annotations, err := nestedMap(obj, "metadata", "annotations")
+		if err != nil {
+			return nil, fmt.Errorf("cannot set the apply order of %s %s: %w", v.Kind, v.Metadata.Name, err)
+		}
+		annotations[annotation] = strconv.Itoa(applyWave(v))
+
+		content, err := yaml.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		objects[k].Content = string(content)
+	}
+
+	return objects, nil
+}
+
+func applyWave(object common.RuntimeObject) int {
+	if _, ok := clusterWaveKinds[object.Kind]; ok {
+		return waveCluster
+	}
+	if _, ok := podTemplatePaths[object.Kind]; !ok && object.Kind != "Pod" {
+		return waveConfig
+	}
+
+	for _, label := range []string{"component", "app.kubernetes.io/name", "app"} {
+		if _, ok := dependencyComponents[object.Metadata.Labels[label]]; ok {
+			return waveDependencies
+		}
+	}
+	if object.Kind == common.TypeMetaBatchJob.Kind && object.Metadata.Labels["component"] == "migrations" {
+		return waveMigrations
+	}
+	if object.Kind == common.TypeMetaBatchJob.Kind && object.Metadata.Labels["component"] == "smoke-test" {
+		return waveSmokeTest
+	}
+	return waveComponents
+}
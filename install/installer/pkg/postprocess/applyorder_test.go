@@ -0,0 +1,106 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package postprocess_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+	"github.com/gitpod-io/gitpod/installer/pkg/postprocess"
+)
+
+const orderedObjects = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: workspaces.workspace.gitpod.io
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: mysql
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: mysql
+  labels:
+    app.kubernetes.io/name: mysql
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrations
+  labels:
+    app: gitpod
+    component: migrations
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: server
+  labels:
+    app: gitpod
+    component: server
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: smoke-test
+  labels:
+    app: gitpod
+    component: smoke-test
+`
+
+func TestApplyOrder(t *testing.T) {
+	tests := []struct {
+		Name       string
+		Order      *config.ApplyOrder
+		Annotation string
+		Expected   []string
+	}{
+		{
+			Name:     "no apply order",
+			Expected: []string{"", "", "", "", "", ""},
+		},
+		{
+			Name:       "argocd sync waves",
+			Order:      &config.ApplyOrder{},
+			Annotation: "argocd.argoproj.io/sync-wave",
+			Expected:   []string{"0", "1", "2", "3", "4", "5"},
+		},
+		{
+			Name:       "custom annotation",
+			Order:      &config.ApplyOrder{Annotation: "example.com/wave"},
+			Annotation: "example.com/wave",
+			Expected:   []string{"0", "1", "2", "3", "4", "5"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			objects, err := common.YamlToRuntimeObject([]string{orderedObjects})
+			require.NoError(t, err)
+
+			res, err := postprocess.ApplyOrder(test.Order, objects)
+			require.NoError(t, err)
+
+			var waves []string
+			for _, o := range res {
+				var obj struct {
+					Metadata struct {
+						Annotations map[string]string `json:"annotations"`
+					} `json:"metadata"`
+				}
+				require.NoError(t, yaml.Unmarshal([]byte(o.Content), &obj))
+				waves = append(waves, obj.Metadata.Annotations[test.Annotation])
+			}
+			require.Equal(t, test.Expected, waves)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package postprocess
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+)
+
+// autoscalerAnnotations are the annotations of the pods of each kind. The replicas of a deployment
+// can be started on another node, a stateful set's pod keeps data that would be unavailable until
+// it is running again, and a daemon set's pod is only needed on the node it runs on.
+var autoscalerAnnotations = map[string]map[string]string{
+	common.TypeMetaDeployment.Kind: {
+		common.AnnotationSafeToEvict: "true",
+	},
+	common.TypeMetaStatefulSet.Kind: {
+		common.AnnotationSafeToEvict: "false",
+	},
+	common.TypeMetaDaemonset.Kind: {
+		common.AnnotationSafeToEvict:      "true",
+		common.AnnotationEnableDSEviction: "true",
+	},
+}
+
+// ClusterAutoscaler annotates the pod templates so the cluster autoscaler can remove the nodes
+// whose pods can be moved, and the workspace nodes once they only run daemon sets
+func ClusterAutoscaler(autoscaler *config.ClusterAutoscaler, objects []common.RuntimeObject) ([]common.RuntimeObject, error) {
+	if autoscaler == nil || !autoscaler.Enabled {
+		return objects, nil
+	}
+
+	for k, v := range objects {
+		annotations, ok := autoscalerAnnotations[v.Kind]
+		if !ok {
+			continue
+		}
+
+		var obj map[string]interface{}
+		if err := yaml.Unmarshal([]byte(v.Content), &obj); err != nil {
+			return nil, err
+		}
+		podAnnotations, err := nestedMap(obj, append(podTemplatePaths[v.Kind], "metadata", "annotations")...)
+		if err != nil {
+			return nil, fmt.Errorf("cannot annotate %s %s for the cluster autoscaler: %w", v.Kind, v.Metadata.Name, err)
+		}
+		for name, value := range annotations {
+			podAnnotations[name] = value
+		}
+		content, err := yaml.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		objects[k].Content = string(content)
+	}
+
+	return objects, nil
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package postprocess_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+	"github.com/gitpod-io/gitpod/installer/pkg/postprocess"
+)
+
+func TestClusterAutoscaler(t *testing.T) {
+	tests := []struct {
+		Name       string
+		Autoscaler *config.ClusterAutoscaler
+		Object     string
+		Expected   map[string]string
+	}{
+		{
+			Name:       "disabled",
+			Autoscaler: &config.ClusterAutoscaler{Enabled: false},
+			Object:     deployment,
+		},
+		{
+			Name:       "deployment",
+			Autoscaler: &config.ClusterAutoscaler{Enabled: true},
+			Object:     deployment,
+			Expected:   map[string]string{"cluster-autoscaler.kubernetes.io/safe-to-evict": "true"},
+		},
+		{
+			Name:       "daemon set",
+			Autoscaler: &config.ClusterAutoscaler{Enabled: true},
+			Object:     daemonSet,
+			Expected: map[string]string{
+				"gitpod.io/checksum_config":                           "abc",
+				"cluster-autoscaler.kubernetes.io/safe-to-evict":      "true",
+				"cluster-autoscaler.kubernetes.io/enable-ds-eviction": "true",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			objects, err := common.YamlToRuntimeObject([]string{test.Object})
+			require.NoError(t, err)
+
+			res, err := postprocess.ClusterAutoscaler(test.Autoscaler, objects)
+			require.NoError(t, err)
+			require.Len(t, res, 1)
+
+			var obj struct {
+				Spec struct {
+					Template struct {
+						Metadata struct {
+							Annotations map[string]string `json:"annotations"`
+						} `json:"metadata"`
+					} `json:"template"`
+				} `json:"spec"`
+			}
+			require.NoError(t, yaml.Unmarshal([]byte(res[0].Content), &obj))
+			if test.Expected == nil {
+				require.NotContains(t, res[0].Content, "cluster-autoscaler")
+				return
+			}
+			require.Equal(t, test.Expected, obj.Spec.Template.Metadata.Annotations)
+		})
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package postprocess
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+)
+
+const (
+	// LabelManagedBy marks the objects the installer renders
+	LabelManagedBy = "app.kubernetes.io/managed-by"
+	// LabelPartOf is the application the objects belong to
+	LabelPartOf = "app.kubernetes.io/part-of"
+	// LabelInstallation is the namespace of the installation the objects belong to, which
+	// tells the cluster-scoped objects of several installations apart
+	LabelInstallation = "gitpod.io/installation"
+	// LabelConfigHash is the hash of the config the objects were rendered from
+	LabelConfigHash = "gitpod.io/config-hash"
+
+	// ManagedByInstaller is the value of LabelManagedBy
+	ManagedByInstaller = "gitpod-installer"
+)
+
+// configHashLength keeps the hash well within the 63 characters of a label value
+const configHashLength = 16
+
+// ConfigHash returns the hash of a config for the LabelConfigHash
+func ConfigHash(cfg interface{}) (string, error) {
+	fc, err := yaml.Marshal(cfg)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(fc)
+	return hex.EncodeToString(sum[:])[:configHashLength], nil
+}
+
+// OwnerSelector selects the objects of the installation in the namespace by their owner labels
+func OwnerSelector(namespace string) string {
+	return fmt.Sprintf("%s=%s,%s=%s", LabelManagedBy, ManagedByInstaller, LabelInstallation, namespace)
+}
+
+// OwnerLabels labels every object as managed by the installer for the installation in the
+// namespace, so that objects that are no longer rendered can be found and pruned. Only the
+// labels of the objects themselves are set, those of the pod templates would restart the pods
+// on every change of the config.
+func OwnerLabels(namespace string, configHash string, objects []common.RuntimeObject) ([]common.RuntimeObject, error) {
+	labels := map[string]string{
+		LabelManagedBy:    ManagedByInstaller,
+		LabelPartOf:       common.AppName,
+		LabelInstallation: namespace,
+		LabelConfigHash:   configHash,
+	}
+
+	for k, v := range objects {
+		var obj map[string]interface{}
+		if err := yaml.Unmarshal([]byte(v.Content), &obj); err != nil {
+			return nil, err
+		}
+		objLabels, err := nestedMap(obj, "metadata", "labels")
+		if err != nil {
+			return nil, fmt.Errorf("cannot label %s %s: %w", v.Kind, v.Metadata.Name, err)
+		}
+		if objects[k].Metadata.Labels == nil {
+			objects[k].Metadata.Labels = make(map[string]string, len(labels))
+		}
+		for name, value := range labels {
+			objLabels[name] = value
+			objects[k].Metadata.Labels[name] = value
+		}
+		content, err := yaml.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		objects[k].Content = string(content)
+	}
+
+	return objects, nil
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package postprocess_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	"github.com/gitpod-io/gitpod/installer/pkg/postprocess"
+)
+
+func TestOwnerLabels(t *testing.T) {
+	objects, err := common.YamlToRuntimeObject([]string{deployment})
+	require.NoError(t, err)
+
+	res, err := postprocess.OwnerLabels("gitpod", "0123456789abcdef", objects)
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+
+	var obj struct {
+		Metadata struct {
+			Labels map[string]string `json:"labels"`
+		} `json:"metadata"`
+		Spec struct {
+			Template struct {
+				Metadata struct {
+					Labels map[string]string `json:"labels"`
+				} `json:"metadata"`
+			} `json:"template"`
+		} `json:"spec"`
+	}
+	require.NoError(t, yaml.Unmarshal([]byte(res[0].Content), &obj))
+
+	expected := map[string]string{
+		"app.kubernetes.io/managed-by": "gitpod-installer",
+		"app.kubernetes.io/part-of":    "gitpod",
+		"gitpod.io/installation":       "gitpod",
+		"gitpod.io/config-hash":        "0123456789abcdef",
+	}
+	require.Equal(t, expected, obj.Metadata.Labels)
+	require.Equal(t, expected, res[0].Metadata.Labels)
+	// the pods are not restarted by a change of the config hash
+	require.Empty(t, obj.Spec.Template.Metadata.Labels)
+}
+
+func TestConfigHash(t *testing.T) {
+	a, err := postprocess.ConfigHash(map[string]string{"domain": "a.example.com"})
+	require.NoError(t, err)
+	b, err := postprocess.ConfigHash(map[string]string{"domain": "b.example.com"})
+	require.NoError(t, err)
+
+	require.Len(t, a, 16)
+	require.NotEqual(t, a, b)
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package postprocess
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+)
+
+// podTemplatePaths is the path to the pod template of the kinds that have one
+var podTemplatePaths = map[string][]string{
+	common.TypeMetaDeployment.Kind:   {"spec", "template"},
+	common.TypeMetaStatefulSet.Kind:  {"spec", "template"},
+	common.TypeMetaDaemonset.Kind:    {"spec", "template"},
+	common.TypeMetaBatchJob.Kind:     {"spec", "template"},
+	common.TypeMetaBatchCronJob.Kind: {"spec", "jobTemplate", "spec", "template"},
+}
+
+// Mesh adds the sidecar annotations of the service mesh to the pod templates and lets the
+// mesh control plane and the pods reach each other through the network policies
+func Mesh(mesh *config.Mesh, objects []common.RuntimeObject) ([]common.RuntimeObject, error) {
+	if mesh == nil {
+		return objects, nil
+	}
+
+	for k, v := range objects {
+		var modify func(obj map[string]interface{}) error
+		if path, ok := podTemplatePaths[v.Kind]; ok {
+			modify = func(obj map[string]interface{}) error {
+				return meshPodTemplate(mesh, v, obj, path)
+			}
+		} else if v.APIVersion == common.TypeMetaNetworkPolicy.APIVersion && v.Kind == common.TypeMetaNetworkPolicy.Kind {
+			modify = func(obj map[string]interface{}) error {
+				return meshNetworkPolicy(mesh, obj)
+			}
+		} else {
+			continue
+		}
+
+		var obj map[string]interface{}
+		if err := yaml.Unmarshal([]byte(v.Content), &obj); err != nil {
+			return nil, err
+		}
+		if err := modify(obj); err != nil {
+			return nil, fmt.Errorf("cannot apply service mesh settings to %s %s: %w", v.Kind, v.Metadata.Name, err)
+		}
+		content, err := yaml.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		objects[k].Content = string(content)
+	}
+
+	return objects, nil
+}
+
+// meshSidecar returns whether the pods of the object should get a sidecar
+func meshSidecar(mesh *config.Mesh, object common.RuntimeObject) bool {
+	component := object.Metadata.Labels["component"]
+	if component == "" {
+		component = object.Metadata.Name
+	}
+	if inject, ok := mesh.Sidecars[component]; ok {
+		return inject
+	}
+
+	switch object.Kind {
+	case common.TypeMetaDaemonset.Kind:
+		// Node components use the host network or talk to the container runtime, which the sidecar cannot proxy
+		return false
+	case common.TypeMetaBatchJob.Kind, common.TypeMetaBatchCronJob.Kind:
+		// A job does not complete while its sidecar keeps running
+		return false
+	}
+	return true
+}
+
+func meshPodTemplate(mesh *config.Mesh, object common.RuntimeObject, obj map[string]interface{}, path []string) error {
+	tpl, err := nestedMap(obj, append(path, "metadata")...)
+	if err != nil {
+		return err
+	}
+	annotations, err := nestedMap(tpl, "annotations")
+	if err != nil {
+		return err
+	}
+	for k, v := range common.MeshSidecarAnnotations(mesh, meshSidecar(mesh, object)) {
+		annotations[k] = v
+	}
+	return nil
+}
+
+func meshNetworkPolicy(mesh *config.Mesh, obj map[string]interface{}) error {
+	spec, err := nestedMap(obj, "spec")
+	if err != nil {
+		return err
+	}
+
+	controlPlane := map[string]interface{}{
+		"namespaceSelector": map[string]interface{}{
+			"matchLabels": map[string]interface{}{
+				"kubernetes.io/metadata.name": common.MeshControlPlaneNamespace(mesh),
+			},
+		},
+	}
+
+	if hasPolicyType(spec, "ingress", "Ingress") {
+		ingress, _ := spec["ingress"].([]interface{})
+		spec["ingress"] = append(ingress, map[string]interface{}{
+			"from": []interface{}{controlPlane},
+		})
+	}
+
+	// The sidecars fetch their certificates and configuration from the control plane
+	if hasPolicyType(spec, "egress", "Egress") {
+		var ports []interface{}
+		for _, port := range common.MeshControlPlanePorts(mesh) {
+			ports = append(ports, map[string]interface{}{"protocol": "TCP", "port": port})
+		}
+		egress, _ := spec["egress"].([]interface{})
+		spec["egress"] = append(egress, map[string]interface{}{
+			"to":    []interface{}{controlPlane},
+			"ports": ports,
+		})
+	}
+	return nil
+}
+
+// hasPolicyType returns whether the network policy restricts the direction of the traffic
+func hasPolicyType(spec map[string]interface{}, field, policyType string) bool {
+	if _, ok := spec[field]; ok {
+		return true
+	}
+	types, _ := spec["policyTypes"].([]interface{})
+	for _, t := range types {
+		if t == policyType {
+			return true
+		}
+	}
+	return false
+}
+
+// nestedMap returns the map at the path, creating the maps that don't exist yet
+func nestedMap(obj map[string]interface{}, path ...string) (map[string]interface{}, error) {
+	for _, p := range path {
+		v, ok := obj[p]
+		if !ok || v == nil {
+			m := make(map[string]interface{})
+			obj[p] = m
+			obj = m
+			continue
+		}
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s is not an object", p)
+		}
+		obj = m
+	}
+	return obj, nil
+}
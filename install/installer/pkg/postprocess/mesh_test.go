@@ -0,0 +1,148 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package postprocess_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/yaml"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+	"github.com/gitpod-io/gitpod/installer/pkg/config/versions"
+	"github.com/gitpod-io/gitpod/installer/pkg/postprocess"
+)
+
+const daemonSet = `apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: ws-daemon
+  labels:
+    component: ws-daemon
+spec:
+  template:
+    metadata:
+      annotations:
+        gitpod.io/checksum_config: abc
+`
+
+func TestMesh(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Mesh     *config.Mesh
+		Object   string
+		Expected map[string]string
+	}{
+		{
+			Name:     "inject deployment",
+			Mesh:     &config.Mesh{Kind: config.MeshLinkerd},
+			Object:   deployment,
+			Expected: map[string]string{"linkerd.io/inject": "enabled"},
+		},
+		{
+			Name:     "exclude daemon set",
+			Mesh:     &config.Mesh{Kind: config.MeshLinkerd},
+			Object:   daemonSet,
+			Expected: map[string]string{"gitpod.io/checksum_config": "abc", "linkerd.io/inject": "disabled"},
+		},
+		{
+			Name:     "component override",
+			Mesh:     &config.Mesh{Kind: config.MeshIstio, Sidecars: map[string]bool{"server": false}},
+			Object:   deployment,
+			Expected: map[string]string{"sidecar.istio.io/inject": "false"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			objects, err := common.YamlToRuntimeObject([]string{test.Object})
+			require.NoError(t, err)
+
+			res, err := postprocess.Mesh(test.Mesh, objects)
+			require.NoError(t, err)
+			require.Len(t, res, 1)
+
+			var obj struct {
+				Spec struct {
+					Template struct {
+						Metadata struct {
+							Annotations map[string]string `json:"annotations"`
+						} `json:"metadata"`
+					} `json:"template"`
+				} `json:"spec"`
+			}
+			require.NoError(t, yaml.Unmarshal([]byte(res[0].Content), &obj))
+			require.Equal(t, test.Expected, obj.Spec.Template.Metadata.Annotations)
+		})
+	}
+}
+
+func TestMeshNetworkPolicy(t *testing.T) {
+	controlPlane := []networkingv1.NetworkPolicyPeer{{
+		NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"kubernetes.io/metadata.name": "linkerd"}},
+	}}
+
+	tests := []struct {
+		Name           string
+		Policy         config.NetworkPolicyMode
+		ExpectedEgress []networkingv1.NetworkPolicyPort
+	}{
+		{
+			Name: "default policy",
+		},
+		{
+			Name:   "strict policy",
+			Policy: config.NetworkPolicyStrict,
+			ExpectedEgress: []networkingv1.NetworkPolicyPort{
+				{Protocol: common.TCPProtocol, Port: &intstr.IntOrString{IntVal: 8080}},
+				{Protocol: common.TCPProtocol, Port: &intstr.IntOrString{IntVal: 8086}},
+				{Protocol: common.TCPProtocol, Port: &intstr.IntOrString{IntVal: 8090}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			ctx, err := common.NewRenderContext(config.Config{
+				Network: &config.Network{Policy: test.Policy},
+			}, versions.Manifest{}, "test_namespace")
+			require.NoError(t, err)
+
+			content, err := yaml.Marshal(&networkingv1.NetworkPolicy{
+				TypeMeta:   common.TypeMetaNetworkPolicy,
+				ObjectMeta: metav1.ObjectMeta{Name: "server"},
+				Spec: networkingv1.NetworkPolicySpec{
+					PolicyTypes: common.NetworkPolicyTypes(ctx),
+					Egress:      common.NetworkPolicyEgress(ctx),
+					Ingress:     []networkingv1.NetworkPolicyIngressRule{{}},
+				},
+			})
+			require.NoError(t, err)
+			objects, err := common.YamlToRuntimeObject([]string{string(content)})
+			require.NoError(t, err)
+
+			res, err := postprocess.Mesh(&config.Mesh{Kind: config.MeshLinkerd}, objects)
+			require.NoError(t, err)
+
+			var policy networkingv1.NetworkPolicy
+			require.NoError(t, yaml.Unmarshal([]byte(res[0].Content), &policy))
+
+			ingress := policy.Spec.Ingress[len(policy.Spec.Ingress)-1]
+			require.Equal(t, controlPlane, ingress.From, "the control plane reaches the pods")
+
+			if test.ExpectedEgress == nil {
+				require.Empty(t, policy.Spec.Egress)
+				return
+			}
+			egress := policy.Spec.Egress[len(policy.Spec.Egress)-1]
+			require.Equal(t, controlPlane, egress.To, "the sidecars reach the control plane")
+			require.Equal(t, test.ExpectedEgress, egress.Ports)
+		})
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package postprocess
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+)
+
+// clusterScopedKinds are the kinds of the objects that can only be created with cluster permissions
+var clusterScopedKinds = map[string]struct{}{
+	common.TypeMetaNamespace.Kind:                      {},
+	common.TypeMetaPodSecurityPolicy.Kind:              {},
+	common.TypeMetaSecurityContextConstraints.Kind:     {},
+	common.TypeMetaCertificateClusterIssuer.Kind:       {},
+	common.TypeMetaBundle.Kind:                         {},
+	common.TypeMetaPriorityClass.Kind:                  {},
+	common.TypeMetaMutatingWebhookConfiguration.Kind:   {},
+	common.TypeMetaValidatingWebhookConfiguration.Kind: {},
+	"CustomResourceDefinition":                         {},
+}
+
+// clusterScopedResources are the resources a role in a namespace cannot grant access to
+var clusterScopedResources = map[string]struct{}{
+	"nodes":                           {},
+	"namespaces":                      {},
+	"persistentvolumes":               {},
+	"storageclasses":                  {},
+	"priorityclasses":                 {},
+	"runtimeclasses":                  {},
+	"tokenreviews":                    {},
+	"subjectaccessreviews":            {},
+	"certificatesigningrequests":      {},
+	"clusterroles":                    {},
+	"clusterrolebindings":             {},
+	"customresourcedefinitions":       {},
+	"validatingwebhookconfigurations": {},
+	"mutatingwebhookconfigurations":   {},
+}
+
+// NamespaceScope turns the cluster roles and their bindings into roles and role bindings in the
+// namespace, and drops the objects that cannot be created in a namespace
+func NamespaceScope(namespace string, objects []common.RuntimeObject) ([]common.RuntimeObject, error) {
+	clusterRoles := make(map[string]struct{})
+	for _, o := range objects {
+		if o.Kind == common.TypeMetaClusterRole.Kind {
+			clusterRoles[o.Metadata.Name] = struct{}{}
+		}
+	}
+
+	res := make([]common.RuntimeObject, 0, len(objects))
+	for _, o := range objects {
+		if _, ok := clusterScopedKinds[o.Kind]; ok {
+			continue
+		}
+
+		var modify func(obj map[string]interface{}) error
+		switch o.Kind {
+		case common.TypeMetaClusterRole.Kind:
+			o.Kind = common.TypeMetaRole.Kind
+			modify = namespaceRole
+		case common.TypeMetaClusterRoleBinding.Kind:
+			o.Kind = common.TypeMetaRoleBinding.Kind
+			modify = func(obj map[string]interface{}) error {
+				return namespaceRoleBinding(clusterRoles, obj)
+			}
+		case common.TypeMetaRoleBinding.Kind:
+			modify = func(obj map[string]interface{}) error {
+				return namespaceRoleBinding(clusterRoles, obj)
+			}
+		default:
+			res = append(res, o)
+			continue
+		}
+
+		var obj map[string]interface{}
+		if err := yaml.Unmarshal([]byte(o.Content), &obj); err != nil {
+			return nil, err
+		}
+		obj["kind"] = o.Kind
+		metadata, err := nestedMap(obj, "metadata")
+		if err != nil {
+			return nil, err
+		}
+		metadata["namespace"] = namespace
+		o.Metadata.Namespace = namespace
+
+		if err := modify(obj); err != nil {
+			return nil, fmt.Errorf("cannot scope %s %s to the namespace: %w", o.Kind, o.Metadata.Name, err)
+		}
+		content, err := yaml.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		o.Content = string(content)
+		res = append(res, o)
+	}
+
+	return res, nil
+}
+
+// namespaceRole removes the rules for the resources that are not in a namespace
+func namespaceRole(obj map[string]interface{}) error {
+	rules, _ := obj["rules"].([]interface{})
+	scoped := make([]interface{}, 0, len(rules))
+	for _, r := range rules {
+		rule, ok := r.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("rule is not an object")
+		}
+		if _, ok := rule["nonResourceURLs"]; ok {
+			continue
+		}
+
+		resources, _ := rule["resources"].([]interface{})
+		var namespaced []interface{}
+		for _, resource := range resources {
+			if _, ok := clusterScopedResources[fmt.Sprint(resource)]; !ok {
+				namespaced = append(namespaced, resource)
+			}
+		}
+		if len(namespaced) == 0 {
+			continue
+		}
+		rule["resources"] = namespaced
+		scoped = append(scoped, rule)
+	}
+	obj["rules"] = scoped
+	return nil
+}
+
+// namespaceRoleBinding references the role that replaces the cluster role of the binding
+func namespaceRoleBinding(clusterRoles map[string]struct{}, obj map[string]interface{}) error {
+	roleRef, err := nestedMap(obj, "roleRef")
+	if err != nil {
+		return err
+	}
+	if roleRef["kind"] != common.TypeMetaClusterRole.Kind {
+		return nil
+	}
+	if _, ok := clusterRoles[fmt.Sprint(roleRef["name"])]; ok {
+		roleRef["kind"] = common.TypeMetaRole.Kind
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package postprocess_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	"github.com/gitpod-io/gitpod/installer/pkg/postprocess"
+)
+
+const clusterScopedObjects = `apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: gitpod-kube-rbac-proxy
+rules:
+- apiGroups: [authentication.k8s.io]
+  resources: [tokenreviews]
+  verbs: [create]
+- apiGroups: [""]
+  resources: [nodes, pods]
+  verbs: [get, list]
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: gitpod-server-rb-kube-rbac-proxy
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: gitpod-kube-rbac-proxy
+subjects:
+- kind: ServiceAccount
+  name: server
+  namespace: gitpod
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: server
+  namespace: gitpod
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: gitpod-kube-rbac-proxy
+subjects:
+- kind: ServiceAccount
+  name: server
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: server-view
+  namespace: gitpod
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: view
+subjects:
+- kind: ServiceAccount
+  name: server
+---
+apiVersion: scheduling.k8s.io/v1
+kind: PriorityClass
+metadata:
+  name: system-node-critical
+value: 1000
+`
+
+func TestNamespaceScope(t *testing.T) {
+	objects, err := common.YamlToRuntimeObject([]string{clusterScopedObjects})
+	require.NoError(t, err)
+
+	res, err := postprocess.NamespaceScope("gitpod", objects)
+	require.NoError(t, err)
+	require.Len(t, res, 4, "the priority class is dropped")
+
+	var role rbacv1.Role
+	require.NoError(t, yaml.Unmarshal([]byte(res[0].Content), &role))
+	require.Equal(t, common.TypeMetaRole.Kind, res[0].Kind)
+	require.Equal(t, "gitpod", role.Namespace)
+	require.Equal(t, []rbacv1.PolicyRule{{
+		APIGroups: []string{""},
+		Resources: []string{"pods"},
+		Verbs:     []string{"get", "list"},
+	}}, role.Rules)
+
+	expectedRoleRefs := map[string]string{
+		"gitpod-server-rb-kube-rbac-proxy": common.TypeMetaRole.Kind,
+		"server":                           common.TypeMetaRole.Kind,
+		"server-view":                      common.TypeMetaClusterRole.Kind,
+	}
+	for _, o := range res[1:] {
+		var binding rbacv1.RoleBinding
+		require.NoError(t, yaml.Unmarshal([]byte(o.Content), &binding))
+		require.Equal(t, common.TypeMetaRoleBinding.Kind, binding.Kind)
+		require.Equal(t, "gitpod", binding.Namespace)
+		require.Equal(t, expectedRoleRefs[binding.Name], binding.RoleRef.Kind, binding.Name)
+	}
+}
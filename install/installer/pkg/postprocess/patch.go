@@ -0,0 +1,113 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package postprocess
+
+import (
+	"fmt"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+)
+
+func customizationMatches(customization config.Customization, object common.RuntimeObject) bool {
+	// Must match value or "*"
+	return (customization.APIVersion == object.APIVersion || customization.APIVersion == "*") &&
+		(customization.Kind == object.Kind || customization.Kind == "*") &&
+		(customization.Metadata.Name == object.Metadata.Name || customization.Metadata.Name == "*")
+}
+
+// Patch applies the customization patches to the objects they match. Patches are
+// applied in the order they are listed in the config.
+func Patch(customizations *[]config.Customization, objects []common.RuntimeObject) ([]common.RuntimeObject, error) {
+	if customizations == nil {
+		return objects, nil
+	}
+
+	for _, customization := range *customizations {
+		if len(customization.Patches) == 0 {
+			continue
+		}
+
+		for k, v := range objects {
+			if !customizationMatches(customization, v) {
+				continue
+			}
+
+			original := v
+			for i, patch := range customization.Patches {
+				content, err := applyPatch(v, patch)
+				if err != nil {
+					return nil, fmt.Errorf("cannot apply patch %d to %s %s: %w", i, original.Kind, original.Metadata.Name, err)
+				}
+
+				// Parse the object again as the patch may change its metadata
+				res, err := common.YamlToRuntimeObject([]string{content})
+				if err != nil {
+					return nil, err
+				}
+				if len(res) != 1 {
+					return nil, fmt.Errorf("patch %d to %s %s must result in a single object", i, original.Kind, original.Metadata.Name)
+				}
+				v = res[0]
+			}
+			objects[k] = v
+		}
+	}
+
+	return objects, nil
+}
+
+func applyPatch(object common.RuntimeObject, patch config.CustomizationPatch) (string, error) {
+	original, err := yaml.YAMLToJSON([]byte(object.Content))
+	if err != nil {
+		return "", err
+	}
+	data, err := yaml.YAMLToJSON([]byte(patch.Patch))
+	if err != nil {
+		return "", fmt.Errorf("invalid patch: %w", err)
+	}
+
+	var patched []byte
+	switch patch.Type {
+	case config.CustomizationPatchStrategic:
+		dataStruct, err := scheme.Scheme.New(object.GroupVersionKind())
+		if err != nil {
+			// Not a known type so the patch strategy is unknown
+			patched, err = jsonpatch.MergePatch(original, data)
+			if err != nil {
+				return "", err
+			}
+			break
+		}
+
+		patched, err = strategicpatch.StrategicMergePatch(original, data, dataStruct)
+		if err != nil {
+			return "", err
+		}
+	case config.CustomizationPatchJSON:
+		p, err := jsonpatch.DecodePatch(data)
+		if err != nil {
+			return "", fmt.Errorf("invalid patch: %w", err)
+		}
+		patched, err = p.Apply(original)
+		if err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("unsupported patch type: %s", patch.Type)
+	}
+
+	content, err := yaml.JSONToYAML(patched)
+	if err != nil {
+		return "", err
+	}
+
+	return string(content), nil
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package postprocess_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+	"github.com/gitpod-io/gitpod/installer/pkg/postprocess"
+)
+
+const deployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: server
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: server
+        image: server:v1
+      - name: kube-rbac-proxy
+        image: kube-rbac-proxy:v1
+`
+
+func TestPatch(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Customization config.Customization
+		Expected      string
+	}{
+		{
+			Name: "strategic merge keeps other containers",
+			Customization: config.Customization{
+				TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+				Metadata: metav1.ObjectMeta{Name: "server"},
+				Patches: []config.CustomizationPatch{{
+					Type:  config.CustomizationPatchStrategic,
+					Patch: "spec:\n  template:\n    spec:\n      containers:\n      - name: server\n        image: server:v2\n",
+				}},
+			},
+			Expected: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: server
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - image: server:v2
+        name: server
+      - image: kube-rbac-proxy:v1
+        name: kube-rbac-proxy
+`,
+		},
+		{
+			Name: "json patch on any kind",
+			Customization: config.Customization{
+				TypeMeta: metav1.TypeMeta{APIVersion: "*", Kind: "*"},
+				Metadata: metav1.ObjectMeta{Name: "server"},
+				Patches: []config.CustomizationPatch{{
+					Type:  config.CustomizationPatchJSON,
+					Patch: `[{"op": "replace", "path": "/spec/replicas", "value": 3}]`,
+				}},
+			},
+			Expected: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: server
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - image: server:v1
+        name: server
+      - image: kube-rbac-proxy:v1
+        name: kube-rbac-proxy
+`,
+		},
+		{
+			Name: "other names are not patched",
+			Customization: config.Customization{
+				TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+				Metadata: metav1.ObjectMeta{Name: "dashboard"},
+				Patches: []config.CustomizationPatch{{
+					Type:  config.CustomizationPatchJSON,
+					Patch: `[{"op": "remove", "path": "/spec"}]`,
+				}},
+			},
+			Expected: deployment,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			objects, err := common.YamlToRuntimeObject([]string{deployment})
+			require.NoError(t, err)
+
+			res, err := postprocess.Patch(&[]config.Customization{test.Customization}, objects)
+			require.NoError(t, err)
+			require.Len(t, res, 1)
+			require.YAMLEq(t, test.Expected, res[0].Content)
+		})
+	}
+}
@@ -25,6 +25,20 @@ var Processors = []Processor{
 		Expression: "del(.status)",
 		Name:       pointer.String(openvsxproxy.Component),
 	},
+	// Remove "status" from root of all horizontal pod autoscalers
+	{
+		Type:       common.TypeMetaHorizontalPodAutoscaler,
+		Expression: "del(.status)",
+	},
+	{
+		Type:       common.TypeMetaHorizontalPodAutoscalerV2beta2,
+		Expression: "del(.status)",
+	},
+	// Remove "status" from root of all pod disruption budgets
+	{
+		Type:       common.TypeMetaPodDisruptionBudget,
+		Expression: "del(.status)",
+	},
 }
 
 type Processor struct {
@@ -0,0 +1,195 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package postprocess
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+)
+
+const defaultExternalSecretRefreshInterval = time.Hour
+
+// Secrets replaces the secrets with the resources the secrets operator of the backend creates
+// them from, so that the values are not part of the rendered objects
+func Secrets(backend *config.SecretsBackend, namespace string, objects []common.RuntimeObject) ([]common.RuntimeObject, error) {
+	if backend == nil {
+		return objects, nil
+	}
+
+	var (
+		typeMeta = common.TypeMetaExternalSecret
+		convert  = func(secret *corev1.Secret) (interface{}, error) {
+			return externalSecret(backend.ExternalSecrets, secret), nil
+		}
+	)
+	if backend.Kind == config.SecretsBackendSealedSecrets {
+		key, err := backend.SealedSecrets.PublicKey()
+		if err != nil {
+			return nil, err
+		}
+		typeMeta = common.TypeMetaSealedSecret
+		convert = func(secret *corev1.Secret) (interface{}, error) {
+			return sealedSecret(key, secret)
+		}
+	}
+
+	for k, v := range objects {
+		if v.Kind != common.TypeMetaSecret.Kind {
+			continue
+		}
+
+		var secret corev1.Secret
+		if err := yaml.Unmarshal([]byte(v.Content), &secret); err != nil {
+			return nil, err
+		}
+		if secret.Namespace == "" {
+			secret.Namespace = namespace
+		}
+
+		obj, err := convert(&secret)
+		if err != nil {
+			return nil, fmt.Errorf("cannot convert secret %s: %w", secret.Name, err)
+		}
+		content, err := yaml.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		objects[k].TypeMeta = typeMeta
+		objects[k].Content = string(content)
+	}
+
+	return objects, nil
+}
+
+// secretTemplateMetadata is the metadata of the secret the operator creates
+func secretTemplateMetadata(secret *corev1.Secret) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Labels:      secret.Labels,
+		Annotations: secret.Annotations,
+	}
+}
+
+// externalSecret reads the values of the secret from the key of the same name in the store
+func externalSecret(backend *config.ExternalSecretsBackend, secret *corev1.Secret) map[string]interface{} {
+	refreshInterval := defaultExternalSecretRefreshInterval
+	if backend.RefreshInterval != nil {
+		refreshInterval = time.Duration(*backend.RefreshInterval)
+	}
+	storeKind := "SecretStore"
+	if backend.ClusterSecretStore {
+		storeKind = "ClusterSecretStore"
+	}
+
+	return map[string]interface{}{
+		"apiVersion": common.TypeMetaExternalSecret.APIVersion,
+		"kind":       common.TypeMetaExternalSecret.Kind,
+		"metadata": metav1.ObjectMeta{
+			Name:      secret.Name,
+			Namespace: secret.Namespace,
+			Labels:    secret.Labels,
+		},
+		"spec": map[string]interface{}{
+			"refreshInterval": refreshInterval.String(),
+			"secretStoreRef": map[string]interface{}{
+				"name": backend.SecretStore,
+				"kind": storeKind,
+			},
+			"target": map[string]interface{}{
+				"name":           secret.Name,
+				"creationPolicy": "Owner",
+				"template": map[string]interface{}{
+					"type":     secret.Type,
+					"metadata": secretTemplateMetadata(secret),
+				},
+			},
+			"dataFrom": []interface{}{
+				map[string]interface{}{
+					"extract": map[string]interface{}{"key": backend.KeyPrefix + secret.Name},
+				},
+			},
+		},
+	}
+}
+
+// sealedSecret encrypts the values of the secret for the namespace and name of the secret
+func sealedSecret(key *rsa.PublicKey, secret *corev1.Secret) (map[string]interface{}, error) {
+	// the strict scope of sealed-secrets, the values can only be decrypted into this secret
+	label := []byte(fmt.Sprintf("%s/%s", secret.Namespace, secret.Name))
+
+	values := make(map[string][]byte, len(secret.Data)+len(secret.StringData))
+	for k, v := range secret.Data {
+		values[k] = v
+	}
+	for k, v := range secret.StringData {
+		values[k] = []byte(v)
+	}
+
+	encryptedData := make(map[string]string, len(values))
+	for k, v := range values {
+		ciphertext, err := hybridEncrypt(key, v, label)
+		if err != nil {
+			return nil, err
+		}
+		encryptedData[k] = base64.StdEncoding.EncodeToString(ciphertext)
+	}
+
+	return map[string]interface{}{
+		"apiVersion": common.TypeMetaSealedSecret.APIVersion,
+		"kind":       common.TypeMetaSealedSecret.Kind,
+		"metadata": metav1.ObjectMeta{
+			Name:      secret.Name,
+			Namespace: secret.Namespace,
+			Labels:    secret.Labels,
+		},
+		"spec": map[string]interface{}{
+			"encryptedData": encryptedData,
+			"template": map[string]interface{}{
+				"type":     secret.Type,
+				"metadata": secretTemplateMetadata(secret),
+			},
+		},
+	}, nil
+}
+
+// hybridEncrypt encrypts the plaintext the way sealed-secrets does: with a random AES key that is
+// encrypted with the RSA key and prepended with its length
+func hybridEncrypt(key *rsa.PublicKey, plaintext, label []byte) ([]byte, error) {
+	sessionKey := make([]byte, 32)
+	if _, err := rand.Read(sessionKey); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(sessionKey)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	rsaCiphertext, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, key, sessionKey, label)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext := binary.BigEndian.AppendUint16(nil, uint16(len(rsaCiphertext)))
+	ciphertext = append(ciphertext, rsaCiphertext...)
+	// the session key is only used once, so the nonce can be zero
+	return aead.Seal(ciphertext, make([]byte, aead.NonceSize()), plaintext, nil), nil
+}
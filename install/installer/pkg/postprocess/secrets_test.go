@@ -0,0 +1,132 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package postprocess_test
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+
+	"github.com/gitpod-io/gitpod/common-go/util"
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+	"github.com/gitpod-io/gitpod/installer/pkg/postprocess"
+)
+
+const secret = `apiVersion: v1
+kind: Secret
+metadata:
+  name: builtin-registry-auth
+  labels:
+    app: gitpod
+type: kubernetes.io/dockerconfigjson
+data:
+  .dockerconfigjson: e30=
+stringData:
+  user: gitpod
+`
+
+func secretObjects(t *testing.T) []common.RuntimeObject {
+	objs, err := common.YamlToRuntimeObject([]string{secret, deployment})
+	require.NoError(t, err)
+	return objs
+}
+
+func TestSecretsExternalSecrets(t *testing.T) {
+	refreshInterval := util.Duration(10 * time.Minute)
+	objs, err := postprocess.Secrets(&config.SecretsBackend{
+		Kind: config.SecretsBackendExternalSecrets,
+		ExternalSecrets: &config.ExternalSecretsBackend{
+			SecretStore:        "vault",
+			ClusterSecretStore: true,
+			KeyPrefix:          "gitpod/",
+			RefreshInterval:    &refreshInterval,
+		},
+	}, "gitpod", secretObjects(t))
+	require.NoError(t, err)
+	require.Equal(t, common.TypeMetaExternalSecret, objs[0].TypeMeta)
+	require.Equal(t, "Deployment", objs[1].Kind, "only secrets are converted")
+
+	var obj struct {
+		Metadata struct {
+			Namespace string `json:"namespace"`
+		} `json:"metadata"`
+		Spec struct {
+			RefreshInterval string            `json:"refreshInterval"`
+			SecretStoreRef  map[string]string `json:"secretStoreRef"`
+			Target          struct {
+				Name     string `json:"name"`
+				Template struct {
+					Type string `json:"type"`
+				} `json:"template"`
+			} `json:"target"`
+			DataFrom []struct {
+				Extract map[string]string `json:"extract"`
+			} `json:"dataFrom"`
+		} `json:"spec"`
+	}
+	require.NoError(t, yaml.Unmarshal([]byte(objs[0].Content), &obj))
+	require.Equal(t, "gitpod", obj.Metadata.Namespace)
+	require.Equal(t, "10m0s", obj.Spec.RefreshInterval)
+	require.Equal(t, map[string]string{"name": "vault", "kind": "ClusterSecretStore"}, obj.Spec.SecretStoreRef)
+	require.Equal(t, "builtin-registry-auth", obj.Spec.Target.Name)
+	require.Equal(t, "kubernetes.io/dockerconfigjson", obj.Spec.Target.Template.Type)
+	require.Equal(t, "gitpod/builtin-registry-auth", obj.Spec.DataFrom[0].Extract["key"])
+}
+
+func TestSecretsSealedSecrets(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	tpl := &x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "sealed-secret"}, NotAfter: time.Now().Add(time.Hour)}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	objs, err := postprocess.Secrets(&config.SecretsBackend{
+		Kind: config.SecretsBackendSealedSecrets,
+		SealedSecrets: &config.SealedSecretsBackend{
+			Certificate: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		},
+	}, "gitpod", secretObjects(t))
+	require.NoError(t, err)
+	require.Equal(t, common.TypeMetaSealedSecret, objs[0].TypeMeta)
+	require.NotContains(t, objs[0].Content, "e30=")
+
+	var obj struct {
+		Spec struct {
+			EncryptedData map[string]string `json:"encryptedData"`
+		} `json:"spec"`
+	}
+	require.NoError(t, yaml.Unmarshal([]byte(objs[0].Content), &obj))
+
+	decrypt := func(value string) string {
+		ciphertext, err := base64.StdEncoding.DecodeString(value)
+		require.NoError(t, err)
+		l := binary.BigEndian.Uint16(ciphertext)
+		sessionKey, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, key, ciphertext[2:2+l], []byte("gitpod/builtin-registry-auth"))
+		require.NoError(t, err)
+		block, err := aes.NewCipher(sessionKey)
+		require.NoError(t, err)
+		aead, err := cipher.NewGCM(block)
+		require.NoError(t, err)
+		plaintext, err := aead.Open(nil, make([]byte, aead.NonceSize()), ciphertext[2+l:], nil)
+		require.NoError(t, err)
+		return string(plaintext)
+	}
+	require.Equal(t, "{}", decrypt(obj.Spec.EncryptedData[".dockerconfigjson"]))
+	require.Equal(t, "gitpod", decrypt(obj.Spec.EncryptedData["user"]))
+}
@@ -0,0 +1,39 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package postprocess
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/utils/pointer"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+)
+
+const minioComponent = "minio"
+
+// ExternalStorage fails if the object storage is external and any of the objects still belongs to
+// MinIO or references its service, which does not exist then
+func ExternalStorage(storage config.ObjectStorage, namespace string, objects []common.RuntimeObject) error {
+	if pointer.BoolDeref(storage.InCluster, false) {
+		return nil
+	}
+
+	service := fmt.Sprintf("%s.%s.svc", minioComponent, namespace)
+	var refs []string
+	for _, v := range objects {
+		labels := v.Metadata.Labels
+		if labels["component"] == minioComponent || labels["app.kubernetes.io/name"] == minioComponent || strings.Contains(v.Content, service) {
+			refs = append(refs, fmt.Sprintf("%s %s", v.Kind, v.Metadata.Name))
+		}
+	}
+	if len(refs) > 0 {
+		return fmt.Errorf("the object storage is external, but these objects belong to or reference the in-cluster MinIO: %s", strings.Join(refs, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package postprocess_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/pointer"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	config "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+	"github.com/gitpod-io/gitpod/installer/pkg/postprocess"
+)
+
+const minioConfigMap = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: proxy-config
+  labels:
+    component: proxy
+data:
+  vhost.minio: reverse_proxy minio.gitpod.svc.cluster.local:9001
+`
+
+const minioRoleBinding = `apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: minio
+  labels:
+    component: minio
+`
+
+func TestExternalStorage(t *testing.T) {
+	external := config.ObjectStorage{S3: &config.ObjectStorageS3{Endpoint: "s3.amazonaws.com", BucketName: "gitpod"}}
+
+	tests := []struct {
+		Name     string
+		Storage  config.ObjectStorage
+		Objects  []string
+		Expected string
+	}{
+		{
+			Name:    "in-cluster storage",
+			Storage: config.ObjectStorage{InCluster: pointer.Bool(true)},
+			Objects: []string{minioConfigMap, minioRoleBinding},
+		},
+		{
+			Name:    "no reference",
+			Storage: external,
+			Objects: []string{deployment},
+		},
+		{
+			Name:     "references",
+			Storage:  external,
+			Objects:  []string{deployment, minioConfigMap, minioRoleBinding},
+			Expected: "the object storage is external, but these objects belong to or reference the in-cluster MinIO: ConfigMap proxy-config, RoleBinding minio",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			objects, err := common.YamlToRuntimeObject(test.Objects)
+			require.NoError(t, err)
+
+			err = postprocess.ExternalStorage(test.Storage, "gitpod", objects)
+			if test.Expected == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, test.Expected)
+			}
+		})
+	}
+}
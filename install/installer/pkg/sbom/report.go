@@ -0,0 +1,203 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package sbom
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/docker/distribution/reference"
+)
+
+const toolName = "gitpod-installer"
+
+// Report aggregates the SBOMs of the images into a single document of the format. Every image
+// is a package of the document that contains the packages of its SBOMs.
+func Report(format, version string, images []Image, created time.Time) (map[string]interface{}, error) {
+	switch format {
+	case FormatSPDX:
+		return spdxReport(version, images, created)
+	case FormatCycloneDX:
+		return cycloneDXReport(version, images, created)
+	default:
+		return nil, fmt.Errorf("unsupported SBOM format: %s", format)
+	}
+}
+
+// imageInfo splits the image reference into the name and tag, and returns its package URL
+func imageInfo(img Image) (name, tag, purl string, err error) {
+	ref, err := reference.ParseNormalizedNamed(img.Reference)
+	if err != nil {
+		return "", "", "", fmt.Errorf("cannot parse image %s: %w", img.Reference, err)
+	}
+	name = ref.Name()
+	if tagged, ok := ref.(reference.Tagged); ok {
+		tag = tagged.Tag()
+	}
+
+	_, path := reference.SplitHostname(ref)
+	query := url.Values{"repository_url": {name}}
+	if tag != "" {
+		query.Set("tag", tag)
+	}
+	purl = fmt.Sprintf("pkg:oci/%s@%s?%s", lastSegment(path), url.QueryEscape(img.Digest.String()), query.Encode())
+
+	return name, tag, purl, nil
+}
+
+func lastSegment(path string) string {
+	for i := len(path) - 1; i >= 0; i-- {
+		if path[i] == '/' {
+			return path[i+1:]
+		}
+	}
+	return path
+}
+
+func spdxReport(version string, images []Image, created time.Time) (map[string]interface{}, error) {
+	var (
+		packages      []interface{}
+		relationships []interface{}
+	)
+	for i, img := range images {
+		name, tag, purl, err := imageInfo(img)
+		if err != nil {
+			return nil, err
+		}
+
+		id := fmt.Sprintf("SPDXRef-Image-%d", i)
+		packages = append(packages, map[string]interface{}{
+			"SPDXID":           id,
+			"name":             name,
+			"versionInfo":      tag,
+			"downloadLocation": "NOASSERTION",
+			"filesAnalyzed":    false,
+			"checksums": []interface{}{
+				map[string]interface{}{"algorithm": "SHA256", "checksumValue": img.Digest.Encoded()},
+			},
+			"externalRefs": []interface{}{
+				map[string]interface{}{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": purl},
+			},
+		})
+		relationships = append(relationships, map[string]interface{}{
+			"spdxElementId":      "SPDXRef-DOCUMENT",
+			"relationshipType":   "DESCRIBES",
+			"relatedSpdxElement": id,
+		})
+
+		// The packages are renamed so they are unique across the documents of the images
+		seen := make(map[string]struct{})
+		for d, doc := range img.SBOMs {
+			pkgs, _ := doc["packages"].([]interface{})
+			for _, p := range pkgs {
+				pkg, ok := p.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				key := fmt.Sprintf("%v@%v", pkg["name"], pkg["versionInfo"])
+				if _, ok := seen[key]; ok {
+					continue
+				}
+				seen[key] = struct{}{}
+
+				contained := make(map[string]interface{}, len(pkg))
+				for k, v := range pkg {
+					contained[k] = v
+				}
+				contained["SPDXID"] = fmt.Sprintf("%s-%d-%v", id, d, pkg["SPDXID"])
+				packages = append(packages, contained)
+				relationships = append(relationships, map[string]interface{}{
+					"spdxElementId":      id,
+					"relationshipType":   "CONTAINS",
+					"relatedSpdxElement": contained["SPDXID"],
+				})
+			}
+		}
+	}
+
+	return map[string]interface{}{
+		"spdxVersion":       "SPDX-2.3",
+		"dataLicense":       "CC0-1.0",
+		"SPDXID":            "SPDXRef-DOCUMENT",
+		"name":              fmt.Sprintf("gitpod-%s", version),
+		"documentNamespace": fmt.Sprintf("https://gitpod.io/spdx/%s/%d", url.PathEscape(version), created.Unix()),
+		"creationInfo": map[string]interface{}{
+			"created":  created.UTC().Format(time.RFC3339),
+			"creators": []string{"Tool: " + toolName},
+		},
+		"packages":      packages,
+		"relationships": relationships,
+	}, nil
+}
+
+func cycloneDXReport(version string, images []Image, created time.Time) (map[string]interface{}, error) {
+	var components []interface{}
+	for _, img := range images {
+		name, tag, purl, err := imageInfo(img)
+		if err != nil {
+			return nil, err
+		}
+
+		component := map[string]interface{}{
+			"type":    "container",
+			"bom-ref": purl,
+			"name":    name,
+			"version": tag,
+			"purl":    purl,
+			"hashes": []interface{}{
+				map[string]interface{}{"alg": "SHA-256", "content": img.Digest.Encoded()},
+			},
+		}
+
+		seen := make(map[string]struct{})
+		var contained []interface{}
+		for _, doc := range img.SBOMs {
+			cs, _ := doc["components"].([]interface{})
+			for _, c := range cs {
+				m, ok := c.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				// the references are only unique within a document, the components of an
+				// image's platforms are mostly the same
+				key := fmt.Sprintf("%v@%v", m["name"], m["version"])
+				if _, ok := seen[key]; ok {
+					continue
+				}
+				seen[key] = struct{}{}
+
+				c := make(map[string]interface{}, len(m))
+				for k, v := range m {
+					c[k] = v
+				}
+				delete(c, "bom-ref")
+				contained = append(contained, c)
+			}
+		}
+		if len(contained) > 0 {
+			component["components"] = contained
+		}
+		components = append(components, component)
+	}
+
+	return map[string]interface{}{
+		"bomFormat":   "CycloneDX",
+		"specVersion": "1.5",
+		"version":     1,
+		"metadata": map[string]interface{}{
+			"timestamp": created.UTC().Format(time.RFC3339),
+			"tools": []interface{}{
+				map[string]interface{}{"name": toolName},
+			},
+			"component": map[string]interface{}{
+				"type":    "application",
+				"name":    "gitpod",
+				"version": version,
+			},
+		},
+		"components": components,
+	}, nil
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package sbom
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/containerd/containerd/remotes"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	FormatSPDX      = "spdx"
+	FormatCycloneDX = "cyclonedx"
+)
+
+const (
+	// The annotations BuildKit marks the attestation manifests of an image index with
+	annotationReferenceType   = "vnd.docker.reference.type"
+	annotationReferenceDigest = "vnd.docker.reference.digest"
+	referenceTypeAttestation  = "attestation-manifest"
+
+	annotationPredicateType = "in-toto.io/predicate-type"
+
+	// maxBlobSize limits the manifests and attestations that are read into memory
+	maxBlobSize = 64 << 20
+)
+
+// predicateTypes are the in-toto predicate types of the SBOMs in each format
+var predicateTypes = map[string]string{
+	FormatSPDX:      "https://spdx.dev/Document",
+	FormatCycloneDX: "https://cyclonedx.org/bom",
+}
+
+// Image is an image with the SBOMs attested for it
+type Image struct {
+	// Reference is the image reference that was resolved
+	Reference string `json:"reference"`
+	// Digest is the digest of the manifest or index of the image
+	Digest digest.Digest `json:"digest"`
+	// SBOMs are the documents of the SBOM attestations in the format, one for each platform
+	SBOMs []map[string]interface{} `json:"-"`
+}
+
+// Fetch resolves the images to their digest and reads their SBOM attestations in the format.
+// Images without attestations are returned without SBOMs.
+func Fetch(ctx context.Context, resolver remotes.Resolver, format string, refs []string, progress func(img Image)) ([]Image, error) {
+	predicateType, ok := predicateTypes[format]
+	if !ok {
+		return nil, fmt.Errorf("unsupported SBOM format: %s", format)
+	}
+
+	res := make([]Image, 0, len(refs))
+	for _, ref := range refs {
+		name, desc, err := resolver.Resolve(ctx, ref)
+		if err != nil {
+			return nil, fmt.Errorf("cannot resolve image %s: %w", ref, err)
+		}
+		fetcher, err := resolver.Fetcher(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+
+		img := Image{Reference: ref, Digest: desc.Digest}
+		img.SBOMs, err = fetchSBOMs(ctx, fetcher, desc, predicateType)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read SBOM attestations of %s: %w", ref, err)
+		}
+		res = append(res, img)
+		if progress != nil {
+			progress(img)
+		}
+	}
+	return res, nil
+}
+
+// fetchSBOMs returns the predicates of the attestations in the image index. Only an index can
+// reference attestation manifests, an image manifest has none.
+func fetchSBOMs(ctx context.Context, fetcher remotes.Fetcher, desc ocispec.Descriptor, predicateType string) ([]map[string]interface{}, error) {
+	if desc.MediaType != ocispec.MediaTypeImageIndex && desc.MediaType != "application/vnd.docker.distribution.manifest.list.v2+json" {
+		return nil, nil
+	}
+
+	var index ocispec.Index
+	if err := fetchJSON(ctx, fetcher, desc, &index); err != nil {
+		return nil, err
+	}
+
+	var res []map[string]interface{}
+	for _, m := range index.Manifests {
+		if m.Annotations[annotationReferenceType] != referenceTypeAttestation || m.Annotations[annotationReferenceDigest] == "" {
+			continue
+		}
+
+		var manifest ocispec.Manifest
+		if err := fetchJSON(ctx, fetcher, m, &manifest); err != nil {
+			return nil, err
+		}
+		for _, layer := range manifest.Layers {
+			if !strings.HasPrefix(layer.Annotations[annotationPredicateType], predicateType) {
+				continue
+			}
+
+			var statement struct {
+				Predicate map[string]interface{} `json:"predicate"`
+			}
+			if err := fetchJSON(ctx, fetcher, layer, &statement); err != nil {
+				return nil, err
+			}
+			if statement.Predicate != nil {
+				res = append(res, statement.Predicate)
+			}
+		}
+	}
+	return res, nil
+}
+
+func fetchJSON(ctx context.Context, fetcher remotes.Fetcher, desc ocispec.Descriptor, v interface{}) error {
+	if desc.Size > maxBlobSize {
+		return fmt.Errorf("%s is too large: %d bytes", desc.Digest, desc.Size)
+	}
+
+	rc, err := fetcher.Fetch(ctx, desc)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, maxBlobSize))
+	if err != nil {
+		return err
+	}
+	if desc.Digest != "" && digest.FromBytes(data) != desc.Digest {
+		return fmt.Errorf("%s does not match its digest", desc.Digest)
+	}
+	return json.Unmarshal(data, v)
+}
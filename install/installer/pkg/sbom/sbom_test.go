@@ -0,0 +1,134 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package sbom_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/sbom"
+	rendertest "github.com/gitpod-io/gitpod/installer/pkg/testing"
+)
+
+// addAttestedImage adds an image index with an attestation manifest like BuildKit pushes it
+func addAttestedImage(t *testing.T, r *rendertest.MemoryRegistry, ref string, predicates map[string]interface{}) {
+	image := ocispec.Manifest{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    r.AddJSON(t, ocispec.MediaTypeImageConfig, map[string]string{"architecture": "amd64", "os": "linux"}),
+	}
+	image.SchemaVersion = 2
+	imageDesc := r.AddJSON(t, ocispec.MediaTypeImageManifest, image)
+	imageDesc.Platform = &ocispec.Platform{Architecture: "amd64", OS: "linux"}
+
+	attestation := ocispec.Manifest{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    r.AddJSON(t, ocispec.MediaTypeImageConfig, map[string]string{}),
+	}
+	attestation.SchemaVersion = 2
+	for predicateType, predicate := range predicates {
+		layer := r.AddJSON(t, "application/vnd.in-toto+json", map[string]interface{}{
+			"_type":         "https://in-toto.io/Statement/v0.1",
+			"predicateType": predicateType,
+			"predicate":     predicate,
+		})
+		layer.Annotations = map[string]string{"in-toto.io/predicate-type": predicateType}
+		attestation.Layers = append(attestation.Layers, layer)
+	}
+	attestationDesc := r.AddJSON(t, ocispec.MediaTypeImageManifest, attestation)
+	attestationDesc.Platform = &ocispec.Platform{Architecture: "unknown", OS: "unknown"}
+	attestationDesc.Annotations = map[string]string{
+		"vnd.docker.reference.type":   "attestation-manifest",
+		"vnd.docker.reference.digest": imageDesc.Digest.String(),
+	}
+
+	index := ocispec.Index{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: []ocispec.Descriptor{imageDesc, attestationDesc},
+	}
+	index.SchemaVersion = 2
+	r.Tag(ref, r.AddJSON(t, ocispec.MediaTypeImageIndex, index))
+}
+
+func TestFetchAndReport(t *testing.T) {
+	registry := rendertest.NewMemoryRegistry()
+	addAttestedImage(t, registry, "eu.gcr.io/gitpod-core-dev/build/server:v1", map[string]interface{}{
+		"https://spdx.dev/Document": map[string]interface{}{
+			"spdxVersion": "SPDX-2.3",
+			"packages": []interface{}{
+				map[string]interface{}{"SPDXID": "SPDXRef-Package-node", "name": "node", "versionInfo": "16.19.1"},
+			},
+		},
+		"https://cyclonedx.org/bom": map[string]interface{}{
+			"bomFormat": "CycloneDX",
+			"components": []interface{}{
+				map[string]interface{}{"bom-ref": "pkg:npm/express@4.18.2", "name": "express", "version": "4.18.2"},
+			},
+		},
+	})
+	manifest := ocispec.Manifest{MediaType: ocispec.MediaTypeImageManifest}
+	registry.Tag("docker.io/library/redis:6.2", registry.AddJSON(t, ocispec.MediaTypeImageManifest, manifest))
+
+	refs := []string{"eu.gcr.io/gitpod-core-dev/build/server:v1", "docker.io/library/redis:6.2"}
+	created := time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("spdx", func(t *testing.T) {
+		images, err := sbom.Fetch(context.Background(), registry, sbom.FormatSPDX, refs, nil)
+		require.NoError(t, err)
+		require.Len(t, images, 2)
+		require.Len(t, images[0].SBOMs, 1)
+		require.Empty(t, images[1].SBOMs)
+		require.Equal(t, registry.Ref("docker.io/library/redis:6.2").Digest, images[1].Digest)
+
+		report, err := sbom.Report(sbom.FormatSPDX, "v1", images, created)
+		require.NoError(t, err)
+		require.Equal(t, "2023-03-01T00:00:00Z", report["creationInfo"].(map[string]interface{})["created"])
+
+		packages := report["packages"].([]interface{})
+		require.Len(t, packages, 3)
+		server := packages[0].(map[string]interface{})
+		require.Equal(t, "eu.gcr.io/gitpod-core-dev/build/server", server["name"])
+		require.Equal(t, "v1", server["versionInfo"])
+		require.Equal(t, images[0].Digest.Encoded(), server["checksums"].([]interface{})[0].(map[string]interface{})["checksumValue"])
+		require.Equal(t,
+			fmt.Sprintf("pkg:oci/server@sha256%%3A%s?repository_url=eu.gcr.io%%2Fgitpod-core-dev%%2Fbuild%%2Fserver&tag=v1", images[0].Digest.Encoded()),
+			server["externalRefs"].([]interface{})[0].(map[string]interface{})["referenceLocator"],
+		)
+		node := packages[1].(map[string]interface{})
+		require.Equal(t, "node", node["name"])
+		require.Equal(t, "SPDXRef-Image-0-0-SPDXRef-Package-node", node["SPDXID"])
+		require.Equal(t, "docker.io/library/redis", packages[2].(map[string]interface{})["name"])
+
+		require.Contains(t, report["relationships"], map[string]interface{}{
+			"spdxElementId":      "SPDXRef-Image-0",
+			"relationshipType":   "CONTAINS",
+			"relatedSpdxElement": "SPDXRef-Image-0-0-SPDXRef-Package-node",
+		})
+	})
+
+	t.Run("cyclonedx", func(t *testing.T) {
+		images, err := sbom.Fetch(context.Background(), registry, sbom.FormatCycloneDX, refs, nil)
+		require.NoError(t, err)
+
+		report, err := sbom.Report(sbom.FormatCycloneDX, "v1", images, created)
+		require.NoError(t, err)
+
+		components := report["components"].([]interface{})
+		require.Len(t, components, 2)
+		server := components[0].(map[string]interface{})
+		require.Equal(t, "container", server["type"])
+		require.Equal(t, []interface{}{map[string]interface{}{"name": "express", "version": "4.18.2"}}, server["components"])
+		require.NotContains(t, components[1], "components")
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		_, err := sbom.Fetch(context.Background(), registry, "swid", refs, nil)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package testing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	gotesting "testing"
+
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/remotes"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+)
+
+// MemoryRegistry is a registry that keeps the blobs and image references in memory, for the
+// tests of the commands that pull and push images. It is safe for concurrent use.
+type MemoryRegistry struct {
+	mu    sync.Mutex
+	blobs map[digest.Digest][]byte
+	refs  map[string]ocispec.Descriptor
+}
+
+var _ remotes.Resolver = &MemoryRegistry{}
+
+func NewMemoryRegistry() *MemoryRegistry {
+	return &MemoryRegistry{
+		blobs: make(map[digest.Digest][]byte),
+		refs:  make(map[string]ocispec.Descriptor),
+	}
+}
+
+// Add stores a blob and returns its descriptor
+func (r *MemoryRegistry) Add(mediaType string, data []byte) ocispec.Descriptor {
+	desc := ocispec.Descriptor{MediaType: mediaType, Digest: digest.FromBytes(data), Size: int64(len(data))}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.blobs[desc.Digest] = data
+	return desc
+}
+
+// AddJSON stores the JSON of a manifest, index or config and returns its descriptor
+func (r *MemoryRegistry) AddJSON(t *gotesting.T, mediaType string, v interface{}) ocispec.Descriptor {
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return r.Add(mediaType, data)
+}
+
+// Tag points the reference at a manifest or index
+func (r *MemoryRegistry) Tag(ref string, desc ocispec.Descriptor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.refs[ref] = desc
+}
+
+// Ref returns the descriptor the reference points at
+func (r *MemoryRegistry) Ref(ref string) ocispec.Descriptor {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.refs[ref]
+}
+
+// Blobs returns a copy of the blobs by their digest
+func (r *MemoryRegistry) Blobs() map[digest.Digest][]byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	res := make(map[digest.Digest][]byte, len(r.blobs))
+	for dgst, data := range r.blobs {
+		res[dgst] = data
+	}
+	return res
+}
+
+func (r *MemoryRegistry) Resolve(ctx context.Context, ref string) (string, ocispec.Descriptor, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	desc, ok := r.refs[ref]
+	if !ok {
+		return "", ocispec.Descriptor{}, fmt.Errorf("%s not found", ref)
+	}
+	return ref, desc, nil
+}
+
+func (r *MemoryRegistry) Fetcher(ctx context.Context, ref string) (remotes.Fetcher, error) {
+	return r, nil
+}
+
+func (r *MemoryRegistry) Fetch(ctx context.Context, desc ocispec.Descriptor) (io.ReadCloser, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	data, ok := r.blobs[desc.Digest]
+	if !ok {
+		return nil, fmt.Errorf("%s not found", desc.Digest)
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// Pusher tags the reference once its manifest is pushed
+func (r *MemoryRegistry) Pusher(ctx context.Context, ref string) (remotes.Pusher, error) {
+	return pusherFunc(func(ctx context.Context, desc ocispec.Descriptor) (content.Writer, error) {
+		return &memoryWriter{registry: r, ref: ref, desc: desc}, nil
+	}), nil
+}
+
+type pusherFunc func(ctx context.Context, desc ocispec.Descriptor) (content.Writer, error)
+
+func (f pusherFunc) Push(ctx context.Context, desc ocispec.Descriptor) (content.Writer, error) {
+	return f(ctx, desc)
+}
+
+type memoryWriter struct {
+	bytes.Buffer
+	registry *MemoryRegistry
+	ref      string
+	desc     ocispec.Descriptor
+}
+
+func (w *memoryWriter) Close() error              { return nil }
+func (w *memoryWriter) Digest() digest.Digest     { return digest.FromBytes(w.Bytes()) }
+func (w *memoryWriter) Truncate(size int64) error { w.Reset(); return nil }
+func (w *memoryWriter) Status() (content.Status, error) {
+	return content.Status{Ref: w.ref, Offset: int64(w.Len()), Total: w.desc.Size}, nil
+}
+
+func (w *memoryWriter) Commit(ctx context.Context, size int64, expected digest.Digest, opts ...content.Opt) error {
+	if expected != "" && expected != w.Digest() {
+		return fmt.Errorf("unexpected digest %s, expected %s", w.Digest(), expected)
+	}
+	w.registry.Add(w.desc.MediaType, w.Bytes())
+	if w.desc.MediaType == ocispec.MediaTypeImageManifest || w.desc.MediaType == ocispec.MediaTypeImageIndex {
+		w.registry.Tag(w.ref, w.desc)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+// Package testing renders the objects of a component in its tests. The components of forks and
+// extensions can use it to test their render functions like those of the installer. It also has
+// an in-memory registry for the tests of the commands that pull and push images.
+package testing
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	gotesting "testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/yaml"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	"github.com/gitpod-io/gitpod/installer/pkg/config"
+	configv1 "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+	"github.com/gitpod-io/gitpod/installer/pkg/config/versions"
+)
+
+var update = flag.Bool("update-golden", false, "write the rendered objects to the golden files instead of comparing them")
+
+const (
+	// Namespace is the namespace the objects are rendered into
+	Namespace = "test_namespace"
+	// Version is the version of every component in the Manifest
+	Version = "test"
+)
+
+// Manifest returns a version manifest with every component in Version, so the images of any
+// component can be named
+func Manifest() versions.Manifest {
+	res := versions.Manifest{Version: Version}
+
+	var walk func(v reflect.Value)
+	walk = func(v reflect.Value) {
+		if v.Type() == reflect.TypeOf(versions.Versioned{}) {
+			v.Set(reflect.ValueOf(versions.Versioned{Version: Version}))
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Field(i).Kind() == reflect.Struct {
+				walk(v.Field(i))
+			}
+		}
+	}
+	walk(reflect.ValueOf(&res.Components).Elem())
+
+	return res
+}
+
+// LoadConfig reads the config of a fixture file onto the defaults, as the render command does
+func LoadConfig(t gotesting.TB, fn string) configv1.Config {
+	t.Helper()
+
+	fc, err := os.ReadFile(fn)
+	require.NoError(t, err)
+
+	cfg, _, err := config.Load(string(fc), true)
+	require.NoError(t, err, "cannot load config %s", fn)
+	return *cfg.(*configv1.Config)
+}
+
+// RenderContext returns the context of the config with the Manifest
+func RenderContext(t gotesting.TB, cfg configv1.Config) *common.RenderContext {
+	t.Helper()
+
+	ctx, err := common.NewRenderContext(cfg, Manifest(), Namespace)
+	require.NoError(t, err)
+	return ctx
+}
+
+// Render returns the objects the function renders for the config
+func Render(t gotesting.TB, fn common.RenderFunc, cfg configv1.Config) []runtime.Object {
+	t.Helper()
+
+	objs, err := fn(RenderContext(t, cfg))
+	require.NoError(t, err)
+	return objs
+}
+
+// Object returns the object of the kind and name, the test fails if it is not rendered
+func Object(t gotesting.TB, objs []runtime.Object, kind, name string) runtime.Object {
+	t.Helper()
+
+	for _, obj := range objs {
+		if obj.GetObjectKind().GroupVersionKind().Kind != kind {
+			continue
+		}
+		m, err := meta.Accessor(obj)
+		require.NoError(t, err)
+		if m.GetName() == name {
+			return obj
+		}
+	}
+	require.FailNow(t, fmt.Sprintf("%s %s is not rendered", kind, name))
+	return nil
+}
+
+// Field returns the value of the JSONPath in the object, e.g. {.spec.replicas}. Numbers are
+// int64 or float64 as they are in JSON.
+func Field(t gotesting.TB, obj runtime.Object, path string) interface{} {
+	t.Helper()
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	require.NoError(t, err)
+
+	jp := jsonpath.New(path)
+	require.NoError(t, jp.Parse(path))
+	res, err := jp.FindResults(content)
+	require.NoError(t, err, "cannot find %s", path)
+	require.True(t, len(res) == 1 && len(res[0]) == 1, "%s must match exactly one value", path)
+
+	return res[0][0].Interface()
+}
+
+// RequireField fails the test if the value of the JSONPath in the object is not the expected one
+func RequireField(t gotesting.TB, obj runtime.Object, path string, expected interface{}) {
+	t.Helper()
+	require.EqualValues(t, expected, Field(t, obj, path), "value of %s", path)
+}
+
+// YAML returns the objects as the documents of the render command
+func YAML(t gotesting.TB, objs []runtime.Object) string {
+	t.Helper()
+
+	var res strings.Builder
+	for _, obj := range objs {
+		fc, err := yaml.Marshal(obj)
+		require.NoError(t, err)
+		fmt.Fprintf(&res, "---\n%s", fc)
+	}
+	return res.String()
+}
+
+// Golden compares the YAML of the objects against the golden file. Running the tests with
+// -update-golden writes the golden file instead.
+func Golden(t gotesting.TB, objs []runtime.Object, fn string) {
+	t.Helper()
+
+	actual := YAML(t, objs)
+	if *update {
+		require.NoError(t, os.MkdirAll(filepath.Dir(fn), 0755))
+		require.NoError(t, os.WriteFile(fn, []byte(actual), 0644))
+		return
+	}
+
+	expected, err := os.ReadFile(fn)
+	require.NoError(t, err, "cannot read golden file, run the tests with -update-golden to write it")
+	if diff := cmp.Diff(string(expected), actual); diff != "" {
+		t.Errorf("objects do not match %s, run the tests with -update-golden to update it (-want +got):\n%s", fn, diff)
+	}
+}
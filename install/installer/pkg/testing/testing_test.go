@@ -0,0 +1,80 @@
+// Copyright (c) 2023 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License.AGPL.txt in the project root for license information.
+
+package testing
+
+import (
+	"os"
+	"path/filepath"
+	gotesting "testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+
+	"github.com/gitpod-io/gitpod/installer/pkg/common"
+	configv1 "github.com/gitpod-io/gitpod/installer/pkg/config/v1"
+)
+
+func render(ctx *common.RenderContext) ([]runtime.Object, error) {
+	return []runtime.Object{
+		&corev1.ConfigMap{
+			TypeMeta:   common.TypeMetaConfigmap,
+			ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: ctx.Namespace},
+			Data:       map[string]string{"domain": ctx.Config.Domain},
+		},
+		&appsv1.Deployment{
+			TypeMeta:   common.TypeMetaDeployment,
+			ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: ctx.Namespace},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: pointer.Int32(2),
+				Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+					Name:  "example",
+					Image: ctx.ImageName(ctx.Config.Repository, "server", ctx.VersionManifest.Components.Server.Version),
+				}}}},
+			},
+		},
+	}, nil
+}
+
+func TestManifest(t *gotesting.T) {
+	mf := Manifest()
+	require.Equal(t, Version, mf.Components.Server.Version)
+	require.Equal(t, Version, mf.Components.WSDaemon.Version)
+	require.Equal(t, Version, mf.Components.WSDaemon.UserNamespaces.SeccompProfileInstaller.Version)
+	require.Equal(t, map[string]struct{}{Version: {}}, mf.Components.Versions())
+}
+
+func TestRender(t *gotesting.T) {
+	fn := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(fn, []byte("domain: gitpod.example.com\n"), 0644))
+	cfg := LoadConfig(t, fn)
+	require.Equal(t, configv1.InstallationFull, cfg.Kind, "the defaults must be kept")
+
+	objs := Render(t, render, cfg)
+
+	RequireField(t, Object(t, objs, "ConfigMap", "example"), "{.data.domain}", "gitpod.example.com")
+	deployment := Object(t, objs, "Deployment", "example")
+	RequireField(t, deployment, "{.spec.replicas}", 2)
+	RequireField(t, deployment, "{.spec.template.spec.containers[0].image}", "eu.gcr.io/gitpod-core-dev/build/server:test")
+}
+
+func TestGolden(t *gotesting.T) {
+	objs := Render(t, render, configv1.Config{Domain: "gitpod.example.com", Repository: "eu.gcr.io/gitpod-core-dev/build"})
+	fn := filepath.Join(t.TempDir(), "example.golden")
+
+	*update = true
+	Golden(t, objs, fn)
+	*update = false
+
+	fc, err := os.ReadFile(fn)
+	require.NoError(t, err)
+	require.Equal(t, YAML(t, objs), string(fc))
+	require.Contains(t, string(fc), "---\napiVersion: v1\ndata:\n  domain: gitpod.example.com\nkind: ConfigMap\n")
+
+	Golden(t, objs, fn)
+}
@@ -22,6 +22,9 @@ done
 echo "building test tests/workspace"
 go test -trimpath -ldflags="-buildid= -w -s" -o bin/workspace.test -c ./tests/workspace
 
+echo "building test tests/smoke-test"
+go test -trimpath -ldflags="-buildid= -w -s" -o bin/smoke-test.test -c ./tests/smoke-test
+
 for COMPONENT in tests/ide/*; do
     echo "building test $COMPONENT"
     OUTPUT=$(basename "$COMPONENT")
@@ -54,3 +54,52 @@ func TestStartWorkspaceWithImageBuild(t *testing.T) {
 		Feature()
 	testEnv.Test(t, f)
 }
+
+// TestStartRegularWorkspace starts a workspace from a repository that needs no image build. It
+// is what the smoke test job of the installer runs after every deployment.
+func TestStartRegularWorkspace(t *testing.T) {
+	userToken, _ := os.LookupEnv("USER_TOKEN")
+	integration.SkipWithoutUsername(t, username)
+	integration.SkipWithoutUserToken(t, userToken)
+
+	contextURL := "https://github.com/gitpod-io/empty"
+	if u, _ := os.LookupEnv("SMOKE_TEST_CONTEXT_URL"); u != "" {
+		contextURL = u
+	}
+
+	f := features.New("Start regular workspace").
+		Assess("it can start a regular workspace", func(_ context.Context, t *testing.T, cfg *envconf.Config) context.Context {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			defer cancel()
+
+			api := integration.NewComponentAPI(ctx, cfg.Namespace(), kubeconfig, cfg.Client())
+			t.Cleanup(func() {
+				api.Done(t)
+			})
+
+			_, err := api.CreateUser(username, userToken)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, stopWs, err := integration.LaunchWorkspaceFromContextURL(t, ctx, contextURL, username, api)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() {
+				sctx, scancel := context.WithTimeout(context.Background(), 5*time.Minute)
+				defer scancel()
+
+				sapi := integration.NewComponentAPI(sctx, cfg.Namespace(), kubeconfig, cfg.Client())
+				defer sapi.Done(t)
+
+				_, err := stopWs(true, sapi)
+				if err != nil {
+					t.Errorf("cannot stop workspace: %v", err)
+				}
+			}()
+			return ctx
+		}).
+		Feature()
+	testEnv.Test(t, f)
+}